@@ -99,8 +99,12 @@ func (c *ContivAgent) Close() error {
 
 func main() {
 
-	ksrServicelabel := servicelabel.NewPlugin(servicelabel.UseLabel(ksr.MicroserviceLabel))
-	ksrServicelabel.SetName("ksrServiceLabel")
+	// place all the keys under the subtree of the cluster (if CONTIV_CLUSTER_ID is set)
+	serviceLabel := ksr.NewClusterServiceLabel(&servicelabel.DefaultPlugin)
+
+	ksrServicelabel := ksr.NewClusterServiceLabel(servicelabel.NewPlugin(servicelabel.UseLabel(ksr.MicroserviceLabel)))
+	ksrServicelabel.Label.SetName("ksrServiceLabel")
+	ksrServicelabel.SetName("ksrClusterServiceLabel")
 
 	newKSRprefixSync := func(name string) *kvdbsync.Plugin {
 		return kvdbsync.NewPlugin(
@@ -115,6 +119,7 @@ func main() {
 	etcdDataSync := kvdbsync.NewPlugin(kvdbsync.UseDeps(func(deps *kvdbsync.Deps) {
 		deps.KvPlugin = &etcd.DefaultPlugin
 		deps.ResyncOrch = &resync.DefaultPlugin
+		deps.ServiceLabel = serviceLabel
 	}))
 
 	nodeIDDataSync := newKSRprefixSync("nodeIdDataSync")
@@ -128,6 +133,7 @@ func main() {
 	vppPlugin := vpp.NewPlugin(
 		vpp.UseDeps(func(deps *vpp.Deps) {
 			deps.GoVppmux = &govppmux.DefaultPlugin
+			deps.ServiceLabel = serviceLabel
 			deps.Publish = etcdDataSync
 			deps.Watcher = watcher
 			deps.WatchEventsMutex = &watchEventsMutex
@@ -159,6 +165,7 @@ func main() {
 
 	contivPlugin := contiv.NewPlugin(contiv.UseDeps(func(deps *contiv.Deps) {
 		deps.VPP = vppPlugin
+		deps.ServiceLabel = serviceLabel
 		deps.Watcher = nodeIDDataSync
 	}))
 
@@ -176,6 +183,7 @@ func main() {
 		deps.Contiv = contivPlugin
 		deps.VPP = vppPlugin
 	}))
+	policyPlugin.Service = servicePlugin
 
 	contivAgent := &ContivAgent{
 		LogManager:      &logmanager.DefaultPlugin,
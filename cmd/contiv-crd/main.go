@@ -51,8 +51,10 @@ func (c *ContivCRD) Close() error {
 }
 
 func main() {
-	ksrServicelabel := servicelabel.NewPlugin(servicelabel.UseLabel(ksr.MicroserviceLabel))
-	ksrServicelabel.SetName("ksrServiceLabel")
+	// watch the subtree of the cluster (if CONTIV_CLUSTER_ID is set)
+	ksrServicelabel := ksr.NewClusterServiceLabel(servicelabel.NewPlugin(servicelabel.UseLabel(ksr.MicroserviceLabel)))
+	ksrServicelabel.Label.SetName("ksrServiceLabel")
+	ksrServicelabel.SetName("ksrClusterServiceLabel")
 
 	ksrDataSync := kvdbsync.NewPlugin(kvdbsync.UseDeps(func(deps *kvdbsync.Deps) {
 		deps.KvPlugin = &etcd.DefaultPlugin
@@ -50,6 +50,7 @@ import (
 	"github.com/apparentlymart/go-cidr/cidr"
 	"github.com/contiv/vpp/cmd/contiv-stn/model/stn"
 	"github.com/contiv/vpp/plugins/contiv"
+	"github.com/contiv/vpp/plugins/ksr"
 	"github.com/vishvananda/netlink"
 )
 
@@ -368,7 +369,7 @@ func persistVppConfig(contivCfg *contiv.Config, stnData *stn.STNReply, cfg *vppC
 	}
 
 	protoDb := kvproto.NewProtoWrapperWithSerializer(conn, &keyval.SerializerJSON{})
-	pb := protoDb.NewBroker(servicelabel.GetDifferentAgentPrefix(os.Getenv(servicelabel.MicroserviceLabelEnvVar)))
+	pb := protoDb.NewBroker(ksr.GetClusterAgentPrefix(ksr.GetClusterID(), os.Getenv(servicelabel.MicroserviceLabelEnvVar)))
 	defer protoDb.Close()
 
 	// persist interface config
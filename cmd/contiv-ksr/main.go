@@ -51,15 +51,20 @@ func main() {
 
 	servicelabel.DefaultPlugin.MicroserviceLabel = ksr.MicroserviceLabel
 
+	// place all the keys under the subtree of the cluster (if CONTIV_CLUSTER_ID is set)
+	serviceLabel := ksr.NewClusterServiceLabel(&servicelabel.DefaultPlugin)
+
 	etcdDataSync := kvdbsync.NewPlugin(kvdbsync.UseDeps(func(deps *kvdbsync.Deps) {
 		deps.KvPlugin = &etcd.DefaultPlugin
 		deps.ResyncOrch = &resync.DefaultPlugin
+		deps.ServiceLabel = serviceLabel
 	}))
 
+	ksr.DefaultPlugin.ServiceLabel = serviceLabel
 	ksr.DefaultPlugin.Publish = etcdDataSync
 
 	contivKSR := &ContivKSR{
-		ServiceLabel: &servicelabel.DefaultPlugin,
+		ServiceLabel: serviceLabel,
 		HealthProbe:  &probe.DefaultPlugin,
 		DataSyncETCD: etcdDataSync,
 		KSR:          &ksr.DefaultPlugin,
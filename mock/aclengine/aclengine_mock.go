@@ -36,6 +36,9 @@ import (
 // maxPortNum is the maximum possible port number.
 const maxPortNum = uint32(^uint16(0))
 
+// sctpProtocol is the IP protocol number of SCTP.
+const sctpProtocol = uint32(132)
+
 // ConnectionAction is one of DENY-SYN, DENY-SYN-ACK, ALLOW, FAILURE.
 type ConnectionAction int
 
@@ -79,6 +82,11 @@ type MockACLEngine struct {
 
 	pods      map[podmodel.ID]*PodConfig
 	aclConfig *ACLConfig
+
+	// failure injection
+	failTxn       bool
+	failAfterOps  int
+	unappliedACLs map[string]struct{} // ACLs with failed (unapplied) operations
 }
 
 // PodConfig encapsulates pod configuration.
@@ -103,10 +111,11 @@ type InterfaceACLs struct {
 // NewMockACLEngine is a constructor for MockACLEngine.
 func NewMockACLEngine(log logging.Logger, contiv contiv.API) *MockACLEngine {
 	return &MockACLEngine{
-		Log:       log,
-		Contiv:    contiv,
-		pods:      make(map[podmodel.ID]*PodConfig),
-		aclConfig: NewACLConfig(),
+		Log:           log,
+		Contiv:        contiv,
+		pods:          make(map[podmodel.ID]*PodConfig),
+		aclConfig:     NewACLConfig(),
+		unappliedACLs: make(map[string]struct{}),
 	}
 }
 
@@ -127,6 +136,17 @@ func (mae *MockACLEngine) RegisterPod(pod podmodel.ID, podIP string, anotherNode
 	mae.pods[pod] = &PodConfig{podIP: net.ParseIP(podIP), anotherNode: anotherNode}
 }
 
+// InjectFailure makes the next transaction fail after *afterOps* operations
+// were applied. The remaining operations of the transaction are not applied.
+// If the transaction has fewer operations, all of them are applied, but
+// the failure is still returned.
+func (mae *MockACLEngine) InjectFailure(afterOps int) {
+	mae.Lock()
+	defer mae.Unlock()
+	mae.failTxn = true
+	mae.failAfterOps = afterOps
+}
+
 // ApplyTxn applies transaction created by ACL renderer.
 func (mae *MockACLEngine) ApplyTxn(txn *localclient.Txn, latestRevs *syncbase.PrevRevisions) error {
 	mae.Lock()
@@ -149,16 +169,26 @@ func (mae *MockACLEngine) ApplyTxn(txn *localclient.Txn, latestRevs *syncbase.Pr
 	}
 
 	dataChange := txn.LinuxDataChangeTxn
-	for _, op := range dataChange.Ops {
+	for opIdx, op := range dataChange.Ops {
 		if !strings.HasPrefix(op.Key, vpp_acl.Prefix) {
 			return errors.New("non-ACL changed in txn")
 		}
 		aclName := strings.TrimPrefix(op.Key, vpp_acl.Prefix)
+		if mae.failTxn && opIdx == mae.failAfterOps {
+			// injected failure - the operations from here on are not applied
+			mae.failTxn = false
+			for _, failedOp := range dataChange.Ops[opIdx:] {
+				mae.unappliedACLs[strings.TrimPrefix(failedOp.Key, vpp_acl.Prefix)] = struct{}{}
+			}
+			return errors.New("injected failure")
+		}
+		_, unapplied := mae.unappliedACLs[aclName]
+		delete(mae.unappliedACLs, aclName)
 		foundRev, _ := latestRevs.Get(op.Key)
 		if op.Value != nil {
 			// put ACL
 			_, hasACL := mae.aclConfig.byName[aclName]
-			if hasACL != foundRev {
+			if hasACL != foundRev && !unapplied {
 				return errors.New("modify vs create ACL operation mismatch")
 			}
 			acl, isACL := op.Value.(*vpp_acl.AccessLists_Acl)
@@ -174,6 +204,10 @@ func (mae *MockACLEngine) ApplyTxn(txn *localclient.Txn, latestRevs *syncbase.Pr
 			if !foundRev {
 				return errors.New("cannot remove ACL without latest value/revision")
 			}
+			if _, hasACL := mae.aclConfig.byName[aclName]; !hasACL && unapplied {
+				// the creation has failed
+				continue
+			}
 			err := mae.aclConfig.DelACL(aclName)
 			if err != nil {
 				return err
@@ -182,6 +216,11 @@ func (mae *MockACLEngine) ApplyTxn(txn *localclient.Txn, latestRevs *syncbase.Pr
 
 	}
 
+	if mae.failTxn {
+		// injected failure after all operations were applied
+		mae.failTxn = false
+		return errors.New("injected failure")
+	}
 	return nil
 }
 
@@ -228,6 +267,7 @@ func (mae *MockACLEngine) GetNumOfACLChanges() int {
 
 // ConnectionPodToPod allows to simulate a connection establishment between two pods
 // and tests what the outcome in terms of ACLs would be.
+// For ICMP, <srcPort> and <dstPort> carry the ICMP type and code, respectively.
 func (mae *MockACLEngine) ConnectionPodToPod(srcPod podmodel.ID, dstPod podmodel.ID,
 	protocol renderer.ProtocolType, srcPort, dstPort uint16) ConnectionAction {
 
@@ -403,7 +443,7 @@ func (mae *MockACLEngine) testConnection(srcIfName string, srcIP net.IP,
 
 	// SYN packet:
 	//   -> test inbound ACL for source interface
-	srcInAction := mae.evalACL(srcACLs.inbound, srcIP, dstIP, protocol, dstPort)
+	srcInAction := mae.evalACL(srcACLs.inbound, srcIP, dstIP, protocol, srcPort, dstPort)
 	if srcInAction == ACLActionFailure {
 		return ConnActionFailure
 	}
@@ -418,7 +458,7 @@ func (mae *MockACLEngine) testConnection(srcIfName string, srcIP net.IP,
 	}
 	//   -> test outbound ACL for destination interface
 	if !dstIfReflected {
-		dstOutAction := mae.evalACL(dstACLs.outbound, srcIP, dstIP, protocol, dstPort)
+		dstOutAction := mae.evalACL(dstACLs.outbound, srcIP, dstIP, protocol, srcPort, dstPort)
 		if dstOutAction == ACLActionFailure {
 			return ConnActionFailure
 		}
@@ -433,10 +473,15 @@ func (mae *MockACLEngine) testConnection(srcIfName string, srcIP net.IP,
 		}
 	}
 
+	// ICMP is tested as a single request packet.
+	if protocol == renderer.ICMP {
+		return ConnActionAllow
+	}
+
 	// SYN-ACK packet:
 	//   -> test inbound ACL for destination interface
 	if !dstIfReflected {
-		dstInAction := mae.evalACL(dstACLs.inbound, dstIP, srcIP, protocol, srcPort)
+		dstInAction := mae.evalACL(dstACLs.inbound, dstIP, srcIP, protocol, dstPort, srcPort)
 		if dstInAction == ACLActionFailure {
 			return ConnActionFailure
 		}
@@ -446,7 +491,7 @@ func (mae *MockACLEngine) testConnection(srcIfName string, srcIP net.IP,
 	}
 	//   -> test outbound ACL for source interface
 	if !srcIfReflected {
-		srcOutAction := mae.evalACL(srcACLs.outbound, dstIP, srcIP, protocol, srcPort)
+		srcOutAction := mae.evalACL(srcACLs.outbound, dstIP, srcIP, protocol, dstPort, srcPort)
 		if srcOutAction == ACLActionFailure {
 			return ConnActionFailure
 		}
@@ -458,8 +503,10 @@ func (mae *MockACLEngine) testConnection(srcIfName string, srcIP net.IP,
 	return ConnActionAllow
 }
 
+// evalACL evaluates ACL for a single packet. For ICMP, <srcPort> and <dstPort>
+// carry ICMP type and code, respectively.
 func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net.IP,
-	protocol renderer.ProtocolType, dstPort uint16) ACLAction {
+	protocol renderer.ProtocolType, srcPort, dstPort uint16) ACLAction {
 
 	if acl == nil {
 		return ACLActionPermit
@@ -477,9 +524,9 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 			return ACLActionFailure
 		}
 		ipRule := rule.Match.IpRule
-		if ipRule.Icmp != nil || ipRule.Ip == nil {
+		if ipRule.Ip == nil {
 			// unsupported
-			mae.Log.WithField("acl", *acl).Error("Missing IP or found unsupported ICMP section")
+			mae.Log.WithField("acl", *acl).Error("Missing IP section")
 			return ACLActionFailure
 		}
 		if ipRule.Udp != nil && ipRule.Tcp != nil {
@@ -487,6 +534,11 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 			mae.Log.WithField("acl", *acl).Error("Both TCP and UDP sections are defined")
 			return ACLActionFailure
 		}
+		if ipRule.Icmp != nil && (ipRule.Udp != nil || ipRule.Tcp != nil) {
+			// invalid
+			mae.Log.WithField("acl", *acl).Error("Both ICMP and TCP/UDP sections are defined")
+			return ACLActionFailure
+		}
 
 		// check source network
 		if ipRule.Ip.SourceNetwork != "" {
@@ -516,10 +568,16 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 			}
 		}
 
+		// check IP protocol (only SCTP can be matched by the protocol number)
+		if ipRule.Ip.Protocol != 0 && (protocol != renderer.SCTP || ipRule.Ip.Protocol != sctpProtocol) {
+			// not matching
+			continue
+		}
+
 		// check L4
 		switch protocol {
 		case renderer.TCP:
-			if ipRule.Udp != nil {
+			if ipRule.Udp != nil || ipRule.Icmp != nil {
 				// not matching
 				continue
 			}
@@ -551,7 +609,7 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 			}
 
 		case renderer.UDP:
-			if ipRule.Tcp != nil {
+			if ipRule.Tcp != nil || ipRule.Icmp != nil {
 				// not matching
 				continue
 			}
@@ -582,11 +640,31 @@ func (mae *MockACLEngine) evalACL(acl *vpp_acl.AccessLists_Acl, srcIP, dstIP net
 				}
 			}
 
-		case renderer.OTHER:
+		case renderer.ICMP:
 			if ipRule.Tcp != nil || ipRule.Udp != nil {
 				// not matching
 				continue
 			}
+			if ipRule.Icmp != nil {
+				typeRange := ipRule.Icmp.IcmpTypeRange
+				codeRange := ipRule.Icmp.IcmpCodeRange
+				if typeRange == nil || codeRange == nil {
+					// invalid
+					mae.Log.WithField("acl", *acl).Error("Missing ICMP type or code range")
+					return ACLActionFailure
+				}
+				if uint32(srcPort) < typeRange.First || uint32(srcPort) > typeRange.Last ||
+					uint32(dstPort) < codeRange.First || uint32(dstPort) > codeRange.Last {
+					// not matching
+					continue
+				}
+			}
+
+		case renderer.SCTP, renderer.OTHER:
+			if ipRule.Tcp != nil || ipRule.Udp != nil || ipRule.Icmp != nil {
+				// not matching
+				continue
+			}
 		}
 
 		// Rule matches the packet!
@@ -17,9 +17,11 @@ package contiv
 import (
 	"net"
 	"sync"
+	"time"
 
 	"github.com/contiv/vpp/plugins/contiv"
 	"github.com/contiv/vpp/plugins/contiv/containeridx"
+	"github.com/contiv/vpp/plugins/contiv/pcap"
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	"github.com/ligato/cn-infra/logging/logrus"
 )
@@ -42,14 +44,21 @@ type MockContiv struct {
 	tcpNATSessionTimeout       uint32
 	otherNATSessionTimeout     uint32
 	serviceLocalEndpointWeight uint8
+	serviceRenderer            string
+	serviceBackendDrainPeriod  time.Duration
 	natLoopbackIP              net.IP
+	dnsProxyConfig             contiv.DNSProxyConfig
+	hostEndpointPolicyConfig   contiv.HostEndpointPolicyConfig
+	pcapTracer                 *pcap.Tracer
 	nodeIP                     string
 	nodeIPsubs                 []chan string
 	podPreRemovalHooks         []contiv.PodActionHook
 	podPostAddHooks            []contiv.PodActionHook
+	remoteEndpointsHooks       []contiv.RemoteEndpointsHook
 	mainPhysIf                 string
 	otherPhysIfs               []string
 	hostInterconnect           string
+	hostInterconnectIP         net.IP
 	vxlanBVIIfName             string
 	defaultIfName              string
 	defaultIfIP                net.IP
@@ -134,6 +143,12 @@ func (mc *MockContiv) SetHostInterconnectIfName(ifName string) {
 	mc.hostInterconnect = ifName
 }
 
+// SetHostInterconnectIP allows to set what tests will assume the IP address of the host end
+// of the host-interconnect is.
+func (mc *MockContiv) SetHostInterconnectIP(ip net.IP) {
+	mc.hostInterconnectIP = ip
+}
+
 // SetVxlanBVIIfName allows to set what tests will assume the name of the VXLAN BVI interface is.
 func (mc *MockContiv) SetVxlanBVIIfName(ifName string) {
 	mc.vxlanBVIIfName = ifName
@@ -157,6 +172,35 @@ func (mc *MockContiv) SetServiceLocalEndpointWeight(weight uint8) {
 	mc.serviceLocalEndpointWeight = weight
 }
 
+// SetServiceRenderer allows to set what tests will assume the renderer selected
+// for services without the renderer annotation is.
+func (mc *MockContiv) SetServiceRenderer(renderer string) {
+	mc.serviceRenderer = renderer
+}
+
+// SetServiceBackendDrainPeriod allows to set what tests will assume the drain
+// period of terminating service backends is.
+func (mc *MockContiv) SetServiceBackendDrainPeriod(period time.Duration) {
+	mc.serviceBackendDrainPeriod = period
+}
+
+// SetDNSProxyConfig allows to set what tests will assume the configuration
+// of the DNS proxy is.
+func (mc *MockContiv) SetDNSProxyConfig(config contiv.DNSProxyConfig) {
+	mc.dnsProxyConfig = config
+}
+
+// SetHostEndpointPolicyConfig allows to set what tests will assume
+// the configuration of host-endpoint policies is.
+func (mc *MockContiv) SetHostEndpointPolicyConfig(config contiv.HostEndpointPolicyConfig) {
+	mc.hostEndpointPolicyConfig = config
+}
+
+// SetPcapTracer allows to set the tracer of VPP pcap traces returned to tests.
+func (mc *MockContiv) SetPcapTracer(tracer *pcap.Tracer) {
+	mc.pcapTracer = tracer
+}
+
 // SetNatLoopbackIP allows to set what tests will assume the NAT loopback IP is.
 func (mc *MockContiv) SetNatLoopbackIP(natLoopIP string) {
 	mc.natLoopbackIP = net.ParseIP(natLoopIP)
@@ -233,6 +277,34 @@ func (mc *MockContiv) GetServiceLocalEndpointWeight() uint8 {
 	return mc.serviceLocalEndpointWeight
 }
 
+// GetServiceRenderer returns the name of the renderer selected for services
+// which do not request any specific renderer.
+func (mc *MockContiv) GetServiceRenderer() string {
+	return mc.serviceRenderer
+}
+
+// GetServiceBackendDrainPeriod returns for how long the terminating or no longer
+// ready service backends keep their existing connections.
+func (mc *MockContiv) GetServiceBackendDrainPeriod() time.Duration {
+	return mc.serviceBackendDrainPeriod
+}
+
+// GetDNSProxyConfig returns configuration for learning of IP addresses
+// referenced by FQDN-based policy rules.
+func (mc *MockContiv) GetDNSProxyConfig() *contiv.DNSProxyConfig {
+	return &mc.dnsProxyConfig
+}
+
+// GetHostEndpointPolicyConfig returns configuration of host-endpoint policies.
+func (mc *MockContiv) GetHostEndpointPolicyConfig() *contiv.HostEndpointPolicyConfig {
+	return &mc.hostEndpointPolicyConfig
+}
+
+// GetPcapTracer returns the owner of the VPP pcap traces.
+func (mc *MockContiv) GetPcapTracer() *pcap.Tracer {
+	return mc.pcapTracer
+}
+
 // GetNatLoopbackIP returns the IP address of a virtual loopback, used to route traffic
 // between clients and services via VPP even if the source and destination are the same
 // IP addresses and would otherwise be routed locally.
@@ -276,6 +348,12 @@ func (mc *MockContiv) GetHostInterconnectIfName() string {
 	return mc.hostInterconnect
 }
 
+// GetHostInterconnectIP returns the IP address of the host (Linux) end of the interconnect
+// between VPP and the host stack.
+func (mc *MockContiv) GetHostInterconnectIP() net.IP {
+	return mc.hostInterconnectIP
+}
+
 // GetVxlanBVIIfName returns the name of an BVI interface facing towards VXLAN tunnels to other hosts.
 // Returns an empty string if VXLAN is not used (in L2 interconnect mode).
 func (mc *MockContiv) GetVxlanBVIIfName() string {
@@ -307,6 +385,15 @@ func (mc *MockContiv) RegisterPodPostAddHook(hook contiv.PodActionHook) {
 	mc.podPostAddHooks = append(mc.podPostAddHooks, hook)
 }
 
+// RegisterRemoteEndpointsHook allows to register callback that will be run for each
+// change in the endpoints imported from a remote cluster of the cluster mesh.
+func (mc *MockContiv) RegisterRemoteEndpointsHook(hook contiv.RemoteEndpointsHook) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	mc.remoteEndpointsHooks = append(mc.remoteEndpointsHooks, hook)
+}
+
 // CleanupIdleNATSessions returns true if cleanup of idle NAT sessions is enabled.
 func (mc *MockContiv) CleanupIdleNATSessions() bool {
 	return mc.cleanupIdleNATSessions
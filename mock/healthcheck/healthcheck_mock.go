@@ -0,0 +1,52 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+	"github.com/contiv/vpp/plugins/service/healthcheck"
+)
+
+// MockHealthCheck is a mock for the health-check server of the service plugin.
+type MockHealthCheck struct {
+	checks map[svcmodel.ID]*healthcheck.Check
+}
+
+// NewMockHealthCheck is a constructor for MockHealthCheck.
+func NewMockHealthCheck() *MockHealthCheck {
+	return &MockHealthCheck{checks: make(map[svcmodel.ID]*healthcheck.Check)}
+}
+
+// Sync stores the given set of health-checks.
+func (mhc *MockHealthCheck) Sync(checks map[svcmodel.ID]*healthcheck.Check) {
+	mhc.checks = checks
+}
+
+// Close removes all health-checks.
+func (mhc *MockHealthCheck) Close() error {
+	mhc.checks = make(map[svcmodel.ID]*healthcheck.Check)
+	return nil
+}
+
+// GetCheck returns the health-check of the given service (nil if the service
+// has no health-check responder).
+func (mhc *MockHealthCheck) GetCheck(service svcmodel.ID) *healthcheck.Check {
+	return mhc.checks[service]
+}
+
+// NumOfChecks returns the number of services with a health-check responder.
+func (mhc *MockHealthCheck) NumOfChecks() int {
+	return len(mhc.checks)
+}
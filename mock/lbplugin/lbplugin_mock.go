@@ -0,0 +1,403 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lbplugin
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	govppmock "git.fd.io/govpp.git/adapter/mock"
+	govppapi "git.fd.io/govpp.git/api"
+	"git.fd.io/govpp.git/codec"
+	govpp "git.fd.io/govpp.git/core"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/vpp-agent/plugins/vpp/binapi/vpe"
+
+	lb_api "github.com/contiv/vpp/plugins/service/renderer/binapi/lb"
+)
+
+const (
+	// errors returned by the mock, as by the VPP LB plugin
+	retvalNoSuchEntry = -6
+	retvalValueExist  = -103
+
+	// encap values of the NAT modes (other modes are not supported by the mock)
+	encapNAT4 = 3
+	encapNAT6 = 4
+
+	// showVIPsCmd is the only CLI command supported by the mock
+	showVIPsCmd = "show lb vips verbose"
+)
+
+// MockLBPlugin simulates the VPP load-balancer plugin.
+// The configured VIPs, ASs and NAT interfaces are only stored locally
+// and can be queried for testing purposes.
+type MockLBPlugin struct {
+	Log logging.Logger
+
+	vppMock  *govppmock.VppAdapter
+	vppConn  *govpp.Connection
+	vips     map[string]*VIP
+	natIfs   map[uint32]struct{}
+	nat6Ifs  map[uint32]struct{}
+	errCount int
+	reqCount int
+}
+
+// VIP is a VIP configured in the mocked LB plugin.
+type VIP struct {
+	IP         string
+	Protocol   uint8
+	Port       uint16
+	Encap      uint8
+	Type       uint8
+	TargetPort uint16
+	NodePort   uint16
+	ASs        []string
+}
+
+// NewMockLBPlugin is a constructor for MockLBPlugin.
+func NewMockLBPlugin(log logging.Logger) *MockLBPlugin {
+	var err error
+	mock := &MockLBPlugin{
+		Log:     log,
+		vppMock: govppmock.NewVppAdapter(),
+		vips:    make(map[string]*VIP),
+		natIfs:  make(map[uint32]struct{}),
+		nat6Ifs: make(map[uint32]struct{}),
+	}
+	mock.vppMock.MockReplyHandler(mock.msgReplyHandler)
+	for _, msg := range []govppapi.Message{
+		&lb_api.LbAddDelVip{}, &lb_api.LbAddDelVipReply{},
+		&lb_api.LbAddDelAs{}, &lb_api.LbAddDelAsReply{},
+		&lb_api.LbAddDelIntfNat4{}, &lb_api.LbAddDelIntfNat4Reply{},
+		&lb_api.LbAddDelIntfNat6{}, &lb_api.LbAddDelIntfNat6Reply{},
+		&vpe.CliInband{}, &vpe.CliInbandReply{},
+	} {
+		mock.vppMock.GetMsgID(msg.GetMessageName(), msg.GetCrcString())
+	}
+	mock.vppConn, err = govpp.Connect(mock.vppMock)
+	if err != nil {
+		return nil
+	}
+	return mock
+}
+
+// Clear clears the state of the mocked LB plugin.
+func (mlb *MockLBPlugin) Clear() {
+	mlb.vips = make(map[string]*VIP)
+	mlb.natIfs = make(map[uint32]struct{})
+	mlb.nat6Ifs = make(map[uint32]struct{})
+	mlb.errCount = 0
+	mlb.reqCount = 0
+}
+
+// NewVPPChan creates a new mock VPP channel.
+func (mlb *MockLBPlugin) NewVPPChan() govppapi.Channel {
+	channel, _ := mlb.vppConn.NewAPIChannel()
+	return channel
+}
+
+// GetErrCount returns the number of errors that have occurred so far.
+func (mlb *MockLBPlugin) GetErrCount() int {
+	return mlb.errCount
+}
+
+// GetReqCount returns the number of requests that have been received so far.
+func (mlb *MockLBPlugin) GetReqCount() int {
+	return mlb.reqCount
+}
+
+// NumOfVIPs returns the number of configured VIPs.
+func (mlb *MockLBPlugin) NumOfVIPs() int {
+	return len(mlb.vips)
+}
+
+// GetVIP returns the VIP with the given IP, protocol and port (nil if not configured).
+// ASs are sorted by IP address.
+func (mlb *MockLBPlugin) GetVIP(ip string, protocol uint8, port uint16) *VIP {
+	vip, exists := mlb.vips[vipKey(net.ParseIP(ip), protocol, port)]
+	if !exists {
+		return nil
+	}
+	sort.Strings(vip.ASs)
+	return vip
+}
+
+// HasNAT4Interface returns true if the NAT4 in2out feature is enabled
+// for the given interface.
+func (mlb *MockLBPlugin) HasNAT4Interface(swIfIndex uint32) bool {
+	_, enabled := mlb.natIfs[swIfIndex]
+	return enabled
+}
+
+// NumOfNAT4Interfaces returns the number of interfaces with enabled NAT4 in2out feature.
+func (mlb *MockLBPlugin) NumOfNAT4Interfaces() int {
+	return len(mlb.natIfs)
+}
+
+// HasNAT6Interface returns true if the NAT6 in2out feature is enabled
+// for the given interface.
+func (mlb *MockLBPlugin) HasNAT6Interface(swIfIndex uint32) bool {
+	_, enabled := mlb.nat6Ifs[swIfIndex]
+	return enabled
+}
+
+// NumOfNAT6Interfaces returns the number of interfaces with enabled NAT6 in2out feature.
+func (mlb *MockLBPlugin) NumOfNAT6Interfaces() int {
+	return len(mlb.nat6Ifs)
+}
+
+// msgReplyHandler handles binary API request.
+func (mlb *MockLBPlugin) msgReplyHandler(request govppmock.MessageDTO) (reply []byte, msgID uint16, prepared bool) {
+	mlb.reqCount++
+	reqName, found := mlb.vppMock.GetMsgNameByID(request.MsgID)
+	if !found {
+		mlb.errCount++
+		mlb.Log.Error("Not existing req msg name for MsgID=", request.MsgID)
+		return reply, 0, false
+	}
+	mlb.Log.Debug("MockLBPlugin msgReplyHandler ", request.MsgID, " ", reqName)
+
+	var (
+		req      govppapi.Message
+		replyMsg govppapi.Message
+		handler  func() int32
+	)
+	switch reqName {
+	case "lb_add_del_vip":
+		vipReq := &lb_api.LbAddDelVip{}
+		req, replyMsg = vipReq, &lb_api.LbAddDelVipReply{}
+		handler = func() int32 { return mlb.addDelVIP(vipReq) }
+	case "lb_add_del_as":
+		asReq := &lb_api.LbAddDelAs{}
+		req, replyMsg = asReq, &lb_api.LbAddDelAsReply{}
+		handler = func() int32 { return mlb.addDelAS(asReq) }
+	case "lb_add_del_intf_nat4":
+		intfReq := &lb_api.LbAddDelIntfNat4{}
+		req, replyMsg = intfReq, &lb_api.LbAddDelIntfNat4Reply{}
+		handler = func() int32 { return addDelNATInterface(mlb.natIfs, intfReq.IsAdd, intfReq.SwIfIndex) }
+	case "lb_add_del_intf_nat6":
+		intfReq := &lb_api.LbAddDelIntfNat6{}
+		req, replyMsg = intfReq, &lb_api.LbAddDelIntfNat6Reply{}
+		handler = func() int32 { return addDelNATInterface(mlb.nat6Ifs, intfReq.IsAdd, intfReq.SwIfIndex) }
+	case "cli_inband":
+		cliReq, cliReply := &vpe.CliInband{}, &vpe.CliInbandReply{}
+		req, replyMsg = cliReq, cliReply
+		handler = func() int32 {
+			cliReply.Reply = []byte(mlb.executeCLI(string(cliReq.Cmd)))
+			return 0
+		}
+	default:
+		mlb.Log.WithField("reqName", reqName).Warn("Unhandled request")
+		return reply, 0, false
+	}
+
+	// Decode request.
+	codec := codec.MsgCodec{}
+	err := codec.DecodeMsg(request.Data, req)
+	if err != nil {
+		mlb.errCount++
+		mlb.Log.Error(err)
+		return reply, 0, false
+	}
+	msgID, err = mlb.vppMock.GetMsgID(replyMsg.GetMessageName(), replyMsg.GetCrcString())
+	if err != nil {
+		mlb.errCount++
+		mlb.Log.Error(err)
+		return reply, 0, false
+	}
+
+	// Apply request and send response.
+	retval := handler()
+	switch r := replyMsg.(type) {
+	case *lb_api.LbAddDelVipReply:
+		r.Retval = retval
+	case *lb_api.LbAddDelAsReply:
+		r.Retval = retval
+	case *lb_api.LbAddDelIntfNat4Reply:
+		r.Retval = retval
+	case *lb_api.LbAddDelIntfNat6Reply:
+		r.Retval = retval
+	case *vpe.CliInbandReply:
+		r.Retval = retval
+	}
+	reply, err = mlb.vppMock.ReplyBytes(request, replyMsg)
+	if err != nil {
+		mlb.errCount++
+		mlb.Log.Error(err)
+		return reply, 0, false
+	}
+	return reply, msgID, true
+}
+
+// addDelVIP adds or removes VIP.
+func (mlb *MockLBPlugin) addDelVIP(req *lb_api.LbAddDelVip) int32 {
+	ip, err := lbAddressToIP(req.IPPrefix, req.PrefixLength)
+	if err != nil {
+		mlb.errCount++
+		mlb.Log.Error(err)
+		return -1
+	}
+	key := vipKey(ip, req.Protocol, req.Port)
+	vip, exists := mlb.vips[key]
+	if req.IsDel == 0 {
+		if exists {
+			return retvalValueExist
+		}
+		mlb.vips[key] = &VIP{
+			IP:         ip.String(),
+			Protocol:   req.Protocol,
+			Port:       req.Port,
+			Encap:      req.Encap,
+			Type:       req.Type,
+			TargetPort: req.TargetPort,
+			NodePort:   req.NodePort,
+			ASs:        []string{},
+		}
+		return 0
+	}
+	if !exists {
+		return retvalNoSuchEntry
+	}
+	if len(vip.ASs) > 0 {
+		mlb.errCount++
+		mlb.Log.WithField("vip", key).Warn("Cannot remove VIP with ASs")
+		return -1
+	}
+	delete(mlb.vips, key)
+	return 0
+}
+
+// addDelAS adds or removes AS of a VIP.
+func (mlb *MockLBPlugin) addDelAS(req *lb_api.LbAddDelAs) int32 {
+	ip, err := lbAddressToIP(req.VipIPPrefix, req.VipPrefixLength)
+	if err != nil {
+		mlb.errCount++
+		mlb.Log.Error(err)
+		return -1
+	}
+	asIP, err := lbAddressToIP(req.AsAddress, 128)
+	if err != nil {
+		mlb.errCount++
+		mlb.Log.Error(err)
+		return -1
+	}
+	key := vipKey(ip, req.Protocol, req.Port)
+	vip, exists := mlb.vips[key]
+	if !exists {
+		mlb.errCount++
+		mlb.Log.WithField("vip", key).Warn("AS for non-existing VIP")
+		return retvalNoSuchEntry
+	}
+	as := asIP.String()
+	for idx, vipAS := range vip.ASs {
+		if vipAS == as {
+			if req.IsDel == 0 {
+				return retvalValueExist
+			}
+			vip.ASs = append(vip.ASs[:idx], vip.ASs[idx+1:]...)
+			return 0
+		}
+	}
+	if req.IsDel != 0 {
+		return retvalNoSuchEntry
+	}
+	vip.ASs = append(vip.ASs, as)
+	return 0
+}
+
+// executeCLI returns the output of the given CLI command, formatted as by VPP.
+func (mlb *MockLBPlugin) executeCLI(cmd string) string {
+	if strings.TrimSpace(cmd) != showVIPsCmd {
+		return fmt.Sprintf("unknown input `%s'\n", cmd)
+	}
+	var keys []string
+	for key := range mlb.vips {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var output strings.Builder
+	for idx, key := range keys {
+		vip := mlb.vips[key]
+		vipType := "ip4-gre4"
+		prefixLen := 32
+		switch vip.Encap {
+		case encapNAT4:
+			vipType = "ip4-nat4"
+		case encapNAT6:
+			vipType = "ip6-nat6"
+			prefixLen = 128
+		}
+		fmt.Fprintf(&output, " %s [%d] %s/%d\n", vipType, idx+1, vip.IP, prefixLen)
+		fmt.Fprintf(&output, "   new_size:1024\n")
+		fmt.Fprintf(&output, "   protocol:%d port:%d \n", vip.Protocol, vip.Port)
+		srvType := "clusterip"
+		if vip.NodePort != 0 {
+			srvType = "nodeport"
+		}
+		fmt.Fprintf(&output, "   type:%s port:%d target_port:%d", srvType, vip.Port, vip.TargetPort)
+		if vip.NodePort != 0 {
+			fmt.Fprintf(&output, " node_port:%d", vip.NodePort)
+		}
+		fmt.Fprintf(&output, "\n   counters:\n     packet from existing sessions: 0\n")
+		fmt.Fprintf(&output, "   #as:%d\n", len(vip.ASs))
+		for asIdx, as := range vip.ASs {
+			fmt.Fprintf(&output, "     %s %d buckets   0 flows  dpo:%d used\n", as, 1024/len(vip.ASs), asIdx)
+		}
+	}
+	return output.String()
+}
+
+// addDelNATInterface enables or disables the NAT4/NAT6 in2out feature for an interface.
+func addDelNATInterface(natIfs map[uint32]struct{}, isAdd uint8, swIfIndex uint32) int32 {
+	_, enabled := natIfs[swIfIndex]
+	if isAdd != 0 {
+		if enabled {
+			return retvalValueExist
+		}
+		natIfs[swIfIndex] = struct{}{}
+		return 0
+	}
+	if !enabled {
+		return retvalNoSuchEntry
+	}
+	delete(natIfs, swIfIndex)
+	return 0
+}
+
+// vipKey returns key identifying VIP.
+func vipKey(ip net.IP, protocol uint8, port uint16) string {
+	return fmt.Sprintf("%s:%d/%d", ip.String(), port, protocol)
+}
+
+// lbAddressToIP converts address from the LB plugin binary API into IP
+// (only host prefixes are supported by the mock).
+func lbAddressToIP(addr []byte, prefixLen uint8) (net.IP, error) {
+	if len(addr) != net.IPv6len || prefixLen != 128 {
+		return nil, fmt.Errorf("unsupported VIP prefix: %v/%d", addr, prefixLen)
+	}
+	for _, b := range addr[:net.IPv6len-net.IPv4len] {
+		if b != 0 {
+			return net.IP(append([]byte{}, addr...)), nil
+		}
+	}
+	// IPv4 in the lower order 32 bits
+	return net.IP(append([]byte{}, addr[net.IPv6len-net.IPv4len:]...)), nil
+}
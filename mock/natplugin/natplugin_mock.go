@@ -235,6 +235,12 @@ func (mnt *MockNatPlugin) ApplyTxn(txn *localclient.Txn, latestRevs *syncbase.Pr
 						return err
 					}
 					mnt.staticMappings.Subtract(oldSms)
+					oldIms, err := mnt.dnatToIdentityMappings(prevDnatConfig)
+					if err != nil {
+						return err
+					}
+					mnt.identityMappings.Subtract(oldIms)
+					delete(mnt.nat44Dnat, label)
 				} else {
 					return errors.New("attempt to remove DNAT config which does not exist")
 				}
@@ -275,6 +281,8 @@ func (mnt *MockNatPlugin) dnatToStaticMappings(dnat *nat.Nat44DNat_DNatConfig) (
 			sm.Protocol = renderer.TCP
 		case nat.Protocol_UDP:
 			sm.Protocol = renderer.UDP
+		case nat.Protocol_SCTP:
+			sm.Protocol = renderer.SCTP
 		case nat.Protocol_ICMP:
 			return nil, errors.New("unexpected static mapping for the ICMP protocol")
 		}
@@ -294,8 +302,11 @@ func (mnt *MockNatPlugin) dnatToStaticMappings(dnat *nat.Nat44DNat_DNatConfig) (
 			if local.LocalPort > uint32(^uint16(0)) {
 				return nil, errors.New("invalid local port number")
 			}
-			if (staticMapping.ExternalPort != 0 && (local.Probability == 0 || local.Probability > uint32(^uint8(0)))) ||
-				(staticMapping.ExternalPort == 0 && local.Probability != 0) {
+			// probability is not configured for mappings with a single local
+			singleLocal := len(staticMapping.LocalIps) == 1
+			if (staticMapping.ExternalPort != 0 && !singleLocal && local.Probability == 0) ||
+				local.Probability > uint32(^uint8(0)) ||
+				((staticMapping.ExternalPort == 0 || singleLocal) && local.Probability != 0) {
 				return nil, errors.New("invalid local probability")
 			}
 			sm.Locals = append(sm.Locals, &Local{
@@ -340,6 +351,8 @@ func (mnt *MockNatPlugin) dnatToIdentityMappings(dnat *nat.Nat44DNat_DNatConfig)
 			im.Protocol = renderer.TCP
 		case nat.Protocol_UDP:
 			im.Protocol = renderer.UDP
+		case nat.Protocol_SCTP:
+			im.Protocol = renderer.SCTP
 		case nat.Protocol_ICMP:
 			return nil, errors.New("unexpected identity mapping for the ICMP protocol")
 		}
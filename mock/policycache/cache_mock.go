@@ -3,7 +3,9 @@ package policycache
 import (
 	"github.com/ligato/cn-infra/datasync"
 
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
 	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	nodemodel "github.com/contiv/vpp/plugins/ksr/model/node"
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	"github.com/contiv/vpp/plugins/policy/cache"
@@ -71,6 +73,11 @@ func (mpc *MockPolicyCache) LookupPodsByNamespace(policyNamespace string) (pods
 	return nil
 }
 
+// LookupPodsByNsAndPodSelector is not implemented by the mock.
+func (mpc *MockPolicyCache) LookupPodsByNsAndPodSelector(nsLabelSelector, podLabelSelector *policymodel.Policy_LabelSelector) (pods []podmodel.ID) {
+	return nil
+}
+
 // ListAllPods is not implemented by the mock.
 func (mpc *MockPolicyCache) ListAllPods() (pods []podmodel.ID) {
 	return nil
@@ -86,11 +93,46 @@ func (mpc *MockPolicyCache) LookupPoliciesByPod(pod podmodel.ID) (policies []pol
 	return nil
 }
 
+// LookupPoliciesReferencingPod is not implemented by the mock.
+func (mpc *MockPolicyCache) LookupPoliciesReferencingPod(pod *podmodel.Pod) (policies []policymodel.ID) {
+	return nil
+}
+
+// LookupPoliciesReferencingNamespace is not implemented by the mock.
+func (mpc *MockPolicyCache) LookupPoliciesReferencingNamespace(ns *nsmodel.Namespace) (policies []policymodel.ID) {
+	return nil
+}
+
 // ListAllPolicies is not implemented by the mock.
 func (mpc *MockPolicyCache) ListAllPolicies() (policies []policymodel.ID) {
 	return nil
 }
 
+// LookupClusterPolicy is not implemented by the mock.
+func (mpc *MockPolicyCache) LookupClusterPolicy(policy string) (found bool, data *clusterpolicymodel.ClusterPolicy) {
+	return false, nil
+}
+
+// ListAllClusterPolicies is not implemented by the mock.
+func (mpc *MockPolicyCache) ListAllClusterPolicies() (policies []string) {
+	return nil
+}
+
+// LookupHostEndpointPolicy is not implemented by the mock.
+func (mpc *MockPolicyCache) LookupHostEndpointPolicy(policy string) (found bool, data *clusterpolicymodel.HostEndpointPolicy) {
+	return false, nil
+}
+
+// ListAllHostEndpointPolicies is not implemented by the mock.
+func (mpc *MockPolicyCache) ListAllHostEndpointPolicies() (policies []string) {
+	return nil
+}
+
+// LookupNode is not implemented by the mock.
+func (mpc *MockPolicyCache) LookupNode(node string) (found bool, data *nodemodel.Node) {
+	return false, nil
+}
+
 // LookupNamespace is not implemented by the mock.
 func (mpc *MockPolicyCache) LookupNamespace(namespace nsmodel.ID) (found bool, data *nsmodel.Namespace) {
 	return false, data
@@ -1,6 +1,7 @@
 package renderer
 
 import (
+	"errors"
 	"net"
 
 	"sync"
@@ -44,6 +45,13 @@ type MockRenderer struct {
 	name   string
 	Log    logging.Logger
 	config map[podmodel.ID]*PodConfig // Pod ID -> config
+
+	hostRules []*renderer.ContivRule
+	lbRules   []*renderer.ContivRule
+	preNAT    map[podmodel.ID][]*renderer.ContivRule
+
+	failCommit bool
+	commits    int
 }
 
 // MockRendererTxn is a mock implementation for the renderer's transaction.
@@ -52,11 +60,17 @@ type MockRendererTxn struct {
 	renderer *MockRenderer
 	resync   bool
 	config   map[podmodel.ID]*PodConfig // Pod ID -> config
+
+	hostRules    []*renderer.ContivRule
+	hostRendered bool
+	lbRules      []*renderer.ContivRule
+	lbRendered   bool
+	preNAT       map[podmodel.ID][]*renderer.ContivRule
 }
 
 // PodConfig stores configuration for a single pod.
 type PodConfig struct {
-	ip      *net.IPNet
+	ips     []*net.IPNet
 	ingress []*renderer.ContivRule
 	egress  []*renderer.ContivRule
 }
@@ -67,6 +81,7 @@ func NewMockRenderer(name string, log logging.Logger) *MockRenderer {
 		name:   name,
 		Log:    log,
 		config: make(map[podmodel.ID]*PodConfig),
+		preNAT: make(map[podmodel.ID][]*renderer.ContivRule),
 	}
 }
 
@@ -77,10 +92,11 @@ func (mr *MockRenderer) NewTxn(resync bool) renderer.Txn {
 		renderer: mr,
 		resync:   resync,
 		config:   make(map[podmodel.ID]*PodConfig),
+		preNAT:   make(map[podmodel.ID][]*renderer.ContivRule),
 	}
 }
 
-// GetPodIP returns the pod IP + masklen as provided by the configurator.
+// GetPodIP returns the (primary) pod IP + masklen as provided by the configurator.
 func (mr *MockRenderer) GetPodIP(pod podmodel.ID) (ip string, masklen int) {
 	mr.Log.WithFields(logging.Fields{
 		"renderer": mr.name,
@@ -92,16 +108,38 @@ func (mr *MockRenderer) GetPodIP(pod podmodel.ID) (ip string, masklen int) {
 	if !hasInterface {
 		return "", 0
 	}
-	if config.ip == nil {
+	if len(config.ips) == 0 {
 		return "", 0
 	}
-	masklen, _ = config.ip.Mask.Size()
-	return config.ip.IP.String(), masklen
+	masklen, _ = config.ips[0].Mask.Size()
+	return config.ips[0].IP.String(), masklen
+}
+
+// GetPodIPs returns all pod IPs as provided by the configurator.
+func (mr *MockRenderer) GetPodIPs(pod podmodel.ID) (ips []*net.IPNet) {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	if config, hasInterface := mr.config[pod]; hasInterface {
+		ips = config.ips
+	}
+	return ips
+}
+
+// GetPodRules returns ingress and egress rules as provided by the configurator.
+func (mr *MockRenderer) GetPodRules(pod podmodel.ID) (ingress, egress []*renderer.ContivRule) {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	if config, hasInterface := mr.config[pod]; hasInterface {
+		ingress = config.ingress
+		egress = config.egress
+	}
+	return ingress, egress
 }
 
 // TestTraffic allows to simulate a traffic and test what the outcome would
 // be with the rendered configuration.
 // The direction is from the vswitch point of view!
+// For ICMP, <srcPort> and <destPort> carry the ICMP type and code, respectively.
 func (mr *MockRenderer) TestTraffic(pod podmodel.ID, direction TrafficDirection, srcIP *net.IP,
 	destIP *net.IP, protocol renderer.ProtocolType, srcPort uint16, destPort uint16) TrafficAction {
 	mr.lock.Lock()
@@ -130,10 +168,19 @@ func (mr *MockRenderer) TestTraffic(pod podmodel.ID, direction TrafficDirection,
 			if rule.Protocol != protocol {
 				continue
 			}
-			if rule.SrcPort != 0 && rule.SrcPort != srcPort {
+			if rule.Protocol == renderer.ICMP {
+				if (rule.ICMPType != renderer.AnyICMP && rule.ICMPType != int16(srcPort)) ||
+					(rule.ICMPCode != renderer.AnyICMP && rule.ICMPCode != int16(destPort)) {
+					continue
+				}
+			} else if rule.SrcPort != 0 && rule.SrcPort != srcPort {
 				continue
 			}
-			if rule.DestPort != 0 && rule.DestPort != destPort {
+			if rule.DestPort != 0 && rule.DestPortEnd > rule.DestPort {
+				if destPort < rule.DestPort || destPort > rule.DestPortEnd {
+					continue
+				}
+			} else if rule.DestPort != 0 && rule.DestPort != destPort {
 				continue
 			}
 		}
@@ -147,11 +194,11 @@ func (mr *MockRenderer) TestTraffic(pod podmodel.ID, direction TrafficDirection,
 }
 
 // Render just stores config to be rendered.
-func (mrt *MockRendererTxn) Render(pod podmodel.ID, podIP *net.IPNet, ingress []*renderer.ContivRule, egress []*renderer.ContivRule, removed bool) renderer.Txn {
+func (mrt *MockRendererTxn) Render(pod podmodel.ID, podIPs []*net.IPNet, ingress []*renderer.ContivRule, egress []*renderer.ContivRule, removed bool) renderer.Txn {
 	mrt.Log.WithFields(logging.Fields{
 		"renderer": mrt.renderer.name,
 		"pod":      pod,
-		"IP":       podIP,
+		"IPs":      podIPs,
 		"ingress":  ingress,
 		"egress":   egress,
 		"removed":  removed,
@@ -161,11 +208,82 @@ func (mrt *MockRendererTxn) Render(pod podmodel.ID, podIP *net.IPNet, ingress []
 			delete(mrt.config, pod)
 		}
 	} else {
-		mrt.config[pod] = &PodConfig{ip: podIP, ingress: ingress, egress: egress}
+		mrt.config[pod] = &PodConfig{ips: podIPs, ingress: ingress, egress: egress}
 	}
 	return mrt
 }
 
+// RenderHost just stores host rules to be rendered.
+func (mrt *MockRendererTxn) RenderHost(rules []*renderer.ContivRule) renderer.Txn {
+	mrt.Log.WithFields(logging.Fields{
+		"renderer": mrt.renderer.name,
+		"rules":    rules,
+	}).Debug("Mock RendererTxn RenderHost()")
+	mrt.hostRules = rules
+	mrt.hostRendered = true
+	return mrt
+}
+
+// GetHostRules returns the rendered rules for the traffic entering the host.
+func (mr *MockRenderer) GetHostRules() []*renderer.ContivRule {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	return mr.hostRules
+}
+
+// RenderLoadBalancers just stores load-balancer rules to be rendered.
+func (mrt *MockRendererTxn) RenderLoadBalancers(rules []*renderer.ContivRule) renderer.Txn {
+	mrt.Log.WithFields(logging.Fields{
+		"renderer": mrt.renderer.name,
+		"rules":    rules,
+	}).Debug("Mock RendererTxn RenderLoadBalancers()")
+	mrt.lbRules = rules
+	mrt.lbRendered = true
+	return mrt
+}
+
+// GetLoadBalancerRules returns the rendered rules for the traffic destined
+// to the load-balancer IPs.
+func (mr *MockRenderer) GetLoadBalancerRules() []*renderer.ContivRule {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	return mr.lbRules
+}
+
+// RenderPreNAT just stores pre-NAT rules to be rendered.
+func (mrt *MockRendererTxn) RenderPreNAT(pod podmodel.ID, rules []*renderer.ContivRule) renderer.Txn {
+	mrt.Log.WithFields(logging.Fields{
+		"renderer": mrt.renderer.name,
+		"pod":      pod,
+		"rules":    rules,
+	}).Debug("Mock RendererTxn RenderPreNAT()")
+	mrt.preNAT[pod] = rules
+	return mrt
+}
+
+// GetPreNATRules returns the rendered rules for the traffic sent by the given
+// pod, evaluated before the service address translation.
+func (mr *MockRenderer) GetPreNATRules(pod podmodel.ID) []*renderer.ContivRule {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	return mr.preNAT[pod]
+}
+
+// InjectFailure makes the next commit fail with *renderer.CommitError.
+// The configuration of the failed transaction is not applied.
+func (mr *MockRenderer) InjectFailure() {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	mr.failCommit = true
+}
+
+// GetCommitCount returns the number of successfully committed transactions.
+func (mr *MockRenderer) GetCommitCount() int {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	return mr.commits
+}
+
 // Commit runs mock rendering. The configuration is just stored in-memory.
 func (mrt *MockRendererTxn) Commit() error {
 	mrt.Log.WithFields(logging.Fields{
@@ -174,6 +292,11 @@ func (mrt *MockRendererTxn) Commit() error {
 
 	mrt.renderer.lock.Lock()
 	defer mrt.renderer.lock.Unlock()
+	if mrt.renderer.failCommit {
+		mrt.renderer.failCommit = false
+		return &renderer.CommitError{Renderer: mrt.renderer.name, Err: errors.New("injected failure")}
+	}
+	mrt.renderer.commits++
 	if mrt.resync {
 		mrt.renderer.config = mrt.config
 	} else {
@@ -181,5 +304,21 @@ func (mrt *MockRendererTxn) Commit() error {
 			mrt.renderer.config[ifName] = config
 		}
 	}
+	if mrt.hostRendered {
+		mrt.renderer.hostRules = mrt.hostRules
+	}
+	if mrt.lbRendered {
+		mrt.renderer.lbRules = mrt.lbRules
+	}
+	if mrt.resync {
+		mrt.renderer.preNAT = make(map[podmodel.ID][]*renderer.ContivRule)
+	}
+	for pod, rules := range mrt.preNAT {
+		if len(rules) == 0 {
+			delete(mrt.renderer.preNAT, pod)
+		} else {
+			mrt.renderer.preNAT[pod] = rules
+		}
+	}
 	return nil
 }
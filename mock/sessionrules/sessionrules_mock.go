@@ -31,6 +31,10 @@ type MockSessionRules struct {
 	globalTable SessionRules
 	errCount    int
 	reqCount    int
+
+	// failure injection
+	failReq      bool
+	failAfterReq int
 }
 
 // LocalTableCheck allows to check the content of a local table.
@@ -77,6 +81,15 @@ func (msr *MockSessionRules) Clear() {
 	msr.globalTable = SessionRules{}
 	msr.errCount = 0
 	msr.reqCount = 0
+	msr.failReq = false
+}
+
+// InjectFailure makes a session rule add/del request fail (with non-zero
+// retval) after *afterReqs* further add/del requests were applied.
+// The injected failure is not counted as an error.
+func (msr *MockSessionRules) InjectFailure(afterReqs int) {
+	msr.failReq = true
+	msr.failAfterReq = afterReqs
 }
 
 // NewVPPChan creates a new mock VPP channel.
@@ -299,6 +312,17 @@ func (msr *MockSessionRules) msgReplyHandler(request govppmock.MessageDTO) (repl
 			retval = 1
 		}
 
+		// Injected failure.
+		if retval == 0 && msr.failReq {
+			if msr.failAfterReq == 0 {
+				msr.failReq = false
+				msr.Log.WithField("rule", rule).Debug("Injected failure")
+				retval = -1
+			} else {
+				msr.failAfterReq--
+			}
+		}
+
 		// Add/Delete rule.
 		if retval == 0 {
 			var ok bool
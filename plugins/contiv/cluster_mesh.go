@@ -0,0 +1,600 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/contiv/vpp/plugins/ksr"
+	epmodel "github.com/contiv/vpp/plugins/ksr/model/endpoints"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/db/keyval/etcd"
+	"github.com/ligato/cn-infra/db/keyval/kvproto"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/servicelabel"
+)
+
+const (
+	// remoteClusterRetryInterval is the period after which the connection to the etcd
+	// of a remote cluster is re-attempted if it has failed.
+	remoteClusterRetryInterval = 10 * time.Second
+)
+
+// ClusterMeshConfig configures the cluster mesh - pod-to-pod connectivity between
+// multiple Contiv clusters.
+type ClusterMeshConfig struct {
+	ClusterID      string                // identifier of this cluster; nodes are exported for the other clusters only if set
+	RemoteClusters []RemoteClusterConfig // remote clusters to import the node records from
+}
+
+// RemoteClusterConfig describes where to import the node records of a remote cluster from.
+type RemoteClusterConfig struct {
+	ClusterID  string       // identifier of the remote cluster
+	Etcd       *etcd.Config // access to the etcd of the remote cluster, including the TLS settings
+	KeyPrefix  string       // prefix under which the remote KSR publishes its data, the default KSR prefix is used if empty
+	ExportFile string       // path to a static (JSON) export of the remote cluster, used if Etcd is not defined
+}
+
+// ClusterExport is a static export of a Contiv cluster, which can be imported
+// by other clusters of the mesh using RemoteClusterConfig.ExportFile.
+type ClusterExport struct {
+	Nodes     []*node.ExportedNodeInfo `json:"nodes"`
+	Endpoints []*epmodel.Endpoints     `json:"endpoints"`
+}
+
+// clusterMesh exports the nodes of this cluster for the remote clusters of the mesh
+// and imports the nodes (and service endpoints) of the remote clusters, installing
+// tunnels and routes towards their pod networks.
+type clusterMesh struct {
+	sync.Mutex
+	logging.Logger
+
+	config *ClusterMeshConfig
+	server *remoteCNIserver
+
+	// broker for the KSR prefix of the local etcd, used to export nodes of this cluster
+	exportBroker keyval.ProtoBroker
+
+	// node records accepted from the remote clusters, key = remoteNodeKey()
+	remoteNodes map[string]*node.ExportedNodeInfo
+
+	endpointsHooks []RemoteEndpointsHook
+
+	// connections to the etcd of the remote clusters
+	connections []*remoteConnection
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// remoteConnection is a connection to the etcd of a remote cluster together with
+// the channel that stops the watcher registered over it.
+type remoteConnection struct {
+	conn    *etcd.BytesConnectionEtcd
+	closeCh chan string
+}
+
+// close stops the watcher and closes the connection.
+func (rc *remoteConnection) close() {
+	close(rc.closeCh)
+	rc.conn.Close()
+}
+
+// newClusterMesh creates a new instance of clusterMesh.
+func newClusterMesh(logger logging.Logger, config *ClusterMeshConfig, server *remoteCNIserver,
+	exportBroker keyval.ProtoBroker) *clusterMesh {
+
+	mesh := &clusterMesh{
+		Logger:       logger,
+		config:       config,
+		server:       server,
+		exportBroker: exportBroker,
+		remoteNodes:  make(map[string]*node.ExportedNodeInfo),
+	}
+	mesh.ctx, mesh.cancel = context.WithCancel(context.Background())
+	return mesh
+}
+
+// start starts importing the data of all configured remote clusters.
+func (m *clusterMesh) start() error {
+	for i := range m.config.RemoteClusters {
+		remote := m.config.RemoteClusters[i]
+		if remote.ClusterID == "" {
+			return fmt.Errorf("cluster ID of a remote cluster is not defined")
+		}
+		if remote.ClusterID == m.config.ClusterID {
+			return fmt.Errorf("remote cluster %v has the same ID as this cluster", remote.ClusterID)
+		}
+
+		switch {
+		case remote.Etcd != nil:
+			go m.watchRemoteCluster(remote)
+		case remote.ExportFile != "":
+			export, err := loadClusterExport(remote.ExportFile)
+			if err != nil {
+				return err
+			}
+			go m.importClusterExport(remote.ClusterID, export)
+		default:
+			return fmt.Errorf("neither etcd nor export file defined for remote cluster %v", remote.ClusterID)
+		}
+	}
+	return nil
+}
+
+// close stops watching the remote clusters.
+func (m *clusterMesh) close() {
+	m.cancel()
+
+	m.Lock()
+	defer m.Unlock()
+	for _, rc := range m.connections {
+		rc.close()
+	}
+	m.connections = nil
+}
+
+// registerEndpointsHook registers callback for the changes in the endpoints of remote clusters.
+func (m *clusterMesh) registerEndpointsHook(hook RemoteEndpointsHook) {
+	m.Lock()
+	defer m.Unlock()
+	m.endpointsHooks = append(m.endpointsHooks, hook)
+}
+
+// exportNode publishes the info about this node for the remote clusters of the mesh.
+func (m *clusterMesh) exportNode(nodeInfo *node.NodeInfo) error {
+	if m.config.ClusterID == "" || nodeInfo.IpAddress == "" {
+		return nil
+	}
+	ipam := m.server.ipam
+	exported := &node.ExportedNodeInfo{
+		ClusterId:           m.config.ClusterID,
+		Id:                  nodeInfo.Id,
+		Name:                nodeInfo.Name,
+		IpAddress:           nodeInfo.IpAddress,
+		ManagementIpAddress: nodeInfo.ManagementIpAddress,
+		PodNetwork:          ipam.PodNetwork().String(),
+		VppHostNetwork:      ipam.VPPHostNetwork().String(),
+	}
+	if !m.server.useL2Interconnect {
+		vxlanIP, err := ipam.VxlanIPAddress(nodeInfo.Id)
+		if err != nil {
+			return err
+		}
+		exported.VxlanIpAddress = vxlanIP.String()
+	}
+	m.Logger.WithField("node", exported).Info("Exporting node for the cluster mesh")
+	return m.exportBroker.Put(node.ExportedNodeKey(nodeInfo.Id), exported)
+}
+
+// unexportNode withdraws the exported info about a node removed from this cluster.
+func (m *clusterMesh) unexportNode(nodeID uint32) error {
+	if m.config.ClusterID == "" {
+		return nil
+	}
+	m.Logger.WithField("nodeID", nodeID).Info("Withdrawing exported node from the cluster mesh")
+	_, err := m.exportBroker.Delete(node.ExportedNodeKey(nodeID))
+	return err
+}
+
+// watchRemoteCluster connects to the etcd of a remote cluster and keeps importing
+// its nodes and endpoints. Connection is re-attempted until the mesh is closed.
+func (m *clusterMesh) watchRemoteCluster(remote RemoteClusterConfig) {
+	for {
+		err := m.connectRemoteCluster(remote)
+		if err == nil {
+			return
+		}
+		m.Logger.WithField("cluster", remote.ClusterID).Errorf("Failed to import remote cluster: %v", err)
+
+		select {
+		case <-time.After(remoteClusterRetryInterval):
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// connectRemoteCluster starts watching and lists the current state of a remote cluster.
+// If the attempt fails, the connection and the watcher are closed before returning.
+func (m *clusterMesh) connectRemoteCluster(remote RemoteClusterConfig) (err error) {
+	clientCfg, err := etcd.ConfigToClient(remote.Etcd)
+	if err != nil {
+		return err
+	}
+	conn, err := etcd.NewEtcdConnectionWithBytes(*clientCfg, m.Logger)
+	if err != nil {
+		return err
+	}
+	rc := &remoteConnection{conn: conn, closeCh: make(chan string)}
+	defer func() {
+		if err != nil {
+			rc.close()
+		}
+	}()
+
+	keyPrefix := remote.KeyPrefix
+	if keyPrefix == "" {
+		keyPrefix = servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)
+	}
+	db := kvproto.NewProtoWrapper(conn)
+
+	// start watching before listing, so that no change gets lost in between
+	// (re-applying the same record is harmless)
+	err = db.NewWatcher(keyPrefix).Watch(func(resp keyval.ProtoWatchResp) {
+		m.handleRemoteChange(remote.ClusterID, resp)
+	}, rc.closeCh, node.ExportedNodesKeyPrefix, epmodel.KeyPrefix())
+	if err != nil {
+		return err
+	}
+
+	broker := db.NewBroker(keyPrefix)
+	export := &ClusterExport{}
+	it, err := broker.ListValues(node.ExportedNodesKeyPrefix)
+	if err != nil {
+		return err
+	}
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		nodeInfo := &node.ExportedNodeInfo{}
+		if err := kv.GetValue(nodeInfo); err != nil {
+			return err
+		}
+		export.Nodes = append(export.Nodes, nodeInfo)
+	}
+	it, err = broker.ListValues(epmodel.KeyPrefix())
+	if err != nil {
+		return err
+	}
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		eps := &epmodel.Endpoints{}
+		if err := kv.GetValue(eps); err != nil {
+			return err
+		}
+		export.Endpoints = append(export.Endpoints, eps)
+	}
+
+	m.Lock()
+	if m.ctx.Err() != nil {
+		// the mesh was closed in the meantime
+		m.Unlock()
+		rc.close()
+		return nil
+	}
+	m.connections = append(m.connections, rc)
+	m.Unlock()
+
+	m.importClusterExport(remote.ClusterID, export)
+	return nil
+}
+
+// handleRemoteChange processes a change in the data of a remote cluster.
+func (m *clusterMesh) handleRemoteChange(clusterID string, resp keyval.ProtoWatchResp) {
+	var err error
+	key := resp.GetKey()
+
+	if strings.HasPrefix(key, node.ExportedNodesKeyPrefix) {
+		if resp.GetChangeType() == datasync.Delete {
+			id, parseErr := strconv.ParseUint(strings.TrimPrefix(key, node.ExportedNodesKeyPrefix), 10, 32)
+			if parseErr != nil {
+				m.Logger.Warnf("Invalid key of exported node: %v", key)
+				return
+			}
+			err = m.removeRemoteNode(clusterID, uint32(id))
+		} else {
+			nodeInfo := &node.ExportedNodeInfo{}
+			if err = resp.GetValue(nodeInfo); err == nil {
+				err = m.addRemoteNode(clusterID, nodeInfo)
+			}
+		}
+	} else {
+		name, namespace, parseErr := epmodel.ParseEndpointsFromKey(key)
+		if parseErr != nil {
+			return
+		}
+		epsID := epmodel.ID{Name: name, Namespace: namespace}
+		if resp.GetChangeType() == datasync.Delete {
+			m.notifyRemoteEndpoints(clusterID, epsID, nil)
+		} else {
+			eps := &epmodel.Endpoints{}
+			if err = resp.GetValue(eps); err == nil {
+				m.notifyRemoteEndpoints(clusterID, epsID, eps)
+			}
+		}
+	}
+
+	if err != nil {
+		m.Logger.WithFields(logging.Fields{"cluster": clusterID, "key": key}).Error(err)
+	}
+}
+
+// importClusterExport applies the (current) state of a remote cluster.
+// Nodes imported from the cluster earlier (e.g. before a reconnect) which are not
+// part of the export anymore are removed.
+func (m *clusterMesh) importClusterExport(clusterID string, export *ClusterExport) {
+	exported := make(map[string]struct{})
+	for _, nodeInfo := range export.Nodes {
+		exported[remoteNodeKey(clusterID, nodeInfo.Id)] = struct{}{}
+	}
+	for _, nodeID := range m.staleRemoteNodes(clusterID, exported) {
+		if err := m.removeRemoteNode(clusterID, nodeID); err != nil {
+			m.Logger.WithField("cluster", clusterID).Error(err)
+		}
+	}
+
+	for _, nodeInfo := range export.Nodes {
+		if err := m.addRemoteNode(clusterID, nodeInfo); err != nil {
+			m.Logger.WithField("cluster", clusterID).Error(err)
+		}
+	}
+	for _, eps := range export.Endpoints {
+		m.notifyRemoteEndpoints(clusterID, epmodel.GetID(eps), eps)
+	}
+}
+
+// staleRemoteNodes returns IDs of the nodes imported from the given cluster
+// which are not among the exported ones.
+func (m *clusterMesh) staleRemoteNodes(clusterID string, exported map[string]struct{}) (stale []uint32) {
+	m.Lock()
+	defer m.Unlock()
+	for key, nodeInfo := range m.remoteNodes {
+		if nodeInfo.ClusterId != clusterID {
+			continue
+		}
+		if _, isExported := exported[key]; !isExported {
+			stale = append(stale, nodeInfo.Id)
+		}
+	}
+	return stale
+}
+
+// addRemoteNode validates the record of a remote node and configures connectivity towards it.
+func (m *clusterMesh) addRemoteNode(clusterID string, nodeInfo *node.ExportedNodeInfo) error {
+	if nodeInfo.ClusterId != "" && nodeInfo.ClusterId != clusterID {
+		return fmt.Errorf("node %v exported by cluster %v is imported as a node of cluster %v",
+			nodeInfo.Name, nodeInfo.ClusterId, clusterID)
+	}
+	nodeInfo.ClusterId = clusterID
+
+	// do not handle remote nodes until the base vswitch config is successfully applied;
+	// wait before taking the mesh lock, so that removals and closing are not blocked
+	m.server.waitForVswitchConnectivity()
+
+	m.Lock()
+	defer m.Unlock()
+
+	key := remoteNodeKey(clusterID, nodeInfo.Id)
+	prevNodeInfo, exists := m.remoteNodes[key]
+	if exists && nodeInfo.String() == prevNodeInfo.String() {
+		return nil
+	}
+	if err := m.validateRemoteNode(nodeInfo); err != nil {
+		return err
+	}
+
+	if exists {
+		delete(m.remoteNodes, key)
+		if err := m.server.deleteRoutesToRemoteNode(prevNodeInfo); err != nil {
+			return err
+		}
+	}
+	m.Logger.WithField("node", nodeInfo).Info("Remote node discovered")
+	if err := m.server.addRoutesToRemoteNode(nodeInfo); err != nil {
+		return err
+	}
+	m.remoteNodes[key] = nodeInfo
+	return nil
+}
+
+// removeRemoteNode removes connectivity towards a node of a remote cluster.
+func (m *clusterMesh) removeRemoteNode(clusterID string, nodeID uint32) error {
+	m.Lock()
+	defer m.Unlock()
+
+	key := remoteNodeKey(clusterID, nodeID)
+	nodeInfo, exists := m.remoteNodes[key]
+	if !exists {
+		// never accepted
+		return nil
+	}
+	m.Logger.WithField("node", nodeInfo).Info("Remote node removed")
+	delete(m.remoteNodes, key)
+	return m.server.deleteRoutesToRemoteNode(nodeInfo)
+}
+
+// validateRemoteNode checks that the networks of a remote node are valid and that they
+// do not overlap with the networks of this cluster and of the other imported nodes.
+func (m *clusterMesh) validateRemoteNode(nodeInfo *node.ExportedNodeInfo) error {
+	if nodeInfo.IpAddress == "" {
+		return fmt.Errorf("IP address of remote node %v/%v is not known", nodeInfo.ClusterId, nodeInfo.Name)
+	}
+	if !m.server.useL2Interconnect && net.ParseIP(m.server.ipPrefixToAddress(nodeInfo.VxlanIpAddress)) == nil {
+		return fmt.Errorf("VXLAN IP address of remote node %v/%v is not valid", nodeInfo.ClusterId, nodeInfo.Name)
+	}
+	_, podNetwork, err := net.ParseCIDR(nodeInfo.PodNetwork)
+	if err != nil {
+		return fmt.Errorf("invalid pod network of remote node %v/%v: %v", nodeInfo.ClusterId, nodeInfo.Name, err)
+	}
+	_, vppHostNetwork, err := net.ParseCIDR(nodeInfo.VppHostNetwork)
+	if err != nil {
+		return fmt.Errorf("invalid VPP-host network of remote node %v/%v: %v", nodeInfo.ClusterId, nodeInfo.Name, err)
+	}
+
+	// networks of this cluster
+	localNetworks := map[string]*net.IPNet{
+		"pod subnet":      m.server.ipam.PodSubnet(),
+		"VPP-host subnet": m.server.ipam.VPPHostSubnet(),
+	}
+	for _, remoteNetwork := range []*net.IPNet{podNetwork, vppHostNetwork} {
+		for name, localNetwork := range localNetworks {
+			if networksOverlap(remoteNetwork, localNetwork) {
+				return fmt.Errorf("network %v of remote node %v/%v overlaps with the %v of this cluster (%v)",
+					remoteNetwork, nodeInfo.ClusterId, nodeInfo.Name, name, localNetwork)
+			}
+		}
+	}
+	if !m.server.useL2Interconnect {
+		vxlanIP := net.ParseIP(m.server.ipPrefixToAddress(nodeInfo.VxlanIpAddress))
+		if m.server.ipam.VxlanSubnet().Contains(vxlanIP) {
+			return fmt.Errorf("VXLAN IP address %v of remote node %v/%v is from the VXLAN subnet of this cluster",
+				vxlanIP, nodeInfo.ClusterId, nodeInfo.Name)
+		}
+	}
+
+	// networks and VXLAN addresses of the other imported nodes
+	for key, otherNode := range m.remoteNodes {
+		if key == remoteNodeKey(nodeInfo.ClusterId, nodeInfo.Id) {
+			continue
+		}
+		if !m.server.useL2Interconnect {
+			vxlanIP := net.ParseIP(m.server.ipPrefixToAddress(nodeInfo.VxlanIpAddress))
+			otherVxlanIP := net.ParseIP(m.server.ipPrefixToAddress(otherNode.VxlanIpAddress))
+			if vxlanIP.Equal(otherVxlanIP) {
+				return fmt.Errorf("VXLAN IP address %v of remote node %v/%v is already used by remote node %v/%v",
+					vxlanIP, nodeInfo.ClusterId, nodeInfo.Name, otherNode.ClusterId, otherNode.Name)
+			}
+		}
+		for _, network := range []string{otherNode.PodNetwork, otherNode.VppHostNetwork} {
+			_, otherNetwork, _ := net.ParseCIDR(network)
+			if networksOverlap(podNetwork, otherNetwork) || networksOverlap(vppHostNetwork, otherNetwork) {
+				return fmt.Errorf("networks of remote node %v/%v overlap with network %v of remote node %v/%v",
+					nodeInfo.ClusterId, nodeInfo.Name, otherNetwork, otherNode.ClusterId, otherNode.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// notifyRemoteEndpoints propagates a change in the endpoints of a remote cluster
+// to all registered hooks.
+func (m *clusterMesh) notifyRemoteEndpoints(clusterID string, epsID epmodel.ID, eps *epmodel.Endpoints) {
+	m.Lock()
+	hooks := m.endpointsHooks
+	m.Unlock()
+
+	for _, hook := range hooks {
+		if err := hook(clusterID, epsID, eps); err != nil {
+			m.Logger.WithFields(logging.Fields{"cluster": clusterID, "endpoints": epsID}).
+				Warnf("Remote endpoints hook has failed: %v", err)
+		}
+	}
+}
+
+// waitForVswitchConnectivity blocks until the base vswitch config is successfully applied.
+func (s *remoteCNIserver) waitForVswitchConnectivity() {
+	s.Lock()
+	defer s.Unlock()
+	for !s.vswitchConnectivityConfigured {
+		s.vswitchCond.Wait()
+	}
+}
+
+// addRoutesToRemoteNode configures the connectivity towards a node of a remote cluster.
+// The caller is expected to wait for the vswitch connectivity first.
+func (s *remoteCNIserver) addRoutesToRemoteNode(remoteNode *node.ExportedNodeInfo) error {
+	s.Lock()
+	defer s.Unlock()
+
+	routes, err := s.computeRoutesToRemoteNode(remoteNode)
+	if err != nil {
+		return err
+	}
+
+	txn := s.vppTxnFactory().Put()
+	if !s.useL2Interconnect {
+		txn.VppInterface(s.computeVxlanToRemoteNode(remoteNode))
+		txn.Arp(s.remoteVxlanArpEntry(remoteNode))
+	}
+	for _, route := range routes {
+		txn.StaticRoute(route)
+		s.Logger.Info("Adding route to remote node: ", route)
+	}
+
+	err = txn.Send().ReceiveReply()
+	if err != nil {
+		return fmt.Errorf("Can't configure VPP to add routes to remote node %v/%v: %v ",
+			remoteNode.ClusterId, remoteNode.Name, err)
+	}
+	return nil
+}
+
+// deleteRoutesToRemoteNode removes the connectivity towards a node of a remote cluster.
+func (s *remoteCNIserver) deleteRoutesToRemoteNode(remoteNode *node.ExportedNodeInfo) error {
+	s.Lock()
+	defer s.Unlock()
+
+	routes, err := s.computeRoutesToRemoteNode(remoteNode)
+	if err != nil {
+		return err
+	}
+
+	txn := s.vppTxnFactory().Delete()
+	for _, route := range routes {
+		txn.StaticRoute(route.VrfId, route.DstIpAddr, route.NextHopAddr)
+		s.Logger.Info("Deleting route to remote node: ", route)
+	}
+	if !s.useL2Interconnect {
+		arp := s.remoteVxlanArpEntry(remoteNode)
+		txn.Arp(arp.Interface, arp.IpAddress)
+		txn.VppInterface(remoteVxlanIfName(remoteNode))
+	}
+
+	err = txn.Send().ReceiveReply()
+	if err != nil {
+		return fmt.Errorf("Can't configure VPP to remove routes to remote node %v/%v: %v ",
+			remoteNode.ClusterId, remoteNode.Name, err)
+	}
+	return nil
+}
+
+// loadClusterExport loads static export of a remote cluster from a JSON file.
+func loadClusterExport(path string) (*ClusterExport, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster export %v: %v", path, err)
+	}
+	export := &ClusterExport{}
+	if err := json.Unmarshal(data, export); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster export %v: %v", path, err)
+	}
+	return export, nil
+}
+
+// remoteNodeKey returns key identifying a remote node across all clusters of the mesh.
+func remoteNodeKey(clusterID string, nodeID uint32) string {
+	return clusterID + "/" + strconv.FormatUint(uint64(nodeID), 10)
+}
+
+// networksOverlap returns true if the given networks have at least one IP address in common.
+func networksOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"testing"
+
+	"github.com/contiv/vpp/mock/broker"
+	"github.com/contiv/vpp/plugins/contiv/model/node"
+	epmodel "github.com/contiv/vpp/plugins/ksr/model/endpoints"
+	"github.com/ligato/cn-infra/logging/logrus"
+	"github.com/onsi/gomega"
+)
+
+var remoteNodeInfo = node.ExportedNodeInfo{
+	ClusterId:      "cluster2",
+	Id:             3,
+	Name:           "remote-node3",
+	IpAddress:      "192.168.1.50/24",
+	VxlanIpAddress: "192.168.31.3",
+	PodNetwork:     "10.10.3.0/24",
+	VppHostNetwork: "172.31.3.0/24",
+}
+
+func TestRemoteNodeAddDelVXLAN(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, txns, _, conn := setupTestCNIServer(&configTapVxlanTCP, nil)
+	defer conn.Disconnect()
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	mesh := newClusterMesh(logrus.DefaultLogger(), &ClusterMeshConfig{ClusterID: "cluster1"}, server, nil)
+
+	remoteNode := remoteNodeInfo
+	err = mesh.addRemoteNode("cluster2", &remoteNode)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// check that the VXLAN tunnel to the remote node has been added
+	vxlanIf := interfaceInLatestRevs(txns.LatestRevisions, "vxlan-cluster2-3")
+	gomega.Expect(vxlanIf).ToNot(gomega.BeNil())
+	gomega.Expect(vxlanIf.Vxlan.DstAddress).To(gomega.BeEquivalentTo("192.168.1.50"))
+
+	// check routes to the pod and VPP-host networks of the remote node
+	routes := routesViaInLatestRevs(txns.LatestRevisions, "192.168.31.3")
+	gomega.Expect(len(routes)).To(gomega.BeEquivalentTo(2))
+
+	err = mesh.removeRemoteNode("cluster2", remoteNode.Id)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(mesh.remoteNodes).To(gomega.BeEmpty())
+}
+
+func TestRemoteNodeAddDelL2(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, txns, _, conn := setupTestCNIServer(&configVethL2NoTCP, nil)
+	defer conn.Disconnect()
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	mesh := newClusterMesh(logrus.DefaultLogger(), &ClusterMeshConfig{ClusterID: "cluster1"}, server, nil)
+
+	remoteNode := remoteNodeInfo
+	remoteNode.VxlanIpAddress = ""
+	err = mesh.addRemoteNode("cluster2", &remoteNode)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// check that the VXLAN interface does not exist
+	vxlanIf := interfaceInLatestRevs(txns.LatestRevisions, "vxlan-cluster2-3")
+	gomega.Expect(vxlanIf).To(gomega.BeNil())
+
+	// check routes to the remote node pointing to its node IP
+	routes := routesViaInLatestRevs(txns.LatestRevisions, "192.168.1.50")
+	gomega.Expect(len(routes)).To(gomega.BeEquivalentTo(2))
+
+	err = mesh.removeRemoteNode("cluster2", remoteNode.Id)
+	gomega.Expect(err).To(gomega.BeNil())
+}
+
+func TestRemoteNodeOverlap(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, txns, _, conn := setupTestCNIServer(&configTapVxlanTCP, nil)
+	defer conn.Disconnect()
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	mesh := newClusterMesh(logrus.DefaultLogger(), &ClusterMeshConfig{ClusterID: "cluster1"}, server, nil)
+
+	// pod network overlapping with the local pod subnet
+	remoteNode := remoteNodeInfo
+	remoteNode.PodNetwork = "10.1.5.0/24"
+	err = mesh.addRemoteNode("cluster2", &remoteNode)
+	gomega.Expect(err).ToNot(gomega.BeNil())
+
+	// VXLAN IP from the local VXLAN subnet
+	remoteNode = remoteNodeInfo
+	remoteNode.VxlanIpAddress = "192.168.30.3"
+	err = mesh.addRemoteNode("cluster2", &remoteNode)
+	gomega.Expect(err).ToNot(gomega.BeNil())
+
+	// node exported by a different cluster than it is imported from
+	remoteNode = remoteNodeInfo
+	err = mesh.addRemoteNode("cluster3", &remoteNode)
+	gomega.Expect(err).ToNot(gomega.BeNil())
+
+	gomega.Expect(mesh.remoteNodes).To(gomega.BeEmpty())
+	gomega.Expect(interfaceInLatestRevs(txns.LatestRevisions, "vxlan-cluster2-3")).To(gomega.BeNil())
+
+	// pod network overlapping with another remote node
+	remoteNode = remoteNodeInfo
+	err = mesh.addRemoteNode("cluster2", &remoteNode)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	otherRemoteNode := remoteNodeInfo
+	otherRemoteNode.ClusterId = "cluster3"
+	otherRemoteNode.VxlanIpAddress = "192.168.32.3"
+	otherRemoteNode.VppHostNetwork = "172.32.3.0/24"
+	err = mesh.addRemoteNode("cluster3", &otherRemoteNode)
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	gomega.Expect(mesh.remoteNodes).To(gomega.HaveLen(1))
+
+	// VXLAN IP used by a node of another remote cluster
+	otherRemoteNode = remoteNodeInfo
+	otherRemoteNode.ClusterId = "cluster3"
+	otherRemoteNode.PodNetwork = "10.10.4.0/24"
+	otherRemoteNode.VppHostNetwork = "172.32.3.0/24"
+	err = mesh.addRemoteNode("cluster3", &otherRemoteNode)
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	gomega.Expect(mesh.remoteNodes).To(gomega.HaveLen(1))
+	gomega.Expect(interfaceInLatestRevs(txns.LatestRevisions, "vxlan-cluster3-3")).To(gomega.BeNil())
+}
+
+func TestRemoteClusterReimport(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, txns, _, conn := setupTestCNIServer(&configTapVxlanTCP, nil)
+	defer conn.Disconnect()
+
+	// exec resync to configure vswitch
+	err := server.resync()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	mesh := newClusterMesh(logrus.DefaultLogger(), &ClusterMeshConfig{ClusterID: "cluster1"}, server, nil)
+
+	remoteNode := remoteNodeInfo
+	otherRemoteNode := remoteNodeInfo
+	otherRemoteNode.Id = 4
+	otherRemoteNode.Name = "remote-node4"
+	otherRemoteNode.VxlanIpAddress = "192.168.31.4"
+	otherRemoteNode.PodNetwork = "10.10.4.0/24"
+	otherRemoteNode.VppHostNetwork = "172.31.4.0/24"
+	mesh.importClusterExport("cluster2", &ClusterExport{Nodes: []*node.ExportedNodeInfo{&remoteNode, &otherRemoteNode}})
+	gomega.Expect(mesh.remoteNodes).To(gomega.HaveLen(2))
+	gomega.Expect(interfaceInLatestRevs(txns.LatestRevisions, "vxlan-cluster2-4")).ToNot(gomega.BeNil())
+
+	// node 4 removed while the connection to the remote cluster was down
+	remoteNode = remoteNodeInfo
+	mesh.importClusterExport("cluster2", &ClusterExport{Nodes: []*node.ExportedNodeInfo{&remoteNode}})
+	gomega.Expect(mesh.remoteNodes).To(gomega.HaveLen(1))
+	gomega.Expect(mesh.remoteNodes).To(gomega.HaveKey(remoteNodeKey("cluster2", 3)))
+	gomega.Expect(interfaceInLatestRevs(txns.LatestRevisions, "vxlan-cluster2-4")).To(gomega.BeNil())
+	gomega.Expect(interfaceInLatestRevs(txns.LatestRevisions, "vxlan-cluster2-3")).ToNot(gomega.BeNil())
+}
+
+func TestRemoteEndpointsHook(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	mesh := newClusterMesh(logrus.DefaultLogger(), &ClusterMeshConfig{ClusterID: "cluster1"}, nil, nil)
+
+	var received []*epmodel.Endpoints
+	mesh.registerEndpointsHook(func(clusterID string, epsID epmodel.ID, eps *epmodel.Endpoints) error {
+		gomega.Expect(clusterID).To(gomega.BeEquivalentTo("cluster2"))
+		received = append(received, eps)
+		return nil
+	})
+
+	eps := &epmodel.Endpoints{Name: "web", Namespace: "default"}
+	mesh.importClusterExport("cluster2", &ClusterExport{Endpoints: []*epmodel.Endpoints{eps}})
+	gomega.Expect(received).To(gomega.HaveLen(1))
+	gomega.Expect(received[0]).To(gomega.Equal(eps))
+}
+
+func TestNodeExportWithdrawn(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server, _, _, conn := setupTestCNIServer(&configTapVxlanTCP, nil)
+	defer conn.Disconnect()
+
+	exportBroker := &broker.MockBroker{}
+	mesh := newClusterMesh(logrus.DefaultLogger(), &ClusterMeshConfig{ClusterID: "cluster1"}, server, exportBroker)
+
+	nodeInfo := &node.NodeInfo{Id: 2, Name: "node2", IpAddress: "192.168.16.2/24"}
+	err := mesh.exportNode(nodeInfo)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(exportBroker.Keys()).To(gomega.ConsistOf(node.ExportedNodeKey(2)))
+
+	// node removed from the cluster
+	err = mesh.unexportNode(2)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(exportBroker.Keys()).To(gomega.BeEmpty())
+}
@@ -22,6 +22,7 @@ import (
 
 	"encoding/binary"
 	"git.fd.io/govpp.git/api"
+	"github.com/contiv/vpp/plugins/contiv/model/node"
 	linux_intf "github.com/ligato/vpp-agent/plugins/linux/model/interfaces"
 	linux_l3 "github.com/ligato/vpp-agent/plugins/linux/model/l3"
 	"github.com/ligato/vpp-agent/plugins/vpp/binapi/ip"
@@ -428,6 +429,67 @@ func (s *remoteCNIserver) removeInterfaceFromVxlanBD(bd *vpp_l2.BridgeDomains_Br
 	}
 }
 
+// computeVxlanToRemoteNode returns the routed VXLAN tunnel interconnecting this node
+// with a node of a remote cluster. Unlike tunnels to the nodes of this cluster,
+// it is not added into the VXLAN bridge domain, as the VXLAN addresses (and MACs)
+// of the remote nodes are not allocated by our IPAM.
+func (s *remoteCNIserver) computeVxlanToRemoteNode(remoteNode *node.ExportedNodeInfo) *vpp_intf.Interfaces_Interface {
+	return &vpp_intf.Interfaces_Interface{
+		Name:    remoteVxlanIfName(remoteNode),
+		Type:    vpp_intf.InterfaceType_VXLAN_TUNNEL,
+		Enabled: true,
+		Vrf:     s.GetPodVrfID(),
+		Unnumbered: &vpp_intf.Interfaces_Interface_Unnumbered{
+			IsUnnumbered:    true,
+			InterfaceWithIp: vxlanBVIInterfaceName,
+		},
+		Vxlan: &vpp_intf.Interfaces_Interface_Vxlan{
+			SrcAddress: s.ipPrefixToAddress(s.nodeIP),
+			DstAddress: s.ipPrefixToAddress(remoteNode.IpAddress),
+			Vni:        vxlanVNI,
+		},
+	}
+}
+
+// remoteVxlanArpEntry returns static ARP entry for the VXLAN BVI of a node of a remote cluster.
+func (s *remoteCNIserver) remoteVxlanArpEntry(remoteNode *node.ExportedNodeInfo) *vpp_l3.ArpTable_ArpEntry {
+	return &vpp_l3.ArpTable_ArpEntry{
+		Interface:   remoteVxlanIfName(remoteNode),
+		IpAddress:   s.ipPrefixToAddress(remoteNode.VxlanIpAddress),
+		PhysAddress: s.hwAddrForVXLAN(remoteNode.Id),
+		Static:      true,
+	}
+}
+
+// computeRoutesToRemoteNode returns routes to the pod and VPP-host networks of a node of a remote cluster.
+func (s *remoteCNIserver) computeRoutesToRemoteNode(remoteNode *node.ExportedNodeInfo) ([]*vpp_l3.StaticRoutes_Route, error) {
+	var routes []*vpp_l3.StaticRoutes_Route
+	for _, network := range []string{remoteNode.PodNetwork, remoteNode.VppHostNetwork} {
+		_, destNetwork, err := net.ParseCIDR(network)
+		if err != nil {
+			return nil, fmt.Errorf("Can't parse network %v of remote node %v: %v ", network, remoteNode.Name, err)
+		}
+		r := &vpp_l3.StaticRoutes_Route{
+			DstIpAddr: destNetwork.String(),
+		}
+		if s.useL2Interconnect {
+			r.VrfId = s.GetMainVrfID()
+			r.NextHopAddr = s.ipPrefixToAddress(remoteNode.IpAddress)
+		} else {
+			r.VrfId = s.GetPodVrfID()
+			r.NextHopAddr = s.ipPrefixToAddress(remoteNode.VxlanIpAddress)
+			r.OutgoingInterface = remoteVxlanIfName(remoteNode)
+		}
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+// remoteVxlanIfName returns the name of the VXLAN tunnel towards the given node of a remote cluster.
+func remoteVxlanIfName(remoteNode *node.ExportedNodeInfo) string {
+	return fmt.Sprintf("vxlan-%s-%d", remoteNode.ClusterId, remoteNode.Id)
+}
+
 func (s *remoteCNIserver) otherHostIP(hostID uint32, hostIPPrefix string) string {
 	// determine next hop IP - either use provided one, or calculate based on hostIPPrefix
 	if hostIPPrefix != "" {
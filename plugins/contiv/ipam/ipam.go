@@ -179,6 +179,14 @@ func (i *IPAM) VxlanIPWithPrefix(nodeID uint32) (*net.IPNet, error) {
 	return &vxlanNetwork, nil
 }
 
+// VxlanSubnet returns the subnet used for inter-node VXLAN on all nodes.
+func (i *IPAM) VxlanSubnet() *net.IPNet {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	vxlanSubnet := newIPNet(i.vxlanCIDR) // defensive copy
+	return &vxlanSubnet
+}
+
 // VEthVPPEndIP provides the IPv4 address of the VPP-end of the VPP to host interconnect veth pair.
 func (i *IPAM) VEthVPPEndIP() net.IP {
 	i.mutex.RLock()
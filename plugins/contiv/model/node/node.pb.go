@@ -9,6 +9,8 @@ It is generated from these files:
 
 It has these top-level messages:
 	NodeInfo
+	ExportedNodeInfo
+	VswitchStatus
 */
 package node
 
@@ -70,21 +72,167 @@ func (m *NodeInfo) GetManagementIpAddress() string {
 	return ""
 }
 
+// ExportedNodeInfo is a self-contained description of a node published
+// for the other Contiv clusters of a cluster mesh. Unlike NodeInfo it carries
+// all the networks of the node explicitly, since they cannot be derived from
+// the node ID using the IPAM configuration of a different cluster.
+type ExportedNodeInfo struct {
+	ClusterId           string `protobuf:"bytes,1,opt,name=cluster_id,json=clusterId" json:"cluster_id,omitempty"`
+	Id                  uint32 `protobuf:"varint,2,opt,name=id" json:"id,omitempty"`
+	Name                string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	IpAddress           string `protobuf:"bytes,4,opt,name=ip_address,json=ipAddress" json:"ip_address,omitempty"`
+	ManagementIpAddress string `protobuf:"bytes,5,opt,name=management_ip_address,json=managementIpAddress" json:"management_ip_address,omitempty"`
+	VxlanIpAddress      string `protobuf:"bytes,6,opt,name=vxlan_ip_address,json=vxlanIpAddress" json:"vxlan_ip_address,omitempty"`
+	PodNetwork          string `protobuf:"bytes,7,opt,name=pod_network,json=podNetwork" json:"pod_network,omitempty"`
+	VppHostNetwork      string `protobuf:"bytes,8,opt,name=vpp_host_network,json=vppHostNetwork" json:"vpp_host_network,omitempty"`
+}
+
+func (m *ExportedNodeInfo) Reset()                    { *m = ExportedNodeInfo{} }
+func (m *ExportedNodeInfo) String() string            { return proto.CompactTextString(m) }
+func (*ExportedNodeInfo) ProtoMessage()               {}
+func (*ExportedNodeInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *ExportedNodeInfo) GetClusterId() string {
+	if m != nil {
+		return m.ClusterId
+	}
+	return ""
+}
+
+func (m *ExportedNodeInfo) GetId() uint32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *ExportedNodeInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ExportedNodeInfo) GetIpAddress() string {
+	if m != nil {
+		return m.IpAddress
+	}
+	return ""
+}
+
+func (m *ExportedNodeInfo) GetManagementIpAddress() string {
+	if m != nil {
+		return m.ManagementIpAddress
+	}
+	return ""
+}
+
+func (m *ExportedNodeInfo) GetVxlanIpAddress() string {
+	if m != nil {
+		return m.VxlanIpAddress
+	}
+	return ""
+}
+
+func (m *ExportedNodeInfo) GetPodNetwork() string {
+	if m != nil {
+		return m.PodNetwork
+	}
+	return ""
+}
+
+func (m *ExportedNodeInfo) GetVppHostNetwork() string {
+	if m != nil {
+		return m.VppHostNetwork
+	}
+	return ""
+}
+
+// VswitchStatus is a health status of contiv-vswitch periodically published
+// by the agent (with a TTL) for contiv-ksr, which reflects it into the node
+// condition (and optionally taint) of the corresponding K8s node.
+type VswitchStatus struct {
+	NodeName string `protobuf:"bytes,1,opt,name=node_name,json=nodeName" json:"node_name,omitempty"`
+	// vpp_connected is true if the agent is connected to VPP (govppmux).
+	VppConnected bool `protobuf:"varint,2,opt,name=vpp_connected,json=vppConnected" json:"vpp_connected,omitempty"`
+	// resynced is true once the first resync of the vswitch configuration has completed.
+	Resynced bool `protobuf:"varint,3,opt,name=resynced" json:"resynced,omitempty"`
+	// agent_ok is true if the overall agent state reported by statuscheck is OK.
+	AgentOk bool `protobuf:"varint,4,opt,name=agent_ok,json=agentOk" json:"agent_ok,omitempty"`
+	// message describes the reason why the vswitch is not ready.
+	Message string `protobuf:"bytes,5,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *VswitchStatus) Reset()                    { *m = VswitchStatus{} }
+func (m *VswitchStatus) String() string            { return proto.CompactTextString(m) }
+func (*VswitchStatus) ProtoMessage()               {}
+func (*VswitchStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *VswitchStatus) GetNodeName() string {
+	if m != nil {
+		return m.NodeName
+	}
+	return ""
+}
+
+func (m *VswitchStatus) GetVppConnected() bool {
+	if m != nil {
+		return m.VppConnected
+	}
+	return false
+}
+
+func (m *VswitchStatus) GetResynced() bool {
+	if m != nil {
+		return m.Resynced
+	}
+	return false
+}
+
+func (m *VswitchStatus) GetAgentOk() bool {
+	if m != nil {
+		return m.AgentOk
+	}
+	return false
+}
+
+func (m *VswitchStatus) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*NodeInfo)(nil), "node.NodeInfo")
+	proto.RegisterType((*ExportedNodeInfo)(nil), "node.ExportedNodeInfo")
+	proto.RegisterType((*VswitchStatus)(nil), "node.VswitchStatus")
 }
 
 func init() { proto.RegisterFile("node.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 141 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xca, 0xcb, 0x4f, 0x49,
-	0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x01, 0xb1, 0x95, 0x1a, 0x19, 0xb9, 0x38, 0xfc,
-	0xf2, 0x53, 0x52, 0x3d, 0xf3, 0xd2, 0xf2, 0x85, 0xf8, 0xb8, 0x98, 0x32, 0x53, 0x24, 0x18, 0x15,
-	0x18, 0x35, 0x78, 0x83, 0x98, 0x32, 0x53, 0x84, 0x84, 0xb8, 0x58, 0xf2, 0x12, 0x73, 0x53, 0x25,
-	0x98, 0x14, 0x18, 0x35, 0x38, 0x83, 0xc0, 0x6c, 0x21, 0x59, 0x2e, 0xae, 0xcc, 0x82, 0xf8, 0xc4,
-	0x94, 0x94, 0xa2, 0xd4, 0xe2, 0x62, 0x09, 0x66, 0xb0, 0x0c, 0x67, 0x66, 0x81, 0x23, 0x44, 0x40,
-	0xc8, 0x88, 0x4b, 0x34, 0x37, 0x31, 0x2f, 0x31, 0x3d, 0x35, 0x37, 0x35, 0xaf, 0x24, 0x1e, 0x49,
-	0x25, 0x0b, 0x58, 0xa5, 0x30, 0x42, 0xd2, 0x13, 0xa6, 0x27, 0x89, 0x0d, 0xec, 0x20, 0x63, 0x40,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0xfd, 0x56, 0x0e, 0xe6, 0x9e, 0x00, 0x00, 0x00,
+	// 343 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0x4f, 0x4b, 0xf3, 0x40,
+	0x10, 0xc6, 0x49, 0x9a, 0xb7, 0xdd, 0xcc, 0x6b, 0x4b, 0x59, 0x11, 0xa2, 0x52, 0x2c, 0xf5, 0xd2,
+	0x93, 0x07, 0xfd, 0x04, 0x22, 0x82, 0xbd, 0x54, 0x88, 0xe0, 0x35, 0xac, 0xd9, 0xb1, 0x0d, 0x6d,
+	0x76, 0x96, 0xec, 0xf6, 0x8f, 0x47, 0x3f, 0x88, 0x17, 0x3f, 0xa9, 0x64, 0x93, 0xa6, 0x05, 0x29,
+	0x78, 0x9b, 0x79, 0xe6, 0x97, 0xe1, 0x99, 0x27, 0x0b, 0xa0, 0x48, 0xe2, 0x8d, 0x2e, 0xc8, 0x12,
+	0x0f, 0xca, 0x7a, 0xf4, 0xe9, 0x01, 0x9b, 0x92, 0xc4, 0x89, 0x7a, 0x27, 0xde, 0x03, 0x3f, 0x93,
+	0x91, 0x37, 0xf4, 0xc6, 0xdd, 0xd8, 0xcf, 0x24, 0xe7, 0x10, 0x28, 0x91, 0x63, 0xe4, 0x0f, 0xbd,
+	0x71, 0x18, 0xbb, 0x9a, 0x0f, 0x00, 0x32, 0x9d, 0x08, 0x29, 0x0b, 0x34, 0x26, 0x6a, 0xb9, 0x49,
+	0x98, 0xe9, 0xfb, 0x4a, 0xe0, 0xb7, 0x70, 0x96, 0x0b, 0x25, 0x66, 0x98, 0xa3, 0xb2, 0xc9, 0x01,
+	0x19, 0x38, 0xf2, 0x74, 0x3f, 0x9c, 0xec, 0xbe, 0x19, 0x7d, 0xf9, 0xd0, 0x7f, 0xdc, 0x6a, 0x2a,
+	0x2c, 0xca, 0xc6, 0xcb, 0x00, 0x20, 0x5d, 0xae, 0x8c, 0xc5, 0x22, 0xa9, 0x3d, 0x85, 0x71, 0x58,
+	0x2b, 0x13, 0x59, 0x5b, 0xf5, 0x7f, 0x59, 0x6d, 0x1d, 0xb5, 0x1a, 0xfc, 0xd9, 0xea, 0xbf, 0xa3,
+	0x56, 0xf9, 0x18, 0xfa, 0xeb, 0xed, 0x52, 0xa8, 0x43, 0xbc, 0xed, 0xf0, 0x9e, 0xd3, 0xf7, 0xe4,
+	0x15, 0xfc, 0xd7, 0x24, 0x13, 0x85, 0x76, 0x43, 0xc5, 0x22, 0xea, 0x38, 0x08, 0x34, 0xc9, 0x69,
+	0xa5, 0xb8, 0x55, 0x5a, 0x27, 0x73, 0x32, 0xb6, 0xa1, 0x58, 0xbd, 0x4a, 0xeb, 0x27, 0x32, 0xb6,
+	0x26, 0x47, 0xdf, 0x1e, 0x74, 0x5f, 0xcd, 0x26, 0xb3, 0xe9, 0xfc, 0xc5, 0x0a, 0xbb, 0x32, 0xfc,
+	0x12, 0xc2, 0xf2, 0xef, 0x25, 0xee, 0xe4, 0x2a, 0x1b, 0x56, 0x0a, 0xd3, 0xf2, 0xec, 0x6b, 0xe8,
+	0x96, 0x8b, 0x53, 0x52, 0x0a, 0x53, 0x8b, 0x55, 0x4a, 0x2c, 0x3e, 0x59, 0x6b, 0xfd, 0xb0, 0xd3,
+	0xf8, 0x05, 0xb0, 0x02, 0xcd, 0x87, 0x4a, 0x51, 0xba, 0xcc, 0x58, 0xdc, 0xf4, 0xfc, 0x1c, 0x98,
+	0x98, 0x95, 0x99, 0xd0, 0xc2, 0xa5, 0xc6, 0xe2, 0x8e, 0xeb, 0x9f, 0x17, 0x3c, 0x82, 0x4e, 0x8e,
+	0xc6, 0x88, 0x19, 0xd6, 0x29, 0xed, 0xda, 0xb7, 0xb6, 0x7b, 0x55, 0x77, 0x3f, 0x03, 0x00, 0x27,
+	0x8a, 0x40, 0x55, 0x63, 0x02, 0x00, 0x00,
 }
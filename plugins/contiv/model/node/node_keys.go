@@ -14,6 +14,27 @@
 
 package node
 
+import "strconv"
+
 // AllocatedIDsKeyPrefix is a key prefix used in ETCD to store information
 // about node ID and its IP addresses.
 const AllocatedIDsKeyPrefix = "allocatedIDs/"
+
+// ExportedNodesKeyPrefix is a key prefix used in ETCD to publish the information
+// about the nodes of this cluster for the remote clusters of a cluster mesh.
+const ExportedNodesKeyPrefix = "clustermesh/nodes/"
+
+// ExportedNodeKey returns the key under which the exported info about the node
+// with the given ID is stored.
+func ExportedNodeKey(id uint32) string {
+	return ExportedNodesKeyPrefix + strconv.FormatUint(uint64(id), 10)
+}
+
+// VswitchStatusKeyPrefix is a key prefix used in ETCD to store the health status
+// of contiv-vswitch on every node.
+const VswitchStatusKeyPrefix = "vswitch-status/"
+
+// VswitchStatusKey returns the key under which the vswitch status of the given node is stored.
+func VswitchStatusKey(nodeName string) string {
+	return VswitchStatusKeyPrefix + nodeName
+}
@@ -178,6 +178,13 @@ func (s *remoteCNIserver) processChangeEvent(dataChngEv datasync.ChangeEvent) er
 
 			// delete routes to the node
 			err = s.deleteRoutesToNode(prevNodeInfo)
+
+			// withdraw the node from the cluster mesh
+			if s.nodeRemovedHook != nil {
+				if hookErr := s.nodeRemovedHook(prevNodeInfo.Id); hookErr != nil {
+					s.Logger.Warnf("Node removed hook has failed: %v", hookErr)
+				}
+			}
 		}
 	} else {
 		return fmt.Errorf("Unknown key %v", key)
@@ -21,10 +21,8 @@ import (
 	"sync"
 
 	"github.com/contiv/vpp/plugins/contiv/model/node"
-	"github.com/contiv/vpp/plugins/ksr"
 	"github.com/ligato/cn-infra/db/keyval"
 	"github.com/ligato/cn-infra/db/keyval/etcd"
-	"github.com/ligato/cn-infra/servicelabel"
 	"strconv"
 	"strings"
 )
@@ -46,8 +44,9 @@ var (
 // the allocation is inserted)
 type idAllocator struct {
 	sync.Mutex
-	etcd   *etcd.Plugin
-	broker keyval.ProtoBroker
+	etcd      *etcd.Plugin
+	ksrPrefix string
+	broker    keyval.ProtoBroker
 
 	allocated bool
 	ID        uint32
@@ -57,15 +56,20 @@ type idAllocator struct {
 
 	// ip used by k8s to access node
 	managementIP string
+
+	// callback triggered when the entry of this node was updated in etcd
+	onUpdate func(nodeInfo *node.NodeInfo) error
 }
 
 // newIDAllocator creates new instance of idAllocator
-func newIDAllocator(etcd *etcd.Plugin, nodeName string, nodeIP string) *idAllocator {
+// (<ksrPrefix> is the key prefix of contiv-ksr of this cluster)
+func newIDAllocator(etcd *etcd.Plugin, ksrPrefix string, nodeName string, nodeIP string) *idAllocator {
 	return &idAllocator{
-		etcd:     etcd,
-		broker:   etcd.NewBroker(servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)),
-		nodeName: nodeName,
-		nodeIP:   nodeIP,
+		etcd:      etcd,
+		ksrPrefix: ksrPrefix,
+		broker:    etcd.NewBroker(ksrPrefix),
+		nodeName:  nodeName,
+		nodeIP:    nodeIP,
 	}
 }
 
@@ -149,6 +153,9 @@ func (ia *idAllocator) updateEtcdEntry(newIP string, newManagementIP string) err
 		ManagementIpAddress: ia.managementIP,
 	}
 	err = ia.broker.Put(createKey(ia.ID), value)
+	if err == nil && ia.onUpdate != nil {
+		err = ia.onUpdate(value)
+	}
 
 	return err
 
@@ -184,7 +191,7 @@ func (ia *idAllocator) writeIfNotExists(id uint32) (succeeded bool, err error) {
 		return false, err
 	}
 
-	succeeded, err = ia.etcd.PutIfNotExists(servicelabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)+createKey(id), encoded)
+	succeeded, err = ia.etcd.PutIfNotExists(ia.ksrPrefix+createKey(id), encoded)
 
 	return succeeded, err
 
@@ -20,6 +20,7 @@ import (
 	"github.com/ligato/cn-infra/datasync/resync"
 	"github.com/ligato/cn-infra/db/keyval/bolt"
 	"github.com/ligato/cn-infra/db/keyval/etcd"
+	"github.com/ligato/cn-infra/health/statuscheck"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/rpc/grpc"
 	"github.com/ligato/cn-infra/rpc/rest"
@@ -51,6 +52,7 @@ func NewPlugin(opts ...Option) *Plugin {
 	p.ETCD = &etcd.DefaultPlugin
 	p.Bolt = &bolt.DefaultPlugin
 	p.HTTPHandlers = &rest.DefaultPlugin
+	p.StatusMonitor = &statuscheck.DefaultPlugin
 
 	for _, o := range opts {
 		o(p)
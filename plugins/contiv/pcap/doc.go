@@ -0,0 +1,58 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pcap implements on-demand packet capture on the VPP interfaces
+// of pods deployed on the node.
+//
+// A capture is bounded by the number of captured packets and by its duration.
+// It is performed by the VPP pcap trace, optionally filtered by the agent once
+// the capture finishes, and stored as a pcap file on the node (in the directory
+// configured as PcapDir in the Contiv plugin configuration). Only one capture
+// can be running at a time, since VPP supports a single pcap trace per direction.
+//
+// The VPP pcap traces are owned by the Tracer, shared by all components of the agent
+// using them (the policy flow logger samples pod interfaces with the same traces).
+// A capture requested while another component holds the trace fails with
+// "409 Conflict" and should be retried.
+//
+// The captures are managed using the REST API of the agent:
+//
+//	POST /contiv/v1/pcap              - start new capture
+//	GET  /contiv/v1/pcap              - list all captures
+//	GET  /contiv/v1/pcap/{id}         - get information about a capture
+//	GET  /contiv/v1/pcap/{id}/file    - download the pcap file of a finished capture
+//
+// Example:
+//
+//	$ curl -X POST localhost:9999/contiv/v1/pcap -d '{"podNamespace": "default", "podName": "nginx",
+//	    "direction": "both", "filter": "tcp port 80", "maxPackets": 100, "duration": 10}'
+//	{
+//	  "id": "default-nginx-1",
+//	  "podNamespace": "default",
+//	  "podName": "nginx",
+//	  "interface": "tap1",
+//	  "direction": "both",
+//	  "filter": "tcp port 80",
+//	  "maxPackets": 100,
+//	  "duration": 10,
+//	  "started": "2018-09-03T10:31:24.381712Z",
+//	  "state": "running"
+//	}
+//	$ curl localhost:9999/contiv/v1/pcap/default-nginx-1/file > nginx.pcap
+//
+// The direction is relative to VPP: "rx" captures packets sent by the pod,
+// "tx" packets sent to the pod. The filter is a conjunction of the following
+// primitives: "tcp", "udp", "icmp", "host <ip>", "src <ip>", "dst <ip>",
+// "port <port>".
+package pcap
@@ -0,0 +1,380 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcap
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/rpc/rest"
+)
+
+const (
+	// DefaultDir is the default directory where the captured pcap files are stored.
+	DefaultDir = "/var/run/contiv/pcap"
+
+	// DefaultMaxPackets is the default limit for the number of captured packets.
+	DefaultMaxPackets = 1000
+	// MaxPacketsLimit is the upper bound for the number of captured packets.
+	MaxPacketsLimit = 100000
+
+	// DefaultDuration is the default duration of a capture in seconds.
+	DefaultDuration = 10
+	// MaxDurationLimit is the upper bound for the duration of a capture in seconds.
+	MaxDurationLimit = 600
+
+	// directory where VPP stores pcap trace files
+	vppPcapDir = "/tmp"
+
+	pcapFileExt = ".pcap"
+
+	// owner of the VPP pcap traces used by the captures
+	captureOwner = "capture"
+)
+
+// Direction of the captured traffic, relative to VPP.
+const (
+	DirectionRx   = "rx"   // packets received by VPP from the pod
+	DirectionTx   = "tx"   // packets sent by VPP to the pod
+	DirectionBoth = "both" // packets in both directions
+)
+
+// States of a capture.
+const (
+	StateRunning = "running"
+	StateDone    = "done"
+	StateFailed  = "failed"
+)
+
+// ErrCaptureRunning is returned when a capture is requested while another one is still running.
+var ErrCaptureRunning = errors.New("another capture is already running")
+
+// CaptureRequest represents a request to start a packet capture on the interface of a pod.
+type CaptureRequest struct {
+	PodNamespace string `json:"podNamespace"`
+	PodName      string `json:"podName"`
+	Direction    string `json:"direction,omitempty"`  // rx, tx or both (default)
+	Filter       string `json:"filter,omitempty"`     // applied on packets after the capture
+	MaxPackets   uint32 `json:"maxPackets,omitempty"` // max. number of packets captured in each direction
+	Duration     uint32 `json:"duration,omitempty"`   // duration of the capture in seconds
+}
+
+// CaptureInfo describes a (running or finished) capture.
+type CaptureInfo struct {
+	ID           string    `json:"id"`
+	PodNamespace string    `json:"podNamespace,omitempty"`
+	PodName      string    `json:"podName,omitempty"`
+	Interface    string    `json:"interface,omitempty"` // VPP interface name
+	Direction    string    `json:"direction,omitempty"`
+	Filter       string    `json:"filter,omitempty"`
+	MaxPackets   uint32    `json:"maxPackets,omitempty"`
+	Duration     uint32    `json:"duration,omitempty"`
+	Started      time.Time `json:"started"`
+	State        string    `json:"state"`
+	Packets      int       `json:"packets"` // number of packets stored in the pcap file
+	Error        string    `json:"error,omitempty"`
+}
+
+// PodIfNameGetter returns the logical name of the VPP interface connecting the given pod.
+type PodIfNameGetter func(podNamespace string, podName string) (ifName string, exists bool)
+
+// Capturer manages packet captures on the VPP interfaces of pods.
+type Capturer struct {
+	logger    logging.Logger
+	mutex     sync.Mutex
+	dir       string
+	tracer    *Tracer
+	getIfName PodIfNameGetter
+
+	captures map[string]*CaptureInfo // capture ID -> capture
+	running  *CaptureInfo
+	seqNum   int
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New creates new packet capturer storing the pcap files into <dir>. Captures stored
+// in the directory by previous runs of the agent are listed as finished. The VPP pcap
+// traces are started and stopped through the given (shared) tracer.
+func New(logger logging.Logger, dir string, tracer *Tracer, getIfName PodIfNameGetter,
+	http rest.HTTPHandlers) (*Capturer, error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	c := &Capturer{
+		logger:    logger,
+		dir:       dir,
+		tracer:    tracer,
+		getIfName: getIfName,
+		captures:  make(map[string]*CaptureInfo),
+		stopCh:    make(chan struct{}),
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	c.loadStoredCaptures()
+	c.registerHandlers(http)
+	return c, nil
+}
+
+// Close stops the running capture (if any).
+func (c *Capturer) Close() error {
+	close(c.stopCh)
+	c.wg.Wait()
+	return nil
+}
+
+// Start validates the request and starts a new capture. The capture runs in the background
+// for the requested duration.
+func (c *Capturer) Start(req *CaptureRequest) (*CaptureInfo, error) {
+	if err := normalizeRequest(req); err != nil {
+		return nil, err
+	}
+	flt, err := parseFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.running != nil {
+		return nil, ErrCaptureRunning
+	}
+
+	ifName, exists := c.getIfName(req.PodNamespace, req.PodName)
+	if !exists {
+		return nil, fmt.Errorf("interface of the pod %s/%s was not found", req.PodNamespace, req.PodName)
+	}
+	vppIfName, err := c.tracer.GetVppIfName(ifName)
+	if err != nil {
+		return nil, err
+	}
+
+	// generate ID not colliding with the captures stored by previous runs of the agent
+	var id string
+	for id == "" || c.captures[id] != nil {
+		c.seqNum++
+		id = fmt.Sprintf("%s-%s-%d", req.PodNamespace, req.PodName, c.seqNum)
+	}
+	capture := &CaptureInfo{
+		ID:           id,
+		PodNamespace: req.PodNamespace,
+		PodName:      req.PodName,
+		Interface:    vppIfName,
+		Direction:    req.Direction,
+		Filter:       req.Filter,
+		MaxPackets:   req.MaxPackets,
+		Duration:     req.Duration,
+		Started:      time.Now(),
+		State:        StateRunning,
+	}
+
+	// start VPP pcap trace(s)
+	var started []string
+	for _, dir := range traceDirections(req.Direction) {
+		if err := c.tracer.StartTrace(captureOwner, dir, ifName, req.MaxPackets); err != nil {
+			for _, startedDir := range started {
+				c.tracer.StopTrace(captureOwner, startedDir)
+			}
+			return nil, err
+		}
+		started = append(started, dir)
+	}
+
+	c.logger.WithFields(logging.Fields{"id": capture.ID, "interface": vppIfName}).Info("Started packet capture")
+	c.captures[capture.ID] = capture
+	c.running = capture
+	c.wg.Add(1)
+	go c.waitForCapture(capture, flt)
+
+	info := *capture
+	return &info, nil
+}
+
+// List returns all captures ordered by their start time.
+func (c *Capturer) List() []*CaptureInfo {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var list []*CaptureInfo
+	for _, capture := range c.captures {
+		info := *capture
+		list = append(list, &info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Started.Equal(list[j].Started) {
+			return list[i].Started.Before(list[j].Started)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Get returns the capture with the given ID.
+func (c *Capturer) Get(id string) (capture *CaptureInfo, exists bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if capture, exists = c.captures[id]; exists {
+		info := *capture
+		return &info, true
+	}
+	return nil, false
+}
+
+// FilePath returns the path to the pcap file of a finished capture.
+func (c *Capturer) FilePath(id string) (path string, err error) {
+	capture, exists := c.Get(id)
+	if !exists {
+		return "", fmt.Errorf("capture %s does not exist", id)
+	}
+	if capture.State != StateDone {
+		return "", fmt.Errorf("capture %s is %s", id, capture.State)
+	}
+	return c.filePath(id), nil
+}
+
+// waitForCapture stops the capture once its duration has elapsed (or the capturer is closed)
+// and stores the captured packets.
+func (c *Capturer) waitForCapture(capture *CaptureInfo, flt *filter) {
+	defer c.wg.Done()
+
+	select {
+	case <-time.After(time.Duration(capture.Duration) * time.Second):
+	case <-c.stopCh:
+	}
+
+	packets, err := c.stopCapture(capture, flt)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.running = nil
+	if err != nil {
+		c.logger.WithField("id", capture.ID).Errorf("Packet capture failed: %v", err)
+		capture.State = StateFailed
+		capture.Error = err.Error()
+		return
+	}
+	c.logger.WithFields(logging.Fields{"id": capture.ID, "packets": packets}).Info("Finished packet capture")
+	capture.State = StateDone
+	capture.Packets = packets
+}
+
+// stopCapture stops VPP pcap traces of the capture and writes filtered packets
+// into the pcap file of the capture. Returns the number of stored packets.
+func (c *Capturer) stopCapture(capture *CaptureInfo, flt *filter) (int, error) {
+	var captured [][]*packet
+	for _, dir := range traceDirections(capture.Direction) {
+		_, content, err := c.tracer.StopTrace(captureOwner, dir)
+		if err != nil {
+			return 0, err
+		}
+		if content == nil {
+			// nothing captured
+			continue
+		}
+		packets, err := readPcap(content)
+		if err != nil {
+			return 0, err
+		}
+		captured = append(captured, packets)
+	}
+
+	packets := flt.apply(mergePackets(captured...))
+	file, err := os.Create(c.filePath(capture.ID))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	if err := writePcap(file, packets); err != nil {
+		return 0, err
+	}
+	return len(packets), nil
+}
+
+// loadStoredCaptures lists captures stored in the capture directory.
+func (c *Capturer) loadStoredCaptures() {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		c.logger.Warnf("Failed to list stored captures: %v", err)
+		return
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), pcapFileExt) {
+			continue
+		}
+		id := strings.TrimSuffix(file.Name(), pcapFileExt)
+		capture := &CaptureInfo{
+			ID:      id,
+			Started: file.ModTime(),
+			State:   StateDone,
+		}
+		if content, err := ioutil.ReadFile(c.filePath(id)); err == nil {
+			if packets, err := readPcap(content); err == nil {
+				capture.Packets = len(packets)
+			}
+		}
+		c.captures[id] = capture
+	}
+}
+
+// filePath returns the path to the pcap file of the given capture.
+func (c *Capturer) filePath(id string) string {
+	return filepath.Join(c.dir, id+pcapFileExt)
+}
+
+// normalizeRequest validates the request and fills the defaults.
+func normalizeRequest(req *CaptureRequest) error {
+	if req.PodNamespace == "" || req.PodName == "" {
+		return errors.New("pod namespace and name must be specified")
+	}
+	switch req.Direction {
+	case "":
+		req.Direction = DirectionBoth
+	case DirectionRx, DirectionTx, DirectionBoth:
+	default:
+		return fmt.Errorf("invalid direction '%s'", req.Direction)
+	}
+	if req.MaxPackets == 0 {
+		req.MaxPackets = DefaultMaxPackets
+	}
+	if req.MaxPackets > MaxPacketsLimit {
+		return fmt.Errorf("max. number of packets is limited to %d", MaxPacketsLimit)
+	}
+	if req.Duration == 0 {
+		req.Duration = DefaultDuration
+	}
+	if req.Duration > MaxDurationLimit {
+		return fmt.Errorf("duration is limited to %d seconds", MaxDurationLimit)
+	}
+	return nil
+}
+
+// traceDirections returns VPP pcap trace directions for the given capture direction.
+func traceDirections(direction string) []string {
+	if direction == DirectionBoth {
+		return []string{DirectionRx, DirectionTx}
+	}
+	return []string{direction}
+}
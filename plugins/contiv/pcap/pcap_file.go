@@ -0,0 +1,285 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	pcapMagic            = 0xa1b2c3d4
+	pcapHeaderLen        = 24
+	pcapRecordHdrLen     = 16
+	pcapVersionMajor     = 2
+	pcapVersionMinor     = 4
+	pcapSnapLen          = 65535
+	pcapLinkTypeEthernet = 1
+
+	etherTypeVLAN = 0x8100
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86dd
+
+	protoICMP   = 1
+	protoTCP    = 6
+	protoUDP    = 17
+	protoICMPv6 = 58
+)
+
+// packet is a single packet record of a pcap file.
+type packet struct {
+	tsSec   uint32
+	tsUsec  uint32
+	origLen uint32
+	data    []byte
+}
+
+// readPcap parses the content of a pcap file.
+func readPcap(content []byte) (packets []*packet, err error) {
+	if len(content) < pcapHeaderLen {
+		return nil, errors.New("pcap file too short")
+	}
+	var order binary.ByteOrder
+	switch {
+	case binary.LittleEndian.Uint32(content) == pcapMagic:
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(content) == pcapMagic:
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("invalid pcap file magic number")
+	}
+
+	content = content[pcapHeaderLen:]
+	for len(content) > 0 {
+		if len(content) < pcapRecordHdrLen {
+			return nil, errors.New("truncated pcap record header")
+		}
+		inclLen := order.Uint32(content[8:])
+		if uint32(len(content)-pcapRecordHdrLen) < inclLen {
+			return nil, errors.New("truncated pcap record")
+		}
+		packets = append(packets, &packet{
+			tsSec:   order.Uint32(content[0:]),
+			tsUsec:  order.Uint32(content[4:]),
+			origLen: order.Uint32(content[12:]),
+			data:    content[pcapRecordHdrLen : pcapRecordHdrLen+inclLen],
+		})
+		content = content[pcapRecordHdrLen+inclLen:]
+	}
+	return packets, nil
+}
+
+// writePcap writes packets as a pcap file (with ethernet link type) into the writer.
+func writePcap(w io.Writer, packets []*packet) error {
+	buf := &bytes.Buffer{}
+	order := binary.LittleEndian
+	binary.Write(buf, order, uint32(pcapMagic))
+	binary.Write(buf, order, []uint16{pcapVersionMajor, pcapVersionMinor})
+	binary.Write(buf, order, []uint32{0 /* timezone */, 0 /* sigfigs */, pcapSnapLen, pcapLinkTypeEthernet})
+	for _, pkt := range packets {
+		binary.Write(buf, order, []uint32{pkt.tsSec, pkt.tsUsec, uint32(len(pkt.data)), pkt.origLen})
+		buf.Write(pkt.data)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// mergePackets merges packets from multiple captures ordered by their timestamps.
+func mergePackets(captures ...[]*packet) []*packet {
+	var merged []*packet
+	for _, packets := range captures {
+		merged = append(merged, packets...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].tsSec != merged[j].tsSec {
+			return merged[i].tsSec < merged[j].tsSec
+		}
+		return merged[i].tsUsec < merged[j].tsUsec
+	})
+	return merged
+}
+
+// filter is a conjunction of simple conditions applied on captured packets.
+type filter struct {
+	protocols []uint8 // any of the protocols
+	hosts     []net.IP
+	srcHosts  []net.IP
+	dstHosts  []net.IP
+	ports     []uint16
+}
+
+// parseFilter parses filter expression consisting of a space-separated
+// conjunction of primitives: tcp, udp, icmp, host <ip>, src <ip>, dst <ip>, port <port>.
+func parseFilter(expr string) (*filter, error) {
+	f := &filter{}
+	tokens := strings.Fields(expr)
+	for i := 0; i < len(tokens); i++ {
+		token := strings.ToLower(tokens[i])
+		switch token {
+		case "tcp":
+			f.protocols = append(f.protocols, protoTCP)
+		case "udp":
+			f.protocols = append(f.protocols, protoUDP)
+		case "icmp":
+			f.protocols = append(f.protocols, protoICMP, protoICMPv6)
+		case "and":
+			// conjunction is implicit
+		case "host", "src", "dst", "port":
+			if i+1 == len(tokens) {
+				return nil, fmt.Errorf("missing argument for '%s' in filter", token)
+			}
+			i++
+			arg := tokens[i]
+			if token == "port" {
+				port, err := strconv.ParseUint(arg, 10, 16)
+				if err != nil {
+					return nil, fmt.Errorf("invalid port '%s' in filter", arg)
+				}
+				f.ports = append(f.ports, uint16(port))
+				continue
+			}
+			ip := net.ParseIP(arg)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address '%s' in filter", arg)
+			}
+			switch token {
+			case "host":
+				f.hosts = append(f.hosts, ip)
+			case "src":
+				f.srcHosts = append(f.srcHosts, ip)
+			case "dst":
+				f.dstHosts = append(f.dstHosts, ip)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported filter primitive '%s'", token)
+		}
+	}
+	return f, nil
+}
+
+// isEmpty returns true if the filter matches all packets.
+func (f *filter) isEmpty() bool {
+	return len(f.protocols) == 0 && len(f.hosts) == 0 && len(f.srcHosts) == 0 &&
+		len(f.dstHosts) == 0 && len(f.ports) == 0
+}
+
+// apply returns only packets matching the filter.
+func (f *filter) apply(packets []*packet) []*packet {
+	if f.isEmpty() {
+		return packets
+	}
+	var matching []*packet
+	for _, pkt := range packets {
+		if f.matches(pkt.data) {
+			matching = append(matching, pkt)
+		}
+	}
+	return matching
+}
+
+// matches returns true if the given ethernet frame matches the filter.
+func (f *filter) matches(frame []byte) bool {
+	// L2
+	if len(frame) < 14 {
+		return false
+	}
+	etherType := binary.BigEndian.Uint16(frame[12:])
+	l3 := frame[14:]
+	if etherType == etherTypeVLAN {
+		if len(frame) < 18 {
+			return false
+		}
+		etherType = binary.BigEndian.Uint16(frame[16:])
+		l3 = frame[18:]
+	}
+
+	// L3
+	var (
+		proto    uint8
+		src, dst net.IP
+		l4       []byte
+	)
+	switch etherType {
+	case etherTypeIPv4:
+		if len(l3) < 20 {
+			return false
+		}
+		ihl := int(l3[0]&0x0f) * 4
+		if len(l3) < ihl {
+			return false
+		}
+		proto = l3[9]
+		src, dst = net.IP(l3[12:16]), net.IP(l3[16:20])
+		l4 = l3[ihl:]
+	case etherTypeIPv6:
+		if len(l3) < 40 {
+			return false
+		}
+		proto = l3[6]
+		src, dst = net.IP(l3[8:24]), net.IP(l3[24:40])
+		l4 = l3[40:]
+	default:
+		return false
+	}
+
+	if len(f.protocols) > 0 && !containsProto(f.protocols, proto) {
+		return false
+	}
+	for _, host := range f.hosts {
+		if !host.Equal(src) && !host.Equal(dst) {
+			return false
+		}
+	}
+	for _, host := range f.srcHosts {
+		if !host.Equal(src) {
+			return false
+		}
+	}
+	for _, host := range f.dstHosts {
+		if !host.Equal(dst) {
+			return false
+		}
+	}
+
+	// L4
+	if len(f.ports) > 0 {
+		if (proto != protoTCP && proto != protoUDP) || len(l4) < 4 {
+			return false
+		}
+		srcPort, dstPort := binary.BigEndian.Uint16(l4[0:]), binary.BigEndian.Uint16(l4[2:])
+		for _, port := range f.ports {
+			if port != srcPort && port != dstPort {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func containsProto(protocols []uint8, proto uint8) bool {
+	for _, p := range protocols {
+		if p == proto {
+			return true
+		}
+	}
+	return false
+}
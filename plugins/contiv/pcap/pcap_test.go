@@ -0,0 +1,133 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// ipv4Frame builds an ethernet frame with IPv4 packet of the given L4 protocol.
+func ipv4Frame(proto uint8, src, dst string, srcPort, dstPort uint16) []byte {
+	frame := make([]byte, 14+20+8)
+	binary.BigEndian.PutUint16(frame[12:], etherTypeIPv4)
+	ip := frame[14:]
+	ip[0] = 0x45
+	ip[9] = proto
+	copy(ip[12:16], net.ParseIP(src).To4())
+	copy(ip[16:20], net.ParseIP(dst).To4())
+	binary.BigEndian.PutUint16(ip[20:], srcPort)
+	binary.BigEndian.PutUint16(ip[22:], dstPort)
+	return frame
+}
+
+func TestReadWritePcap(t *testing.T) {
+	RegisterTestingT(t)
+
+	rx := []*packet{
+		{tsSec: 10, tsUsec: 5, data: ipv4Frame(protoTCP, "10.1.1.2", "10.1.1.3", 40000, 80)},
+		{tsSec: 12, tsUsec: 0, data: ipv4Frame(protoUDP, "10.1.1.2", "10.96.0.10", 40001, 53)},
+	}
+	tx := []*packet{
+		{tsSec: 10, tsUsec: 7, data: ipv4Frame(protoTCP, "10.1.1.3", "10.1.1.2", 80, 40000)},
+	}
+	for _, pkt := range append(rx, tx...) {
+		pkt.origLen = uint32(len(pkt.data))
+	}
+
+	merged := mergePackets(rx, tx)
+	Expect(merged).To(HaveLen(3))
+	Expect(merged[0]).To(Equal(rx[0]))
+	Expect(merged[1]).To(Equal(tx[0]))
+	Expect(merged[2]).To(Equal(rx[1]))
+
+	buf := &bytes.Buffer{}
+	Expect(writePcap(buf, merged)).To(Succeed())
+	Expect(buf.Len()).To(Equal(pcapHeaderLen + 3*(pcapRecordHdrLen+42)))
+
+	packets, err := readPcap(buf.Bytes())
+	Expect(err).To(BeNil())
+	Expect(packets).To(Equal(merged))
+
+	_, err = readPcap(buf.Bytes()[:buf.Len()-1])
+	Expect(err).ToNot(BeNil())
+	_, err = readPcap([]byte("not a pcap file, just some text"))
+	Expect(err).ToNot(BeNil())
+}
+
+func TestFilter(t *testing.T) {
+	RegisterTestingT(t)
+
+	tcp := &packet{data: ipv4Frame(protoTCP, "10.1.1.2", "10.1.1.3", 40000, 80)}
+	udp := &packet{data: ipv4Frame(protoUDP, "10.1.1.2", "10.96.0.10", 40001, 53)}
+	icmp := &packet{data: ipv4Frame(protoICMP, "10.1.1.3", "10.1.1.2", 0, 0)}
+	packets := []*packet{tcp, udp, icmp}
+
+	flt, err := parseFilter("")
+	Expect(err).To(BeNil())
+	Expect(flt.apply(packets)).To(Equal(packets))
+
+	flt, err = parseFilter("tcp port 80")
+	Expect(err).To(BeNil())
+	Expect(flt.apply(packets)).To(Equal([]*packet{tcp}))
+
+	flt, err = parseFilter("udp and dst 10.96.0.10")
+	Expect(err).To(BeNil())
+	Expect(flt.apply(packets)).To(Equal([]*packet{udp}))
+
+	flt, err = parseFilter("host 10.1.1.3")
+	Expect(err).To(BeNil())
+	Expect(flt.apply(packets)).To(Equal([]*packet{tcp, icmp}))
+
+	flt, err = parseFilter("src 10.1.1.3 port 80")
+	Expect(err).To(BeNil())
+	Expect(flt.apply(packets)).To(BeEmpty())
+
+	_, err = parseFilter("port")
+	Expect(err).ToNot(BeNil())
+	_, err = parseFilter("port http")
+	Expect(err).ToNot(BeNil())
+	_, err = parseFilter("host 10.1.1")
+	Expect(err).ToNot(BeNil())
+	_, err = parseFilter("tcp or udp")
+	Expect(err).ToNot(BeNil())
+}
+
+func TestNormalizeRequest(t *testing.T) {
+	RegisterTestingT(t)
+
+	req := &CaptureRequest{PodNamespace: "default", PodName: "nginx"}
+	Expect(normalizeRequest(req)).To(Succeed())
+	Expect(req.Direction).To(Equal(DirectionBoth))
+	Expect(req.MaxPackets).To(BeEquivalentTo(DefaultMaxPackets))
+	Expect(req.Duration).To(BeEquivalentTo(DefaultDuration))
+	Expect(traceDirections(req.Direction)).To(Equal([]string{DirectionRx, DirectionTx}))
+
+	req = &CaptureRequest{PodNamespace: "default", PodName: "nginx", Direction: DirectionTx}
+	Expect(normalizeRequest(req)).To(Succeed())
+	Expect(traceDirections(req.Direction)).To(Equal([]string{DirectionTx}))
+
+	Expect(normalizeRequest(&CaptureRequest{PodName: "nginx"})).ToNot(Succeed())
+	Expect(normalizeRequest(&CaptureRequest{PodNamespace: "default", PodName: "nginx",
+		Direction: "in"})).ToNot(Succeed())
+	Expect(normalizeRequest(&CaptureRequest{PodNamespace: "default", PodName: "nginx",
+		MaxPackets: MaxPacketsLimit + 1})).ToNot(Succeed())
+	Expect(normalizeRequest(&CaptureRequest{PodNamespace: "default", PodName: "nginx",
+		Duration: MaxDurationLimit + 1})).ToNot(Succeed())
+}
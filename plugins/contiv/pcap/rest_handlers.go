@@ -0,0 +1,100 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcap
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gorilla/mux"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+)
+
+const (
+	// Prefix is versioned prefix for REST urls
+	Prefix = "/contiv/v1/"
+	// PluginURL is versioned URL (using prefix) for the packet capture REST endpoint
+	PluginURL = Prefix + "pcap"
+
+	captureIDVar = "id"
+	captureURL   = PluginURL + "/{" + captureIDVar + "}"
+	fileURL      = captureURL + "/file"
+)
+
+func (c *Capturer) registerHandlers(http rest.HTTPHandlers) {
+	if http == nil {
+		c.logger.Warnf("No http handler provided, skipping registration of packet capture REST handlers")
+		return
+	}
+	http.RegisterHTTPHandler(PluginURL, c.startHandler, "POST")
+	http.RegisterHTTPHandler(PluginURL, c.listHandler, "GET")
+	http.RegisterHTTPHandler(captureURL, c.getHandler, "GET")
+	http.RegisterHTTPHandler(fileURL, c.fileHandler, "GET")
+	c.logger.Infof("Packet capture REST handlers registered: POST/GET %v, GET %v, GET %v",
+		PluginURL, captureURL, fileURL)
+}
+
+func (c *Capturer) startHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		captureReq := &CaptureRequest{}
+		if err := json.NewDecoder(req.Body).Decode(captureReq); err != nil {
+			formatter.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		capture, err := c.Start(captureReq)
+		if err == ErrCaptureRunning || err == ErrTraceBusy {
+			formatter.JSON(w, http.StatusConflict, err.Error())
+			return
+		}
+		if err != nil {
+			c.logger.Errorf("Error starting packet capture: %v", err)
+			formatter.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		formatter.JSON(w, http.StatusOK, capture)
+	}
+}
+
+func (c *Capturer) listHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		formatter.JSON(w, http.StatusOK, c.List())
+	}
+}
+
+func (c *Capturer) getHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		capture, exists := c.Get(mux.Vars(req)[captureIDVar])
+		if !exists {
+			formatter.JSON(w, http.StatusNotFound, "capture not found")
+			return
+		}
+		formatter.JSON(w, http.StatusOK, capture)
+	}
+}
+
+func (c *Capturer) fileHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		path, err := c.FilePath(mux.Vars(req)[captureIDVar])
+		if err != nil {
+			formatter.JSON(w, http.StatusNotFound, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.tcpdump.pcap")
+		w.Header().Set("Content-Disposition", "attachment; filename="+filepath.Base(path))
+		http.ServeFile(w, req, path)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	govppapi "git.fd.io/govpp.git/api"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/vpp-agent/plugins/vpp/binapi/interfaces"
+	"github.com/ligato/vpp-agent/plugins/vpp/binapi/vpe"
+	"github.com/ligato/vpp-agent/plugins/vpp/ifplugin/ifaceidx"
+)
+
+// DirectionDrop selects the VPP pcap trace of packets dropped by VPP (Tracer only).
+const DirectionDrop = "drop"
+
+// ErrTraceBusy is returned when a pcap trace is requested in a direction
+// already traced by another owner.
+var ErrTraceBusy = errors.New("VPP pcap trace is used by another component")
+
+// Tracer is the single owner of the VPP pcap traces. VPP supports only one pcap
+// trace per direction (rx, tx and drop) and starting a trace silently replaces
+// the running one. All components of the agent using the pcap trace (e.g. the
+// on-demand packet capture) therefore start and stop the traces through
+// the Tracer, which lends each direction to one owner at a time.
+type Tracer struct {
+	logger    logging.Logger
+	mutex     sync.Mutex
+	govppChan govppapi.Channel
+	swIfIndex ifaceidx.SwIfIndex
+
+	traces map[string]*trace // direction -> running trace
+}
+
+// trace is a running VPP pcap trace.
+type trace struct {
+	owner     string
+	swIfIndex uint32
+	file      string // name of the file in vppPcapDir
+}
+
+// NewTracer creates new Tracer of the VPP pcap traces.
+func NewTracer(logger logging.Logger, govppChan govppapi.Channel, swIfIndex ifaceidx.SwIfIndex) *Tracer {
+	return &Tracer{
+		logger:    logger,
+		govppChan: govppChan,
+		swIfIndex: swIfIndex,
+		traces:    make(map[string]*trace),
+	}
+}
+
+// StartTrace starts VPP pcap trace of packets received (rx), sent (tx) or dropped
+// (drop) on the interface with the given logical name, limited to <maxPackets>.
+// For the drop trace the interface is the one the packets were received from.
+// Returns ErrTraceBusy if the direction is already traced by another owner.
+func (t *Tracer) StartTrace(owner, direction, ifName string, maxPackets uint32) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if running, busy := t.traces[direction]; busy {
+		if running.owner != owner {
+			return ErrTraceBusy
+		}
+		return fmt.Errorf("pcap %s trace is already running", direction)
+	}
+	swIfIndex, _, exists := t.swIfIndex.LookupIdx(ifName)
+	if !exists {
+		return fmt.Errorf("interface %s was not found in VPP", ifName)
+	}
+	vppIfName, err := t.getVppIfName(swIfIndex)
+	if err != nil {
+		return err
+	}
+
+	tr := &trace{
+		owner:     owner,
+		swIfIndex: swIfIndex,
+		file:      fmt.Sprintf("contiv-%s-%s%s", owner, direction, pcapFileExt),
+	}
+	os.Remove(filepath.Join(vppPcapDir, tr.file))
+	cmd := fmt.Sprintf("pcap %s trace on max %d intfc %s file %s", direction, maxPackets, vppIfName, tr.file)
+	if err := t.executeCLI(cmd); err != nil {
+		return err
+	}
+	t.traces[direction] = tr
+	return nil
+}
+
+// StopTrace stops VPP pcap trace started by the owner in the given direction
+// and returns the index of the traced interface together with the content
+// of the pcap file (nil if nothing was traced).
+func (t *Tracer) StopTrace(owner, direction string) (swIfIndex uint32, content []byte, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tr, running := t.traces[direction]
+	if !running || tr.owner != owner {
+		return 0, nil, fmt.Errorf("pcap %s trace is not owned by %s", direction, owner)
+	}
+	delete(t.traces, direction)
+
+	// the trace is already stopped by VPP if the max. number of packets has been reached
+	if err := t.executeCLI(fmt.Sprintf("pcap %s trace off", direction)); err != nil {
+		t.logger.Debugf("Stopping pcap %s trace: %v", direction, err)
+	}
+	path := filepath.Join(vppPcapDir, tr.file)
+	content, err = ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		// nothing traced
+		return tr.swIfIndex, nil, nil
+	}
+	if err != nil {
+		return tr.swIfIndex, nil, err
+	}
+	os.Remove(path)
+	return tr.swIfIndex, content, nil
+}
+
+// GetVppIfName returns the VPP internal name of the interface with the given logical name.
+func (t *Tracer) GetVppIfName(ifName string) (string, error) {
+	swIfIndex, _, exists := t.swIfIndex.LookupIdx(ifName)
+	if !exists {
+		return "", fmt.Errorf("interface %s was not found in VPP", ifName)
+	}
+	return t.getVppIfName(swIfIndex)
+}
+
+// getVppIfName returns the VPP internal name of the interface with the given index.
+func (t *Tracer) getVppIfName(swIfIndex uint32) (string, error) {
+	req := &interfaces.SwInterfaceDump{}
+	reqCtx := t.govppChan.SendMultiRequest(req)
+	var name string
+	for {
+		msg := &interfaces.SwInterfaceDetails{}
+		stop, err := reqCtx.ReceiveReply(msg)
+		if err != nil {
+			return "", err
+		}
+		if stop {
+			break
+		}
+		if msg.SwIfIndex == swIfIndex {
+			name = string(bytes.TrimRight(msg.InterfaceName, "\x00"))
+		}
+	}
+	if name == "" {
+		return "", fmt.Errorf("interface with sw_if_index=%d was not found in VPP", swIfIndex)
+	}
+	return name, nil
+}
+
+// executeCLI executes VPP CLI command and returns an error if the command has failed.
+func (t *Tracer) executeCLI(cmd string) error {
+	t.logger.Debugf("Executing VPP CLI: %s", cmd)
+	req := &vpe.CliInband{
+		Cmd: []byte(cmd),
+	}
+	reply := &vpe.CliInbandReply{}
+	if err := t.govppChan.SendRequest(req).ReceiveReply(reply); err != nil {
+		return err
+	}
+	// CLI errors are reported only in the textual reply
+	out := strings.TrimSpace(string(reply.Reply))
+	if strings.Contains(out, "error") || strings.Contains(out, "unknown input") ||
+		strings.Contains(out, "already") {
+		return fmt.Errorf("VPP CLI '%s' failed: %s", cmd, out)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.fd.io/govpp.git/adapter/mock"
+	"git.fd.io/govpp.git/codec"
+	govpp "git.fd.io/govpp.git/core"
+	"github.com/ligato/cn-infra/logging/logrus"
+	"github.com/ligato/vpp-agent/plugins/vpp/binapi/vpe"
+	. "github.com/onsi/gomega"
+)
+
+func TestTracerOwnership(t *testing.T) {
+	RegisterTestingT(t)
+
+	// VPP mock recording executed CLI commands
+	var commands []string
+	vppMock := mock.NewVppAdapter()
+	vppMock.MockReplyHandler(func(request mock.MessageDTO) (reply []byte, msgID uint16, prepared bool) {
+		if request.MsgName != "cli_inband" {
+			return nil, 0, false
+		}
+		cli := &vpe.CliInband{}
+		if err := (&codec.MsgCodec{}).DecodeMsg(request.Data, cli); err != nil {
+			return nil, 0, false
+		}
+		commands = append(commands, string(cli.Cmd))
+		replyMsg, msgID, _ := vppMock.ReplyFor(request.MsgName)
+		reply, err := vppMock.ReplyBytes(request, replyMsg)
+		return reply, msgID, err == nil
+	})
+	conn, err := govpp.Connect(vppMock)
+	Expect(err).To(BeNil())
+	defer conn.Disconnect()
+	govppChan, err := conn.NewAPIChannel()
+	Expect(err).To(BeNil())
+	defer govppChan.Close()
+
+	tracer := NewTracer(logrus.DefaultLogger(), govppChan, nil)
+
+	// rx trace on sw_if_index 1 is running for the packet capture
+	const traceFile = "contiv-pcap-test-rx.pcap"
+	tracer.traces[DirectionRx] = &trace{owner: captureOwner, swIfIndex: 1, file: traceFile}
+
+	// another component can neither start nor stop the trace
+	Expect(tracer.StartTrace("other", DirectionRx, "tap1", 10)).To(Equal(ErrTraceBusy))
+	_, _, err = tracer.StopTrace("other", DirectionRx)
+	Expect(err).ToNot(BeNil())
+	Expect(commands).To(BeEmpty())
+
+	// the owner stops the trace and receives the traced packets
+	path := filepath.Join(vppPcapDir, traceFile)
+	file, err := os.Create(path)
+	Expect(err).To(BeNil())
+	data := ipv4Frame(protoTCP, "10.1.1.2", "10.1.1.3", 40000, 80)
+	Expect(writePcap(file, []*packet{{tsSec: 10, data: data, origLen: uint32(len(data))}})).To(Succeed())
+	file.Close()
+
+	swIfIndex, content, err := tracer.StopTrace(captureOwner, DirectionRx)
+	Expect(err).To(BeNil())
+	Expect(swIfIndex).To(BeEquivalentTo(1))
+	packets, err := readPcap(content)
+	Expect(err).To(BeNil())
+	Expect(packets).To(HaveLen(1))
+	Expect(commands).To(Equal([]string{"pcap rx trace off"}))
+	_, err = os.Stat(path)
+	Expect(os.IsNotExist(err)).To(BeTrue())
+
+	// the trace is released
+	_, _, err = tracer.StopTrace(captureOwner, DirectionRx)
+	Expect(err).ToNot(BeNil())
+	Expect(tracer.traces).To(BeEmpty())
+}
@@ -2,14 +2,22 @@ package contiv
 
 import (
 	"net"
+	"time"
 
 	"github.com/contiv/vpp/plugins/contiv/containeridx"
+	"github.com/contiv/vpp/plugins/contiv/pcap"
+	epmodel "github.com/contiv/vpp/plugins/ksr/model/endpoints"
 )
 
 // PodActionHook defines parameters and the return value of a callback triggered
 // during an event associated with a pod.
 type PodActionHook func(podNamespace string, podName string) error
 
+// RemoteEndpointsHook defines parameters and the return value of a callback triggered
+// when endpoints of a service from a remote cluster of the cluster mesh are imported,
+// changed or removed (<eps> is nil).
+type RemoteEndpointsHook func(clusterID string, epsID epmodel.ID, eps *epmodel.Endpoints) error
+
 // API for other plugins to query network-related information.
 type API interface {
 	// GetIfName looks up logical interface name that corresponds to the interface
@@ -57,6 +65,26 @@ type API interface {
 	// GetServiceLocalEndpointWeight returns the load-balancing weight assigned to locally deployed service endpoints.
 	GetServiceLocalEndpointWeight() uint8
 
+	// GetServiceRenderer returns the name of the renderer selected for services
+	// which do not request any specific renderer (empty for the default one).
+	GetServiceRenderer() string
+
+	// GetServiceBackendDrainPeriod returns for how long the terminating or no longer
+	// ready service backends keep their existing connections (zero to drop them immediately).
+	GetServiceBackendDrainPeriod() time.Duration
+
+	// GetDNSProxyConfig returns configuration for learning of IP addresses
+	// referenced by FQDN-based policy rules.
+	GetDNSProxyConfig() *DNSProxyConfig
+
+	// GetHostEndpointPolicyConfig returns configuration of host-endpoint
+	// policies (fail-safe rules, protected node ports).
+	GetHostEndpointPolicyConfig() *HostEndpointPolicyConfig
+
+	// GetPcapTracer returns the owner of the VPP pcap traces. All users of the traces
+	// have to start and stop them through the tracer (VPP supports one trace per direction).
+	GetPcapTracer() *pcap.Tracer
+
 	// GetNatLoopbackIP returns the IP address of a virtual loopback, used to route traffic
 	// between clients and services via VPP even if the source and destination are the same
 	// IP addresses and would otherwise be routed locally.
@@ -86,6 +114,10 @@ type API interface {
 	// interconnecting VPP with the host stack.
 	GetHostInterconnectIfName() string
 
+	// GetHostInterconnectIP returns the IP address of the host (Linux) end of the interconnect
+	// between VPP and the host stack.
+	GetHostInterconnectIP() net.IP
+
 	// GetVxlanBVIIfName returns the name of an BVI interface facing towards VXLAN tunnels to other hosts.
 	// Returns an empty string if VXLAN is not used (in L2 interconnect mode).
 	GetVxlanBVIIfName() string
@@ -103,6 +135,10 @@ type API interface {
 	// pod once it is added and before the CNI reply is sent.
 	RegisterPodPostAddHook(hook PodActionHook)
 
+	// RegisterRemoteEndpointsHook allows to register callback that will be run for each
+	// change in the endpoints imported from a remote cluster of the cluster mesh.
+	RegisterRemoteEndpointsHook(hook RemoteEndpointsHook)
+
 	// GetMainVrfID returns the ID of the main network connectivity VRF.
 	GetMainVrfID() uint32
 
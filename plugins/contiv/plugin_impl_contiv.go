@@ -23,6 +23,7 @@ import (
 	"net"
 
 	"strings"
+	"time"
 
 	"git.fd.io/govpp.git/api"
 	"github.com/apparentlymart/go-cidr/cidr"
@@ -31,12 +32,16 @@ import (
 	"github.com/contiv/vpp/plugins/contiv/ipam"
 	"github.com/contiv/vpp/plugins/contiv/model/cni"
 	"github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/contiv/vpp/plugins/contiv/pcap"
+	"github.com/contiv/vpp/plugins/contiv/vpptrace"
+	"github.com/contiv/vpp/plugins/ksr"
 	protoNode "github.com/contiv/vpp/plugins/ksr/model/node"
 	"github.com/contiv/vpp/plugins/kvdbproxy"
 	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/cn-infra/datasync/resync"
 	"github.com/ligato/cn-infra/db/keyval"
 	"github.com/ligato/cn-infra/db/keyval/etcd"
+	"github.com/ligato/cn-infra/health/statuscheck"
 	"github.com/ligato/cn-infra/infra"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/rpc/grpc"
@@ -57,6 +62,13 @@ type Plugin struct {
 
 	configuredContainers *containeridx.ConfigIndex
 	cniServer            *remoteCNIserver
+	clusterMesh          *clusterMesh
+	statusReporter       *vswitchStatusReporter
+	pcapTracer           *pcap.Tracer
+	pcapCapturer         *pcap.Capturer
+	pcapGovppCh          api.Channel
+	tracer               *vpptrace.Tracer
+	traceGovppCh         api.Channel
 
 	nodeIDAllocator   *idAllocator
 	nodeIDsresyncChan chan datasync.ResyncEvent
@@ -77,16 +89,17 @@ type Plugin struct {
 // Deps groups the dependencies of the Plugin.
 type Deps struct {
 	infra.PluginDeps
-	ServiceLabel servicelabel.ReaderAPI
-	GRPC         grpc.Server
-	Proxy        *kvdbproxy.Plugin
-	VPP          *vpp.Plugin
-	GoVPP        govppmux.API
-	Resync       resync.Subscriber
-	ETCD         *etcd.Plugin
-	Bolt         keyval.KvProtoPlugin
-	Watcher      datasync.KeyValProtoWatcher
-	HTTPHandlers rest.HTTPHandlers
+	ServiceLabel  servicelabel.ReaderAPI
+	GRPC          grpc.Server
+	Proxy         *kvdbproxy.Plugin
+	VPP           *vpp.Plugin
+	GoVPP         govppmux.API
+	Resync        resync.Subscriber
+	ETCD          *etcd.Plugin
+	Bolt          keyval.KvProtoPlugin
+	Watcher       datasync.KeyValProtoWatcher
+	HTTPHandlers  rest.HTTPHandlers
+	StatusMonitor statuscheck.StatusReader
 }
 
 // Config represents configuration for the Contiv plugin.
@@ -114,9 +127,30 @@ type Config struct {
 	MainVRFID                   uint32
 	PodVRFID                    uint32
 	ServiceLocalEndpointWeight  uint8
-	DisableNATVirtualReassembly bool // if true, NAT plugin will drop fragmented packets
+	ServiceRenderer             string // renderer of services without the renderer annotation: "nat44" (default) or "maglev"
+	ServiceBackendDrainPeriod   uint32 // for how long (in seconds) terminating or not-ready service backends keep existing connections
+	DisableNATVirtualReassembly bool   // if true, NAT plugin will drop fragmented packets
 	IPAMConfig                  ipam.Config
 	NodeConfig                  []OneNodeConfig
+	ClusterMesh                 ClusterMeshConfig        // pod-to-pod connectivity with remote Contiv clusters
+	PcapDir                     string                   // directory where pcap files of pod packet captures are stored
+	DNSProxy                    DNSProxyConfig           // learning of IP addresses for FQDN-based policy rules
+	HostEndpointPolicy          HostEndpointPolicyConfig // host-endpoint policies protecting the node itself
+}
+
+// DNSProxyConfig configures how the agent learns IP addresses of domain names
+// referenced by FQDN-based egress policy rules.
+type DNSProxyConfig struct {
+	ListenAddress  string // UDP address (IP:port) of the DNS proxy snooping pod DNS responses, empty = disabled
+	UpstreamServer string // DNS server (IP:port) to forward queries to and to resolve FQDNs with, empty = system resolver
+	MinTTL         uint32 // minimum time (in seconds) a learned IP address is kept for
+}
+
+// HostEndpointPolicyConfig configures host-endpoint policies protecting the host
+// network stack and the node ports of this node.
+type HostEndpointPolicyConfig struct {
+	FailSafeInbound []string // "<protocol>:<port>" always allowed into the host, empty = SSH, DHCP, etcd, API server and kubelet
+	NodePortRange   string   // "<first>-<last>" range of node ports protected on the physical interfaces, empty = 30000-32767
 }
 
 // OneNodeConfig represents configuration for one node. It contains only settings specific to given node.
@@ -162,7 +196,8 @@ func (plugin *Plugin) Init() error {
 	if plugin.myNodeConfig != nil {
 		nodeIP = plugin.myNodeConfig.MainVPPInterface.IP
 	}
-	plugin.nodeIDAllocator = newIDAllocator(plugin.ETCD, plugin.ServiceLabel.GetAgentLabel(), nodeIP)
+	plugin.nodeIDAllocator = newIDAllocator(plugin.ETCD,
+		plugin.ServiceLabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel), plugin.ServiceLabel.GetAgentLabel(), nodeIP)
 	nodeID, err := plugin.nodeIDAllocator.getID()
 	if err != nil {
 		return err
@@ -206,6 +241,39 @@ func (plugin *Plugin) Init() error {
 	}
 	cni.RegisterRemoteCNIServer(plugin.GRPC.GetServer(), plugin.cniServer)
 
+	// export this node for the remote clusters of the mesh whenever its IP addresses change
+	// and withdraw the exports of the nodes removed from the cluster
+	plugin.clusterMesh = newClusterMesh(plugin.Log, &plugin.Config.ClusterMesh, plugin.cniServer,
+		plugin.ETCD.NewBroker(plugin.ServiceLabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)))
+	plugin.nodeIDAllocator.onUpdate = plugin.clusterMesh.exportNode
+	plugin.cniServer.nodeRemovedHook = plugin.clusterMesh.unexportNode
+
+	// publish the vswitch health status for contiv-ksr to maintain the node condition
+	plugin.statusReporter = newVswitchStatusReporter(plugin.Log, plugin.ServiceLabel.GetAgentLabel(),
+		plugin.ETCD.NewBroker(plugin.ServiceLabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)),
+		plugin.StatusMonitor, plugin.cniServer)
+
+	// enable on-demand packet capture on pod interfaces (uses its own GoVPP channel
+	// not to interfere with the requests of the CNI server)
+	plugin.pcapGovppCh, err = plugin.GoVPP.NewAPIChannel()
+	if err != nil {
+		return err
+	}
+	plugin.pcapTracer = pcap.NewTracer(plugin.Log.NewLogger("-pcapTracer"), plugin.pcapGovppCh,
+		plugin.VPP.GetSwIfIndexes())
+	plugin.pcapCapturer, err = pcap.New(plugin.Log.NewLogger("-pcap"), plugin.Config.PcapDir, plugin.pcapTracer,
+		plugin.GetIfName, plugin.HTTPHandlers)
+	if err != nil {
+		return fmt.Errorf("Can't create packet capturer due to error: %v ", err)
+	}
+
+	// expose VPP packet tracer
+	plugin.traceGovppCh, err = plugin.GoVPP.NewAPIChannel()
+	if err != nil {
+		return err
+	}
+	plugin.tracer = vpptrace.New(plugin.Log.NewLogger("-trace"), plugin.traceGovppCh, plugin.HTTPHandlers)
+
 	plugin.nodeIPWatcher = make(chan string, 1)
 	go plugin.watchEvents()
 	plugin.cniServer.WatchNodeIP(plugin.nodeIPWatcher)
@@ -224,15 +292,18 @@ func (plugin *Plugin) AfterInit() error {
 		reg := plugin.Resync.Register(string(plugin.PluginName))
 		go plugin.handleResync(reg.StatusChan())
 	}
-	return nil
+	plugin.statusReporter.start(plugin.ctx)
+	return plugin.clusterMesh.start()
 }
 
 // Close is called by the plugin infra upon agent cleanup. It cleans up the resources allocated by the plugin.
 func (plugin *Plugin) Close() error {
 	plugin.ctxCancelFunc()
 	plugin.cniServer.close()
+	plugin.clusterMesh.close()
 	//plugin.nodeIDAllocator.releaseID()
-	_, err := safeclose.CloseAll(plugin.govppCh, plugin.nodeIDwatchReg, plugin.watchReg)
+	_, err := safeclose.CloseAll(plugin.pcapCapturer, plugin.govppCh, plugin.pcapGovppCh, plugin.traceGovppCh,
+		plugin.nodeIDwatchReg, plugin.watchReg)
 	return err
 }
 
@@ -342,6 +413,34 @@ func (plugin *Plugin) GetServiceLocalEndpointWeight() uint8 {
 	return plugin.Config.ServiceLocalEndpointWeight
 }
 
+// GetServiceRenderer returns the name of the renderer selected for services
+// which do not request any specific renderer.
+func (plugin *Plugin) GetServiceRenderer() string {
+	return plugin.Config.ServiceRenderer
+}
+
+// GetServiceBackendDrainPeriod returns for how long the terminating or no longer
+// ready service backends keep their existing connections.
+func (plugin *Plugin) GetServiceBackendDrainPeriod() time.Duration {
+	return time.Duration(plugin.Config.ServiceBackendDrainPeriod) * time.Second
+}
+
+// GetDNSProxyConfig returns configuration for learning of IP addresses referenced
+// by FQDN-based policy rules.
+func (plugin *Plugin) GetDNSProxyConfig() *DNSProxyConfig {
+	return &plugin.Config.DNSProxy
+}
+
+// GetHostEndpointPolicyConfig returns configuration of host-endpoint policies.
+func (plugin *Plugin) GetHostEndpointPolicyConfig() *HostEndpointPolicyConfig {
+	return &plugin.Config.HostEndpointPolicy
+}
+
+// GetPcapTracer returns the owner of the VPP pcap traces, shared by all users of the traces.
+func (plugin *Plugin) GetPcapTracer() *pcap.Tracer {
+	return plugin.pcapTracer
+}
+
 // GetNatLoopbackIP returns the IP address of a virtual loopback, used to route traffic
 // between clients and services via VPP even if the source and destination are the same
 // IP addresses and would otherwise be routed locally.
@@ -352,6 +451,12 @@ func (plugin *Plugin) GetNatLoopbackIP() net.IP {
 	return cidr.Dec(broadcastIP)
 }
 
+// GetHostInterconnectIP returns the IP address of the host (Linux) end of the interconnect
+// between VPP and the host stack.
+func (plugin *Plugin) GetHostInterconnectIP() net.IP {
+	return plugin.cniServer.ipam.VEthHostEndIP()
+}
+
 // GetNodeIP returns the IP address of this node.
 func (plugin *Plugin) GetNodeIP() (ip net.IP, network *net.IPNet) {
 	return plugin.cniServer.GetNodeIP()
@@ -411,6 +516,12 @@ func (plugin *Plugin) RegisterPodPostAddHook(hook PodActionHook) {
 	plugin.cniServer.RegisterPodPostAddHook(hook)
 }
 
+// RegisterRemoteEndpointsHook allows to register callback that will be run for each
+// change in the endpoints imported from a remote cluster of the cluster mesh.
+func (plugin *Plugin) RegisterRemoteEndpointsHook(hook RemoteEndpointsHook) {
+	plugin.clusterMesh.registerEndpointsHook(hook)
+}
+
 // GetMainVrfID returns the ID of the main network connectivity VRF.
 func (plugin *Plugin) GetMainVrfID() uint32 {
 	return plugin.cniServer.GetMainVrfID()
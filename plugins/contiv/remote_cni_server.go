@@ -131,6 +131,9 @@ type remoteCNIserver struct {
 	// podPostAddHooks is a slice of callbacks called once pod is added
 	podPostAddHook []PodActionHook
 
+	// nodeRemovedHook is called with the ID of every other node removed from the cluster
+	nodeRemovedHook func(nodeID uint32) error
+
 	// node specific configuration
 	nodeConfig *OneNodeConfig
 
@@ -142,6 +145,9 @@ type remoteCNIserver struct {
 	vswitchConnectivityConfigured bool
 	vswitchCond                   *sync.Cond
 
+	// set to true once the first resync of the vswitch configuration has succeeded
+	resyncCompleted bool
+
 	// if the flag is true only veth without stn and tcp stack is configured
 	disableTCPstack bool
 
@@ -272,11 +278,20 @@ func (s *remoteCNIserver) resync() error {
 	err := s.configureVswitchConnectivity()
 	if err != nil {
 		s.Logger.Error(err)
+	} else {
+		s.resyncCompleted = true
 	}
 
 	return err
 }
 
+// isResyncCompleted returns true if the vswitch configuration has been successfully resynced at least once.
+func (s *remoteCNIserver) isResyncCompleted() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.resyncCompleted
+}
+
 // close is called by the plugin infra when the CNI server needs to be stopped.
 func (s *remoteCNIserver) close() {
 	s.cleanupVswitchConnectivity()
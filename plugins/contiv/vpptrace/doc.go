@@ -0,0 +1,65 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vpptrace exposes the VPP packet tracer through the REST API
+// of the agent, with the trace output parsed into a structured form.
+//
+// The trace is started on the given VPP graph input nodes (by default on all
+// the input nodes through which packets from pods and other nodes may enter VPP):
+//
+//	$ curl -X POST localhost:9999/contiv/v1/trace -d '{"packets": 50}'
+//	{
+//	  "tracedNodes": ["virtio-input", "tapcli-rx", "af-packet-input", "dpdk-input"]
+//	}
+//
+// The traced packets are then returned as a list of graph nodes traversed
+// by each packet, together with all the IP flows seen by the IP nodes (e.g. both
+// the outer and the inner header of a packet received over VXLAN, addresses
+// before and after NAT). The packets can be filtered by the source and destination
+// IP address of any of the flows (in both directions). The nodes making ACL, NAT,
+// VXLAN and drop decisions are annotated:
+//
+//	$ curl "localhost:9999/contiv/v1/trace?src=10.1.1.3&dst=10.1.2.4"
+//	{
+//	  "packets": [
+//	    {
+//	      "id": 1,
+//	      "protocol": "ICMP",
+//	      "srcIP": "10.1.1.3",
+//	      "dstIP": "10.1.2.4",
+//	      "flows": [
+//	        {"protocol": "ICMP", "srcIP": "10.1.1.3", "dstIP": "10.1.2.4"}
+//	      ],
+//	      "nodes": [
+//	        {
+//	          "time": "00:10:31:364219",
+//	          "name": "virtio-input",
+//	          "details": ["virtio: hw_if_index 3 next-index 4 vring 0 len 98"]
+//	        },
+//	        ...
+//	        {
+//	          "time": "00:10:31:364302",
+//	          "name": "vxlan4-encap",
+//	          "details": ["VXLAN encap to vxlan_tunnel0 vni 10"],
+//	          "annotation": "VXLAN encap: VXLAN encap to vxlan_tunnel0 vni 10"
+//	        },
+//	        ...
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// contiv-netctl uses this API to trace packets between two pods across
+// the source and the destination node.
+package vpptrace
@@ -0,0 +1,191 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpptrace
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Trace is a parsed output of the VPP packet tracer.
+type Trace struct {
+	Packets []*Packet `json:"packets"`
+}
+
+// Packet is a single traced packet.
+// For packets received over VXLAN the first IP node sees the outer header,
+// the flow of the pods is seen by the IP nodes following the decapsulation.
+type Packet struct {
+	ID       int          `json:"id"`
+	Protocol string       `json:"protocol,omitempty"` // L4 protocol as seen by the first IP input node
+	SrcIP    string       `json:"srcIP,omitempty"`    // source IP as seen by the first IP input node
+	DstIP    string       `json:"dstIP,omitempty"`    // destination IP as seen by the first IP input node
+	Flows    []*Flow      `json:"flows,omitempty"`    // all IP flows seen by the IP nodes (the addresses may change e.g. by NAT)
+	Nodes    []*NodeEntry `json:"nodes"`
+}
+
+// Flow is an IP flow seen by an IP node.
+type Flow struct {
+	Protocol string `json:"protocol"`
+	SrcIP    string `json:"srcIP"`
+	DstIP    string `json:"dstIP"`
+}
+
+// NodeEntry describes the processing of a packet by a single VPP graph node.
+type NodeEntry struct {
+	Time       string   `json:"time"`
+	Name       string   `json:"name"`
+	Details    []string `json:"details,omitempty"`
+	Annotation string   `json:"annotation,omitempty"` // ACL/NAT/VXLAN/drop decision made by the node
+}
+
+var (
+	packetRegex = regexp.MustCompile(`^Packet (\d+)$`)
+	nodeRegex   = regexp.MustCompile(`^(\d+:\d+:\d+:\d+): (\S+)$`)
+	ipFlowRegex = regexp.MustCompile(`^(\S+): ([0-9a-fA-F.:]+) -> ([0-9a-fA-F.:]+)$`)
+	aclRegex    = regexp.MustCompile(`action:? (\d+)`)
+)
+
+// ParseTrace parses the output of the "show trace" VPP CLI command.
+func ParseTrace(output string) *Trace {
+	trace := &Trace{Packets: []*Packet{}}
+	var (
+		packet *Packet
+		node   *NodeEntry
+	)
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "---") {
+			// empty line or thread separator
+			continue
+		}
+		if match := packetRegex.FindStringSubmatch(trimmed); match != nil {
+			id, _ := strconv.Atoi(match[1])
+			packet = &Packet{ID: id}
+			trace.Packets = append(trace.Packets, packet)
+			node = nil
+			continue
+		}
+		if packet == nil {
+			continue
+		}
+		if match := nodeRegex.FindStringSubmatch(trimmed); match != nil {
+			node = &NodeEntry{Time: match[1], Name: match[2]}
+			packet.Nodes = append(packet.Nodes, node)
+			continue
+		}
+		if node == nil {
+			continue
+		}
+		node.Details = append(node.Details, trimmed)
+		if isIPNode(node.Name) {
+			// L4 ports are printed in the same format as addresses
+			match := ipFlowRegex.FindStringSubmatch(trimmed)
+			if match != nil && net.ParseIP(match[2]) != nil && net.ParseIP(match[3]) != nil {
+				if packet.SrcIP == "" {
+					packet.Protocol, packet.SrcIP, packet.DstIP = match[1], match[2], match[3]
+				}
+				if packet.Flow(match[2], match[3]) == nil {
+					packet.Flows = append(packet.Flows, &Flow{Protocol: match[1], SrcIP: match[2], DstIP: match[3]})
+				}
+			}
+		}
+	}
+
+	for _, packet := range trace.Packets {
+		for _, node := range packet.Nodes {
+			node.Annotation = annotate(node)
+		}
+	}
+	return trace
+}
+
+// Filter returns only packets of the flow between the given IP addresses (in any direction).
+// Empty address matches any address.
+func (t *Trace) Filter(ip1, ip2 string) *Trace {
+	if ip1 == "" && ip2 == "" {
+		return t
+	}
+	filtered := &Trace{Packets: []*Packet{}}
+	for _, packet := range t.Packets {
+		for _, flow := range packet.Flows {
+			if (matchesIP(flow.SrcIP, ip1) && matchesIP(flow.DstIP, ip2)) ||
+				(matchesIP(flow.SrcIP, ip2) && matchesIP(flow.DstIP, ip1)) {
+				filtered.Packets = append(filtered.Packets, packet)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// FirstPacket returns the first packet seen by any IP node as sent from <srcIP>
+// to <dstIP> (including the inner flow of VXLAN-encapsulated packets), nil if there
+// is no such packet.
+func (t *Trace) FirstPacket(srcIP, dstIP string) *Packet {
+	for _, packet := range t.Packets {
+		if packet.Flow(srcIP, dstIP) != nil {
+			return packet
+		}
+	}
+	return nil
+}
+
+// Flow returns the flow from <srcIP> to <dstIP> seen by the IP nodes, nil if the packet
+// was never seen with these addresses.
+func (p *Packet) Flow(srcIP, dstIP string) *Flow {
+	for _, flow := range p.Flows {
+		if flow.SrcIP == srcIP && flow.DstIP == dstIP {
+			return flow
+		}
+	}
+	return nil
+}
+
+// annotate returns a description of the decision made by the node (if any).
+func annotate(node *NodeEntry) string {
+	details := strings.Join(node.Details, " ")
+	switch {
+	case strings.HasPrefix(node.Name, "acl-plugin"):
+		if match := aclRegex.FindStringSubmatch(details); match != nil {
+			if match[1] == "0" {
+				return "ACL: deny"
+			}
+			return "ACL: permit"
+		}
+	case strings.HasPrefix(node.Name, "nat44"):
+		if len(node.Details) > 0 {
+			return "NAT: " + node.Details[0]
+		}
+		return "NAT: " + node.Name
+	case strings.HasPrefix(node.Name, "vxlan") && strings.Contains(node.Name, "encap"):
+		return "VXLAN encap: " + details
+	case strings.HasPrefix(node.Name, "vxlan") && strings.Contains(node.Name, "input"):
+		return "VXLAN decap: " + details
+	case strings.HasSuffix(node.Name, "drop"):
+		return "DROP: " + details
+	}
+	return ""
+}
+
+func isIPNode(nodeName string) bool {
+	return strings.HasPrefix(nodeName, "ip4-") || strings.HasPrefix(nodeName, "ip6-")
+}
+
+func matchesIP(ip, filter string) bool {
+	return filter == "" || ip == filter
+}
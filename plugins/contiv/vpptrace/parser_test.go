@@ -0,0 +1,187 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpptrace
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const traceOutput = `------------------- Start of thread 0 vpp_main -------------------
+Packet 1
+
+00:10:31:364219: virtio-input
+  virtio: hw_if_index 3 next-index 4 vring 0 len 98
+    hdr: flags 0x00 gso_type 0x00 hdr_len 0 gso_size 0 csum_start 0 csum_offset 0 num_buffers 1
+00:10:31:364230: ethernet-input
+  IP4: 00:00:00:00:00:02 -> 01:23:45:67:89:42
+00:10:31:364240: ip4-input
+  ICMP: 10.1.1.3 -> 10.1.2.4
+    tos 0x00, ttl 64, length 84, checksum 0x1234
+    fragment id 0x5e8c, flags DONT_FRAGMENT
+  ICMP echo_request checksum 0x4fa5
+00:10:31:364250: acl-plugin-in-ip4-fa
+  acl-plugin: lc_index: 0, sw_if_index 3, next index 1, action: 1, match: acl 2 rule 0 trace_bits 00000000
+00:10:31:364260: nat44-in2out
+  NAT44_IN2OUT_FAST_PATH: sw_if_index 3, next index 3, session -1
+00:10:31:364270: ip4-lookup
+  fib 1 dpo-idx 5 flow hash: 0x00000000
+  ICMP: 10.1.1.3 -> 10.1.2.4
+00:10:31:364302: vxlan4-encap
+  VXLAN encap to vxlan_tunnel0 vni 10
+00:10:31:364310: GigabitEthernet0/8/0-tx
+  GigabitEthernet0/8/0 tx queue 0
+
+Packet 2
+
+00:10:31:364411: virtio-input
+  virtio: hw_if_index 4 next-index 4 vring 0 len 60
+00:10:31:364420: ip4-input
+  UDP: 10.1.1.5 -> 10.96.0.10
+    tos 0x00, ttl 64, length 60, checksum 0x1234
+00:10:31:364430: acl-plugin-in-ip4-fa
+  acl-plugin: lc_index: 0, sw_if_index 4, next index 0, action: 0, match: acl 3 rule 2 trace_bits 00000000
+00:10:31:364440: error-drop
+  acl-plugin-in-ip4-fa: ACL deny packets
+`
+
+// decapTraceOutput is a trace of the packet from traceOutput received over VXLAN
+// by the node of the destination pod.
+const decapTraceOutput = `------------------- Start of thread 0 vpp_main -------------------
+Packet 1
+
+00:10:31:500100: dpdk-input
+  GigabitEthernet0/8/0 rx queue 0
+  buffer 0x8a3b: current data 0, length 134, free-list 0, clone-count 0, totlen-nifb 0, trace 0x0
+  IP4: 08:00:27:aa:bb:cc -> 08:00:27:dd:ee:ff
+  UDP: 192.168.16.1 -> 192.168.16.2
+    tos 0x00, ttl 253, length 134, checksum 0x1234
+    fragment id 0x0000
+  UDP: 24320 -> 4789
+    length 114, checksum 0x0000
+00:10:31:500110: ip4-input-no-checksum
+  UDP: 192.168.16.1 -> 192.168.16.2
+    tos 0x00, ttl 253, length 134, checksum 0x1234
+    fragment id 0x0000
+  UDP: 24320 -> 4789
+    length 114, checksum 0x0000
+00:10:31:500120: ip4-lookup
+  fib 0 dpo-idx 7 flow hash: 0x00000000
+  UDP: 192.168.16.1 -> 192.168.16.2
+    tos 0x00, ttl 253, length 134, checksum 0x1234
+00:10:31:500130: ip4-local
+    UDP: 192.168.16.1 -> 192.168.16.2
+      tos 0x00, ttl 253, length 134, checksum 0x1234
+00:10:31:500140: ip4-udp-lookup
+  UDP: src-port 24320 dst-port 4789
+00:10:31:500150: vxlan4-input
+  VXLAN decap from vxlan_tunnel0 vni 10 next 1 error 0
+00:10:31:500160: l2-input
+  l2-input: sw_if_index 5 dst 01:23:45:67:89:42 src 00:00:00:00:00:02
+00:10:31:500170: ip4-input
+  ICMP: 10.1.1.3 -> 10.1.2.4
+    tos 0x00, ttl 63, length 84, checksum 0x1234
+    fragment id 0x5e8c, flags DONT_FRAGMENT
+  ICMP echo_request checksum 0x4fa5
+00:10:31:500180: acl-plugin-out-ip4-fa
+  acl-plugin: lc_index: 1, sw_if_index 3, next index 1, action: 1, match: acl 4 rule 1 trace_bits 00000000
+00:10:31:500190: ip4-rewrite
+  tx_sw_if_index 3 dpo-idx 9 : ipv4 via 10.1.2.4 tap1: mtu:1500
+  00000000: 00000000000201234567894208004500005476a540003f01adc40a0101030a01
+00:10:31:500200: tap1-output
+  tap1
+`
+
+func TestParseTrace(t *testing.T) {
+	RegisterTestingT(t)
+
+	trace := ParseTrace(traceOutput)
+	Expect(trace.Packets).To(HaveLen(2))
+
+	packet := trace.Packets[0]
+	Expect(packet.ID).To(Equal(1))
+	Expect(packet.Protocol).To(Equal("ICMP"))
+	Expect(packet.SrcIP).To(Equal("10.1.1.3"))
+	Expect(packet.DstIP).To(Equal("10.1.2.4"))
+	Expect(packet.Nodes).To(HaveLen(8))
+	Expect(packet.Nodes[0].Name).To(Equal("virtio-input"))
+	Expect(packet.Nodes[0].Time).To(Equal("00:10:31:364219"))
+	Expect(packet.Nodes[0].Details).To(HaveLen(2))
+	Expect(packet.Nodes[0].Annotation).To(BeEmpty())
+	Expect(packet.Nodes[3].Annotation).To(Equal("ACL: permit"))
+	Expect(packet.Nodes[4].Annotation).To(HavePrefix("NAT: "))
+	Expect(packet.Nodes[6].Annotation).To(Equal("VXLAN encap: VXLAN encap to vxlan_tunnel0 vni 10"))
+
+	packet = trace.Packets[1]
+	Expect(packet.Protocol).To(Equal("UDP"))
+	Expect(packet.Nodes).To(HaveLen(4))
+	Expect(packet.Nodes[2].Annotation).To(Equal("ACL: deny"))
+	Expect(packet.Nodes[3].Annotation).To(Equal("DROP: acl-plugin-in-ip4-fa: ACL deny packets"))
+}
+
+func TestFilterTrace(t *testing.T) {
+	RegisterTestingT(t)
+
+	trace := ParseTrace(traceOutput)
+	Expect(trace.Filter("", "").Packets).To(HaveLen(2))
+
+	filtered := trace.Filter("10.1.1.3", "10.1.2.4")
+	Expect(filtered.Packets).To(HaveLen(1))
+	Expect(filtered.Packets[0].ID).To(Equal(1))
+
+	// reverse direction
+	filtered = trace.Filter("10.1.2.4", "10.1.1.3")
+	Expect(filtered.Packets).To(HaveLen(1))
+
+	filtered = trace.Filter("10.1.1.5", "")
+	Expect(filtered.Packets).To(HaveLen(1))
+	Expect(filtered.Packets[0].ID).To(Equal(2))
+
+	Expect(trace.Filter("10.1.1.3", "10.96.0.10").Packets).To(BeEmpty())
+	Expect(ParseTrace("No packets in trace buffer").Packets).To(BeEmpty())
+}
+
+func TestFirstPacketDecapsulated(t *testing.T) {
+	RegisterTestingT(t)
+
+	// the trace is passed to netctl in JSON
+	b, err := json.Marshal(ParseTrace(decapTraceOutput).Filter("10.1.1.3", "10.1.2.4"))
+	Expect(err).To(BeNil())
+	trace := &Trace{}
+	Expect(json.Unmarshal(b, trace)).To(Succeed())
+	Expect(trace.Packets).To(HaveLen(1))
+
+	// the first IP node sees the outer header
+	packet := trace.Packets[0]
+	Expect(packet.SrcIP).To(Equal("192.168.16.1"))
+	Expect(packet.DstIP).To(Equal("192.168.16.2"))
+	Expect(packet.Flows).To(Equal([]*Flow{
+		{Protocol: "UDP", SrcIP: "192.168.16.1", DstIP: "192.168.16.2"},
+		{Protocol: "ICMP", SrcIP: "10.1.1.3", DstIP: "10.1.2.4"},
+	}))
+	Expect(packet.Nodes[5].Annotation).To(Equal("VXLAN decap: VXLAN decap from vxlan_tunnel0 vni 10 next 1 error 0"))
+
+	// the packet of the pods is found by the inner flow
+	Expect(trace.FirstPacket("10.1.1.3", "10.1.2.4")).To(Equal(packet))
+	Expect(packet.Flow("10.1.1.3", "10.1.2.4").Protocol).To(Equal("ICMP"))
+	Expect(trace.FirstPacket("10.1.2.4", "10.1.1.3")).To(BeNil())
+
+	// source node - the flow is seen before the encapsulation
+	trace = ParseTrace(traceOutput)
+	Expect(trace.FirstPacket("10.1.1.3", "10.1.2.4").ID).To(Equal(1))
+	Expect(trace.FirstPacket("10.1.1.5", "10.96.0.10").ID).To(Equal(2))
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpptrace
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+)
+
+const (
+	// Prefix is versioned prefix for REST urls
+	Prefix = "/contiv/v1/"
+	// PluginURL is versioned URL (using prefix) for the packet trace REST endpoint
+	PluginURL = Prefix + "trace"
+
+	// query parameters used to filter traced packets
+	srcIPParam = "src"
+	dstIPParam = "dst"
+)
+
+func (t *Tracer) registerHandlers(http rest.HTTPHandlers) {
+	if http == nil {
+		t.logger.Warnf("No http handler provided, skipping registration of packet trace REST handlers")
+		return
+	}
+	http.RegisterHTTPHandler(PluginURL, t.startHandler, "POST")
+	http.RegisterHTTPHandler(PluginURL, t.getHandler, "GET")
+	http.RegisterHTTPHandler(PluginURL, t.clearHandler, "DELETE")
+	t.logger.Infof("Packet trace REST handlers registered: POST/GET/DELETE %v", PluginURL)
+}
+
+func (t *Tracer) startHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		traceReq := &TraceRequest{}
+		if err := json.NewDecoder(req.Body).Decode(traceReq); err != nil && err != io.EOF {
+			formatter.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		started, err := t.Start(traceReq)
+		if err != nil {
+			t.logger.Errorf("Error starting packet trace: %v", err)
+			formatter.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		formatter.JSON(w, http.StatusOK, started)
+	}
+}
+
+func (t *Tracer) getHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		trace, err := t.Get()
+		if err != nil {
+			t.logger.Errorf("Error getting packet trace: %v", err)
+			formatter.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		query := req.URL.Query()
+		formatter.JSON(w, http.StatusOK, trace.Filter(query.Get(srcIPParam), query.Get(dstIPParam)))
+	}
+}
+
+func (t *Tracer) clearHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if err := t.Clear(); err != nil {
+			t.logger.Errorf("Error clearing packet trace: %v", err)
+			formatter.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		formatter.JSON(w, http.StatusOK, nil)
+	}
+}
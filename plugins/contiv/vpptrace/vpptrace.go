@@ -0,0 +1,147 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpptrace
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	govppapi "git.fd.io/govpp.git/api"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/ligato/vpp-agent/plugins/vpp/binapi/vpe"
+)
+
+const (
+	// DefaultPackets is the default number of packets traced on each input node.
+	DefaultPackets = 50
+	// MaxPacketsLimit is the upper bound for the number of packets traced on each input node.
+	MaxPacketsLimit = 1000
+)
+
+// DefaultInputNodes are the VPP graph input nodes traced by default - the nodes
+// through which packets from pods (tap, tapv2, veth) and from other nodes (DPDK)
+// enter VPP.
+var DefaultInputNodes = []string{"virtio-input", "tapcli-rx", "af-packet-input", "dpdk-input"}
+
+// TraceRequest represents a request to start the VPP packet tracer.
+type TraceRequest struct {
+	InputNodes []string `json:"inputNodes,omitempty"` // defaults to DefaultInputNodes
+	Packets    uint32   `json:"packets,omitempty"`    // number of packets traced on each input node
+}
+
+// TraceStarted is a reply to the TraceRequest.
+type TraceStarted struct {
+	TracedNodes []string `json:"tracedNodes"`
+	Errors      []string `json:"errors,omitempty"` // input nodes which could not be traced
+}
+
+// Tracer controls the VPP packet tracer.
+type Tracer struct {
+	logger    logging.Logger
+	mutex     sync.Mutex
+	govppChan govppapi.Channel
+}
+
+// New creates new VPP packet tracer and registers its REST handlers.
+func New(logger logging.Logger, govppChan govppapi.Channel, http rest.HTTPHandlers) *Tracer {
+	t := &Tracer{
+		logger:    logger,
+		govppChan: govppChan,
+	}
+	t.registerHandlers(http)
+	return t
+}
+
+// Start clears the previous trace and starts tracing on the requested input nodes.
+// If the input nodes are not specified, all default input nodes available in VPP
+// are traced.
+func (t *Tracer) Start(req *TraceRequest) (*TraceStarted, error) {
+	packets := req.Packets
+	if packets == 0 {
+		packets = DefaultPackets
+	}
+	if packets > MaxPacketsLimit {
+		return nil, fmt.Errorf("number of traced packets is limited to %d", MaxPacketsLimit)
+	}
+	inputNodes := req.InputNodes
+	if len(inputNodes) == 0 {
+		inputNodes = DefaultInputNodes
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, err := t.executeCLI("clear trace"); err != nil {
+		return nil, err
+	}
+	started := &TraceStarted{TracedNodes: []string{}}
+	for _, node := range inputNodes {
+		// input nodes of VPP plugins which are not loaded are not available
+		if _, err := t.executeCLI(fmt.Sprintf("trace add %s %d", node, packets)); err != nil {
+			started.Errors = append(started.Errors, err.Error())
+			continue
+		}
+		started.TracedNodes = append(started.TracedNodes, node)
+	}
+	if len(started.TracedNodes) == 0 {
+		return nil, errors.New("failed to trace any input node: " + strings.Join(started.Errors, ", "))
+	}
+	t.logger.Infof("Started VPP packet trace on %v", started.TracedNodes)
+	return started, nil
+}
+
+// Get returns the current trace output parsed.
+func (t *Tracer) Get() (*Trace, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	output, err := t.executeCLI(fmt.Sprintf("show trace max %d", MaxPacketsLimit))
+	if err != nil {
+		return nil, err
+	}
+	return ParseTrace(output), nil
+}
+
+// Clear clears the trace and stops tracing.
+func (t *Tracer) Clear() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	_, err := t.executeCLI("clear trace")
+	return err
+}
+
+// executeCLI executes VPP CLI command and returns its output or an error if the command has failed.
+func (t *Tracer) executeCLI(cmd string) (string, error) {
+	t.logger.Debugf("Executing VPP CLI: %s", cmd)
+	req := &vpe.CliInband{
+		Cmd: []byte(cmd),
+	}
+	reply := &vpe.CliInbandReply{}
+	if err := t.govppChan.SendRequest(req).ReceiveReply(reply); err != nil {
+		return "", err
+	}
+	// CLI errors are reported only in the textual reply
+	out := string(reply.Reply)
+	firstLine := strings.TrimSpace(strings.SplitN(out, "\n", 2)[0])
+	if strings.Contains(firstLine, "error") || strings.Contains(firstLine, "unknown input") ||
+		strings.Contains(firstLine, "not found") {
+		return "", fmt.Errorf("VPP CLI '%s' failed: %s", cmd, firstLine)
+	}
+	return out, nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contiv
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/health/statuscheck"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"github.com/ligato/cn-infra/logging"
+)
+
+const (
+	// vswitchStatusPeriod is the period in which the vswitch status is published.
+	vswitchStatusPeriod = 5 * time.Second
+
+	// vswitchStatusTTL is the TTL of the published vswitch status. If the agent
+	// stops refreshing the status (e.g. because it crashed), contiv-ksr will consider
+	// the vswitch as not ready once the status expires.
+	vswitchStatusTTL = 3 * vswitchStatusPeriod
+
+	// govppPluginName is the name under which govppmux reports the state of the VPP connection.
+	govppPluginName = "govpp"
+)
+
+// vswitchStatusReporter periodically publishes the health status of the vswitch
+// into ETCD for contiv-ksr, which maintains the corresponding K8s node condition.
+type vswitchStatusReporter struct {
+	logging.Logger
+
+	nodeName      string
+	broker        keyval.ProtoBroker
+	statusMonitor statuscheck.StatusReader
+	cniServer     *remoteCNIserver
+
+	// last published status, used only to log changes
+	lastStatus *node.VswitchStatus
+}
+
+// newVswitchStatusReporter creates new vswitch status reporter, which will publish
+// the status using the given broker (expected to be prefixed with the KSR prefix).
+func newVswitchStatusReporter(logger logging.Logger, nodeName string, broker keyval.ProtoBroker,
+	statusMonitor statuscheck.StatusReader, cniServer *remoteCNIserver) *vswitchStatusReporter {
+	return &vswitchStatusReporter{
+		Logger:        logger,
+		nodeName:      nodeName,
+		broker:        broker,
+		statusMonitor: statusMonitor,
+		cniServer:     cniServer,
+	}
+}
+
+// start starts the go routine periodically publishing the vswitch status until the context is cancelled.
+func (r *vswitchStatusReporter) start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(vswitchStatusPeriod)
+		defer ticker.Stop()
+		for {
+			r.publishStatus()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// getStatus evaluates the current status of the vswitch.
+func (r *vswitchStatusReporter) getStatus() *node.VswitchStatus {
+	vswitchStatus := &node.VswitchStatus{NodeName: r.nodeName}
+	var reasons []string
+
+	if govppStatus, found := r.statusMonitor.GetAllPluginStatus()[govppPluginName]; found &&
+		govppStatus.State == status.OperationalState_OK {
+		vswitchStatus.VppConnected = true
+	} else {
+		reasons = append(reasons, "not connected to VPP")
+	}
+
+	if r.cniServer.isResyncCompleted() {
+		vswitchStatus.Resynced = true
+	} else {
+		reasons = append(reasons, "vswitch configuration not resynced")
+	}
+
+	if r.statusMonitor.GetAgentStatus().State == status.OperationalState_OK {
+		vswitchStatus.AgentOk = true
+	} else {
+		reasons = append(reasons, "agent state is not OK")
+	}
+
+	vswitchStatus.Message = strings.Join(reasons, ", ")
+	return vswitchStatus
+}
+
+// publishStatus evaluates the current vswitch status and writes it into ETCD with TTL.
+func (r *vswitchStatusReporter) publishStatus() {
+	vswitchStatus := r.getStatus()
+	if r.lastStatus == nil || *r.lastStatus != *vswitchStatus {
+		r.Infof("Vswitch status: %+v", *vswitchStatus)
+		r.lastStatus = vswitchStatus
+	}
+
+	err := r.broker.Put(node.VswitchStatusKey(r.nodeName), vswitchStatus, datasync.WithTTL(vswitchStatusTTL))
+	if err != nil {
+		r.Warnf("Failed to publish vswitch status: %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterpolicy
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/workqueue"
+
+	apiextcs "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	k8sCache "k8s.io/client-go/tools/cache"
+
+	"github.com/contiv/vpp/plugins/crd/handler"
+	"github.com/contiv/vpp/plugins/crd/handler/clusterpolicy"
+	"github.com/contiv/vpp/plugins/crd/pkg/apis/clusterpolicy/v1"
+	"github.com/contiv/vpp/plugins/crd/utils"
+
+	crdClientSet "github.com/contiv/vpp/plugins/crd/pkg/client/clientset/versioned"
+	factory "github.com/contiv/vpp/plugins/crd/pkg/client/informers/externalversions"
+	informers "github.com/contiv/vpp/plugins/crd/pkg/client/informers/externalversions/clusterpolicy/v1"
+	listers "github.com/contiv/vpp/plugins/crd/pkg/client/listers/clusterpolicy/v1"
+)
+
+// HostEndpointPolicyController struct defines how a controller should encapsulate
+// logging, client connectivity, informing (list and watching) queueing, and
+// handling of resource changes
+type HostEndpointPolicyController struct {
+	Deps
+
+	CrdClient *crdClientSet.Clientset
+	APIClient *apiextcs.Clientset
+
+	clientset kubernetes.Interface
+	queue     workqueue.RateLimitingInterface
+	// HostEndpointPolicy CRD specifics
+	policyInformer informers.HostEndpointPolicyInformer
+	policyLister   listers.HostEndpointPolicyLister
+	// event handlers for HostEndpointPolicy CRDs
+	eventHandler handler.Handler
+}
+
+// Init performs the initialization of HostEndpointPolicy Controller
+func (c *HostEndpointPolicyController) Init() error {
+
+	var event Event
+
+	c.Log.Info("HostEndpointPolicy-Controller: initializing...")
+
+	crdName := reflect.TypeOf(v1.HostEndpointPolicy{}).Name()
+	err := c.createCRD(v1.CRDFullContivHostEndpointPolicyName,
+		v1.CRDGroup,
+		v1.CRDGroupVersion,
+		v1.CRDContivHostEndpointPolicyPlural,
+		crdName)
+
+	if err != nil {
+		c.Log.Error("Error initializing CRD")
+		return err
+	}
+
+	sharedFactory := factory.NewSharedInformerFactory(c.CrdClient, time.Second*30)
+	c.policyInformer = sharedFactory.Clusterpolicy().V1().HostEndpointPolicies()
+	c.policyLister = c.policyInformer.Lister()
+
+	// Create a new queue in that when the informer gets a resource from listing or watching,
+	// adding the identifying key to the queue for the handler
+	c.queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+	// Add event handlers to handle the three types of events for resources (add, update, delete)
+	c.policyInformer.Informer().AddEventHandler(k8sCache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			event.key, err = k8sCache.MetaNamespaceKeyFunc(obj)
+			event.eventType = "create"
+			event.resource = obj
+			c.Log.Infof("Add HostEndpointPolicy resource with key: %s", event.key)
+			if err == nil {
+				c.queue.Add(event)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			event.key, err = k8sCache.MetaNamespaceKeyFunc(newObj)
+			event.resource = newObj
+			event.oldResource = oldObj
+			event.eventType = "update"
+			c.Log.Infof("Update HostEndpointPolicy resource with key: %s", event.key)
+			if err == nil {
+				c.queue.Add(event)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			event.key, err = k8sCache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			event.eventType = "delete"
+			event.resource = obj
+			c.Log.Infof("Delete HostEndpointPolicy resource with key: %s", event.key)
+			if err == nil {
+				c.queue.Add(event)
+			}
+		},
+	})
+	c.eventHandler = &clusterpolicy.HostEndpointPolicyHandler{
+		Deps: clusterpolicy.Deps{
+			Log:     c.Log,
+			Publish: c.Publish,
+		},
+	}
+
+	return nil
+}
+
+// Run this in the plugin_crd_impl, it's the controller loop
+func (c *HostEndpointPolicyController) Run(ctx <-chan struct{}) {
+	// handle a panic with logging and exiting
+	defer utilruntime.HandleCrash()
+	// ignore new items and shutdown when done
+	defer c.queue.ShutDown()
+
+	c.Log.Info("HostEndpointPolicy-Controller: Starting...")
+
+	// runs the informer to list and watch on a goroutine
+	go c.policyInformer.Informer().Run(ctx)
+
+	// populate resources one after synchronization
+	if !k8sCache.WaitForCacheSync(ctx, c.HasSynced) {
+		utilruntime.HandleError(fmt.Errorf("Error syncing cache"))
+		return
+	}
+	c.Log.Info("Controller.Run: cache sync complete")
+
+	// runWorker method runs every second using a stop channel
+	wait.Until(c.runWorker, time.Second, ctx)
+}
+
+// HasSynced indicates when the controller is synced up with the K8s.
+func (c *HostEndpointPolicyController) HasSynced() bool {
+	return c.policyInformer.Informer().HasSynced()
+}
+
+// runWorker processes new items in the queue
+func (c *HostEndpointPolicyController) runWorker() {
+	c.Log.Info("HostEndpointPolicy-Controller: Running..")
+
+	// invoke processNextItem to fetch and consume the next change
+	// to a watched or listed resource
+	for c.processNextItem() {
+		c.Log.Info("HostEndpointPolicy-Controller-runWorker: processing next item...")
+	}
+
+	c.Log.Info("HostEndpointPolicy-Controller-runWorker: Completed")
+}
+
+// processNextItem retrieves next queued item, acts accordingly for object CRUD
+func (c *HostEndpointPolicyController) processNextItem() bool {
+	// get the next item (blocking) from the queue and process or
+	// quit if shutdown requested
+	event, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(event)
+
+	err := c.processItem(event.(Event))
+	if err == nil {
+		// If there is no error reset the rate limit counters
+		c.queue.Forget(event)
+	} else if c.queue.NumRequeues(event) < maxRetries {
+		c.Log.Errorf("Error processing %s (will retry): %v", event.(Event).key, err)
+		c.queue.AddRateLimited(event)
+	} else {
+		// err != nil and too many retries
+		c.Log.Errorf("Error processing %s (giving up): %v", event.(Event).key, err)
+		c.queue.Forget(event)
+		utilruntime.HandleError(err)
+	}
+
+	// keep the worker loop running by returning true
+	return true
+}
+
+// processItem processes the next item from the queue and send the event update
+// to the host-endpoint policy event handler
+func (c *HostEndpointPolicyController) processItem(event Event) error {
+
+	// process events based on its type
+	switch event.eventType {
+	case "create":
+		// get object's metadata
+		objectMeta := utils.GetObjectMetaData(event.resource)
+		// compare CreationTimestamp and serverStartTime and alert only on latest events
+		if objectMeta.CreationTimestamp.Sub(serverStartTime).Seconds() > 0 {
+			c.eventHandler.ObjectCreated(event.resource)
+			return nil
+		}
+	case "update":
+		c.eventHandler.ObjectUpdated(event.oldResource, event.resource)
+		return nil
+	case "delete":
+		c.eventHandler.ObjectDeleted(event.resource)
+		return nil
+	}
+	return nil
+}
+
+// Create the CRD resource, ignore error if it already exists
+func (c *HostEndpointPolicyController) createCRD(FullName, Group, Version, Plural, Name string) error {
+	c.Log.Info("Creating HostEndpointPolicy CRD")
+	return createClusterScopedCRD(c.APIClient, FullName, Group, Version, Plural, Name)
+}
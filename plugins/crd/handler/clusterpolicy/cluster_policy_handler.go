@@ -0,0 +1,188 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:generate protoc -I ./model --go_out=plugins=grpc:./model ./model/clusterpolicy.proto
+
+package clusterpolicy
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ligato/cn-infra/datasync/kvdbsync"
+	"github.com/ligato/cn-infra/logging"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
+	"github.com/contiv/vpp/plugins/crd/pkg/apis/clusterpolicy/v1"
+)
+
+// Handler handler implements Handler interface,
+type Handler struct {
+	Deps
+}
+
+// Deps defines dependencies for ClusterPolicy CRD Handler.
+type Deps struct {
+	Log     logging.Logger
+	Publish *kvdbsync.Plugin // KeyProtoValWriter does not define Delete
+}
+
+// Init initializes handler configuration
+// ClusterPolicy Handler will be taking action on resource CRUD
+func (h *Handler) Init() error {
+	return nil
+}
+
+// ObjectCreated is called when a CRD object is created
+func (h *Handler) ObjectCreated(obj interface{}) {
+	h.Log.Debugf("Object created with value: %v", obj)
+	clusterPolicy, ok := obj.(*v1.ClusterPolicy)
+	if !ok {
+		h.Log.Warn("Failed to cast newly created cluster-policy object")
+		return
+	}
+
+	h.Publish.Put(model.Key(clusterPolicy.GetName()), ClusterPolicyToProto(clusterPolicy))
+}
+
+// ObjectDeleted is called when a CRD object is deleted
+func (h *Handler) ObjectDeleted(obj interface{}) {
+	h.Log.Debugf("Object deleted with value: %v", obj)
+	clusterPolicy, ok := obj.(*v1.ClusterPolicy)
+	if !ok {
+		h.Log.Warn("Failed to cast delete event")
+		return
+	}
+
+	h.Publish.Delete(model.Key(clusterPolicy.GetName()))
+}
+
+// ObjectUpdated is called when a CRD object is updated
+func (h *Handler) ObjectUpdated(oldObj, newObj interface{}) {
+	h.Log.Debugf("Object updated with value: %v", newObj)
+	clusterPolicy, ok := newObj.(*v1.ClusterPolicy)
+	if !ok {
+		h.Log.Warn("Failed to cast updated cluster-policy object")
+		return
+	}
+
+	h.Publish.Put(model.Key(clusterPolicy.GetName()), ClusterPolicyToProto(clusterPolicy))
+}
+
+// ClusterPolicyToProto converts cluster policy data from the Contiv's own CRD
+// representation into the corresponding protobuf-modelled data format.
+func ClusterPolicyToProto(clusterPolicy *v1.ClusterPolicy) *model.ClusterPolicy {
+	policyProto := &model.ClusterPolicy{}
+	policyProto.Name = clusterPolicy.Name
+	policyProto.Priority = clusterPolicy.Spec.Priority
+	if clusterPolicy.Spec.NamespaceSelector != nil {
+		policyProto.Namespaces = labelSelectorToProto(clusterPolicy.Spec.NamespaceSelector)
+	}
+	policyProto.Pods = labelSelectorToProto(&clusterPolicy.Spec.PodSelector)
+	for _, rule := range clusterPolicy.Spec.Ingress {
+		policyProto.IngressRule = append(policyProto.IngressRule, ruleToProto(rule))
+	}
+	for _, rule := range clusterPolicy.Spec.Egress {
+		policyProto.EgressRule = append(policyProto.EgressRule, ruleToProto(rule))
+	}
+	return policyProto
+}
+
+func ruleToProto(rule v1.ClusterPolicyRule) *model.ClusterPolicy_Rule {
+	ruleProto := &model.ClusterPolicy_Rule{}
+	if strings.EqualFold(string(rule.Action), string(v1.ActionDeny)) {
+		ruleProto.Action = model.ClusterPolicy_DENY
+	} else {
+		ruleProto.Action = model.ClusterPolicy_ALLOW
+	}
+	for _, peer := range rule.Peers {
+		peerProto := &model.ClusterPolicy_Peer{}
+		if peer.NamespaceSelector != nil {
+			peerProto.Namespaces = labelSelectorToProto(peer.NamespaceSelector)
+		}
+		if peer.PodSelector != nil {
+			peerProto.Pods = labelSelectorToProto(peer.PodSelector)
+		}
+		if peer.IPBlock != nil {
+			peerProto.IpBlock = &model.ClusterPolicy_Peer_IPBlock{
+				Cidr:   peer.IPBlock.CIDR,
+				Except: peer.IPBlock.Except,
+			}
+		}
+		peerProto.Fqdn = strings.ToLower(strings.TrimSuffix(peer.FQDN, "."))
+		if peer.Service != nil {
+			peerProto.Service = &model.ClusterPolicy_Peer_ServiceRef{
+				Name:      peer.Service.Name,
+				Namespace: peer.Service.Namespace,
+			}
+			if peerProto.Service.Namespace == "" {
+				peerProto.Service.Namespace = metav1.NamespaceDefault
+			}
+		}
+		ruleProto.Peers = append(ruleProto.Peers, peerProto)
+	}
+	for _, port := range rule.Ports {
+		portProto := &model.ClusterPolicy_Port{Port: port.Port, EndPort: port.EndPort, IcmpType: -1, IcmpCode: -1}
+		switch strings.ToUpper(port.Protocol) {
+		case "UDP":
+			portProto.Protocol = model.ClusterPolicy_Port_UDP
+		case "SCTP":
+			portProto.Protocol = model.ClusterPolicy_Port_SCTP
+		case "ICMP":
+			portProto.Protocol = model.ClusterPolicy_Port_ICMP
+			if port.ICMPType != nil {
+				portProto.IcmpType = *port.ICMPType
+			}
+			if port.ICMPCode != nil {
+				portProto.IcmpCode = *port.ICMPCode
+			}
+		default:
+			portProto.Protocol = model.ClusterPolicy_Port_TCP
+		}
+		ruleProto.Ports = append(ruleProto.Ports, portProto)
+	}
+	return ruleProto
+}
+
+func labelSelectorToProto(selector *metav1.LabelSelector) *model.ClusterPolicy_LabelSelector {
+	selectorProto := &model.ClusterPolicy_LabelSelector{}
+	for key, val := range selector.MatchLabels {
+		selectorProto.MatchLabel = append(selectorProto.MatchLabel, &model.ClusterPolicy_Label{Key: key, Value: val})
+	}
+	for _, expression := range selector.MatchExpressions {
+		expressionProto := &model.ClusterPolicy_LabelSelector_LabelExpression{
+			Key:   expression.Key,
+			Value: expression.Values,
+		}
+		switch expression.Operator {
+		case metav1.LabelSelectorOpIn:
+			expressionProto.Operator = model.ClusterPolicy_LabelSelector_LabelExpression_IN
+		case metav1.LabelSelectorOpNotIn:
+			expressionProto.Operator = model.ClusterPolicy_LabelSelector_LabelExpression_NOT_IN
+		case metav1.LabelSelectorOpExists:
+			expressionProto.Operator = model.ClusterPolicy_LabelSelector_LabelExpression_EXISTS
+		case metav1.LabelSelectorOpDoesNotExist:
+			expressionProto.Operator = model.ClusterPolicy_LabelSelector_LabelExpression_DOES_NOT_EXIST
+		}
+		selectorProto.MatchExpression = append(selectorProto.MatchExpression, expressionProto)
+	}
+
+	// Make sure that match labels are always stored in the same order to avoid
+	// unnecessary updates during resync.
+	sort.Slice(selectorProto.MatchLabel, func(i, j int) bool {
+		return selectorProto.MatchLabel[i].Key < selectorProto.MatchLabel[j].Key
+	})
+	return selectorProto
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterpolicy
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
+	"github.com/contiv/vpp/plugins/crd/pkg/apis/clusterpolicy/v1"
+)
+
+func TestClusterPolicyToProto(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	icmpType := int32(8)
+	policy := &v1.ClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "deny-external"},
+		Spec: v1.ClusterPolicySpec{
+			Priority: 10,
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "env", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"dev", "test"}},
+				},
+			},
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"tier": "web", "app": "shop"},
+			},
+			Ingress: []v1.ClusterPolicyRule{
+				{
+					Action: "deny",
+					Peers: []v1.ClusterPolicyPeer{
+						{IPBlock: &v1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+					},
+					Ports: []v1.ClusterPolicyPort{
+						{Protocol: "udp", Port: 53},
+						{Protocol: "ICMP", ICMPType: &icmpType},
+					},
+				},
+			},
+			Egress: []v1.ClusterPolicyRule{
+				{
+					Action: v1.ActionAllow,
+					Peers: []v1.ClusterPolicyPeer{
+						{NamespaceSelector: &metav1.LabelSelector{}},
+						{FQDN: "API.Example.com."},
+						{Service: &v1.ServiceReference{Name: "db"}},
+					},
+					Ports: []v1.ClusterPolicyPort{{Port: 8000, EndPort: 8080}},
+				},
+			},
+		},
+	}
+
+	policyProto := ClusterPolicyToProto(policy)
+	gomega.Expect(policyProto.Name).To(gomega.Equal("deny-external"))
+	gomega.Expect(policyProto.Priority).To(gomega.BeEquivalentTo(10))
+
+	// selectors, match labels are sorted by key
+	gomega.Expect(policyProto.Namespaces.MatchLabel).To(gomega.BeEmpty())
+	gomega.Expect(policyProto.Namespaces.MatchExpression).To(gomega.Equal([]*model.ClusterPolicy_LabelSelector_LabelExpression{
+		{Key: "env", Operator: model.ClusterPolicy_LabelSelector_LabelExpression_NOT_IN, Value: []string{"dev", "test"}},
+	}))
+	gomega.Expect(policyProto.Pods.MatchLabel).To(gomega.Equal([]*model.ClusterPolicy_Label{
+		{Key: "app", Value: "shop"},
+		{Key: "tier", Value: "web"},
+	}))
+
+	// ingress rule
+	gomega.Expect(policyProto.IngressRule).To(gomega.HaveLen(1))
+	ingress := policyProto.IngressRule[0]
+	gomega.Expect(ingress.Action).To(gomega.Equal(model.ClusterPolicy_DENY))
+	gomega.Expect(ingress.Peers).To(gomega.HaveLen(1))
+	gomega.Expect(ingress.Peers[0].IpBlock).To(gomega.Equal(&model.ClusterPolicy_Peer_IPBlock{
+		Cidr: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}))
+	gomega.Expect(ingress.Peers[0].Namespaces).To(gomega.BeNil())
+	gomega.Expect(ingress.Peers[0].Pods).To(gomega.BeNil())
+	gomega.Expect(ingress.Ports).To(gomega.Equal([]*model.ClusterPolicy_Port{
+		{Protocol: model.ClusterPolicy_Port_UDP, Port: 53, IcmpType: -1, IcmpCode: -1},
+		{Protocol: model.ClusterPolicy_Port_ICMP, IcmpType: 8, IcmpCode: -1},
+	}))
+
+	// egress rule
+	gomega.Expect(policyProto.EgressRule).To(gomega.HaveLen(1))
+	egress := policyProto.EgressRule[0]
+	gomega.Expect(egress.Action).To(gomega.Equal(model.ClusterPolicy_ALLOW))
+	gomega.Expect(egress.Peers).To(gomega.HaveLen(3))
+	gomega.Expect(egress.Peers[0].Namespaces).To(gomega.Equal(&model.ClusterPolicy_LabelSelector{}))
+	gomega.Expect(egress.Peers[0].Pods).To(gomega.BeNil())
+	gomega.Expect(egress.Peers[1].Fqdn).To(gomega.Equal("api.example.com"))
+	gomega.Expect(egress.Peers[2].Service).To(gomega.Equal(&model.ClusterPolicy_Peer_ServiceRef{
+		Name: "db", Namespace: metav1.NamespaceDefault}))
+	gomega.Expect(egress.Ports).To(gomega.Equal([]*model.ClusterPolicy_Port{
+		{Protocol: model.ClusterPolicy_Port_TCP, Port: 8000, EndPort: 8080, IcmpType: -1, IcmpCode: -1},
+	}))
+}
+
+func TestClusterPolicyToProtoAllNamespaces(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	policyProto := ClusterPolicyToProto(&v1.ClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "all-pods"},
+	})
+	// nil namespace selector selects all namespaces, empty pod selector all pods
+	gomega.Expect(policyProto.Namespaces).To(gomega.BeNil())
+	gomega.Expect(policyProto.Pods).To(gomega.Equal(&model.ClusterPolicy_LabelSelector{}))
+	gomega.Expect(policyProto.IngressRule).To(gomega.BeEmpty())
+	gomega.Expect(policyProto.EgressRule).To(gomega.BeEmpty())
+}
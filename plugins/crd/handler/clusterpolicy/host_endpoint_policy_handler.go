@@ -0,0 +1,81 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterpolicy
+
+import (
+	"github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
+	"github.com/contiv/vpp/plugins/crd/pkg/apis/clusterpolicy/v1"
+)
+
+// HostEndpointPolicyHandler implements Handler interface for HostEndpointPolicy
+// CRD. It reuses dependencies of the ClusterPolicy handler.
+type HostEndpointPolicyHandler struct {
+	Deps
+}
+
+// Init initializes handler configuration
+// HostEndpointPolicy Handler will be taking action on resource CRUD
+func (h *HostEndpointPolicyHandler) Init() error {
+	return nil
+}
+
+// ObjectCreated is called when a CRD object is created
+func (h *HostEndpointPolicyHandler) ObjectCreated(obj interface{}) {
+	h.Log.Debugf("Object created with value: %v", obj)
+	policy, ok := obj.(*v1.HostEndpointPolicy)
+	if !ok {
+		h.Log.Warn("Failed to cast newly created host-endpoint-policy object")
+		return
+	}
+
+	h.Publish.Put(model.HostEndpointPolicyKey(policy.GetName()), HostEndpointPolicyToProto(policy))
+}
+
+// ObjectDeleted is called when a CRD object is deleted
+func (h *HostEndpointPolicyHandler) ObjectDeleted(obj interface{}) {
+	h.Log.Debugf("Object deleted with value: %v", obj)
+	policy, ok := obj.(*v1.HostEndpointPolicy)
+	if !ok {
+		h.Log.Warn("Failed to cast delete event")
+		return
+	}
+
+	h.Publish.Delete(model.HostEndpointPolicyKey(policy.GetName()))
+}
+
+// ObjectUpdated is called when a CRD object is updated
+func (h *HostEndpointPolicyHandler) ObjectUpdated(oldObj, newObj interface{}) {
+	h.Log.Debugf("Object updated with value: %v", newObj)
+	policy, ok := newObj.(*v1.HostEndpointPolicy)
+	if !ok {
+		h.Log.Warn("Failed to cast updated host-endpoint-policy object")
+		return
+	}
+
+	h.Publish.Put(model.HostEndpointPolicyKey(policy.GetName()), HostEndpointPolicyToProto(policy))
+}
+
+// HostEndpointPolicyToProto converts host-endpoint policy data from the Contiv's
+// own CRD representation into the corresponding protobuf-modelled data format.
+func HostEndpointPolicyToProto(policy *v1.HostEndpointPolicy) *model.HostEndpointPolicy {
+	policyProto := &model.HostEndpointPolicy{}
+	policyProto.Name = policy.Name
+	policyProto.Priority = policy.Spec.Priority
+	policyProto.Nodes = labelSelectorToProto(&policy.Spec.NodeSelector)
+	for _, rule := range policy.Spec.Ingress {
+		policyProto.IngressRule = append(policyProto.IngressRule, ruleToProto(rule))
+	}
+	return policyProto
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/contiv/vpp/plugins/ksr/model/ksrkey"
+)
+
+// KeyPrefix return prefix where all cluster policies are persisted.
+func KeyPrefix() string {
+	return ksrkey.KsrK8sPrefix + "/clusterpolicy/"
+}
+
+// Key returns the key under which a given cluster policy is persisted.
+func Key(policy string) string {
+	return KeyPrefix() + policy
+}
+
+// ParseClusterPolicyFromKey parses the name of the cluster policy from the associated
+// data-store key.
+func ParseClusterPolicyFromKey(key string) (policy string, err error) {
+	if strings.HasPrefix(key, KeyPrefix()) {
+		policy = strings.TrimPrefix(key, KeyPrefix())
+		if policy != "" && !strings.Contains(policy, "/") {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid format of the key %s", key)
+}
+
+// HostEndpointPolicyKeyPrefix return prefix where all host-endpoint policies
+// are persisted.
+func HostEndpointPolicyKeyPrefix() string {
+	return ksrkey.KsrK8sPrefix + "/hostendpointpolicy/"
+}
+
+// HostEndpointPolicyKey returns the key under which a given host-endpoint
+// policy is persisted.
+func HostEndpointPolicyKey(policy string) string {
+	return HostEndpointPolicyKeyPrefix() + policy
+}
+
+// ParseHostEndpointPolicyFromKey parses the name of the host-endpoint policy
+// from the associated data-store key.
+func ParseHostEndpointPolicyFromKey(key string) (policy string, err error) {
+	if strings.HasPrefix(key, HostEndpointPolicyKeyPrefix()) {
+		policy = strings.TrimPrefix(key, HostEndpointPolicyKeyPrefix())
+		if policy != "" && !strings.Contains(policy, "/") {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid format of the key %s", key)
+}
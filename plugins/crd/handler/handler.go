@@ -15,6 +15,7 @@
 package handler
 
 import (
+	"github.com/contiv/vpp/plugins/crd/handler/clusterpolicy"
 	"github.com/contiv/vpp/plugins/crd/handler/nodeconfig"
 	"github.com/contiv/vpp/plugins/crd/handler/telemetry"
 )
@@ -34,9 +35,10 @@ type Handler interface {
 
 // Map maps each event handler function to a name for easily lookup
 var Map = map[string]interface{}{
-	"default":    &Default{},
-	"telemetry":  &telemetry.Handler{},
-	"nodeConfig": &nodeconfig.Handler{},
+	"default":       &Default{},
+	"telemetry":     &telemetry.Handler{},
+	"nodeConfig":    &nodeconfig.Handler{},
+	"clusterPolicy": &clusterpolicy.Handler{},
 }
 
 // Default handler implements Handler interface
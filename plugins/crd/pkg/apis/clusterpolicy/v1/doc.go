@@ -0,0 +1,26 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +k8s:deepcopy-gen=package
+// +groupName=clusterpolicy.contiv.vpp
+
+package v1
+
+// in the ...../contiv/vpp folder, execute the following command to generate the
+// zz_generated_deepcopy.go file.  This is required each time the types.go structures
+// are changed.  The CRD code in the controller folder make use of the deep copy
+// routines to transfer node state into kubernetes.
+//
+// CODEGEN_PKG=./vendor/k8s.io/code-generator plugins/crd/controller/update-codegen.sh
+//
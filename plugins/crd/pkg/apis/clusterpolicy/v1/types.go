@@ -0,0 +1,205 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"github.com/contiv/vpp/plugins/crd/pkg/apis/clusterpolicy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CRD Constants
+const (
+	CRDGroup                       string = clusterpolicy.GroupName
+	CRDGroupVersion                string = "v1"
+	CRDContivClusterPolicyPlural   string = "clusterpolicies"
+	CRDFullContivClusterPolicyName string = CRDContivClusterPolicyPlural + "." + CRDGroup
+
+	CRDContivHostEndpointPolicyPlural   string = "hostendpointpolicies"
+	CRDFullContivHostEndpointPolicyName string = CRDContivHostEndpointPolicyPlural + "." + CRDGroup
+)
+
+// ClusterPolicy describes cluster-wide network policy custom resource.
+// Cluster policies are ordered by their priority and evaluated before
+// the namespaced K8s network policies. Unlike K8s network policies, cluster
+// policies can explicitly deny traffic and select pods across namespaces.
+// Traffic not matched by any rule of the cluster policies is passed
+// to the K8s network policies.
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ClusterPolicy struct {
+	// TypeMeta is the metadata for the resource, like kind and apiversion
+	metav1.TypeMeta `json:",inline"`
+	// ObjectMeta contains the metadata for the particular object
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec is the custom resource spec
+	Spec ClusterPolicySpec `json:"spec,omitempty"`
+}
+
+// ClusterPolicySpec is the spec for the cluster policy resource.
+type ClusterPolicySpec struct {
+	// Priority orders the evaluation of cluster policies - policies with lower
+	// value are evaluated first. Policies with equal priority are ordered by name.
+	Priority int32 `json:"priority,omitempty"`
+
+	// NamespaceSelector selects namespaces of the pods the policy applies to.
+	// Nil selector selects all namespaces.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// PodSelector selects pods (inside the selected namespaces) the policy
+	// applies to. Empty selector selects all pods.
+	PodSelector metav1.LabelSelector `json:"podSelector"`
+
+	// Ingress is an ordered list of rules applied to the traffic entering
+	// the selected pods. The first matching rule wins.
+	Ingress []ClusterPolicyRule `json:"ingress,omitempty"`
+
+	// Egress is an ordered list of rules applied to the traffic leaving
+	// the selected pods. The first matching rule wins.
+	Egress []ClusterPolicyRule `json:"egress,omitempty"`
+}
+
+// ClusterPolicyAction is the action applied to the traffic matched by a rule.
+type ClusterPolicyAction string
+
+const (
+	// ActionAllow allows the matched traffic (regardless of the K8s network policies).
+	ActionAllow ClusterPolicyAction = "Allow"
+	// ActionDeny blocks the matched traffic.
+	ActionDeny ClusterPolicyAction = "Deny"
+)
+
+// ClusterPolicyRule matches traffic if and only if the traffic matches both
+// Peers and Ports.
+type ClusterPolicyRule struct {
+	// Action to apply to the matched traffic.
+	Action ClusterPolicyAction `json:"action"`
+
+	// Peers is a list of sources (ingress) or destinations (egress) of the traffic.
+	// Empty list matches all peers.
+	Peers []ClusterPolicyPeer `json:"peers,omitempty"`
+
+	// Ports is a list of destination ports. Empty list matches all ports.
+	Ports []ClusterPolicyPort `json:"ports,omitempty"`
+}
+
+// ClusterPolicyPeer selects a set of pods, an IP block, a domain name or a service.
+type ClusterPolicyPeer struct {
+	// NamespaceSelector selects namespaces of the peer pods.
+	// If only PodSelector is defined, pods are selected from all namespaces.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// PodSelector selects peer pods inside the selected namespaces.
+	// If only NamespaceSelector is defined, all pods of the namespaces are selected.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
+	// IPBlock selects a particular CIDR with possible exceptions.
+	IPBlock *IPBlock `json:"ipBlock,omitempty"`
+
+	// FQDN selects IP addresses the given domain name resolves to. The name is
+	// either exact (e.g. "api.example.com") or a wildcard matching all subdomains
+	// (e.g. "*.example.com"). Supported only for egress rules.
+	FQDN string `json:"fqdn,omitempty"`
+
+	// Service selects a K8s service by name. The service is matched both by its
+	// cluster/external IPs with the service ports (before NAT) and by its endpoints
+	// with the target ports (after NAT). Supported only for egress rules.
+	Service *ServiceReference `json:"service,omitempty"`
+}
+
+// ServiceReference references K8s service by its name and namespace.
+type ServiceReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// IPBlock describes a CIDR with possible exceptions.
+type IPBlock struct {
+	CIDR   string   `json:"cidr"`
+	Except []string `json:"except,omitempty"`
+}
+
+// ClusterPolicyPort selects a destination port, or ICMP type and code.
+type ClusterPolicyPort struct {
+	// Protocol is either TCP, UDP, SCTP or ICMP (default is TCP).
+	Protocol string `json:"protocol,omitempty"`
+
+	// Port number, 0 matches all ports of the protocol.
+	Port int32 `json:"port,omitempty"`
+
+	// EndPort, if set, selects the range of ports from Port to EndPort (inclusive).
+	EndPort int32 `json:"endPort,omitempty"`
+
+	// ICMPType selects ICMP messages of the given type (nil matches all types).
+	ICMPType *int32 `json:"icmpType,omitempty"`
+
+	// ICMPCode selects ICMP messages of the given code (nil matches all codes).
+	ICMPCode *int32 `json:"icmpCode,omitempty"`
+}
+
+// ClusterPolicyList is a list of cluster policy resources
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ClusterPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ClusterPolicy `json:"items"`
+}
+
+// HostEndpointPolicy describes network policy custom resource protecting
+// the host network stack of the selected nodes (node ports and services
+// of the host, e.g. kubelet or SSH).
+// Host-endpoint policies are ordered by their priority. Once a node is selected
+// by at least one host-endpoint policy, the traffic entering the host that is
+// not allowed by any rule is denied. The traffic to the fail-safe ports
+// (configured for the agent) is always allowed, so that the node cannot be
+// locked out.
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type HostEndpointPolicy struct {
+	// TypeMeta is the metadata for the resource, like kind and apiversion
+	metav1.TypeMeta `json:",inline"`
+	// ObjectMeta contains the metadata for the particular object
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec is the custom resource spec
+	Spec HostEndpointPolicySpec `json:"spec,omitempty"`
+}
+
+// HostEndpointPolicySpec is the spec for the host-endpoint policy resource.
+type HostEndpointPolicySpec struct {
+	// Priority orders the evaluation of host-endpoint policies - policies
+	// with lower value are evaluated first. Policies with equal priority
+	// are ordered by name.
+	Priority int32 `json:"priority,omitempty"`
+
+	// NodeSelector selects nodes (by node labels) the policy applies to.
+	// Empty selector selects all nodes.
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+
+	// Ingress is an ordered list of rules applied to the traffic entering
+	// the host network stack of the selected nodes. The first matching rule
+	// wins. FQDN peers are not supported.
+	Ingress []ClusterPolicyRule `json:"ingress,omitempty"`
+}
+
+// HostEndpointPolicyList is a list of host-endpoint policy resources
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type HostEndpointPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []HostEndpointPolicy `json:"items"`
+}
@@ -18,6 +18,8 @@ package fake
 
 import (
 	clientset "github.com/contiv/vpp/plugins/crd/pkg/client/clientset/versioned"
+	clusterpolicyv1 "github.com/contiv/vpp/plugins/crd/pkg/client/clientset/versioned/typed/clusterpolicy/v1"
+	fakeclusterpolicyv1 "github.com/contiv/vpp/plugins/crd/pkg/client/clientset/versioned/typed/clusterpolicy/v1/fake"
 	nodeconfigv1 "github.com/contiv/vpp/plugins/crd/pkg/client/clientset/versioned/typed/nodeconfig/v1"
 	fakenodeconfigv1 "github.com/contiv/vpp/plugins/crd/pkg/client/clientset/versioned/typed/nodeconfig/v1/fake"
 	telemetryv1 "github.com/contiv/vpp/plugins/crd/pkg/client/clientset/versioned/typed/telemetry/v1"
@@ -71,6 +73,16 @@ func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 
 var _ clientset.Interface = &Clientset{}
 
+// ClusterpolicyV1 retrieves the ClusterpolicyV1Client
+func (c *Clientset) ClusterpolicyV1() clusterpolicyv1.ClusterpolicyV1Interface {
+	return &fakeclusterpolicyv1.FakeClusterpolicyV1{Fake: &c.Fake}
+}
+
+// Clusterpolicy retrieves the ClusterpolicyV1Client
+func (c *Clientset) Clusterpolicy() clusterpolicyv1.ClusterpolicyV1Interface {
+	return &fakeclusterpolicyv1.FakeClusterpolicyV1{Fake: &c.Fake}
+}
+
 // NodeconfigV1 retrieves the NodeconfigV1Client
 func (c *Clientset) NodeconfigV1() nodeconfigv1.NodeconfigV1Interface {
 	return &fakenodeconfigv1.FakeNodeconfigV1{Fake: &c.Fake}
@@ -22,6 +22,7 @@ import (
 	time "time"
 
 	versioned "github.com/contiv/vpp/plugins/crd/pkg/client/clientset/versioned"
+	clusterpolicy "github.com/contiv/vpp/plugins/crd/pkg/client/informers/externalversions/clusterpolicy"
 	internalinterfaces "github.com/contiv/vpp/plugins/crd/pkg/client/informers/externalversions/internalinterfaces"
 	nodeconfig "github.com/contiv/vpp/plugins/crd/pkg/client/informers/externalversions/nodeconfig"
 	telemetry "github.com/contiv/vpp/plugins/crd/pkg/client/informers/externalversions/telemetry"
@@ -171,10 +172,15 @@ type SharedInformerFactory interface {
 	ForResource(resource schema.GroupVersionResource) (GenericInformer, error)
 	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
 
+	Clusterpolicy() clusterpolicy.Interface
 	Nodeconfig() nodeconfig.Interface
 	Telemetry() telemetry.Interface
 }
 
+func (f *sharedInformerFactory) Clusterpolicy() clusterpolicy.Interface {
+	return clusterpolicy.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Nodeconfig() nodeconfig.Interface {
 	return nodeconfig.New(f, f.namespace, f.tweakListOptions)
 }
@@ -19,7 +19,8 @@ package externalversions
 import (
 	"fmt"
 
-	v1 "github.com/contiv/vpp/plugins/crd/pkg/apis/nodeconfig/v1"
+	v1 "github.com/contiv/vpp/plugins/crd/pkg/apis/clusterpolicy/v1"
+	nodeconfigv1 "github.com/contiv/vpp/plugins/crd/pkg/apis/nodeconfig/v1"
 	telemetryv1 "github.com/contiv/vpp/plugins/crd/pkg/apis/telemetry/v1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
@@ -51,8 +52,14 @@ func (f *genericInformer) Lister() cache.GenericLister {
 // TODO extend this to unknown resources with a client pool
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
-	// Group=nodeconfig.contiv.vpp, Version=v1
-	case v1.SchemeGroupVersion.WithResource("nodeconfigs"):
+	// Group=clusterpolicy.contiv.vpp, Version=v1
+	case v1.SchemeGroupVersion.WithResource("clusterpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Clusterpolicy().V1().ClusterPolicies().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("hostendpointpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Clusterpolicy().V1().HostEndpointPolicies().Informer()}, nil
+
+		// Group=nodeconfig.contiv.vpp, Version=v1
+	case nodeconfigv1.SchemeGroupVersion.WithResource("nodeconfigs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Nodeconfig().V1().NodeConfigs().Informer()}, nil
 
 		// Group=telemetry.contiv.vpp, Version=v1
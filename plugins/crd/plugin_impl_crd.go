@@ -32,6 +32,7 @@ import (
 	"github.com/ligato/cn-infra/utils/safeclose"
 
 	nodeinfomodel "github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/contiv/vpp/plugins/crd/controller/clusterpolicy"
 	"github.com/contiv/vpp/plugins/crd/controller/nodeconfig"
 	"github.com/contiv/vpp/plugins/crd/controller/telemetry"
 	crdClientSet "github.com/contiv/vpp/plugins/crd/pkg/client/clientset/versioned"
@@ -64,10 +65,12 @@ type Plugin struct {
 	pendingResync  datasync.ResyncEvent
 	pendingChanges []datasync.ChangeEvent
 
-	telemetryController  *telemetry.Controller
-	nodeConfigController *nodeconfig.Controller
-	cache                *cache.ContivTelemetryCache
-	processor            api.ContivTelemetryProcessor
+	telemetryController     *telemetry.Controller
+	nodeConfigController    *nodeconfig.Controller
+	clusterPolicyController *clusterpolicy.Controller
+	hostPolicyController    *clusterpolicy.HostEndpointPolicyController
+	cache                   *cache.ContivTelemetryCache
+	processor               api.ContivTelemetryProcessor
 }
 
 // Deps defines dependencies of policy plugin.
@@ -165,9 +168,29 @@ func (p *Plugin) Init() error {
 	}
 	p.telemetryController.Log.SetLevel(logging.DebugLevel)
 
+	p.clusterPolicyController = &clusterpolicy.Controller{
+		Deps: clusterpolicy.Deps{
+			Log:     p.Log.NewLogger("-clusterPolicyController"),
+			Publish: p.Publish,
+		},
+		CrdClient: crdClient,
+		APIClient: apiclientset,
+	}
+
+	p.hostPolicyController = &clusterpolicy.HostEndpointPolicyController{
+		Deps: clusterpolicy.Deps{
+			Log:     p.Log.NewLogger("-hostEndpointPolicyController"),
+			Publish: p.Publish,
+		},
+		CrdClient: crdClient,
+		APIClient: apiclientset,
+	}
+
 	// Init and run the controllers
 	p.telemetryController.Init()
 	p.nodeConfigController.Init()
+	p.clusterPolicyController.Init()
+	p.hostPolicyController.Init()
 
 	go p.watchEvents()
 	err = p.subscribeWatcher()
@@ -188,6 +211,8 @@ func (p *Plugin) AfterInit() error {
 	}
 	go p.telemetryController.Run(p.ctx.Done())
 	go p.nodeConfigController.Run(p.ctx.Done())
+	go p.clusterPolicyController.Run(p.ctx.Done())
+	go p.hostPolicyController.Run(p.ctx.Done())
 	return nil
 }
 
@@ -0,0 +1,106 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ksr
+
+import (
+	"os"
+
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/servicelabel"
+)
+
+// ClusterServiceLabel is a service label plugin which places the key prefixes
+// of all agents (contiv-ksr included) under the subtree of the Contiv cluster
+// identified by ClusterID. This allows to run multiple independent Contiv clusters
+// against a single etcd. The agent label itself (e.g. node name in the case
+// of contiv-agent) is not affected.
+type ClusterServiceLabel struct {
+	infra.PluginName
+
+	// Label is the wrapped service label plugin providing the agent label.
+	Label *servicelabel.Plugin
+
+	// ClusterID identifies the Contiv cluster, the default (cluster-less) prefixes
+	// are used if empty.
+	ClusterID string
+}
+
+// NewClusterServiceLabel wraps the given service label plugin to use the prefixes
+// of the cluster identified by the CONTIV_CLUSTER_ID environment variable.
+func NewClusterServiceLabel(label *servicelabel.Plugin) *ClusterServiceLabel {
+	l := &ClusterServiceLabel{
+		Label:     label,
+		ClusterID: GetClusterID(),
+	}
+	l.SetName("cluster-service-label")
+	return l
+}
+
+// Init does nothing, the wrapped service label is initialized as a separate plugin.
+func (l *ClusterServiceLabel) Init() error {
+	return nil
+}
+
+// Close does nothing.
+func (l *ClusterServiceLabel) Close() error {
+	return nil
+}
+
+// GetAgentLabel returns the microservice label of the wrapped service label.
+func (l *ClusterServiceLabel) GetAgentLabel() string {
+	return l.Label.GetAgentLabel()
+}
+
+// GetAgentPrefix returns the key prefix for the configuration "subtree"
+// of the current agent instance within the cluster.
+func (l *ClusterServiceLabel) GetAgentPrefix() string {
+	return l.GetDifferentAgentPrefix(l.GetAgentLabel())
+}
+
+// GetDifferentAgentPrefix returns the key prefix used by (another) agent instance
+// of the same cluster labelled as <microserviceLabel>.
+func (l *ClusterServiceLabel) GetDifferentAgentPrefix(microserviceLabel string) string {
+	return GetClusterAgentPrefix(l.ClusterID, microserviceLabel)
+}
+
+// GetAllAgentsPrefix returns the part of the key prefix common to all prefixes
+// of all agents of the cluster.
+func (l *ClusterServiceLabel) GetAllAgentsPrefix() string {
+	if l.ClusterID == "" {
+		return servicelabel.GetAllAgentsPrefix()
+	}
+	return servicelabel.GetAllAgentsPrefix() + l.ClusterID + "/"
+}
+
+// GetClusterID returns the ID of the Contiv cluster as defined by the CONTIV_CLUSTER_ID
+// environment variable.
+func GetClusterID() string {
+	return os.Getenv(ClusterIDEnvVar)
+}
+
+// GetClusterAgentPrefix returns the key prefix used by the agent labelled as <microserviceLabel>
+// within the Contiv cluster identified by <clusterID>.
+func GetClusterAgentPrefix(clusterID string, microserviceLabel string) string {
+	if clusterID == "" {
+		return servicelabel.GetDifferentAgentPrefix(microserviceLabel)
+	}
+	return servicelabel.GetDifferentAgentPrefix(clusterID + "/" + microserviceLabel)
+}
+
+// GetKsrPrefix returns the key prefix under which contiv-ksr of the cluster identified
+// by <clusterID> reflects the K8s state data.
+func GetKsrPrefix(clusterID string) string {
+	return GetClusterAgentPrefix(clusterID, MicroserviceLabel)
+}
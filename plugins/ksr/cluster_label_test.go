@@ -0,0 +1,44 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ksr
+
+import (
+	"testing"
+
+	"github.com/ligato/cn-infra/servicelabel"
+	"github.com/onsi/gomega"
+)
+
+func TestClusterServiceLabel(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	label := &ClusterServiceLabel{
+		Label: servicelabel.NewPlugin(servicelabel.UseLabel("node1")),
+	}
+
+	// no cluster ID - default prefixes
+	gomega.Expect(label.GetAgentLabel()).To(gomega.Equal("node1"))
+	gomega.Expect(label.GetAgentPrefix()).To(gomega.Equal("/vnf-agent/node1/"))
+	gomega.Expect(label.GetDifferentAgentPrefix(MicroserviceLabel)).To(gomega.Equal("/vnf-agent/contiv-ksr/"))
+	gomega.Expect(label.GetAllAgentsPrefix()).To(gomega.Equal("/vnf-agent/"))
+
+	// prefixes within the cluster subtree
+	label.ClusterID = "cluster1"
+	gomega.Expect(label.GetAgentLabel()).To(gomega.Equal("node1"))
+	gomega.Expect(label.GetAgentPrefix()).To(gomega.Equal("/vnf-agent/cluster1/node1/"))
+	gomega.Expect(label.GetDifferentAgentPrefix(MicroserviceLabel)).To(gomega.Equal(GetKsrPrefix("cluster1")))
+	gomega.Expect(GetKsrPrefix("cluster1")).To(gomega.Equal("/vnf-agent/cluster1/contiv-ksr/"))
+	gomega.Expect(label.GetAllAgentsPrefix()).To(gomega.Equal("/vnf-agent/cluster1/"))
+}
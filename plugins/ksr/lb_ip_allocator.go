@@ -0,0 +1,334 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ksr
+
+import (
+	"math/big"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/logging"
+)
+
+const (
+	// lbIPAllocatorResyncPeriod is the period in which the load-balancer IPs
+	// of all services are re-evaluated, in case some status update has failed.
+	lbIPAllocatorResyncPeriod = 1 * time.Minute
+)
+
+// LoadBalancerIPAllocator assigns IP addresses from a configured pool to services
+// of type LoadBalancer. The allocated IP is written into the service status
+// (status.loadBalancer.ingress), from where it is reflected into the data store
+// and picked up by contiv-agents, exposing the service on the IP.
+// Requested spec.loadBalancerIP is allocated if it belongs to the pool and it
+// is not used by another service. Pool IPs of services no longer of type
+// LoadBalancer are released.
+// The allocator learns the services from the data store (as reflected by the
+// service reflector), therefore the allocation state survives restarts of KSR.
+type LoadBalancerIPAllocator struct {
+	Log       logging.Logger
+	K8sClient kubernetes.Interface
+
+	// Pool is the list of subnets to allocate load-balancer IPs from
+	// (allocator is disabled if empty).
+	Pool []*net.IPNet
+
+	// Broker and Watcher are used to access the service data in the data store
+	// (they are expected to be prefixed with the KSR prefix).
+	Broker  KeyProtoValBroker
+	Watcher keyval.ProtoWatcher
+
+	watchCh  chan keyval.ProtoWatchResp
+	services map[svcmodel.ID]*svcmodel.Service // reflected services
+	pending  map[svcmodel.ID]string            // allocated IPs not yet reflected
+}
+
+// Init subscribes to the changes of services. The changes are not processed
+// until Start() is called.
+func (a *LoadBalancerIPAllocator) Init() error {
+	a.services = make(map[svcmodel.ID]*svcmodel.Service)
+	a.pending = make(map[svcmodel.ID]string)
+	if len(a.Pool) == 0 {
+		a.Log.Info("Load-balancer IP pool is not configured, allocator is disabled")
+		return nil
+	}
+	a.watchCh = make(chan keyval.ProtoWatchResp, 100)
+	return a.Watcher.Watch(keyval.ToChanProto(a.watchCh), nil, svcmodel.KeyPrefix())
+}
+
+// Start starts the go routine allocating load-balancer IPs.
+func (a *LoadBalancerIPAllocator) Start(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	if len(a.Pool) == 0 {
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.resync()
+		ticker := time.NewTicker(lbIPAllocatorResyncPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				a.resync()
+			case resp := <-a.watchCh:
+				a.processServiceChange(resp)
+			}
+		}
+	}()
+}
+
+// resync reloads all services from the data store and updates the allocations.
+func (a *LoadBalancerIPAllocator) resync() {
+	it, err := a.Broker.ListValues(svcmodel.KeyPrefix())
+	if err != nil {
+		a.Log.Warnf("Failed to list services: %v", err)
+		return
+	}
+	services := make(map[svcmodel.ID]*svcmodel.Service)
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		service := &svcmodel.Service{}
+		if err := kv.GetValue(service); err != nil {
+			a.Log.Warnf("Failed to read service %s: %v", kv.GetKey(), err)
+			continue
+		}
+		services[svcmodel.GetID(service)] = service
+	}
+	a.services = services
+	a.allocate()
+}
+
+// processServiceChange updates the allocations after a service has changed.
+func (a *LoadBalancerIPAllocator) processServiceChange(resp keyval.ProtoWatchResp) {
+	name, namespace, err := svcmodel.ParseServiceFromKey(resp.GetKey())
+	if err != nil {
+		a.Log.Warnf("Unexpected service key: %s", resp.GetKey())
+		return
+	}
+	var service *svcmodel.Service
+	if resp.GetChangeType() == datasync.Put {
+		service = &svcmodel.Service{}
+		if err := resp.GetValue(service); err != nil {
+			a.Log.Warnf("Failed to read service %s: %v", resp.GetKey(), err)
+			return
+		}
+	}
+	a.UpdateService(svcmodel.ID{Name: name, Namespace: namespace}, service)
+}
+
+// UpdateService learns the new state of the given service and updates
+// the allocations. Nil service means that the service was removed.
+func (a *LoadBalancerIPAllocator) UpdateService(id svcmodel.ID, service *svcmodel.Service) {
+	if service == nil {
+		delete(a.services, id)
+	} else {
+		a.services[id] = service
+	}
+	a.allocate()
+}
+
+// allocate assigns pool IPs to services of type LoadBalancer without
+// an ingress IP and releases pool IPs of services which are no longer of type
+// LoadBalancer.
+func (a *LoadBalancerIPAllocator) allocate() {
+	ids := make([]svcmodel.ID, 0, len(a.services))
+	for id := range a.services {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+
+	// Collect IPs already in use, release IPs of non-LoadBalancer services.
+	used := make(map[string]svcmodel.ID)
+	for id, ip := range a.pending {
+		service, hasService := a.services[id]
+		if !hasService || service.ServiceType != "LoadBalancer" || len(service.LoadbalancerIngressIps) > 0 {
+			delete(a.pending, id)
+			continue
+		}
+		used[ip] = id
+	}
+	for _, id := range ids {
+		service := a.services[id]
+		if service.ServiceType != "LoadBalancer" {
+			if a.hasPoolIP(service.LoadbalancerIngressIps) {
+				a.updateStatus(id, "")
+			}
+			continue
+		}
+		for _, ip := range service.LoadbalancerIngressIps {
+			used[ip] = id
+		}
+	}
+
+	// Allocate IPs for services without the ingress IP.
+	for _, id := range ids {
+		service := a.services[id]
+		if service.ServiceType != "LoadBalancer" || len(service.LoadbalancerIngressIps) > 0 {
+			continue
+		}
+		if _, isPending := a.pending[id]; isPending {
+			continue
+		}
+		var ip string
+		if service.LoadbalancerIp != "" {
+			if _, isUsed := used[service.LoadbalancerIp]; isUsed || !a.inPool(service.LoadbalancerIp) {
+				a.Log.WithFields(logging.Fields{
+					"service":        id,
+					"loadBalancerIP": service.LoadbalancerIp,
+				}).Warn("Requested load-balancer IP is not available")
+				continue
+			}
+			ip = service.LoadbalancerIp
+		} else {
+			ip = a.nextFreeIP(used)
+			if ip == "" {
+				a.Log.WithField("service", id).Warn("Load-balancer IP pool is exhausted")
+				continue
+			}
+		}
+		if a.updateStatus(id, ip) {
+			used[ip] = id
+			a.pending[id] = ip
+		}
+	}
+}
+
+// updateStatus writes the given IP as the only ingress IP into the status
+// of the service (empty IP clears the ingress). Returns true on success.
+func (a *LoadBalancerIPAllocator) updateStatus(id svcmodel.ID, ip string) bool {
+	svc, err := a.K8sClient.CoreV1().Services(id.Namespace).Get(id.Name, metaV1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		// service removed, will be un-reflected shortly
+		return false
+	}
+	if err != nil {
+		a.Log.Warnf("Failed to get service %s: %v", id, err)
+		return false
+	}
+	svc.Status.LoadBalancer.Ingress = nil
+	if ip != "" {
+		svc.Status.LoadBalancer.Ingress = []coreV1.LoadBalancerIngress{{IP: ip}}
+	}
+	if _, err := a.K8sClient.CoreV1().Services(id.Namespace).UpdateStatus(svc); err != nil {
+		a.Log.Warnf("Failed to update status of the service %s: %v", id, err)
+		return false
+	}
+	if ip == "" {
+		a.Log.WithField("service", id).Info("Released load-balancer IP")
+	} else {
+		a.Log.WithFields(logging.Fields{
+			"service":        id,
+			"loadBalancerIP": ip,
+		}).Info("Allocated load-balancer IP")
+	}
+	return true
+}
+
+// hasPoolIP returns true if at least one of the given IPs belongs to the pool.
+func (a *LoadBalancerIPAllocator) hasPoolIP(ips []string) bool {
+	for _, ip := range ips {
+		if a.inPool(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// inPool returns true if the given IP belongs to the pool.
+func (a *LoadBalancerIPAllocator) inPool(ipStr string) bool {
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return false
+	}
+	for _, subnet := range a.Pool {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextFreeIP returns the first IP from the pool which is not used (empty string
+// if the pool is exhausted). Network and broadcast addresses of IPv4 subnets
+// are skipped.
+func (a *LoadBalancerIPAllocator) nextFreeIP(used map[string]svcmodel.ID) string {
+	for _, subnet := range a.Pool {
+		ones, bits := subnet.Mask.Size()
+		first := new(big.Int).SetBytes(subnet.IP)
+		size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+		last := new(big.Int).Add(first, size)
+		last.Sub(last, big.NewInt(1))
+		if bits == net.IPv4len*8 && bits-ones > 1 {
+			first.Add(first, big.NewInt(1))
+			last.Sub(last, big.NewInt(1))
+		}
+		for i := first; i.Cmp(last) <= 0; i.Add(i, big.NewInt(1)) {
+			ip := bigIntToIP(i, len(subnet.IP)).String()
+			if _, isUsed := used[ip]; !isUsed {
+				return ip
+			}
+		}
+	}
+	return ""
+}
+
+// bigIntToIP converts integer into IP address of the given length.
+func bigIntToIP(i *big.Int, length int) net.IP {
+	bytes := i.Bytes()
+	ip := make(net.IP, length)
+	copy(ip[length-len(bytes):], bytes)
+	return ip
+}
+
+// loadBalancerIPPool returns the pool of load-balancer IPs configured via
+// the environment variable (comma-separated list of subnets).
+func loadBalancerIPPool(log logging.Logger) (pool []*net.IPNet) {
+	for _, subnetStr := range strings.Split(os.Getenv(LoadBalancerIPPoolEnvVar), ",") {
+		subnetStr = strings.TrimSpace(subnetStr)
+		if subnetStr == "" {
+			continue
+		}
+		_, subnet, err := net.ParseCIDR(subnetStr)
+		if err != nil {
+			log.Warnf("Invalid subnet in the load-balancer IP pool %s: %v", subnetStr, err)
+			continue
+		}
+		if ip4 := subnet.IP.To4(); ip4 != nil {
+			subnet.IP = ip4
+		}
+		pool = append(pool, subnet)
+	}
+	return pool
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ksr
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+	"github.com/ligato/cn-infra/logging"
+)
+
+func TestLoadBalancerIPAllocator(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	newService := func(name string) *coreV1.Service {
+		return &coreV1.Service{
+			ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       coreV1.ServiceSpec{Type: coreV1.ServiceTypeLoadBalancer},
+		}
+	}
+	client := fake.NewSimpleClientset(newService("web"), newService("dns"), newService("db"))
+	broker := newMockKeyProtoValBroker()
+	_, pool, _ := net.ParseCIDR("20.0.0.0/30")
+	allocator := &LoadBalancerIPAllocator{
+		Log:       logging.ForPlugin("lb-ip-allocator-test"),
+		K8sClient: client,
+		Pool:      []*net.IPNet{pool},
+		Broker:    broker,
+	}
+	allocator.services = make(map[svcmodel.ID]*svcmodel.Service)
+	allocator.pending = make(map[svcmodel.ID]string)
+
+	ingressIPs := func(name string) []string {
+		svc, err := client.CoreV1().Services("default").Get(name, metaV1.GetOptions{})
+		gomega.Expect(err).To(gomega.BeNil())
+		ips := []string{}
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			ips = append(ips, ingress.IP)
+		}
+		return ips
+	}
+	// reflect simulates reflection of the service status into the data store.
+	reflect := func(service *svcmodel.Service) {
+		service.LoadbalancerIngressIps = ingressIPs(service.Name)
+		allocator.UpdateService(svcmodel.GetID(service), service)
+	}
+
+	// services reflected in the data store, "dns" requests the last pool IP
+	web := &svcmodel.Service{Name: "web", Namespace: "default", ServiceType: "LoadBalancer"}
+	dns := &svcmodel.Service{Name: "dns", Namespace: "default", ServiceType: "LoadBalancer", LoadbalancerIp: "20.0.0.2"}
+	gomega.Expect(broker.Put(svcmodel.Key(web.Name, web.Namespace), web)).To(gomega.BeNil())
+	gomega.Expect(broker.Put(svcmodel.Key(dns.Name, dns.Namespace), dns)).To(gomega.BeNil())
+	allocator.resync()
+	gomega.Expect(ingressIPs("dns")).To(gomega.Equal([]string{"20.0.0.2"}))
+	gomega.Expect(ingressIPs("web")).To(gomega.Equal([]string{"20.0.0.1"}))
+
+	// pending allocations are not repeated
+	allocator.allocate()
+	gomega.Expect(allocator.pending).To(gomega.HaveLen(2))
+	reflect(web)
+	reflect(dns)
+	gomega.Expect(allocator.pending).To(gomega.BeEmpty())
+
+	// the pool is exhausted
+	db := &svcmodel.Service{Name: "db", Namespace: "default", ServiceType: "LoadBalancer"}
+	allocator.UpdateService(svcmodel.GetID(db), db)
+	gomega.Expect(ingressIPs("db")).To(gomega.BeEmpty())
+
+	// IP of a service changed to ClusterIP is released and re-allocated
+	web.ServiceType = "ClusterIP"
+	allocator.UpdateService(svcmodel.GetID(web), web)
+	gomega.Expect(ingressIPs("web")).To(gomega.BeEmpty())
+	reflect(web)
+	gomega.Expect(ingressIPs("db")).To(gomega.Equal([]string{"20.0.0.1"}))
+
+	// IP requested outside of the pool is not allocated
+	reflect(db)
+	other := newService("other")
+	_, err := client.CoreV1().Services("default").Create(other)
+	gomega.Expect(err).To(gomega.BeNil())
+	allocator.UpdateService(svcmodel.ID{Name: "other", Namespace: "default"},
+		&svcmodel.Service{Name: "other", Namespace: "default", ServiceType: "LoadBalancer", LoadbalancerIp: "30.0.0.1"})
+	gomega.Expect(ingressIPs("other")).To(gomega.BeEmpty())
+}
+
+func TestLoadBalancerIPPool(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	log := logging.ForPlugin("lb-ip-pool-test")
+
+	os.Setenv(LoadBalancerIPPoolEnvVar, "")
+	gomega.Expect(loadBalancerIPPool(log)).To(gomega.BeEmpty())
+
+	os.Setenv(LoadBalancerIPPoolEnvVar, "20.0.0.0/24, invalid, 2001:db8::/120")
+	defer os.Unsetenv(LoadBalancerIPPoolEnvVar)
+	pool := loadBalancerIPPool(log)
+	gomega.Expect(pool).To(gomega.HaveLen(2))
+	gomega.Expect(pool[0].String()).To(gomega.Equal("20.0.0.0/24"))
+	gomega.Expect(pool[1].String()).To(gomega.Equal("2001:db8::/120"))
+
+	allocator := &LoadBalancerIPAllocator{Pool: pool[1:]}
+	gomega.Expect(allocator.nextFreeIP(map[string]svcmodel.ID{"2001:db8::": {}})).To(gomega.Equal("2001:db8::1"))
+}
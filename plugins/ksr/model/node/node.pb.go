@@ -87,6 +87,9 @@ type Node struct {
 	// More info: https://kubernetes.io/docs/concepts/nodes/node/#info
 	// +optional
 	NodeInfo *NodeSystemInfo `protobuf:"bytes,5,opt,name=node_info,json=nodeInfo" json:"node_info,omitempty"`
+	// A list of labels attached to this node.
+	// +optional
+	Label []*Node_Label `protobuf:"bytes,6,rep,name=label" json:"label,omitempty"`
 }
 
 func (m *Node) Reset()                    { *m = Node{} }
@@ -129,6 +132,39 @@ func (m *Node) GetNodeInfo() *NodeSystemInfo {
 	return nil
 }
 
+func (m *Node) GetLabel() []*Node_Label {
+	if m != nil {
+		return m.Label
+	}
+	return nil
+}
+
+// Label is a key/value pair attached to an object (node in this case).
+// Labels are used to organize and to select subsets of objects.
+type Node_Label struct {
+	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *Node_Label) Reset()                    { *m = Node_Label{} }
+func (m *Node_Label) String() string            { return proto.CompactTextString(m) }
+func (*Node_Label) ProtoMessage()               {}
+func (*Node_Label) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 0} }
+
+func (m *Node_Label) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Node_Label) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
 // NodeAddress contains information for the node's address.
 type NodeAddress struct {
 	// Node address type, one of Hostname, ExternalIP or InternalIP.
@@ -263,6 +299,7 @@ func (m *NodeSystemInfo) GetArchitecture() string {
 
 func init() {
 	proto.RegisterType((*Node)(nil), "node.Node")
+	proto.RegisterType((*Node_Label)(nil), "node.Node.Label")
 	proto.RegisterType((*NodeAddress)(nil), "node.NodeAddress")
 	proto.RegisterType((*NodeSystemInfo)(nil), "node.NodeSystemInfo")
 	proto.RegisterEnum("node.NodeAddress_AddressType", NodeAddress_AddressType_name, NodeAddress_AddressType_value)
@@ -271,36 +308,38 @@ func init() {
 func init() { proto.RegisterFile("node.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 483 bytes of a gzipped FileDescriptorProto
+	// 523 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x93, 0xc1, 0x6e, 0xd3, 0x4c,
-	0x10, 0x80, 0x7f, 0x37, 0x6e, 0x1c, 0x8f, 0xfb, 0x27, 0x66, 0x40, 0xea, 0xf6, 0x50, 0x11, 0x59,
-	0x42, 0x44, 0x1c, 0x82, 0x1a, 0x6e, 0xdc, 0x2a, 0x8c, 0xc4, 0x0a, 0x29, 0x54, 0x2e, 0xe1, 0x6a,
-	0x39, 0xf1, 0xb4, 0xb5, 0x12, 0xef, 0x5a, 0xeb, 0x4d, 0x69, 0x5e, 0x80, 0x03, 0x8f, 0xc4, 0xeb,
-	0xf0, 0x22, 0x68, 0xd7, 0x76, 0x52, 0xe8, 0xc9, 0x3b, 0xdf, 0x7c, 0x33, 0xeb, 0xdd, 0xb1, 0x01,
-	0x84, 0xcc, 0x69, 0x5a, 0x29, 0xa9, 0x25, 0xba, 0x66, 0x1d, 0xfd, 0x72, 0xc0, 0x9d, 0xcb, 0x9c,
-	0x10, 0xc1, 0x15, 0x59, 0x49, 0xcc, 0x19, 0x3b, 0x13, 0x3f, 0xb1, 0x6b, 0x3c, 0x83, 0x41, 0x25,
-	0xf3, 0xf4, 0x03, 0x8f, 0x13, 0x76, 0x64, 0xb9, 0x57, 0xc9, 0xdc, 0x84, 0xf8, 0x12, 0x82, 0x4a,
-	0xc9, 0xfb, 0x22, 0x27, 0x95, 0xf2, 0x98, 0xf5, 0x6c, 0x16, 0x3a, 0xc4, 0x63, 0x7c, 0x0b, 0x7e,
-	0x96, 0xe7, 0x8a, 0xea, 0x9a, 0x6a, 0xe6, 0x8e, 0x7b, 0x93, 0x60, 0xf6, 0x6c, 0x6a, 0xb7, 0x37,
-	0xdb, 0x5d, 0x36, 0xa9, 0xe4, 0xe0, 0xe0, 0x05, 0xf8, 0x26, 0x9d, 0x16, 0xe2, 0x46, 0xb2, 0xe3,
-	0xb1, 0x33, 0x09, 0x66, 0x2f, 0x0e, 0x05, 0xd7, 0xbb, 0x5a, 0x53, 0xc9, 0xc5, 0x8d, 0x4c, 0x06,
-	0x06, 0x9a, 0x55, 0xf4, 0xdb, 0x81, 0xe0, 0x51, 0x37, 0xbc, 0x00, 0x57, 0xef, 0xaa, 0xe6, 0x0c,
-	0xc3, 0xd9, 0xf9, 0x93, 0xed, 0xa6, 0xed, 0xf3, 0xeb, 0xae, 0xa2, 0xc4, 0xaa, 0xc8, 0xc0, 0x6b,
-	0x5f, 0xa1, 0x3b, 0x61, 0x1b, 0x46, 0x3f, 0x1c, 0x08, 0x1e, 0xf9, 0xf8, 0x1c, 0x46, 0xa6, 0xd5,
-	0x42, 0xac, 0x85, 0xfc, 0x2e, 0x4c, 0x26, 0xfc, 0x0f, 0x43, 0x38, 0x31, 0xf0, 0x93, 0xac, 0xf5,
-	0x3c, 0x2b, 0x29, 0x74, 0x10, 0x61, 0x68, 0xc8, 0xc7, 0x07, 0x4d, 0x4a, 0x64, 0x1b, 0x7e, 0x15,
-	0x1e, 0x75, 0x8c, 0x8b, 0x3d, 0xeb, 0x75, 0xed, 0x3a, 0x2f, 0x9e, 0x5f, 0x87, 0x6e, 0x07, 0x3b,
-	0xd1, 0xc0, 0xe3, 0xe8, 0x67, 0xaf, 0x29, 0x3f, 0x5c, 0x01, 0x9e, 0x03, 0x94, 0xd9, 0xea, 0xae,
-	0x10, 0x64, 0x2e, 0xbf, 0x19, 0x99, 0xdf, 0x12, 0x1e, 0x9b, 0xe1, 0xd4, 0x56, 0x4e, 0x17, 0x0b,
-	0x1e, 0xb7, 0x07, 0x83, 0x06, 0x19, 0x82, 0xa7, 0xe0, 0x2d, 0xa5, 0xd4, 0x87, 0xc9, 0xf5, 0x4d,
-	0xc8, 0x63, 0x7c, 0x05, 0xc3, 0x35, 0x29, 0x41, 0x9b, 0xf4, 0x9e, 0x54, 0x5d, 0x48, 0xc1, 0x5c,
-	0x9b, 0xff, 0xbf, 0xa1, 0xdf, 0x1a, 0x68, 0x3e, 0x0c, 0x59, 0xa7, 0x45, 0x99, 0xdd, 0x92, 0x1d,
-	0x95, 0x9f, 0x78, 0xb2, 0xe6, 0x26, 0xc4, 0xf7, 0x70, 0xb6, 0x92, 0x42, 0x67, 0x85, 0x20, 0x95,
-	0xaa, 0xad, 0xd0, 0x45, 0x49, 0xfb, 0x66, 0x7d, 0xeb, 0x9e, 0xee, 0x85, 0xa4, 0xc9, 0x77, 0x6d,
-	0x5f, 0xc3, 0x68, 0xbd, 0x5d, 0xd2, 0x86, 0xf4, 0xbe, 0xc2, 0xb3, 0x15, 0xc3, 0x16, 0x77, 0xe2,
-	0x1b, 0x08, 0x3f, 0x6f, 0x97, 0x74, 0xa5, 0xe4, 0xc3, 0xae, 0x65, 0x6c, 0x60, 0xcd, 0x27, 0x1c,
-	0x27, 0x30, 0xfa, 0x52, 0x91, 0xca, 0x74, 0x21, 0x6e, 0x9b, 0x2b, 0x64, 0xbe, 0x55, 0xff, 0xc5,
-	0x18, 0xc1, 0xc9, 0xa5, 0x5a, 0xdd, 0x15, 0x9a, 0x56, 0x7a, 0xab, 0x88, 0x81, 0xd5, 0xfe, 0x62,
-	0xcb, 0xbe, 0xfd, 0x79, 0xde, 0xfd, 0x09, 0x00, 0x00, 0xff, 0xff, 0x51, 0x7c, 0x50, 0x68, 0x4a,
-	0x03, 0x00, 0x00,
+	0x10, 0xc7, 0x3f, 0x37, 0x4e, 0x52, 0x4f, 0xfa, 0x25, 0x66, 0xa8, 0xd4, 0x2d, 0x52, 0x45, 0x14,
+	0x09, 0x88, 0x38, 0xa4, 0x6a, 0xb8, 0x71, 0xab, 0x30, 0x12, 0x2b, 0x50, 0xa8, 0x5c, 0xc2, 0xd5,
+	0x72, 0xe2, 0x69, 0x6b, 0xc5, 0xde, 0xb5, 0xd6, 0x9b, 0xd0, 0xbc, 0x00, 0x07, 0xae, 0x3c, 0x22,
+	0x2f, 0x82, 0x76, 0x6d, 0x27, 0x2d, 0x3d, 0x65, 0xe6, 0xf7, 0xff, 0xef, 0x6c, 0x66, 0x76, 0x0c,
+	0x20, 0x64, 0x42, 0x93, 0x42, 0x49, 0x2d, 0xd1, 0x35, 0xf1, 0xe8, 0xf7, 0x01, 0xb8, 0x33, 0x99,
+	0x10, 0x22, 0xb8, 0x22, 0xce, 0x89, 0x39, 0x43, 0x67, 0xec, 0x85, 0x36, 0xc6, 0x53, 0x38, 0x2c,
+	0x64, 0x12, 0x7d, 0xe0, 0x41, 0xc8, 0x0e, 0x2c, 0xef, 0x16, 0x32, 0x31, 0x29, 0xbe, 0x84, 0x5e,
+	0xa1, 0xe4, 0x26, 0x4d, 0x48, 0x45, 0x3c, 0x60, 0x2d, 0xab, 0x42, 0x83, 0x78, 0x80, 0xe7, 0xe0,
+	0xc5, 0x49, 0xa2, 0xa8, 0x2c, 0xa9, 0x64, 0xee, 0xb0, 0x35, 0xee, 0x4d, 0x9f, 0x4d, 0xec, 0xf5,
+	0xe6, 0xba, 0xcb, 0x4a, 0x0a, 0xf7, 0x1e, 0xbc, 0x00, 0xcf, 0xc8, 0x51, 0x2a, 0x6e, 0x24, 0x6b,
+	0x0f, 0x9d, 0x71, 0x6f, 0x7a, 0xbc, 0x3f, 0x70, 0xbd, 0x2d, 0x35, 0xe5, 0x5c, 0xdc, 0xc8, 0xf0,
+	0xd0, 0x40, 0x13, 0xe1, 0x6b, 0x68, 0x67, 0xf1, 0x82, 0x32, 0xd6, 0xb1, 0xf5, 0xfd, 0xbd, 0x7d,
+	0xf2, 0xc5, 0xf0, 0xb0, 0x92, 0x5f, 0x9c, 0x43, 0xdb, 0xe6, 0xe8, 0x43, 0x6b, 0x45, 0xdb, 0xba,
+	0x47, 0x13, 0xe2, 0x31, 0xb4, 0x37, 0x71, 0xb6, 0xa6, 0xba, 0xbf, 0x2a, 0x19, 0xfd, 0x71, 0xa0,
+	0xf7, 0xe0, 0x6f, 0xe2, 0x05, 0xb8, 0x7a, 0x5b, 0x54, 0xc3, 0xe9, 0x4f, 0xcf, 0x9e, 0xf4, 0x31,
+	0xa9, 0x7f, 0xbf, 0x6d, 0x0b, 0x0a, 0xad, 0x15, 0x19, 0x74, 0xeb, 0xde, 0x9a, 0xd1, 0xd5, 0xe9,
+	0xe8, 0xa7, 0x03, 0xbd, 0x07, 0x7e, 0x7c, 0x0e, 0x03, 0x53, 0x6a, 0x2e, 0x56, 0x42, 0xfe, 0x10,
+	0x46, 0xf1, 0xff, 0x43, 0x1f, 0x8e, 0x0c, 0xfc, 0x24, 0x4b, 0x3d, 0x8b, 0x73, 0xf2, 0x1d, 0x44,
+	0xe8, 0x1b, 0xf2, 0xf1, 0x5e, 0x93, 0x12, 0x71, 0xc6, 0xaf, 0xfc, 0x83, 0x86, 0x71, 0xb1, 0x63,
+	0xad, 0xa6, 0x5c, 0xe3, 0x0b, 0x66, 0xd7, 0xbe, 0xdb, 0x40, 0x2e, 0xf6, 0xb0, 0x3d, 0xfa, 0xd5,
+	0x82, 0xfe, 0xe3, 0xd9, 0xe2, 0x19, 0x40, 0x1e, 0x2f, 0xef, 0x52, 0x41, 0xe6, 0x55, 0xab, 0x39,
+	0x79, 0x35, 0xe1, 0x81, 0x79, 0xf5, 0xd2, 0x9a, 0xa3, 0xf9, 0x9c, 0x07, 0x75, 0x63, 0x50, 0x21,
+	0x43, 0xf0, 0x04, 0xba, 0x0b, 0x29, 0xf5, 0x7e, 0x25, 0x3a, 0x26, 0xe5, 0x01, 0xbe, 0x82, 0xfe,
+	0x8a, 0x94, 0xa0, 0x2c, 0xda, 0x90, 0x2a, 0x53, 0x29, 0x98, 0x6b, 0xf5, 0xff, 0x2b, 0xfa, 0xbd,
+	0x82, 0x66, 0xe3, 0x64, 0x19, 0xa5, 0x79, 0x7c, 0x4b, 0x76, 0x07, 0xbc, 0xb0, 0x2b, 0x4b, 0x6e,
+	0x52, 0x7c, 0x0f, 0xa7, 0x4b, 0x29, 0x74, 0x9c, 0x0a, 0x52, 0x91, 0x5a, 0x0b, 0x9d, 0xe6, 0xb4,
+	0x2b, 0xd6, 0xb1, 0xde, 0x93, 0x9d, 0x21, 0xac, 0xf4, 0xa6, 0xec, 0x1b, 0x18, 0xac, 0xd6, 0x0b,
+	0xca, 0x48, 0xef, 0x4e, 0x74, 0xed, 0x89, 0x7e, 0x8d, 0x1b, 0xe3, 0x5b, 0xf0, 0x3f, 0xaf, 0x17,
+	0x74, 0xa5, 0xe4, 0xfd, 0xb6, 0x66, 0xec, 0xd0, 0x3a, 0x9f, 0x70, 0x1c, 0xc3, 0xe0, 0x6b, 0x41,
+	0x2a, 0xd6, 0xa9, 0xb8, 0xad, 0x46, 0xc8, 0x3c, 0x6b, 0xfd, 0x17, 0xe3, 0x08, 0x8e, 0x2e, 0xd5,
+	0xf2, 0x2e, 0xd5, 0xb4, 0xd4, 0x6b, 0x45, 0x0c, 0xac, 0xed, 0x11, 0x5b, 0x74, 0xec, 0x57, 0xf9,
+	0xee, 0xef, 0x00, 0x00, 0xe2, 0x44, 0x85, 0xa3, 0x03, 0x00, 0x00,
 }
@@ -33,17 +33,20 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 type Pod_Container_Port_Protocol int32
 
 const (
-	Pod_Container_Port_TCP Pod_Container_Port_Protocol = 0
-	Pod_Container_Port_UDP Pod_Container_Port_Protocol = 1
+	Pod_Container_Port_TCP  Pod_Container_Port_Protocol = 0
+	Pod_Container_Port_UDP  Pod_Container_Port_Protocol = 1
+	Pod_Container_Port_SCTP Pod_Container_Port_Protocol = 2
 )
 
 var Pod_Container_Port_Protocol_name = map[int32]string{
 	0: "TCP",
 	1: "UDP",
+	2: "SCTP",
 }
 var Pod_Container_Port_Protocol_value = map[string]int32{
-	"TCP": 0,
-	"UDP": 1,
+	"TCP":  0,
+	"UDP":  1,
+	"SCTP": 2,
 }
 
 func (x Pod_Container_Port_Protocol) String() string {
@@ -80,6 +83,10 @@ type Pod struct {
 	// There must be at least one container in a Pod.
 	// Cannot be updated.
 	Container []*Pod_Container `protobuf:"bytes,6,rep,name=container" json:"container,omitempty"`
+	// IP addresses allocated to the pod, one for each IP family in dual-stack
+	// clusters. The first address equals ip_address.
+	// +optional
+	IpAddresses []string `protobuf:"bytes,7,rep,name=ip_addresses,json=ipAddresses" json:"ip_addresses,omitempty"`
 }
 
 func (m *Pod) Reset()                    { *m = Pod{} }
@@ -129,6 +136,13 @@ func (m *Pod) GetContainer() []*Pod_Container {
 	return nil
 }
 
+func (m *Pod) GetIpAddresses() []string {
+	if m != nil {
+		return m.IpAddresses
+	}
+	return nil
+}
+
 // Label is a key/value pair attached to an object (pod in this case).
 // Labels are used to organize and to select subsets of objects.
 type Pod_Label struct {
@@ -206,7 +220,7 @@ type Pod_Container_Port struct {
 	// Port number to expose on the pod's IP address.
 	// The port number is in the range: 0 < x < 65536.
 	ContainerPort int32 `protobuf:"varint,3,opt,name=container_port,json=containerPort" json:"container_port,omitempty"`
-	// Protocol for port. Must be UDP or TCP.
+	// Protocol for port. Must be UDP, TCP or SCTP.
 	// Defaults to "TCP".
 	// +optional
 	Protocol Pod_Container_Port_Protocol `protobuf:"varint,4,opt,name=protocol,enum=pod.Pod_Container_Port_Protocol" json:"protocol,omitempty"`
@@ -266,26 +280,27 @@ func init() {
 func init() { proto.RegisterFile("pod.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 322 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x91, 0xdf, 0x4a, 0xc3, 0x30,
-	0x14, 0xc6, 0xed, 0xd2, 0xce, 0xe5, 0xc8, 0xe6, 0x08, 0x82, 0x61, 0x4e, 0x18, 0x43, 0x65, 0x20,
-	0x54, 0x99, 0xb7, 0xde, 0xc8, 0xbc, 0x11, 0xbc, 0x08, 0x41, 0xaf, 0x47, 0xb6, 0x04, 0x1c, 0xd6,
-	0x25, 0xb4, 0x55, 0xf0, 0xb1, 0xbc, 0xf6, 0x6d, 0x7c, 0x12, 0xc9, 0x69, 0x9b, 0x09, 0x4e, 0xf0,
-	0xaa, 0x27, 0xdf, 0xf9, 0x9d, 0xef, 0xfc, 0x29, 0x50, 0x67, 0x75, 0xea, 0x72, 0x5b, 0x5a, 0x46,
-	0x9c, 0xd5, 0xe3, 0xcf, 0x18, 0x88, 0xb0, 0x9a, 0x31, 0x88, 0xd7, 0xea, 0xc5, 0xf0, 0x68, 0x14,
-	0x4d, 0xa8, 0xc4, 0x98, 0x0d, 0x81, 0xfa, 0x6f, 0xe1, 0xd4, 0xd2, 0xf0, 0x16, 0x26, 0x36, 0x02,
-	0x3b, 0x81, 0x24, 0x53, 0x0b, 0x93, 0x71, 0x32, 0x22, 0x93, 0xbd, 0x69, 0x2f, 0xf5, 0xce, 0xc2,
-	0xea, 0xf4, 0xde, 0xab, 0xb2, 0x4a, 0xb2, 0x63, 0x80, 0x95, 0x9b, 0x2b, 0xad, 0x73, 0x53, 0x14,
-	0x3c, 0xae, 0x4c, 0x56, 0xee, 0xa6, 0x12, 0xd8, 0x19, 0xec, 0x3f, 0xd9, 0xa2, 0x9c, 0xff, 0x60,
-	0x12, 0x64, 0xba, 0x5e, 0xbe, 0x0b, 0xdc, 0x25, 0xd0, 0xa5, 0x5d, 0x97, 0x6a, 0xb5, 0x36, 0x39,
-	0x6f, 0x63, 0x43, 0x16, 0x1a, 0xce, 0x9a, 0x8c, 0xdc, 0x40, 0x83, 0x0b, 0x48, 0x70, 0x10, 0xd6,
-	0x07, 0xf2, 0x6c, 0xde, 0xeb, 0xc5, 0x7c, 0xc8, 0x0e, 0x20, 0x79, 0x53, 0xd9, 0x6b, 0xb3, 0x53,
-	0xf5, 0x18, 0x7c, 0xb4, 0x80, 0x06, 0xa7, 0xad, 0xf7, 0x38, 0x87, 0xd8, 0xd9, 0xbc, 0xe4, 0x2d,
-	0xec, 0x7f, 0xf8, 0xbb, 0x7f, 0x2a, 0x6c, 0x5e, 0x4a, 0x84, 0x06, 0x5f, 0x11, 0xc4, 0xfe, 0xb9,
-	0xd5, 0xe9, 0x08, 0x28, 0xae, 0x5d, 0xdb, 0x45, 0x93, 0x44, 0x76, 0xbc, 0x80, 0x05, 0xa7, 0xd0,
-	0x0b, 0x6b, 0x54, 0x04, 0x41, 0xa2, 0x1b, 0x54, 0xc4, 0xae, 0xa1, 0x83, 0xff, 0x71, 0x69, 0x33,
-	0xbc, 0x6b, 0x6f, 0x3a, 0xfa, 0x63, 0xa2, 0x54, 0xd4, 0x9c, 0x0c, 0x15, 0xff, 0x3d, 0xfc, 0x78,
-	0x08, 0x9d, 0xa6, 0x9a, 0xed, 0x02, 0x79, 0x98, 0x89, 0xfe, 0x8e, 0x0f, 0x1e, 0x6f, 0x45, 0x3f,
-	0x5a, 0xb4, 0xd1, 0xef, 0xea, 0x3b, 0x00, 0x00, 0xff, 0xff, 0x1e, 0x6d, 0x78, 0xcf, 0x56, 0x02,
-	0x00, 0x00,
+	// 344 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x91, 0xcf, 0x4a, 0xc3, 0x40,
+	0x10, 0xc6, 0x4d, 0x36, 0x69, 0x93, 0xa9, 0xad, 0x61, 0x11, 0x5c, 0xaa, 0x42, 0x2c, 0x5a, 0x02,
+	0x42, 0x94, 0x7a, 0xf5, 0x22, 0xf5, 0x22, 0x78, 0x58, 0xd6, 0x7a, 0x2e, 0x69, 0xb3, 0x60, 0x31,
+	0x76, 0x97, 0x24, 0x0a, 0x3e, 0x9b, 0x2f, 0xe4, 0xc1, 0x87, 0x90, 0x9d, 0xb4, 0x5b, 0xc1, 0x0a,
+	0x9e, 0x32, 0xf9, 0xe6, 0x37, 0xdf, 0xfc, 0x59, 0x08, 0xb5, 0xca, 0x53, 0x5d, 0xaa, 0x5a, 0x51,
+	0xa2, 0x55, 0x3e, 0xf8, 0xf4, 0x80, 0x70, 0x95, 0x53, 0x0a, 0xde, 0x32, 0x7b, 0x91, 0xcc, 0x89,
+	0x9d, 0x24, 0x14, 0x18, 0xd3, 0x23, 0x08, 0xcd, 0xb7, 0xd2, 0xd9, 0x5c, 0x32, 0x17, 0x13, 0x1b,
+	0x81, 0x9e, 0x82, 0x5f, 0x64, 0x33, 0x59, 0x30, 0x12, 0x93, 0xa4, 0x33, 0xea, 0xa5, 0xc6, 0x99,
+	0xab, 0x3c, 0xbd, 0x37, 0xaa, 0x68, 0x92, 0xf4, 0x18, 0x60, 0xa1, 0xa7, 0x59, 0x9e, 0x97, 0xb2,
+	0xaa, 0x98, 0xd7, 0x98, 0x2c, 0xf4, 0x4d, 0x23, 0xd0, 0x21, 0xec, 0x3d, 0xa9, 0xaa, 0x9e, 0xfe,
+	0x60, 0x7c, 0x64, 0xba, 0x46, 0xbe, 0xb3, 0xdc, 0x25, 0x84, 0x73, 0xb5, 0xac, 0xb3, 0xc5, 0x52,
+	0x96, 0xac, 0x85, 0x0d, 0xa9, 0x6d, 0x38, 0x5e, 0x67, 0xc4, 0x06, 0xa2, 0x27, 0xb0, 0xbb, 0x31,
+	0x95, 0x15, 0x6b, 0xc7, 0x24, 0x09, 0x45, 0xc7, 0xb6, 0x96, 0x55, 0xff, 0x02, 0x7c, 0x9c, 0x95,
+	0x46, 0x40, 0x9e, 0xe5, 0xfb, 0x6a, 0x77, 0x13, 0xd2, 0x7d, 0xf0, 0xdf, 0xb2, 0xe2, 0x75, 0xbd,
+	0x76, 0xf3, 0xd3, 0xff, 0x70, 0x21, 0xb4, 0xcd, 0xb6, 0x9e, 0xec, 0x1c, 0x3c, 0xad, 0xca, 0x9a,
+	0xb9, 0x38, 0xe2, 0xc1, 0xef, 0x11, 0x53, 0xae, 0xca, 0x5a, 0x20, 0xd4, 0xff, 0x72, 0xc0, 0x33,
+	0xbf, 0x5b, 0x9d, 0x0e, 0x21, 0xc4, 0xcb, 0xac, 0xec, 0x9c, 0xc4, 0x17, 0x81, 0x11, 0xb0, 0xe0,
+	0x0c, 0x7a, 0x76, 0xd3, 0x86, 0x20, 0x48, 0x74, 0xad, 0x8a, 0xd8, 0x35, 0x04, 0xf8, 0xd4, 0x73,
+	0x55, 0xe0, 0xe9, 0x7b, 0xa3, 0xf8, 0x8f, 0x89, 0x52, 0xbe, 0xe2, 0x84, 0xad, 0xf8, 0xef, 0xdb,
+	0x0c, 0x86, 0x10, 0xac, 0xab, 0x69, 0x1b, 0xc8, 0x64, 0xcc, 0xa3, 0x1d, 0x13, 0x3c, 0xde, 0xf2,
+	0xc8, 0xa1, 0x01, 0x78, 0x0f, 0xe3, 0x09, 0x8f, 0xdc, 0x59, 0x0b, 0x9d, 0xaf, 0xbe, 0x07, 0x00,
+	0x0a, 0x0f, 0x2a, 0x59, 0x83, 0x02, 0x00, 0x00,
 }
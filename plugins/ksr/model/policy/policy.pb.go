@@ -98,23 +98,26 @@ func (Policy_LabelSelector_LabelExpression_Operator) EnumDescriptor() ([]byte, [
 	return fileDescriptor0, []int{0, 1, 0, 0}
 }
 
-// The protocol (TCP or UDP) which traffic must match.
+// The protocol (TCP, UDP or SCTP) which traffic must match.
 // If not specified, this field defaults to TCP.
 // +optional
 type Policy_Port_Protocol int32
 
 const (
-	Policy_Port_TCP Policy_Port_Protocol = 0
-	Policy_Port_UDP Policy_Port_Protocol = 1
+	Policy_Port_TCP  Policy_Port_Protocol = 0
+	Policy_Port_UDP  Policy_Port_Protocol = 1
+	Policy_Port_SCTP Policy_Port_Protocol = 2
 )
 
 var Policy_Port_Protocol_name = map[int32]string{
 	0: "TCP",
 	1: "UDP",
+	2: "SCTP",
 }
 var Policy_Port_Protocol_value = map[string]int32{
-	"TCP": 0,
-	"UDP": 1,
+	"TCP":  0,
+	"UDP":  1,
+	"SCTP": 2,
 }
 
 func (x Policy_Port_Protocol) String() string {
@@ -356,6 +359,11 @@ type Policy_Port struct {
 	// will be matched.
 	// +optional
 	Port *Policy_Port_PortNameOrNumber `protobuf:"bytes,1,opt,name=port" json:"port,omitempty"`
+	// If set, indicates that the range of ports from port to end_port,
+	// inclusive, should be matched. Ignored unless port is a number.
+	// end_port must be equal or greater than port.
+	// +optional
+	EndPort int32 `protobuf:"varint,2,opt,name=end_port,json=endPort" json:"end_port,omitempty"`
 }
 
 func (m *Policy_Port) Reset()                    { *m = Policy_Port{} }
@@ -377,6 +385,13 @@ func (m *Policy_Port) GetPort() *Policy_Port_PortNameOrNumber {
 	return nil
 }
 
+func (m *Policy_Port) GetEndPort() int32 {
+	if m != nil {
+		return m.EndPort
+	}
+	return 0
+}
+
 // Numerical or named port.
 type Policy_Port_PortNameOrNumber struct {
 	Type Policy_Port_PortNameOrNumber_Type `protobuf:"varint,1,opt,name=type,enum=policy.Policy_Port_PortNameOrNumber_Type" json:"type,omitempty"`
@@ -589,49 +604,51 @@ func init() {
 func init() { proto.RegisterFile("policy.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 703 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xdd, 0x6e, 0xd3, 0x4a,
-	0x10, 0x8e, 0x1d, 0xe7, 0xa7, 0xe3, 0x9e, 0xd6, 0xda, 0x56, 0x55, 0x8e, 0x4f, 0x2e, 0xa2, 0x1c,
-	0x24, 0x02, 0x42, 0x06, 0x05, 0x15, 0x55, 0x88, 0x22, 0xb5, 0xc4, 0xa0, 0xa0, 0xd6, 0x31, 0x9b,
-	0x54, 0x20, 0x6e, 0x2c, 0xc7, 0x5d, 0xc0, 0xaa, 0x93, 0xb5, 0x36, 0x0e, 0x6a, 0x9e, 0xa5, 0xef,
-	0xc1, 0x53, 0xf0, 0x1a, 0xdc, 0xf2, 0x0c, 0x68, 0xc7, 0x8e, 0x5d, 0x42, 0x54, 0x0a, 0x57, 0x9e,
-	0x99, 0xfd, 0xbe, 0xd9, 0x99, 0xcf, 0x33, 0x0b, 0x9b, 0x31, 0x8f, 0xc2, 0x60, 0x61, 0xc5, 0x82,
-	0x27, 0x9c, 0x54, 0x53, 0xaf, 0x7d, 0xb5, 0x09, 0x55, 0x17, 0x4d, 0x42, 0x40, 0x9b, 0xfa, 0x13,
-	0xd6, 0x50, 0x5a, 0x4a, 0x67, 0x83, 0xa2, 0x4d, 0x9a, 0xb0, 0x21, 0xbf, 0xb3, 0xd8, 0x0f, 0x58,
-	0x43, 0xc5, 0x83, 0x22, 0x40, 0xee, 0x43, 0x25, 0xf2, 0xc7, 0x2c, 0x6a, 0x94, 0x5b, 0xe5, 0x8e,
-	0xde, 0xdd, 0xb5, 0xb2, 0x2b, 0xd2, 0x84, 0xd6, 0x89, 0x3c, 0xa3, 0x29, 0x84, 0x3c, 0x02, 0x2d,
-	0xe6, 0xe7, 0xb3, 0x86, 0xd6, 0x52, 0x3a, 0x7a, 0xb7, 0xb9, 0x0e, 0x3a, 0x64, 0x11, 0x0b, 0x12,
-	0x2e, 0x28, 0x22, 0xc9, 0x53, 0xd0, 0x53, 0x90, 0x97, 0x2c, 0x62, 0xd6, 0xa8, 0xb4, 0x94, 0xce,
-	0x56, 0xf7, 0xdf, 0x15, 0x62, 0xfa, 0x19, 0x2d, 0x62, 0x46, 0x21, 0xce, 0x6d, 0x72, 0x08, 0x9b,
-	0xe1, 0xf4, 0xa3, 0x60, 0xb3, 0x99, 0x27, 0xe6, 0x11, 0x6b, 0x54, 0xb1, 0x40, 0x73, 0x85, 0xdc,
-	0x4f, 0x21, 0x74, 0x1e, 0x31, 0xaa, 0x87, 0x85, 0x23, 0xaf, 0x66, 0xd7, 0xd8, 0x35, 0x64, 0xaf,
-	0x5e, 0x6d, 0x17, 0x64, 0x60, 0xb9, 0x6d, 0x3e, 0x84, 0x0a, 0x76, 0x43, 0x0c, 0x28, 0x5f, 0xb0,
-	0x45, 0x26, 0xa7, 0x34, 0xc9, 0x2e, 0x54, 0x3e, 0xfb, 0xd1, 0x7c, 0xa9, 0x64, 0xea, 0x98, 0xdf,
-	0x55, 0xf8, 0xe7, 0xa7, 0xfe, 0xc9, 0x3e, 0xe8, 0x13, 0x3f, 0x09, 0x3e, 0x79, 0xa9, 0xba, 0xca,
-	0x0d, 0xea, 0x02, 0x02, 0xd3, 0x0b, 0xdf, 0x82, 0x91, 0xd2, 0xd8, 0x65, 0x2c, 0xcb, 0x09, 0xf9,
-	0xb4, 0xa1, 0x22, 0xf7, 0xc1, 0x4d, 0x72, 0xa7, 0x9e, 0x9d, 0x73, 0xe8, 0x36, 0x66, 0x29, 0x02,
-	0xe6, 0x57, 0x05, 0xb6, 0x57, 0x40, 0x6b, 0xba, 0x7b, 0x03, 0x75, 0x1e, 0x33, 0xe1, 0x27, 0x5c,
-	0x60, 0x83, 0x5b, 0xdd, 0xfd, 0x3f, 0xb9, 0xd6, 0x1a, 0x64, 0x64, 0x9a, 0xa7, 0x29, 0x04, 0x93,
-	0x03, 0xb6, 0x14, 0xac, 0xfd, 0x1c, 0xea, 0x4b, 0x2c, 0xa9, 0x82, 0xda, 0x77, 0x8c, 0x12, 0x01,
-	0xa8, 0x3a, 0x83, 0x91, 0xd7, 0x77, 0x0c, 0x45, 0xda, 0xf6, 0xbb, 0xfe, 0x70, 0x34, 0x34, 0x54,
-	0x42, 0x60, 0xab, 0x37, 0xb0, 0x87, 0x9e, 0x3c, 0xc4, 0xa0, 0x51, 0x36, 0xbf, 0xa8, 0xa0, 0xb9,
-	0x5c, 0x24, 0xe4, 0x00, 0xea, 0xb8, 0x0d, 0x01, 0x97, 0x23, 0x2c, 0x2b, 0x6e, 0xfe, 0x32, 0x5e,
-	0x22, 0xb1, 0xdc, 0x0c, 0x43, 0x73, 0x34, 0x39, 0x90, 0xd3, 0x2c, 0x12, 0x6c, 0x5f, 0xef, 0xde,
-	0x59, 0xcb, 0xe2, 0x22, 0x71, 0xfc, 0x09, 0x1b, 0x08, 0x67, 0x3e, 0x19, 0x33, 0x9c, 0x6a, 0x91,
-	0x98, 0x57, 0x0a, 0x18, 0xab, 0x47, 0xe4, 0x10, 0x34, 0x9c, 0x71, 0x05, 0x8b, 0xb8, 0x77, 0x9b,
-	0x74, 0x16, 0xce, 0x3c, 0xd2, 0xc8, 0x1e, 0x54, 0xa7, 0x18, 0x44, 0xdd, 0x2b, 0x34, 0xf3, 0xf2,
-	0x8d, 0x2e, 0x17, 0x1b, 0xdd, 0x6e, 0x82, 0x86, 0x1b, 0x22, 0x05, 0x3b, 0x3b, 0x3d, 0xb6, 0xa9,
-	0x51, 0x22, 0x75, 0xd0, 0x9c, 0xa3, 0x53, 0xdb, 0x50, 0xda, 0x4d, 0xa8, 0x2f, 0xbb, 0x25, 0x35,
-	0x28, 0x8f, 0x5e, 0xb8, 0x46, 0x49, 0x1a, 0x67, 0x3d, 0xd7, 0x50, 0xcc, 0x6f, 0x0a, 0x68, 0x2e,
-	0x63, 0x22, 0x5f, 0x66, 0xe5, 0xd6, 0xcb, 0xfc, 0x0c, 0x20, 0x7f, 0x37, 0x66, 0x58, 0xe6, 0xef,
-	0x78, 0xd7, 0xf0, 0xe4, 0x09, 0xd4, 0xc3, 0xd8, 0x1b, 0x47, 0x3c, 0xb8, 0xc0, 0x66, 0xf4, 0xee,
-	0x7f, 0xab, 0x1a, 0x31, 0x26, 0xac, 0xbe, 0x7b, 0x2c, 0x21, 0xb4, 0x16, 0xc6, 0x68, 0x98, 0xfb,
-	0x50, 0xcb, 0x62, 0x52, 0x8b, 0x20, 0x3c, 0x17, 0xcb, 0xd7, 0x4d, 0xda, 0x52, 0x37, 0x76, 0x19,
-	0xb0, 0x38, 0xc1, 0x35, 0xd9, 0xa0, 0x99, 0x67, 0x7a, 0xa0, 0x5f, 0x7b, 0x1a, 0xc8, 0xdd, 0xfc,
-	0x67, 0xcb, 0x5d, 0xda, 0x59, 0xf3, 0x77, 0xd2, 0x7f, 0x2b, 0x81, 0x1f, 0x04, 0x9f, 0x64, 0x4b,
-	0xb7, 0xb3, 0xa6, 0x44, 0x8a, 0x00, 0xf3, 0x3d, 0x80, 0xfd, 0x17, 0xf9, 0xff, 0x07, 0x35, 0xe1,
-	0x37, 0x65, 0x57, 0x13, 0xde, 0x7e, 0x0d, 0x50, 0x3c, 0x8a, 0x44, 0x87, 0x5a, 0xcf, 0x7e, 0x79,
-	0x74, 0x76, 0x32, 0x32, 0x4a, 0xd2, 0xe9, 0x3b, 0xaf, 0xa8, 0x3d, 0x1c, 0x66, 0x5b, 0x92, 0xda,
-	0x2a, 0xd9, 0x03, 0x92, 0x1d, 0x78, 0x47, 0x4e, 0xcf, 0xcb, 0xe2, 0xe5, 0x71, 0x15, 0x07, 0xfe,
-	0xf1, 0x8f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x40, 0x91, 0x36, 0x48, 0x3c, 0x06, 0x00, 0x00,
+	// 722 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xd1, 0x6e, 0xd3, 0x4a,
+	0x10, 0x8d, 0x1d, 0x27, 0x71, 0xc7, 0xbd, 0xad, 0xb5, 0xb7, 0xaa, 0x5c, 0xdf, 0x3c, 0x44, 0xb9,
+	0x88, 0x06, 0x84, 0x0c, 0x0a, 0x2a, 0xaa, 0x10, 0x45, 0x6a, 0x1b, 0x83, 0x82, 0x5a, 0xc7, 0xac,
+	0x53, 0x81, 0x78, 0xb1, 0x1c, 0x67, 0x01, 0xab, 0x4e, 0xd6, 0xda, 0x38, 0xa8, 0xf9, 0x16, 0x7e,
+	0x86, 0x0f, 0xe0, 0x0f, 0x78, 0xe6, 0x95, 0x6f, 0x40, 0xbb, 0x76, 0xec, 0x12, 0xa2, 0xaa, 0xf0,
+	0xe4, 0x99, 0xdd, 0x73, 0x76, 0x76, 0x8e, 0xe7, 0x2c, 0x6c, 0x26, 0x34, 0x8e, 0xc2, 0x85, 0x95,
+	0x30, 0x9a, 0x52, 0x54, 0xcf, 0xb2, 0xf6, 0x97, 0x4d, 0xa8, 0xbb, 0x22, 0x44, 0x08, 0x94, 0x69,
+	0x30, 0x21, 0x86, 0xd4, 0x92, 0x3a, 0x1b, 0x58, 0xc4, 0xa8, 0x09, 0x1b, 0xfc, 0x3b, 0x4b, 0x82,
+	0x90, 0x18, 0xb2, 0xd8, 0x28, 0x17, 0xd0, 0x7d, 0xa8, 0xc5, 0xc1, 0x88, 0xc4, 0x46, 0xb5, 0x55,
+	0xed, 0x68, 0xdd, 0x1d, 0x2b, 0x2f, 0x91, 0x1d, 0x68, 0x9d, 0xf1, 0x3d, 0x9c, 0x41, 0xd0, 0x23,
+	0x50, 0x12, 0x3a, 0x9e, 0x19, 0x4a, 0x4b, 0xea, 0x68, 0xdd, 0xe6, 0x3a, 0xa8, 0x47, 0x62, 0x12,
+	0xa6, 0x94, 0x61, 0x81, 0x44, 0x4f, 0x41, 0xcb, 0x40, 0x7e, 0xba, 0x48, 0x88, 0x51, 0x6b, 0x49,
+	0x9d, 0xad, 0xee, 0xde, 0x0a, 0x31, 0xfb, 0x0c, 0x17, 0x09, 0xc1, 0x90, 0x14, 0x31, 0x3a, 0x82,
+	0xcd, 0x68, 0xfa, 0x81, 0x91, 0xd9, 0xcc, 0x67, 0xf3, 0x98, 0x18, 0x75, 0x71, 0x41, 0x73, 0x85,
+	0xdc, 0xcf, 0x20, 0x78, 0x1e, 0x13, 0xac, 0x45, 0x65, 0xc2, 0x4b, 0x93, 0x6b, 0xec, 0x86, 0x60,
+	0xaf, 0x96, 0xb6, 0x4b, 0x32, 0x90, 0x22, 0x36, 0x1f, 0x42, 0x4d, 0x74, 0x83, 0x74, 0xa8, 0x5e,
+	0x92, 0x45, 0x2e, 0x27, 0x0f, 0xd1, 0x0e, 0xd4, 0x3e, 0x05, 0xf1, 0x7c, 0xa9, 0x64, 0x96, 0x98,
+	0x3f, 0x64, 0xf8, 0xe7, 0x97, 0xfe, 0xd1, 0x01, 0x68, 0x93, 0x20, 0x0d, 0x3f, 0xfa, 0x99, 0xba,
+	0xd2, 0x0d, 0xea, 0x82, 0x00, 0x66, 0x05, 0xdf, 0x80, 0x9e, 0xd1, 0xc8, 0x55, 0xc2, 0xaf, 0x13,
+	0xd1, 0xa9, 0x21, 0x0b, 0xee, 0x83, 0x9b, 0xe4, 0xce, 0x32, 0xbb, 0xe0, 0xe0, 0x6d, 0x71, 0x4a,
+	0xb9, 0x60, 0x7e, 0x95, 0x60, 0x7b, 0x05, 0xb4, 0xa6, 0xbb, 0xd7, 0xa0, 0xd2, 0x84, 0xb0, 0x20,
+	0xa5, 0x4c, 0x34, 0xb8, 0xd5, 0x3d, 0xf8, 0x93, 0xb2, 0xd6, 0x20, 0x27, 0xe3, 0xe2, 0x98, 0x52,
+	0x30, 0x3e, 0x60, 0x4b, 0xc1, 0xda, 0xcf, 0x41, 0x5d, 0x62, 0x51, 0x1d, 0xe4, 0xbe, 0xa3, 0x57,
+	0x10, 0x40, 0xdd, 0x19, 0x0c, 0xfd, 0xbe, 0xa3, 0x4b, 0x3c, 0xb6, 0xdf, 0xf6, 0xbd, 0xa1, 0xa7,
+	0xcb, 0x08, 0xc1, 0x56, 0x6f, 0x60, 0x7b, 0x3e, 0xdf, 0x14, 0x8b, 0x7a, 0xd5, 0xfc, 0x26, 0x83,
+	0xe2, 0x52, 0x96, 0xa2, 0x43, 0x50, 0x85, 0x1b, 0x42, 0xca, 0x47, 0x98, 0xdf, 0xb8, 0xf9, 0xdb,
+	0x78, 0xb1, 0xd4, 0x72, 0x73, 0x0c, 0x2e, 0xd0, 0xe8, 0x90, 0x4f, 0x33, 0x4b, 0x45, 0xfb, 0x5a,
+	0xf7, 0xce, 0x5a, 0x16, 0x65, 0xa9, 0x13, 0x4c, 0xc8, 0x80, 0x39, 0xf3, 0xc9, 0x88, 0x88, 0xa9,
+	0x66, 0x29, 0xda, 0x03, 0x95, 0x4c, 0xc7, 0xbe, 0x60, 0x73, 0x95, 0x6a, 0xb8, 0x41, 0xa6, 0x63,
+	0x0e, 0x36, 0x3f, 0x4b, 0xa0, 0xaf, 0xb2, 0xd0, 0x11, 0x28, 0x62, 0xfc, 0x25, 0x71, 0xbf, 0x7b,
+	0xb7, 0xa9, 0x64, 0x09, 0x3b, 0x08, 0x1a, 0xda, 0x85, 0xfa, 0x54, 0x2c, 0xe6, 0xc5, 0xf2, 0xac,
+	0x30, 0x7b, 0xb5, 0x34, 0x7b, 0xbb, 0x09, 0x8a, 0x30, 0x0f, 0xd7, 0xf2, 0xe2, 0xfc, 0xc4, 0xc6,
+	0x7a, 0x05, 0xa9, 0xa0, 0x38, 0xc7, 0xe7, 0xb6, 0x2e, 0xb5, 0xef, 0x82, 0xba, 0x14, 0x02, 0x35,
+	0xa0, 0x3a, 0x3c, 0x75, 0xf5, 0x0a, 0x0f, 0x2e, 0x7a, 0xae, 0x2e, 0x71, 0x9c, 0x77, 0x3a, 0x74,
+	0x75, 0xd9, 0xfc, 0x2e, 0x81, 0xe2, 0x12, 0xc2, 0x0a, 0xc7, 0x4b, 0xb7, 0x76, 0xfc, 0x33, 0x80,
+	0xe2, 0x71, 0x99, 0x19, 0xf2, 0x2d, 0x78, 0xd7, 0xf0, 0xe8, 0x09, 0xa8, 0x51, 0xe2, 0x8f, 0x62,
+	0x1a, 0x5e, 0x8a, 0xb6, 0xb4, 0xee, 0x7f, 0xab, 0x6a, 0x11, 0xc2, 0xac, 0xbe, 0x7b, 0xc2, 0x21,
+	0xb8, 0x11, 0x25, 0x22, 0x30, 0x0f, 0xa0, 0x91, 0xaf, 0x71, 0x55, 0xc2, 0x68, 0xcc, 0x96, 0x4f,
+	0x20, 0x8f, 0xb9, 0x82, 0xe4, 0x2a, 0x24, 0x49, 0x2a, 0xbc, 0xb4, 0x81, 0xf3, 0xcc, 0xf4, 0x41,
+	0xbb, 0xf6, 0x7e, 0xa0, 0xfd, 0x62, 0x22, 0xb8, 0xe1, 0xfe, 0x5d, 0xf3, 0x9f, 0xf2, 0x01, 0xd8,
+	0x07, 0xe5, 0x3d, 0xa3, 0x13, 0x43, 0x5e, 0x0f, 0x24, 0x7c, 0x52, 0x38, 0xc0, 0x7c, 0x07, 0x60,
+	0xff, 0xc5, 0xf9, 0xff, 0x83, 0x9c, 0xd2, 0x9b, 0x4e, 0x97, 0x53, 0xda, 0x7e, 0x05, 0x50, 0xbe,
+	0x9c, 0x48, 0x83, 0x46, 0xcf, 0x7e, 0x71, 0x7c, 0x71, 0x36, 0xd4, 0x2b, 0x3c, 0xe9, 0x3b, 0x2f,
+	0xb1, 0xed, 0x79, 0xb9, 0x95, 0xb2, 0x58, 0x46, 0xbb, 0x80, 0xf2, 0x0d, 0xff, 0xd8, 0xe9, 0xf9,
+	0xf9, 0x7a, 0x75, 0x54, 0x17, 0xae, 0x78, 0xfc, 0x73, 0x00, 0x67, 0x3c, 0x40, 0xff, 0x61, 0x06,
+	0x00, 0x00,
 }
@@ -146,6 +146,22 @@ type Service struct {
 	// LoadBalancer and ExternalTrafficPolicy is set to Local.
 	// +optional
 	HealthCheckNodePort int32 `protobuf:"varint,12,opt,name=health_check_node_port,json=healthCheckNodePort" json:"health_check_node_port,omitempty"`
+	// Annotations is an unstructured key value map stored with the service
+	// metadata. Contiv uses it to read service options not expressible
+	// in the service spec (e.g. "contiv.vpp/global-service").
+	// +optional
+	Annotations map[string]string `protobuf:"bytes,13,rep,name=annotations" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// sessionAffinityTimeout is the maximum session sticky time in seconds
+	// for the ClientIP session affinity (sessionAffinityConfig.clientIP.timeoutSeconds).
+	// Zero if not specified (Kubernetes defaults to 10800, i.e. 3 hours).
+	// +optional
+	SessionAffinityTimeout int32 `protobuf:"varint,14,opt,name=session_affinity_timeout,json=sessionAffinityTimeout" json:"session_affinity_timeout,omitempty"`
+	// LoadBalancer ingress IPs are the IP addresses of the load-balancer as written
+	// into the service status (status.loadBalancer.ingress[].ip) by the cloud provider
+	// or by the load-balancer IP allocator of contiv-ksr.
+	// Only applies to Service Type: LoadBalancer.
+	// +optional
+	LoadbalancerIngressIps []string `protobuf:"bytes,15,rep,name=loadbalancer_ingress_ips,json=loadbalancerIngressIps" json:"loadbalancer_ingress_ips,omitempty"`
 }
 
 func (m *Service) Reset()                    { *m = Service{} }
@@ -237,6 +253,27 @@ func (m *Service) GetHealthCheckNodePort() int32 {
 	return 0
 }
 
+func (m *Service) GetAnnotations() map[string]string {
+	if m != nil {
+		return m.Annotations
+	}
+	return nil
+}
+
+func (m *Service) GetSessionAffinityTimeout() int32 {
+	if m != nil {
+		return m.SessionAffinityTimeout
+	}
+	return 0
+}
+
+func (m *Service) GetLoadbalancerIngressIps() []string {
+	if m != nil {
+		return m.LoadbalancerIngressIps
+	}
+	return nil
+}
+
 // ServicePort contains information on service's port.
 type Service_ServicePort struct {
 	// The name of this port within the service. This must be a DNS_LABEL.
@@ -245,7 +282,7 @@ type Service_ServicePort struct {
 	// Optional if only one ServicePort is defined on this service.
 	// +optional
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
-	// The IP protocol for this port. Supports "TCP" and "UDP".
+	// The IP protocol for this port. Supports "TCP", "UDP" and "SCTP".
 	// Default is TCP.
 	// +optional
 	Protocol string `protobuf:"bytes,2,opt,name=protocol" json:"protocol,omitempty"`
@@ -349,39 +386,43 @@ func init() {
 func init() { proto.RegisterFile("service.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 530 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0x5d, 0x6f, 0xd3, 0x30,
-	0x14, 0x25, 0x6d, 0xfa, 0x75, 0xb3, 0x8f, 0xca, 0x7c, 0x2c, 0x2a, 0x63, 0x2a, 0x7b, 0xa1, 0x3c,
-	0x50, 0xa1, 0x4e, 0x42, 0x68, 0xf0, 0x32, 0xd0, 0x84, 0xf2, 0x40, 0x99, 0xd2, 0xb2, 0xd7, 0xc8,
-	0x4b, 0xbd, 0x36, 0x9a, 0xb1, 0x2d, 0xdb, 0xad, 0xc8, 0x3f, 0xe2, 0x95, 0xff, 0xc4, 0x0f, 0x41,
-	0xbe, 0x4e, 0xbb, 0x56, 0x9a, 0x10, 0x4f, 0xb9, 0x3e, 0xe7, 0xdc, 0xdc, 0x13, 0xdf, 0x13, 0xd8,
-	0x37, 0x4c, 0xaf, 0x8a, 0x9c, 0x0d, 0x95, 0x96, 0x56, 0x92, 0x56, 0x75, 0x3c, 0xfd, 0xdd, 0x82,
-	0xd6, 0xc4, 0xd7, 0x84, 0x40, 0x28, 0xe8, 0x0f, 0x16, 0x07, 0xfd, 0x60, 0xd0, 0x49, 0xb1, 0x26,
-	0xc7, 0xd0, 0x71, 0x4f, 0xa3, 0x68, 0xce, 0xe2, 0x1a, 0x12, 0xf7, 0x00, 0x79, 0x0b, 0xa1, 0x92,
-	0xda, 0xc6, 0xf5, 0x7e, 0x7d, 0x10, 0x8d, 0x8e, 0x87, 0xeb, 0x21, 0x93, 0xdd, 0xe7, 0x95, 0xd4,
-	0x36, 0x45, 0x25, 0x39, 0x87, 0xb6, 0x61, 0x9c, 0xe5, 0x56, 0xea, 0x38, 0xc4, 0xae, 0x93, 0x07,
-	0xba, 0xbc, 0xe0, 0x52, 0x58, 0x5d, 0xa6, 0x1b, 0x3d, 0x79, 0x01, 0x90, 0xf3, 0xa5, 0xb1, 0x4c,
-	0x67, 0x85, 0x8a, 0x1b, 0xde, 0x4c, 0x85, 0x24, 0x8a, 0xbc, 0x84, 0xbd, 0xea, 0x4d, 0x99, 0x2d,
-	0x15, 0x8b, 0x9b, 0x28, 0x88, 0x2a, 0x6c, 0x5a, 0x2a, 0xe6, 0x24, 0xec, 0xa7, 0x65, 0x5a, 0x50,
-	0x9e, 0x15, 0xca, 0xc4, 0xad, 0x7e, 0xdd, 0x49, 0xd6, 0x58, 0xa2, 0x0c, 0x79, 0x0d, 0x5d, 0xc3,
-	0x8c, 0x29, 0xa4, 0xc8, 0xe8, 0xed, 0x6d, 0x21, 0x0a, 0x5b, 0xc6, 0x6d, 0x7c, 0xd3, 0x61, 0x85,
-	0x5f, 0x54, 0x30, 0x79, 0x05, 0x87, 0x5c, 0xd2, 0xd9, 0x0d, 0xe5, 0x54, 0xe4, 0xde, 0x54, 0x07,
-	0x95, 0x07, 0xdb, 0x70, 0xa2, 0xc8, 0x47, 0xe8, 0xed, 0x08, 0x8d, 0x5c, 0xea, 0x9c, 0x65, 0x9a,
-	0x8a, 0x39, 0x33, 0x31, 0xa0, 0x89, 0x78, 0x5b, 0x31, 0x41, 0x41, 0x8a, 0x3c, 0x79, 0x07, 0x47,
-	0x1b, 0xd3, 0x56, 0x3b, 0x53, 0x79, 0xa6, 0x24, 0x2f, 0xf2, 0x32, 0x8e, 0x70, 0xdc, 0xd3, 0x35,
-	0x3d, 0xf5, 0xec, 0x15, 0x92, 0xe4, 0x0c, 0x9e, 0x2d, 0x18, 0xe5, 0x76, 0x91, 0xe5, 0x0b, 0x96,
-	0xdf, 0x65, 0x42, 0xce, 0x58, 0x86, 0xeb, 0xda, 0xeb, 0x07, 0x83, 0x46, 0xfa, 0xd8, 0xb3, 0x9f,
-	0x1d, 0x39, 0x96, 0x33, 0xdc, 0x52, 0xef, 0x4f, 0x0d, 0xa2, 0xad, 0xad, 0x3d, 0x98, 0x89, 0x1e,
-	0xb4, 0x31, 0x45, 0xb9, 0xe4, 0x55, 0x24, 0x36, 0x67, 0xa7, 0xaf, 0x12, 0xe1, 0x46, 0xf8, 0x9d,
-	0x27, 0x10, 0x59, 0xaa, 0xe7, 0xcc, 0xfa, 0xe9, 0x61, 0x3f, 0x18, 0x44, 0xa3, 0xc1, 0xbf, 0xc2,
-	0x32, 0x4c, 0x84, 0xfd, 0xa6, 0x27, 0x56, 0x17, 0x62, 0x9e, 0x82, 0x6f, 0x46, 0x3b, 0xcf, 0xa1,
-	0x73, 0xff, 0x19, 0x0d, 0x9c, 0xd1, 0x16, 0x6b, 0xef, 0xbf, 0x02, 0x88, 0xb6, 0x1a, 0xc9, 0x05,
-	0x84, 0x18, 0x04, 0xe7, 0xfd, 0x60, 0xf4, 0xe6, 0x7f, 0x07, 0x0e, 0x5d, 0x54, 0x52, 0x6c, 0x25,
-	0x47, 0xd0, 0x2a, 0x84, 0xcd, 0x56, 0xd4, 0x7f, 0x69, 0x23, 0x6d, 0x16, 0xc2, 0x5e, 0x53, 0xee,
-	0xb2, 0x68, 0x50, 0x8d, 0x5c, 0xdd, 0x67, 0xd1, 0x23, 0xd7, 0x94, 0x9f, 0x9e, 0x40, 0x88, 0x81,
-	0x03, 0x68, 0x8e, 0xbf, 0x7f, 0xfd, 0x74, 0x99, 0x76, 0x1f, 0xb9, 0x7a, 0x32, 0x4d, 0x93, 0xf1,
-	0x97, 0x6e, 0xd0, 0xfb, 0x00, 0xfb, 0x3b, 0x29, 0x27, 0x5d, 0xa8, 0xdf, 0xb1, 0xb2, 0xba, 0x66,
-	0x57, 0x92, 0x27, 0xd0, 0x58, 0x51, 0xbe, 0x5c, 0xff, 0x75, 0xfe, 0x70, 0x5e, 0x7b, 0x1f, 0xdc,
-	0x34, 0xf1, 0xb6, 0xcf, 0xfe, 0x06, 0x00, 0x00, 0xff, 0xff, 0xc4, 0x53, 0xa4, 0x4a, 0xd4, 0x03,
-	0x00, 0x00,
+	// 602 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x5d, 0x4f, 0x14, 0x31,
+	0x14, 0x75, 0xd8, 0xef, 0x3b, 0x7c, 0x6c, 0xaa, 0x42, 0xb3, 0x22, 0x59, 0x78, 0x71, 0x7d, 0x70,
+	0x63, 0x20, 0x31, 0x04, 0x8d, 0x09, 0x12, 0x62, 0xe6, 0x41, 0x24, 0xb3, 0x2b, 0xaf, 0x93, 0x32,
+	0x94, 0xa5, 0x61, 0x68, 0x9b, 0xb6, 0x4b, 0x9c, 0x7f, 0x64, 0xfc, 0x5d, 0xfe, 0x10, 0xd3, 0xdb,
+	0x59, 0xd8, 0x45, 0x62, 0xf4, 0x69, 0x6e, 0xef, 0x39, 0xb7, 0xf7, 0xee, 0xb9, 0xa7, 0x0b, 0x2b,
+	0x96, 0x9b, 0x5b, 0x91, 0xf3, 0xa1, 0x36, 0xca, 0x29, 0xd2, 0xaa, 0x8e, 0x3b, 0x3f, 0x3b, 0xd0,
+	0x1a, 0x85, 0x98, 0x10, 0xa8, 0x4b, 0x76, 0xc3, 0x69, 0xd4, 0x8f, 0x06, 0x9d, 0x14, 0x63, 0xb2,
+	0x09, 0x1d, 0xff, 0xb5, 0x9a, 0xe5, 0x9c, 0x2e, 0x21, 0x70, 0x9f, 0x20, 0x6f, 0xa1, 0xae, 0x95,
+	0x71, 0xb4, 0xd6, 0xaf, 0x0d, 0xe2, 0xdd, 0xcd, 0xe1, 0xac, 0xc9, 0x68, 0xf1, 0x7b, 0xaa, 0x8c,
+	0x4b, 0x91, 0x49, 0x0e, 0xa0, 0x6d, 0x79, 0xc1, 0x73, 0xa7, 0x0c, 0xad, 0x63, 0xd5, 0xd6, 0x23,
+	0x55, 0x81, 0x70, 0x2c, 0x9d, 0x29, 0xd3, 0x3b, 0x3e, 0x79, 0x09, 0x90, 0x17, 0x53, 0xeb, 0xb8,
+	0xc9, 0x84, 0xa6, 0x8d, 0x30, 0x4c, 0x95, 0x49, 0x34, 0xd9, 0x86, 0xe5, 0xea, 0xa6, 0xcc, 0x95,
+	0x9a, 0xd3, 0x26, 0x12, 0xe2, 0x2a, 0x37, 0x2e, 0x35, 0xf7, 0x14, 0xfe, 0xdd, 0x71, 0x23, 0x59,
+	0x91, 0x09, 0x6d, 0x69, 0xab, 0x5f, 0xf3, 0x94, 0x59, 0x2e, 0xd1, 0x96, 0xbc, 0x86, 0xae, 0xe5,
+	0xd6, 0x0a, 0x25, 0x33, 0x76, 0x79, 0x29, 0xa4, 0x70, 0x25, 0x6d, 0xe3, 0x4d, 0x6b, 0x55, 0xfe,
+	0xb0, 0x4a, 0x93, 0x57, 0xb0, 0x56, 0x28, 0x76, 0x71, 0xce, 0x0a, 0x26, 0xf3, 0x30, 0x54, 0x07,
+	0x99, 0xab, 0xf3, 0xe9, 0x44, 0x93, 0x0f, 0xd0, 0x5b, 0x20, 0x5a, 0x35, 0x35, 0x39, 0xcf, 0x0c,
+	0x93, 0x13, 0x6e, 0x29, 0xe0, 0x10, 0x74, 0x9e, 0x31, 0x42, 0x42, 0x8a, 0x38, 0x79, 0x07, 0x1b,
+	0x77, 0x43, 0x3b, 0xe3, 0x87, 0xca, 0x33, 0xad, 0x0a, 0x91, 0x97, 0x34, 0xc6, 0x76, 0xcf, 0x67,
+	0xf0, 0x38, 0xa0, 0xa7, 0x08, 0x92, 0x3d, 0x58, 0xbf, 0xe2, 0xac, 0x70, 0x57, 0x59, 0x7e, 0xc5,
+	0xf3, 0xeb, 0x4c, 0xaa, 0x0b, 0x9e, 0xe1, 0xba, 0x96, 0xfb, 0xd1, 0xa0, 0x91, 0x3e, 0x0d, 0xe8,
+	0x91, 0x07, 0x4f, 0xd4, 0x05, 0x6e, 0x89, 0x1c, 0x41, 0xcc, 0xa4, 0x54, 0x8e, 0x39, 0xa1, 0xa4,
+	0xa5, 0x2b, 0xb8, 0xa2, 0xed, 0x3f, 0x56, 0x74, 0x78, 0xcf, 0x09, 0x5b, 0x9a, 0xaf, 0x22, 0xfb,
+	0x40, 0x1f, 0x6a, 0x98, 0x39, 0x71, 0xc3, 0xd5, 0xd4, 0xd1, 0x55, 0xec, 0xbd, 0xfe, 0x40, 0xcb,
+	0x71, 0x40, 0x7d, 0xe5, 0xa2, 0xa4, 0x72, 0x62, 0xb8, 0xb5, 0xb8, 0xac, 0x35, 0xd4, 0x69, 0x7d,
+	0x41, 0xdb, 0x00, 0x27, 0xda, 0xf6, 0x7e, 0x2d, 0x41, 0x3c, 0x67, 0xb7, 0x47, 0xcd, 0xdc, 0x83,
+	0x36, 0xda, 0x3f, 0x57, 0x45, 0xe5, 0xe5, 0xbb, 0xb3, 0xe7, 0x57, 0x56, 0xf6, 0xf3, 0x61, 0x4c,
+	0x12, 0x88, 0x1d, 0x33, 0x13, 0xee, 0x82, 0x6c, 0xf5, 0x7e, 0x34, 0x88, 0x77, 0x07, 0x7f, 0x73,
+	0xf9, 0x30, 0x91, 0xee, 0xab, 0x19, 0x39, 0x23, 0xe4, 0x24, 0x85, 0x50, 0x8c, 0xe3, 0xbc, 0x80,
+	0xce, 0xbd, 0xfe, 0x0d, 0xec, 0xd1, 0x96, 0x95, 0xe8, 0xbd, 0x1f, 0x11, 0xc4, 0x73, 0x85, 0xe4,
+	0x10, 0xea, 0xe8, 0x60, 0x3f, 0xfb, 0xea, 0xee, 0x9b, 0x7f, 0x6d, 0x38, 0xf4, 0x1e, 0x4f, 0xb1,
+	0x94, 0x6c, 0x40, 0x4b, 0x48, 0x97, 0xdd, 0xb2, 0xf0, 0x4b, 0x1b, 0x69, 0x53, 0x48, 0x77, 0xc6,
+	0x0a, 0xff, 0x88, 0x2c, 0xb2, 0x11, 0xab, 0x85, 0x47, 0x14, 0x32, 0x67, 0xac, 0xd8, 0xd9, 0x82,
+	0x3a, 0xbe, 0x14, 0x80, 0xe6, 0xc9, 0xb7, 0x2f, 0x9f, 0x8e, 0xd3, 0xee, 0x13, 0x1f, 0x8f, 0xc6,
+	0x69, 0x72, 0xf2, 0xb9, 0x1b, 0xf5, 0xde, 0xc3, 0xca, 0xc2, 0xf3, 0x24, 0x5d, 0xa8, 0x5d, 0xf3,
+	0xb2, 0x92, 0xd9, 0x87, 0xe4, 0x19, 0x34, 0x6e, 0x59, 0x31, 0x9d, 0xfd, 0x5d, 0x84, 0xc3, 0xc1,
+	0xd2, 0x7e, 0xd4, 0xfb, 0x08, 0xdd, 0x87, 0xc6, 0xf9, 0x9f, 0xfa, 0xf3, 0x26, 0x6e, 0x6b, 0xef,
+	0xf7, 0x00, 0x8e, 0x7e, 0x13, 0xa6, 0xcd, 0x04, 0x00, 0x00,
 }
@@ -0,0 +1,343 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ksr
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	nodeID "github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/logging"
+)
+
+const (
+	// VswitchReadyCondition is the type of the node condition maintained by KSR
+	// to reflect the health of contiv-vswitch running on the node.
+	VswitchReadyCondition coreV1.NodeConditionType = "ContivVswitchReady"
+
+	// VswitchNotReadyTaint is the key of the NoSchedule taint optionally applied
+	// on nodes with not ready contiv-vswitch.
+	VswitchNotReadyTaint = "contiv.vpp/vswitch-not-ready"
+
+	// vswitchReadyReason and vswitchNotReadyReason are reasons used in the node conditions.
+	vswitchReadyReason    = "ContivVswitchReady"
+	vswitchNotReadyReason = "ContivVswitchNotReady"
+
+	// vswitchStatusMissingMsg is the condition message used when the vswitch status
+	// is not reported (or has expired).
+	vswitchStatusMissingMsg = "vswitch status is not reported"
+
+	// nodeConditionResyncPeriod is the period in which the conditions of all nodes
+	// are re-evaluated, in case some update has failed.
+	nodeConditionResyncPeriod = 1 * time.Minute
+
+	// defaultStatusGracePeriod is the default time given to contiv-vswitch to report
+	// its status after the node was created (or KSR was started).
+	defaultStatusGracePeriod = 2 * time.Minute
+)
+
+// NodeConditionUpdater watches the vswitch status published by contiv-agents
+// and maintains the ContivVswitchReady condition of the K8s nodes accordingly.
+// Optionally, nodes with not ready vswitch are tainted with a NoSchedule taint
+// and marked with the NetworkUnavailable condition (for which the node lifecycle
+// controller adds its own NoSchedule taint). The conditions and the taint are
+// cleared automatically once the vswitch becomes healthy.
+type NodeConditionUpdater struct {
+	Log       logging.Logger
+	K8sClient kubernetes.Interface
+
+	// Broker and Watcher are used to access the vswitch status data in the data store
+	// (they are expected to be prefixed with the KSR prefix).
+	Broker  KeyProtoValBroker
+	Watcher keyval.ProtoWatcher
+
+	// TaintNotReadyNodes enables the NoSchedule taint and the NetworkUnavailable
+	// condition for nodes with not ready vswitch.
+	TaintNotReadyNodes bool
+
+	// StatusGracePeriod is the time within which the vswitch of a node is expected
+	// to report its status, counted from the creation of the node or from the start
+	// of the updater, whichever is later. Nodes that have not reported any status
+	// within the grace period are considered not ready. Defaults to 2 minutes.
+	StatusGracePeriod time.Duration
+
+	watchCh chan keyval.ProtoWatchResp
+	started time.Time
+}
+
+// Init subscribes to the changes of the vswitch status. The changes are not
+// processed until Start() is called.
+func (u *NodeConditionUpdater) Init() error {
+	u.watchCh = make(chan keyval.ProtoWatchResp, 100)
+	if u.StatusGracePeriod == 0 {
+		u.StatusGracePeriod = defaultStatusGracePeriod
+	}
+	return u.Watcher.Watch(keyval.ToChanProto(u.watchCh), nil, nodeID.VswitchStatusKeyPrefix)
+}
+
+// Start starts the go routine updating the node conditions.
+func (u *NodeConditionUpdater) Start(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	u.started = time.Now()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		u.resync()
+		ticker := time.NewTicker(nodeConditionResyncPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				u.resync()
+			case resp := <-u.watchCh:
+				u.processStatusChange(resp)
+			}
+		}
+	}()
+}
+
+// resync updates the conditions of all K8s nodes based on the vswitch status
+// currently present in the data store. Nodes without the vswitch status are left
+// untouched within the status grace period, afterwards they are considered not ready
+// (the vswitch has never started or the status has expired while KSR was down).
+func (u *NodeConditionUpdater) resync() {
+	statuses := make(map[string]*nodeID.VswitchStatus)
+	it, err := u.Broker.ListValues(nodeID.VswitchStatusKeyPrefix)
+	if err != nil {
+		u.Log.Warnf("Failed to list vswitch status: %v", err)
+		return
+	}
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		vswitchStatus := &nodeID.VswitchStatus{}
+		if err := kv.GetValue(vswitchStatus); err != nil {
+			u.Log.Warnf("Failed to read vswitch status %s: %v", kv.GetKey(), err)
+			continue
+		}
+		statuses[vswitchStatus.NodeName] = vswitchStatus
+	}
+
+	nodes, err := u.K8sClient.CoreV1().Nodes().List(metaV1.ListOptions{})
+	if err != nil {
+		u.Log.Warnf("Failed to list K8s nodes: %v", err)
+		return
+	}
+	for i := range nodes.Items {
+		k8sNode := &nodes.Items[i]
+		vswitchStatus, hasStatus := statuses[k8sNode.Name]
+		if !hasStatus && u.inStatusGracePeriod(k8sNode) {
+			continue
+		}
+		if err := u.updateNode(k8sNode, vswitchStatus); err != nil {
+			u.Log.Warnf("Failed to update node %s: %v", k8sNode.Name, err)
+		}
+	}
+}
+
+// inStatusGracePeriod returns true if the vswitch of the given node may still
+// be starting up and therefore not reporting its status yet.
+func (u *NodeConditionUpdater) inStatusGracePeriod(k8sNode *coreV1.Node) bool {
+	since := u.started
+	if created := k8sNode.CreationTimestamp.Time; created.After(since) {
+		since = created
+	}
+	return time.Since(since) < u.StatusGracePeriod
+}
+
+// processStatusChange updates the conditions of the node whose vswitch status has changed.
+func (u *NodeConditionUpdater) processStatusChange(resp keyval.ProtoWatchResp) {
+	key := resp.GetKey()
+	idx := strings.LastIndex(key, nodeID.VswitchStatusKeyPrefix)
+	if idx == -1 {
+		u.Log.Warnf("Unexpected vswitch status key: %s", key)
+		return
+	}
+	nodeName := key[idx+len(nodeID.VswitchStatusKeyPrefix):]
+
+	var vswitchStatus *nodeID.VswitchStatus
+	if resp.GetChangeType() == datasync.Put {
+		vswitchStatus = &nodeID.VswitchStatus{}
+		if err := resp.GetValue(vswitchStatus); err != nil {
+			u.Log.Warnf("Failed to read vswitch status %s: %v", key, err)
+			return
+		}
+	}
+
+	if err := u.UpdateNodeCondition(nodeName, vswitchStatus); err != nil {
+		u.Log.Warnf("Failed to update node %s: %v", nodeName, err)
+	}
+}
+
+// UpdateNodeCondition updates the conditions (and taint) of the given node
+// according to the given vswitch status. Nil status means that the vswitch
+// status is not reported and the vswitch is therefore considered not ready.
+func (u *NodeConditionUpdater) UpdateNodeCondition(nodeName string, vswitchStatus *nodeID.VswitchStatus) error {
+	k8sNode, err := u.K8sClient.CoreV1().Nodes().Get(nodeName, metaV1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	return u.updateNode(k8sNode, vswitchStatus)
+}
+
+// updateNode updates the given K8s node according to the vswitch status.
+// The node is updated only if the condition or the taint has changed.
+func (u *NodeConditionUpdater) updateNode(k8sNode *coreV1.Node, vswitchStatus *nodeID.VswitchStatus) error {
+	ready, message := isVswitchReady(vswitchStatus)
+	now := metaV1.Now()
+	k8sNode = k8sNode.DeepCopy()
+
+	// update node conditions (part of the node status)
+	readyStatus, unavailableStatus := coreV1.ConditionFalse, coreV1.ConditionTrue
+	reason := vswitchNotReadyReason
+	if ready {
+		readyStatus, unavailableStatus = coreV1.ConditionTrue, coreV1.ConditionFalse
+		reason = vswitchReadyReason
+	}
+	changed := setNodeCondition(k8sNode, coreV1.NodeCondition{
+		Type:    VswitchReadyCondition,
+		Status:  readyStatus,
+		Reason:  reason,
+		Message: message,
+	}, now)
+	if u.TaintNotReadyNodes {
+		changed = setNodeCondition(k8sNode, coreV1.NodeCondition{
+			Type:    coreV1.NodeNetworkUnavailable,
+			Status:  unavailableStatus,
+			Reason:  reason,
+			Message: message,
+		}, now) || changed
+	} else {
+		changed = clearNetworkUnavailable(k8sNode, now) || changed
+	}
+	if changed {
+		u.Log.WithFields(logging.Fields{"node": k8sNode.Name, "ready": ready, "message": message}).
+			Info("Updating vswitch node condition")
+		updated, err := u.K8sClient.CoreV1().Nodes().UpdateStatus(k8sNode)
+		if err != nil {
+			return err
+		}
+		// continue with the updated node (with new resource version)
+		k8sNode = updated
+	}
+	return u.updateNodeTaint(k8sNode, ready)
+}
+
+// updateNodeTaint adds or removes the vswitch-not-ready taint of the given node.
+// The taint is removed from a ready node even if tainting is disabled, in order
+// to clean up after a previous configuration.
+func (u *NodeConditionUpdater) updateNodeTaint(k8sNode *coreV1.Node, ready bool) error {
+	taintIdx := -1
+	for i, taint := range k8sNode.Spec.Taints {
+		if taint.Key == VswitchNotReadyTaint && taint.Effect == coreV1.TaintEffectNoSchedule {
+			taintIdx = i
+			break
+		}
+	}
+
+	switch {
+	case !ready && u.TaintNotReadyNodes && taintIdx == -1:
+		now := metaV1.Now()
+		k8sNode.Spec.Taints = append(k8sNode.Spec.Taints, coreV1.Taint{
+			Key:       VswitchNotReadyTaint,
+			Effect:    coreV1.TaintEffectNoSchedule,
+			TimeAdded: &now,
+		})
+	case ready && taintIdx != -1:
+		k8sNode.Spec.Taints = append(k8sNode.Spec.Taints[:taintIdx], k8sNode.Spec.Taints[taintIdx+1:]...)
+	default:
+		return nil
+	}
+
+	u.Log.WithFields(logging.Fields{"node": k8sNode.Name, "ready": ready}).Info("Updating vswitch node taint")
+	_, err := u.K8sClient.CoreV1().Nodes().Update(k8sNode)
+	return err
+}
+
+// isVswitchReady evaluates the given vswitch status, returns readiness and
+// the message describing it.
+func isVswitchReady(vswitchStatus *nodeID.VswitchStatus) (ready bool, message string) {
+	if vswitchStatus == nil {
+		return false, vswitchStatusMissingMsg
+	}
+	if vswitchStatus.VppConnected && vswitchStatus.Resynced && vswitchStatus.AgentOk {
+		return true, "vswitch is ready"
+	}
+	return false, vswitchStatus.Message
+}
+
+// setNodeCondition sets the condition in the node status. The transition
+// time is updated only if the status of the condition has changed.
+// Returns true if the node has been changed.
+func setNodeCondition(k8sNode *coreV1.Node, condition coreV1.NodeCondition, now metaV1.Time) bool {
+	condition.LastHeartbeatTime = now
+	condition.LastTransitionTime = now
+	for i := range k8sNode.Status.Conditions {
+		existing := &k8sNode.Status.Conditions[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && existing.Reason == condition.Reason &&
+			existing.Message == condition.Message {
+			return false
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return true
+	}
+	k8sNode.Status.Conditions = append(k8sNode.Status.Conditions, condition)
+	return true
+}
+
+// clearNetworkUnavailable resets the NetworkUnavailable condition set by a previous
+// configuration with tainting enabled. Conditions set by others are left untouched.
+// Returns true if the node has been changed.
+func clearNetworkUnavailable(k8sNode *coreV1.Node, now metaV1.Time) bool {
+	for i := range k8sNode.Status.Conditions {
+		existing := &k8sNode.Status.Conditions[i]
+		if existing.Type != coreV1.NodeNetworkUnavailable || existing.Reason != vswitchNotReadyReason ||
+			existing.Status != coreV1.ConditionTrue {
+			continue
+		}
+		return setNodeCondition(k8sNode, coreV1.NodeCondition{
+			Type:    coreV1.NodeNetworkUnavailable,
+			Status:  coreV1.ConditionFalse,
+			Reason:  vswitchReadyReason,
+			Message: "vswitch readiness is not enforced",
+		}, now)
+	}
+	return false
+}
+
+// taintNotReadyNodes returns true if tainting of nodes with not ready vswitch
+// is enabled by the CONTIV_TAINT_NOT_READY_NODES environment variable.
+func taintNotReadyNodes() bool {
+	taint, _ := strconv.ParseBool(os.Getenv(TaintNotReadyNodesEnvVar))
+	return taint
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ksr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	nodeID "github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/ligato/cn-infra/logging"
+)
+
+const testNodeName = "k8s-worker1"
+
+func getNodeCondition(k8sNode *coreV1.Node, condType coreV1.NodeConditionType) *coreV1.NodeCondition {
+	for i := range k8sNode.Status.Conditions {
+		if k8sNode.Status.Conditions[i].Type == condType {
+			return &k8sNode.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func hasVswitchTaint(k8sNode *coreV1.Node) bool {
+	for _, taint := range k8sNode.Spec.Taints {
+		if taint.Key == VswitchNotReadyTaint && taint.Effect == coreV1.TaintEffectNoSchedule {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNodeConditionUpdater(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	k8sNode := &coreV1.Node{
+		ObjectMeta: metaV1.ObjectMeta{Name: testNodeName},
+		Spec: coreV1.NodeSpec{
+			Taints: []coreV1.Taint{{Key: "other-taint", Effect: coreV1.TaintEffectNoExecute}},
+		},
+	}
+	client := fake.NewSimpleClientset(k8sNode)
+
+	updater := &NodeConditionUpdater{
+		Log:                logging.ForPlugin("node-condition-test"),
+		K8sClient:          client,
+		Broker:             newMockKeyProtoValBroker(),
+		TaintNotReadyNodes: true,
+		StatusGracePeriod:  time.Minute,
+		started:            time.Now(),
+	}
+
+	getNode := func() *coreV1.Node {
+		k8sNode, err := client.CoreV1().Nodes().Get(testNodeName, metaV1.GetOptions{})
+		gomega.Expect(err).To(gomega.BeNil())
+		return k8sNode
+	}
+
+	// node without vswitch status is not touched by resync within the grace period
+	updater.resync()
+	k8sNode = getNode()
+	gomega.Expect(k8sNode.Status.Conditions).To(gomega.BeEmpty())
+	gomega.Expect(k8sNode.Spec.Taints).To(gomega.HaveLen(1))
+
+	// vswitch status not reported within the grace period
+	updater.started = time.Now().Add(-2 * time.Minute)
+	updater.resync()
+	k8sNode = getNode()
+	cond := getNodeCondition(k8sNode, VswitchReadyCondition)
+	gomega.Expect(cond).ToNot(gomega.BeNil())
+	gomega.Expect(cond.Status).To(gomega.BeEquivalentTo(coreV1.ConditionFalse))
+	gomega.Expect(cond.Message).To(gomega.BeEquivalentTo(vswitchStatusMissingMsg))
+	cond = getNodeCondition(k8sNode, coreV1.NodeNetworkUnavailable)
+	gomega.Expect(cond).ToNot(gomega.BeNil())
+	gomega.Expect(cond.Status).To(gomega.BeEquivalentTo(coreV1.ConditionTrue))
+	gomega.Expect(hasVswitchTaint(k8sNode)).To(gomega.BeTrue())
+	gomega.Expect(k8sNode.Spec.Taints).To(gomega.HaveLen(2))
+
+	// vswitch status reported as missing
+	err := updater.UpdateNodeCondition(testNodeName, nil)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(hasVswitchTaint(getNode())).To(gomega.BeTrue())
+
+	// VPP connected, but the vswitch is not resynced yet
+	err = updater.UpdateNodeCondition(testNodeName, &nodeID.VswitchStatus{
+		NodeName:     testNodeName,
+		VppConnected: true,
+		AgentOk:      true,
+		Message:      "vswitch configuration not resynced",
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	k8sNode = getNode()
+	cond = getNodeCondition(k8sNode, VswitchReadyCondition)
+	gomega.Expect(cond.Status).To(gomega.BeEquivalentTo(coreV1.ConditionFalse))
+	gomega.Expect(cond.Message).To(gomega.BeEquivalentTo("vswitch configuration not resynced"))
+	gomega.Expect(hasVswitchTaint(k8sNode)).To(gomega.BeTrue())
+	gomega.Expect(k8sNode.Spec.Taints).To(gomega.HaveLen(2))
+
+	// healthy vswitch - condition and taint are cleared
+	err = updater.UpdateNodeCondition(testNodeName, &nodeID.VswitchStatus{
+		NodeName:     testNodeName,
+		VppConnected: true,
+		Resynced:     true,
+		AgentOk:      true,
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	k8sNode = getNode()
+	cond = getNodeCondition(k8sNode, VswitchReadyCondition)
+	gomega.Expect(cond.Status).To(gomega.BeEquivalentTo(coreV1.ConditionTrue))
+	cond = getNodeCondition(k8sNode, coreV1.NodeNetworkUnavailable)
+	gomega.Expect(cond.Status).To(gomega.BeEquivalentTo(coreV1.ConditionFalse))
+	gomega.Expect(hasVswitchTaint(k8sNode)).To(gomega.BeFalse())
+	gomega.Expect(k8sNode.Spec.Taints).To(gomega.HaveLen(1))
+
+	// repeated healthy status does not update the node
+	actions := len(client.Actions())
+	err = updater.UpdateNodeCondition(testNodeName, &nodeID.VswitchStatus{
+		NodeName:     testNodeName,
+		VppConnected: true,
+		Resynced:     true,
+		AgentOk:      true,
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(client.Actions()).To(gomega.HaveLen(actions + 1)) // get only
+
+	// vswitch status expired
+	err = updater.UpdateNodeCondition(testNodeName, nil)
+	gomega.Expect(err).To(gomega.BeNil())
+	k8sNode = getNode()
+	cond = getNodeCondition(k8sNode, VswitchReadyCondition)
+	gomega.Expect(cond.Status).To(gomega.BeEquivalentTo(coreV1.ConditionFalse))
+	gomega.Expect(hasVswitchTaint(k8sNode)).To(gomega.BeTrue())
+}
+
+func TestNodeConditionUpdaterWithoutTaint(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	// NetworkUnavailable condition left over from a configuration with tainting enabled
+	client := fake.NewSimpleClientset(&coreV1.Node{
+		ObjectMeta: metaV1.ObjectMeta{Name: testNodeName},
+		Status: coreV1.NodeStatus{
+			Conditions: []coreV1.NodeCondition{{
+				Type:   coreV1.NodeNetworkUnavailable,
+				Status: coreV1.ConditionTrue,
+				Reason: vswitchNotReadyReason,
+			}},
+		},
+	})
+	broker := newMockKeyProtoValBroker()
+	updater := &NodeConditionUpdater{
+		Log:       logging.ForPlugin("node-condition-test"),
+		K8sClient: client,
+		Broker:    broker,
+	}
+
+	// not ready vswitch reported in the data store
+	err := broker.Put(nodeID.VswitchStatusKey(testNodeName), &nodeID.VswitchStatus{
+		NodeName: testNodeName,
+		Resynced: true,
+		AgentOk:  true,
+		Message:  "not connected to VPP",
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+
+	updater.resync()
+	k8sNode, err := client.CoreV1().Nodes().Get(testNodeName, metaV1.GetOptions{})
+	gomega.Expect(err).To(gomega.BeNil())
+	cond := getNodeCondition(k8sNode, VswitchReadyCondition)
+	gomega.Expect(cond).ToNot(gomega.BeNil())
+	gomega.Expect(cond.Status).To(gomega.BeEquivalentTo(coreV1.ConditionFalse))
+	gomega.Expect(cond.Message).To(gomega.BeEquivalentTo("not connected to VPP"))
+	gomega.Expect(hasVswitchTaint(k8sNode)).To(gomega.BeFalse())
+
+	// network is not reported as unavailable without tainting
+	cond = getNodeCondition(k8sNode, coreV1.NodeNetworkUnavailable)
+	gomega.Expect(cond).ToNot(gomega.BeNil())
+	gomega.Expect(cond.Status).To(gomega.BeEquivalentTo(coreV1.ConditionFalse))
+}
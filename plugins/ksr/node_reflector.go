@@ -16,6 +16,7 @@ package ksr
 
 import (
 	"reflect"
+	"sort"
 	"sync"
 
 	coreV1 "k8s.io/api/core/v1"
@@ -134,10 +135,27 @@ func (nr *NodeReflector) nodeToProto(k8sNode *coreV1.Node) *node.Node {
 	nodeProto.Provider_ID = k8sNode.Spec.ProviderID
 	nodeProto.Addresses = getNodeAddresses(k8sNode.Status.Addresses)
 	nodeProto.NodeInfo = getNodeInfo(k8sNode.Status.NodeInfo)
+	nodeProto.Label = getNodeLabels(k8sNode.GetLabels())
 
 	return nodeProto
 }
 
+// getNodeLabels converts node labels from the k8s representation into
+// the corresponding contiv protobuf-modelled data format. Labels are sorted
+// by keys, so that unchanged labels always result in equal protobuf messages.
+func getNodeLabels(k8sLabels map[string]string) []*node.Node_Label {
+	var protoLabels []*node.Node_Label
+	keys := make([]string, 0, len(k8sLabels))
+	for key := range k8sLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		protoLabels = append(protoLabels, &node.Node_Label{Key: key, Value: k8sLabels[key]})
+	}
+	return protoLabels
+}
+
 // getNodeAddresses converts node addresses from the k8s representation
 // into the corresponding contiv protobuf-modelled data format.
 func getNodeAddresses(k8sAddrs []coreV1.NodeAddress) []*node.NodeAddress {
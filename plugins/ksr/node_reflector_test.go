@@ -68,6 +68,7 @@ func TestNodeReflector(t *testing.T) {
 			ObjectMeta: metaV1.ObjectMeta{
 				Name:            "test-node-1",
 				Namespace:       "default",
+				Labels:          map[string]string{"role": "master", "zone": "a"},
 				SelfLink:        "/apis/extensions/v1beta1/namespaces/default/nodes/test-node-1",
 				UID:             "44a9312f-f99f-11e7-b9b5-0800271d72be",
 				ResourceVersion: "692693",
@@ -356,6 +357,14 @@ func checkNodeToProtoTranslation(t *testing.T, protoNode *node.Node, k8sNode *co
 	gomega.Expect(protoNode.NodeInfo.OperatingSystem).To(gomega.Equal(k8sNode.Status.NodeInfo.OperatingSystem))
 	gomega.Expect(protoNode.NodeInfo.OsImage).To(gomega.Equal(k8sNode.Status.NodeInfo.OSImage))
 
+	gomega.Expect(protoNode.Label).To(gomega.HaveLen(len(k8sNode.GetLabels())))
+	for i, label := range protoNode.Label {
+		gomega.Expect(label.Value).To(gomega.Equal(k8sNode.GetLabels()[label.Key]))
+		if i > 0 {
+			gomega.Expect(label.Key > protoNode.Label[i-1].Key).To(gomega.BeTrue())
+		}
+	}
+
 	for i, addr := range protoNode.Addresses {
 		switch addr.Type {
 		case node.NodeAddress_NodeHostName:
@@ -29,6 +29,19 @@ const (
 	// MicroserviceLabel is the microservice label used by contiv-ksr.
 	MicroserviceLabel = "contiv-ksr"
 
+	// ClusterIDEnvVar is the name of the environment variable defining the ID of the Contiv cluster.
+	// If set, all components of the cluster store their data in etcd under the subtree of the cluster.
+	ClusterIDEnvVar = "CONTIV_CLUSTER_ID"
+
+	// TaintNotReadyNodesEnvVar is the name of the environment variable which, if set to true,
+	// enables the NoSchedule taint for nodes where contiv-vswitch is not ready.
+	TaintNotReadyNodesEnvVar = "CONTIV_TAINT_NOT_READY_NODES"
+
+	// LoadBalancerIPPoolEnvVar is the name of the environment variable defining
+	// a comma-separated list of subnets to allocate IP addresses of services
+	// of type LoadBalancer from. The allocation is disabled if not set.
+	LoadBalancerIPPoolEnvVar = "CONTIV_LOADBALANCER_IP_POOL"
+
 	// KubeConfigAdmin is the default location of kubeconfig with admin credentials.
 	KubeConfigAdmin = "/etc/kubernetes/admin.conf"
 
@@ -64,6 +64,10 @@ type Plugin struct {
 
 	reflectorRegistry *ReflectorRegistry
 
+	nodeConditionUpdater *NodeConditionUpdater
+	policyStatusUpdater  *PolicyStatusUpdater
+	lbIPAllocator        *LoadBalancerIPAllocator
+
 	StatusMonitor  statuscheck.StatusReader
 	etcdMonitor    EtcdMonitor
 	StatsCollector StatsCollector
@@ -201,6 +205,45 @@ func (plugin *Plugin) Init() error {
 		return err
 	}
 
+	plugin.nodeConditionUpdater = &NodeConditionUpdater{
+		Log:                plugin.Log.NewLogger("-node-condition"),
+		K8sClient:          plugin.k8sClientset,
+		Broker:             broker,
+		Watcher:            plugin.Publish.Deps.KvPlugin.NewWatcher(ksrPrefix),
+		TaintNotReadyNodes: taintNotReadyNodes(),
+	}
+	err = plugin.nodeConditionUpdater.Init()
+	if err != nil {
+		plugin.Log.WithField("rwErr", err).Error("Failed to initialize Node condition updater")
+		return err
+	}
+
+	plugin.policyStatusUpdater = &PolicyStatusUpdater{
+		Log:       plugin.Log.NewLogger("-policy-status"),
+		K8sClient: plugin.k8sClientset,
+		Broker:    broker,
+		Watcher:   plugin.Publish.Deps.KvPlugin.NewWatcher(ksrPrefix),
+	}
+	err = plugin.policyStatusUpdater.Init()
+	if err != nil {
+		plugin.Log.WithField("rwErr", err).Error("Failed to initialize Policy status updater")
+		return err
+	}
+
+	lbIPAllocatorLog := plugin.Log.NewLogger("-lb-ip-allocator")
+	plugin.lbIPAllocator = &LoadBalancerIPAllocator{
+		Log:       lbIPAllocatorLog,
+		K8sClient: plugin.k8sClientset,
+		Pool:      loadBalancerIPPool(lbIPAllocatorLog),
+		Broker:    broker,
+		Watcher:   plugin.Publish.Deps.KvPlugin.NewWatcher(ksrPrefix),
+	}
+	err = plugin.lbIPAllocator.Init()
+	if err != nil {
+		plugin.Log.WithField("rwErr", err).Error("Failed to initialize Load-balancer IP allocator")
+		return err
+	}
+
 	plugin.StatsCollector.Log = plugin.Log.NewLogger("-metrics")
 	plugin.StatsCollector.serviceLabel = plugin.Publish.ServiceLabel.GetAgentLabel()
 	plugin.StatsCollector.Prometheus = plugin.Prometheus
@@ -223,6 +266,9 @@ func (plugin *Plugin) Init() error {
 func (plugin *Plugin) AfterInit() error {
 	plugin.reflectorRegistry.startReflectors()
 	plugin.StatsCollector.start(plugin.stopCh, plugin.reflectorRegistry)
+	plugin.nodeConditionUpdater.Start(plugin.stopCh, &plugin.wg)
+	plugin.policyStatusUpdater.Start(plugin.stopCh, &plugin.wg)
+	plugin.lbIPAllocator.Start(plugin.stopCh, &plugin.wg)
 
 	go plugin.monitorEtcdStatus(plugin.stopCh)
 
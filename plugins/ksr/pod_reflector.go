@@ -26,6 +26,10 @@ import (
 	"github.com/contiv/vpp/plugins/ksr/model/pod"
 )
 
+// protocolSCTP is the K8s name of the SCTP protocol (not defined
+// by the vendored version of the K8s API).
+const protocolSCTP coreV1.Protocol = "SCTP"
+
 // PodReflector subscribes to K8s cluster to watch for changes in the
 // configuration of k8s pods. Protobuf-modelled changes are published
 // into the selected key-value store.
@@ -129,6 +133,10 @@ func (pr *PodReflector) podToProto(k8sPod *coreV1.Pod) *pod.Pod {
 		}
 	}
 	podProto.IpAddress = k8sPod.Status.PodIP
+	if podProto.IpAddress != "" {
+		// The K8s API in use reports only the primary pod IP.
+		podProto.IpAddresses = []string{podProto.IpAddress}
+	}
 	podProto.HostIpAddress = k8sPod.Status.HostIP
 	for _, container := range k8sPod.Spec.Containers {
 		podProto.Container = append(podProto.Container, pr.containerToProto(&container))
@@ -152,6 +160,8 @@ func (pr *PodReflector) containerToProto(container *coreV1.Container) *pod.Pod_C
 			portProto.Protocol = pod.Pod_Container_Port_TCP
 		case coreV1.ProtocolUDP:
 			portProto.Protocol = pod.Pod_Container_Port_UDP
+		case protocolSCTP:
+			portProto.Protocol = pod.Pod_Container_Port_SCTP
 		}
 		portProto.HostIpAddress = port.HostIP
 		containerProto.Port = append(containerProto.Port, portProto)
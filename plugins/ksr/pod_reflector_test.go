@@ -347,6 +347,7 @@ func testAddDeletePod(t *testing.T) {
 
 	gomega.Expect(protoPod.HostIpAddress).To(gomega.Equal(k8sPod.Status.HostIP))
 	gomega.Expect(protoPod.IpAddress).To(gomega.Equal(k8sPod.Status.PodIP))
+	gomega.Expect(protoPod.IpAddresses).To(gomega.Equal([]string{k8sPod.Status.PodIP}))
 
 	gomega.Expect(protoPod.Container[0].Name).To(gomega.Equal(k8sPod.Spec.Containers[0].Name))
 	gomega.Expect(protoPod.Container[0].Port[0].Name).
@@ -15,8 +15,11 @@
 package ksr
 
 import (
+	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/golang/protobuf/proto"
@@ -32,6 +35,13 @@ import (
 	"github.com/contiv/vpp/plugins/ksr/model/policy"
 )
 
+// EndPortsAnnotation extends numbered ports of a K8s network policy into port
+// ranges, which cannot be expressed with the extensions/v1beta1 API. The value
+// is a comma-separated list of "<port>-<endPort>" ranges, every numbered port
+// of the policy equal to <port> is reflected with end_port set to <endPort>
+// (e.g. "10000-20000" turns all rule ports 10000 into the range 10000-20000).
+const EndPortsAnnotation = "contiv.vpp/policy-end-ports"
+
 // PolicyReflector subscribes to K8s cluster to watch for changes
 // in the configuration of k8s network policies.
 // Protobuf-modelled changes are published into the selected key-value store.
@@ -153,6 +163,17 @@ func (pr *PolicyReflector) policyToProto(k8sPolicy *coreV1Beta1.NetworkPolicy) *
 	// Pods
 	policyProto.Pods = pr.labelSelectorToProto(&k8sPolicy.Spec.PodSelector)
 
+	// Port ranges
+	var endPorts map[int32]int32
+	if value, hasAnnotation := k8sPolicy.GetAnnotations()[EndPortsAnnotation]; hasAnnotation {
+		var err error
+		endPorts, err = parseEndPorts(value)
+		if err != nil {
+			pr.Log.WithField("policy", k8sPolicy.GetName()).Warnf("Ignoring invalid %s annotation: %v",
+				EndPortsAnnotation, err)
+		}
+	}
+
 	// PolicyType
 	ingress := 0
 	egress := 0
@@ -180,7 +201,7 @@ func (pr *PolicyReflector) policyToProto(k8sPolicy *coreV1Beta1.NetworkPolicy) *
 			ingressProto := &policy.Policy_IngressRule{}
 			// Ports
 			if ingress.Ports != nil {
-				ingressProto.Port = pr.portsToProto(ingress.Ports)
+				ingressProto.Port = pr.portsToProto(ingress.Ports, endPorts)
 			}
 			// From
 			if ingress.From != nil {
@@ -197,7 +218,7 @@ func (pr *PolicyReflector) policyToProto(k8sPolicy *coreV1Beta1.NetworkPolicy) *
 			egressProto := &policy.Policy_EgressRule{}
 			// Ports
 			if egress.Ports != nil {
-				egressProto.Port = pr.portsToProto(egress.Ports)
+				egressProto.Port = pr.portsToProto(egress.Ports, endPorts)
 			}
 			// From
 			if egress.To != nil {
@@ -259,8 +280,10 @@ func (pr *PolicyReflector) labelSelectorToProto(selector *clientApiMetaV1.LabelS
 }
 
 // portsToProto converts a list of ports from the k8s representation into
-// our protobuf-modelled data structure.
-func (pr *PolicyReflector) portsToProto(ports []coreV1Beta1.NetworkPolicyPort) (portsProto []*policy.Policy_Port) {
+// our protobuf-modelled data structure. Numbered ports found in <endPorts>
+// are extended into port ranges.
+func (pr *PolicyReflector) portsToProto(ports []coreV1Beta1.NetworkPolicyPort,
+	endPorts map[int32]int32) (portsProto []*policy.Policy_Port) {
 	for _, port := range ports {
 		portProto := &policy.Policy_Port{}
 		// Protocol
@@ -270,6 +293,8 @@ func (pr *PolicyReflector) portsToProto(ports []coreV1Beta1.NetworkPolicyPort) (
 				portProto.Protocol = policy.Policy_Port_TCP
 			case coreV1.ProtocolUDP:
 				portProto.Protocol = policy.Policy_Port_UDP
+			case protocolSCTP:
+				portProto.Protocol = policy.Policy_Port_SCTP
 			}
 		}
 		// Port number/name
@@ -279,6 +304,7 @@ func (pr *PolicyReflector) portsToProto(ports []coreV1Beta1.NetworkPolicyPort) (
 			case intstr.Int:
 				portProto.Port.Type = policy.Policy_Port_PortNameOrNumber_NUMBER
 				portProto.Port.Number = port.Port.IntVal
+				portProto.EndPort = endPorts[port.Port.IntVal]
 			case intstr.String:
 				portProto.Port.Type = policy.Policy_Port_PortNameOrNumber_NAME
 				portProto.Port.Name = port.Port.StrVal
@@ -290,6 +316,31 @@ func (pr *PolicyReflector) portsToProto(ports []coreV1Beta1.NetworkPolicyPort) (
 	return portsProto
 }
 
+// parseEndPorts parses the value of EndPortsAnnotation into a map port -> end port.
+func parseEndPorts(value string) (map[int32]int32, error) {
+	endPorts := make(map[int32]int32)
+	for _, portRange := range strings.Split(value, ",") {
+		portRange = strings.TrimSpace(portRange)
+		if portRange == "" {
+			continue
+		}
+		bounds := strings.Split(portRange, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid port range '%s'", portRange)
+		}
+		port, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 16)
+		if err != nil || port == 0 {
+			return nil, fmt.Errorf("invalid port in the range '%s'", portRange)
+		}
+		endPort, err := strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 16)
+		if err != nil || endPort < port {
+			return nil, fmt.Errorf("invalid end port in the range '%s'", portRange)
+		}
+		endPorts[int32(port)] = int32(endPort)
+	}
+	return endPorts, nil
+}
+
 // peersToProto converts a list of peers from the k8s representation into
 // our protobuf-modelled data structure.
 func (pr *PolicyReflector) peersToProto(peers []coreV1Beta1.NetworkPolicyPeer) (peersProto []*policy.Policy_Peer) {
@@ -70,6 +70,7 @@ func TestPolicyReflector(t *testing.T) {
 	}
 
 	var pprotTCP coreV1.Protocol = "TCP"
+	var pprotSCTP coreV1.Protocol = "SCTP"
 
 	policyTestVars.policyTestData = []coreV1Beta1.NetworkPolicy{
 		// Test data 0: mocks a new object to be added or a "pre-existing"
@@ -136,6 +137,13 @@ func TestPolicyReflector(t *testing.T) {
 									IntVal: 5978,
 								},
 							},
+							{
+								Protocol: &pprotSCTP,
+								Port: &intstr.IntOrString{
+									Type:   intstr.Int,
+									IntVal: 3868,
+								},
+							},
 						},
 						To: []coreV1Beta1.NetworkPolicyPeer{
 							{
@@ -896,6 +904,50 @@ func testResyncPolicyTransientDsError(t *testing.T) {
 
 // checkPolicyToProtoTranslation checks whether the translation of K8s policy
 // into the Contiv-VPP protobuf format is correct.
+func TestPolicyEndPortsAnnotation(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	pr := &PolicyReflector{
+		Reflector: Reflector{
+			Log: logging.ForPlugin("policy-reflector"),
+		},
+	}
+	var pprotUDP coreV1.Protocol = "UDP"
+	numberedPort := func(port int32) coreV1Beta1.NetworkPolicyPort {
+		return coreV1Beta1.NetworkPolicyPort{
+			Protocol: &pprotUDP,
+			Port:     &intstr.IntOrString{Type: intstr.Int, IntVal: port},
+		}
+	}
+	k8sPolicy := &coreV1Beta1.NetworkPolicy{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:        "rtp",
+			Namespace:   "default",
+			Annotations: map[string]string{EndPortsAnnotation: "10000-20000, 30000-30100"},
+		},
+		Spec: coreV1Beta1.NetworkPolicySpec{
+			Ingress: []coreV1Beta1.NetworkPolicyIngressRule{
+				{Ports: []coreV1Beta1.NetworkPolicyPort{numberedPort(10000), numberedPort(5060)}},
+			},
+			Egress: []coreV1Beta1.NetworkPolicyEgressRule{
+				{Ports: []coreV1Beta1.NetworkPolicyPort{numberedPort(30000)}},
+			},
+		},
+	}
+
+	protoPolicy := pr.policyToProto(k8sPolicy)
+	gomega.Expect(protoPolicy.IngressRule[0].Port[0].EndPort).To(gomega.BeEquivalentTo(20000))
+	gomega.Expect(protoPolicy.IngressRule[0].Port[1].EndPort).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(protoPolicy.EgressRule[0].Port[0].EndPort).To(gomega.BeEquivalentTo(30100))
+
+	// Invalid annotation is ignored.
+	for _, invalid := range []string{"10000", "10000-9000", "0-100", "10000-70000", "a-b"} {
+		k8sPolicy.Annotations[EndPortsAnnotation] = invalid
+		protoPolicy = pr.policyToProto(k8sPolicy)
+		gomega.Expect(protoPolicy.IngressRule[0].Port[0].EndPort).To(gomega.BeEquivalentTo(0))
+	}
+}
+
 func checkPolicyToProtoTranslation(t *testing.T, protoNp *policy.Policy, k8sNp *coreV1Beta1.NetworkPolicy) {
 
 	gomega.Expect(protoNp.Name).To(gomega.Equal(k8sNp.GetName()))
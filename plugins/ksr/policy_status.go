@@ -0,0 +1,303 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ksr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	clusterpolicyv1 "github.com/contiv/vpp/plugins/crd/pkg/apis/clusterpolicy/v1"
+	policystatus "github.com/contiv/vpp/plugins/policy/model/status"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/logging"
+)
+
+const (
+	// PolicyEnforcedReason is the reason of the (Normal) event reported for policies
+	// rendered successfully on all nodes where they are applied.
+	PolicyEnforcedReason = "PolicyEnforced"
+
+	// PolicyRenderingFailedReason is the reason of the (Warning) event reported
+	// for policies which failed to render on at least one node.
+	PolicyRenderingFailedReason = "PolicyRenderingFailed"
+
+	// policyEventComponent is the source component of the policy events.
+	policyEventComponent = "contiv-ksr"
+
+	// maxReportedFailures is the maximum number of node failures listed in one event.
+	maxReportedFailures = 3
+
+	// policyStatusResyncPeriod is the period in which the status of all policies
+	// is re-evaluated, in case some event has failed to be reported.
+	policyStatusResyncPeriod = 1 * time.Minute
+)
+
+// PolicyStatusUpdater watches the policy status published by contiv-agents,
+// aggregates it across all nodes and reports it as K8s Events on the policies:
+// Warning event PolicyRenderingFailed if the policy failed to render on some
+// node, Normal event PolicyEnforced with the number of nodes, selected pods
+// and generated rules otherwise. An event is reported only when the aggregated
+// status of the policy changes.
+type PolicyStatusUpdater struct {
+	Log       logging.Logger
+	K8sClient kubernetes.Interface
+
+	// Broker and Watcher are used to access the policy status data in the data store
+	// (they are expected to be prefixed with the KSR prefix).
+	Broker  KeyProtoValBroker
+	Watcher keyval.ProtoWatcher
+
+	watchCh    chan keyval.ProtoWatchResp
+	nodeStatus map[string]*policystatus.NodePolicyStatus // node name -> status
+	reported   map[policyRef]policyEvent                 // last reported event of every policy
+}
+
+// policyRef identifies a policy whose status is reported.
+type policyRef struct {
+	kind      policystatus.PolicyStatus_Kind
+	namespace string
+	name      string
+}
+
+// policyEvent is the content of an event reported for a policy.
+type policyEvent struct {
+	eventType string
+	reason    string
+	message   string
+}
+
+// policyAggregate is the status of a policy aggregated across all nodes.
+type policyAggregate struct {
+	nodes    int
+	pods     uint32
+	rules    uint32
+	failures []string // "<node>: <error>"
+}
+
+// Init subscribes to the changes of the policy status. The changes are not
+// processed until Start() is called.
+func (u *PolicyStatusUpdater) Init() error {
+	u.nodeStatus = make(map[string]*policystatus.NodePolicyStatus)
+	u.reported = make(map[policyRef]policyEvent)
+	u.watchCh = make(chan keyval.ProtoWatchResp, 100)
+	return u.Watcher.Watch(keyval.ToChanProto(u.watchCh), nil, policystatus.KeyPrefix)
+}
+
+// Start starts the go routine reporting the policy status.
+func (u *PolicyStatusUpdater) Start(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		u.resync()
+		ticker := time.NewTicker(policyStatusResyncPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				u.resync()
+			case resp := <-u.watchCh:
+				u.processStatusChange(resp)
+			}
+		}
+	}()
+}
+
+// resync reloads the policy status of all nodes from the data store and reports
+// the policies whose aggregated status has changed.
+func (u *PolicyStatusUpdater) resync() {
+	it, err := u.Broker.ListValues(policystatus.KeyPrefix)
+	if err != nil {
+		u.Log.Warnf("Failed to list policy status: %v", err)
+		return
+	}
+	nodeStatus := make(map[string]*policystatus.NodePolicyStatus)
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		status := &policystatus.NodePolicyStatus{}
+		if err := kv.GetValue(status); err != nil {
+			u.Log.Warnf("Failed to read policy status %s: %v", kv.GetKey(), err)
+			continue
+		}
+		nodeStatus[status.NodeName] = status
+	}
+	u.nodeStatus = nodeStatus
+	u.reportStatus()
+}
+
+// processStatusChange updates the policy status of the node whose status has changed.
+func (u *PolicyStatusUpdater) processStatusChange(resp keyval.ProtoWatchResp) {
+	key := resp.GetKey()
+	idx := strings.LastIndex(key, policystatus.KeyPrefix)
+	if idx == -1 {
+		u.Log.Warnf("Unexpected policy status key: %s", key)
+		return
+	}
+	nodeName := key[idx+len(policystatus.KeyPrefix):]
+
+	var status *policystatus.NodePolicyStatus
+	if resp.GetChangeType() == datasync.Put {
+		status = &policystatus.NodePolicyStatus{}
+		if err := resp.GetValue(status); err != nil {
+			u.Log.Warnf("Failed to read policy status %s: %v", key, err)
+			return
+		}
+	}
+	u.UpdateNodeStatus(nodeName, status)
+}
+
+// UpdateNodeStatus updates the policy status of the given node and reports
+// the policies whose aggregated status has changed. Nil status means that
+// the status of the node is not reported (or has expired).
+func (u *PolicyStatusUpdater) UpdateNodeStatus(nodeName string, status *policystatus.NodePolicyStatus) {
+	if status == nil {
+		delete(u.nodeStatus, nodeName)
+	} else {
+		u.nodeStatus[nodeName] = status
+	}
+	u.reportStatus()
+}
+
+// reportStatus aggregates the policy status across all nodes and reports
+// an event for every policy whose aggregated status has changed. Policies
+// no longer rendered on any node are forgotten without an event.
+func (u *PolicyStatusUpdater) reportStatus() {
+	nodes := make([]string, 0, len(u.nodeStatus))
+	for nodeName := range u.nodeStatus {
+		nodes = append(nodes, nodeName)
+	}
+	sort.Strings(nodes)
+
+	aggregates := make(map[policyRef]*policyAggregate)
+	for _, nodeName := range nodes {
+		for _, status := range u.nodeStatus[nodeName].Policies {
+			ref := policyRef{kind: status.Kind, namespace: status.Namespace, name: status.Name}
+			aggregate, hasAggregate := aggregates[ref]
+			if !hasAggregate {
+				aggregate = &policyAggregate{}
+				aggregates[ref] = aggregate
+			}
+			aggregate.nodes++
+			aggregate.pods += status.SelectedPods
+			aggregate.rules += status.Rules
+			if status.Error != "" {
+				aggregate.failures = append(aggregate.failures, nodeName+": "+status.Error)
+			}
+		}
+	}
+
+	for ref := range u.reported {
+		if _, hasAggregate := aggregates[ref]; !hasAggregate {
+			delete(u.reported, ref)
+		}
+	}
+	for ref, aggregate := range aggregates {
+		event := aggregate.event()
+		if reported, wasReported := u.reported[ref]; wasReported && reported == event {
+			continue
+		}
+		if err := u.recordEvent(ref, event); err != nil {
+			u.Log.Warnf("Failed to report status of the policy %s/%s: %v", ref.namespace, ref.name, err)
+			continue
+		}
+		u.reported[ref] = event
+	}
+}
+
+// event returns the event describing the aggregated status of a policy.
+func (a *policyAggregate) event() policyEvent {
+	if len(a.failures) == 0 {
+		return policyEvent{
+			eventType: coreV1.EventTypeNormal,
+			reason:    PolicyEnforcedReason,
+			message: fmt.Sprintf("enforced on %d node(s): %d selected pod(s), %d rule(s)",
+				a.nodes, a.pods, a.rules),
+		}
+	}
+	failures := a.failures
+	if len(failures) > maxReportedFailures {
+		failures = append(failures[:maxReportedFailures:maxReportedFailures],
+			fmt.Sprintf("and %d more", len(a.failures)-maxReportedFailures))
+	}
+	return policyEvent{
+		eventType: coreV1.EventTypeWarning,
+		reason:    PolicyRenderingFailedReason,
+		message: fmt.Sprintf("rendering failed on %d of %d node(s): %s",
+			len(a.failures), a.nodes, strings.Join(failures, "; ")),
+	}
+}
+
+// recordEvent creates K8s event for the given policy. Events of network policies
+// are created in the namespace of the policy, events of (cluster-scoped) cluster
+// policies in the default namespace.
+func (u *PolicyStatusUpdater) recordEvent(ref policyRef, event policyEvent) error {
+	namespace := ref.namespace
+	involvedObject := coreV1.ObjectReference{Name: ref.name, Namespace: ref.namespace}
+	switch ref.kind {
+	case policystatus.PolicyStatus_NETWORK_POLICY:
+		policy, err := u.K8sClient.NetworkingV1().NetworkPolicies(ref.namespace).Get(ref.name, metaV1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			// policy removed, agents will stop reporting it shortly
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		involvedObject.Kind = "NetworkPolicy"
+		involvedObject.APIVersion = "networking.k8s.io/v1"
+		involvedObject.UID = policy.UID
+	case policystatus.PolicyStatus_CLUSTER_POLICY:
+		namespace = metaV1.NamespaceDefault
+		involvedObject.Kind = "ClusterPolicy"
+		involvedObject.APIVersion = clusterpolicyv1.SchemeGroupVersion.String()
+	}
+
+	u.Log.WithFields(logging.Fields{
+		"kind":      involvedObject.Kind,
+		"namespace": ref.namespace,
+		"name":      ref.name,
+		"reason":    event.reason,
+		"message":   event.message,
+	}).Info("Reporting policy status")
+	now := metaV1.Now()
+	_, err := u.K8sClient.CoreV1().Events(namespace).Create(&coreV1.Event{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", ref.name, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: involvedObject,
+		Reason:         event.reason,
+		Message:        event.message,
+		Type:           event.eventType,
+		Source:         coreV1.EventSource{Component: policyEventComponent},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	})
+	return err
+}
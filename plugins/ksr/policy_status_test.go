@@ -0,0 +1,145 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ksr
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	coreV1 "k8s.io/api/core/v1"
+	networkingV1 "k8s.io/api/networking/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	policystatus "github.com/contiv/vpp/plugins/policy/model/status"
+	"github.com/ligato/cn-infra/logging"
+)
+
+const (
+	testNode2Name     = "k8s-worker2"
+	testPolicyName    = "allow-web"
+	testPolicyNs      = "default"
+	testPolicyUID     = "0123-4567"
+	testClusterPolicy = "deny-db"
+)
+
+func TestPolicyStatusUpdater(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	client := fake.NewSimpleClientset(&networkingV1.NetworkPolicy{
+		ObjectMeta: metaV1.ObjectMeta{Name: testPolicyName, Namespace: testPolicyNs, UID: types.UID(testPolicyUID)},
+	})
+	broker := newMockKeyProtoValBroker()
+	updater := &PolicyStatusUpdater{
+		Log:       logging.ForPlugin("policy-status-test"),
+		K8sClient: client,
+		Broker:    broker,
+	}
+	updater.nodeStatus = make(map[string]*policystatus.NodePolicyStatus)
+	updater.reported = make(map[policyRef]policyEvent)
+
+	listEvents := func(namespace string) []coreV1.Event {
+		events, err := client.CoreV1().Events(namespace).List(metaV1.ListOptions{})
+		gomega.Expect(err).To(gomega.BeNil())
+		return events.Items
+	}
+
+	// policy status of the first node reported in the data store
+	err := broker.Put(policystatus.Key(testNodeName), &policystatus.NodePolicyStatus{
+		NodeName: testNodeName,
+		Policies: []*policystatus.PolicyStatus{
+			{
+				Kind:         policystatus.PolicyStatus_NETWORK_POLICY,
+				Name:         testPolicyName,
+				Namespace:    testPolicyNs,
+				SelectedPods: 2,
+				Rules:        4,
+			},
+			{
+				Kind:         policystatus.PolicyStatus_CLUSTER_POLICY,
+				Name:         testClusterPolicy,
+				SelectedPods: 1,
+				Rules:        1,
+			},
+		},
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	updater.resync()
+
+	events := listEvents(testPolicyNs)
+	gomega.Expect(events).To(gomega.HaveLen(2))
+	var policyEvent, clusterPolicyEvent *coreV1.Event
+	for i := range events {
+		switch events[i].InvolvedObject.Name {
+		case testPolicyName:
+			policyEvent = &events[i]
+		case testClusterPolicy:
+			clusterPolicyEvent = &events[i]
+		}
+	}
+	gomega.Expect(policyEvent).ToNot(gomega.BeNil())
+	gomega.Expect(policyEvent.InvolvedObject.Kind).To(gomega.Equal("NetworkPolicy"))
+	gomega.Expect(policyEvent.InvolvedObject.Namespace).To(gomega.Equal(testPolicyNs))
+	gomega.Expect(policyEvent.InvolvedObject.UID).To(gomega.BeEquivalentTo(testPolicyUID))
+	gomega.Expect(policyEvent.Type).To(gomega.Equal(coreV1.EventTypeNormal))
+	gomega.Expect(policyEvent.Reason).To(gomega.Equal(PolicyEnforcedReason))
+	gomega.Expect(policyEvent.Message).To(gomega.Equal("enforced on 1 node(s): 2 selected pod(s), 4 rule(s)"))
+	gomega.Expect(clusterPolicyEvent).ToNot(gomega.BeNil())
+	gomega.Expect(clusterPolicyEvent.InvolvedObject.Kind).To(gomega.Equal("ClusterPolicy"))
+	gomega.Expect(clusterPolicyEvent.InvolvedObject.Namespace).To(gomega.BeEmpty())
+
+	// repeated resync does not report unchanged status
+	updater.resync()
+	gomega.Expect(listEvents(testPolicyNs)).To(gomega.HaveLen(2))
+
+	// the policy failed to render on the second node
+	updater.UpdateNodeStatus(testNode2Name, &policystatus.NodePolicyStatus{
+		NodeName: testNode2Name,
+		Policies: []*policystatus.PolicyStatus{
+			{
+				Kind:         policystatus.PolicyStatus_NETWORK_POLICY,
+				Name:         testPolicyName,
+				Namespace:    testPolicyNs,
+				Error:        "failed to commit ACLs",
+				SelectedPods: 1,
+				Rules:        2,
+			},
+		},
+	})
+	events = listEvents(testPolicyNs)
+	gomega.Expect(events).To(gomega.HaveLen(3))
+	var failedEvent *coreV1.Event
+	for i := range events {
+		if events[i].Reason == PolicyRenderingFailedReason {
+			failedEvent = &events[i]
+		}
+	}
+	gomega.Expect(failedEvent).ToNot(gomega.BeNil())
+	gomega.Expect(failedEvent.Type).To(gomega.Equal(coreV1.EventTypeWarning))
+	gomega.Expect(failedEvent.InvolvedObject.Name).To(gomega.Equal(testPolicyName))
+	gomega.Expect(failedEvent.Message).To(gomega.Equal(
+		"rendering failed on 1 of 2 node(s): k8s-worker2: failed to commit ACLs"))
+
+	// status of the second node expired - the policy is enforced on the first node only
+	updater.UpdateNodeStatus(testNode2Name, nil)
+	gomega.Expect(listEvents(testPolicyNs)).To(gomega.HaveLen(4))
+
+	// policies are no longer rendered - forgotten without an event
+	updater.UpdateNodeStatus(testNodeName, &policystatus.NodePolicyStatus{NodeName: testNodeName})
+	gomega.Expect(listEvents(testPolicyNs)).To(gomega.HaveLen(4))
+	gomega.Expect(updater.reported).To(gomega.BeEmpty())
+}
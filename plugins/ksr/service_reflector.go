@@ -157,10 +157,20 @@ loop:
 	svcProto.ServiceType = string(svc.Spec.Type)
 	svcProto.ExternalIps = svc.Spec.ExternalIPs
 	svcProto.SessionAffinity = string(svc.Spec.SessionAffinity)
+	if svc.Spec.SessionAffinityConfig != nil && svc.Spec.SessionAffinityConfig.ClientIP != nil &&
+		svc.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds != nil {
+		svcProto.SessionAffinityTimeout = *svc.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds
+	}
 	svcProto.LoadbalancerIp = svc.Spec.LoadBalancerIP
 	svcProto.LoadbalancerSourceRanges = svc.Spec.LoadBalancerSourceRanges
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			svcProto.LoadbalancerIngressIps = append(svcProto.LoadbalancerIngressIps, ingress.IP)
+		}
+	}
 	svcProto.ExternalTrafficPolicy = string(svc.Spec.ExternalTrafficPolicy)
 	svcProto.HealthCheckNodePort = svc.Spec.HealthCheckNodePort
+	svcProto.Annotations = svc.GetAnnotations()
 
 	return svcProto
 }
@@ -43,6 +43,7 @@ var serviceTestVars ServiceTestVars
 
 func TestServiceReflector(t *testing.T) {
 	gomega.RegisterTestingT(t)
+	affinityTimeout := int32(3600)
 
 	serviceTestVars.k8sListWatch = &mockK8sListWatch{}
 	serviceTestVars.mockKvBroker = newMockKeyProtoValBroker()
@@ -91,9 +92,13 @@ func TestServiceReflector(t *testing.T) {
 						},
 					},
 				},
-				Selector:  map[string]string{},
-				ClusterIP: "10.96.0.1",
-				Type:      "ClusterIP",
+				Selector:        map[string]string{},
+				ClusterIP:       "10.96.0.1",
+				Type:            "ClusterIP",
+				SessionAffinity: coreV1.ServiceAffinityClientIP,
+				SessionAffinityConfig: &coreV1.SessionAffinityConfig{
+					ClientIP: &coreV1.ClientIPConfig{TimeoutSeconds: &affinityTimeout},
+				},
 			},
 		},
 		// Test data 1: mocks an object that updates a "pre-existing" object
@@ -296,6 +301,16 @@ func testUpdateService(t *testing.T) {
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(svcProto.ClusterIp).To(gomega.Equal(svcNew.Spec.ClusterIP))
 
+	// Test update of the load-balancer status
+	svcLB := svcNew
+	svcLB.Status.LoadBalancer.Ingress = []coreV1.LoadBalancerIngress{{IP: "20.0.0.1"}, {Hostname: "lb.example.com"}}
+	serviceTestVars.k8sListWatch.Update(&svcNew, &svcLB)
+	gomega.Expect(upd + 2).To(gomega.Equal(serviceTestVars.svcReflector.GetStats().Updates))
+
+	svcProto = &service.Service{}
+	_, _, err = serviceTestVars.mockKvBroker.GetValue(service.Key(svcLB.GetName(), svcLB.GetNamespace()), svcProto)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(svcProto.LoadbalancerIngressIps).To(gomega.Equal([]string{"20.0.0.1"}))
 }
 
 func testAddDeleteService(t *testing.T) {
@@ -328,6 +343,8 @@ func testAddDeleteService(t *testing.T) {
 	gomega.Expect(len(svcProto.Selector)).To(gomega.Equal(len(svc.Spec.Selector)))
 	gomega.Expect(svcProto.ServiceType).Should(gomega.BeEquivalentTo(svc.Spec.Type))
 	gomega.Expect(svcProto.LoadbalancerIp).To(gomega.Equal(svc.Spec.LoadBalancerIP))
+	gomega.Expect(svcProto.SessionAffinity).To(gomega.BeEquivalentTo(svc.Spec.SessionAffinity))
+	gomega.Expect(svcProto.SessionAffinityTimeout).To(gomega.Equal(*svc.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds))
 	gomega.Expect(len(svcProto.Port)).Should(gomega.BeNumerically("==", 1))
 	gomega.Expect(svcProto.Port[0].Name).To(gomega.Equal(svc.Spec.Ports[0].Name))
 	gomega.Expect(svcProto.Port[0].Port).To(gomega.Equal(svc.Spec.Ports[0].Port))
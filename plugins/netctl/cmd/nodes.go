@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/contiv/vpp/plugins/netctl/cmdimpl"
+)
+
+var nodesCmd = &cobra.Command{
+	Use:   "nodes",
+	Short: "Display nodes in the Contiv cluster",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdimpl.PrintNodes()
+	},
+}
+
+var ipamCmd = &cobra.Command{
+	Use:   "ipam [<node>]",
+	Short: "Display IPAM information of all nodes or of the given node",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			cmdimpl.PrintAllIpams()
+			return
+		}
+		cmdimpl.NodeIPamCmd(args[0])
+	},
+}
+
+var podsCmd = &cobra.Command{
+	Use:   "pods [<node>]",
+	Short: "Display pods of all nodes or of the given node",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			cmdimpl.PrintAllPods()
+			return
+		}
+		cmdimpl.PrintPodsPerNode(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nodesCmd, ipamCmd, podsCmd)
+}
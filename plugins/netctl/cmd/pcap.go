@@ -0,0 +1,88 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/contiv/vpp/plugins/contiv/pcap"
+	"github.com/contiv/vpp/plugins/netctl/cmdimpl"
+)
+
+// pcapStartReq collects the flags of the "pcap start" command.
+var pcapStartReq pcap.CaptureRequest
+
+// pcapOutFile is the value of the --output flag of the "pcap fetch" command.
+var pcapOutFile string
+
+var pcapCmd = &cobra.Command{
+	Use:   "pcap",
+	Short: "Capture packets on the VPP interfaces of pods",
+}
+
+var pcapStartCmd = &cobra.Command{
+	Use:   "start <node> [<namespace>/]<pod>",
+	Short: "Start packet capture on the VPP interface of the given pod",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		req := pcapStartReq
+		req.PodNamespace, req.PodName = splitPodName(args[1])
+		cmdimpl.StartPcapCmd(args[0], &req)
+	},
+}
+
+var pcapListCmd = &cobra.Command{
+	Use:   "list <node>",
+	Short: "List packet captures on the given node",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdimpl.ListPcapCmd(args[0])
+	},
+}
+
+var pcapFetchCmd = &cobra.Command{
+	Use:   "fetch <node> <capture-id>",
+	Short: "Download the pcap file of a finished capture",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdimpl.FetchPcapCmd(args[0], args[1], pcapOutFile)
+	},
+}
+
+// splitPodName splits "[<namespace>/]<name>" into namespace and name,
+// the namespace defaults to "default".
+func splitPodName(podName string) (namespace, name string) {
+	if idx := strings.Index(podName, "/"); idx >= 0 {
+		return podName[:idx], podName[idx+1:]
+	}
+	return "default", podName
+}
+
+func init() {
+	flags := pcapStartCmd.Flags()
+	flags.StringVar(&pcapStartReq.Direction, "direction", "",
+		fmt.Sprintf("captured direction: %s, %s or %s (default)", pcap.DirectionRx, pcap.DirectionTx, pcap.DirectionBoth))
+	flags.StringVar(&pcapStartReq.Filter, "filter", "", "filter applied on the captured packets")
+	flags.Uint32Var(&pcapStartReq.MaxPackets, "max-packets", 0, "max. number of packets captured in each direction")
+	flags.Uint32Var(&pcapStartReq.Duration, "duration", 0, "duration of the capture in seconds")
+	pcapFetchCmd.Flags().StringVarP(&pcapOutFile, "output", "o", "", "output file (default <capture-id>.pcap)")
+
+	pcapCmd.AddCommand(pcapStartCmd, pcapListCmd, pcapFetchCmd)
+	rootCmd.AddCommand(pcapCmd)
+}
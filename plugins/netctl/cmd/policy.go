@@ -0,0 +1,44 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/contiv/vpp/plugins/netctl/cmdimpl"
+)
+
+var (
+	policyNode string
+	policyPod  string
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Display the policy configuration of pods",
+	Long: "Displays the policies assigned to the pods of the given node (or of all nodes)\n" +
+		"and the ingress and egress rules if a single pod is selected.",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdimpl.PrintPolicies(policyNode, policyPod)
+	},
+}
+
+func init() {
+	policyCmd.Flags().StringVar(&policyNode, "node", "", "node to display, all nodes if empty")
+	policyCmd.Flags().StringVar(&policyPod, "pod", "", "single pod to display: <namespace>/<name>")
+	rootCmd.AddCommand(policyCmd)
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/contiv/vpp/plugins/netctl/cmdimpl"
+	"github.com/contiv/vpp/plugins/policy/simulator"
+)
+
+var (
+	simSnapshotFile string
+	simQuery        simulator.Query
+)
+
+var policySimulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Evaluate a flow against the network policies",
+	Long: "Evaluates the flow against the policies of the K8s state loaded from the snapshot\n" +
+		"file, or from etcd if the file is not given. Exits with the status 2 if the flow is denied.",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdimpl.SimulatePolicyCmd(simSnapshotFile, simQuery)
+	},
+}
+
+func init() {
+	flags := policySimulateCmd.Flags()
+	flags.StringVarP(&simSnapshotFile, "file", "f", "", "JSON snapshot of the K8s state, read from etcd if empty")
+	flags.StringVar(&simQuery.Src.Pod, "src-pod", "", "source pod: <namespace>/<name>")
+	flags.StringVar(&simQuery.Src.IP, "src-ip", "", "source IP address (if not a pod)")
+	flags.StringVar(&simQuery.Dst.Pod, "dst-pod", "", "destination pod: <namespace>/<name>")
+	flags.StringVar(&simQuery.Dst.IP, "dst-ip", "", "destination IP address (if not a pod)")
+	flags.StringVar(&simQuery.Protocol, "protocol", "TCP", "protocol: TCP, UDP, SCTP, ICMP or OTHER")
+	flags.Uint16Var(&simQuery.SrcPort, "src-port", 0, "source port")
+	flags.Uint16Var(&simQuery.DstPort, "dst-port", 0, "destination port")
+	flags.Uint8Var(&simQuery.ICMPType, "icmp-type", 0, "ICMP type (protocol ICMP only)")
+	flags.Uint8Var(&simQuery.ICMPCode, "icmp-code", 0, "ICMP code (protocol ICMP only)")
+
+	policyCmd.AddCommand(policySimulateCmd)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/contiv/vpp/plugins/ksr"
+	"github.com/contiv/vpp/plugins/netctl/cmdimpl"
+)
+
+// Environment variables with the default etcd settings, the same as used by etcdctl.
+const (
+	etcdEndpointsEnvVar = "ETCDCTL_ENDPOINTS"
+	etcdCertEnvVar      = "ETCDCTL_CERT"
+	etcdKeyEnvVar       = "ETCDCTL_KEY"
+	etcdCACertEnvVar    = "ETCDCTL_CACERT"
+	etcdInsecureEnvVar  = "ETCDCTL_INSECURE_SKIP_TLS_VERIFY"
+	etcdUserEnvVar      = "ETCDCTL_USER" // <username>[:<password>]
+)
+
+// etcdUser is the value of the --user flag: <username>[:<password>].
+var etcdUser string
+
+// rootCmd represents the base command when called without any subcommands.
+var rootCmd = &cobra.Command{
+	Use:   "netctl",
+	Short: "A CLI tool used to manage and debug a Contiv-VPP cluster.",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if etcdUser != "" {
+			userPass := strings.SplitN(etcdUser, ":", 2)
+			cmdimpl.Etcd.Username = userPass[0]
+			if len(userPass) > 1 {
+				cmdimpl.Etcd.Password = userPass[1]
+			}
+		}
+	},
+}
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	endpoints := cmdimpl.Etcd.Endpoints
+	if env := os.Getenv(etcdEndpointsEnvVar); env != "" {
+		endpoints = strings.Split(env, ",")
+	}
+	insecure, _ := strconv.ParseBool(os.Getenv(etcdInsecureEnvVar))
+
+	flags.StringSliceVar(&cmdimpl.Etcd.Endpoints, "endpoints", endpoints,
+		"etcd endpoints (env "+etcdEndpointsEnvVar+")")
+	flags.StringVar(&cmdimpl.Etcd.CertFile, "cert", os.Getenv(etcdCertEnvVar),
+		"client certificate for etcd, enables TLS (env "+etcdCertEnvVar+")")
+	flags.StringVar(&cmdimpl.Etcd.KeyFile, "key", os.Getenv(etcdKeyEnvVar),
+		"client key for etcd (env "+etcdKeyEnvVar+")")
+	flags.StringVar(&cmdimpl.Etcd.CAFile, "cacert", os.Getenv(etcdCACertEnvVar),
+		"CA certificate to verify etcd, enables TLS (env "+etcdCACertEnvVar+")")
+	flags.BoolVar(&cmdimpl.Etcd.InsecureSkipTLSVerify, "insecure-skip-tls-verify", insecure,
+		"do not verify the certificate of etcd, enables TLS (env "+etcdInsecureEnvVar+")")
+	flags.StringVar(&etcdUser, "user", os.Getenv(etcdUserEnvVar),
+		"etcd credentials: <username>[:<password>] (env "+etcdUserEnvVar+")")
+	flags.StringVar(&cmdimpl.Etcd.ClusterID, "cluster-id", cmdimpl.Etcd.ClusterID,
+		"ID of the Contiv cluster (env "+ksr.ClusterIDEnvVar+")")
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/contiv/vpp/plugins/netctl/cmdimpl"
+)
+
+var (
+	traceWaitTime time.Duration
+	tracePackets  uint32
+)
+
+var traceCmd = &cobra.Command{
+	Use:   "trace [<namespace>/]<src-pod> [<namespace>/]<dst-pod>",
+	Short: "Trace packets exchanged between two pods across the nodes",
+	Long: "Starts the VPP packet tracer on the nodes of both pods, waits while the traffic\n" +
+		"between the pods is generated and prints the traced path in both directions.",
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdimpl.TracePodsCmd(args[0], args[1], traceWaitTime, tracePackets)
+	},
+}
+
+func init() {
+	traceCmd.Flags().DurationVar(&traceWaitTime, "wait", 5*time.Second, "time to wait for the traffic to be generated")
+	traceCmd.Flags().Uint32Var(&tracePackets, "packets", 50, "max. number of packets traced on each node")
+	rootCmd.AddCommand(traceCmd)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/contiv/vpp/plugins/netctl/cmdimpl"
+)
+
+var vppDumpCmd = &cobra.Command{
+	Use:   "vppdump <node> [<dump-type>]",
+	Short: "Dump the VPP state of the given type from the given node, list the types if omitted",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		dumpType := ""
+		if len(args) > 1 {
+			dumpType = args[1]
+		}
+		cmdimpl.DumpCmd(args[0], dumpType)
+	},
+}
+
+var vppCliCmd = &cobra.Command{
+	Use:   "vppcli <node> <vpp-cli-command>...",
+	Short: "Execute a VPP CLI command on the given node",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdimpl.VppCliCmd(args[0], strings.Join(args[1:], " "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(vppDumpCmd, vppCliCmd)
+}
@@ -20,32 +20,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/contiv/vpp/plugins/contiv/model/node"
-	"github.com/coreos/etcd/clientv3"
-	"github.com/ligato/cn-infra/db/keyval/etcd"
 	"github.com/ligato/cn-infra/logging/logrus"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // FindIPForNodeName will find an ip address that corresponds to the passed
 // in nodeName
 func FindIPForNodeName(nodeName string) string {
-	cfg := &etcd.ClientConfig{
-		Config: &clientv3.Config{
-			Endpoints: []string{"127.0.0.1:32379"},
-		},
-		OpTimeout: 1 * time.Second,
-	}
 	// Create connection to etcd.
-	db, err := etcd.NewEtcdConnectionWithBytes(*cfg, logrus.DefaultLogger())
+	db, err := newEtcdConnection(logrus.DefaultLogger())
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	itr, err := db.ListValues("/vnf-agent/contiv-ksr/allocatedIDs/")
+	itr, err := db.ListValues(ksrPrefix() + node.AllocatedIDsKeyPrefix)
 	if err != nil {
 		fmt.Printf("Error getting values")
 		return ""
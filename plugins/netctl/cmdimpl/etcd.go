@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmdimpl
+
+import (
+	"time"
+
+	"github.com/contiv/vpp/plugins/ksr"
+	"github.com/ligato/cn-infra/db/keyval/etcd"
+	"github.com/ligato/cn-infra/logging"
+)
+
+const (
+	// defaultEtcdEndpoint is the etcd endpoint exposed by the Contiv deployment on the master node.
+	defaultEtcdEndpoint = "127.0.0.1:32379"
+
+	// etcdOpTimeout is the timeout for any etcd operation done by netctl.
+	etcdOpTimeout = 1 * time.Second
+)
+
+// EtcdSettings holds the parameters of the connection to the etcd of the Contiv cluster.
+type EtcdSettings struct {
+	Endpoints             []string
+	CertFile              string // client certificate, enables TLS
+	KeyFile               string // client key
+	CAFile                string // CA certificate to verify the etcd server, enables TLS
+	InsecureSkipTLSVerify bool   // do not verify the certificate of the etcd server, enables TLS
+	Username              string // etcd user, authentication is disabled if empty
+	Password              string // password of the etcd user
+
+	// ClusterID identifies the Contiv cluster (see ksr.ClusterIDEnvVar)
+	ClusterID string
+}
+
+// Etcd contains the settings used by all netctl commands to access etcd.
+// It is updated from the global command-line flags of netctl (see package cmd)
+// before any command is executed.
+var Etcd = EtcdSettings{
+	Endpoints: []string{defaultEtcdEndpoint},
+	ClusterID: ksr.GetClusterID(),
+}
+
+// newEtcdConnection creates new connection to etcd using the current Etcd settings.
+func newEtcdConnection(logger logging.Logger) (*etcd.BytesConnectionEtcd, error) {
+	useTLS := Etcd.CertFile != "" || Etcd.CAFile != "" || Etcd.InsecureSkipTLSVerify
+	cfg, err := etcd.ConfigToClient(&etcd.Config{
+		Endpoints:             Etcd.Endpoints,
+		OpTimeout:             etcdOpTimeout,
+		InsecureTransport:     !useTLS,
+		InsecureSkipTLSVerify: Etcd.InsecureSkipTLSVerify,
+		Certfile:              Etcd.CertFile,
+		Keyfile:               Etcd.KeyFile,
+		CAfile:                Etcd.CAFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg.Username = Etcd.Username
+	cfg.Password = Etcd.Password
+
+	return etcd.NewEtcdConnectionWithBytes(*cfg, logger)
+}
+
+// ksrPrefix returns the key prefix under which contiv-ksr of the cluster stores the data.
+func ksrPrefix() string {
+	return ksr.GetKsrPrefix(Etcd.ClusterID)
+}
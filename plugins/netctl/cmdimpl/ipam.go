@@ -20,35 +20,26 @@ import (
 	"github.com/contiv/vpp/plugins/contiv/model/node"
 	"github.com/contiv/vpp/plugins/crd/cache/telemetrymodel"
 	"github.com/contiv/vpp/plugins/netctl/http"
-	"github.com/coreos/etcd/clientv3"
 	"github.com/ligato/cn-infra/db/keyval/etcd"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/logging/logrus"
 	"os"
 	"text/tabwriter"
-	"time"
 )
 
 func PrintAllIpams() {
-	etcdCfg := etcd.ClientConfig{
-		Config: &clientv3.Config{
-			Endpoints: []string{"127.0.0.1:32379"},
-		},
-		OpTimeout: 1 * time.Second,
-	}
-
 	logger := logrus.DefaultLogger()
 	logger.SetLevel(logging.ErrorLevel)
 
 	// Create connection to etcd.
 	var err error
 	var db *etcd.BytesConnectionEtcd
-	if db, err = etcd.NewEtcdConnectionWithBytes(etcdCfg, logger); err != nil {
+	if db, err = newEtcdConnection(logger); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	itr, err := db.ListValues("/vnf-agent/contiv-ksr/allocatedIDs/")
+	itr, err := db.ListValues(ksrPrefix() + node.AllocatedIDsKeyPrefix)
 	if err != nil {
 		fmt.Printf("Failed to discover nodes in Contiv cluster")
 		return
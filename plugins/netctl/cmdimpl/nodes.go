@@ -23,8 +23,6 @@ import (
 
 	"github.com/contiv/vpp/plugins/crd/cache/telemetrymodel"
 	"github.com/contiv/vpp/plugins/netctl/http"
-	"github.com/coreos/etcd/clientv3"
-	"github.com/ligato/cn-infra/db/keyval/etcd"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/logging/logrus"
 	"os"
@@ -35,23 +33,17 @@ import (
 
 //PrintNodes will print out all of the cmdimpl in a network in a table format.
 func PrintNodes() {
-	cfg := &etcd.ClientConfig{
-		Config: &clientv3.Config{
-			Endpoints: []string{"127.0.0.1:32379"},
-		},
-		OpTimeout: 1 * time.Second,
-	}
 	logger := logrus.DefaultLogger()
 	logger.SetLevel(logging.FatalLevel)
 	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
 	// w := tabwriter.NewWriter(os.Stdout, 0, 8, 4, '\t', 0)
 	// Create connection to etcd.
-	db, err := etcd.NewEtcdConnectionWithBytes(*cfg, logger)
+	db, err := newEtcdConnection(logger)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	itr, err := db.ListValues("/vnf-agent/contiv-ksr/allocatedIDs/")
+	itr, err := db.ListValues(ksrPrefix() + nodeinfomodel.AllocatedIDsKeyPrefix)
 	if err != nil {
 		fmt.Printf("Error getting values")
 		return
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmdimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/contiv/vpp/plugins/contiv/pcap"
+	"github.com/contiv/vpp/plugins/netctl/http"
+)
+
+// pcapCmd is the agent REST URL (without the leading slash) of the packet capture API.
+const pcapCmd = "contiv/v1/pcap"
+
+// StartPcapCmd starts packet capture on the VPP interface of the given pod
+// deployed on the given node.
+func StartPcapCmd(nodeName string, req *pcap.CaptureRequest) {
+	ipAdr := resolveNodeOrIP(nodeName)
+	if ipAdr == "" {
+		fmt.Printf("Unknown node name %s\n", nodeName)
+		return
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	b, err := http.PostNodeInfo(ipAdr, pcapCmd, string(body))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	capture := &pcap.CaptureInfo{}
+	if err := json.Unmarshal(b, capture); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Capture %s on pod %s/%s started\n", capture.ID, req.PodNamespace, req.PodName)
+	fmt.Printf("Use 'netctl pcap list %s' to watch its state and 'netctl pcap fetch %s %s' to download it\n",
+		nodeName, nodeName, capture.ID)
+}
+
+// ListPcapCmd prints all packet captures (running or finished) on the given node.
+func ListPcapCmd(nodeName string) {
+	ipAdr := resolveNodeOrIP(nodeName)
+	if ipAdr == "" {
+		fmt.Printf("Unknown node name %s\n", nodeName)
+		return
+	}
+	b := http.GetNodeInfo(ipAdr, pcapCmd)
+	var captures []*pcap.CaptureInfo
+	if err := json.Unmarshal(b, &captures); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, '\t', 0)
+	fmt.Fprintf(w, "ID\tPOD\tINTERFACE\tDIRECTION\tFILTER\tSTARTED\tSTATE\tPACKETS\n")
+	for _, capture := range captures {
+		state := capture.State
+		if capture.Error != "" {
+			state += " (" + capture.Error + ")"
+		}
+		pod := ""
+		if capture.PodName != "" {
+			pod = capture.PodNamespace + "/" + capture.PodName
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			capture.ID, pod, capture.Interface, capture.Direction, capture.Filter,
+			capture.Started.Format("2006-01-02 15:04:05"), state, capture.Packets)
+	}
+	w.Flush()
+}
+
+// FetchPcapCmd downloads the pcap file of a finished capture from the given node
+// and stores it into <outFile> (defaults to <captureID>.pcap).
+func FetchPcapCmd(nodeName string, captureID string, outFile string) {
+	ipAdr := resolveNodeOrIP(nodeName)
+	if ipAdr == "" {
+		fmt.Printf("Unknown node name %s\n", nodeName)
+		return
+	}
+
+	// check the state of the capture first
+	b := http.GetNodeInfo(ipAdr, pcapCmd+"/"+captureID)
+	capture := &pcap.CaptureInfo{}
+	if err := json.Unmarshal(b, capture); err != nil || capture.ID == "" {
+		fmt.Printf("Capture %s was not found on node %s\n", captureID, nodeName)
+		return
+	}
+	if capture.State != pcap.StateDone {
+		fmt.Printf("Capture %s is %s\n", captureID, capture.State)
+		return
+	}
+
+	if outFile == "" {
+		outFile = captureID + ".pcap"
+	}
+	b, err := http.GetNodeData(ipAdr, pcapCmd+"/"+captureID+"/file")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := ioutil.WriteFile(outFile, b, 0644); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Capture %s (%d packets) stored into %s\n", captureID, capture.Packets, outFile)
+}
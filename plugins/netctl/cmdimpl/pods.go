@@ -20,7 +20,6 @@ import (
 	"github.com/contiv/vpp/plugins/crd/cache/telemetrymodel"
 	"github.com/contiv/vpp/plugins/ksr/model/pod"
 	"github.com/contiv/vpp/plugins/netctl/http"
-	"github.com/coreos/etcd/clientv3"
 	"github.com/ligato/cn-infra/db/keyval/etcd"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/logging/logrus"
@@ -28,7 +27,6 @@ import (
 	"os"
 	"strings"
 	"text/tabwriter"
-	"time"
 )
 
 type nodeData struct {
@@ -38,10 +36,9 @@ type nodeData struct {
 type nodeDataCache map[string]*nodeData
 
 type podGetter struct {
-	etcdConfig *etcd.ClientConfig
-	ndCache    nodeDataCache
-	logger     logging.Logger
-	db         *etcd.BytesConnectionEtcd
+	ndCache nodeDataCache
+	logger  logging.Logger
+	db      *etcd.BytesConnectionEtcd
 }
 
 // PrintAllPods will print out all of the non local pods in a network in
@@ -66,12 +63,6 @@ func PrintPodsPerNode(input string) {
 
 func newPodGetter() *podGetter {
 	pg := &podGetter{
-		etcdConfig: &etcd.ClientConfig{
-			Config: &clientv3.Config{
-				Endpoints: []string{"127.0.0.1:32379"},
-			},
-			OpTimeout: 1 * time.Second,
-		},
 		ndCache: make(nodeDataCache, 0),
 		logger:  logrus.DefaultLogger(),
 	}
@@ -80,7 +71,7 @@ func newPodGetter() *podGetter {
 
 	// Create connection to etcd.
 	var err error
-	if pg.db, err = etcd.NewEtcdConnectionWithBytes(*pg.etcdConfig, pg.logger); err != nil {
+	if pg.db, err = newEtcdConnection(pg.logger); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -90,7 +81,7 @@ func newPodGetter() *podGetter {
 
 func (pg *podGetter) printAllPods(w *tabwriter.Writer) {
 
-	itr, err := pg.db.ListValues("/vnf-agent/contiv-ksr/allocatedIDs/")
+	itr, err := pg.db.ListValues(ksrPrefix() + node.AllocatedIDsKeyPrefix)
 	if err != nil {
 		fmt.Printf("Error getting values")
 		return
@@ -116,7 +107,7 @@ func (pg *podGetter) printAllPods(w *tabwriter.Writer) {
 func (pg *podGetter) printPodsPerNode(w *tabwriter.Writer, nodeNameOrIP string, nodeName string) {
 	hostIP := resolveNodeOrIP(nodeNameOrIP)
 
-	itr, err := pg.db.ListValues("/vnf-agent/contiv-ksr/k8s/pod/")
+	itr, err := pg.db.ListValues(ksrPrefix() + pod.KeyPrefix())
 	if err != nil {
 		fmt.Printf("Failed to get pods from etcd for node %s, err %s", nodeNameOrIP, err)
 		return
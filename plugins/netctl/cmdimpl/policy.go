@@ -0,0 +1,132 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package cmdimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	nodeinfomodel "github.com/contiv/vpp/plugins/contiv/model/node"
+	"github.com/contiv/vpp/plugins/netctl/http"
+	policydebug "github.com/contiv/vpp/plugins/policy/debug"
+)
+
+// policyPodsCmd is the agent REST URL (without the leading slash) of the policy debug API.
+const policyPodsCmd = "contiv/v1/policy/pods"
+
+// PrintPolicies prints the policy configuration of pods deployed on the given
+// node, or on all nodes if <nodeName> is empty. If <podName> ("namespace/name")
+// is given, only this pod is printed, including its ingress and egress rules.
+func PrintPolicies(nodeName string, podName string) {
+	nodes := []string{nodeName}
+	if nodeName == "" {
+		nodes = listNodeNames()
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "NODE\tPOD\tIP-ADDRESSES\tPOLICIES\tINGRESS\tEGRESS\tTABLES\n")
+	var details []*policydebug.PodDump
+	for _, node := range nodes {
+		ipAdr := resolveNodeOrIP(node)
+		if ipAdr == "" {
+			fmt.Printf("Unknown node name %s\n", node)
+			continue
+		}
+		b := http.GetNodeInfo(ipAdr, policyPodsCmd)
+		var pods []*policydebug.PodDump
+		if err := json.Unmarshal(b, &pods); err != nil {
+			fmt.Printf("Failed to get policy configuration from node %s: %v\n", node, err)
+			continue
+		}
+		for _, pod := range pods {
+			if podName != "" && pod.Pod != podName {
+				continue
+			}
+			tables := []string{}
+			for _, table := range pod.Tables {
+				if table.LocalTable == "" {
+					continue
+				}
+				tableStr := table.Renderer + ":" + table.LocalTable
+				if len(table.SharedWith) > 0 {
+					tableStr += fmt.Sprintf("(shared with %d)", len(table.SharedWith))
+				}
+				tables = append(tables, tableStr)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+				node, pod.Pod, strings.Join(pod.IPAddresses, ","), strings.Join(pod.Policies, ","),
+				len(pod.Ingress), len(pod.Egress), strings.Join(tables, ","))
+			if podName != "" {
+				details = append(details, pod)
+			}
+		}
+	}
+	w.Flush()
+
+	// Print rules of the selected pod.
+	for _, pod := range details {
+		fmt.Printf("\nIngress rules of %s (vswitch point of view):\n", pod.Pod)
+		for _, rule := range pod.Ingress {
+			fmt.Printf("  %s\n", rule)
+		}
+		fmt.Printf("Egress rules of %s (vswitch point of view):\n", pod.Pod)
+		for _, rule := range pod.Egress {
+			fmt.Printf("  %s\n", rule)
+		}
+		for _, table := range pod.Tables {
+			if len(table.SharedWith) > 0 {
+				fmt.Printf("%s local table %s shared with: %s\n", table.Renderer, table.LocalTable,
+					strings.Join(table.SharedWith, ","))
+			}
+		}
+	}
+}
+
+// listNodeNames returns names of all nodes in the Contiv cluster.
+func listNodeNames() (nodes []string) {
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.ErrorLevel)
+
+	db, err := newEtcdConnection(logger)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	itr, err := db.ListValues(ksrPrefix() + nodeinfomodel.AllocatedIDsKeyPrefix)
+	if err != nil {
+		fmt.Printf("Failed to discover nodes in Contiv cluster")
+		return nil
+	}
+	for {
+		kv, stop := itr.GetNext()
+		if stop {
+			break
+		}
+		nodeInfo := &nodeinfomodel.NodeInfo{}
+		if err := json.Unmarshal(kv.GetValue(), nodeInfo); err != nil {
+			continue
+		}
+		nodes = append(nodes, nodeInfo.Name)
+	}
+	return nodes
+}
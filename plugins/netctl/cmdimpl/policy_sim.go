@@ -0,0 +1,88 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmdimpl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	"github.com/contiv/vpp/plugins/policy/simulator"
+)
+
+// policyDeniedExitCode is the exit code of the policy simulation for denied
+// flows.
+const policyDeniedExitCode = 2
+
+// SimulatePolicyCmd evaluates the given flow against the policies of the K8s
+// state loaded from the snapshot file, or from etcd if the file is not given.
+// The command exits with the status 1 on errors and with the status 2 if
+// the flow is denied, therefore it can be used to test policy changes in CI.
+func SimulatePolicyCmd(snapshotFile string, query simulator.Query) {
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.ErrorLevel)
+
+	var (
+		snapshot *simulator.Snapshot
+		err      error
+	)
+	if snapshotFile != "" {
+		snapshot, err = simulator.LoadSnapshotFile(snapshotFile)
+	} else {
+		db, dbErr := newEtcdConnection(logger)
+		if dbErr != nil {
+			fmt.Println(dbErr)
+			os.Exit(1)
+		}
+		snapshot, err = simulator.LoadSnapshotFromDB(db, ksrPrefix())
+		db.Close()
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	sim, err := simulator.NewSimulator(logger, snapshot)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	result, err := sim.Simulate(query)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "POD\tDIRECTION\tVERDICT\tPOLICIES\tRULE\n")
+	for _, evaluation := range []*simulator.Evaluation{result.Egress, result.Ingress} {
+		if evaluation == nil {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", evaluation.Pod, evaluation.Direction,
+			evaluation.Verdict, strings.Join(evaluation.Policies, ","), evaluation.Rule)
+	}
+	w.Flush()
+	fmt.Printf("\nVerdict: %s\n", result.Verdict)
+
+	if result.Verdict == simulator.VerdictDenied {
+		os.Exit(policyDeniedExitCode)
+	}
+}
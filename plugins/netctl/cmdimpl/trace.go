@@ -0,0 +1,154 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmdimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/contiv/vpp/plugins/contiv/vpptrace"
+	"github.com/contiv/vpp/plugins/ksr/model/pod"
+	"github.com/contiv/vpp/plugins/netctl/http"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+)
+
+// traceCmd is the agent REST URL (without the leading slash) of the packet trace API.
+const traceCmd = "contiv/v1/trace"
+
+// TracePodsCmd traces packets exchanged between two pods (given as "<namespace>/<name>"
+// or just "<name>" for the default namespace). The VPP packet tracer is started
+// on the nodes of both pods, then after <waitTime> (during which the traffic
+// should be generated) the traced packets of the pod pair are fetched
+// and printed as one annotated path across the nodes.
+func TracePodsCmd(srcPod string, dstPod string, waitTime time.Duration, packets uint32) {
+	src, err := getPodInfo(srcPod)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	dst, err := getPodInfo(dstPod)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	nodes := []string{src.HostIpAddress}
+	if dst.HostIpAddress != src.HostIpAddress {
+		nodes = append(nodes, dst.HostIpAddress)
+	}
+
+	// start tracing on both nodes
+	body, _ := json.Marshal(&vpptrace.TraceRequest{Packets: packets})
+	for _, nodeIP := range nodes {
+		if _, err := http.PostNodeInfo(nodeIP, traceCmd, string(body)); err != nil {
+			fmt.Printf("Failed to start packet trace on node %s: %v\n", nodeIP, err)
+			return
+		}
+	}
+	fmt.Printf("Tracing packets between %s (%s) and %s (%s) for %v, generate the traffic now...\n",
+		srcPod, src.IpAddress, dstPod, dst.IpAddress, waitTime)
+	time.Sleep(waitTime)
+
+	// fetch the packets of the pod pair from both nodes
+	query := url.Values{}
+	query.Set("src", src.IpAddress)
+	query.Set("dst", dst.IpAddress)
+	traces := make(map[string]*vpptrace.Trace)
+	for _, nodeIP := range nodes {
+		b := http.GetNodeInfo(nodeIP, traceCmd+"?"+query.Encode())
+		trace := &vpptrace.Trace{}
+		if err := json.Unmarshal(b, trace); err != nil {
+			fmt.Printf("Failed to get packet trace from node %s: %v\n", nodeIP, err)
+			return
+		}
+		traces[nodeIP] = trace
+	}
+
+	// print the path of the first packet going from the source to the destination pod,
+	// as seen on the source node and then on the destination node
+	fmt.Printf("\nPath %s -> %s:\n", srcPod, dstPod)
+	for _, nodeIP := range nodes {
+		packet := traces[nodeIP].FirstPacket(src.IpAddress, dst.IpAddress)
+		fmt.Printf("\n== node %s ==\n", nodeIP)
+		if packet == nil {
+			fmt.Printf("no packet traced (captured %d packets of the pod pair)\n", len(traces[nodeIP].Packets))
+			continue
+		}
+		printPacketPath(packet, src.IpAddress, dst.IpAddress)
+	}
+
+	// print the path of the reply (if any)
+	fmt.Printf("\nPath %s -> %s:\n", dstPod, srcPod)
+	for i := len(nodes) - 1; i >= 0; i-- {
+		packet := traces[nodes[i]].FirstPacket(dst.IpAddress, src.IpAddress)
+		fmt.Printf("\n== node %s ==\n", nodes[i])
+		if packet == nil {
+			fmt.Println("no packet traced")
+			continue
+		}
+		printPacketPath(packet, dst.IpAddress, src.IpAddress)
+	}
+}
+
+// getPodInfo reads the pod data reflected by KSR from etcd.
+func getPodInfo(podID string) (*pod.Pod, error) {
+	namespace, name := "default", podID
+	if parts := strings.SplitN(podID, "/", 2); len(parts) == 2 {
+		namespace, name = parts[0], parts[1]
+	}
+
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.ErrorLevel)
+	db, err := newEtcdConnection(logger)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	buf, found, _, err := db.GetValue(ksrPrefix() + pod.Key(name, namespace))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("pod %s/%s not found", namespace, name)
+	}
+	podInfo := &pod.Pod{}
+	if err := json.Unmarshal(buf, podInfo); err != nil {
+		return nil, err
+	}
+	if podInfo.IpAddress == "" || podInfo.HostIpAddress == "" {
+		return nil, fmt.Errorf("pod %s/%s is not running", namespace, name)
+	}
+	return podInfo, nil
+}
+
+// printPacketPath prints the VPP graph nodes traversed by the packet of the flow
+// from <srcIP> to <dstIP> with annotations.
+func printPacketPath(packet *vpptrace.Packet, srcIP, dstIP string) {
+	flow := packet.Flow(srcIP, dstIP)
+	fmt.Printf("packet %d (%s %s -> %s)\n", packet.ID, flow.Protocol, flow.SrcIP, flow.DstIP)
+	for _, node := range packet.Nodes {
+		if node.Annotation != "" {
+			fmt.Printf("  %s  %-30s <-- %s\n", node.Time, node.Name, node.Annotation)
+		} else {
+			fmt.Printf("  %s  %s\n", node.Time, node.Name)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package http implements access of netctl to the REST API of Contiv agents.
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	agentPort     = ":9999"
+	clientTimeout = 10 * time.Second
+)
+
+// GetNodeInfo will make an http request for the given command and return an indented slice of bytes.
+func GetNodeInfo(ipAddr string, cmd string) []byte {
+	b, err := GetNodeData(ipAddr, cmd)
+	if err != nil {
+		fmt.Printf("GetNodeInfo: %s\n", err)
+		return nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, "", "  "); err != nil {
+		fmt.Printf("GetNodeInfo: url: %s indent error: %s\n", cmd, err)
+		return nil
+	}
+	return out.Bytes()
+}
+
+// GetNodeData will make an http request for the given command and return the body
+// of the response as is (e.g. a file served by the agent).
+func GetNodeData(ipAddr string, cmd string) ([]byte, error) {
+	client := http.Client{Timeout: clientTimeout}
+	res, err := client.Get(agentURL(ipAddr, cmd))
+	if err != nil {
+		return nil, fmt.Errorf("url: %s error: %s", cmd, err)
+	}
+	return readResponse(cmd, res)
+}
+
+// SetNodeInfo will make an http json post request for the given command
+// and print out the response (e.g. the output of a vpp cli command).
+func SetNodeInfo(ipAddr string, cmd string, body string) error {
+	b, err := PostNodeInfo(ipAddr, cmd, body)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// PostNodeInfo will make an http json post request for the given command
+// and return the body of the response.
+func PostNodeInfo(ipAddr string, cmd string, body string) ([]byte, error) {
+	client := http.Client{Timeout: clientTimeout}
+	res, err := client.Post(agentURL(ipAddr, cmd), "application/json", strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("url: %s error: %s", cmd, err)
+	}
+	return readResponse(cmd, res)
+}
+
+// Crawl will crawl the index page at the given url ("<host>:<port>") and return
+// all the links found there.
+func Crawl(url string) []string {
+	client := http.Client{Timeout: clientTimeout}
+	res, err := client.Get("http://" + url)
+	if err != nil {
+		fmt.Printf("Crawl: url: %s error: %s\n", url, err)
+		return nil
+	}
+	defer res.Body.Close()
+
+	var links []string
+	z := html.NewTokenizer(res.Body)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			// end of the document
+			return links
+		case html.StartTagToken:
+			t := z.Token()
+			if t.Data != "a" {
+				continue
+			}
+			for _, attr := range t.Attr {
+				if attr.Key == "href" {
+					links = append(links, attr.Val)
+				}
+			}
+		}
+	}
+}
+
+// agentURL returns the URL of the given command served by the agent at the given IP address.
+func agentURL(ipAddr string, cmd string) string {
+	return "http://" + ipAddr + agentPort + "/" + cmd
+}
+
+// readResponse reads the body of the response, non-2xx responses are turned into errors
+// carrying the message returned by the agent.
+func readResponse(cmd string, res *http.Response) ([]byte, error) {
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("url: %s error: %s", cmd, err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var msg string
+		if json.Unmarshal(b, &msg) != nil {
+			msg = strings.TrimSpace(string(b))
+		}
+		return nil, fmt.Errorf("url: %s HTTP status: %s: %s", cmd, res.Status, msg)
+	}
+	return b, nil
+}
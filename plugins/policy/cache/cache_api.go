@@ -19,7 +19,9 @@ package cache
 import (
 	"github.com/ligato/cn-infra/datasync"
 
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
 	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	nodemodel "github.com/contiv/vpp/plugins/ksr/model/node"
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 )
@@ -62,6 +64,12 @@ type PolicyCacheAPI interface {
 	// LookupPodsByNamespace returns IDs of all pods inside a given namespace.
 	LookupPodsByNamespace(policyNamespace string) (pods []podmodel.ID)
 
+	// LookupPodsByNsAndPodSelector evaluates namespace and pod label selectors
+	// and returns IDs of matching pods from across all namespaces.
+	// Nil namespace selector matches all namespaces, nil pod selector matches
+	// all pods inside the selected namespaces.
+	LookupPodsByNsAndPodSelector(nsLabelSelector, podLabelSelector *policymodel.Policy_LabelSelector) (pods []podmodel.ID)
+
 	// ListAllPods returns IDs of all known pods.
 	ListAllPods() (pods []podmodel.ID)
 
@@ -71,9 +79,33 @@ type PolicyCacheAPI interface {
 	// LookupPoliciesByPod returns IDs of all policies assigned to a given pod.
 	LookupPoliciesByPod(pod podmodel.ID) (policies []policymodel.ID)
 
+	// LookupPoliciesReferencingPod returns IDs of policies which may reference
+	// a given pod as a peer (superset, selectors need to be evaluated).
+	LookupPoliciesReferencingPod(pod *podmodel.Pod) (policies []policymodel.ID)
+
+	// LookupPoliciesReferencingNamespace returns IDs of policies which may
+	// reference a given namespace by a namespace selector (superset, selectors
+	// need to be evaluated).
+	LookupPoliciesReferencingNamespace(ns *nsmodel.Namespace) (policies []policymodel.ID)
+
 	// ListAllPolicies returns IDs of all policies.
 	ListAllPolicies() (policies []policymodel.ID)
 
+	// LookupClusterPolicy returns data of a given cluster-wide policy.
+	LookupClusterPolicy(policy string) (found bool, data *clusterpolicymodel.ClusterPolicy)
+
+	// ListAllClusterPolicies returns names of all cluster-wide policies.
+	ListAllClusterPolicies() (policies []string)
+
+	// LookupHostEndpointPolicy returns data of a given host-endpoint policy.
+	LookupHostEndpointPolicy(policy string) (found bool, data *clusterpolicymodel.HostEndpointPolicy)
+
+	// ListAllHostEndpointPolicies returns names of all host-endpoint policies.
+	ListAllHostEndpointPolicies() (policies []string)
+
+	// LookupNode returns data of a given K8s node.
+	LookupNode(node string) (found bool, data *nodemodel.Node)
+
 	// LookupNamespace returns data of a given namespace.
 	LookupNamespace(namespace nsmodel.ID) (found bool, data *nsmodel.Namespace)
 
@@ -105,6 +137,40 @@ type PolicyCacheWatcher interface {
 	// modified.
 	UpdatePolicy(oldPolicy, newPolicy *policymodel.Policy) error
 
+	// AddClusterPolicy is called by Policy Cache when a new cluster-wide policy
+	// is created.
+	AddClusterPolicy(policy *clusterpolicymodel.ClusterPolicy) error
+
+	// DelClusterPolicy is called by Policy Cache after a cluster-wide policy
+	// was removed.
+	DelClusterPolicy(policy *clusterpolicymodel.ClusterPolicy) error
+
+	// UpdateClusterPolicy is called by Policy Cache when data of a cluster-wide
+	// policy were modified.
+	UpdateClusterPolicy(oldPolicy, newPolicy *clusterpolicymodel.ClusterPolicy) error
+
+	// AddHostEndpointPolicy is called by Policy Cache when a new host-endpoint
+	// policy is created.
+	AddHostEndpointPolicy(policy *clusterpolicymodel.HostEndpointPolicy) error
+
+	// DelHostEndpointPolicy is called by Policy Cache after a host-endpoint
+	// policy was removed.
+	DelHostEndpointPolicy(policy *clusterpolicymodel.HostEndpointPolicy) error
+
+	// UpdateHostEndpointPolicy is called by Policy Cache when data of
+	// a host-endpoint policy were modified.
+	UpdateHostEndpointPolicy(oldPolicy, newPolicy *clusterpolicymodel.HostEndpointPolicy) error
+
+	// AddNode is called by Policy Cache when a new K8s node is added.
+	AddNode(node *nodemodel.Node) error
+
+	// DelNode is called by Policy Cache after a K8s node was removed.
+	DelNode(node *nodemodel.Node) error
+
+	// UpdateNode is called by Policy Cache when data of a K8s node were
+	// modified.
+	UpdateNode(oldNode, newNode *nodemodel.Node) error
+
 	// AddNamespace is called by Policy Cache when a new namespace is created.
 	AddNamespace(ns *nsmodel.Namespace) error
 
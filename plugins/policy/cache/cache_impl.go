@@ -1,10 +1,14 @@
 package cache
 
 import (
+	"sort"
+
 	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/cn-infra/logging"
 
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
 	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	nodemodel "github.com/contiv/vpp/plugins/ksr/model/node"
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	"github.com/contiv/vpp/plugins/policy/cache/namespaceidx"
@@ -27,6 +31,9 @@ type PolicyCache struct {
 	configuredPolicies   *policyidx.ConfigIndex
 	configuredPods       *podidx.ConfigIndex
 	configuredNamespaces *namespaceidx.ConfigIndex
+	clusterPolicies      map[string]*clusterpolicymodel.ClusterPolicy
+	hostPolicies         map[string]*clusterpolicymodel.HostEndpointPolicy
+	nodes                map[string]*nodemodel.Node
 	watchers             []PolicyCacheWatcher
 }
 
@@ -40,6 +47,9 @@ func (pc *PolicyCache) Init() error {
 	pc.configuredPolicies = policyidx.NewConfigIndex(pc.Log, "policies")
 	pc.configuredPods = podidx.NewConfigIndex(pc.Log, "pods")
 	pc.configuredNamespaces = namespaceidx.NewConfigIndex(pc.Log, "namespaces")
+	pc.clusterPolicies = make(map[string]*clusterpolicymodel.ClusterPolicy)
+	pc.hostPolicies = make(map[string]*clusterpolicymodel.HostEndpointPolicy)
+	pc.nodes = make(map[string]*nodemodel.Node)
 
 	pc.watchers = []PolicyCacheWatcher{}
 	return nil
@@ -159,6 +169,42 @@ func (pc *PolicyCache) LookupPodsByNamespace(namespace string) (pods []podmodel.
 	return pods
 }
 
+// LookupPodsByNsAndPodSelector evaluates namespace and pod label selectors
+// and returns IDs of matching pods from across all namespaces.
+// Nil namespace selector matches all namespaces, nil pod selector matches
+// all pods inside the selected namespaces.
+func (pc *PolicyCache) LookupPodsByNsAndPodSelector(nsLabelSelector,
+	podLabelSelector *policymodel.Policy_LabelSelector) (pods []podmodel.ID) {
+
+	var nsPods []podmodel.ID
+	if nsLabelSelector == nil {
+		nsPods = pc.ListAllPods()
+	} else {
+		nsPods = pc.LookupPodsByNsLabelSelector(nsLabelSelector)
+	}
+	if podLabelSelector == nil {
+		return nsPods
+	}
+
+	// Evaluate pod selector inside each of the selected namespaces.
+	namespaces := make(map[string]struct{})
+	for _, pod := range nsPods {
+		namespaces[pod.Namespace] = struct{}{}
+	}
+	selected := make(map[podmodel.ID]struct{})
+	for namespace := range namespaces {
+		for _, pod := range pc.LookupPodsByLabelSelectorInsideNs(namespace, podLabelSelector) {
+			selected[pod] = struct{}{}
+		}
+	}
+	for _, pod := range nsPods {
+		if _, isSelected := selected[pod]; isSelected {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
 // ListAllPods returns the IDs of all known pods.
 func (pc *PolicyCache) ListAllPods() (pods []podmodel.ID) {
 	allPods := pc.configuredPods.ListAll()
@@ -181,8 +227,9 @@ func (pc *PolicyCache) LookupPoliciesByPod(pod podmodel.ID) (policyIDs []policym
 	policyIDs = []policymodel.ID{}
 	policyData := []*policymodel.Policy{}
 
-	// Get and store the data from all the policies in cache.
-	policies := pc.ListAllPolicies()
+	// Get and store the data from all the policies in the pod's namespace
+	// (policy selects pods only from its own namespace).
+	policies := utils.UnstringPolicyID(pc.configuredPolicies.LookupPolicyByNamespace(pod.Namespace))
 	for _, policy := range policies {
 		found, data := pc.LookupPolicy(policy)
 		if !found {
@@ -206,6 +253,38 @@ func (pc *PolicyCache) LookupPoliciesByPod(pod podmodel.ID) (policyIDs []policym
 	return policyIDs
 }
 
+// LookupPoliciesReferencingPod returns the IDs of policies which may reference
+// a given pod as a peer. The returned list is a superset of the policies
+// actually matching the pod - the selectors still need to be evaluated.
+func (pc *PolicyCache) LookupPoliciesReferencingPod(pod *podmodel.Pod) (policyIDs []policymodel.ID) {
+	podLabelKeys := []string{}
+	for _, label := range pod.Label {
+		podLabelKeys = append(podLabelKeys, label.Key)
+	}
+	nsLabelKeys := []string{}
+	if found, nsData := pc.LookupNamespace(nsmodel.ID(pod.Namespace)); found {
+		nsLabelKeys = namespaceLabelKeys(nsData)
+	}
+	policies := pc.configuredPolicies.LookupPolicyByPodPeer(pod.Namespace, podLabelKeys, nsLabelKeys)
+	return utils.UnstringPolicyID(policies)
+}
+
+// LookupPoliciesReferencingNamespace returns the IDs of policies which may
+// reference pods of a given namespace as peers by a namespace selector.
+// The returned list is a superset of the policies actually matching the namespace.
+func (pc *PolicyCache) LookupPoliciesReferencingNamespace(ns *nsmodel.Namespace) (policyIDs []policymodel.ID) {
+	policies := pc.configuredPolicies.LookupPolicyByNamespacePeer(namespaceLabelKeys(ns))
+	return utils.UnstringPolicyID(policies)
+}
+
+// namespaceLabelKeys returns keys of all labels of the namespace.
+func namespaceLabelKeys(ns *nsmodel.Namespace) (keys []string) {
+	for _, label := range ns.Label {
+		keys = append(keys, label.Key)
+	}
+	return keys
+}
+
 // ListAllPolicies returns IDs of all policies.
 func (pc *PolicyCache) ListAllPolicies() (policyIDs []policymodel.ID) {
 	allPolicies := pc.configuredPolicies.ListAll()
@@ -214,6 +293,42 @@ func (pc *PolicyCache) ListAllPolicies() (policyIDs []policymodel.ID) {
 	return policyIDs
 }
 
+// LookupClusterPolicy returns data of a given cluster-wide policy.
+func (pc *PolicyCache) LookupClusterPolicy(policy string) (found bool, data *clusterpolicymodel.ClusterPolicy) {
+	data, found = pc.clusterPolicies[policy]
+	return found, data
+}
+
+// ListAllClusterPolicies returns names of all cluster-wide policies (sorted).
+func (pc *PolicyCache) ListAllClusterPolicies() (policies []string) {
+	for policy := range pc.clusterPolicies {
+		policies = append(policies, policy)
+	}
+	sort.Strings(policies)
+	return policies
+}
+
+// LookupHostEndpointPolicy returns data of a given host-endpoint policy.
+func (pc *PolicyCache) LookupHostEndpointPolicy(policy string) (found bool, data *clusterpolicymodel.HostEndpointPolicy) {
+	data, found = pc.hostPolicies[policy]
+	return found, data
+}
+
+// ListAllHostEndpointPolicies returns names of all host-endpoint policies (sorted).
+func (pc *PolicyCache) ListAllHostEndpointPolicies() (policies []string) {
+	for policy := range pc.hostPolicies {
+		policies = append(policies, policy)
+	}
+	sort.Strings(policies)
+	return policies
+}
+
+// LookupNode returns data of a given K8s node.
+func (pc *PolicyCache) LookupNode(node string) (found bool, data *nodemodel.Node) {
+	data, found = pc.nodes[node]
+	return found, data
+}
+
 // LookupNamespace returns data of a given namespace.
 func (pc *PolicyCache) LookupNamespace(namespace nsmodel.ID) (found bool, data *nsmodel.Namespace) {
 	found, data = pc.configuredNamespaces.LookupNamespace(namespace.String())
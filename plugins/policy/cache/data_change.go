@@ -19,7 +19,9 @@ package cache
 import (
 	"github.com/ligato/cn-infra/datasync"
 
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
 	namespacemodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	nodemodel "github.com/contiv/vpp/plugins/ksr/model/node"
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 )
@@ -79,6 +81,138 @@ func (pc *PolicyCache) changePropagateEvent(dataChngEv datasync.ChangeEvent) err
 		return nil
 	}
 
+	// Propagate cluster Policy CHANGE event
+	_, err = clusterpolicymodel.ParseClusterPolicyFromKey(key)
+	if err == nil {
+		var value, prevValue clusterpolicymodel.ClusterPolicy
+
+		if err = dataChngEv.GetValue(&value); err != nil {
+			return err
+		}
+
+		if diff, err = dataChngEv.GetPrevValue(&prevValue); err != nil {
+			return err
+		}
+
+		if datasync.Delete == dataChngEv.GetChangeType() {
+			delete(pc.clusterPolicies, prevValue.Name)
+
+			for _, watcher := range pc.watchers {
+				if err := watcher.DelClusterPolicy(&prevValue); err != nil {
+					return err
+				}
+			}
+
+		} else if diff {
+			delete(pc.clusterPolicies, prevValue.Name)
+			pc.clusterPolicies[value.Name] = &value
+
+			for _, watcher := range pc.watchers {
+				if err := watcher.UpdateClusterPolicy(&prevValue, &value); err != nil {
+					return err
+				}
+			}
+
+		} else {
+			pc.clusterPolicies[value.Name] = &value
+
+			for _, watcher := range pc.watchers {
+				if err := watcher.AddClusterPolicy(&value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	// Propagate host-endpoint Policy CHANGE event
+	_, err = clusterpolicymodel.ParseHostEndpointPolicyFromKey(key)
+	if err == nil {
+		var value, prevValue clusterpolicymodel.HostEndpointPolicy
+
+		if err = dataChngEv.GetValue(&value); err != nil {
+			return err
+		}
+
+		if diff, err = dataChngEv.GetPrevValue(&prevValue); err != nil {
+			return err
+		}
+
+		if datasync.Delete == dataChngEv.GetChangeType() {
+			delete(pc.hostPolicies, prevValue.Name)
+
+			for _, watcher := range pc.watchers {
+				if err := watcher.DelHostEndpointPolicy(&prevValue); err != nil {
+					return err
+				}
+			}
+
+		} else if diff {
+			delete(pc.hostPolicies, prevValue.Name)
+			pc.hostPolicies[value.Name] = &value
+
+			for _, watcher := range pc.watchers {
+				if err := watcher.UpdateHostEndpointPolicy(&prevValue, &value); err != nil {
+					return err
+				}
+			}
+
+		} else {
+			pc.hostPolicies[value.Name] = &value
+
+			for _, watcher := range pc.watchers {
+				if err := watcher.AddHostEndpointPolicy(&value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	// Propagate Node CHANGE event
+	_, err = nodemodel.ParseNodeFromKey(key)
+	if err == nil {
+		var value, prevValue nodemodel.Node
+
+		if err = dataChngEv.GetValue(&value); err != nil {
+			return err
+		}
+
+		if diff, err = dataChngEv.GetPrevValue(&prevValue); err != nil {
+			return err
+		}
+
+		if datasync.Delete == dataChngEv.GetChangeType() {
+			delete(pc.nodes, prevValue.Name)
+
+			for _, watcher := range pc.watchers {
+				if err := watcher.DelNode(&prevValue); err != nil {
+					return err
+				}
+			}
+
+		} else if diff {
+			delete(pc.nodes, prevValue.Name)
+			pc.nodes[value.Name] = &value
+
+			for _, watcher := range pc.watchers {
+				if err := watcher.UpdateNode(&prevValue, &value); err != nil {
+					return err
+				}
+			}
+
+		} else {
+			pc.nodes[value.Name] = &value
+
+			for _, watcher := range pc.watchers {
+				if err := watcher.AddNode(&value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
 	// Propagate Pod CHANGE event
 	podName, podNs, err := podmodel.ParsePodFromKey(key)
 	if err == nil {
@@ -19,7 +19,9 @@ package cache
 import (
 	"github.com/ligato/cn-infra/datasync"
 
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
 	namespacemodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	nodemodel "github.com/contiv/vpp/plugins/ksr/model/node"
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 
@@ -31,6 +33,10 @@ type DataResyncEvent struct {
 	Namespaces []*namespacemodel.Namespace
 	Pods       []*podmodel.Pod
 	Policies   []*policymodel.Policy
+
+	ClusterPolicies      []*clusterpolicymodel.ClusterPolicy
+	HostEndpointPolicies []*clusterpolicymodel.HostEndpointPolicy
+	Nodes                []*nodemodel.Node
 }
 
 // NewDataResyncEvent creates an empty instance of DataResyncEvent.
@@ -39,6 +45,10 @@ func NewDataResyncEvent() *DataResyncEvent {
 		Namespaces: []*namespacemodel.Namespace{},
 		Pods:       []*podmodel.Pod{},
 		Policies:   []*policymodel.Policy{},
+
+		ClusterPolicies:      []*clusterpolicymodel.ClusterPolicy{},
+		HostEndpointPolicies: []*clusterpolicymodel.HostEndpointPolicy{},
+		Nodes:                []*nodemodel.Node{},
 	}
 }
 
@@ -47,8 +57,14 @@ func (pc *PolicyCache) resyncParseEvent(resyncEv datasync.ResyncEvent) *DataResy
 	var numNs int
 	var numPolicy int
 	var numPod int
+	var numClusterPolicy int
+	var numHostPolicy int
+	var numNode int
 
 	event := NewDataResyncEvent()
+	pc.clusterPolicies = make(map[string]*clusterpolicymodel.ClusterPolicy)
+	pc.hostPolicies = make(map[string]*clusterpolicymodel.HostEndpointPolicy)
+	pc.nodes = make(map[string]*nodemodel.Node)
 
 	for key, resyncData := range resyncEv.GetValues() {
 		pc.Log.Debug("Received RESYNC key ", key)
@@ -89,6 +105,45 @@ func (pc *PolicyCache) resyncParseEvent(resyncEv datasync.ResyncEvent) *DataResy
 				continue
 			}
 
+			// Parse cluster policy RESYNC event
+			_, err = clusterpolicymodel.ParseClusterPolicyFromKey(key)
+			if err == nil {
+				value := &clusterpolicymodel.ClusterPolicy{}
+				err = evData.GetValue(value)
+				if err == nil {
+					event.ClusterPolicies = append(event.ClusterPolicies, value)
+					pc.clusterPolicies[value.Name] = value
+					numClusterPolicy++
+				}
+				continue
+			}
+
+			// Parse host-endpoint policy RESYNC event
+			_, err = clusterpolicymodel.ParseHostEndpointPolicyFromKey(key)
+			if err == nil {
+				value := &clusterpolicymodel.HostEndpointPolicy{}
+				err = evData.GetValue(value)
+				if err == nil {
+					event.HostEndpointPolicies = append(event.HostEndpointPolicies, value)
+					pc.hostPolicies[value.Name] = value
+					numHostPolicy++
+				}
+				continue
+			}
+
+			// Parse node RESYNC event
+			_, err = nodemodel.ParseNodeFromKey(key)
+			if err == nil {
+				value := &nodemodel.Node{}
+				err = evData.GetValue(value)
+				if err == nil {
+					event.Nodes = append(event.Nodes, value)
+					pc.nodes[value.Name] = value
+					numNode++
+				}
+				continue
+			}
+
 			// Parse namespace RESYNC event
 			_, err = namespacemodel.ParseNamespaceFromKey(key)
 			if err == nil {
@@ -106,9 +161,12 @@ func (pc *PolicyCache) resyncParseEvent(resyncEv datasync.ResyncEvent) *DataResy
 	}
 
 	pc.Log.WithFields(logging.Fields{
-		"num-policies": numPolicy,
-		"num-pods":     numPod,
-		"num-ns":       numNs,
+		"num-policies":         numPolicy,
+		"num-pods":             numPod,
+		"num-ns":               numNs,
+		"num-cluster-policies": numClusterPolicy,
+		"num-host-policies":    numHostPolicy,
+		"num-nodes":            numNode,
 	}).Debug("Parsed RESYNC event")
 
 	return event
@@ -26,6 +26,21 @@ const (
 	policyIngressLabelKey = "policyIngressLabelKey"
 	policyEgressLabelKey  = "policyEgressLabelKey"
 	policyPodNSLabelKey   = "policyPodNSLabelKey"
+	policyNamespaceKey    = "policyNamespaceKey"
+	policyIngressPeerKey  = "policyIngressPeerKey"
+	policyEgressPeerKey   = "policyEgressPeerKey"
+)
+
+const (
+	// anyPeer is the value of the peer indexes for policies which may reference
+	// any pod (empty or negative-only peer selectors).
+	anyPeer = "*"
+	// podPeerPrefix prefixes the values of the peer index for pod selectors
+	// (<prefix><policy-namespace>/<label-key>).
+	podPeerPrefix = "pod/"
+	// nsPeerPrefix prefixes the values of the peer index for namespace selectors
+	// (<prefix><label-key>).
+	nsPeerPrefix = "ns/"
 )
 
 // ConfigIndex implements a cache for configured policies. Primary index is policyID.
@@ -75,6 +90,56 @@ func (ci *ConfigIndex) LookupPolicyByNSLabelSelector(policyNSLabelSelector strin
 	return ci.mapping.ListNames(policyPodNSLabelKey, policyNSLabelSelector)
 }
 
+// LookupPolicyByNamespace performs lookup based on secondary index policyNamespace.
+func (ci *ConfigIndex) LookupPolicyByNamespace(namespace string) (policyIDs []string) {
+	return ci.mapping.ListNames(policyNamespaceKey, namespace)
+}
+
+// LookupPolicyByPodPeer performs lookup based on secondary indexes policyIngressPeer
+// and policyEgressPeer.
+// Returned is a superset of policies which may reference a pod from the given
+// namespace with the given label keys (and namespace label keys) as a peer.
+func (ci *ConfigIndex) LookupPolicyByPodPeer(podNamespace string, podLabelKeys, nsLabelKeys []string) (policyIDs []string) {
+	peerKeys := []string{anyPeer}
+	for _, key := range podLabelKeys {
+		peerKeys = append(peerKeys, podPeerPrefix+podNamespace+"/"+key)
+	}
+	for _, key := range nsLabelKeys {
+		peerKeys = append(peerKeys, nsPeerPrefix+key)
+	}
+	return ci.lookupPolicyByPeerKeys(peerKeys)
+}
+
+// LookupPolicyByNamespacePeer performs lookup based on secondary indexes
+// policyIngressPeer and policyEgressPeer.
+// Returned is a superset of policies which may reference (all pods of) a namespace
+// with the given label keys as a peer.
+func (ci *ConfigIndex) LookupPolicyByNamespacePeer(nsLabelKeys []string) (policyIDs []string) {
+	peerKeys := []string{anyPeer}
+	for _, key := range nsLabelKeys {
+		peerKeys = append(peerKeys, nsPeerPrefix+key)
+	}
+	return ci.lookupPolicyByPeerKeys(peerKeys)
+}
+
+// lookupPolicyByPeerKeys returns the union of policies indexed under any
+// of the given peer keys in either of the peer indexes.
+func (ci *ConfigIndex) lookupPolicyByPeerKeys(peerKeys []string) (policyIDs []string) {
+	found := make(map[string]struct{})
+	for _, peerIndex := range []string{policyIngressPeerKey, policyEgressPeerKey} {
+		for _, peerKey := range peerKeys {
+			for _, policyID := range ci.mapping.ListNames(peerIndex, peerKey) {
+				if _, duplicate := found[policyID]; duplicate {
+					continue
+				}
+				found[policyID] = struct{}{}
+				policyIDs = append(policyIDs, policyID)
+			}
+		}
+	}
+	return policyIDs
+}
+
 // ListAll returns all registered names in the mapping.
 func (ci *ConfigIndex) ListAll() (policyIDs []string) {
 	return ci.mapping.ListAllNames()
@@ -95,8 +160,69 @@ func IndexFunction(data interface{}) map[string][]string {
 		}
 		res[policyPodLabelKey] = policyPodLabels
 		res[policyPodNSLabelKey] = policyPodNSLabels
-
+		res[policyNamespaceKey] = []string{config.Namespace}
+		res[policyIngressPeerKey] = ingressPeerKeys(config)
+		res[policyEgressPeerKey] = egressPeerKeys(config)
 	}
 
 	return res
 }
+
+// ingressPeerKeys returns the keys of the ingress peer index for a given policy.
+func ingressPeerKeys(policy *policymodel.Policy) (keys []string) {
+	var peers []*policymodel.Policy_Peer
+	for _, ingress := range policy.IngressRule {
+		peers = append(peers, ingress.From...)
+	}
+	return peerKeys(policy.Namespace, peers)
+}
+
+// egressPeerKeys returns the keys of the egress peer index for a given policy.
+func egressPeerKeys(policy *policymodel.Policy) (keys []string) {
+	var peers []*policymodel.Policy_Peer
+	for _, egress := range policy.EgressRule {
+		peers = append(peers, egress.To...)
+	}
+	return peerKeys(policy.Namespace, peers)
+}
+
+// peerKeys returns the keys of a peer index for the given peers of a policy
+// from the given namespace. Selectors are indexed by the keys of the labels
+// which must be present for the selector to match (labels and In/Exists
+// expressions). A direction without rules references no peers.
+func peerKeys(policyNamespace string, peers []*policymodel.Policy_Peer) (keys []string) {
+	for _, peer := range peers {
+		var selectorKeys []string
+		var prefix string
+		if peer.Pods != nil {
+			selectorKeys = requiredLabelKeys(peer.Pods)
+			prefix = podPeerPrefix + policyNamespace + "/"
+		} else if peer.Namespaces != nil {
+			selectorKeys = requiredLabelKeys(peer.Namespaces)
+			prefix = nsPeerPrefix
+		} else {
+			continue
+		}
+		if len(selectorKeys) == 0 {
+			return []string{anyPeer}
+		}
+		for _, key := range selectorKeys {
+			keys = append(keys, prefix+key)
+		}
+	}
+	return keys
+}
+
+// requiredLabelKeys returns keys of labels required by the selector to match.
+func requiredLabelKeys(selector *policymodel.Policy_LabelSelector) (keys []string) {
+	for _, label := range selector.MatchLabel {
+		keys = append(keys, label.Key)
+	}
+	for _, expr := range selector.MatchExpression {
+		if expr.Operator == policymodel.Policy_LabelSelector_LabelExpression_IN ||
+			expr.Operator == policymodel.Policy_LabelSelector_LabelExpression_EXISTS {
+			keys = append(keys, expr.Key)
+		}
+	}
+	return keys
+}
@@ -15,6 +15,7 @@
 package policyidx
 
 import (
+	"fmt"
 	"testing"
 
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
@@ -212,3 +213,177 @@ func TestSecondaryIndexLookup(t *testing.T) {
 	gomega.Expect(labelMatch).To(gomega.ContainElement(policyIDfive))
 
 }
+
+func TestPeerIndexLookup(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	idx := NewConfigIndex(logrus.DefaultLogger(), "title")
+	gomega.Expect(idx).NotTo(gomega.BeNil())
+
+	const (
+		policyIDone   = "default/allow-from-frontend"
+		policyIDtwo   = "default/allow-from-monitoring-ns"
+		policyIDthree = "default/deny-all"
+		policyIDfour  = "other/allow-from-not-test"
+		policyIDfive  = "other/allow-from-frontend"
+		policyIDsix   = "default/allow-from-backend-only"
+	)
+
+	podSelector := func(key, value string) *policymodel.Policy_LabelSelector {
+		return &policymodel.Policy_LabelSelector{
+			MatchLabel: []*policymodel.Policy_Label{{Key: key, Value: value}},
+		}
+	}
+	allowAllEgress := []*policymodel.Policy_EgressRule{{}}
+
+	// Selects peers by pod label "role".
+	policyDataOne := &policymodel.Policy{
+		Name:      "allow-from-frontend",
+		Namespace: "default",
+		Pods:      podSelector("app", "db"),
+		IngressRule: []*policymodel.Policy_IngressRule{
+			{From: []*policymodel.Policy_Peer{{Pods: podSelector("role", "frontend")}}},
+		},
+		EgressRule: allowAllEgress,
+	}
+
+	// Selects peers by namespace label "team" (using expression).
+	policyDataTwo := &policymodel.Policy{
+		Name:      "allow-from-monitoring-ns",
+		Namespace: "default",
+		Pods:      podSelector("app", "db"),
+		IngressRule: []*policymodel.Policy_IngressRule{
+			{From: []*policymodel.Policy_Peer{{Namespaces: &policymodel.Policy_LabelSelector{
+				MatchExpression: []*policymodel.Policy_LabelSelector_LabelExpression{
+					{
+						Key:      "team",
+						Operator: policymodel.Policy_LabelSelector_LabelExpression_IN,
+						Value:    []string{"monitoring"},
+					},
+				},
+			}}}},
+		},
+		EgressRule: allowAllEgress,
+	}
+
+	// No rules - references no peers.
+	policyDataThree := &policymodel.Policy{
+		Name:      "deny-all",
+		Namespace: "default",
+		Pods:      podSelector("app", "web"),
+	}
+
+	// Negative-only expression - may reference any pod.
+	policyDataFour := &policymodel.Policy{
+		Name:      "allow-from-not-test",
+		Namespace: "other",
+		Pods:      podSelector("app", "web"),
+		IngressRule: []*policymodel.Policy_IngressRule{
+			{From: []*policymodel.Policy_Peer{{Pods: &policymodel.Policy_LabelSelector{
+				MatchExpression: []*policymodel.Policy_LabelSelector_LabelExpression{
+					{
+						Key:      "test",
+						Operator: policymodel.Policy_LabelSelector_LabelExpression_DOES_NOT_EXIST,
+					},
+				},
+			}}}},
+		},
+		EgressRule: allowAllEgress,
+	}
+
+	// Pod selector of a policy from another namespace.
+	policyDataFive := &policymodel.Policy{
+		Name:      "allow-from-frontend",
+		Namespace: "other",
+		Pods:      podSelector("app", "db"),
+		IngressRule: []*policymodel.Policy_IngressRule{
+			{From: []*policymodel.Policy_Peer{{Pods: podSelector("role", "frontend")}}},
+		},
+		EgressRule: allowAllEgress,
+	}
+
+	// Ingress-only policy (no egress rules) selecting peers by pod label "tier".
+	policyDataSix := &policymodel.Policy{
+		Name:      "allow-from-backend-only",
+		Namespace: "default",
+		Pods:      podSelector("app", "cache"),
+		IngressRule: []*policymodel.Policy_IngressRule{
+			{From: []*policymodel.Policy_Peer{{Pods: podSelector("tier", "backend")}}},
+		},
+	}
+
+	idx.RegisterPolicy(policyIDone, policyDataOne)
+	idx.RegisterPolicy(policyIDtwo, policyDataTwo)
+	idx.RegisterPolicy(policyIDthree, policyDataThree)
+	idx.RegisterPolicy(policyIDfour, policyDataFour)
+	idx.RegisterPolicy(policyIDfive, policyDataFive)
+	idx.RegisterPolicy(policyIDsix, policyDataSix)
+
+	byNamespace := idx.LookupPolicyByNamespace("default")
+	gomega.Expect(byNamespace).To(gomega.ConsistOf(policyIDone, policyIDtwo, policyIDthree, policyIDsix))
+	byNamespace = idx.LookupPolicyByNamespace("other")
+	gomega.Expect(byNamespace).To(gomega.ConsistOf(policyIDfour, policyIDfive))
+
+	// Pod from "default" with label "role".
+	byPodPeer := idx.LookupPolicyByPodPeer("default", []string{"role"}, nil)
+	gomega.Expect(byPodPeer).To(gomega.ConsistOf(policyIDone, policyIDfour))
+
+	// Pod from "other" with label "role", namespace labeled with "team".
+	byPodPeer = idx.LookupPolicyByPodPeer("other", []string{"role"}, []string{"team"})
+	gomega.Expect(byPodPeer).To(gomega.ConsistOf(policyIDtwo, policyIDfour, policyIDfive))
+
+	// Pod from "default" with label "tier" - ingress-only policy.
+	byPodPeer = idx.LookupPolicyByPodPeer("default", []string{"tier"}, nil)
+	gomega.Expect(byPodPeer).To(gomega.ConsistOf(policyIDfour, policyIDsix))
+
+	// Pod without labels.
+	byPodPeer = idx.LookupPolicyByPodPeer("default", nil, nil)
+	gomega.Expect(byPodPeer).To(gomega.ConsistOf(policyIDfour))
+
+	// Namespace with and without the label "team".
+	byNsPeer := idx.LookupPolicyByNamespacePeer([]string{"team", "env"})
+	gomega.Expect(byNsPeer).To(gomega.ConsistOf(policyIDtwo, policyIDfour))
+	byNsPeer = idx.LookupPolicyByNamespacePeer([]string{"env"})
+	gomega.Expect(byNsPeer).To(gomega.ConsistOf(policyIDfour))
+
+	// Unregistered policy is removed from the indexes.
+	idx.UnregisterPolicy(policyIDfour)
+	byNsPeer = idx.LookupPolicyByNamespacePeer(nil)
+	gomega.Expect(byNsPeer).To(gomega.BeEmpty())
+}
+
+// BenchmarkLookupPolicyByPodPeer measures the lookup of policies referencing
+// a pod among many single-direction (ingress-only and egress-only) policies,
+// each selecting peers by a different label.
+func BenchmarkLookupPolicyByPodPeer(b *testing.B) {
+	const numOfPolicies = 1000
+
+	idx := NewConfigIndex(logrus.DefaultLogger(), "title")
+	for i := 0; i < numOfPolicies; i++ {
+		peers := []*policymodel.Policy_Peer{{Pods: &policymodel.Policy_LabelSelector{
+			MatchLabel: []*policymodel.Policy_Label{{Key: fmt.Sprintf("peer-%d", i), Value: "true"}},
+		}}}
+		policy := &policymodel.Policy{
+			Name:      fmt.Sprintf("policy-%d", i),
+			Namespace: "default",
+			Pods: &policymodel.Policy_LabelSelector{
+				MatchLabel: []*policymodel.Policy_Label{{Key: "app", Value: fmt.Sprintf("app-%d", i)}},
+			},
+		}
+		if i%2 == 0 {
+			policy.IngressRule = []*policymodel.Policy_IngressRule{{From: peers}}
+		} else {
+			policy.EgressRule = []*policymodel.Policy_EgressRule{{To: peers}}
+		}
+		idx.RegisterPolicy(policymodel.GetID(policy).String(), policy)
+	}
+
+	// The pod is referenced by one ingress-only and one egress-only policy.
+	podLabelKeys := []string{"peer-10", "peer-11"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if policies := idx.LookupPolicyByPodPeer("default", podLabelKeys, nil); len(policies) != 2 {
+			b.Fatalf("expected 2 candidate policies, got %d", len(policies))
+		}
+	}
+}
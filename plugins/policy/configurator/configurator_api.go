@@ -23,6 +23,7 @@ import (
 
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
 	"github.com/contiv/vpp/plugins/policy/renderer"
 )
 
@@ -60,10 +61,30 @@ type Txn interface {
 	// The order of policies is not important (it is a set).
 	Configure(pod podmodel.ID, policies []*ContivPolicy) Txn
 
+	// ConfigureHost applies the set of host-endpoint policies for the traffic
+	// entering the host network stack of this node. The existing host policies
+	// are replaced. Empty set of policies allows all the traffic.
+	ConfigureHost(policies []*ContivPolicy) Txn
+
+	// ConfigureLoadBalancers applies the access restrictions of services
+	// of type LoadBalancer. The existing restrictions are replaced.
+	// Empty set of load-balancers allows all the traffic.
+	ConfigureLoadBalancers(loadBalancers []*LoadBalancer) Txn
+
 	// Commit proceeds with the reconfiguration.
 	Commit() error
 }
 
+// PolicyStatus is the rendering status of a policy on this node, as returned
+// by PolicyConfigurator.GetPolicyStatus().
+type PolicyStatus struct {
+	Policy       policymodel.ID
+	ClusterWide  bool
+	SelectedPods int    // number of local pods the policy is applied to
+	Rules        int    // number of rules generated from the policy
+	Error        string // error of the last rendering of the policy, empty on success
+}
+
 // ContivPolicy is a less-abstract, free of indirect references representation
 // of K8s Network Policy.
 // It has:
@@ -75,13 +96,32 @@ type Txn interface {
 // Traffic matched by a Contiv policy should by ALLOWED. Traffic not matched
 // by any policy from a **non-empty** set of policies assigned
 // to the source/destination pod should be DENIED.
+// Cluster-wide policies are the exception: they are evaluated before
+// the K8s policies, in the order of priority, with each match allowing
+// or denying the traffic as per the match action. Traffic not matched
+// by any cluster-wide policy is passed to the K8s policies. Cluster-wide
+// policies alone do not isolate the pod.
+// K8s policies in the audit mode isolate the pod only on paper: traffic
+// they would deny is permitted and only logged (see EvaluateFlow).
 type ContivPolicy struct {
 	// ID should uniquely identify policy across all namespaces.
+	// Cluster-wide policies have empty namespace.
 	ID policymodel.ID
 
 	// Type selects the rule types that the network policy relates to.
 	Type PolicyType
 
+	// ClusterWide is true for policies defined by the ClusterPolicy CRD.
+	ClusterWide bool
+
+	// Priority orders cluster-wide policies (lower value is evaluated first).
+	// Not used for K8s policies.
+	Priority int32
+
+	// Audit is true for K8s policies in the audit mode.
+	// Not used for cluster-wide policies.
+	Audit bool
+
 	// Matches is an array of Match-es: predicates that select a subset of the
 	// traffic to be ALLOWED.
 	Matches []Match
@@ -96,6 +136,14 @@ func (cp ContivPolicy) String() string {
 			matches += ", "
 		}
 	}
+	if cp.ClusterWide {
+		return fmt.Sprintf("ContivPolicy %s <Type:%s, ClusterWide, Priority:%d, Matches:[%s]>",
+			cp.ID, cp.Type, cp.Priority, matches)
+	}
+	if cp.Audit {
+		return fmt.Sprintf("ContivPolicy %s <Type:%s, Audit, Matches:[%s]>",
+			cp.ID, cp.Type, matches)
+	}
 	return fmt.Sprintf("ContivPolicy %s <Type:%s, Matches:[%s]>",
 		cp.ID, cp.Type, matches)
 }
@@ -105,14 +153,28 @@ type Match struct {
 	// Type selects the direction of the traffic.
 	Type MatchType
 
+	// Action to apply to the matched traffic. Only cluster-wide policies
+	// may contain matches with MatchDeny.
+	Action MatchAction
+
 	// Layer 3: destinations (egress) / sources (ingress)
-	// If both arrays are nils, then this predicate matches all
+	// If all the arrays are nils, then this predicate matches all
 	// sources(ingress) / destinations(egress). Otherwise, this predicate
 	// applies to a given traffic only if the traffic matches at least one item
 	// in one of the lists.
 	Pods     []podmodel.ID
 	IPBlocks []IPBlock
 
+	// FQDNs select destinations (egress only) by domain names (exact or
+	// wildcard "*.<domain>"). The IP addresses are learned by the DNS cache
+	// and change in the runtime.
+	FQDNs []string
+
+	// Services select destinations (egress only) by K8s services, expanded
+	// by the processor into the service frontends and backends (with ports
+	// of their own, not combined with Ports).
+	Services []ServicePeer
+
 	// Layer 4: destination ports
 	// If the array is empty or nil, then this predicate matches all ports
 	// (traffic not restricted by port).
@@ -158,8 +220,115 @@ func (m Match) String() string {
 		}
 		ports += "]"
 	}
-	return fmt.Sprintf("<Type:%s, Pods:%s, Blocks:%s, Ports:%s>",
-		m.Type, pods, blocks, ports)
+	if m.Services != nil {
+		return fmt.Sprintf("<Type:%s, Action:%s, Pods:%s, Blocks:%s, FQDNs:%v, Services:%v, Ports:%s>",
+			m.Type, m.Action, pods, blocks, m.FQDNs, m.Services, ports)
+	}
+	if m.FQDNs != nil {
+		return fmt.Sprintf("<Type:%s, Action:%s, Pods:%s, Blocks:%s, FQDNs:%v, Ports:%s>",
+			m.Type, m.Action, pods, blocks, m.FQDNs, ports)
+	}
+	return fmt.Sprintf("<Type:%s, Action:%s, Pods:%s, Blocks:%s, Ports:%s>",
+		m.Type, m.Action, pods, blocks, ports)
+}
+
+// ServicePeer is a K8s service referenced by a policy rule.
+// Frontends are visible only before the service address translation (NAT),
+// backends after it.
+type ServicePeer struct {
+	// ID identifies the service.
+	ID svcmodel.ID
+
+	// Frontends are cluster and external IPs of the service combined
+	// with the service ports.
+	Frontends []ServiceAddr
+
+	// Backends are IPs of the service endpoints combined with the target ports.
+	Backends []ServiceAddr
+}
+
+// String converts ServicePeer into a human-readable string.
+func (sp ServicePeer) String() string {
+	return fmt.Sprintf("<ID:%s, Frontends:%v, Backends:%v>", sp.ID, sp.Frontends, sp.Backends)
+}
+
+// LoadBalancer is a K8s service of type LoadBalancer with access restricted
+// to a set of client subnets.
+type LoadBalancer struct {
+	// Service identifies the service.
+	Service svcmodel.ID
+
+	// Frontends are load-balancer IPs of the service combined with the service
+	// ports.
+	Frontends []ServiceAddr
+
+	// SourceRanges are client subnets allowed to access the frontends.
+	SourceRanges []*net.IPNet
+}
+
+// String converts LoadBalancer into a human-readable string.
+func (lb LoadBalancer) String() string {
+	return fmt.Sprintf("<Service:%s, Frontends:%v, SourceRanges:%v>", lb.Service, lb.Frontends, lb.SourceRanges)
+}
+
+// ServiceAddr is an IP address combined with a port of a service
+// frontend or backend.
+type ServiceAddr struct {
+	IP   net.IP
+	Port Port
+}
+
+// String converts ServiceAddr into a human-readable string.
+func (sa ServiceAddr) String() string {
+	return fmt.Sprintf("<IP:%s, Port:%s>", sa.IP, sa.Port)
+}
+
+// Flow is a connection (or a single packet) between a local pod and a peer,
+// as seen by the policy evaluation.
+type Flow struct {
+	// Direction of the flow from the pod point of view.
+	Direction MatchType
+
+	// PeerIP is the IP address of the opposite side of the flow.
+	PeerIP net.IP
+
+	// Protocol and the destination port of the flow.
+	Protocol renderer.ProtocolType
+	DestPort uint16
+
+	// ICMP type and code (Protocol=ICMP only).
+	ICMPType uint8
+	ICMPCode uint8
+}
+
+// String converts Flow into a human-readable string.
+func (f Flow) String() string {
+	if f.Protocol == renderer.ICMP {
+		return fmt.Sprintf("<Direction:%s, Peer:%s, %s:%d/%d>",
+			f.Direction, f.PeerIP, f.Protocol, f.ICMPType, f.ICMPCode)
+	}
+	return fmt.Sprintf("<Direction:%s, Peer:%s, %s:%d>",
+		f.Direction, f.PeerIP, f.Protocol, f.DestPort)
+}
+
+// FlowVerdict is the outcome of the evaluation of a flow against the policies
+// of a pod.
+type FlowVerdict struct {
+	// Denied is true if the flow is denied by the policies (with policies
+	// in the audit mode evaluated as if they were enforced).
+	Denied bool
+
+	// Audited is true if the flow is denied only by policies in the audit mode,
+	// i.e. it is actually permitted.
+	Audited bool
+
+	// Policies responsible for the verdict. For a denied flow, it is either
+	// the cluster-wide policy with the matching deny rule, or all the K8s
+	// policies isolating the pod in the direction of the flow. For a permitted
+	// flow, it is either the cluster-wide policy with the matching allow rule,
+	// or all the K8s policies with a rule matching the flow (empty if the pod
+	// is not isolated).
+	Policies []policymodel.ID
 }
 
 // PolicyType selects the rule types that the network policy relates to.
@@ -213,7 +382,29 @@ func (mt MatchType) String() string {
 	return "INVALID"
 }
 
-// ProtocolType is either TCP or UDP.
+// MatchAction is the action applied to the traffic selected by a Match.
+type MatchAction int
+
+const (
+	// MatchAllow allows the matched traffic.
+	MatchAllow MatchAction = iota
+
+	// MatchDeny blocks the matched traffic.
+	MatchDeny
+)
+
+// String converts MatchAction into a human-readable string.
+func (ma MatchAction) String() string {
+	switch ma {
+	case MatchAllow:
+		return "ALLOW"
+	case MatchDeny:
+		return "DENY"
+	}
+	return "INVALID"
+}
+
+// ProtocolType is either TCP, UDP, SCTP or ICMP.
 type ProtocolType int
 
 const (
@@ -222,6 +413,12 @@ const (
 
 	// UDP protocol.
 	UDP
+
+	// SCTP protocol.
+	SCTP
+
+	// ICMP protocol.
+	ICMP
 )
 
 // String converts ProtocolType into a human-readable string.
@@ -231,22 +428,47 @@ func (pt ProtocolType) String() string {
 		return "TCP"
 	case UDP:
 		return "UDP"
+	case SCTP:
+		return "SCTP"
+	case ICMP:
+		return "ICMP"
 	}
 	return "INVALID"
 }
 
-// Port represent a TCP or UDP port.
+// Port represent a TCP, UDP or SCTP port or a range of ports.
 // Number=0 represents all ports for a given protocol.
+// EndNumber>0 turns the port into the range Number..EndNumber (inclusive).
+// For ICMP, ports are not used and ICMPType with ICMPCode are matched instead
+// (renderer.AnyICMP matches all types/codes).
 type Port struct {
-	Protocol ProtocolType
-	Number   uint16
+	Protocol  ProtocolType
+	Number    uint16
+	EndNumber uint16
+	ICMPType  int16
+	ICMPCode  int16
 }
 
 // String return a human-readable string representation of the Port.
 func (port Port) String() string {
+	if port.Protocol == ICMP {
+		icmp := port.Protocol.String() + ":"
+		if port.ICMPType == renderer.AnyICMP {
+			return icmp + "ANY"
+		}
+		icmp += strconv.Itoa(int(port.ICMPType))
+		if port.ICMPCode != renderer.AnyICMP {
+			icmp += "/" + strconv.Itoa(int(port.ICMPCode))
+		}
+		return icmp
+	}
 	if port.Number == 0 {
 		return port.Protocol.String() + ":ANY"
 	}
+	if port.EndNumber > port.Number {
+		return port.Protocol.String() + ":" + strconv.Itoa(int(port.Number)) +
+			"-" + strconv.Itoa(int(port.EndNumber))
+	}
 	return port.Protocol.String() + ":" + strconv.Itoa(int(port.Number))
 }
 
@@ -18,13 +18,19 @@ package configurator
 
 import (
 	"net"
+	"runtime"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ligato/cn-infra/logging"
 
 	"github.com/contiv/vpp/plugins/contiv"
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	"github.com/contiv/vpp/plugins/policy/cache"
+	"github.com/contiv/vpp/plugins/policy/dnscache"
 	"github.com/contiv/vpp/plugins/policy/renderer"
 	"github.com/contiv/vpp/plugins/policy/utils"
 )
@@ -43,13 +49,28 @@ type PolicyConfigurator struct {
 	renderers         []renderer.PolicyRendererAPI
 	parallelRendering bool
 	podIPAddresses    PodIPAddresses
+	podPolicies       map[podmodel.ID]ContivPolicies /* to refresh FQDN-based rules and to resync */
+	hostPolicies      ContivPolicies                 /* to resync */
+	loadBalancers     []*LoadBalancer                /* to resync */
+	resyncChan        chan<- struct{}
+
+	// policy status
+	podPolicyStats map[podmodel.ID]*policySetStats
+	policyErrors   map[policymodel.ID]string
+	statusChan     chan<- struct{}
 }
 
+// resyncDelay is the delay between a failed commit of a renderer transaction
+// and the request to re-synchronize the renderers.
+var resyncDelay = 5 * time.Second
+
 // Deps lists dependencies of PolicyConfigurator.
 type Deps struct {
-	Log    logging.Logger
-	Cache  cache.PolicyCacheAPI
-	Contiv contiv.API /* to get the NAT-loopback IP */
+	Log      logging.Logger
+	Cache    cache.PolicyCacheAPI
+	Contiv   contiv.API           /* to get the NAT-loopback IP */
+	DNSCache dnscache.DNSCacheAPI /* to get IP addresses of FQDNs, optional */
+	Workers  int                  /* number of workers generating rules in parallel, defaults to the number of CPUs */
 }
 
 // PolicyConfiguratorTxn represents a single transaction of the policy configurator.
@@ -59,6 +80,13 @@ type PolicyConfiguratorTxn struct {
 	resync         bool
 	config         map[podmodel.ID]ContivPolicies // config to render
 	podIPAddresses PodIPAddresses
+	ignoreAudit    bool // generate rules as if no policy was in the audit mode
+
+	hostPolicies   ContivPolicies // host-endpoint policies to render
+	hostConfigured bool           // true if ConfigureHost() was called
+
+	loadBalancers []*LoadBalancer // load-balancers to render
+	lbsConfigured bool            // true if ConfigureLoadBalancers() was called
 }
 
 // ContivPolicies is a list of policies that can be ordered by policy ID.
@@ -71,19 +99,50 @@ type ProcessedPolicySet struct {
 	policies ContivPolicies // ordered
 	ingress  ContivRules
 	egress   ContivRules
+	preNAT   ContivRules
+	stats    *policySetStats
+}
+
+// policySetStats are statistics of a processed set of policies, remembered
+// for each pod to evaluate the policy status.
+type policySetStats struct {
+	key   string                 // policySetKey()
+	rules map[policymodel.ID]int // number of rules generated from each policy
+}
+
+// PodPolicyConfig is the policy configuration of a pod as dumped by DumpPodConfigs().
+// Ingress and egress rules are from the vswitch point of view.
+type PodPolicyConfig struct {
+	Pod         podmodel.ID
+	IPAddresses []*net.IPNet
+	Policies    ContivPolicies
+	Ingress     ContivRules
+	Egress      ContivRules
+}
+
+// podRenderConfig is the configuration of a single pod to render.
+type podRenderConfig struct {
+	pod       podmodel.ID
+	ips       []*net.IPNet
+	policySet *ProcessedPolicySet // nil if removed
+	removed   bool
 }
 
 // ContivRules is a list of Contiv rules.
 type ContivRules []*renderer.ContivRule
 
-// PodIPAddresses is a map used to remember IP address for each configured pod.
-type PodIPAddresses map[podmodel.ID]*net.IPNet
+// PodIPAddresses is a map used to remember IP addresses (one for each IP family)
+// of each configured pod.
+type PodIPAddresses map[podmodel.ID][]*net.IPNet
 
 // Init initializes policy configurator.
 func (pc *PolicyConfigurator) Init(parallelRendering bool) error {
 	pc.renderers = []renderer.PolicyRendererAPI{}
 	pc.parallelRendering = parallelRendering
 	pc.podIPAddresses = make(PodIPAddresses)
+	pc.podPolicies = make(map[podmodel.ID]ContivPolicies)
+	pc.podPolicyStats = make(map[podmodel.ID]*policySetStats)
+	pc.policyErrors = make(map[policymodel.ID]string)
 	return nil
 }
 
@@ -101,6 +160,299 @@ func (pc *PolicyConfigurator) Close() error {
 	return nil
 }
 
+// WatchResyncRequests registers a channel through which the configurator asks
+// (with a delay) for Resync() when a renderer failed to commit a transaction.
+// The channel should be buffered, pending requests are not repeated.
+func (pc *PolicyConfigurator) WatchResyncRequests(resyncChan chan<- struct{}) {
+	pc.resyncChan = resyncChan
+}
+
+// WatchPolicyStatus registers a channel through which the configurator notifies
+// about a (possible) change of the policy status returned by GetPolicyStatus().
+// The channel should be buffered, pending notifications are not repeated.
+func (pc *PolicyConfigurator) WatchPolicyStatus(statusChan chan<- struct{}) {
+	pc.statusChan = statusChan
+}
+
+// GetPolicyStatus returns the rendering status of all policies applied to at
+// least one pod of this node, ordered by policy ID. Rules are counted once for
+// every distinct set of policies the policy is part of (pods with the same set
+// of policies share the rules).
+func (pc *PolicyConfigurator) GetPolicyStatus() []*PolicyStatus {
+	statuses := make(map[policymodel.ID]*PolicyStatus)
+	counted := make(map[string]struct{}) // keys of policy sets with counted rules
+	for pod, policies := range pc.podPolicies {
+		for _, policy := range policies {
+			status, hasStatus := statuses[policy.ID]
+			if !hasStatus {
+				status = &PolicyStatus{
+					Policy:      policy.ID,
+					ClusterWide: policy.ClusterWide,
+					Error:       pc.policyErrors[policy.ID],
+				}
+				statuses[policy.ID] = status
+			}
+			status.SelectedPods++
+		}
+		stats, hasStats := pc.podPolicyStats[pod]
+		if !hasStats {
+			continue
+		}
+		if _, alreadyCounted := counted[stats.key]; alreadyCounted {
+			continue
+		}
+		counted[stats.key] = struct{}{}
+		for policy, rules := range stats.rules {
+			if status, hasStatus := statuses[policy]; hasStatus {
+				status.Rules += rules
+			}
+		}
+	}
+
+	sorted := make([]*PolicyStatus, 0, len(statuses))
+	for _, status := range statuses {
+		sorted = append(sorted, status)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Policy.String() < sorted[j].Policy.String()
+	})
+	return sorted
+}
+
+// notifyStatusChange notifies the status watcher (if any) about a possible
+// change of the policy status.
+func (pc *PolicyConfigurator) notifyStatusChange() {
+	if pc.statusChan == nil {
+		return
+	}
+	select {
+	case pc.statusChan <- struct{}{}:
+	default:
+		// notification already pending
+	}
+}
+
+// Resync re-renders the last configured policies of all pods with the resync
+// enabled for all renderers.
+func (pc *PolicyConfigurator) Resync() error {
+	pc.Log.Info("Re-synchronizing renderers")
+	txn := pc.NewTxn(true)
+	for pod, policies := range pc.podPolicies {
+		txn.Configure(pod, policies)
+	}
+	txn.ConfigureHost(pc.hostPolicies)
+	txn.ConfigureLoadBalancers(pc.loadBalancers)
+	return txn.Commit()
+}
+
+// requestResync asks for Resync() after resyncDelay.
+func (pc *PolicyConfigurator) requestResync() {
+	resyncChan := pc.resyncChan
+	if resyncChan == nil {
+		return
+	}
+	time.AfterFunc(resyncDelay, func() {
+		select {
+		case resyncChan <- struct{}{}:
+		default:
+			// resync already requested
+		}
+	})
+}
+
+// RefreshFQDNs re-generates rules for pods with policies referencing any of
+// the given domain names, whose IP addresses have changed in the DNS cache.
+// Policies are not re-processed and pods not referencing the names are left
+// unchanged.
+func (pc *PolicyConfigurator) RefreshFQDNs(names []string) error {
+	txn := pc.NewTxn(false)
+	refresh := false
+	for pod, policies := range pc.podPolicies {
+		if policies.referenceFQDN(names) {
+			txn.Configure(pod, policies)
+			refresh = true
+		}
+	}
+	if !refresh {
+		return nil
+	}
+	pc.Log.WithField("names", names).Debug("Refreshing rules with FQDNs")
+	return txn.Commit()
+}
+
+// DumpPodConfigs returns the current configuration of all configured pods
+// (sorted by pod ID), including the rules generated from the policies.
+func (pc *PolicyConfigurator) DumpPodConfigs() (configs []*PodPolicyConfig) {
+	txn := &PolicyConfiguratorTxn{
+		Log:          pc.Log,
+		configurator: pc,
+	}
+	processed := make(map[string]*ProcessedPolicySet) // key = policySetKey()
+	for pod, unorderedPolicies := range pc.podPolicies {
+		policies := unorderedPolicies.Copy()
+		sort.Sort(policies)
+		setKey := policies.policySetKey()
+		policySet, alreadyProcessed := processed[setKey]
+		if !alreadyProcessed {
+			policySet = &ProcessedPolicySet{
+				policies: policies,
+				egress:   txn.generateRules(MatchIngress, policies),
+				ingress:  txn.generateRules(MatchEgress, policies),
+			}
+			processed[setKey] = policySet
+		}
+		configs = append(configs, &PodPolicyConfig{
+			Pod:         pod,
+			IPAddresses: pc.podIPAddresses[pod],
+			Policies:    policies,
+			Ingress:     policySet.ingress.Copy(),
+			Egress:      policySet.egress.Copy(),
+		})
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].Pod.String() < configs[j].Pod.String()
+	})
+	return configs
+}
+
+// EvaluateFlow evaluates the flow against the policies configured for the given
+// pod, with policies in the audit mode evaluated as if they were enforced.
+// The verdict of a flow of a pod not configured by the configurator is
+// always "not denied".
+func (pc *PolicyConfigurator) EvaluateFlow(pod podmodel.ID, flow Flow) (verdict FlowVerdict) {
+	unorderedPolicies, configured := pc.podPolicies[pod]
+	if !configured {
+		return verdict
+	}
+	policies := unorderedPolicies.Copy()
+	sort.Sort(policies)
+
+	txn := &PolicyConfiguratorTxn{
+		Log:          pc.Log,
+		configurator: pc,
+		ignoreAudit:  true,
+	}
+	rule := MatchingRule(txn.generateRules(flow.Direction, policies), flow)
+	if rule == nil {
+		// The pod is not isolated in the direction of the flow.
+		return verdict
+	}
+	clusterPolicy, clusterMatch := txn.firstMatchingClusterPolicy(policies, flow)
+	if rule.Action == renderer.ActionPermit {
+		verdict.Policies = txn.permittingPolicies(policies, clusterPolicy, clusterMatch, flow)
+		return verdict
+	}
+	verdict.Denied = true
+
+	// The first matching rule of cluster-wide policies decides.
+	if clusterMatch != nil && clusterMatch.Action == MatchDeny {
+		verdict.Policies = []policymodel.ID{clusterPolicy.ID}
+		return verdict
+	}
+
+	// Denied by the isolation of the pod.
+	verdict.Audited = true
+	for _, policy := range policies {
+		if policy.ClusterWide || !policy.appliesTo(flow.Direction) {
+			continue
+		}
+		verdict.Policies = append(verdict.Policies, policy.ID)
+		if !policy.Audit {
+			verdict.Audited = false
+		}
+	}
+	return verdict
+}
+
+// firstMatchingClusterPolicy returns the cluster-wide policy with the first
+// rule matching the flow, together with the rule itself. Returns nil if no
+// cluster-wide policy matches the flow.
+func (pct *PolicyConfiguratorTxn) firstMatchingClusterPolicy(policies ContivPolicies, flow Flow) (*ContivPolicy, *Match) {
+	for _, policy := range policies {
+		if !policy.ClusterWide {
+			break
+		}
+		if !policy.appliesTo(flow.Direction) {
+			continue
+		}
+		for i := range policy.Matches {
+			match := &policy.Matches[i]
+			if match.Type != flow.Direction {
+				continue
+			}
+			matchRules, _ := pct.generateMatchRules(flow.Direction, *match, renderer.ActionDeny)
+			if MatchingRule(matchRules, flow) != nil {
+				return policy, match
+			}
+		}
+	}
+	return nil, nil
+}
+
+// permittingPolicies returns policies that permit the flow: either
+// the cluster-wide policy with the matching allow rule, or all the K8s policies
+// with a rule matching the flow.
+func (pct *PolicyConfiguratorTxn) permittingPolicies(policies ContivPolicies,
+	clusterPolicy *ContivPolicy, clusterMatch *Match, flow Flow) (permitting []policymodel.ID) {
+	if clusterMatch != nil && clusterMatch.Action == MatchAllow {
+		return []policymodel.ID{clusterPolicy.ID}
+	}
+	for _, policy := range policies {
+		if policy.ClusterWide || !policy.appliesTo(flow.Direction) {
+			continue
+		}
+		for _, match := range policy.Matches {
+			if match.Type != flow.Direction {
+				continue
+			}
+			matchRules, _ := pct.generateMatchRules(flow.Direction, match, renderer.ActionPermit)
+			if MatchingRule(matchRules, flow) != nil {
+				permitting = append(permitting, policy.ID)
+				break
+			}
+		}
+	}
+	return permitting
+}
+
+// AuditedPolicies returns the K8s policies in the audit mode isolating the pod
+// in the given direction if the traffic not permitted by any of the rules
+// of the pod is permitted only because of the audit mode, i.e. it is matched
+// by the rule permitting all the traffic which replaces the final "deny the rest"
+// rule. Returns nil if the pod is not isolated, or if it is isolated also by an
+// enforced policy or by a policy allowing all the traffic.
+func (pc *PolicyConfigurator) AuditedPolicies(pod podmodel.ID, direction MatchType) (audited []policymodel.ID) {
+	unorderedPolicies, configured := pc.podPolicies[pod]
+	if !configured {
+		return nil
+	}
+	policies := unorderedPolicies.Copy()
+	sort.Sort(policies)
+
+	txn := &PolicyConfiguratorTxn{
+		Log:          pc.Log,
+		configurator: pc,
+	}
+	for _, policy := range policies {
+		if policy.ClusterWide || !policy.appliesTo(direction) {
+			continue
+		}
+		if !policy.Audit {
+			return nil
+		}
+		for _, match := range policy.Matches {
+			if match.Type != direction {
+				continue
+			}
+			if _, allMatched := txn.generateMatchRules(direction, match, renderer.ActionPermit); allMatched {
+				return nil
+			}
+		}
+		audited = append(audited, policy.ID)
+	}
+	return audited
+}
+
 // NewTxn starts a new transaction. The re-configuration executes only after
 // Commit() is called. If <resync> is enabled, the supplied configuration will
 // completely replace the existing one, otherwise pods not mentioned in the
@@ -127,90 +479,167 @@ func (pct *PolicyConfiguratorTxn) Configure(pod podmodel.ID, policies []*ContivP
 	return pct
 }
 
+// ConfigureHost applies the set of host-endpoint policies for the traffic
+// entering the host network stack of this node. The existing host policies
+// are replaced. Empty set of policies allows all the traffic.
+func (pct *PolicyConfiguratorTxn) ConfigureHost(policies []*ContivPolicy) Txn {
+	pct.Log.WithField("policies", policies).Debug("PolicyConfigurator ConfigureHost()")
+	pct.hostPolicies = policies
+	pct.hostConfigured = true
+	return pct
+}
+
+// ConfigureLoadBalancers applies the access restrictions of services of type
+// LoadBalancer. The existing restrictions are replaced. Empty set
+// of load-balancers allows all the traffic.
+func (pct *PolicyConfiguratorTxn) ConfigureLoadBalancers(loadBalancers []*LoadBalancer) Txn {
+	pct.Log.WithField("loadBalancers", loadBalancers).Debug("PolicyConfigurator ConfigureLoadBalancers()")
+	pct.loadBalancers = loadBalancers
+	pct.lbsConfigured = true
+	return pct
+}
+
 // Commit proceeds with the reconfiguration.
+// Rules are generated for every distinct set of policies in parallel, using
+// a bounded pool of workers. Pods are then rendered in a fixed order (sorted
+// by ID) so that the outcome does not depend on the scheduling of the workers.
 func (pct *PolicyConfiguratorTxn) Commit() error {
-	// Remember processed sets of policies between iterations so that the same
-	// set will not be processed more than once.
-	processed := []ProcessedPolicySet{}
+	// Remember processed sets of policies so that the same set will not be
+	// processed more than once.
+	processed := []*ProcessedPolicySet{}
+	processedIdx := make(map[string]*ProcessedPolicySet) // key = policySetKey()
 
-	// Transactions of all registered renderers.
-	rendererTxns := []renderer.Txn{}
+	// Pods are processed in a deterministic order.
+	pods := make([]podmodel.ID, 0, len(pct.config))
+	for pod := range pct.config {
+		pods = append(pods, pod)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].String() < pods[j].String()
+	})
 
-	for pod, unorderedPolicies := range pct.config {
-		var ingress ContivRules
-		var egress ContivRules
-		var delPodConfig bool
+	// Collect the configuration of pods to render.
+	podConfigs := []podRenderConfig{}
+	for _, pod := range pods {
+		podConfig := podRenderConfig{pod: pod}
 
 		// Get target pod configuration.
-		podIPNet, hadIPAddr := pct.podIPAddresses[pod]
+		podIPNets, hadIPAddr := pct.podIPAddresses[pod]
 		found, podData := pct.configurator.Cache.LookupPod(pod)
 
 		// Handle removed pod.
 		if !found || podData.IpAddress == "" {
 			if hadIPAddr {
 				pct.Log.WithField("pod", pod).Debug("Removing policies from the pod.")
-				delPodConfig = true
+				podConfig.removed = true
+				podConfig.ips = podIPNets
 				delete(pct.podIPAddresses, pod)
-			} else {
-				/* already un-configured */
-				continue
+				podConfigs = append(podConfigs, podConfig)
 			}
+			/* else already un-configured */
+			continue
 		}
 
-		if !delPodConfig {
-			// Get pod IP address (expressed as one-host subnet).
-			podIPNet = utils.GetOneHostSubnet(podData.IpAddress)
-			if podIPNet == nil {
-				pct.Log.WithField("pod", pod).Warn("Pod has invalid IP address assigned")
-				continue
-			}
-			pct.podIPAddresses[pod] = podIPNet
+		// Get pod IP addresses (expressed as one-host subnets).
+		podConfig.ips = utils.GetOneHostSubnets(utils.GetPodIPAddresses(podData)...)
+		if len(podConfig.ips) == 0 {
+			pct.Log.WithField("pod", pod).Warn("Pod has invalid IP address assigned")
+			continue
+		}
+		pct.podIPAddresses[pod] = podConfig.ips
 
-			// Sort policies to get the same outcome for the same set.
-			policies := unorderedPolicies.Copy()
-			sort.Sort(policies)
+		// Sort policies to get the same outcome for the same set.
+		policies := pct.config[pod].Copy()
+		sort.Sort(policies)
 
-			// Check if this set was already processed.
-			alreadyProcessed := false
-			for _, policySet := range processed {
-				if policySet.policies.Equals(policies) {
-					ingress = policySet.ingress
-					egress = policySet.egress
-					alreadyProcessed = true
-				}
-			}
+		// Check if this set was already processed.
+		setKey := policies.policySetKey()
+		policySet, alreadyProcessed := processedIdx[setKey]
+		if !alreadyProcessed {
+			policySet = &ProcessedPolicySet{policies: policies}
+			processedIdx[setKey] = policySet
+			processed = append(processed, policySet)
+		}
+		podConfig.policySet = policySet
+		podConfigs = append(podConfigs, podConfig)
+	}
 
-			// Generate rules for a set of policies not yet processed.
-			if !alreadyProcessed {
-				// Direction in policies is from the pod point of view, whereas rules
-				// are evaluated from the vswitch perspective.
-				egress = pct.generateRules(MatchIngress, policies)
-				ingress = pct.generateRules(MatchEgress, policies)
-				// Remember already processed set of policies.
-				processed = append(processed,
-					ProcessedPolicySet{
-						policies: policies,
-						ingress:  ingress,
-						egress:   egress,
-					})
-			}
+	// Generate rules for all distinct sets of policies.
+	pct.generatePolicySetRules(processed)
+
+	// Host policies are always re-rendered with resync.
+	if pct.resync && !pct.hostConfigured {
+		pct.ConfigureHost(pct.configurator.hostPolicies)
+	}
+	var hostRules ContivRules
+	if pct.hostConfigured {
+		hostRules = pct.generateHostRules(pct.hostPolicies)
+	}
+
+	// The same applies to the load-balancers.
+	if pct.resync && !pct.lbsConfigured {
+		pct.ConfigureLoadBalancers(pct.configurator.loadBalancers)
+	}
+	var lbRules ContivRules
+	if pct.lbsConfigured {
+		lbRules = pct.generateLoadBalancerRules(pct.loadBalancers)
+	}
+
+	// Start transaction on every renderer.
+	rendererTxns := []renderer.Txn{}
+	if len(podConfigs) > 0 || pct.hostConfigured || pct.lbsConfigured {
+		for _, renderer := range pct.configurator.renderers {
+			rendererTxns = append(rendererTxns, renderer.NewTxn(pct.resync))
 		}
+	}
 
-		// Start transaction on every renderer if they are not running already.
-		if len(rendererTxns) == 0 {
-			for _, renderer := range pct.configurator.renderers {
-				rendererTxns = append(rendererTxns, renderer.NewTxn(pct.resync))
+	// Add rules into the transactions.
+	for _, podConfig := range podConfigs {
+		var ingress ContivRules
+		var egress ContivRules
+		var preNAT ContivRules
+		if podConfig.policySet != nil {
+			ingress = podConfig.policySet.ingress
+			egress = podConfig.policySet.egress
+			preNAT = podConfig.policySet.preNAT
+		}
+		for _, rTxn := range rendererTxns {
+			rTxn.Render(podConfig.pod, podConfig.ips, ingress.Copy(), egress.Copy(), podConfig.removed)
+			// Pre-NAT rules are rendered only by renderers supporting them.
+			if preNATTxn, supportsPreNAT := rTxn.(renderer.PreNATTxn); supportsPreNAT {
+				preNATTxn.RenderPreNAT(podConfig.pod, preNAT.Copy())
 			}
 		}
-
-		// Add rules into the transactions.
+	}
+	if pct.hostConfigured {
+		for _, rTxn := range rendererTxns {
+			// Host rules are rendered only by renderers supporting them.
+			if hostTxn, supportsHost := rTxn.(renderer.HostTxn); supportsHost {
+				hostTxn.RenderHost(hostRules.Copy())
+			}
+		}
+	}
+	if pct.lbsConfigured {
 		for _, rTxn := range rendererTxns {
-			rTxn.Render(pod, podIPNet, ingress.Copy(), egress.Copy(), delPodConfig)
+			// Load-balancer rules are rendered only by renderers supporting them.
+			if lbTxn, supportsLBs := rTxn.(renderer.LoadBalancerTxn); supportsLBs {
+				lbTxn.RenderLoadBalancers(lbRules.Copy())
+			}
 		}
 	}
 
 	// Commit all renderer transactions.
 	var wasError error
+	var commitFailed bool
+	handleErr := func(err error) {
+		if err == nil {
+			return
+		}
+		wasError = err
+		if _, isCommitErr := err.(*renderer.CommitError); isCommitErr {
+			commitFailed = true
+		}
+	}
 	rndrChan := make(chan error)
 	for _, rTxn := range rendererTxns {
 		if pct.configurator.parallelRendering {
@@ -219,27 +648,276 @@ func (pct *PolicyConfiguratorTxn) Commit() error {
 				rndrChan <- err
 			}(rTxn)
 		} else {
-			err := rTxn.Commit()
-			if err != nil {
-				wasError = err
-			}
+			handleErr(rTxn.Commit())
 		}
 	}
 	if pct.configurator.parallelRendering {
 		for i := 0; i < len(rendererTxns); i++ {
-			err := <-rndrChan
-			if err != nil {
-				wasError = err
-			}
+			handleErr(<-rndrChan)
 		}
 	}
+	if commitFailed {
+		// The failed renderer has reverted the transaction, the configuration
+		// recorded below will be rendered again by Resync().
+		pct.Log.WithField("err", wasError).Warn("Renderer failed to commit transaction, requesting resync")
+		pct.configurator.requestResync()
+	}
 
 	// Save changes to the configurator.
 	pct.configurator.podIPAddresses = pct.podIPAddresses.Copy()
+	if pct.resync {
+		pct.configurator.podPolicies = make(map[podmodel.ID]ContivPolicies)
+	}
+	for pod, policies := range pct.config {
+		if _, configured := pct.podIPAddresses[pod]; configured {
+			pct.configurator.podPolicies[pod] = policies
+		} else {
+			delete(pct.configurator.podPolicies, pod)
+		}
+	}
+	if pct.hostConfigured {
+		pct.configurator.hostPolicies = pct.hostPolicies
+	}
+	if pct.lbsConfigured {
+		pct.configurator.loadBalancers = pct.loadBalancers
+	}
+	pct.configurator.trackFQDNs()
+	pct.updatePolicyStatus(podConfigs, wasError)
 
 	return wasError
 }
 
+// generatePolicySetRules generates ingress and egress rules for the given sets
+// of policies. The sets are distributed between a bounded number of workers,
+// each set is processed by exactly one of them.
+func (pct *PolicyConfiguratorTxn) generatePolicySetRules(policySets []*ProcessedPolicySet) {
+	workers := pct.configurator.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(policySets) {
+		workers = len(policySets)
+	}
+
+	var wg sync.WaitGroup
+	policySetCh := make(chan *ProcessedPolicySet, len(policySets))
+	for _, policySet := range policySets {
+		policySetCh <- policySet
+	}
+	close(policySetCh)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for policySet := range policySetCh {
+				// Direction in policies is from the pod point of view, whereas rules
+				// are evaluated from the vswitch perspective.
+				policySet.egress = pct.generateRules(MatchIngress, policySet.policies)
+				policySet.ingress = pct.generateRules(MatchEgress, policySet.policies)
+				policySet.preNAT = pct.generatePreNATRules(policySet.policies)
+				policySet.stats = newPolicySetStats(policySet)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// updatePolicyStatus saves statistics of the rendered pods and the result
+// of the rendering into the configurator and notifies the status watcher.
+// Rendering error is attributed to all policies of the rendered pods, it is
+// cleared by the next successful rendering of the policy (e.g. by the resync).
+func (pct *PolicyConfiguratorTxn) updatePolicyStatus(podConfigs []podRenderConfig, renderErr error) {
+	if pct.resync {
+		pct.configurator.podPolicyStats = make(map[podmodel.ID]*policySetStats)
+		if renderErr == nil {
+			pct.configurator.policyErrors = make(map[policymodel.ID]string)
+		}
+	}
+	for _, podConfig := range podConfigs {
+		if podConfig.policySet == nil {
+			delete(pct.configurator.podPolicyStats, podConfig.pod)
+			continue
+		}
+		pct.configurator.podPolicyStats[podConfig.pod] = podConfig.policySet.stats
+		for _, policy := range podConfig.policySet.policies {
+			if renderErr != nil {
+				pct.configurator.policyErrors[policy.ID] = renderErr.Error()
+			} else {
+				delete(pct.configurator.policyErrors, policy.ID)
+			}
+		}
+	}
+	for pod := range pct.config {
+		if _, configured := pct.podIPAddresses[pod]; !configured {
+			delete(pct.configurator.podPolicyStats, pod)
+		}
+	}
+	if len(podConfigs) > 0 || pct.resync {
+		pct.configurator.notifyStatusChange()
+	}
+}
+
+// newPolicySetStats counts the rules generated for the given set of policies
+// by the policy they were generated from.
+func newPolicySetStats(policySet *ProcessedPolicySet) *policySetStats {
+	stats := &policySetStats{
+		key:   policySet.policies.policySetKey(),
+		rules: make(map[policymodel.ID]int),
+	}
+	for _, rules := range []ContivRules{policySet.ingress, policySet.egress, policySet.preNAT} {
+		for _, rule := range rules {
+			for _, policy := range rule.Policies {
+				stats.rules[policy]++
+			}
+		}
+	}
+	return stats
+}
+
+// generateHostRules generates the list of rules for the traffic entering the host
+// network stack, implementing the given host-endpoint policies. The policies
+// are evaluated in the order of priority, each match allowing or denying
+// the traffic as per the match action. Traffic not matched by any of the policies
+// is denied. For an empty set of policies an empty list is returned (the host
+// is not isolated).
+func (pct *PolicyConfiguratorTxn) generateHostRules(unorderedPolicies ContivPolicies) ContivRules {
+	if len(unorderedPolicies) == 0 {
+		return ContivRules{}
+	}
+	policies := unorderedPolicies.Copy()
+	sort.Sort(policies)
+
+	rules := ContivRules{}
+	isolating := []policymodel.ID{}
+	for _, policy := range policies {
+		isolating = append(isolating, policy.ID)
+		for _, match := range policy.Matches {
+			if match.Type != MatchIngress {
+				continue
+			}
+			action := renderer.ActionPermit
+			if match.Action == MatchDeny {
+				action = renderer.ActionDeny
+			}
+			matchRules, _ := pct.generateMatchRules(MatchIngress, match, action)
+			setRulePolicies(matchRules, policy.ID)
+			rules = append(rules, matchRules...)
+		}
+	}
+
+	// Deny the rest.
+	ruleNone := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: &net.IPNet{},
+		Protocol:    renderer.ANY,
+		SrcPort:     0,
+		DestPort:    0,
+		Policies:    isolating,
+	}
+	rules = append(rules, ruleNone)
+	return resolveRulePrecedence(rules)
+}
+
+// generatePreNATRules generates the list of rules for the traffic sent by pods
+// with the given policies, evaluated before the service address translation
+// (i.e. with service frontends as destinations). The rules are generated only
+// from the service peers of cluster-wide policies, in the order of priority.
+// Traffic not matched by any of the rules is allowed (it is still subject to
+// the rules evaluated after NAT, including the rules for service backends).
+// If there are no service peers, an empty list is returned.
+func (pct *PolicyConfiguratorTxn) generatePreNATRules(policies ContivPolicies) ContivRules {
+	rules := ContivRules{}
+	for _, policy := range policies {
+		if !policy.ClusterWide || !policy.appliesTo(MatchEgress) {
+			continue
+		}
+		for _, match := range policy.Matches {
+			if match.Type != MatchEgress || len(match.Services) == 0 {
+				continue
+			}
+			action := renderer.ActionPermit
+			if match.Action == MatchDeny {
+				action = renderer.ActionDeny
+			}
+			matchRules := ContivRules{}
+			for _, service := range match.Services {
+				for _, frontend := range service.Frontends {
+					matchRules = pct.appendRules(matchRules, serviceAddrRule(frontend, action))
+				}
+			}
+			setRulePolicies(matchRules, policy.ID)
+			rules = append(rules, matchRules...)
+		}
+	}
+	if len(rules) == 0 {
+		return rules
+	}
+
+	// Allow the rest.
+	ruleAll := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: &net.IPNet{},
+		Protocol:    renderer.ANY,
+		SrcPort:     0,
+		DestPort:    0,
+	}
+	rules = append(rules, ruleAll)
+	return resolveRulePrecedence(rules)
+}
+
+// generateLoadBalancerRules generates the list of rules restricting access
+// to the frontends of the given load-balancers. Traffic destined to a frontend
+// is permitted only from the source ranges of the same IP family. Traffic
+// not matched by any of the rules (i.e. destined elsewhere or to load-balancers
+// without source ranges) is allowed.
+// If there are no source ranges, an empty list is returned.
+func (pct *PolicyConfiguratorTxn) generateLoadBalancerRules(unorderedLBs []*LoadBalancer) ContivRules {
+	loadBalancers := append([]*LoadBalancer{}, unorderedLBs...)
+	sort.Slice(loadBalancers, func(i, j int) bool {
+		return loadBalancers[i].Service.String() < loadBalancers[j].Service.String()
+	})
+
+	rules := ContivRules{}
+	for _, lb := range loadBalancers {
+		if len(lb.SourceRanges) == 0 {
+			continue
+		}
+		for _, frontend := range lb.Frontends {
+			isIPv4 := frontend.IP.To4() != nil
+			for _, sourceRange := range lb.SourceRanges {
+				if (sourceRange.IP.To4() != nil) != isIPv4 {
+					continue
+				}
+				rule := serviceAddrRule(frontend, renderer.ActionPermit)
+				rule.SrcNetwork = sourceRange
+				rules = pct.appendRules(rules, rule)
+			}
+			rules = pct.appendRules(rules, serviceAddrRule(frontend, renderer.ActionDeny))
+		}
+	}
+	if len(rules) == 0 {
+		return rules
+	}
+	return resolveRulePrecedence(rules)
+}
+
+// serviceAddrRule returns rule matching the traffic destined to the given
+// service frontend or backend.
+func serviceAddrRule(addr ServiceAddr, action renderer.ActionType) *renderer.ContivRule {
+	rule := &renderer.ContivRule{
+		Action:      action,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: utils.GetOneHostSubnetFromIP(addr.IP),
+		SrcPort:     0,
+		DestPort:    addr.Port.Number,
+		DestPortEnd: addr.Port.EndNumber,
+	}
+	setRuleProtocol(rule, addr.Port)
+	return rule
+}
+
 // PeerPod represents the opposite pod in the policy rule.
 type PeerPod struct {
 	ID    podmodel.ID
@@ -247,188 +925,66 @@ type PeerPod struct {
 }
 
 // Generate a list of ingress or egress rules implementing a given list of policies.
+// Rules of cluster-wide policies take precedence over the rules of K8s policies,
+// see resolveClusterRulePrecedence().
 func (pct *PolicyConfiguratorTxn) generateRules(direction MatchType, policies ContivPolicies) ContivRules {
 	rules := ContivRules{}
-	hasPolicy := false
+	clusterRules := ContivRules{}
+	isolating := []policymodel.ID{}
+	enforced := false
 	allAllowed := false
 
 	for _, policy := range policies {
-		if (policy.Type == PolicyIngress && direction == MatchEgress) ||
-			(policy.Type == PolicyEgress && direction == MatchIngress) {
-			// Policy does not apply to this direction.
+		if !policy.appliesTo(direction) {
 			continue
 		}
-		hasPolicy = true
+		if !policy.ClusterWide {
+			// Only K8s policies isolate the pod.
+			isolating = append(isolating, policy.ID)
+			if !policy.Audit || pct.ignoreAudit {
+				enforced = true
+			}
+		}
 
 		for _, match := range policy.Matches {
 			if match.Type != direction {
 				continue
 			}
-
-			// Collect IP addresses of all pod peers.
-			peers := []PeerPod{}
-			for _, peer := range match.Pods {
-				found, peerData := pct.configurator.Cache.LookupPod(peer)
-				if !found {
-					pct.Log.WithField("peer", peer).Warn("Peer pod data not found in the cache")
-					continue
-				}
-				if peerData.IpAddress == "" {
-					pct.Log.WithField("peer", peer).Warn("Peer pod has no IP address assigned")
-					continue
-				}
-				peerIPNet := utils.GetOneHostSubnet(peerData.IpAddress)
-				if peerIPNet == nil {
-					pct.Log.WithFields(logging.Fields{
-						"peer": peer,
-						"ip":   peerData.IpAddress}).Warn("Peer pod has invalid IP address assigned")
-					continue
-				}
-				peers = append(peers, PeerPod{ID: peer, IPNet: peerIPNet})
-			}
-
-			// Collect all subnets from IPBlocks.
-			allSubnets := []*net.IPNet{}
-			for _, block := range match.IPBlocks {
-				subnets := []*net.IPNet{&block.Network}
-				for _, except := range block.Except {
-					subtracted := []*net.IPNet{}
-					for _, subnet := range subnets {
-						subtracted = append(subtracted, subtractSubnet(subnet, &except)...)
-					}
-					subnets = subtracted
-				}
-				allSubnets = append(allSubnets, subnets...)
-			}
-
-			// Handle undefined set of pods and IP blocks.
-			// = match anything on L3
-			if match.Pods == nil && match.IPBlocks == nil {
-				if len(match.Ports) == 0 {
-					// = match anything on L3 & L4
-					ruleAny := &renderer.ContivRule{
-						Action:      renderer.ActionPermit,
-						SrcNetwork:  &net.IPNet{},
-						DestNetwork: &net.IPNet{},
-						Protocol:    renderer.ANY,
-						SrcPort:     0,
-						DestPort:    0,
-					}
-					rules = pct.appendRules(rules, ruleAny)
-					allAllowed = true
-				} else {
-					// = match by L4
-					for _, port := range match.Ports {
-						rule := &renderer.ContivRule{
-							Action:      renderer.ActionPermit,
-							SrcNetwork:  &net.IPNet{},
-							DestNetwork: &net.IPNet{},
-							SrcPort:     0,
-							DestPort:    port.Number,
-						}
-						if port.Protocol == TCP {
-							rule.Protocol = renderer.TCP
-						} else {
-							rule.Protocol = renderer.UDP
-						}
-						rules = pct.appendRules(rules, rule)
-					}
-				}
-			}
-
-			// Combine pod peers with ports.
-			for _, peer := range peers {
-				if len(match.Ports) == 0 {
-					// Match all ports.
-					// = match by L3
-					ruleAny := &renderer.ContivRule{
-						Action:      renderer.ActionPermit,
-						Protocol:    renderer.ANY,
-						SrcNetwork:  &net.IPNet{},
-						DestNetwork: &net.IPNet{},
-						SrcPort:     0,
-						DestPort:    0,
-					}
-					if direction == MatchIngress {
-						ruleAny.SrcNetwork = peer.IPNet
-					} else {
-						ruleAny.DestNetwork = peer.IPNet
-					}
-					rules = pct.appendRules(rules, ruleAny)
-				} else {
-					// Combine each port with the peer.
-					// = match by L3 & L4
-					for _, port := range match.Ports {
-						rule := &renderer.ContivRule{
-							Action:      renderer.ActionPermit,
-							SrcNetwork:  &net.IPNet{},
-							DestNetwork: &net.IPNet{},
-							SrcPort:     0,
-							DestPort:    port.Number,
-						}
-						if direction == MatchIngress {
-							rule.SrcNetwork = peer.IPNet
-						} else {
-							rule.DestNetwork = peer.IPNet
-						}
-						if port.Protocol == TCP {
-							rule.Protocol = renderer.TCP
-						} else {
-							rule.Protocol = renderer.UDP
-						}
-						rules = pct.appendRules(rules, rule)
-					}
+			if policy.ClusterWide {
+				// Keep the order of cluster rules - it defines their precedence.
+				action := renderer.ActionPermit
+				if match.Action == MatchDeny {
+					action = renderer.ActionDeny
 				}
+				matchRules, _ := pct.generateMatchRules(direction, match, action)
+				setRulePolicies(matchRules, policy.ID)
+				clusterRules = append(clusterRules, matchRules...)
+				continue
 			}
-
-			// Combine IPBlocks with ports.
-			for _, subnet := range allSubnets {
-				if len(match.Ports) == 0 {
-					// Handle IPBlock with no ports.
-					// = match by L3
-					ruleAny := &renderer.ContivRule{
-						Action:      renderer.ActionPermit,
-						Protocol:    renderer.ANY,
-						SrcNetwork:  &net.IPNet{},
-						DestNetwork: &net.IPNet{},
-						SrcPort:     0,
-						DestPort:    0,
-					}
-					if direction == MatchIngress {
-						ruleAny.SrcNetwork = subnet
-					} else {
-						ruleAny.DestNetwork = subnet
-					}
-					rules = pct.appendRules(rules, ruleAny)
-				} else {
-					// Combine each port with the block.
-					// = match by L3 & L4
-					for _, port := range match.Ports {
-						rule := &renderer.ContivRule{
-							Action:      renderer.ActionPermit,
-							SrcNetwork:  &net.IPNet{},
-							DestNetwork: &net.IPNet{},
-							SrcPort:     0,
-							DestPort:    port.Number,
-						}
-						if direction == MatchIngress {
-							rule.SrcNetwork = subnet
-						} else {
-							rule.DestNetwork = subnet
-						}
-						if port.Protocol == TCP {
-							rule.Protocol = renderer.TCP
-						} else {
-							rule.Protocol = renderer.UDP
-						}
-						rules = pct.appendRules(rules, rule)
-					}
-				}
+			matchRules, allMatched := pct.generateMatchRules(direction, match, renderer.ActionPermit)
+			setRulePolicies(matchRules, policy.ID)
+			rules = pct.appendRules(rules, matchRules...)
+			if allMatched {
+				allAllowed = true
 			}
 		}
 	}
 
-	if hasPolicy && !allAllowed {
+	hasPolicy := len(isolating) > 0
+	if hasPolicy && !allAllowed && !enforced {
+		// Only policies in the audit mode - permit the traffic that would be
+		// denied (it is logged by the flow logger instead).
+		ruleAll := &renderer.ContivRule{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.ANY,
+			SrcPort:     0,
+			DestPort:    0,
+			Policies:    isolating,
+		}
+		rules = pct.appendRules(rules, ruleAll)
+	} else if hasPolicy && !allAllowed {
 		if direction == MatchIngress {
 			// Allow connections from the virtual NAT-loopback (access to service from itself).
 			natLoopIP := pct.configurator.Contiv.GetNatLoopbackIP()
@@ -450,24 +1006,271 @@ func (pct *PolicyConfiguratorTxn) generateRules(direction MatchType, policies Co
 			Protocol:    renderer.ANY,
 			SrcPort:     0,
 			DestPort:    0,
+			Policies:    isolating,
 		}
 		rules = pct.appendRules(rules, ruleNone)
 	}
 
+	if len(clusterRules) > 0 {
+		if !hasPolicy {
+			// Traffic not matched by cluster rules is allowed for non-isolated pods.
+			ruleAll := &renderer.ContivRule{
+				Action:      renderer.ActionPermit,
+				SrcNetwork:  &net.IPNet{},
+				DestNetwork: &net.IPNet{},
+				Protocol:    renderer.ANY,
+				SrcPort:     0,
+				DestPort:    0,
+			}
+			rules = pct.appendRules(rules, ruleAll)
+		}
+		rules = resolveClusterRulePrecedence(clusterRules, rules)
+	}
+
 	return rules
 }
 
+// generateMatchRules generates rules with the given action for a single match.
+// The returned flag is true if the match selects all the traffic.
+func (pct *PolicyConfiguratorTxn) generateMatchRules(direction MatchType, match Match,
+	action renderer.ActionType) (rules ContivRules, allMatched bool) {
+
+	// Collect IP addresses of all pod peers.
+	peers := []PeerPod{}
+	for _, peer := range match.Pods {
+		found, peerData := pct.configurator.Cache.LookupPod(peer)
+		if !found {
+			pct.Log.WithField("peer", peer).Warn("Peer pod data not found in the cache")
+			continue
+		}
+		if peerData.IpAddress == "" {
+			pct.Log.WithField("peer", peer).Warn("Peer pod has no IP address assigned")
+			continue
+		}
+		peerIPNets := utils.GetOneHostSubnets(utils.GetPodIPAddresses(peerData)...)
+		if len(peerIPNets) == 0 {
+			pct.Log.WithFields(logging.Fields{
+				"peer": peer,
+				"ip":   peerData.IpAddress}).Warn("Peer pod has invalid IP address assigned")
+			continue
+		}
+		// In dual-stack clusters the peer is matched by each of its IP addresses.
+		for _, peerIPNet := range peerIPNets {
+			peers = append(peers, PeerPod{ID: peer, IPNet: peerIPNet})
+		}
+	}
+
+	// Collect all subnets from IPBlocks.
+	allSubnets := []*net.IPNet{}
+	for _, block := range match.IPBlocks {
+		subnets := []*net.IPNet{&block.Network}
+		for _, except := range block.Except {
+			if !utils.SameIPFamily(&block.Network, &except) {
+				continue
+			}
+			subtracted := []*net.IPNet{}
+			for _, subnet := range subnets {
+				subtracted = append(subtracted, subtractSubnet(subnet, &except)...)
+			}
+			subnets = subtracted
+		}
+		allSubnets = append(allSubnets, subnets...)
+	}
+
+	// Collect IP addresses learned for FQDNs (as one-host subnets).
+	if direction == MatchEgress && pct.configurator.DNSCache != nil {
+		for _, fqdn := range match.FQDNs {
+			for _, ip := range pct.configurator.DNSCache.LookupFQDN(fqdn) {
+				allSubnets = append(allSubnets, utils.GetOneHostSubnetFromIP(ip))
+			}
+		}
+	}
+
+	// Match service backends (with their own ports).
+	// Frontends are matched only by the pre-NAT rules.
+	if direction == MatchEgress {
+		for _, service := range match.Services {
+			for _, backend := range service.Backends {
+				rules = pct.appendRules(rules, serviceAddrRule(backend, action))
+			}
+		}
+	}
+
+	// Handle undefined set of pods, IP blocks, FQDNs and services.
+	// = match anything on L3
+	if match.Pods == nil && match.IPBlocks == nil && match.FQDNs == nil && match.Services == nil {
+		if len(match.Ports) == 0 {
+			// = match anything on L3 & L4
+			ruleAny := &renderer.ContivRule{
+				Action:      action,
+				SrcNetwork:  &net.IPNet{},
+				DestNetwork: &net.IPNet{},
+				Protocol:    renderer.ANY,
+				SrcPort:     0,
+				DestPort:    0,
+			}
+			rules = pct.appendRules(rules, ruleAny)
+			allMatched = true
+		} else {
+			// = match by L4
+			for _, port := range match.Ports {
+				rule := &renderer.ContivRule{
+					Action:      action,
+					SrcNetwork:  &net.IPNet{},
+					DestNetwork: &net.IPNet{},
+					SrcPort:     0,
+					DestPort:    port.Number,
+					DestPortEnd: port.EndNumber,
+				}
+				setRuleProtocol(rule, port)
+				rules = pct.appendRules(rules, rule)
+			}
+		}
+	}
+
+	// Combine pod peers with ports.
+	for _, peer := range peers {
+		if len(match.Ports) == 0 {
+			// Match all ports.
+			// = match by L3
+			ruleAny := &renderer.ContivRule{
+				Action:      action,
+				Protocol:    renderer.ANY,
+				SrcNetwork:  &net.IPNet{},
+				DestNetwork: &net.IPNet{},
+				SrcPort:     0,
+				DestPort:    0,
+			}
+			if direction == MatchIngress {
+				ruleAny.SrcNetwork = peer.IPNet
+			} else {
+				ruleAny.DestNetwork = peer.IPNet
+			}
+			rules = pct.appendRules(rules, ruleAny)
+		} else {
+			// Combine each port with the peer.
+			// = match by L3 & L4
+			for _, port := range match.Ports {
+				rule := &renderer.ContivRule{
+					Action:      action,
+					SrcNetwork:  &net.IPNet{},
+					DestNetwork: &net.IPNet{},
+					SrcPort:     0,
+					DestPort:    port.Number,
+					DestPortEnd: port.EndNumber,
+				}
+				if direction == MatchIngress {
+					rule.SrcNetwork = peer.IPNet
+				} else {
+					rule.DestNetwork = peer.IPNet
+				}
+				setRuleProtocol(rule, port)
+				rules = pct.appendRules(rules, rule)
+			}
+		}
+	}
+
+	// Combine IPBlocks with ports.
+	for _, subnet := range allSubnets {
+		if len(match.Ports) == 0 {
+			// Handle IPBlock with no ports.
+			// = match by L3
+			ruleAny := &renderer.ContivRule{
+				Action:      action,
+				Protocol:    renderer.ANY,
+				SrcNetwork:  &net.IPNet{},
+				DestNetwork: &net.IPNet{},
+				SrcPort:     0,
+				DestPort:    0,
+			}
+			if direction == MatchIngress {
+				ruleAny.SrcNetwork = subnet
+			} else {
+				ruleAny.DestNetwork = subnet
+			}
+			rules = pct.appendRules(rules, ruleAny)
+		} else {
+			// Combine each port with the block.
+			// = match by L3 & L4
+			for _, port := range match.Ports {
+				rule := &renderer.ContivRule{
+					Action:      action,
+					SrcNetwork:  &net.IPNet{},
+					DestNetwork: &net.IPNet{},
+					SrcPort:     0,
+					DestPort:    port.Number,
+					DestPortEnd: port.EndNumber,
+				}
+				if direction == MatchIngress {
+					rule.SrcNetwork = subnet
+				} else {
+					rule.DestNetwork = subnet
+				}
+				setRuleProtocol(rule, port)
+				rules = pct.appendRules(rules, rule)
+			}
+		}
+	}
+
+	return rules, allMatched
+}
+
+// setRuleProtocol sets the protocol of the rule together with the protocol-specific
+// match (ports or ICMP type and code) selected by <port>.
+func setRuleProtocol(rule *renderer.ContivRule, port Port) {
+	switch port.Protocol {
+	case TCP:
+		rule.Protocol = renderer.TCP
+	case UDP:
+		rule.Protocol = renderer.UDP
+	case SCTP:
+		rule.Protocol = renderer.SCTP
+	case ICMP:
+		rule.Protocol = renderer.ICMP
+		rule.DestPort = 0
+		rule.DestPortEnd = 0
+		rule.ICMPType = port.ICMPType
+		rule.ICMPCode = port.ICMPCode
+	}
+}
+
 // Append rule into the list if it is not there already.
 func (pct *PolicyConfiguratorTxn) appendRule(rules []*renderer.ContivRule, newRule *renderer.ContivRule) []*renderer.ContivRule {
 	for _, rule := range rules {
 		if rule.Compare(newRule) == 0 {
 			pct.Log.WithField("rule", newRule).Debug("Skipping duplicate rule")
+			rule.Policies = mergePolicies(rule.Policies, newRule.Policies)
 			return rules
 		}
 	}
 	return append(rules, newRule)
 }
 
+// setRulePolicies sets the policy the rules were generated from.
+func setRulePolicies(rules ContivRules, policy policymodel.ID) {
+	for _, rule := range rules {
+		rule.Policies = []policymodel.ID{policy}
+	}
+}
+
+// mergePolicies returns the union of two lists of policies (as a new list).
+func mergePolicies(policies, policies2 []policymodel.ID) []policymodel.ID {
+	merged := append([]policymodel.ID{}, policies...)
+	for _, policy := range policies2 {
+		duplicate := false
+		for _, policy2 := range merged {
+			if policy == policy2 {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			merged = append(merged, policy)
+		}
+	}
+	return merged
+}
+
 // Append rules into the list. Skip those which are already there.
 func (pct *PolicyConfiguratorTxn) appendRules(rules []*renderer.ContivRule, newRules ...*renderer.ContivRule) []*renderer.ContivRule {
 	for _, newRule := range newRules {
@@ -476,6 +1279,88 @@ func (pct *PolicyConfiguratorTxn) appendRules(rules []*renderer.ContivRule, newR
 	return rules
 }
 
+// MatchingRule returns the first rule matching the flow, with rules evaluated
+// in the order in which they are installed by the renderers.
+func MatchingRule(rules ContivRules, flow Flow) *renderer.ContivRule {
+	sorted := rules.Copy()
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Compare(sorted[j]) < 0
+	})
+	for _, rule := range sorted {
+		// Direction of rules is from the vswitch point of view.
+		peerNet := rule.DestNetwork
+		if flow.Direction == MatchIngress {
+			peerNet = rule.SrcNetwork
+		}
+		if len(peerNet.IP) > 0 && !peerNet.Contains(flow.PeerIP) {
+			continue
+		}
+		if rule.Protocol != renderer.ANY {
+			if rule.Protocol != flow.Protocol {
+				continue
+			}
+			if rule.Protocol == renderer.ICMP {
+				if !icmpContains(rule.ICMPType, int16(flow.ICMPType)) ||
+					!icmpContains(rule.ICMPCode, int16(flow.ICMPCode)) {
+					continue
+				}
+			} else if !destPortContains(rule, flow.DestPort) {
+				continue
+			}
+		}
+		return rule
+	}
+	return nil
+}
+
+// trackFQDNs passes FQDNs referenced by policies of all configured pods
+// to the DNS cache.
+func (pc *PolicyConfigurator) trackFQDNs() {
+	if pc.DNSCache == nil {
+		return
+	}
+	fqdns := make(map[string]struct{})
+	for _, policies := range pc.podPolicies {
+		for _, policy := range policies {
+			for _, match := range policy.Matches {
+				for _, fqdn := range match.FQDNs {
+					fqdns[fqdn] = struct{}{}
+				}
+			}
+		}
+	}
+	tracked := []string{}
+	for fqdn := range fqdns {
+		tracked = append(tracked, fqdn)
+	}
+	sort.Strings(tracked)
+	pc.DNSCache.Track(tracked)
+}
+
+// appliesTo returns true if the policy relates to the traffic in the given
+// direction.
+func (cp *ContivPolicy) appliesTo(direction MatchType) bool {
+	return !((cp.Type == PolicyIngress && direction == MatchEgress) ||
+		(cp.Type == PolicyEgress && direction == MatchIngress))
+}
+
+// referenceFQDN returns true if any of the policies references FQDN matching
+// one of the given domain names.
+func (cp ContivPolicies) referenceFQDN(names []string) bool {
+	for _, policy := range cp {
+		for _, match := range policy.Matches {
+			for _, fqdn := range match.FQDNs {
+				for _, name := range names {
+					if dnscache.MatchFQDN(dnscache.NormalizeFQDN(fqdn), name) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
 // Copy creates a shallow copy of ContivPolicies.
 func (cp ContivPolicies) Copy() ContivPolicies {
 	cpCopy := make(ContivPolicies, len(cp))
@@ -496,6 +1381,16 @@ func (cp ContivPolicies) Equals(cp2 ContivPolicies) bool {
 	return true
 }
 
+// policySetKey returns a string uniquely identifying the (ordered) list
+// of policies. Lists with the same key are Equal.
+func (cp ContivPolicies) policySetKey() string {
+	ids := make([]string, 0, len(cp))
+	for _, policy := range cp {
+		ids = append(ids, policy.ID.String())
+	}
+	return strings.Join(ids, ",")
+}
+
 // Len return the number of policies in the list.
 func (cp ContivPolicies) Len() int {
 	return len(cp)
@@ -506,8 +1401,18 @@ func (cp ContivPolicies) Swap(i, j int) {
 	cp[i], cp[j] = cp[j], cp[i]
 }
 
-// Less compares two policies by their IDs.
+// Less orders cluster-wide policies before K8s policies. Cluster-wide policies
+// are ordered by priority and name, K8s policies by their IDs.
 func (cp ContivPolicies) Less(i, j int) bool {
+	if cp[i].ClusterWide != cp[j].ClusterWide {
+		return cp[i].ClusterWide
+	}
+	if cp[i].ClusterWide {
+		if cp[i].Priority != cp[j].Priority {
+			return cp[i].Priority < cp[j].Priority
+		}
+		return cp[i].ID.Name < cp[j].ID.Name
+	}
 	if cp[i].ID.Namespace < cp[j].ID.Namespace {
 		return true
 	}
@@ -17,8 +17,10 @@
 package configurator
 
 import (
+	"fmt"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/onsi/gomega"
 
@@ -31,6 +33,7 @@ import (
 
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
 	rendererAPI "github.com/contiv/vpp/plugins/policy/renderer"
 )
 
@@ -1739,3 +1742,1458 @@ func TestMultiplePodsSpecialCases(t *testing.T) {
 		parseIP(natLoopbackIP), parseIP(pod1IP), rendererAPI.OTHER, 0, 0)
 	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
 }
+
+func TestClusterPolicies(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestClusterPolicies")
+
+	// Prepare input data.
+	const (
+		namespace  = "default"
+		pod1Name   = "pod1"
+		pod2Name   = "pod2"
+		pod1IP     = "192.168.1.1"
+		pod2IP     = "192.168.1.2"
+		metadataIP = "169.254.169.254"
+		externalIP = "8.8.8.8"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+	pod2 := podmodel.ID{Name: pod2Name, Namespace: namespace}
+
+	// K8s policy allowing all egress traffic of pod1.
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyEgress,
+		Matches: []Match{
+			{
+				Type: MatchEgress,
+			},
+		},
+	}
+	// Cluster policy denying access to the metadata server.
+	denyMetadata := &ContivPolicy{
+		ID:          policymodel.ID{Name: "deny-metadata"},
+		Type:        PolicyAll,
+		ClusterWide: true,
+		Priority:    10,
+		Matches: []Match{
+			{
+				Type:     MatchEgress,
+				Action:   MatchDeny,
+				IPBlocks: []IPBlock{{Network: parseIPNet(metadataIP + "/32")}},
+			},
+		},
+	}
+	// Cluster policy allowing DNS queries to the metadata server.
+	allowDNS := &ContivPolicy{
+		ID:          policymodel.ID{Name: "allow-dns"},
+		Type:        PolicyAll,
+		ClusterWide: true,
+		Priority:    5,
+		Matches: []Match{
+			{
+				Type:     MatchEgress,
+				Action:   MatchAllow,
+				IPBlocks: []IPBlock{{Network: parseIPNet(metadataIP + "/32")}},
+				Ports: []Port{
+					{Protocol: UDP, Number: 53},
+				},
+			},
+		},
+	}
+	pod1Policies := []*ContivPolicy{policy1, denyMetadata, allowDNS}
+	pod2Policies := []*ContivPolicy{denyMetadata, allowDNS}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+	cache.AddPodConfig(pod2, pod2IP)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:    logger,
+			Cache:  cache,
+			Contiv: contiv,
+		},
+	}
+	configurator.Init(false)
+
+	// Register one renderer.
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction.
+	txn := configurator.NewTxn(false)
+	txn.Configure(pod1, pod1Policies)
+	txn.Configure(pod2, pod2Policies)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test with fake traffic.
+
+	// Blocked by deny-metadata despite policy1 allowing all.
+	action := renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(metadataIP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(metadataIP), rendererAPI.OTHER, 0, 0)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Allowed by allow-dns which has higher priority than deny-metadata.
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(metadataIP), rendererAPI.UDP, 123, 53)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Not matched by cluster policies, allowed by policy1.
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(externalIP), rendererAPI.TCP, 123, 443)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Cluster policies apply also to pods without K8s policies.
+	action = renderer.TestTraffic(pod2, IngressTraffic,
+		parseIP(pod2IP), parseIP(metadataIP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+	action = renderer.TestTraffic(pod2, IngressTraffic,
+		parseIP(pod2IP), parseIP(metadataIP), rendererAPI.UDP, 123, 53)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Cluster policies do not isolate pods.
+	action = renderer.TestTraffic(pod2, IngressTraffic,
+		parseIP(pod2IP), parseIP(externalIP), rendererAPI.TCP, 123, 443)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+	action = renderer.TestTraffic(pod2, EgressTraffic,
+		parseIP(pod1IP), parseIP(pod2IP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(UnmatchedTraffic))
+
+	// Change the priorities - deny-metadata is now evaluated first.
+	denyMetadata.Priority = 1
+
+	txn = configurator.NewTxn(false)
+	txn.Configure(pod1, pod1Policies)
+	txn.Configure(pod2, pod2Policies)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// DNS is now blocked as well.
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(metadataIP), rendererAPI.UDP, 123, 53)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+	action = renderer.TestTraffic(pod2, IngressTraffic,
+		parseIP(pod2IP), parseIP(metadataIP), rendererAPI.UDP, 123, 53)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Other traffic is unaffected.
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(externalIP), rendererAPI.UDP, 123, 53)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+}
+
+func TestClusterRulePrecedence(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	rule := func(action rendererAPI.ActionType, src, dst string, protocol rendererAPI.ProtocolType, dstPort uint16) *rendererAPI.ContivRule {
+		rule := &rendererAPI.ContivRule{
+			Action:      action,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: &net.IPNet{},
+			Protocol:    protocol,
+			DestPort:    dstPort,
+		}
+		if src != "" {
+			network := parseIPNet(src)
+			rule.SrcNetwork = &network
+		}
+		if dst != "" {
+			network := parseIPNet(dst)
+			rule.DestNetwork = &network
+		}
+		return rule
+	}
+	permit, deny := rendererAPI.ActionPermit, rendererAPI.ActionDeny
+	tcp, udp, anyProto := rendererAPI.TCP, rendererAPI.UDP, rendererAPI.ANY
+
+	clusterRules := ContivRules{
+		rule(permit, "", "10.1.1.0/24", tcp, 53),
+		rule(deny, "", "10.1.0.0/16", anyProto, 0),
+		rule(deny, "172.16.0.0/12", "", udp, 0),
+	}
+	k8sRules := ContivRules{
+		rule(permit, "172.16.1.0/24", "", anyProto, 0),
+		rule(permit, "", "10.1.0.0/16", tcp, 80),
+		rule(permit, "", "", udp, 53),
+		rule(permit, "", "10.2.0.0/16", tcp, 0),
+		rule(deny, "", "", anyProto, 0),
+	}
+	fullClosure := resolveRulePrecedence(append(append(ContivRules{}, clusterRules...), k8sRules...))
+	rules := resolveClusterRulePrecedence(clusterRules, k8sRules)
+	gomega.Expect(len(rules)).To(gomega.BeNumerically("<", len(fullClosure)))
+
+	for _, src := range []string{"172.16.1.1", "172.17.0.1", "192.168.1.1"} {
+		for _, dst := range []string{"10.1.1.1", "10.1.2.1", "10.2.0.1", "8.8.8.8"} {
+			for _, protocol := range []rendererAPI.ProtocolType{tcp, udp} {
+				for _, port := range []uint16{53, 80, 443} {
+					expected := evalLoadBalancerRules(fullClosure, src, dst, protocol, port)
+					action := evalLoadBalancerRules(rules, src, dst, protocol, port)
+					gomega.Expect(action).To(gomega.BeEquivalentTo(expected),
+						fmt.Sprintf("%s -> %s:%d/%v", src, dst, port, protocol))
+				}
+			}
+		}
+	}
+
+	// cluster rules take precedence
+	gomega.Expect(evalLoadBalancerRules(rules, "192.168.1.1", "10.1.1.1", tcp, 53)).To(gomega.BeEquivalentTo(permit))
+	gomega.Expect(evalLoadBalancerRules(rules, "192.168.1.1", "10.1.1.1", tcp, 80)).To(gomega.BeEquivalentTo(deny))
+	gomega.Expect(evalLoadBalancerRules(rules, "172.16.1.1", "8.8.8.8", udp, 53)).To(gomega.BeEquivalentTo(deny))
+	// K8s rules apply to the rest
+	gomega.Expect(evalLoadBalancerRules(rules, "172.16.1.1", "8.8.8.8", tcp, 443)).To(gomega.BeEquivalentTo(permit))
+	gomega.Expect(evalLoadBalancerRules(rules, "192.168.1.1", "10.2.0.1", tcp, 443)).To(gomega.BeEquivalentTo(permit))
+	gomega.Expect(evalLoadBalancerRules(rules, "192.168.1.1", "8.8.8.8", tcp, 443)).To(gomega.BeEquivalentTo(deny))
+}
+
+// fakeDNSCache is a static DNS cache for unit tests.
+type fakeDNSCache struct {
+	ips     map[string][]net.IP
+	tracked []string
+}
+
+func (fdc *fakeDNSCache) LookupFQDN(fqdn string) []net.IP {
+	return fdc.ips[fqdn]
+}
+
+func (fdc *fakeDNSCache) Track(fqdns []string) {
+	fdc.tracked = fqdns
+}
+
+func (fdc *fakeDNSCache) Watch(subscriber chan<- []string) {
+}
+
+func TestFQDNEgress(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestFQDNEgress")
+
+	// Prepare input data.
+	const (
+		namespace = "default"
+		pod1Name  = "pod1"
+		pod1IP    = "192.168.1.1"
+		fqdn      = "api.example.com"
+		fqdnIP1   = "10.0.0.1"
+		fqdnIP2   = "10.0.0.2"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+
+	// Policy allowing egress of pod1 only to the FQDN on TCP port 443.
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyEgress,
+		Matches: []Match{
+			{
+				Type:     MatchEgress,
+				Pods:     []podmodel.ID{},
+				IPBlocks: []IPBlock{},
+				FQDNs:    []string{fqdn},
+				Ports: []Port{
+					{Protocol: TCP, Number: 443},
+				},
+			},
+		},
+	}
+	pod1Policies := []*ContivPolicy{policy1}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+
+	dnsCache := &fakeDNSCache{ips: make(map[string][]net.IP)}
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:      logger,
+			Cache:    cache,
+			Contiv:   contiv,
+			DNSCache: dnsCache,
+		},
+	}
+	configurator.Init(false)
+
+	// Register one renderer.
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction.
+	txn := configurator.NewTxn(false)
+	txn.Configure(pod1, pod1Policies)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(dnsCache.tracked).To(gomega.Equal([]string{fqdn}))
+
+	// FQDN not resolved yet.
+	action := renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(fqdnIP1), rendererAPI.TCP, 123, 443)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Changes in unrelated names do not trigger any re-configuration.
+	err = configurator.RefreshFQDNs([]string{"www.example.com"})
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// FQDN resolved.
+	dnsCache.ips[fqdn] = []net.IP{net.ParseIP(fqdnIP1)}
+	err = configurator.RefreshFQDNs([]string{fqdn})
+	gomega.Expect(err).To(gomega.BeNil())
+
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(fqdnIP1), rendererAPI.TCP, 123, 443)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(fqdnIP1), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(fqdnIP2), rendererAPI.TCP, 123, 443)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// FQDN address changed.
+	dnsCache.ips[fqdn] = []net.IP{net.ParseIP(fqdnIP2)}
+	err = configurator.RefreshFQDNs([]string{fqdn})
+	gomega.Expect(err).To(gomega.BeNil())
+
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(fqdnIP1), rendererAPI.TCP, 123, 443)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(fqdnIP2), rendererAPI.TCP, 123, 443)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Pod removed - FQDN no longer tracked.
+	cache.AddPodConfig(pod1, "")
+	txn = configurator.NewTxn(false)
+	txn.Configure(pod1, pod1Policies)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(dnsCache.tracked).To(gomega.BeEmpty())
+}
+
+func TestAuditModeAndEvaluateFlow(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestAuditModeAndEvaluateFlow")
+
+	// Prepare input data.
+	const (
+		namespace = "default"
+		pod1Name  = "pod1"
+		pod1IP    = "192.168.1.1"
+		pod2Name  = "pod2"
+		pod2IP    = "192.168.1.2"
+		peerIP    = "10.0.0.1"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+	pod2 := podmodel.ID{Name: pod2Name, Namespace: namespace}
+
+	// Policy allowing ingress only on TCP port 80.
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyIngress,
+		Matches: []Match{
+			{
+				Type:  MatchIngress,
+				Ports: []Port{{Protocol: TCP, Number: 80}},
+			},
+		},
+	}
+	// The same policy in the audit mode.
+	auditPolicy1 := &ContivPolicy{
+		ID:      policymodel.ID{Name: "audit-policy1", Namespace: namespace},
+		Type:    PolicyIngress,
+		Audit:   true,
+		Matches: policy1.Matches,
+	}
+	// Cluster-wide policy denying ingress from the peer.
+	clusterPolicy := &ContivPolicy{
+		ID:          policymodel.ID{Name: "deny-peer"},
+		Type:        PolicyIngress,
+		ClusterWide: true,
+		Matches: []Match{
+			{
+				Type:     MatchIngress,
+				Action:   MatchDeny,
+				IPBlocks: []IPBlock{{Network: parseIPNet(peerIP + "/32")}},
+			},
+		},
+	}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+	cache.AddPodConfig(pod2, pod2IP)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:    logger,
+			Cache:  cache,
+			Contiv: contiv,
+		},
+	}
+	configurator.Init(false)
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction.
+	txn := configurator.NewTxn(false)
+	txn.Configure(pod1, []*ContivPolicy{policy1})
+	txn.Configure(pod2, []*ContivPolicy{auditPolicy1, clusterPolicy})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Pod1 is isolated.
+	action := renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP("10.0.0.2"), parseIP(pod1IP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP("10.0.0.2"), parseIP(pod1IP), rendererAPI.TCP, 123, 22)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Pod2 is isolated only by the audited policy, the cluster policy is enforced.
+	action = renderer.TestTraffic(pod2, EgressTraffic,
+		parseIP("10.0.0.2"), parseIP(pod2IP), rendererAPI.TCP, 123, 22)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+	action = renderer.TestTraffic(pod2, EgressTraffic,
+		parseIP(peerIP), parseIP(pod2IP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	gomega.Expect(configurator.AuditedPolicies(pod1, MatchIngress)).To(gomega.BeEmpty())
+	gomega.Expect(configurator.AuditedPolicies(pod2, MatchIngress)).To(gomega.Equal([]policymodel.ID{auditPolicy1.ID}))
+	gomega.Expect(configurator.AuditedPolicies(pod2, MatchEgress)).To(gomega.BeEmpty())
+
+	// Evaluate flows.
+	flow := Flow{Direction: MatchIngress, PeerIP: net.ParseIP("10.0.0.2"), Protocol: rendererAPI.TCP, DestPort: 80}
+	verdict := configurator.EvaluateFlow(pod1, flow)
+	gomega.Expect(verdict.Denied).To(gomega.BeFalse())
+	gomega.Expect(verdict.Policies).To(gomega.Equal([]policymodel.ID{policy1.ID}))
+	gomega.Expect(configurator.EvaluateFlow(pod2, flow).Denied).To(gomega.BeFalse())
+
+	flow.DestPort = 22
+	verdict = configurator.EvaluateFlow(pod1, flow)
+	gomega.Expect(verdict.Denied).To(gomega.BeTrue())
+	gomega.Expect(verdict.Audited).To(gomega.BeFalse())
+	gomega.Expect(verdict.Policies).To(gomega.Equal([]policymodel.ID{policy1.ID}))
+
+	verdict = configurator.EvaluateFlow(pod2, flow)
+	gomega.Expect(verdict.Denied).To(gomega.BeTrue())
+	gomega.Expect(verdict.Audited).To(gomega.BeTrue())
+	gomega.Expect(verdict.Policies).To(gomega.Equal([]policymodel.ID{auditPolicy1.ID}))
+
+	flow.PeerIP = net.ParseIP(peerIP)
+	verdict = configurator.EvaluateFlow(pod2, flow)
+	gomega.Expect(verdict.Denied).To(gomega.BeTrue())
+	gomega.Expect(verdict.Audited).To(gomega.BeFalse())
+	gomega.Expect(verdict.Policies).To(gomega.Equal([]policymodel.ID{clusterPolicy.ID}))
+
+	// Egress is not restricted.
+	flow.Direction = MatchEgress
+	verdict = configurator.EvaluateFlow(pod1, flow)
+	gomega.Expect(verdict.Denied).To(gomega.BeFalse())
+	gomega.Expect(verdict.Policies).To(gomega.BeEmpty())
+}
+
+func TestPortRanges(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestPortRanges")
+
+	// Prepare input data.
+	const (
+		namespace = "default"
+		pod1Name  = "pod1"
+		pod1IP    = "192.168.1.1"
+		peerIP    = "10.0.0.1"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+
+	// Policy allowing ingress on TCP ports 8000-8100 and on UDP port 53.
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyIngress,
+		Matches: []Match{
+			{
+				Type: MatchIngress,
+				Ports: []Port{
+					{Protocol: TCP, Number: 8000, EndNumber: 8100},
+					{Protocol: UDP, Number: 53},
+				},
+			},
+		},
+	}
+	// Cluster-wide policy denying ingress on TCP ports 8050-8060.
+	clusterPolicy := &ContivPolicy{
+		ID:          policymodel.ID{Name: "deny-range"},
+		Type:        PolicyIngress,
+		ClusterWide: true,
+		Matches: []Match{
+			{
+				Type:   MatchIngress,
+				Action: MatchDeny,
+				Ports:  []Port{{Protocol: TCP, Number: 8050, EndNumber: 8060}},
+			},
+		},
+	}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:    logger,
+			Cache:  cache,
+			Contiv: contiv,
+		},
+	}
+	configurator.Init(false)
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction.
+	txn := configurator.NewTxn(false)
+	txn.Configure(pod1, []*ContivPolicy{policy1, clusterPolicy})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test traffic.
+	for port, expected := range map[uint16]TrafficAction{
+		22:   DeniedTraffic,
+		7999: DeniedTraffic,
+		8000: AllowedTraffic,
+		8049: AllowedTraffic,
+		8050: DeniedTraffic,
+		8055: DeniedTraffic,
+		8060: DeniedTraffic,
+		8061: AllowedTraffic,
+		8100: AllowedTraffic,
+		8101: DeniedTraffic,
+	} {
+		action := renderer.TestTraffic(pod1, EgressTraffic,
+			parseIP(peerIP), parseIP(pod1IP), rendererAPI.TCP, 123, port)
+		gomega.Expect(action).To(gomega.BeEquivalentTo(expected), "port %d", port)
+	}
+	action := renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(peerIP), parseIP(pod1IP), rendererAPI.UDP, 123, 53)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Evaluate flows.
+	flow := Flow{Direction: MatchIngress, PeerIP: net.ParseIP(peerIP), Protocol: rendererAPI.TCP, DestPort: 8080}
+	gomega.Expect(configurator.EvaluateFlow(pod1, flow).Denied).To(gomega.BeFalse())
+
+	flow.DestPort = 8055
+	verdict := configurator.EvaluateFlow(pod1, flow)
+	gomega.Expect(verdict.Denied).To(gomega.BeTrue())
+	gomega.Expect(verdict.Policies).To(gomega.Equal([]policymodel.ID{clusterPolicy.ID}))
+
+	flow.DestPort = 8101
+	verdict = configurator.EvaluateFlow(pod1, flow)
+	gomega.Expect(verdict.Denied).To(gomega.BeTrue())
+	gomega.Expect(verdict.Policies).To(gomega.Equal([]policymodel.ID{policy1.ID}))
+}
+
+func TestICMPAndSCTP(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestICMPAndSCTP")
+
+	// Prepare input data.
+	const (
+		namespace = "default"
+		pod1Name  = "pod1"
+		pod1IP    = "192.168.1.1"
+		peerIP    = "10.0.0.1"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+
+	// Policy allowing ingress on SCTP port 3868 and all ICMP messages.
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyIngress,
+		Matches: []Match{
+			{
+				Type: MatchIngress,
+				Ports: []Port{
+					{Protocol: SCTP, Number: 3868},
+					{Protocol: ICMP, ICMPType: rendererAPI.AnyICMP, ICMPCode: rendererAPI.AnyICMP},
+				},
+			},
+		},
+	}
+	// Cluster-wide policy denying ingress ICMP echo requests.
+	clusterPolicy := &ContivPolicy{
+		ID:          policymodel.ID{Name: "deny-ping"},
+		Type:        PolicyIngress,
+		ClusterWide: true,
+		Matches: []Match{
+			{
+				Type:   MatchIngress,
+				Action: MatchDeny,
+				Ports:  []Port{{Protocol: ICMP, ICMPType: 8, ICMPCode: rendererAPI.AnyICMP}},
+			},
+		},
+	}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:    logger,
+			Cache:  cache,
+			Contiv: contiv,
+		},
+	}
+	configurator.Init(false)
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction.
+	txn := configurator.NewTxn(false)
+	txn.Configure(pod1, []*ContivPolicy{policy1, clusterPolicy})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test traffic (ICMP type and code are passed as ports).
+	action := renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(peerIP), parseIP(pod1IP), rendererAPI.SCTP, 123, 3868)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(peerIP), parseIP(pod1IP), rendererAPI.SCTP, 123, 3869)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(peerIP), parseIP(pod1IP), rendererAPI.TCP, 123, 3868)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(peerIP), parseIP(pod1IP), rendererAPI.ICMP, 8, 0)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(peerIP), parseIP(pod1IP), rendererAPI.ICMP, 0, 0)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(peerIP), parseIP(pod1IP), rendererAPI.ICMP, 3, 1)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Evaluate flows.
+	flow := Flow{Direction: MatchIngress, PeerIP: net.ParseIP(peerIP), Protocol: rendererAPI.ICMP, ICMPType: 0}
+	gomega.Expect(configurator.EvaluateFlow(pod1, flow).Denied).To(gomega.BeFalse())
+
+	flow.ICMPType = 8
+	verdict := configurator.EvaluateFlow(pod1, flow)
+	gomega.Expect(verdict.Denied).To(gomega.BeTrue())
+	gomega.Expect(verdict.Policies).To(gomega.Equal([]policymodel.ID{clusterPolicy.ID}))
+
+	flow = Flow{Direction: MatchIngress, PeerIP: net.ParseIP(peerIP), Protocol: rendererAPI.SCTP, DestPort: 3868}
+	gomega.Expect(configurator.EvaluateFlow(pod1, flow).Denied).To(gomega.BeFalse())
+}
+
+func TestRendererFailureWithResync(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestRendererFailureWithResync")
+
+	// Prepare input data.
+	const (
+		namespace = "default"
+		pod1Name  = "pod1"
+		pod2Name  = "pod2"
+		pod1IP    = "192.168.1.1"
+		pod2IP    = "192.168.1.2"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+	pod2 := podmodel.ID{Name: pod2Name, Namespace: namespace}
+
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyIngress,
+		Matches: []Match{
+			{
+				Type: MatchIngress,
+				Pods: []podmodel.ID{
+					pod2,
+				},
+				Ports: []Port{
+					{Protocol: TCP, Number: 80},
+				},
+			},
+		},
+	}
+	pod1Policies := []*ContivPolicy{policy1}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+	cache.AddPodConfig(pod2, pod2IP)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:    logger,
+			Cache:  cache,
+			Contiv: contiv,
+		},
+	}
+	configurator.Init(false)
+	resyncDelay = 10 * time.Millisecond
+	resyncChan := make(chan struct{}, 1)
+	configurator.WatchResyncRequests(resyncChan)
+
+	// Register one renderer.
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction, which fails in the renderer.
+	renderer.InjectFailure()
+	txn := configurator.NewTxn(false)
+	txn.Configure(pod1, pod1Policies)
+	err = txn.Commit()
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	gomega.Expect(renderer.GetCommitCount()).To(gomega.Equal(0))
+	ip, _ := renderer.GetPodIP(pod1)
+	gomega.Expect(ip).To(gomega.BeEmpty())
+
+	// Wait for the resync request.
+	select {
+	case <-resyncChan:
+	case <-time.After(time.Second):
+		t.Fatal("resync was not requested")
+	}
+
+	// Resync renderers.
+	err = configurator.Resync()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(renderer.GetCommitCount()).To(gomega.Equal(1))
+	ip, _ = renderer.GetPodIP(pod1)
+	gomega.Expect(ip).To(gomega.BeEquivalentTo(pod1IP))
+
+	// Allowed by policy1.
+	action := renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// Not allowed by policy1.
+	action = renderer.TestTraffic(pod1, EgressTraffic,
+		parseIP(pod2IP), parseIP(pod1IP), rendererAPI.TCP, 123, 443)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+}
+
+// scaleTestConfig builds configuration for <numOfPods> pods and <numOfPolicies>
+// policies, each pod with <policiesPerPod> policies, each policy with
+// <peersPerPolicy> peer pods.
+func scaleTestConfig(numOfPods, numOfPolicies, policiesPerPod, peersPerPolicy int) (
+	cache *MockPolicyCache, pods []podmodel.ID, config map[podmodel.ID][]*ContivPolicy) {
+
+	const namespace = "default"
+	cache = NewMockPolicyCache()
+	for i := 0; i < numOfPods; i++ {
+		pod := podmodel.ID{Name: fmt.Sprintf("pod%d", i), Namespace: namespace}
+		cache.AddPodConfig(pod, fmt.Sprintf("10.%d.%d.%d", i>>16&0xff, i>>8&0xff, i&0xff))
+		pods = append(pods, pod)
+	}
+
+	policies := []*ContivPolicy{}
+	for i := 0; i < numOfPolicies; i++ {
+		peers := []podmodel.ID{}
+		for j := 0; j < peersPerPolicy; j++ {
+			peers = append(peers, pods[(i*peersPerPolicy+j)%numOfPods])
+		}
+		policies = append(policies, &ContivPolicy{
+			ID:   policymodel.ID{Name: fmt.Sprintf("policy%d", i), Namespace: namespace},
+			Type: PolicyAll,
+			Matches: []Match{
+				{
+					Type:  MatchIngress,
+					Pods:  peers,
+					Ports: []Port{{Protocol: TCP, Number: uint16(1000 + i)}},
+				},
+				{
+					Type: MatchEgress,
+					Pods: peers,
+					IPBlocks: []IPBlock{
+						{
+							Network: parseIPNet(fmt.Sprintf("192.%d.0.0/16", i&0xff)),
+							Except:  []net.IPNet{parseIPNet(fmt.Sprintf("192.%d.1.0/24", i&0xff))},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	config = make(map[podmodel.ID][]*ContivPolicy)
+	for i, pod := range pods {
+		podPolicies := []*ContivPolicy{}
+		for j := 0; j < policiesPerPod; j++ {
+			podPolicies = append(podPolicies, policies[(i+j*7)%numOfPolicies])
+		}
+		config[pod] = podPolicies
+	}
+	return cache, pods, config
+}
+
+func TestParallelRuleGeneration(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.InfoLevel)
+	logger.Info("TestParallelRuleGeneration")
+
+	cache, pods, config := scaleTestConfig(200, 40, 3, 5)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+
+	// Render the same configuration with a single worker and with multiple workers.
+	renderers := []*MockRenderer{}
+	for _, workers := range []int{1, 8} {
+		renderer := NewMockRenderer("A", logger)
+		renderers = append(renderers, renderer)
+		configurator := &PolicyConfigurator{
+			Deps: Deps{
+				Log:     logger,
+				Cache:   cache,
+				Contiv:  contiv,
+				Workers: workers,
+			},
+		}
+		configurator.Init(false)
+		err := configurator.RegisterRenderer(renderer)
+		gomega.Expect(err).To(gomega.BeNil())
+
+		txn := configurator.NewTxn(true)
+		for pod, policies := range config {
+			txn.Configure(pod, policies)
+		}
+		err = txn.Commit()
+		gomega.Expect(err).To(gomega.BeNil())
+	}
+
+	// The outcome should be the same.
+	for _, pod := range pods {
+		ingress1, egress1 := renderers[0].GetPodRules(pod)
+		ingress2, egress2 := renderers[1].GetPodRules(pod)
+		gomega.Expect(ingress1).ToNot(gomega.BeEmpty())
+		gomega.Expect(egress1).ToNot(gomega.BeEmpty())
+		gomega.Expect(ingress2).To(gomega.Equal(ingress1))
+		gomega.Expect(egress2).To(gomega.Equal(egress1))
+	}
+}
+
+// BenchmarkCommit measures the time to (re)generate and render rules
+// for all pods on a node.
+func BenchmarkCommit(b *testing.B) {
+	gomega.RegisterTestingT(b)
+	logger := logrus.NewLogger("benchmark")
+	logger.SetLevel(logging.ErrorLevel)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+
+	scales := []struct {
+		pods, policies int
+	}{
+		{pods: 100, policies: 10},
+		{pods: 1000, policies: 100},
+		{pods: 5000, policies: 500},
+	}
+	for _, scale := range scales {
+		cache, _, config := scaleTestConfig(scale.pods, scale.policies, 3, 20)
+		for _, workers := range []int{1, 4, 16} {
+			name := fmt.Sprintf("pods=%d/policies=%d/workers=%d", scale.pods, scale.policies, workers)
+			b.Run(name, func(b *testing.B) {
+				configurator := &PolicyConfigurator{
+					Deps: Deps{
+						Log:     logger,
+						Cache:   cache,
+						Contiv:  contiv,
+						Workers: workers,
+					},
+				}
+				configurator.Init(false)
+				configurator.RegisterRenderer(NewMockRenderer("A", logger))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					txn := configurator.NewTxn(true)
+					for pod, policies := range config {
+						txn.Configure(pod, policies)
+					}
+					if err := txn.Commit(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestDumpPodConfigs(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestDumpPodConfigs")
+
+	cache, pods, config := scaleTestConfig(10, 4, 2, 3)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+	renderer := NewMockRenderer("A", logger)
+
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:    logger,
+			Cache:  cache,
+			Contiv: contiv,
+		},
+	}
+	configurator.Init(false)
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	txn := configurator.NewTxn(true)
+	for pod, policies := range config {
+		txn.Configure(pod, policies)
+	}
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Dumped configuration should be sorted by pod ID and match the rendered one.
+	dump := configurator.DumpPodConfigs()
+	gomega.Expect(dump).To(gomega.HaveLen(len(pods)))
+	for i, podConfig := range dump {
+		if i > 0 {
+			gomega.Expect(dump[i-1].Pod.String() < podConfig.Pod.String()).To(gomega.BeTrue())
+		}
+		gomega.Expect(podConfig.Policies).To(gomega.HaveLen(2))
+		gomega.Expect(podConfig.IPAddresses).To(gomega.Equal(renderer.GetPodIPs(podConfig.Pod)))
+		ingress, egress := renderer.GetPodRules(podConfig.Pod)
+		gomega.Expect(podConfig.Ingress).To(gomega.Equal(ContivRules(ingress)))
+		gomega.Expect(podConfig.Egress).To(gomega.Equal(ContivRules(egress)))
+	}
+}
+
+// evalHostRules returns the action of the first host rule matching the given
+// connection (the host allows all the traffic if there are no rules).
+func evalHostRules(rules []*rendererAPI.ContivRule, srcIP string, protocol rendererAPI.ProtocolType, dstPort uint16) rendererAPI.ActionType {
+	ip := net.ParseIP(srcIP)
+	for _, rule := range rules {
+		if len(rule.SrcNetwork.IP) > 0 && !rule.SrcNetwork.Contains(ip) {
+			continue
+		}
+		if rule.Protocol != rendererAPI.ANY && rule.Protocol != protocol {
+			continue
+		}
+		lastPort := rule.DestPort
+		if rule.DestPortEnd > rule.DestPort {
+			lastPort = rule.DestPortEnd
+		}
+		if rule.DestPort != 0 && (dstPort < rule.DestPort || dstPort > lastPort) {
+			continue
+		}
+		return rule.Action
+	}
+	return rendererAPI.ActionPermit
+}
+
+func TestHostPolicies(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestHostPolicies")
+
+	// Prepare input data.
+	failSafe := &ContivPolicy{
+		ID:          policymodel.ID{Name: "fail-safe"},
+		Type:        PolicyIngress,
+		ClusterWide: true,
+		Priority:    -100,
+		Matches: []Match{
+			{
+				Type:  MatchIngress,
+				Ports: []Port{{Protocol: TCP, Number: 22}},
+			},
+		},
+	}
+	hostPolicy := &ContivPolicy{
+		ID:          policymodel.ID{Name: "host-policy"},
+		Type:        PolicyIngress,
+		ClusterWide: true,
+		Priority:    10,
+		Matches: []Match{
+			{
+				Type:     MatchIngress,
+				Action:   MatchDeny,
+				IPBlocks: []IPBlock{{Network: parseIPNet("10.0.0.0/8")}},
+			},
+			{
+				Type:     MatchIngress,
+				IPBlocks: []IPBlock{{Network: parseIPNet("192.168.0.0/16")}},
+				Ports:    []Port{{Protocol: TCP, Number: 80}},
+			},
+		},
+	}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:    logger,
+			Cache:  cache,
+			Contiv: contiv,
+		},
+	}
+	configurator.Init(false)
+
+	// Register one renderer.
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Configure only the host.
+	txn := configurator.NewTxn(false)
+	txn.ConfigureHost([]*ContivPolicy{hostPolicy, failSafe})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test the rendered host rules.
+	rules := renderer.GetHostRules()
+	gomega.Expect(rules).ToNot(gomega.BeEmpty())
+	gomega.Expect(evalHostRules(rules, "10.1.1.1", rendererAPI.TCP, 22)).To(gomega.Equal(rendererAPI.ActionPermit))
+	gomega.Expect(evalHostRules(rules, "10.1.1.1", rendererAPI.TCP, 80)).To(gomega.Equal(rendererAPI.ActionDeny))
+	gomega.Expect(evalHostRules(rules, "192.168.1.1", rendererAPI.TCP, 80)).To(gomega.Equal(rendererAPI.ActionPermit))
+	gomega.Expect(evalHostRules(rules, "192.168.1.1", rendererAPI.TCP, 8080)).To(gomega.Equal(rendererAPI.ActionDeny))
+	gomega.Expect(evalHostRules(rules, "8.8.8.8", rendererAPI.UDP, 53)).To(gomega.Equal(rendererAPI.ActionDeny))
+	gomega.Expect(evalHostRules(rules, "8.8.8.8", rendererAPI.TCP, 22)).To(gomega.Equal(rendererAPI.ActionPermit))
+	for _, rule := range rules {
+		gomega.Expect(rule.DestNetwork.IP).To(gomega.BeEmpty())
+	}
+
+	// Host policies are re-rendered with resync.
+	err = configurator.Resync()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(renderer.GetHostRules()).To(gomega.Equal(rules))
+
+	// Removed host policies allow all the traffic.
+	txn = configurator.NewTxn(false)
+	txn.ConfigureHost(nil)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(renderer.GetHostRules()).To(gomega.BeEmpty())
+}
+
+// evalLoadBalancerRules returns the action of the first load-balancer rule
+// matching the given connection (all the traffic is allowed if there are
+// no rules).
+func evalLoadBalancerRules(rules []*rendererAPI.ContivRule, srcIP, dstIP string, protocol rendererAPI.ProtocolType, dstPort uint16) rendererAPI.ActionType {
+	src := net.ParseIP(srcIP)
+	dst := net.ParseIP(dstIP)
+	for _, rule := range rules {
+		if len(rule.SrcNetwork.IP) > 0 && !rule.SrcNetwork.Contains(src) {
+			continue
+		}
+		if len(rule.DestNetwork.IP) > 0 && !rule.DestNetwork.Contains(dst) {
+			continue
+		}
+		if rule.Protocol != rendererAPI.ANY && (rule.Protocol != protocol || (rule.DestPort != 0 && rule.DestPort != dstPort)) {
+			continue
+		}
+		return rule.Action
+	}
+	return rendererAPI.ActionPermit
+}
+
+func TestLoadBalancers(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestLoadBalancers")
+
+	// Prepare input data.
+	const (
+		lbIP    = "20.0.0.1"
+		lbIPv6  = "2001:db8::1"
+		otherIP = "20.0.0.2"
+	)
+	allowedNet := parseIPNet("192.168.0.0/16")
+	allowedNetv6 := parseIPNet("fd00::/8")
+	restricted := &LoadBalancer{
+		Service: svcmodel.ID{Name: "web", Namespace: "default"},
+		Frontends: []ServiceAddr{
+			{IP: net.ParseIP(lbIP), Port: Port{Protocol: TCP, Number: 80}},
+			{IP: net.ParseIP(lbIPv6), Port: Port{Protocol: TCP, Number: 80}},
+		},
+		SourceRanges: []*net.IPNet{&allowedNet, &allowedNetv6},
+	}
+	unrestricted := &LoadBalancer{
+		Service: svcmodel.ID{Name: "dns", Namespace: "default"},
+		Frontends: []ServiceAddr{
+			{IP: net.ParseIP(otherIP), Port: Port{Protocol: UDP, Number: 53}},
+		},
+	}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:    logger,
+			Cache:  cache,
+			Contiv: contiv,
+		},
+	}
+	configurator.Init(false)
+
+	// Register one renderer.
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Configure only the load-balancers.
+	txn := configurator.NewTxn(false)
+	txn.ConfigureLoadBalancers([]*LoadBalancer{unrestricted, restricted})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test the rendered load-balancer rules.
+	rules := renderer.GetLoadBalancerRules()
+	gomega.Expect(rules).To(gomega.HaveLen(4))
+	gomega.Expect(evalLoadBalancerRules(rules, "192.168.1.1", lbIP, rendererAPI.TCP, 80)).To(gomega.Equal(rendererAPI.ActionPermit))
+	gomega.Expect(evalLoadBalancerRules(rules, "10.1.1.1", lbIP, rendererAPI.TCP, 80)).To(gomega.Equal(rendererAPI.ActionDeny))
+	gomega.Expect(evalLoadBalancerRules(rules, "fd00::1", lbIPv6, rendererAPI.TCP, 80)).To(gomega.Equal(rendererAPI.ActionPermit))
+	gomega.Expect(evalLoadBalancerRules(rules, "2001:db8::2", lbIPv6, rendererAPI.TCP, 80)).To(gomega.Equal(rendererAPI.ActionDeny))
+	gomega.Expect(evalLoadBalancerRules(rules, "10.1.1.1", lbIP, rendererAPI.TCP, 8080)).To(gomega.Equal(rendererAPI.ActionPermit))
+	gomega.Expect(evalLoadBalancerRules(rules, "10.1.1.1", otherIP, rendererAPI.UDP, 53)).To(gomega.Equal(rendererAPI.ActionPermit))
+	for _, rule := range rules {
+		gomega.Expect(rule.DestNetwork.IP).ToNot(gomega.BeEmpty())
+	}
+
+	// Load-balancers are re-rendered with resync.
+	err = configurator.Resync()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(renderer.GetLoadBalancerRules()).To(gomega.Equal(rules))
+
+	// Removed source ranges allow all the traffic.
+	txn = configurator.NewTxn(false)
+	txn.ConfigureLoadBalancers([]*LoadBalancer{unrestricted})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(renderer.GetLoadBalancerRules()).To(gomega.BeEmpty())
+}
+
+// evalPreNATRules returns the action of the first pre-NAT rule matching
+// the given connection (all the traffic is allowed if there are no rules).
+func evalPreNATRules(rules []*rendererAPI.ContivRule, dstIP string, protocol rendererAPI.ProtocolType, dstPort uint16) rendererAPI.ActionType {
+	ip := net.ParseIP(dstIP)
+	for _, rule := range rules {
+		if len(rule.DestNetwork.IP) > 0 && !rule.DestNetwork.Contains(ip) {
+			continue
+		}
+		if rule.Protocol != rendererAPI.ANY && (rule.Protocol != protocol || (rule.DestPort != 0 && rule.DestPort != dstPort)) {
+			continue
+		}
+		return rule.Action
+	}
+	return rendererAPI.ActionPermit
+}
+
+func TestServicePeers(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestServicePeers")
+
+	// Prepare input data.
+	const (
+		namespace  = "default"
+		pod1Name   = "pod1"
+		pod2Name   = "pod2"
+		pod1IP     = "192.168.1.1"
+		pod2IP     = "192.168.1.2"
+		clusterIP  = "10.96.0.20"
+		backendIP1 = "10.1.1.2"
+		backendIP2 = "10.1.1.3"
+		otherIP    = "10.1.1.4"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+	pod2 := podmodel.ID{Name: pod2Name, Namespace: namespace}
+
+	// Cluster policy denying access to the database service.
+	db := ServicePeer{
+		ID: svcmodel.ID{Name: "db", Namespace: namespace},
+		Frontends: []ServiceAddr{
+			{IP: net.ParseIP(clusterIP), Port: Port{Protocol: TCP, Number: 5432}},
+		},
+		Backends: []ServiceAddr{
+			{IP: net.ParseIP(backendIP1), Port: Port{Protocol: TCP, Number: 15432}},
+		},
+	}
+	denyDB := &ContivPolicy{
+		ID:          policymodel.ID{Name: "deny-db"},
+		Type:        PolicyAll,
+		ClusterWide: true,
+		Priority:    10,
+		Matches: []Match{
+			{
+				Type:     MatchEgress,
+				Action:   MatchDeny,
+				Pods:     []podmodel.ID{},
+				IPBlocks: []IPBlock{},
+				Services: []ServicePeer{db},
+			},
+		},
+	}
+	pod1Policies := []*ContivPolicy{denyDB}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+	cache.AddPodConfig(pod2, pod2IP)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:    logger,
+			Cache:  cache,
+			Contiv: contiv,
+		},
+	}
+	configurator.Init(false)
+
+	// Register one renderer.
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Run single transaction.
+	txn := configurator.NewTxn(false)
+	txn.Configure(pod1, pod1Policies)
+	txn.Configure(pod2, []*ContivPolicy{})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Frontend is denied before NAT.
+	preNAT := renderer.GetPreNATRules(pod1)
+	gomega.Expect(preNAT).ToNot(gomega.BeEmpty())
+	gomega.Expect(evalPreNATRules(preNAT, clusterIP, rendererAPI.TCP, 5432)).To(gomega.Equal(rendererAPI.ActionDeny))
+	gomega.Expect(evalPreNATRules(preNAT, clusterIP, rendererAPI.TCP, 80)).To(gomega.Equal(rendererAPI.ActionPermit))
+	gomega.Expect(evalPreNATRules(preNAT, otherIP, rendererAPI.TCP, 5432)).To(gomega.Equal(rendererAPI.ActionPermit))
+	gomega.Expect(renderer.GetPreNATRules(pod2)).To(gomega.BeEmpty())
+
+	// Backend is denied after NAT (or when accessed directly).
+	action := renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(backendIP1), rendererAPI.TCP, 123, 15432)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(backendIP1), rendererAPI.TCP, 123, 80)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(backendIP2), rendererAPI.TCP, 123, 15432)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(AllowedTraffic))
+
+	// New endpoint of the service.
+	db.Backends = append(db.Backends, ServiceAddr{IP: net.ParseIP(backendIP2), Port: Port{Protocol: TCP, Number: 15432}})
+	denyDB.Matches[0].Services = []ServicePeer{db}
+
+	txn = configurator.NewTxn(false)
+	txn.Configure(pod1, pod1Policies)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	action = renderer.TestTraffic(pod1, IngressTraffic,
+		parseIP(pod1IP), parseIP(backendIP2), rendererAPI.TCP, 123, 15432)
+	gomega.Expect(action).To(gomega.BeEquivalentTo(DeniedTraffic))
+
+	// Pod removed - pre-NAT rules are removed as well.
+	cache.AddPodConfig(pod1, "")
+	txn = configurator.NewTxn(false)
+	txn.Configure(pod1, pod1Policies)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(renderer.GetPreNATRules(pod1)).To(gomega.BeEmpty())
+}
+
+func TestPolicyStatus(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestPolicyStatus")
+
+	// Prepare input data.
+	const (
+		namespace = "default"
+		pod1Name  = "pod1"
+		pod2Name  = "pod2"
+		pod3Name  = "pod3"
+		pod1IP    = "192.168.1.1"
+		pod2IP    = "192.168.1.2"
+		pod3IP    = "192.168.1.3"
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+	pod2 := podmodel.ID{Name: pod2Name, Namespace: namespace}
+	pod3 := podmodel.ID{Name: pod3Name, Namespace: namespace}
+
+	policy1 := &ContivPolicy{
+		ID:   policymodel.ID{Name: "policy1", Namespace: namespace},
+		Type: PolicyIngress,
+		Matches: []Match{
+			{
+				Type:  MatchIngress,
+				Pods:  []podmodel.ID{pod3},
+				Ports: []Port{{Protocol: TCP, Number: 80}},
+			},
+		},
+	}
+	policy2 := &ContivPolicy{
+		ID:          policymodel.ID{Name: "policy2"},
+		Type:        PolicyIngress,
+		ClusterWide: true,
+		Matches: []Match{
+			{
+				Type:   MatchIngress,
+				Action: MatchDeny,
+				Pods:   []podmodel.ID{pod1},
+			},
+		},
+	}
+
+	// Initialize mocks.
+	cache := NewMockPolicyCache()
+	cache.AddPodConfig(pod1, pod1IP)
+	cache.AddPodConfig(pod2, pod2IP)
+	cache.AddPodConfig(pod3, pod3IP)
+
+	contiv := NewMockContiv()
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+
+	renderer := NewMockRenderer("A", logger)
+
+	// Initialize configurator.
+	configurator := &PolicyConfigurator{
+		Deps: Deps{
+			Log:    logger,
+			Cache:  cache,
+			Contiv: contiv,
+		},
+	}
+	configurator.Init(false)
+	resyncDelay = time.Hour
+	statusChan := make(chan struct{}, 1)
+	configurator.WatchPolicyStatus(statusChan)
+	err := configurator.RegisterRenderer(renderer)
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Policy1 applied to pod1 and pod2 (same set of policies -> rules counted once),
+	// policy2 applied to pod3 together with policy1.
+	txn := configurator.NewTxn(false)
+	txn.Configure(pod1, []*ContivPolicy{policy1})
+	txn.Configure(pod2, []*ContivPolicy{policy1})
+	txn.Configure(pod3, []*ContivPolicy{policy1, policy2})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(statusChan).To(gomega.Receive())
+
+	status := configurator.GetPolicyStatus()
+	gomega.Expect(status).To(gomega.HaveLen(2))
+	// Cluster policies (empty namespace) are ordered first.
+	gomega.Expect(status[0].Policy).To(gomega.Equal(policy2.ID))
+	gomega.Expect(status[0].ClusterWide).To(gomega.BeTrue())
+	gomega.Expect(status[0].SelectedPods).To(gomega.Equal(1))
+	gomega.Expect(status[0].Rules).To(gomega.Equal(1))
+	gomega.Expect(status[0].Error).To(gomega.BeEmpty())
+	gomega.Expect(status[1].Policy).To(gomega.Equal(policy1.ID))
+	gomega.Expect(status[1].ClusterWide).To(gomega.BeFalse())
+	gomega.Expect(status[1].SelectedPods).To(gomega.Equal(3))
+	gomega.Expect(status[1].Rules).To(gomega.Equal(4)) // permit + isolating deny, once for each distinct set
+	gomega.Expect(status[1].Error).To(gomega.BeEmpty())
+
+	// Failed rendering of pod3 is attributed to both of its policies.
+	renderer.InjectFailure()
+	txn = configurator.NewTxn(false)
+	txn.Configure(pod3, []*ContivPolicy{policy2})
+	err = txn.Commit()
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	gomega.Expect(statusChan).To(gomega.Receive())
+
+	status = configurator.GetPolicyStatus()
+	gomega.Expect(status).To(gomega.HaveLen(2))
+	gomega.Expect(status[0].Policy).To(gomega.Equal(policy2.ID))
+	gomega.Expect(status[0].Error).To(gomega.Equal(err.Error()))
+	gomega.Expect(status[1].Policy).To(gomega.Equal(policy1.ID))
+	gomega.Expect(status[1].SelectedPods).To(gomega.Equal(2))
+	gomega.Expect(status[1].Rules).To(gomega.Equal(2))
+	gomega.Expect(status[1].Error).To(gomega.BeEmpty())
+
+	// Resync clears the error.
+	err = configurator.Resync()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(statusChan).To(gomega.Receive())
+	status = configurator.GetPolicyStatus()
+	gomega.Expect(status).To(gomega.HaveLen(2))
+	gomega.Expect(status[0].Error).To(gomega.BeEmpty())
+
+	// Removed pods are no longer counted.
+	cache.AddPodConfig(pod3, "")
+	txn = configurator.NewTxn(false)
+	txn.Configure(pod3, []*ContivPolicy{policy2})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	status = configurator.GetPolicyStatus()
+	gomega.Expect(status).To(gomega.HaveLen(1))
+	gomega.Expect(status[0].Policy).To(gomega.Equal(policy1.ID))
+}
@@ -0,0 +1,335 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package configurator
+
+import (
+	"math"
+	"net"
+	"sort"
+
+	"github.com/contiv/vpp/plugins/policy/renderer"
+)
+
+// resolveRulePrecedence converts a list of rules evaluated with the first-match
+// semantic in the given order into an equivalent list of rules ordered
+// by ContivRule.Compare, i.e. from the most specific to the least specific
+// rule, which is the order in which the renderers install the rules.
+//
+// The rule list is first closed under intersection. Then each rule from
+// the closure takes the action of the first rule from the input list which
+// contains it. Because the closure contains the intersection of all rules
+// matching a given packet, the most specific rule matching the packet
+// is always the first one in the output, and its action is the action of the
+// first input rule matching the packet.
+func resolveRulePrecedence(rules ContivRules) ContivRules {
+	closure := newRuleClosure()
+	for _, rule := range rules {
+		closure.add(rule, closure.rules)
+	}
+	return closure.resolve(rules)
+}
+
+// resolveClusterRulePrecedence is resolveRulePrecedence for the (ordered) rules
+// of cluster-wide policies followed by the rules of K8s policies. K8s rules
+// do not depend on their order - they are already resolved by the specificity
+// (see ContivRule.Compare) - therefore only the intersections involving cluster
+// rules are added into the closure, i.e. the closure of cluster rules and its
+// intersections with each K8s rule. For a packet matched by some cluster rules,
+// the intersection of those rules with any K8s rule matching the packet is more
+// specific than the K8s rule and carries the action of the first matching cluster
+// rule. Packets not matched by any cluster rule are matched only by K8s rules.
+func resolveClusterRulePrecedence(clusterRules, k8sRules ContivRules) ContivRules {
+	closure := newRuleClosure()
+	for _, rule := range clusterRules {
+		closure.add(rule, closure.rules)
+	}
+	clusterClosure := closure.rules[:len(closure.rules):len(closure.rules)]
+	for _, rule := range k8sRules {
+		closure.add(rule, clusterClosure)
+	}
+
+	rules := make(ContivRules, 0, len(clusterRules)+len(k8sRules))
+	rules = append(rules, clusterRules...)
+	rules = append(rules, k8sRules...)
+	return closure.resolve(rules)
+}
+
+// ruleClosure is a set of rules being closed under intersection,
+// de-duplicated by the matched traffic.
+type ruleClosure struct {
+	rules   ContivRules
+	matches map[ruleMatch]struct{}
+}
+
+// newRuleClosure returns an empty rule closure.
+func newRuleClosure() *ruleClosure {
+	return &ruleClosure{matches: make(map[ruleMatch]struct{})}
+}
+
+// add adds a copy of the rule and its intersections with the given rules
+// into the closure.
+func (c *ruleClosure) add(rule *renderer.ContivRule, intersectWith ContivRules) {
+	newRules := ContivRules{rule.Copy()}
+	for _, closureRule := range intersectWith {
+		if intersection := intersectRules(rule, closureRule); intersection != nil {
+			newRules = append(newRules, intersection)
+		}
+	}
+	for _, newRule := range newRules {
+		match := getRuleMatch(newRule)
+		if _, duplicate := c.matches[match]; !duplicate {
+			c.matches[match] = struct{}{}
+			c.rules = append(c.rules, newRule)
+		}
+	}
+}
+
+// resolve sets the action of each rule from the closure to the action of the first
+// rule from the given (ordered) input list which contains it, and returns the closure
+// ordered by ContivRule.Compare.
+func (c *ruleClosure) resolve(rules ContivRules) ContivRules {
+	for _, closureRule := range c.rules {
+		for _, rule := range rules {
+			if ruleContains(rule, closureRule) {
+				closureRule.Action = rule.Action
+				closureRule.Policies = rule.Policies
+				break
+			}
+		}
+	}
+
+	sort.Slice(c.rules, func(i, j int) bool {
+		return c.rules[i].Compare(c.rules[j]) < 0
+	})
+	return c.rules
+}
+
+// ruleMatch is a comparable representation of the traffic matched by a rule
+// (regardless of the action), used as a key to de-duplicate rules.
+// Two rules have the same ruleMatch if and only if ContivRule.Compare
+// considers them equal (up to the action).
+type ruleMatch struct {
+	srcNetwork  string
+	destNetwork string
+	protocol    renderer.ProtocolType
+	srcPort     uint16
+	destPort    uint16
+	destPortEnd uint16
+	icmpType    int16
+	icmpCode    int16
+}
+
+// getRuleMatch returns the ruleMatch of the given rule.
+func getRuleMatch(rule *renderer.ContivRule) ruleMatch {
+	match := ruleMatch{
+		srcNetwork:  netKey(rule.SrcNetwork),
+		destNetwork: netKey(rule.DestNetwork),
+		protocol:    rule.Protocol,
+	}
+	switch rule.Protocol {
+	case renderer.ANY:
+	case renderer.ICMP:
+		match.icmpType = rule.ICMPType
+		match.icmpCode = rule.ICMPCode
+	default:
+		match.srcPort = rule.SrcPort
+		match.destPort, match.destPortEnd = destPortRange(rule)
+	}
+	return match
+}
+
+// netKey returns the string representation of the network,
+// empty string for empty network (matching all IP addresses).
+func netKey(network *net.IPNet) string {
+	if network == nil || len(network.IP) == 0 {
+		return ""
+	}
+	return network.String()
+}
+
+// ruleContains returns true if all the traffic matched by <rule2> is also
+// matched by <rule>.
+func ruleContains(rule, rule2 *renderer.ContivRule) bool {
+	if !netContains(rule.SrcNetwork, rule2.SrcNetwork) ||
+		!netContains(rule.DestNetwork, rule2.DestNetwork) {
+		return false
+	}
+	if rule.Protocol == renderer.ANY {
+		return true
+	}
+	if rule.Protocol != rule2.Protocol {
+		return false
+	}
+	if rule.Protocol == renderer.ICMP {
+		return icmpContains(rule.ICMPType, rule2.ICMPType) && icmpContains(rule.ICMPCode, rule2.ICMPCode)
+	}
+	if !portContains(rule.SrcPort, rule2.SrcPort) {
+		return false
+	}
+	first, last := destPortRange(rule)
+	first2, last2 := destPortRange(rule2)
+	return first <= first2 && last2 <= last
+}
+
+// intersectRules returns rule matching the traffic matched by both <rule>
+// and <rule2>, or nil if there is no such traffic.
+func intersectRules(rule, rule2 *renderer.ContivRule) *renderer.ContivRule {
+	intersection := &renderer.ContivRule{Action: rule.Action}
+	if intersection.SrcNetwork = intersectNets(rule.SrcNetwork, rule2.SrcNetwork); intersection.SrcNetwork == nil {
+		return nil
+	}
+	if intersection.DestNetwork = intersectNets(rule.DestNetwork, rule2.DestNetwork); intersection.DestNetwork == nil {
+		return nil
+	}
+	switch {
+	case rule.Protocol == renderer.ANY:
+		intersection.Protocol = rule2.Protocol
+		intersection.SrcPort = rule2.SrcPort
+		intersection.DestPort = rule2.DestPort
+		intersection.DestPortEnd = rule2.DestPortEnd
+		intersection.ICMPType = rule2.ICMPType
+		intersection.ICMPCode = rule2.ICMPCode
+	case rule2.Protocol == renderer.ANY:
+		intersection.Protocol = rule.Protocol
+		intersection.SrcPort = rule.SrcPort
+		intersection.DestPort = rule.DestPort
+		intersection.DestPortEnd = rule.DestPortEnd
+		intersection.ICMPType = rule.ICMPType
+		intersection.ICMPCode = rule.ICMPCode
+	case rule.Protocol == renderer.ICMP && rule2.Protocol == renderer.ICMP:
+		var ok bool
+		intersection.Protocol = renderer.ICMP
+		if intersection.ICMPType, ok = intersectICMP(rule.ICMPType, rule2.ICMPType); !ok {
+			return nil
+		}
+		if intersection.ICMPCode, ok = intersectICMP(rule.ICMPCode, rule2.ICMPCode); !ok {
+			return nil
+		}
+	case rule.Protocol == rule2.Protocol:
+		var ok bool
+		intersection.Protocol = rule.Protocol
+		if intersection.SrcPort, ok = intersectPorts(rule.SrcPort, rule2.SrcPort); !ok {
+			return nil
+		}
+		if intersection.DestPort, intersection.DestPortEnd, ok = intersectDestPorts(rule, rule2); !ok {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return intersection
+}
+
+// netContains returns true if <net2> is a subnet of <net1>.
+// Empty network matches all IP addresses.
+func netContains(net1, net2 *net.IPNet) bool {
+	if len(net1.IP) == 0 {
+		return true
+	}
+	if len(net2.IP) == 0 {
+		return false
+	}
+	ones1, bits1 := net1.Mask.Size()
+	ones2, bits2 := net2.Mask.Size()
+	return bits1 == bits2 && ones1 <= ones2 && net1.Contains(net2.IP)
+}
+
+// intersectNets returns the intersection of two networks, or nil if they are
+// disjoint.
+func intersectNets(net1, net2 *net.IPNet) *net.IPNet {
+	if netContains(net1, net2) {
+		return net2
+	}
+	if netContains(net2, net1) {
+		return net1
+	}
+	return nil
+}
+
+// portContains returns true if <port> matches <port2>.
+func portContains(port, port2 uint16) bool {
+	return port == 0 || port == port2
+}
+
+// intersectPorts returns the intersection of two port matches.
+func intersectPorts(port, port2 uint16) (uint16, bool) {
+	if port == 0 {
+		return port2, true
+	}
+	if port2 == 0 || port == port2 {
+		return port, true
+	}
+	return 0, false
+}
+
+// destPortRange returns the (inclusive) range of destination ports matched
+// by the rule.
+func destPortRange(rule *renderer.ContivRule) (first, last uint16) {
+	if rule.DestPort == 0 {
+		return 0, math.MaxUint16
+	}
+	if rule.DestPortEnd > rule.DestPort {
+		return rule.DestPort, rule.DestPortEnd
+	}
+	return rule.DestPort, rule.DestPort
+}
+
+// destPortContains returns true if the destination port match of the rule
+// includes the given port.
+func destPortContains(rule *renderer.ContivRule, port uint16) bool {
+	first, last := destPortRange(rule)
+	return first <= port && port <= last
+}
+
+// intersectDestPorts returns the intersection of destination port matches
+// of two rules.
+func intersectDestPorts(rule, rule2 *renderer.ContivRule) (port, portEnd uint16, ok bool) {
+	first, last := destPortRange(rule)
+	first2, last2 := destPortRange(rule2)
+	if first2 > first {
+		first = first2
+	}
+	if last2 < last {
+		last = last2
+	}
+	switch {
+	case first > last:
+		return 0, 0, false
+	case first == 0 && last == math.MaxUint16:
+		return 0, 0, true
+	case first == last:
+		return first, 0, true
+	}
+	return first, last, true
+}
+
+// icmpContains returns true if ICMP type (or code) match <icmp> includes
+// the type (code) matched by <icmp2>.
+func icmpContains(icmp, icmp2 int16) bool {
+	return icmp == renderer.AnyICMP || icmp == icmp2
+}
+
+// intersectICMP returns the intersection of two ICMP type (or code) matches.
+func intersectICMP(icmp, icmp2 int16) (int16, bool) {
+	if icmp == renderer.AnyICMP {
+		return icmp2, true
+	}
+	if icmp2 == renderer.AnyICMP || icmp == icmp2 {
+		return icmp, true
+	}
+	return 0, false
+}
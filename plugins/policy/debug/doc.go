@@ -0,0 +1,32 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+// Package debug exposes the policy configuration of the node over the REST API
+// of the agent, to help answering questions like "why is this pod isolated?".
+//
+// For every pod configured on the node the dump includes the policies
+// selecting the pod, the ingress and egress ContivRules generated for it
+// by the Configurator (from the vswitch point of view) and, for every renderer,
+// the ID of the local table assigned to the pod together with the other pods
+// sharing the same table. Tables of renderer caches (local and global) are
+// dumped as well:
+//
+//	GET /contiv/v1/policy/pods
+//	GET /contiv/v1/policy/pods/{namespace}/{name}
+//	GET /contiv/v1/policy/tables
+//
+// netctl renders the dump across all nodes (see cmdimpl.PrintPolicies).
+package debug
@@ -0,0 +1,179 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"sort"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+	"github.com/contiv/vpp/plugins/policy/renderer/cache"
+)
+
+// Dump is the policy configuration of the node.
+type Dump struct {
+	Pods   []*PodDump   `json:"pods"`
+	Tables []*TableDump `json:"tables"`
+}
+
+// PodDump is the policy configuration of a single pod.
+type PodDump struct {
+	Pod         string   `json:"pod"`
+	IPAddresses []string `json:"ipAddresses"`
+
+	// Policies selecting the pod.
+	Policies []string `json:"policies"`
+
+	// Rules generated for the pod, from the vswitch point of view.
+	Ingress []string `json:"ingress"`
+	Egress  []string `json:"egress"`
+
+	// Tables assigned to the pod by the renderers.
+	Tables []*PodTable `json:"tables"`
+}
+
+// PodTable describes the local table of a pod in the cache of a renderer.
+type PodTable struct {
+	Renderer string `json:"renderer"`
+
+	// LocalTable is the ID of the local table, empty if the pod is not isolated.
+	LocalTable string `json:"localTable,omitempty"`
+
+	// SharedWith lists other pods with the same local table.
+	SharedWith []string `json:"sharedWith,omitempty"`
+}
+
+// TableDump is a table of the cache of a renderer.
+type TableDump struct {
+	Renderer string   `json:"renderer"`
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Pods     []string `json:"pods,omitempty"`
+	Rules    []string `json:"rules"`
+}
+
+// RendererCache is a view of the cache of a named renderer.
+type RendererCache struct {
+	Renderer string
+	View     cache.View
+}
+
+// NewDump builds the dump from the pod configurations of the Configurator
+// and the caches of the renderers.
+func NewDump(podConfigs []*configurator.PodPolicyConfig, rendererCaches []RendererCache) *Dump {
+	dump := &Dump{
+		Pods:   []*PodDump{},
+		Tables: []*TableDump{},
+	}
+	for _, podConfig := range podConfigs {
+		podDump := &PodDump{
+			Pod:         podConfig.Pod.String(),
+			IPAddresses: []string{},
+			Policies:    []string{},
+			Ingress:     ruleStrings(podConfig.Ingress),
+			Egress:      ruleStrings(podConfig.Egress),
+			Tables:      []*PodTable{},
+		}
+		for _, ipNet := range podConfig.IPAddresses {
+			podDump.IPAddresses = append(podDump.IPAddresses, ipNet.IP.String())
+		}
+		for _, policy := range podConfig.Policies {
+			podDump.Policies = append(podDump.Policies, policyName(policy.ID))
+		}
+		for _, rendererCache := range rendererCaches {
+			podTable := &PodTable{Renderer: rendererCache.Renderer}
+			if table := rendererCache.View.GetLocalTableByPod(podConfig.Pod); table != nil {
+				podTable.LocalTable = table.ID
+				for _, pod := range podNames(table.Pods) {
+					if pod != podDump.Pod {
+						podTable.SharedWith = append(podTable.SharedWith, pod)
+					}
+				}
+			}
+			podDump.Tables = append(podDump.Tables, podTable)
+		}
+		dump.Pods = append(dump.Pods, podDump)
+	}
+
+	for _, rendererCache := range rendererCaches {
+		// Local tables ordered by ID, global table last.
+		localTables := make(map[string]*cache.ContivRuleTable)
+		for pod := range rendererCache.View.GetIsolatedPods() {
+			if table := rendererCache.View.GetLocalTableByPod(pod); table != nil {
+				localTables[table.ID] = table
+			}
+		}
+		tableIDs := []string{}
+		for tableID := range localTables {
+			tableIDs = append(tableIDs, tableID)
+		}
+		sort.Strings(tableIDs)
+		tables := []*cache.ContivRuleTable{}
+		for _, tableID := range tableIDs {
+			tables = append(tables, localTables[tableID])
+		}
+		tables = append(tables, rendererCache.View.GetGlobalTable())
+
+		for _, table := range tables {
+			dump.Tables = append(dump.Tables, &TableDump{
+				Renderer: rendererCache.Renderer,
+				ID:       table.ID,
+				Type:     table.Type.String(),
+				Pods:     podNames(table.Pods),
+				Rules:    ruleStrings(table.Rules[:table.NumOfRules]),
+			})
+		}
+	}
+	return dump
+}
+
+// GetPod returns the dump of the given pod.
+func (d *Dump) GetPod(pod podmodel.ID) (podDump *PodDump, found bool) {
+	for _, podDump := range d.Pods {
+		if podDump.Pod == pod.String() {
+			return podDump, true
+		}
+	}
+	return nil, false
+}
+
+// ruleStrings converts rules into their string representations.
+func ruleStrings(rules []*renderer.ContivRule) []string {
+	strs := []string{}
+	for _, rule := range rules {
+		strs = append(strs, rule.String())
+	}
+	return strs
+}
+
+// podNames returns sorted names of the pods from the set.
+func podNames(pods cache.PodSet) (names []string) {
+	for pod := range pods {
+		names = append(names, pod.String())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// policyName returns name of the policy as used in the outputs.
+func policyName(policy policymodel.ID) string {
+	if policy.Namespace == "" {
+		// cluster-wide policy
+		return policy.Name
+	}
+	return policy.Namespace + "/" + policy.Name
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/onsi/gomega"
+	"github.com/unrolled/render"
+
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+	"github.com/contiv/vpp/plugins/policy/renderer/cache"
+	"github.com/contiv/vpp/plugins/policy/utils"
+)
+
+var (
+	pod1 = podmodel.ID{Name: "pod1", Namespace: "default"}
+	pod2 = podmodel.ID{Name: "pod2", Namespace: "default"}
+	pod3 = podmodel.ID{Name: "pod3", Namespace: "default"}
+)
+
+// staticState returns the same dump for every request.
+type staticState struct {
+	dump *Dump
+}
+
+func (ss *staticState) Dump() *Dump {
+	return ss.dump
+}
+
+func ipNetwork(addr string) *net.IPNet {
+	_, network, _ := net.ParseCIDR(addr)
+	return network
+}
+
+func testDump() *Dump {
+	logger := logrus.DefaultLogger()
+
+	// pod1 and pod2 are selected by the same policy, pod3 is not isolated.
+	policy := &configurator.ContivPolicy{
+		ID:   policymodel.ID{Name: "allow-http", Namespace: "default"},
+		Type: configurator.PolicyIngress,
+	}
+	egress := []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  ipNetwork("10.0.0.0/8"),
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.TCP,
+			DestPort:    80,
+		},
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.ANY,
+		},
+	}
+	podIPs := map[podmodel.ID][]*net.IPNet{
+		pod1: utils.GetOneHostSubnets("192.168.1.1"),
+		pod2: utils.GetOneHostSubnets("192.168.1.2"),
+		pod3: utils.GetOneHostSubnets("192.168.1.3"),
+	}
+	podConfigs := []*configurator.PodPolicyConfig{
+		{Pod: pod1, IPAddresses: podIPs[pod1], Policies: configurator.ContivPolicies{policy}, Egress: egress},
+		{Pod: pod2, IPAddresses: podIPs[pod2], Policies: configurator.ContivPolicies{policy}, Egress: egress},
+		{Pod: pod3, IPAddresses: podIPs[pod3]},
+	}
+
+	// Render the configuration into the cache.
+	rendererCache := &cache.RendererCache{
+		Deps: cache.Deps{
+			Log: logger,
+		},
+	}
+	rendererCache.Init(cache.EgressOrientation)
+	txn := rendererCache.NewTxn()
+	for _, podConfig := range podConfigs {
+		txn.Update(podConfig.Pod, &cache.PodConfig{
+			PodIPs:  podConfig.IPAddresses,
+			Ingress: podConfig.Ingress,
+			Egress:  podConfig.Egress,
+		})
+	}
+	gomega.Expect(txn.Commit()).To(gomega.BeNil())
+
+	return NewDump(podConfigs, []RendererCache{{Renderer: "ACL", View: rendererCache}})
+}
+
+func TestNewDump(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	dump := testDump()
+
+	// Pods.
+	gomega.Expect(dump.Pods).To(gomega.HaveLen(3))
+	pod1Dump, found := dump.GetPod(pod1)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(pod1Dump.IPAddresses).To(gomega.Equal([]string{"192.168.1.1"}))
+	gomega.Expect(pod1Dump.Policies).To(gomega.Equal([]string{"default/allow-http"}))
+	gomega.Expect(pod1Dump.Ingress).To(gomega.BeEmpty())
+	gomega.Expect(pod1Dump.Egress).To(gomega.HaveLen(2))
+	gomega.Expect(pod1Dump.Tables).To(gomega.HaveLen(1))
+	gomega.Expect(pod1Dump.Tables[0].Renderer).To(gomega.Equal("ACL"))
+	gomega.Expect(pod1Dump.Tables[0].LocalTable).ToNot(gomega.BeEmpty())
+	gomega.Expect(pod1Dump.Tables[0].SharedWith).To(gomega.Equal([]string{pod2.String()}))
+
+	pod2Dump, found := dump.GetPod(pod2)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(pod2Dump.Tables[0].LocalTable).To(gomega.Equal(pod1Dump.Tables[0].LocalTable))
+	gomega.Expect(pod2Dump.Tables[0].SharedWith).To(gomega.Equal([]string{pod1.String()}))
+
+	pod3Dump, found := dump.GetPod(pod3)
+	gomega.Expect(found).To(gomega.BeTrue())
+	gomega.Expect(pod3Dump.Policies).To(gomega.BeEmpty())
+	gomega.Expect(pod3Dump.Tables[0].LocalTable).To(gomega.BeEmpty())
+
+	_, found = dump.GetPod(podmodel.ID{Name: "pod4", Namespace: "default"})
+	gomega.Expect(found).To(gomega.BeFalse())
+
+	// Tables: one local shared by pod1 and pod2, global last.
+	gomega.Expect(dump.Tables).To(gomega.HaveLen(2))
+	gomega.Expect(dump.Tables[0].ID).To(gomega.Equal(pod1Dump.Tables[0].LocalTable))
+	gomega.Expect(dump.Tables[0].Type).To(gomega.Equal(cache.Local.String()))
+	gomega.Expect(dump.Tables[0].Pods).To(gomega.Equal([]string{pod1.String(), pod2.String()}))
+	gomega.Expect(dump.Tables[0].Rules).To(gomega.HaveLen(2))
+	gomega.Expect(dump.Tables[1].Type).To(gomega.Equal(cache.Global.String()))
+}
+
+func TestRESTHandler(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	handler := &RESTHandler{
+		Deps: Deps{
+			Log:   logrus.DefaultLogger(),
+			State: &staticState{dump: testDump()},
+		},
+	}
+	formatter := render.New()
+	router := mux.NewRouter()
+	router.HandleFunc(PodsURL, handler.podsHandler(formatter)).Methods("GET")
+	router.HandleFunc(podURL, handler.podHandler(formatter)).Methods("GET")
+	router.HandleFunc(TablesURL, handler.tablesHandler(formatter)).Methods("GET")
+
+	get := func(url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", url, nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// All pods.
+	resp := get(PodsURL)
+	gomega.Expect(resp.Code).To(gomega.Equal(http.StatusOK))
+	pods := []*PodDump{}
+	gomega.Expect(json.Unmarshal(resp.Body.Bytes(), &pods)).To(gomega.Succeed())
+	gomega.Expect(pods).To(gomega.HaveLen(3))
+
+	// Single pod.
+	resp = get(PodsURL + "/default/pod2")
+	gomega.Expect(resp.Code).To(gomega.Equal(http.StatusOK))
+	pod := &PodDump{}
+	gomega.Expect(json.Unmarshal(resp.Body.Bytes(), pod)).To(gomega.Succeed())
+	gomega.Expect(pod.Pod).To(gomega.Equal(pod2.String()))
+	gomega.Expect(pod.Tables[0].SharedWith).To(gomega.Equal([]string{pod1.String()}))
+
+	// Unknown pod.
+	resp = get(PodsURL + "/default/pod4")
+	gomega.Expect(resp.Code).To(gomega.Equal(http.StatusNotFound))
+
+	// Tables.
+	resp = get(TablesURL)
+	gomega.Expect(resp.Code).To(gomega.Equal(http.StatusOK))
+	tables := []*TableDump{}
+	gomega.Expect(json.Unmarshal(resp.Body.Bytes(), &tables)).To(gomega.Succeed())
+	gomega.Expect(tables).To(gomega.HaveLen(2))
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+)
+
+const (
+	// Prefix is versioned prefix for REST urls
+	Prefix = "/contiv/v1/"
+	// PodsURL is versioned URL (using prefix) for the REST endpoint dumping
+	// the policy configuration of all pods on the node
+	PodsURL = Prefix + "policy/pods"
+	// TablesURL is versioned URL (using prefix) for the REST endpoint dumping
+	// the tables of the renderer caches
+	TablesURL = Prefix + "policy/tables"
+
+	namespaceVar = "namespace"
+	nameVar      = "name"
+	podURL       = PodsURL + "/{" + namespaceVar + "}/{" + nameVar + "}"
+)
+
+// StateSource provides the current policy configuration of the node.
+type StateSource interface {
+	// Dump returns the current policy configuration.
+	Dump() *Dump
+}
+
+// RESTHandler exposes the policy configuration of the node over the REST API.
+type RESTHandler struct {
+	Deps
+}
+
+// Deps lists dependencies of RESTHandler.
+type Deps struct {
+	Log          logging.Logger
+	HTTPHandlers rest.HTTPHandlers
+	State        StateSource
+}
+
+// Init registers the REST handlers.
+func (h *RESTHandler) Init() error {
+	if h.HTTPHandlers == nil {
+		h.Log.Warnf("No http handler provided, skipping registration of policy debug REST handlers")
+		return nil
+	}
+	h.HTTPHandlers.RegisterHTTPHandler(PodsURL, h.podsHandler, "GET")
+	h.HTTPHandlers.RegisterHTTPHandler(podURL, h.podHandler, "GET")
+	h.HTTPHandlers.RegisterHTTPHandler(TablesURL, h.tablesHandler, "GET")
+	h.Log.Infof("Policy debug REST handlers registered: GET %v, GET %v, GET %v",
+		PodsURL, podURL, TablesURL)
+	return nil
+}
+
+// podsHandler dumps the policy configuration of all pods.
+func (h *RESTHandler) podsHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		formatter.JSON(w, http.StatusOK, h.State.Dump().Pods)
+	}
+}
+
+// podHandler dumps the policy configuration of a single pod.
+func (h *RESTHandler) podHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		pod := podmodel.ID{Namespace: vars[namespaceVar], Name: vars[nameVar]}
+		podDump, found := h.State.Dump().GetPod(pod)
+		if !found {
+			formatter.JSON(w, http.StatusNotFound, "pod not configured on this node")
+			return
+		}
+		formatter.JSON(w, http.StatusOK, podDump)
+	}
+}
+
+// tablesHandler dumps the tables of the renderer caches.
+func (h *RESTHandler) tablesHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		formatter.JSON(w, http.StatusOK, h.State.Dump().Tables)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package dnscache
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+)
+
+// Minimal support for the DNS wire format (RFC 1035) - only what is needed
+// to build A/AAAA queries and to extract addresses from the responses.
+
+const (
+	dnsHeaderLen = 12
+	dnsMaxLabels = 128
+
+	dnsTypeA     = 1
+	dnsTypeCNAME = 5
+	dnsTypeAAAA  = 28
+	dnsClassIN   = 1
+
+	dnsFlagResponse  = 1 << 15
+	dnsFlagRecursion = 1 << 8
+	dnsRcodeMask     = 0xf
+)
+
+var errDNSTruncated = errors.New("truncated DNS message")
+
+// dnsRecord is an IP address learned for a domain name from a DNS response.
+type dnsRecord struct {
+	name string
+	ip   net.IP
+	ttl  uint32
+}
+
+// buildDNSQuery builds a recursive query for the given domain name and record type.
+func buildDNSQuery(id uint16, name string, qtype uint16) ([]byte, error) {
+	msg := make([]byte, dnsHeaderLen, dnsHeaderLen+len(name)+6)
+	binary.BigEndian.PutUint16(msg[0:], id)
+	binary.BigEndian.PutUint16(msg[2:], dnsFlagRecursion)
+	binary.BigEndian.PutUint16(msg[4:], 1) // QDCOUNT
+	for _, label := range strings.Split(NormalizeFQDN(name), ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return nil, errors.New("invalid domain name: " + name)
+		}
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0, byte(qtype>>8), byte(qtype), 0, dnsClassIN)
+	return msg, nil
+}
+
+// parseDNSResponse returns all IPv4 and IPv6 addresses from the answer section
+// of a DNS response. Addresses of canonical names are also returned for all
+// aliases pointing to them (with the smallest TTL along the CNAME chain).
+func parseDNSResponse(msg []byte) (id uint16, records []dnsRecord, err error) {
+	if len(msg) < dnsHeaderLen {
+		return 0, nil, errDNSTruncated
+	}
+	id = binary.BigEndian.Uint16(msg[0:])
+	flags := binary.BigEndian.Uint16(msg[2:])
+	if flags&dnsFlagResponse == 0 {
+		return id, nil, errors.New("DNS message is not a response")
+	}
+	if flags&dnsRcodeMask != 0 {
+		// Error response, e.g. NXDOMAIN.
+		return id, nil, nil
+	}
+	qdCount := int(binary.BigEndian.Uint16(msg[4:]))
+	anCount := int(binary.BigEndian.Uint16(msg[6:]))
+
+	// Skip questions.
+	offset := dnsHeaderLen
+	for i := 0; i < qdCount; i++ {
+		if _, offset, err = readDNSName(msg, offset); err != nil {
+			return id, nil, err
+		}
+		offset += 4 // QTYPE + QCLASS
+	}
+
+	// Read answers.
+	type alias struct {
+		name string
+		ttl  uint32
+	}
+	aliases := make(map[string][]alias) // canonical name -> aliases
+	for i := 0; i < anCount; i++ {
+		var name string
+		if name, offset, err = readDNSName(msg, offset); err != nil {
+			return id, nil, err
+		}
+		if offset+10 > len(msg) {
+			return id, nil, errDNSTruncated
+		}
+		rrType := binary.BigEndian.Uint16(msg[offset:])
+		rrClass := binary.BigEndian.Uint16(msg[offset+2:])
+		ttl := binary.BigEndian.Uint32(msg[offset+4:])
+		rdLen := int(binary.BigEndian.Uint16(msg[offset+8:]))
+		offset += 10
+		if offset+rdLen > len(msg) {
+			return id, nil, errDNSTruncated
+		}
+		rdata := msg[offset : offset+rdLen]
+		if rrClass == dnsClassIN {
+			switch {
+			case rrType == dnsTypeA && rdLen == net.IPv4len:
+				records = append(records, dnsRecord{name: name, ip: net.IP(append([]byte{}, rdata...)), ttl: ttl})
+			case rrType == dnsTypeAAAA && rdLen == net.IPv6len:
+				records = append(records, dnsRecord{name: name, ip: net.IP(append([]byte{}, rdata...)), ttl: ttl})
+			case rrType == dnsTypeCNAME:
+				target, _, err := readDNSName(msg, offset)
+				if err != nil {
+					return id, nil, err
+				}
+				aliases[target] = append(aliases[target], alias{name: name, ttl: ttl})
+			}
+		}
+		offset += rdLen
+	}
+
+	// Propagate addresses to aliases.
+	resolved := len(records)
+	for i := 0; i < resolved; i++ {
+		visited := map[string]struct{}{records[i].name: {}}
+		queue := []dnsRecord{records[i]}
+		for len(queue) > 0 {
+			record := queue[0]
+			queue = queue[1:]
+			for _, alias := range aliases[record.name] {
+				if _, loop := visited[alias.name]; loop {
+					continue
+				}
+				visited[alias.name] = struct{}{}
+				aliasRecord := dnsRecord{name: alias.name, ip: record.ip, ttl: record.ttl}
+				if alias.ttl < aliasRecord.ttl {
+					aliasRecord.ttl = alias.ttl
+				}
+				records = append(records, aliasRecord)
+				queue = append(queue, aliasRecord)
+			}
+		}
+	}
+	return id, records, nil
+}
+
+// readDNSName reads (possibly compressed) domain name starting at the given
+// offset. Returns the normalized name and the offset following the name.
+func readDNSName(msg []byte, offset int) (name string, next int, err error) {
+	var labels []string
+	next = -1
+	for jumps := 0; ; {
+		if offset >= len(msg) {
+			return "", 0, errDNSTruncated
+		}
+		length := int(msg[offset])
+		switch {
+		case length == 0:
+			if next < 0 {
+				next = offset + 1
+			}
+			return NormalizeFQDN(strings.Join(labels, ".")), next, nil
+		case length&0xc0 == 0xc0:
+			// Compression pointer.
+			if offset+1 >= len(msg) {
+				return "", 0, errDNSTruncated
+			}
+			if next < 0 {
+				next = offset + 2
+			}
+			if jumps++; jumps > dnsMaxLabels {
+				return "", 0, errors.New("too many compression pointers in DNS message")
+			}
+			offset = int(binary.BigEndian.Uint16(msg[offset:]) & 0x3fff)
+		default:
+			if offset+1+length > len(msg) {
+				return "", 0, errDNSTruncated
+			}
+			labels = append(labels, string(msg[offset+1:offset+1+length]))
+			if len(labels) > dnsMaxLabels {
+				return "", 0, errors.New("too many labels in DNS name")
+			}
+			offset += 1 + length
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package dnscache
+
+import (
+	"net"
+	"strings"
+)
+
+// DNSCacheAPI defines API of DNS Cache - a cache of IP addresses learned
+// for domain names referenced by FQDN-based policy rules.
+// The addresses are learned from DNS responses snooped by the DNS proxy
+// (as received by the pods) and by actively resolving the tracked domain names.
+// Every learned address expires once its TTL (at least the configured MinTTL)
+// elapses, unless it is refreshed by another DNS response.
+type DNSCacheAPI interface {
+	// LookupFQDN returns all (not expired) IP addresses learned for domain names
+	// matching the given FQDN. FQDN is either an exact domain name
+	// or a wildcard "*.<domain>" matching all subdomains of <domain>.
+	LookupFQDN(fqdn string) []net.IP
+
+	// Track replaces the set of FQDNs referenced by policy rules.
+	// Exact domain names from the set are actively resolved and kept resolved
+	// as the TTLs of the learned addresses expire.
+	Track(fqdns []string)
+
+	// Watch subscribes for notifications about changes in the learned addresses.
+	// Each notification carries the list of domain names whose set of IP addresses
+	// has changed (use MatchFQDN to find out which FQDNs are affected).
+	Watch(subscriber chan<- []string)
+}
+
+// MatchFQDN returns true if the given domain name is matched by the FQDN,
+// which is either an exact domain name or a wildcard "*.<domain>".
+// Both arguments are expected to be normalized (see NormalizeFQDN).
+func MatchFQDN(fqdn, name string) bool {
+	if strings.HasPrefix(fqdn, "*.") {
+		return strings.HasSuffix(name, fqdn[1:])
+	}
+	return fqdn == name
+}
+
+// NormalizeFQDN converts domain name into the form used by the cache:
+// lower-case and without the trailing dot.
+func NormalizeFQDN(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
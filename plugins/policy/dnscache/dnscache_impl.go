@@ -0,0 +1,371 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package dnscache
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/logging"
+
+	"github.com/contiv/vpp/plugins/contiv"
+)
+
+const (
+	// defaultMinTTL is used when MinTTL is not configured.
+	defaultMinTTL = 60 * time.Second
+
+	// sweepPeriod is the period of checking for expired addresses
+	// and for tracked names to refresh.
+	sweepPeriod = time.Second
+
+	// refreshAhead is how long before the expiration the tracked names
+	// get re-resolved.
+	refreshAhead = 5 * time.Second
+
+	// retryPeriod is the minimal delay between two resolutions of the same name.
+	retryPeriod = 10 * time.Second
+
+	// queryTimeout limits the time to wait for a DNS response.
+	queryTimeout = 5 * time.Second
+)
+
+// DNSCache implements DNSCacheAPI.
+type DNSCache struct {
+	Deps
+
+	sync.Mutex
+	minTTL      time.Duration
+	entries     map[string]map[string]time.Time // name -> IP -> expiration
+	tracked     map[string]struct{}             // FQDNs referenced by policies
+	lastResolve map[string]time.Time            // name -> time of the last resolution
+	subscribers []chan<- []string
+
+	proxy  *dnsProxy
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// for unit tests
+	clock func() time.Time
+}
+
+// Deps lists dependencies of DNSCache.
+type Deps struct {
+	Log    logging.Logger
+	Contiv contiv.API /* to get the DNS proxy configuration */
+}
+
+// Init initializes DNS cache and starts the DNS proxy if enabled.
+func (dc *DNSCache) Init() error {
+	dc.entries = make(map[string]map[string]time.Time)
+	dc.tracked = make(map[string]struct{})
+	dc.lastResolve = make(map[string]time.Time)
+	if dc.clock == nil {
+		dc.clock = time.Now
+	}
+	dc.ctx, dc.cancel = context.WithCancel(context.Background())
+
+	config := dc.Contiv.GetDNSProxyConfig()
+	dc.minTTL = time.Duration(config.MinTTL) * time.Second
+	if dc.minTTL == 0 {
+		dc.minTTL = defaultMinTTL
+	}
+	if config.ListenAddress != "" {
+		if config.UpstreamServer == "" {
+			dc.Log.Warn("DNS proxy requires UpstreamServer to be configured, proxy is disabled")
+		} else {
+			var err error
+			dc.proxy, err = newDNSProxy(dc.Log, config.ListenAddress, config.UpstreamServer, dc.learn)
+			if err != nil {
+				return err
+			}
+			dc.wg.Add(1)
+			go dc.proxy.serve(&dc.wg)
+		}
+	}
+
+	dc.wg.Add(1)
+	go dc.run()
+	return nil
+}
+
+// Close stops the DNS proxy and the periodic refresh of tracked names.
+func (dc *DNSCache) Close() error {
+	dc.cancel()
+	if dc.proxy != nil {
+		dc.proxy.close()
+	}
+	dc.wg.Wait()
+	return nil
+}
+
+// LookupFQDN returns all (not expired) IP addresses learned for domain names
+// matching the given FQDN.
+func (dc *DNSCache) LookupFQDN(fqdn string) (ips []net.IP) {
+	dc.Lock()
+	defer dc.Unlock()
+
+	fqdn = NormalizeFQDN(fqdn)
+	now := dc.clock()
+	for name, addrs := range dc.entries {
+		if !MatchFQDN(fqdn, name) {
+			continue
+		}
+		for addr, expiration := range addrs {
+			if now.Before(expiration) {
+				ips = append(ips, net.ParseIP(addr))
+			}
+		}
+	}
+	// Return the same output for the same state.
+	sort.Slice(ips, func(i, j int) bool {
+		return ips[i].String() < ips[j].String()
+	})
+	return ips
+}
+
+// Track replaces the set of FQDNs referenced by policy rules.
+func (dc *DNSCache) Track(fqdns []string) {
+	dc.Lock()
+	defer dc.Unlock()
+
+	dc.tracked = make(map[string]struct{})
+	for _, fqdn := range fqdns {
+		dc.tracked[NormalizeFQDN(fqdn)] = struct{}{}
+	}
+	// Forget addresses of names that are no longer referenced.
+	for name := range dc.entries {
+		if !dc.isTracked(name) {
+			delete(dc.entries, name)
+		}
+	}
+	for name := range dc.lastResolve {
+		if _, tracked := dc.tracked[name]; !tracked {
+			delete(dc.lastResolve, name)
+		}
+	}
+}
+
+// Watch subscribes for notifications about changes in the learned addresses.
+func (dc *DNSCache) Watch(subscriber chan<- []string) {
+	dc.Lock()
+	defer dc.Unlock()
+	dc.subscribers = append(dc.subscribers, subscriber)
+}
+
+// learn stores addresses from a DNS response (snooped by the proxy or received
+// for an active resolution) and notifies subscribers about changed names.
+func (dc *DNSCache) learn(msg []byte) {
+	_, records, err := parseDNSResponse(msg)
+	if err != nil {
+		dc.Log.WithField("err", err).Debug("Failed to parse DNS response")
+		return
+	}
+	dc.learnRecords(records)
+}
+
+// learnRecords stores the given records and notifies subscribers about
+// changed names.
+func (dc *DNSCache) learnRecords(records []dnsRecord) {
+	dc.Lock()
+	changed := make(map[string]struct{})
+	now := dc.clock()
+	for _, record := range records {
+		if !dc.isTracked(record.name) {
+			continue
+		}
+		ttl := time.Duration(record.ttl) * time.Second
+		if ttl < dc.minTTL {
+			ttl = dc.minTTL
+		}
+		addrs, hasName := dc.entries[record.name]
+		if !hasName {
+			addrs = make(map[string]time.Time)
+			dc.entries[record.name] = addrs
+		}
+		addr := record.ip.String()
+		expiration, hasAddr := addrs[addr]
+		if !hasAddr || !now.Before(expiration) {
+			changed[record.name] = struct{}{}
+			dc.Log.WithFields(logging.Fields{
+				"name": record.name,
+				"ip":   addr,
+				"ttl":  ttl,
+			}).Debug("Learned new IP address for a tracked domain name")
+		}
+		if !hasAddr || now.Add(ttl).After(expiration) {
+			addrs[addr] = now.Add(ttl)
+		}
+	}
+	dc.Unlock()
+	dc.notify(changed)
+}
+
+// expire removes expired addresses and notifies subscribers about changed names.
+func (dc *DNSCache) expire() {
+	dc.Lock()
+	changed := make(map[string]struct{})
+	now := dc.clock()
+	for name, addrs := range dc.entries {
+		for addr, expiration := range addrs {
+			if !now.Before(expiration) {
+				delete(addrs, addr)
+				changed[name] = struct{}{}
+				dc.Log.WithFields(logging.Fields{
+					"name": name,
+					"ip":   addr,
+				}).Debug("IP address of a tracked domain name has expired")
+			}
+		}
+		if len(addrs) == 0 {
+			delete(dc.entries, name)
+		}
+	}
+	dc.Unlock()
+	dc.notify(changed)
+}
+
+// namesToResolve returns tracked exact domain names that are not resolved yet
+// or whose addresses are about to expire.
+func (dc *DNSCache) namesToResolve() (names []string) {
+	dc.Lock()
+	defer dc.Unlock()
+
+	now := dc.clock()
+	for name := range dc.tracked {
+		if strings.HasPrefix(name, "*.") {
+			// Wildcards can be learned only from the snooped responses.
+			continue
+		}
+		if last, resolved := dc.lastResolve[name]; resolved && now.Sub(last) < retryPeriod {
+			continue
+		}
+		refresh := true
+		for _, expiration := range dc.entries[name] {
+			if expiration.Sub(now) > refreshAhead {
+				refresh = false
+				break
+			}
+		}
+		if refresh {
+			dc.lastResolve[name] = now
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// run periodically removes expired addresses and re-resolves tracked names.
+func (dc *DNSCache) run() {
+	defer dc.wg.Done()
+	ticker := time.NewTicker(sweepPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			dc.expire()
+			for _, name := range dc.namesToResolve() {
+				dc.wg.Add(1)
+				go dc.resolve(name)
+			}
+		case <-dc.ctx.Done():
+			return
+		}
+	}
+}
+
+// resolve actively resolves the given domain name - either via the upstream
+// DNS server of the proxy (to learn the TTLs) or using the system resolver.
+func (dc *DNSCache) resolve(name string) {
+	defer dc.wg.Done()
+	ctx, cancel := context.WithTimeout(dc.ctx, queryTimeout)
+	defer cancel()
+
+	upstream := dc.Contiv.GetDNSProxyConfig().UpstreamServer
+	if upstream == "" {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, name)
+		if err != nil {
+			dc.Log.WithFields(logging.Fields{"name": name, "err": err}).Debug("Failed to resolve domain name")
+			return
+		}
+		records := []dnsRecord{}
+		for _, addr := range addrs {
+			// TTL is not known - keep for MinTTL.
+			records = append(records, dnsRecord{name: name, ip: addr.IP})
+		}
+		dc.learnRecords(records)
+		return
+	}
+
+	for _, qtype := range []uint16{dnsTypeA, dnsTypeAAAA} {
+		query, err := buildDNSQuery(uint16(rand.Uint32()), name, qtype)
+		if err != nil {
+			dc.Log.WithField("err", err).Warn("Failed to build DNS query")
+			return
+		}
+		response, err := exchangeDNS(ctx, upstream, query)
+		if err != nil {
+			dc.Log.WithFields(logging.Fields{"name": name, "err": err}).Debug("Failed to resolve domain name")
+			continue
+		}
+		dc.learn(response)
+	}
+}
+
+// notify sends the list of changed names to all subscribers.
+func (dc *DNSCache) notify(changed map[string]struct{}) {
+	if len(changed) == 0 {
+		return
+	}
+	names := []string{}
+	for name := range changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dc.Lock()
+	subscribers := append([]chan<- []string{}, dc.subscribers...)
+	dc.Unlock()
+	for _, subscriber := range subscribers {
+		select {
+		case subscriber <- names:
+		case <-dc.ctx.Done():
+			return
+		}
+	}
+}
+
+// isTracked returns true if the domain name is matched by any of the tracked FQDNs.
+// The method expects the cache to be locked.
+func (dc *DNSCache) isTracked(name string) bool {
+	if _, tracked := dc.tracked[name]; tracked {
+		return true
+	}
+	for fqdn := range dc.tracked {
+		if MatchFQDN(fqdn, name) {
+			return true
+		}
+	}
+	return false
+}
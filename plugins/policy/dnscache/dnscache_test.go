@@ -0,0 +1,184 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package dnscache
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	. "github.com/contiv/vpp/mock/contiv"
+)
+
+// dnsAnswer is an answer to be encoded into a test DNS response.
+type dnsAnswer struct {
+	rrType uint16
+	ttl    uint32
+	rdata  []byte
+}
+
+// buildDNSResponse builds response to the given query. The name of every
+// answer is a compression pointer to the question. CNAME rdata is expected
+// to be already encoded.
+func buildDNSResponse(query []byte, answers ...dnsAnswer) []byte {
+	msg := append([]byte{}, query...)
+	binary.BigEndian.PutUint16(msg[2:], dnsFlagResponse|dnsFlagRecursion)
+	binary.BigEndian.PutUint16(msg[6:], uint16(len(answers)))
+	for _, answer := range answers {
+		rr := make([]byte, 12)
+		binary.BigEndian.PutUint16(rr[0:], 0xc000|dnsHeaderLen)
+		binary.BigEndian.PutUint16(rr[2:], answer.rrType)
+		binary.BigEndian.PutUint16(rr[4:], dnsClassIN)
+		binary.BigEndian.PutUint32(rr[6:], answer.ttl)
+		binary.BigEndian.PutUint16(rr[10:], uint16(len(answer.rdata)))
+		msg = append(msg, rr...)
+		msg = append(msg, answer.rdata...)
+	}
+	return msg
+}
+
+func newTestCache(now *time.Time) *DNSCache {
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	dnsCache := &DNSCache{
+		Deps: Deps{
+			Log:    logger,
+			Contiv: NewMockContiv(),
+		},
+		clock: func() time.Time { return *now },
+	}
+	gomega.Expect(dnsCache.Init()).To(gomega.BeNil())
+	return dnsCache
+}
+
+// lookup returns IP addresses of the FQDN as strings.
+func lookup(dnsCache *DNSCache, fqdn string) (ips []string) {
+	for _, ip := range dnsCache.LookupFQDN(fqdn) {
+		ips = append(ips, ip.String())
+	}
+	return ips
+}
+
+func TestParseDNSResponse(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	query, err := buildDNSQuery(1234, "API.example.com.", dnsTypeA)
+	gomega.Expect(err).To(gomega.BeNil())
+	id, records, err := parseDNSResponse(query)
+	gomega.Expect(err).ToNot(gomega.BeNil())
+
+	// api.example.com CNAME lb.example.net (TTL 30); lb.example.net A 10.0.0.1 (TTL 300)
+	cname := []byte{2, 'l', 'b', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'n', 'e', 't', 0}
+	response := buildDNSResponse(query,
+		dnsAnswer{rrType: dnsTypeCNAME, ttl: 30, rdata: cname})
+	// Answer for the canonical name with a pointer to the CNAME rdata.
+	cnameOffset := len(response) - len(cname)
+	rr := make([]byte, 12)
+	binary.BigEndian.PutUint16(rr[0:], 0xc000|uint16(cnameOffset))
+	binary.BigEndian.PutUint16(rr[2:], dnsTypeA)
+	binary.BigEndian.PutUint16(rr[4:], dnsClassIN)
+	binary.BigEndian.PutUint32(rr[6:], 300)
+	binary.BigEndian.PutUint16(rr[10:], net.IPv4len)
+	response = append(response, rr...)
+	response = append(response, 10, 0, 0, 1)
+	binary.BigEndian.PutUint16(response[6:], 2)
+
+	id, records, err = parseDNSResponse(response)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(id).To(gomega.BeEquivalentTo(1234))
+	gomega.Expect(records).To(gomega.HaveLen(2))
+	gomega.Expect(records[0].name).To(gomega.Equal("lb.example.net"))
+	gomega.Expect(records[0].ip.String()).To(gomega.Equal("10.0.0.1"))
+	gomega.Expect(records[0].ttl).To(gomega.BeEquivalentTo(300))
+	gomega.Expect(records[1].name).To(gomega.Equal("api.example.com"))
+	gomega.Expect(records[1].ip.String()).To(gomega.Equal("10.0.0.1"))
+	gomega.Expect(records[1].ttl).To(gomega.BeEquivalentTo(30))
+
+	// Truncated message.
+	_, _, err = parseDNSResponse(response[:len(response)-2])
+	gomega.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestLearnAndExpire(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	now := time.Unix(1000, 0)
+	dnsCache := newTestCache(&now)
+	defer dnsCache.Close()
+	changes := make(chan []string, 10)
+	dnsCache.Watch(changes)
+
+	dnsCache.Track([]string{"api.example.com", "*.cdn.example.com"})
+
+	// Not tracked name is ignored.
+	query, _ := buildDNSQuery(1, "other.com", dnsTypeA)
+	dnsCache.learn(buildDNSResponse(query, dnsAnswer{rrType: dnsTypeA, ttl: 600, rdata: []byte{1, 1, 1, 1}}))
+	gomega.Expect(changes).To(gomega.BeEmpty())
+
+	// Exact name, TTL below MinTTL.
+	query, _ = buildDNSQuery(2, "api.example.com", dnsTypeA)
+	dnsCache.learn(buildDNSResponse(query,
+		dnsAnswer{rrType: dnsTypeA, ttl: 10, rdata: []byte{10, 0, 0, 1}},
+		dnsAnswer{rrType: dnsTypeA, ttl: 120, rdata: []byte{10, 0, 0, 2}}))
+	gomega.Expect(<-changes).To(gomega.Equal([]string{"api.example.com"}))
+	gomega.Expect(lookup(dnsCache, "api.example.com")).To(gomega.Equal([]string{"10.0.0.1", "10.0.0.2"}))
+
+	// Wildcard.
+	query, _ = buildDNSQuery(3, "eu.cdn.example.com", dnsTypeA)
+	dnsCache.learn(buildDNSResponse(query, dnsAnswer{rrType: dnsTypeA, ttl: 300, rdata: []byte{10, 0, 1, 1}}))
+	gomega.Expect(<-changes).To(gomega.Equal([]string{"eu.cdn.example.com"}))
+	gomega.Expect(lookup(dnsCache, "*.cdn.example.com")).To(gomega.Equal([]string{"10.0.1.1"}))
+	gomega.Expect(dnsCache.LookupFQDN("eu.cdn.example.com")).To(gomega.HaveLen(1))
+	gomega.Expect(dnsCache.LookupFQDN("example.com")).To(gomega.BeEmpty())
+
+	// Refreshed address is not reported as a change.
+	query, _ = buildDNSQuery(4, "api.example.com", dnsTypeA)
+	dnsCache.learn(buildDNSResponse(query, dnsAnswer{rrType: dnsTypeA, ttl: 10, rdata: []byte{10, 0, 0, 1}}))
+	gomega.Expect(changes).To(gomega.BeEmpty())
+
+	// 10.0.0.1 expires after MinTTL.
+	now = now.Add(defaultMinTTL)
+	dnsCache.expire()
+	gomega.Expect(<-changes).To(gomega.Equal([]string{"api.example.com"}))
+	gomega.Expect(lookup(dnsCache, "api.example.com")).To(gomega.Equal([]string{"10.0.0.2"}))
+
+	// Expiring names get re-resolved.
+	gomega.Expect(dnsCache.namesToResolve()).To(gomega.BeEmpty())
+	now = now.Add(2 * defaultMinTTL)
+	gomega.Expect(dnsCache.namesToResolve()).To(gomega.Equal([]string{"api.example.com"}))
+
+	// Untracked names are forgotten.
+	dnsCache.Track([]string{"api.example.com"})
+	gomega.Expect(dnsCache.LookupFQDN("*.cdn.example.com")).To(gomega.BeEmpty())
+}
+
+func TestMatchFQDN(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	gomega.Expect(MatchFQDN("api.example.com", "api.example.com")).To(gomega.BeTrue())
+	gomega.Expect(MatchFQDN("api.example.com", "www.example.com")).To(gomega.BeFalse())
+	gomega.Expect(MatchFQDN("*.example.com", "a.b.example.com")).To(gomega.BeTrue())
+	gomega.Expect(MatchFQDN("*.example.com", "example.com")).To(gomega.BeFalse())
+	gomega.Expect(MatchFQDN("*.example.com", "badexample.com")).To(gomega.BeFalse())
+	gomega.Expect(NormalizeFQDN("API.Example.COM.")).To(gomega.Equal("api.example.com"))
+}
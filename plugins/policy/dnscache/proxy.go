@@ -0,0 +1,137 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package dnscache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/logging"
+)
+
+// maxDNSMessageLen is the maximum size of a DNS message carried over UDP
+// (with EDNS0).
+const maxDNSMessageLen = 4096
+
+// dnsProxy forwards DNS queries of pods to the upstream DNS server and passes
+// every response to the cache before sending it back to the pod.
+// Only UDP is supported - truncated responses are passed through as they are
+// and the pod may retry over TCP directly with the upstream server.
+type dnsProxy struct {
+	log      logging.Logger
+	upstream string
+	conn     *net.UDPConn
+	learn    func(msg []byte)
+}
+
+// newDNSProxy creates DNS proxy listening on the given UDP address.
+func newDNSProxy(log logging.Logger, listenAddr, upstream string, learn func(msg []byte)) (*dnsProxy, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS proxy address %s: %v", listenAddr, err)
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start DNS proxy on %s: %v", listenAddr, err)
+	}
+	log.WithFields(logging.Fields{
+		"address":  listenAddr,
+		"upstream": upstream,
+	}).Info("DNS proxy started")
+	return &dnsProxy{
+		log:      log,
+		upstream: upstream,
+		conn:     conn,
+		learn:    learn,
+	}, nil
+}
+
+// serve receives queries until the proxy is closed.
+func (p *dnsProxy) serve(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		buf := make([]byte, maxDNSMessageLen)
+		n, client, err := p.conn.ReadFromUDP(buf)
+		if err != nil {
+			// Closed.
+			return
+		}
+		go p.forward(buf[:n], client)
+	}
+}
+
+// forward sends the query to the upstream server and the response back
+// to the client.
+func (p *dnsProxy) forward(query []byte, client *net.UDPAddr) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	response, err := exchangeDNS(ctx, p.upstream, query)
+	if err != nil {
+		p.log.WithFields(logging.Fields{
+			"client": client,
+			"err":    err,
+		}).Debug("Failed to forward DNS query")
+		return
+	}
+	// Learn addresses before the client can use them.
+	p.learn(response)
+	if _, err = p.conn.WriteToUDP(response, client); err != nil {
+		p.log.WithFields(logging.Fields{
+			"client": client,
+			"err":    err,
+		}).Debug("Failed to send DNS response")
+	}
+}
+
+// close stops the proxy.
+func (p *dnsProxy) close() {
+	p.conn.Close()
+}
+
+// exchangeDNS sends DNS query over UDP to the given server and waits for the response.
+func exchangeDNS(ctx context.Context, server string, query []byte) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	deadline, hasDeadline := ctx.Deadline()
+	if !hasDeadline {
+		deadline = time.Now().Add(queryTimeout)
+	}
+	conn.SetDeadline(deadline)
+	if _, err = conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxDNSMessageLen)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Ignore responses with not matching ID.
+		if n >= 2 && buf[0] == query[0] && buf[1] == query[1] {
+			return buf[:n], nil
+		}
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+// Package flowlog implements logging of flows denied by network policies.
+//
+// Flow Logger is built from the hit counters of the ACL rules rendered for
+// the local pods, which the ACL renderer reads from VPP every 30 seconds
+// (see acl.Renderer.WatchRuleHits). A rule matched by packets of a pod since
+// the previous reading is reported as a denial if:
+//   - the rule denies the traffic - the denial is "enforced" and attributed
+//     to the pod and the policies of the matched rule, in both directions,
+//   - the rule permits all the traffic of a pod isolated only by policies
+//     in the audit mode (the rule replacing the final "deny the rest" rule,
+//     see PolicyConfigurator.AuditedPolicies) - the denial is "audit" and
+//     attributed to the audited policies.
+//
+// A denial describes the matched rule from the pod point of view (the peer
+// network, the protocol and the destination ports), not individual connections.
+// The hits are counted by VPP only with the hash-based ACL matching (enabled
+// by the ACL renderer); traffic denied by the VPPTCP session rules is not counted.
+//
+// Each flow is reported at most once per minute and the rate of reported
+// denials is limited. Denials are logged (with the pod identity and the
+// responsible policies), counted by the Prometheus counter "deniedFlows"
+// (exposed at /metrics) and kept in a buffer of recent denials available
+// via the REST API of the agent:
+//
+//	GET /contiv/v1/policy/denials                  - list recent denials
+//	GET /contiv/v1/policy/denials?follow=true      - stream denials as they are reported
+//
+// Both variants accept "namespace" and "pod" query parameters to select
+// denials of a given namespace or pod.
+package flowlog
@@ -0,0 +1,60 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package flowlog
+
+import (
+	"time"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/configurator"
+)
+
+// Modes of policy enforcement a denial was reported in.
+const (
+	// ModeEnforced is used for flows actually denied by the policies.
+	ModeEnforced = "enforced"
+
+	// ModeAudit is used for flows that would be denied if the policies
+	// in the audit mode were enforced.
+	ModeAudit = "audit"
+)
+
+// DeniedFlow is a record of traffic denied by the policies of a local pod,
+// as matched by a single rule of the rendered policies.
+type DeniedFlow struct {
+	Time         time.Time `json:"time"`
+	PodNamespace string    `json:"podNamespace"`
+	PodName      string    `json:"podName"`
+	Direction    string    `json:"direction"`      // INGRESS or EGRESS from the pod point of view
+	Peer         string    `json:"peer,omitempty"` // network of the peers (empty for any peer)
+	Protocol     string    `json:"protocol"`
+	DstPort      uint16    `json:"dstPort,omitempty"`
+	DstPortEnd   uint16    `json:"dstPortEnd,omitempty"`
+	Packets      uint64    `json:"packets"`  // packets matched since the previous reading of the ACL counters
+	Policies     []string  `json:"policies"` // responsible policies as <namespace>/<name> (name for cluster-wide policies)
+	Mode         string    `json:"mode"`     // enforced or audit
+}
+
+// FlowEvaluator evaluates the policies of the local pods.
+// It is implemented by PolicyConfigurator.
+type FlowEvaluator interface {
+	// AuditedPolicies returns the policies in the audit mode isolating the pod
+	// in the given direction, if the traffic not permitted by the rules of the pod
+	// is permitted only because of the audit mode. Returns nil otherwise.
+	AuditedPolicies(pod podmodel.ID, direction configurator.MatchType) []policymodel.ID
+}
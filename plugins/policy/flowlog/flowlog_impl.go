@@ -0,0 +1,378 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package flowlog
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/logging"
+	prometheusplugin "github.com/ligato/cn-infra/rpc/prometheus"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/prometheus/client_golang/prometheus"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+	"github.com/contiv/vpp/plugins/policy/renderer/acl"
+)
+
+const (
+	// dedupWindow is the minimal delay between two reports of the same flow.
+	dedupWindow = time.Minute
+
+	// reportRate is the maximum average number of reported denials per second.
+	reportRate = 20
+	// reportBurst is the maximum number of denials reported at once.
+	reportBurst = 100
+
+	// maxRecentDenials is the capacity of the buffer with recent denials.
+	maxRecentDenials = 1000
+
+	// subscriberBufSize is the capacity of the channel of a stream subscriber.
+	subscriberBufSize = 100
+
+	// names and labels of the Prometheus metrics
+	deniedFlowsMetric     = "deniedFlows"
+	suppressedFlowsMetric = "suppressedDeniedFlows"
+	policyLabel           = "policy"
+	podNamespaceLabel     = "podNamespace"
+	podNameLabel          = "podName"
+	directionLabel        = "direction"
+	modeLabel             = "mode"
+)
+
+// FlowLogger reports flows denied by the policies of the local pods.
+type FlowLogger struct {
+	Deps
+
+	hitsChan chan []*acl.RuleHits
+
+	sync.Mutex
+	reported    map[flowKey]time.Time // flow -> time of the last report
+	recent      []*DeniedFlow         // ring buffer of recent denials
+	recentNext  int                   // next position to write in the ring buffer
+	tokens      float64               // rate limiter
+	lastRefill  time.Time
+	subscribers map[chan *DeniedFlow]struct{}
+
+	deniedFlows     *prometheus.CounterVec
+	suppressedFlows prometheus.Counter
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// for unit tests
+	clock func() time.Time
+}
+
+// Deps lists dependencies of FlowLogger.
+type Deps struct {
+	Log          logging.Logger
+	Evaluator    FlowEvaluator        /* to find policies in the audit mode */
+	HTTPHandlers rest.HTTPHandlers    /* optional */
+	Prometheus   prometheusplugin.API /* optional */
+}
+
+// flowKey identifies a flow for the purpose of de-duplication of reports.
+type flowKey struct {
+	pod        podmodel.ID
+	direction  configurator.MatchType
+	peer       string
+	protocol   renderer.ProtocolType
+	dstPort    uint16
+	dstPortEnd uint16
+}
+
+// Init initializes the flow logger, registers the metrics and the REST API,
+// and starts processing the ACL rule hits.
+func (fl *FlowLogger) Init() error {
+	fl.hitsChan = make(chan []*acl.RuleHits, 1)
+	fl.reported = make(map[flowKey]time.Time)
+	fl.subscribers = make(map[chan *DeniedFlow]struct{})
+	if fl.clock == nil {
+		fl.clock = time.Now
+	}
+	fl.tokens = reportBurst
+	fl.lastRefill = fl.clock()
+	fl.ctx, fl.cancel = context.WithCancel(context.Background())
+
+	fl.deniedFlows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: deniedFlowsMetric,
+		Help: "Number of distinct flows denied by the policy (reported at most once per minute)",
+	}, []string{policyLabel, podNamespaceLabel, podNameLabel, directionLabel, modeLabel})
+	fl.suppressedFlows = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: suppressedFlowsMetric,
+		Help: "Number of denied flows not logged due to the rate limiting",
+	})
+	if fl.Prometheus != nil {
+		for _, metric := range []prometheus.Collector{fl.deniedFlows, fl.suppressedFlows} {
+			if err := fl.Prometheus.Register(prometheusplugin.DefaultRegistry, metric); err != nil {
+				return err
+			}
+		}
+	}
+	fl.registerHandlers(fl.HTTPHandlers)
+
+	fl.wg.Add(1)
+	go fl.collect()
+	return nil
+}
+
+// RuleHitsChan returns the channel through which the flow logger should receive
+// the hits of the ACL rules of the local pods (see acl.Renderer.WatchRuleHits).
+func (fl *FlowLogger) RuleHitsChan() chan<- []*acl.RuleHits {
+	return fl.hitsChan
+}
+
+// Close stops processing the rule hits and terminates all denial streams.
+func (fl *FlowLogger) Close() error {
+	fl.cancel()
+	fl.wg.Wait()
+	return nil
+}
+
+// collect processes the rule hits received from the ACL renderer
+// and periodically forgets flows not reported recently.
+func (fl *FlowLogger) collect() {
+	defer fl.wg.Done()
+	prune := time.NewTicker(dedupWindow)
+	defer prune.Stop()
+
+	for {
+		select {
+		case ruleHits := <-fl.hitsChan:
+			for _, hits := range ruleHits {
+				fl.observe(hits)
+			}
+		case <-prune.C:
+			fl.pruneReported()
+		case <-fl.ctx.Done():
+			return
+		}
+	}
+}
+
+// observe reports the hits of the ACL rule of a local pod as a denial if:
+//   - the rule denies the traffic (the denial is attributed to the policies
+//     of the rule), or
+//   - the rule permits all the traffic in place of the final "deny the rest"
+//     rule of a pod isolated only by policies in the audit mode.
+func (fl *FlowLogger) observe(hits *acl.RuleHits) {
+	direction := configurator.MatchEgress
+	peer := hits.Rule.DestNetwork
+	if hits.Ingress {
+		direction = configurator.MatchIngress
+		peer = hits.Rule.SrcNetwork
+	}
+
+	mode := ModeEnforced
+	policies := hits.Policies
+	switch {
+	case hits.Rule.Action == renderer.ActionDeny:
+	case matchesAll(hits.Rule):
+		policies = fl.Evaluator.AuditedPolicies(hits.Pod, direction)
+		if len(policies) == 0 {
+			// the pod is not isolated or some traffic is allowed for all peers
+			return
+		}
+		mode = ModeAudit
+	default:
+		return
+	}
+	fl.report(hits, direction, networkName(peer), policies, mode)
+}
+
+// report records the denial unless the flow was reported recently
+// or the rate limit is exceeded.
+func (fl *FlowLogger) report(hits *acl.RuleHits, direction configurator.MatchType,
+	peer string, policies []policymodel.ID, mode string) {
+
+	fl.Lock()
+	defer fl.Unlock()
+
+	now := fl.clock()
+	pod := hits.Pod
+	key := flowKey{
+		pod:        pod,
+		direction:  direction,
+		peer:       peer,
+		protocol:   hits.Rule.Protocol,
+		dstPort:    hits.Rule.DestPort,
+		dstPortEnd: hits.Rule.DestPortEnd,
+	}
+	if last, reported := fl.reported[key]; reported && now.Sub(last) < dedupWindow {
+		return
+	}
+	fl.reported[key] = now
+
+	denial := &DeniedFlow{
+		Time:         now,
+		PodNamespace: pod.Namespace,
+		PodName:      pod.Name,
+		Direction:    direction.String(),
+		Peer:         peer,
+		Protocol:     hits.Rule.Protocol.String(),
+		DstPort:      hits.Rule.DestPort,
+		DstPortEnd:   hits.Rule.DestPortEnd,
+		Packets:      hits.Packets,
+		Mode:         mode,
+	}
+	for _, policy := range policies {
+		denial.Policies = append(denial.Policies, policyName(policy))
+		fl.deniedFlows.With(prometheus.Labels{
+			policyLabel:       policyName(policy),
+			podNamespaceLabel: pod.Namespace,
+			podNameLabel:      pod.Name,
+			directionLabel:    denial.Direction,
+			modeLabel:         denial.Mode,
+		}).Inc()
+	}
+
+	if !fl.allowReport(now) {
+		fl.suppressedFlows.Inc()
+		return
+	}
+
+	fl.Log.WithFields(logging.Fields{
+		"pod":       pod,
+		"direction": denial.Direction,
+		"peer":      denial.Peer,
+		"protocol":  denial.Protocol,
+		"dstPort":   denial.DstPort,
+		"packets":   denial.Packets,
+		"policies":  denial.Policies,
+		"mode":      denial.Mode,
+	}).Info("Flow denied by policy")
+
+	if len(fl.recent) < maxRecentDenials {
+		fl.recent = append(fl.recent, denial)
+	} else {
+		fl.recent[fl.recentNext] = denial
+	}
+	fl.recentNext = (fl.recentNext + 1) % maxRecentDenials
+
+	for subscriber := range fl.subscribers {
+		select {
+		case subscriber <- denial:
+		default:
+			// Slow subscriber, drop the record rather than block the logger.
+		}
+	}
+}
+
+// allowReport implements a token bucket limiting the rate of the reports.
+// The method expects the logger to be locked.
+func (fl *FlowLogger) allowReport(now time.Time) bool {
+	fl.tokens += now.Sub(fl.lastRefill).Seconds() * reportRate
+	if fl.tokens > reportBurst {
+		fl.tokens = reportBurst
+	}
+	fl.lastRefill = now
+	if fl.tokens < 1 {
+		return false
+	}
+	fl.tokens--
+	return true
+}
+
+// pruneReported forgets flows not reported for longer than dedupWindow.
+func (fl *FlowLogger) pruneReported() {
+	fl.Lock()
+	defer fl.Unlock()
+
+	now := fl.clock()
+	for key, last := range fl.reported {
+		if now.Sub(last) >= dedupWindow {
+			delete(fl.reported, key)
+		}
+	}
+}
+
+// listRecent returns recent denials of the given namespace and pod (empty
+// values select all) ordered from the oldest to the newest one.
+// The method expects the logger to be locked.
+func (fl *FlowLogger) listRecent(namespace, pod string) []*DeniedFlow {
+	denials := []*DeniedFlow{}
+	start := 0
+	if len(fl.recent) == maxRecentDenials {
+		start = fl.recentNext
+	}
+	for i := 0; i < len(fl.recent); i++ {
+		denial := fl.recent[(start+i)%len(fl.recent)]
+		if denial.matches(namespace, pod) {
+			denials = append(denials, denial)
+		}
+	}
+	return denials
+}
+
+// subscribe returns recent denials of the given namespace and pod together
+// with a channel delivering the denials reported from now on.
+func (fl *FlowLogger) subscribe(namespace, pod string) (recent []*DeniedFlow, subscriber chan *DeniedFlow) {
+	fl.Lock()
+	defer fl.Unlock()
+
+	subscriber = make(chan *DeniedFlow, subscriberBufSize)
+	fl.subscribers[subscriber] = struct{}{}
+	return fl.listRecent(namespace, pod), subscriber
+}
+
+// unsubscribe stops delivering denials to the subscriber.
+func (fl *FlowLogger) unsubscribe(subscriber chan *DeniedFlow) {
+	fl.Lock()
+	defer fl.Unlock()
+	delete(fl.subscribers, subscriber)
+}
+
+// matches returns true if the denial relates to the given namespace and pod
+// (empty values match all).
+func (df *DeniedFlow) matches(namespace, pod string) bool {
+	return (namespace == "" || df.PodNamespace == namespace) &&
+		(pod == "" || df.PodName == pod)
+}
+
+// policyName returns the name of the policy as used in the reports.
+func policyName(policy policymodel.ID) string {
+	if policy.Namespace == "" {
+		// cluster-wide policy
+		return policy.Name
+	}
+	return policy.Namespace + "/" + policy.Name
+}
+
+// matchesAll returns true if the rule matches all the traffic of the pod.
+func matchesAll(rule *renderer.ContivRule) bool {
+	return rule.Protocol == renderer.ANY && networkName(rule.SrcNetwork) == "" &&
+		networkName(rule.DestNetwork) == ""
+}
+
+// networkName returns the network as used in the reports - empty for a network
+// matching all addresses.
+func networkName(network *net.IPNet) string {
+	if network == nil || len(network.IP) == 0 {
+		return ""
+	}
+	if ones, _ := network.Mask.Size(); ones == 0 {
+		return ""
+	}
+	return network.String()
+}
@@ -0,0 +1,209 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package flowlog
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+	"github.com/contiv/vpp/plugins/policy/renderer/acl"
+)
+
+var (
+	pod1 = podmodel.ID{Name: "pod1", Namespace: "default"}
+	pod2 = podmodel.ID{Name: "pod2", Namespace: "test"}
+
+	policy1 = policymodel.ID{Name: "policy1", Namespace: "default"}
+	policy2 = policymodel.ID{Name: "policy2", Namespace: "test"}
+)
+
+// fakeEvaluator returns the audited policies of the pods isolated only by
+// policies in the audit mode, in both directions.
+type fakeEvaluator struct {
+	audited map[podmodel.ID][]policymodel.ID
+}
+
+func (fe *fakeEvaluator) AuditedPolicies(pod podmodel.ID, direction configurator.MatchType) []policymodel.ID {
+	return fe.audited[pod]
+}
+
+func newTestLogger(now *time.Time) *FlowLogger {
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+
+	flowLogger := &FlowLogger{
+		Deps: Deps{
+			Log: logger,
+			Evaluator: &fakeEvaluator{
+				audited: map[podmodel.ID][]policymodel.ID{
+					pod2: {policy2},
+				},
+			},
+		},
+		clock: func() time.Time { return *now },
+	}
+	gomega.Expect(flowLogger.Init()).To(gomega.BeNil())
+	return flowLogger
+}
+
+func ipNetwork(addr string) *net.IPNet {
+	if addr == "" {
+		return &net.IPNet{}
+	}
+	_, network, err := net.ParseCIDR(addr)
+	gomega.Expect(err).To(gomega.BeNil())
+	return network
+}
+
+// tcpHits returns hits of a TCP rule of the pod with the given peer network.
+func tcpHits(pod podmodel.ID, ingress bool, action renderer.ActionType, peer string, dstPort uint16,
+	policies ...policymodel.ID) *acl.RuleHits {
+
+	rule := &renderer.ContivRule{
+		Action:      action,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: &net.IPNet{},
+		Protocol:    renderer.TCP,
+		DestPort:    dstPort,
+	}
+	if ingress {
+		rule.SrcNetwork = ipNetwork(peer)
+	} else {
+		rule.DestNetwork = ipNetwork(peer)
+	}
+	return &acl.RuleHits{Pod: pod, Ingress: ingress, Rule: rule, Policies: policies, Packets: 10}
+}
+
+// permitAllHits returns hits of the rule permitting all the traffic of the pod.
+func permitAllHits(pod podmodel.ID, ingress bool) *acl.RuleHits {
+	rule := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: &net.IPNet{},
+		Protocol:    renderer.ANY,
+	}
+	return &acl.RuleHits{Pod: pod, Ingress: ingress, Rule: rule, Packets: 5}
+}
+
+func TestObserve(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	now := time.Unix(1000, 0)
+	flowLogger := newTestLogger(&now)
+	defer flowLogger.Close()
+	recent, subscriber := flowLogger.subscribe("default", "")
+	gomega.Expect(recent).To(gomega.BeEmpty())
+
+	// Egress traffic of pod1 matched by a deny rule.
+	flowLogger.observe(tcpHits(pod1, false, renderer.ActionDeny, "10.1.1.9/32", 80, policy1))
+	gomega.Expect(flowLogger.listRecent("", "")).To(gomega.HaveLen(1))
+	denial := <-subscriber
+	gomega.Expect(denial.PodNamespace).To(gomega.Equal("default"))
+	gomega.Expect(denial.PodName).To(gomega.Equal("pod1"))
+	gomega.Expect(denial.Direction).To(gomega.Equal("EGRESS"))
+	gomega.Expect(denial.Peer).To(gomega.Equal("10.1.1.9/32"))
+	gomega.Expect(denial.DstPort).To(gomega.BeEquivalentTo(80))
+	gomega.Expect(denial.Protocol).To(gomega.Equal("TCP"))
+	gomega.Expect(denial.Packets).To(gomega.BeEquivalentTo(10))
+	gomega.Expect(denial.Policies).To(gomega.Equal([]string{"default/policy1"}))
+	gomega.Expect(denial.Mode).To(gomega.Equal(ModeEnforced))
+
+	// The same flow is reported once per dedupWindow.
+	flowLogger.observe(tcpHits(pod1, false, renderer.ActionDeny, "10.1.1.9/32", 80, policy1))
+	gomega.Expect(flowLogger.listRecent("", "")).To(gomega.HaveLen(1))
+	now = now.Add(dedupWindow)
+	flowLogger.pruneReported()
+	flowLogger.observe(tcpHits(pod1, false, renderer.ActionDeny, "10.1.1.9/32", 80, policy1))
+	gomega.Expect(flowLogger.listRecent("", "")).To(gomega.HaveLen(2))
+
+	// Ingress traffic of pod1 denied for all peers.
+	flowLogger.observe(tcpHits(pod1, true, renderer.ActionDeny, "", 0, policy1))
+	denials := flowLogger.listRecent("default", "pod1")
+	gomega.Expect(denials).To(gomega.HaveLen(3))
+	gomega.Expect(denials[2].Direction).To(gomega.Equal("INGRESS"))
+	gomega.Expect(denials[2].Peer).To(gomega.BeEmpty())
+
+	// Permitted traffic of the enforced pod is not a denial.
+	flowLogger.observe(tcpHits(pod1, true, renderer.ActionPermit, "10.1.1.9/32", 8080, policy1))
+	flowLogger.observe(permitAllHits(pod1, true))
+	gomega.Expect(flowLogger.listRecent("", "")).To(gomega.HaveLen(3))
+
+	// Traffic of the audited pod permitted by its rules is not a denial.
+	flowLogger.observe(tcpHits(pod2, true, renderer.ActionPermit, "10.1.1.9/32", 80, policy2))
+	gomega.Expect(flowLogger.listRecent("", "")).To(gomega.HaveLen(3))
+
+	// Traffic of the audited pod permitted only due to the audit mode.
+	flowLogger.observe(permitAllHits(pod2, true))
+	denials = flowLogger.listRecent("test", "pod2")
+	gomega.Expect(denials).To(gomega.HaveLen(1))
+	gomega.Expect(denials[0].Direction).To(gomega.Equal("INGRESS"))
+	gomega.Expect(denials[0].Protocol).To(gomega.Equal("ANY"))
+	gomega.Expect(denials[0].Packets).To(gomega.BeEquivalentTo(5))
+	gomega.Expect(denials[0].Mode).To(gomega.Equal(ModeAudit))
+	gomega.Expect(denials[0].Policies).To(gomega.Equal([]string{"test/policy2"}))
+
+	// Subscriber receives all denials, filtered by the handler.
+	gomega.Expect(<-subscriber).ToNot(gomega.BeNil())
+	gomega.Expect(<-subscriber).ToNot(gomega.BeNil())
+	gomega.Expect((<-subscriber).matches("default", "")).To(gomega.BeFalse())
+	flowLogger.unsubscribe(subscriber)
+}
+
+func TestRuleHitsChan(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	now := time.Unix(1000, 0)
+	flowLogger := newTestLogger(&now)
+	defer flowLogger.Close()
+	_, subscriber := flowLogger.subscribe("", "")
+
+	flowLogger.RuleHitsChan() <- []*acl.RuleHits{
+		tcpHits(pod1, false, renderer.ActionDeny, "10.1.1.9/32", 80, policy1),
+		permitAllHits(pod2, false),
+	}
+	gomega.Eventually(subscriber).Should(gomega.Receive())
+	gomega.Eventually(subscriber).Should(gomega.Receive())
+}
+
+func TestRateLimit(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	now := time.Unix(1000, 0)
+	flowLogger := newTestLogger(&now)
+	defer flowLogger.Close()
+
+	for port := 1; port <= reportBurst+10; port++ {
+		flowLogger.observe(tcpHits(pod1, false, renderer.ActionDeny, "10.1.1.9/32", uint16(port), policy1))
+	}
+	gomega.Expect(flowLogger.listRecent("", "")).To(gomega.HaveLen(reportBurst))
+
+	now = now.Add(time.Second)
+	for port := 1000; port < 1000+2*reportRate; port++ {
+		flowLogger.observe(tcpHits(pod1, false, renderer.ActionDeny, "10.1.1.9/32", uint16(port), policy1))
+	}
+	gomega.Expect(flowLogger.listRecent("", "")).To(gomega.HaveLen(reportBurst + reportRate))
+}
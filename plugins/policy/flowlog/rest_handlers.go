@@ -0,0 +1,95 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package flowlog
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+)
+
+const (
+	// Prefix is versioned prefix for REST urls
+	Prefix = "/contiv/v1/"
+	// DenialsURL is versioned URL (using prefix) for the REST endpoint with recent denials
+	DenialsURL = Prefix + "policy/denials"
+
+	namespaceParam = "namespace"
+	podParam       = "pod"
+	followParam    = "follow"
+)
+
+func (fl *FlowLogger) registerHandlers(http rest.HTTPHandlers) {
+	if http == nil {
+		fl.Log.Warnf("No http handler provided, skipping registration of denied flows REST handlers")
+		return
+	}
+	http.RegisterHTTPHandler(DenialsURL, fl.denialsHandler, "GET")
+	fl.Log.Infof("Denied flows REST handler registered: GET %v", DenialsURL)
+}
+
+// denialsHandler returns recent denials, or streams the denials as they are
+// reported (one JSON object per line) if the "follow" parameter is set to true.
+func (fl *FlowLogger) denialsHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		namespace, pod := query.Get(namespaceParam), query.Get(podParam)
+		if query.Get(followParam) != "true" {
+			fl.Lock()
+			denials := fl.listRecent(namespace, pod)
+			fl.Unlock()
+			formatter.JSON(w, http.StatusOK, denials)
+			return
+		}
+
+		flusher, canFlush := w.(http.Flusher)
+		if !canFlush {
+			formatter.JSON(w, http.StatusInternalServerError, "streaming is not supported")
+			return
+		}
+		recent, subscriber := fl.subscribe(namespace, pod)
+		defer fl.unsubscribe(subscriber)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		encoder := json.NewEncoder(w)
+		for _, denial := range recent {
+			if err := encoder.Encode(denial); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+		for {
+			select {
+			case denial := <-subscriber:
+				if !denial.matches(namespace, pod) {
+					continue
+				}
+				if err := encoder.Encode(denial); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-req.Context().Done():
+				return
+			case <-fl.ctx.Done():
+				return
+			}
+		}
+	}
+}
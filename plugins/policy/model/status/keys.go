@@ -0,0 +1,24 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+// KeyPrefix is a key prefix used in ETCD to store the status of policies
+// rendered on every node.
+const KeyPrefix = "policy-status/"
+
+// Key returns the key under which the policy status of the given node is stored.
+func Key(nodeName string) string {
+	return KeyPrefix + nodeName
+}
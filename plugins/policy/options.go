@@ -16,7 +16,11 @@ package policy
 
 import (
 	"github.com/ligato/cn-infra/datasync/resync"
+	"github.com/ligato/cn-infra/db/keyval/etcd"
 	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/rpc/prometheus"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/ligato/cn-infra/servicelabel"
 	"github.com/ligato/vpp-agent/plugins/govppmux"
 )
 
@@ -27,6 +31,10 @@ func NewPlugin(opts ...Option) *Plugin {
 	p.PluginName = "policy"
 	p.Resync = &resync.DefaultPlugin
 	p.GoVPP = &govppmux.DefaultPlugin
+	p.HTTPHandlers = &rest.DefaultPlugin
+	p.Prometheus = &prometheus.DefaultPlugin
+	p.ServiceLabel = &servicelabel.DefaultPlugin
+	p.ETCD = &etcd.DefaultPlugin
 
 	for _, o := range opts {
 		o(p)
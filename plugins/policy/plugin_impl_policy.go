@@ -21,7 +21,11 @@ import (
 	"github.com/ligato/cn-infra/datasync"
 	kvdbsync_local "github.com/ligato/cn-infra/datasync/kvdbsync/local"
 	"github.com/ligato/cn-infra/datasync/resync"
+	"github.com/ligato/cn-infra/db/keyval/etcd"
 	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/rpc/prometheus"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/ligato/cn-infra/servicelabel"
 	"github.com/ligato/cn-infra/utils/safeclose"
 
 	"github.com/ligato/vpp-agent/clientv1/linux"
@@ -30,15 +34,26 @@ import (
 	"github.com/ligato/vpp-agent/plugins/vpp"
 
 	"github.com/contiv/vpp/plugins/contiv"
+	"github.com/contiv/vpp/plugins/ksr"
 	"github.com/contiv/vpp/plugins/policy/cache"
 	"github.com/contiv/vpp/plugins/policy/configurator"
+	policydebug "github.com/contiv/vpp/plugins/policy/debug"
+	"github.com/contiv/vpp/plugins/policy/dnscache"
+	"github.com/contiv/vpp/plugins/policy/flowlog"
 	"github.com/contiv/vpp/plugins/policy/processor"
 	"github.com/contiv/vpp/plugins/policy/renderer/acl"
 	"github.com/contiv/vpp/plugins/policy/renderer/vpptcp"
+	"github.com/contiv/vpp/plugins/policy/simulator"
+	"github.com/contiv/vpp/plugins/policy/statusreporter"
+	"github.com/contiv/vpp/plugins/policy/svccache"
+	"github.com/contiv/vpp/plugins/service"
 
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
 	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	nodemodel "github.com/contiv/vpp/plugins/ksr/model/node"
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
 	"github.com/ligato/cn-infra/infra"
 )
 
@@ -48,8 +63,11 @@ import (
 type Plugin struct {
 	Deps
 
-	resyncChan chan datasync.ResyncEvent
-	changeChan chan datasync.ChangeEvent
+	resyncChan         chan datasync.ResyncEvent
+	changeChan         chan datasync.ChangeEvent
+	dnsChan            chan []string
+	svcChan            chan []svcmodel.ID
+	rendererResyncChan chan struct{} /* not closed, written by timers of the configurator */
 
 	watchConfigReg datasync.WatchRegistration
 
@@ -75,6 +93,24 @@ type Plugin struct {
 	// Policy Configurator: layer 3
 	configurator *configurator.PolicyConfigurator
 
+	// DNS Cache: IP addresses of FQDNs referenced by policies (used by layer 3)
+	dnsCache *dnscache.DNSCache
+
+	// Service Cache: frontends and backends of services referenced by policies (used by layer 2)
+	svcCache *svccache.ServiceCache
+
+	// Flow Logger: reports flows denied by policies (uses layer 3)
+	flowLogger *flowlog.FlowLogger
+
+	// Policy Simulator: REST API for offline simulation of policies
+	simulatorREST *simulator.RESTHandler
+
+	// Policy Debug: REST API dumping the policy configuration of the node
+	debugREST *policydebug.RESTHandler
+
+	// Status Reporter: publishes the status of the rendered policies for KSR (uses layer 3)
+	statusReporter *statusreporter.StatusReporter
+
 	// Policy Renderers: layer 4
 	//  -> ACL Renderer
 	aclRenderer *acl.Renderer
@@ -86,11 +122,17 @@ type Plugin struct {
 // Deps defines dependencies of policy plugin.
 type Deps struct {
 	infra.PluginDeps
-	Resync  resync.Subscriber
-	Watcher datasync.KeyValProtoWatcher /* prefixed for KSR-published K8s state data */
-	Contiv  contiv.API                  /* for GetIfName() */
-	VPP     vpp.API                     /* for DumpACLs() */
-	GoVPP   govppmux.API                /* for VPPTCP Renderer */
+	Resync       resync.Subscriber
+	Watcher      datasync.KeyValProtoWatcher /* prefixed for KSR-published K8s state data */
+	Contiv       contiv.API                  /* for GetIfName() */
+	ServiceLabel servicelabel.ReaderAPI      /* to get the name of this node */
+	VPP          vpp.API                     /* for DumpACLs() */
+	GoVPP        govppmux.API                /* for VPPTCP Renderer and ACL hit counters */
+	Service      service.API                 /* to learn services referenced by policies, optional */
+	ETCD         *etcd.Plugin                /* to publish the policy status for KSR, optional */
+
+	HTTPHandlers rest.HTTPHandlers /* for the REST API of Flow Logger, Policy Simulator and Policy Debug */
+	Prometheus   prometheus.API    /* for the metrics of Flow Logger and ACL Renderer */
 }
 
 // Init initializes policy layers and caches and starts watching ETCD for K8s configuration.
@@ -100,6 +142,9 @@ func (p *Plugin) Init() error {
 
 	p.resyncChan = make(chan datasync.ResyncEvent)
 	p.changeChan = make(chan datasync.ChangeEvent)
+	p.dnsChan = make(chan []string, 10)
+	p.svcChan = make(chan []svcmodel.ID, 10)
+	p.rendererResyncChan = make(chan struct{}, 1)
 
 	// Inject dependencies between layers.
 	p.policyCache = &cache.PolicyCache{
@@ -109,11 +154,27 @@ func (p *Plugin) Init() error {
 	}
 	p.policyCache.Log.SetLevel(logging.DebugLevel)
 
+	p.dnsCache = &dnscache.DNSCache{
+		Deps: dnscache.Deps{
+			Log:    p.Log.NewLogger("-dnsCache"),
+			Contiv: p.Contiv,
+		},
+	}
+	p.dnsCache.Log.SetLevel(logging.DebugLevel)
+
+	p.svcCache = &svccache.ServiceCache{
+		Deps: svccache.Deps{
+			Log: p.Log.NewLogger("-svcCache"),
+		},
+	}
+	p.svcCache.Log.SetLevel(logging.DebugLevel)
+
 	p.configurator = &configurator.PolicyConfigurator{
 		Deps: configurator.Deps{
-			Log:    p.Log.NewLogger("-policyConfigurator"),
-			Cache:  p.policyCache,
-			Contiv: p.Contiv,
+			Log:      p.Log.NewLogger("-policyConfigurator"),
+			Cache:    p.policyCache,
+			Contiv:   p.Contiv,
+			DNSCache: p.dnsCache,
 		},
 	}
 	p.configurator.Log.SetLevel(logging.DebugLevel)
@@ -124,10 +185,16 @@ func (p *Plugin) Init() error {
 			Contiv:       p.Contiv,
 			Cache:        p.policyCache,
 			Configurator: p.configurator,
+			ServiceLabel: p.ServiceLabel,
+			ServiceCache: p.svcCache,
 		},
 	}
 	p.processor.Log.SetLevel(logging.DebugLevel)
 
+	aclGoVppCh, err := p.GoVPP.NewAPIChannel()
+	if err != nil {
+		return err
+	}
 	p.aclRenderer = &acl.Renderer{
 		Deps: acl.Deps{
 			Log:        p.Log.NewLogger("-aclRenderer"),
@@ -138,6 +205,8 @@ func (p *Plugin) Init() error {
 				return localclient.DataChangeRequest(p.String())
 			},
 			LatestRevs: kvdbsync_local.Get().LastRev(),
+			GoVPPChan:  aclGoVppCh,
+			Prometheus: p.Prometheus,
 		},
 	}
 	p.aclRenderer.Log.SetLevel(logging.DebugLevel)
@@ -158,14 +227,71 @@ func (p *Plugin) Init() error {
 	}
 	p.vppTCPRenderer.Log.SetLevel(logging.DebugLevel)
 
+	p.flowLogger = &flowlog.FlowLogger{
+		Deps: flowlog.Deps{
+			Log:          p.Log.NewLogger("-flowLogger"),
+			Evaluator:    &flowEvaluator{plugin: p},
+			HTTPHandlers: p.HTTPHandlers,
+			Prometheus:   p.Prometheus,
+		},
+	}
+
+	p.simulatorREST = &simulator.RESTHandler{
+		Deps: simulator.Deps{
+			Log:          p.Log.NewLogger("-policySimulator"),
+			HTTPHandlers: p.HTTPHandlers,
+			State:        &simulatorState{plugin: p},
+		},
+	}
+
+	p.debugREST = &policydebug.RESTHandler{
+		Deps: policydebug.Deps{
+			Log:          p.Log.NewLogger("-policyDebug"),
+			HTTPHandlers: p.HTTPHandlers,
+			State:        &debugState{plugin: p},
+		},
+	}
+
+	if p.ETCD != nil {
+		p.statusReporter = &statusreporter.StatusReporter{
+			Deps: statusreporter.Deps{
+				Log:      p.Log.NewLogger("-statusReporter"),
+				NodeName: p.ServiceLabel.GetAgentLabel(),
+				Broker:   p.ETCD.NewBroker(p.ServiceLabel.GetDifferentAgentPrefix(ksr.MicroserviceLabel)),
+				State:    &statusState{plugin: p},
+			},
+		}
+	}
+
 	// Initialize layers.
 	p.policyCache.Init()
+	if err = p.dnsCache.Init(); err != nil {
+		return err
+	}
+	p.dnsCache.Watch(p.dnsChan)
+	if err = p.svcCache.Init(); err != nil {
+		return err
+	}
+	p.svcCache.Watch(p.svcChan)
 	p.processor.Init()
 	p.configurator.Init(false) // Do not render in parallel while we do lot of debugging.
-	p.aclRenderer.Init()
+	p.configurator.WatchResyncRequests(p.rendererResyncChan)
+	if err = p.aclRenderer.Init(); err != nil {
+		return err
+	}
+	if err = p.flowLogger.Init(); err != nil {
+		return err
+	}
+	p.aclRenderer.WatchRuleHits(p.flowLogger.RuleHitsChan())
 	if !p.Contiv.IsTCPstackDisabled() {
 		p.vppTCPRenderer.Init()
 	}
+	p.simulatorREST.Init()
+	p.debugREST.Init()
+	if p.statusReporter != nil {
+		p.statusReporter.Init()
+		p.configurator.WatchPolicyStatus(p.statusReporter.NotifyChan())
+	}
 
 	// Register renderers.
 	p.configurator.RegisterRenderer(p.aclRenderer)
@@ -188,6 +314,12 @@ func (p *Plugin) Init() error {
 // in order to ensure that the resync for this plugin is triggered only after
 // resync of the Contiv plugin has finished.
 func (p *Plugin) AfterInit() error {
+	// Learn services from the service plugin (its resync follows).
+	if p.Service != nil {
+		if err := p.Service.RegisterRenderer(p.svcCache); err != nil {
+			return err
+		}
+	}
 	if p.Resync != nil {
 		reg := p.Resync.Register(string(p.PluginName))
 		go p.handleResync(reg.StatusChan())
@@ -198,7 +330,9 @@ func (p *Plugin) AfterInit() error {
 func (p *Plugin) subscribeWatcher() (err error) {
 	p.watchConfigReg, err = p.Watcher.
 		Watch("K8s policies", p.changeChan, p.resyncChan,
-			nsmodel.KeyPrefix(), podmodel.KeyPrefix(), policymodel.KeyPrefix())
+			nsmodel.KeyPrefix(), podmodel.KeyPrefix(), policymodel.KeyPrefix(),
+			clusterpolicymodel.KeyPrefix(), clusterpolicymodel.HostEndpointPolicyKeyPrefix(),
+			nodemodel.KeyPrefix())
 	return err
 }
 
@@ -235,6 +369,36 @@ func (p *Plugin) watchEvents() {
 			}
 			p.resyncLock.Unlock()
 
+		case names := <-p.dnsChan:
+			p.resyncLock.Lock()
+			if p.resyncCounter > 0 && p.pendingResync == nil {
+				// Delayed resync will render the current addresses anyway.
+				if err := p.configurator.RefreshFQDNs(names); err != nil {
+					p.Log.Error(err)
+				}
+			}
+			p.resyncLock.Unlock()
+
+		case services := <-p.svcChan:
+			p.resyncLock.Lock()
+			if p.resyncCounter > 0 && p.pendingResync == nil {
+				// Delayed resync will expand the current services anyway.
+				if err := p.processor.UpdateServices(services); err != nil {
+					p.Log.Error(err)
+				}
+			}
+			p.resyncLock.Unlock()
+
+		case <-p.rendererResyncChan:
+			p.resyncLock.Lock()
+			if p.resyncCounter > 0 && p.pendingResync == nil {
+				// Delayed resync will re-render everything anyway.
+				if err := p.configurator.Resync(); err != nil {
+					p.Log.Error(err)
+				}
+			}
+			p.resyncLock.Unlock()
+
 		case <-p.ctx.Done():
 			p.Log.Debug("Stop watching events")
 			return
@@ -285,6 +449,67 @@ func (p *Plugin) handleResync(resyncChan chan resync.StatusEvent) {
 func (p *Plugin) Close() error {
 	p.cancel()
 	p.wg.Wait()
-	safeclose.CloseAll(p.watchConfigReg, p.resyncChan, p.changeChan)
+	safeclose.CloseAll(p.watchConfigReg, p.aclRenderer, p.flowLogger, p.dnsCache, p.svcCache, p.statusReporter,
+		p.resyncChan, p.changeChan, p.dnsChan, p.svcChan)
 	return nil
 }
+
+// flowEvaluator gives Flow Logger access to the configurator, synchronized
+// with the processing of K8s state changes.
+type flowEvaluator struct {
+	plugin *Plugin
+}
+
+// AuditedPolicies returns the policies in the audit mode isolating the pod
+// in the given direction (see PolicyConfigurator.AuditedPolicies).
+func (fe *flowEvaluator) AuditedPolicies(pod podmodel.ID, direction configurator.MatchType) []policymodel.ID {
+	fe.plugin.resyncLock.Lock()
+	defer fe.plugin.resyncLock.Unlock()
+	return fe.plugin.configurator.AuditedPolicies(pod, direction)
+}
+
+// simulatorState gives Policy Simulator access to the K8s state stored
+// in the policy cache, synchronized with the processing of K8s state changes.
+type simulatorState struct {
+	plugin *Plugin
+}
+
+// Snapshot returns the current K8s state.
+func (ss *simulatorState) Snapshot() *simulator.Snapshot {
+	ss.plugin.resyncLock.Lock()
+	defer ss.plugin.resyncLock.Unlock()
+	return simulator.SnapshotFromCache(ss.plugin.policyCache)
+}
+
+// debugState gives Policy Debug access to the configuration of the Configurator
+// and the renderer caches, synchronized with the processing of K8s state changes.
+type debugState struct {
+	plugin *Plugin
+}
+
+// Dump returns the current policy configuration of the node.
+func (ds *debugState) Dump() *policydebug.Dump {
+	ds.plugin.resyncLock.Lock()
+	defer ds.plugin.resyncLock.Unlock()
+	rendererCaches := []policydebug.RendererCache{
+		{Renderer: "ACL", View: ds.plugin.aclRenderer.CacheView()},
+	}
+	if !ds.plugin.Contiv.IsTCPstackDisabled() {
+		rendererCaches = append(rendererCaches,
+			policydebug.RendererCache{Renderer: "VPPTCP", View: ds.plugin.vppTCPRenderer.CacheView()})
+	}
+	return policydebug.NewDump(ds.plugin.configurator.DumpPodConfigs(), rendererCaches)
+}
+
+// statusState gives Status Reporter access to the policy status of the Configurator,
+// synchronized with the processing of K8s state changes.
+type statusState struct {
+	plugin *Plugin
+}
+
+// GetPolicyStatus returns the rendering status of the policies applied to the local pods.
+func (ss *statusState) GetPolicyStatus() []*configurator.PolicyStatus {
+	ss.plugin.resyncLock.Lock()
+	defer ss.plugin.resyncLock.Unlock()
+	return ss.plugin.configurator.GetPolicyStatus()
+}
@@ -0,0 +1,380 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package processor
+
+import (
+	"net"
+
+	"github.com/ligato/cn-infra/logging"
+
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
+	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	config "github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+)
+
+// processedClusterPolicy is a cluster-wide policy converted to ContivPolicy
+// together with the set of pods it applies to.
+type processedClusterPolicy struct {
+	policy *config.ContivPolicy
+	pods   map[podmodel.ID]struct{}
+}
+
+// AddClusterPolicy processes the event of newly added cluster-wide policy.
+// Cluster policies may select pods and peers from any namespace, therefore
+// all pods are re-processed.
+func (pp *PolicyProcessor) AddClusterPolicy(policy *clusterpolicymodel.ClusterPolicy) error {
+	pp.Log.WithField("policy", policy).Info("Cluster policy was added")
+	return pp.Process(false, pp.Cache.ListAllPods())
+}
+
+// DelClusterPolicy processes the event of a removed cluster-wide policy.
+// All pods are re-processed.
+func (pp *PolicyProcessor) DelClusterPolicy(policy *clusterpolicymodel.ClusterPolicy) error {
+	pp.Log.WithField("policy", policy).Info("Cluster policy was deleted")
+	return pp.Process(false, pp.Cache.ListAllPods())
+}
+
+// UpdateClusterPolicy processes the event of changed cluster-wide policy data.
+// All pods are re-processed.
+func (pp *PolicyProcessor) UpdateClusterPolicy(oldPolicy, newPolicy *clusterpolicymodel.ClusterPolicy) error {
+	pp.Log.WithFields(logging.Fields{
+		"new-policy": newPolicy,
+		"old-policy": oldPolicy,
+	}).Info("Cluster policy was updated")
+	return pp.Process(false, pp.Cache.ListAllPods())
+}
+
+// getPodsAffectedByClusterPolicyPod returns pods whose cluster policies have to be
+// re-evaluated after a change of the given pod (<pods> are the versions of the pod
+// before and after the change, nil if not known). A cluster policy is affected
+// if the pod (in any of the versions) is selected by the policy or by any of
+// its peers; all pods the policy applies to are returned together with the
+// changed pod itself (which may have stopped matching the policy).
+func (pp *PolicyProcessor) getPodsAffectedByClusterPolicyPod(podID podmodel.ID, pods ...*podmodel.Pod) []podmodel.ID {
+	var nsLabels map[string]string
+	if found, nsData := pp.Cache.LookupNamespace(nsmodel.ID(podID.Namespace)); found {
+		nsLabels = namespaceLabels(nsData)
+	}
+	var affected []podmodel.ID
+	for _, policyData := range pp.listClusterPolicies() {
+		for _, pod := range pods {
+			if pod == nil || !clusterPolicySelectsPod(policyData, nsLabels, podLabels(pod)) {
+				continue
+			}
+			affected = append(affected, pp.getPodsAssignedToClusterPolicy(policyData)...)
+			affected = append(affected, podID)
+			break
+		}
+	}
+	return affected
+}
+
+// getPodsAffectedByClusterPolicyNamespace returns pods whose cluster policies have
+// to be re-evaluated after a change of labels of the given namespace. A cluster
+// policy is affected if any of its namespace selectors matches the namespace
+// before or after the change; all pods the policy applies to are returned
+// together with the pods of the namespace (which may have stopped matching the policy).
+func (pp *PolicyProcessor) getPodsAffectedByClusterPolicyNamespace(oldNs, newNs *nsmodel.Namespace) []podmodel.ID {
+	oldLabels, newLabels := namespaceLabels(oldNs), namespaceLabels(newNs)
+	var affected []podmodel.ID
+	nsPodsAdded := false
+	for _, policyData := range pp.listClusterPolicies() {
+		if !clusterPolicySelectsNamespace(policyData, oldLabels) &&
+			!clusterPolicySelectsNamespace(policyData, newLabels) {
+			continue
+		}
+		affected = append(affected, pp.getPodsAssignedToClusterPolicy(policyData)...)
+		if !nsPodsAdded {
+			affected = append(affected, pp.Cache.LookupPodsByNamespace(newNs.Name)...)
+			nsPodsAdded = true
+		}
+	}
+	return affected
+}
+
+// listClusterPolicies returns the data of all cluster-wide policies.
+func (pp *PolicyProcessor) listClusterPolicies() (policies []*clusterpolicymodel.ClusterPolicy) {
+	for _, name := range pp.Cache.ListAllClusterPolicies() {
+		if found, policyData := pp.Cache.LookupClusterPolicy(name); found {
+			policies = append(policies, policyData)
+		}
+	}
+	return policies
+}
+
+// getPodsAssignedToClusterPolicy returns all pods the cluster-wide policy applies to.
+func (pp *PolicyProcessor) getPodsAssignedToClusterPolicy(policyData *clusterpolicymodel.ClusterPolicy) []podmodel.ID {
+	return pp.Cache.LookupPodsByNsAndPodSelector(
+		clusterSelectorToPolicySelector(policyData.Namespaces),
+		clusterSelectorToPolicySelector(policyData.Pods))
+}
+
+// processClusterPolicies converts all cluster-wide policies into ContivPolicies
+// and evaluates the set of pods each of them applies to.
+func (pp *PolicyProcessor) processClusterPolicies() []*processedClusterPolicy {
+	var processed []*processedClusterPolicy
+	for _, name := range pp.Cache.ListAllClusterPolicies() {
+		found, policyData := pp.Cache.LookupClusterPolicy(name)
+		if !found {
+			continue
+		}
+		pods := make(map[podmodel.ID]struct{})
+		for _, pod := range pp.getPodsAssignedToClusterPolicy(policyData) {
+			pods[pod] = struct{}{}
+		}
+		processed = append(processed, &processedClusterPolicy{
+			policy: pp.convertClusterPolicy(policyData),
+			pods:   pods,
+		})
+	}
+	return processed
+}
+
+// convertClusterPolicy converts cluster-wide policy into ContivPolicy.
+func (pp *PolicyProcessor) convertClusterPolicy(policyData *clusterpolicymodel.ClusterPolicy) *config.ContivPolicy {
+	contivPolicy := &config.ContivPolicy{
+		ID:          policymodel.ID{Name: policyData.Name},
+		Type:        config.PolicyAll,
+		ClusterWide: true,
+		Priority:    policyData.Priority,
+	}
+	for _, rule := range policyData.IngressRule {
+		contivPolicy.Matches = append(contivPolicy.Matches, pp.convertClusterPolicyRule(config.MatchIngress, rule))
+	}
+	for _, rule := range policyData.EgressRule {
+		contivPolicy.Matches = append(contivPolicy.Matches, pp.convertClusterPolicyRule(config.MatchEgress, rule))
+	}
+	return contivPolicy
+}
+
+// convertClusterPolicyRule converts a rule of a cluster-wide policy into Match.
+func (pp *PolicyProcessor) convertClusterPolicyRule(matchType config.MatchType,
+	rule *clusterpolicymodel.ClusterPolicy_Rule) config.Match {
+
+	match := config.Match{
+		Type:   matchType,
+		Action: config.MatchAllow,
+	}
+	if rule.Action == clusterpolicymodel.ClusterPolicy_DENY {
+		match.Action = config.MatchDeny
+	}
+
+	if len(rule.Peers) > 0 {
+		// Non-empty list of peers - match only the selected pods and IP blocks.
+		match.Pods = []podmodel.ID{}
+		match.IPBlocks = []config.IPBlock{}
+	}
+	var services []*clusterpolicymodel.ClusterPolicy_Peer_ServiceRef
+	for _, peer := range rule.Peers {
+		if peer.Service != nil {
+			if matchType == config.MatchEgress {
+				services = append(services, peer.Service)
+			} else {
+				pp.Log.WithField("service", peer.Service).Warn("Service peer is supported only for egress rules")
+			}
+		}
+		if peer.Namespaces != nil || peer.Pods != nil {
+			pods := pp.Cache.LookupPodsByNsAndPodSelector(
+				clusterSelectorToPolicySelector(peer.Namespaces),
+				clusterSelectorToPolicySelector(peer.Pods))
+			match.Pods = append(match.Pods, pods...)
+		}
+		if peer.Fqdn != "" {
+			if matchType == config.MatchEgress {
+				match.FQDNs = append(match.FQDNs, peer.Fqdn)
+			} else {
+				pp.Log.WithField("fqdn", peer.Fqdn).Warn("FQDN peer is supported only for egress rules")
+			}
+		}
+		if peer.IpBlock == nil {
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(peer.IpBlock.Cidr)
+		if err != nil {
+			pp.Log.WithField("cidr", peer.IpBlock.Cidr).Warn("Invalid CIDR in cluster policy")
+			continue
+		}
+		block := config.IPBlock{Network: *cidr, Except: []net.IPNet{}}
+		for _, except := range peer.IpBlock.Except {
+			_, exceptNet, err := net.ParseCIDR(except)
+			if err != nil {
+				pp.Log.WithField("cidr", except).Warn("Invalid CIDR in cluster policy")
+				continue
+			}
+			block.Except = append(block.Except, *exceptNet)
+		}
+		match.IPBlocks = append(match.IPBlocks, block)
+	}
+
+	for _, port := range rule.Ports {
+		if port.Protocol == clusterpolicymodel.ClusterPolicy_Port_ICMP {
+			match.Ports = append(match.Ports, config.Port{
+				Protocol: config.ICMP,
+				ICMPType: icmpMatch(port.IcmpType),
+				ICMPCode: icmpMatch(port.IcmpCode),
+			})
+			continue
+		}
+		protocol := config.TCP
+		switch port.Protocol {
+		case clusterpolicymodel.ClusterPolicy_Port_UDP:
+			protocol = config.UDP
+		case clusterpolicymodel.ClusterPolicy_Port_SCTP:
+			protocol = config.SCTP
+		}
+		match.Ports = append(match.Ports, config.Port{
+			Protocol:  protocol,
+			Number:    uint16(port.Port),
+			EndNumber: portRangeEnd(port.Port, port.EndPort),
+		})
+	}
+
+	// Services are expanded with the ports known.
+	for _, service := range services {
+		match.Services = append(match.Services, pp.convertServicePeer(service, match.Ports))
+	}
+	return match
+}
+
+// clusterSelectorToPolicySelector converts label selector of a cluster-wide
+// policy into the selector of K8s network policy, as used by the cache.
+func clusterSelectorToPolicySelector(selector *clusterpolicymodel.ClusterPolicy_LabelSelector) *policymodel.Policy_LabelSelector {
+	if selector == nil {
+		return nil
+	}
+	policySelector := &policymodel.Policy_LabelSelector{}
+	for _, label := range selector.MatchLabel {
+		policySelector.MatchLabel = append(policySelector.MatchLabel,
+			&policymodel.Policy_Label{Key: label.Key, Value: label.Value})
+	}
+	for _, expression := range selector.MatchExpression {
+		policySelector.MatchExpression = append(policySelector.MatchExpression,
+			&policymodel.Policy_LabelSelector_LabelExpression{
+				Key:      expression.Key,
+				Operator: policymodel.Policy_LabelSelector_LabelExpression_Operator(expression.Operator),
+				Value:    expression.Value,
+			})
+	}
+	return policySelector
+}
+
+// icmpMatch converts ICMP type or code of a cluster policy port into the match
+// used by the configurator (values outside of 0-255 match all types/codes).
+func icmpMatch(value int32) int16 {
+	if value < 0 || value > 255 {
+		return renderer.AnyICMP
+	}
+	return int16(value)
+}
+
+// clusterPolicySelectsPod returns true if the pod with the given labels (in the namespace
+// with <nsLabels>) is selected by the cluster policy or by any of its peers.
+func clusterPolicySelectsPod(policyData *clusterpolicymodel.ClusterPolicy, nsLabels, podLabels map[string]string) bool {
+	if clusterSelectorMatches(policyData.Namespaces, nsLabels) && clusterSelectorMatches(policyData.Pods, podLabels) {
+		return true
+	}
+	for _, peer := range clusterPolicyPeers(policyData) {
+		if peer.Namespaces == nil && peer.Pods == nil {
+			continue
+		}
+		if clusterSelectorMatches(peer.Namespaces, nsLabels) && clusterSelectorMatches(peer.Pods, podLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterPolicySelectsNamespace returns true if any namespace selector of the cluster
+// policy or of its peers matches the namespace with the given labels.
+func clusterPolicySelectsNamespace(policyData *clusterpolicymodel.ClusterPolicy, nsLabels map[string]string) bool {
+	if policyData.Namespaces != nil && clusterSelectorMatches(policyData.Namespaces, nsLabels) {
+		return true
+	}
+	for _, peer := range clusterPolicyPeers(policyData) {
+		if peer.Namespaces != nil && clusterSelectorMatches(peer.Namespaces, nsLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterPolicyPeers returns peers of all ingress and egress rules of the cluster policy.
+func clusterPolicyPeers(policyData *clusterpolicymodel.ClusterPolicy) (peers []*clusterpolicymodel.ClusterPolicy_Peer) {
+	for _, rule := range policyData.IngressRule {
+		peers = append(peers, rule.Peers...)
+	}
+	for _, rule := range policyData.EgressRule {
+		peers = append(peers, rule.Peers...)
+	}
+	return peers
+}
+
+// clusterSelectorMatches evaluates label selector of a cluster-wide policy against
+// the given labels. Nil selector matches everything.
+func clusterSelectorMatches(selector *clusterpolicymodel.ClusterPolicy_LabelSelector, labels map[string]string) bool {
+	if selector == nil {
+		return true
+	}
+	for _, label := range selector.MatchLabel {
+		if value, hasKey := labels[label.Key]; !hasKey || value != label.Value {
+			return false
+		}
+	}
+	for _, expression := range selector.MatchExpression {
+		value, hasKey := labels[expression.Key]
+		switch expression.Operator {
+		case clusterpolicymodel.ClusterPolicy_LabelSelector_LabelExpression_IN:
+			if !hasKey || !containsString(expression.Value, value) {
+				return false
+			}
+		case clusterpolicymodel.ClusterPolicy_LabelSelector_LabelExpression_NOT_IN:
+			if hasKey && containsString(expression.Value, value) {
+				return false
+			}
+		case clusterpolicymodel.ClusterPolicy_LabelSelector_LabelExpression_EXISTS:
+			if !hasKey {
+				return false
+			}
+		case clusterpolicymodel.ClusterPolicy_LabelSelector_LabelExpression_DOES_NOT_EXIST:
+			if hasKey {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// podLabels returns labels of the pod as a map.
+func podLabels(pod *podmodel.Pod) map[string]string {
+	labels := make(map[string]string)
+	for _, label := range pod.Label {
+		labels[label.Key] = label.Value
+	}
+	return labels
+}
+
+// namespaceLabels returns labels of the namespace as a map.
+func namespaceLabels(ns *nsmodel.Namespace) map[string]string {
+	labels := make(map[string]string)
+	for _, label := range ns.Label {
+		labels[label.Key] = label.Value
+	}
+	return labels
+}
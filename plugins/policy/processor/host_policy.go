@@ -0,0 +1,219 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package processor
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ligato/cn-infra/logging"
+
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
+	nodemodel "github.com/contiv/vpp/plugins/ksr/model/node"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	config "github.com/contiv/vpp/plugins/policy/configurator"
+)
+
+const (
+	// HostFailSafePolicyName is the name of the policy allowing the fail-safe
+	// ports into the host of a node selected by host-endpoint policies.
+	// It is evaluated before any host-endpoint policy and cannot be overridden.
+	HostFailSafePolicyName = "host-endpoint-fail-safe"
+)
+
+// DefaultFailSafeInbound is the list of ports always allowed into the host
+// unless configured otherwise: SSH, DHCP client, etcd (incl. contiv-etcd),
+// K8s API server and kubelet.
+var DefaultFailSafeInbound = []string{
+	"tcp:22", "udp:68", "tcp:2379", "tcp:2380", "tcp:12379", "tcp:6443", "tcp:10250",
+}
+
+// AddHostEndpointPolicy processes the event of newly added host-endpoint policy.
+func (pp *PolicyProcessor) AddHostEndpointPolicy(policy *clusterpolicymodel.HostEndpointPolicy) error {
+	pp.Log.WithField("policy", policy).Info("Host-endpoint policy was added")
+	return pp.processHost()
+}
+
+// DelHostEndpointPolicy processes the event of a removed host-endpoint policy.
+func (pp *PolicyProcessor) DelHostEndpointPolicy(policy *clusterpolicymodel.HostEndpointPolicy) error {
+	pp.Log.WithField("policy", policy).Info("Host-endpoint policy was deleted")
+	return pp.processHost()
+}
+
+// UpdateHostEndpointPolicy processes the event of changed host-endpoint
+// policy data.
+func (pp *PolicyProcessor) UpdateHostEndpointPolicy(oldPolicy, newPolicy *clusterpolicymodel.HostEndpointPolicy) error {
+	pp.Log.WithFields(logging.Fields{
+		"new-policy": newPolicy,
+		"old-policy": oldPolicy,
+	}).Info("Host-endpoint policy was updated")
+	return pp.processHost()
+}
+
+// AddNode processes the event of newly added K8s node. Host policies are
+// re-processed if the node is this node.
+func (pp *PolicyProcessor) AddNode(node *nodemodel.Node) error {
+	if node.Name != pp.nodeName() {
+		return nil
+	}
+	pp.Log.WithField("node", node).Info("This node was added")
+	return pp.processHost()
+}
+
+// DelNode processes the event of a removed K8s node (no action needed).
+func (pp *PolicyProcessor) DelNode(node *nodemodel.Node) error {
+	return nil
+}
+
+// UpdateNode processes the event of changed K8s node data. Host policies are
+// re-processed if labels of this node have changed.
+func (pp *PolicyProcessor) UpdateNode(oldNode, newNode *nodemodel.Node) error {
+	if newNode.Name != pp.nodeName() || reflect.DeepEqual(oldNode.Label, newNode.Label) {
+		return nil
+	}
+	pp.Log.WithFields(logging.Fields{
+		"new-node": newNode,
+		"old-node": oldNode,
+	}).Info("Labels of this node were updated")
+	return pp.processHost()
+}
+
+// processHost re-calculates the set of host-endpoint policies applied to this
+// node and propagates them into the configurator. Pods are left unchanged.
+func (pp *PolicyProcessor) processHost() error {
+	txn := pp.Configurator.NewTxn(false)
+	txn.ConfigureHost(pp.processHostPolicies())
+	return txn.Commit()
+}
+
+// processHostPolicies converts host-endpoint policies selecting this node into
+// ContivPolicies. If there is at least one such policy, the fail-safe policy
+// is included as well. Returns empty list if the host is not isolated.
+func (pp *PolicyProcessor) processHostPolicies() []*config.ContivPolicy {
+	found, node := pp.Cache.LookupNode(pp.nodeName())
+	if !found {
+		return nil
+	}
+	var policies []*config.ContivPolicy
+	for _, name := range pp.Cache.ListAllHostEndpointPolicies() {
+		found, policyData := pp.Cache.LookupHostEndpointPolicy(name)
+		if !found || !isNodeSelected(node, policyData.Nodes) {
+			continue
+		}
+		policy := &config.ContivPolicy{
+			ID:          policymodel.ID{Name: policyData.Name},
+			Type:        config.PolicyIngress,
+			ClusterWide: true,
+			Priority:    policyData.Priority,
+		}
+		if policy.Priority == math.MinInt32 {
+			// The highest priority is reserved for the fail-safe policy.
+			policy.Priority++
+		}
+		for _, rule := range policyData.IngressRule {
+			policy.Matches = append(policy.Matches, pp.convertClusterPolicyRule(config.MatchIngress, rule))
+		}
+		policies = append(policies, policy)
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	return append([]*config.ContivPolicy{pp.failSafePolicy()}, policies...)
+}
+
+// failSafePolicy returns the policy allowing the fail-safe ports into the host.
+func (pp *PolicyProcessor) failSafePolicy() *config.ContivPolicy {
+	failSafe := DefaultFailSafeInbound
+	if pp.Contiv != nil && len(pp.Contiv.GetHostEndpointPolicyConfig().FailSafeInbound) > 0 {
+		failSafe = pp.Contiv.GetHostEndpointPolicyConfig().FailSafeInbound
+	}
+	match := config.Match{
+		Type:   config.MatchIngress,
+		Action: config.MatchAllow,
+	}
+	for _, protoPort := range failSafe {
+		port, err := parseProtocolPort(protoPort)
+		if err != nil {
+			pp.Log.WithField("port", protoPort).Warn("Invalid fail-safe port")
+			continue
+		}
+		match.Ports = append(match.Ports, port)
+	}
+	return &config.ContivPolicy{
+		ID:          policymodel.ID{Name: HostFailSafePolicyName},
+		Type:        config.PolicyIngress,
+		ClusterWide: true,
+		Priority:    math.MinInt32,
+		Matches:     []config.Match{match},
+	}
+}
+
+// nodeName returns the name of this K8s node.
+func (pp *PolicyProcessor) nodeName() string {
+	if pp.ServiceLabel == nil {
+		return ""
+	}
+	return pp.ServiceLabel.GetAgentLabel()
+}
+
+// isNodeSelected returns true if the node labels match the given selector
+// (nil selector matches all nodes).
+func isNodeSelected(node *nodemodel.Node, selector *clusterpolicymodel.ClusterPolicy_LabelSelector) bool {
+	labels := make(map[string]string)
+	for _, label := range node.Label {
+		labels[label.Key] = label.Value
+	}
+	return clusterSelectorMatches(selector, labels)
+}
+
+// containsString returns true if the list contains the given string.
+func containsString(list []string, str string) bool {
+	for _, item := range list {
+		if item == str {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProtocolPort parses port in the format "<protocol>:<port>".
+func parseProtocolPort(protoPort string) (port config.Port, err error) {
+	protoPort = strings.ToLower(strings.TrimSpace(protoPort))
+	idx := strings.Index(protoPort, ":")
+	if idx < 0 {
+		return port, fmt.Errorf("missing protocol in port %s", protoPort)
+	}
+	switch protoPort[:idx] {
+	case "tcp":
+		port.Protocol = config.TCP
+	case "udp":
+		port.Protocol = config.UDP
+	case "sctp":
+		port.Protocol = config.SCTP
+	default:
+		return port, fmt.Errorf("unsupported protocol in port %s", protoPort)
+	}
+	number, err := strconv.ParseUint(protoPort[idx+1:], 10, 16)
+	if err != nil || number == 0 {
+		return port, fmt.Errorf("invalid port number in port %s", protoPort)
+	}
+	port.Number = uint16(number)
+	return port, nil
+}
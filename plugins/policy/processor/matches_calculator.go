@@ -68,16 +68,14 @@ func (pp *PolicyProcessor) calculateMatches(policyData *policymodel.Policy, podI
 
 			ingressRulePorts := ingressRule.Port
 			for _, ingressRulePort := range ingressRulePorts {
-				ingressPortProtocol := config.TCP
-				if ingressRulePort.Protocol == policymodel.Policy_Port_UDP {
-					ingressPortProtocol = config.UDP
-				}
+				ingressPortProtocol := portProtocol(ingressRulePort.Protocol)
 				// A port in kubernetes network policy is either a name (1) or a port number (0)
 				if ingressRulePort.Port.Type == 0 {
 					ingressPortNumber := uint16(ingressRulePort.Port.Number)
 					ingressPorts = append(ingressPorts, config.Port{
-						Protocol: ingressPortProtocol,
-						Number:   ingressPortNumber,
+						Protocol:  ingressPortProtocol,
+						Number:    ingressPortNumber,
+						EndNumber: portRangeEnd(ingressRulePort.Port.Number, ingressRulePort.EndPort),
 					})
 				} else {
 					// Obtain data for pod that matches are calculated
@@ -157,16 +155,14 @@ func (pp *PolicyProcessor) calculateMatches(policyData *policymodel.Policy, podI
 			egressRulePorts := egressRule.Port
 			// Egress ports to appropriate type
 			for _, egressRulePort := range egressRulePorts {
-				egressPortProtocol := config.TCP
-				if egressRulePort.Protocol == policymodel.Policy_Port_UDP {
-					egressPortProtocol = config.UDP
-				}
+				egressPortProtocol := portProtocol(egressRulePort.Protocol)
 
 				if egressRulePort.Port.Type == 0 {
 					egressPortNumber := uint16(egressRulePort.Port.Number)
 					egressPorts = append(egressPorts, config.Port{
-						Protocol: egressPortProtocol,
-						Number:   egressPortNumber,
+						Protocol:  egressPortProtocol,
+						Number:    egressPortNumber,
+						EndNumber: portRangeEnd(egressRulePort.Port.Number, egressRulePort.EndPort),
 					})
 				} else {
 					// if there are egressPods then map the portName to portNumber for every each one of them
@@ -219,3 +215,27 @@ func (pp *PolicyProcessor) portNameToNumber(pods []podmodel.ID, portProtocol con
 	}
 	return matches
 }
+
+// portProtocol converts protocol of a policy port into the protocol type
+// used by the configurator.
+func portProtocol(protocol policymodel.Policy_Port_Protocol) config.ProtocolType {
+	switch protocol {
+	case policymodel.Policy_Port_UDP:
+		return config.UDP
+	case policymodel.Policy_Port_SCTP:
+		return config.SCTP
+	}
+	return config.TCP
+}
+
+// portRangeEnd returns the last port of the range <port>..<endPort> as expected
+// by config.Port, i.e. 0 if the selector matches only a single port (or all ports).
+func portRangeEnd(port, endPort int32) uint16 {
+	if port == 0 || endPort <= port {
+		return 0
+	}
+	if endPort > 65535 {
+		endPort = 65535
+	}
+	return uint16(endPort)
+}
@@ -18,8 +18,10 @@ package processor
 
 import (
 	"net"
+	"reflect"
 
 	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/servicelabel"
 
 	"github.com/contiv/vpp/plugins/contiv"
 	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
@@ -27,9 +29,18 @@ import (
 	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	"github.com/contiv/vpp/plugins/policy/cache"
 	config "github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/svccache"
 	"github.com/contiv/vpp/plugins/policy/utils"
 )
 
+const (
+	// AuditLabel is the label which, when set to "true" on a K8s NetworkPolicy
+	// or on a namespace (affecting all policies inside), switches the policy(-ies)
+	// into the audit mode: traffic that would be denied is permitted and logged.
+	// The label of the policy takes precedence over the label of the namespace.
+	AuditLabel = "contiv.vpp/policy-audit"
+)
+
 // PolicyProcessor processes K8s State data and generates a set of Contiv
 // policies for each pod with outdated configuration.
 // PolicyProcessor implements the PolicyCacheWatcher interface to watch
@@ -44,6 +55,7 @@ import (
 type PolicyProcessor struct {
 	Deps
 	podIPAddressMap map[podmodel.ID]net.IP
+	loadBalancers   []*config.LoadBalancer // last configured load-balancers
 }
 
 // Deps lists dependencies of Policy Processor.
@@ -52,6 +64,8 @@ type Deps struct {
 	Cache        cache.PolicyCacheAPI
 	Contiv       contiv.API /* to get the Host IP */
 	Configurator config.PolicyConfiguratorAPI
+	ServiceLabel servicelabel.ReaderAPI   /* to get the name of this node, optional */
+	ServiceCache svccache.ServiceCacheAPI /* to expand service peers, optional */
 }
 
 // Init initializes the Policy Processor.
@@ -73,17 +87,32 @@ func (pp *PolicyProcessor) Process(resync bool, pods []podmodel.ID) error {
 
 	// Re-configure only pods that belong to the current node.
 	pods = pp.filterHostPods(pods)
-	if len(pods) == 0 {
+	if len(pods) == 0 && !resync {
 		return nil
 	}
 
 	txn := pp.Configurator.NewTxn(resync)
+	if resync {
+		// Host-endpoint policies are re-processed with every resync.
+		txn.ConfigureHost(pp.processHostPolicies())
+		// The same applies to the access restrictions of load-balancers.
+		pp.loadBalancers = pp.processLoadBalancers()
+		txn.ConfigureLoadBalancers(pp.loadBalancers)
+	}
 	processedPolicies := make(map[policymodel.ID]*config.ContivPolicy)
+	clusterPolicies := pp.processClusterPolicies()
 	pp.Log.WithField("pods", pods).Info("Non-empty set of pods sent to Process")
 
 	for _, pod := range pods {
 		policies := []*config.ContivPolicy{}
 
+		// Add cluster-wide policies selecting the pod.
+		for _, clusterPolicy := range clusterPolicies {
+			if _, selected := clusterPolicy.pods[pod]; selected {
+				policies = append(policies, clusterPolicy.policy)
+			}
+		}
+
 		// Find the policies the pod in the slice is associated with.
 		policiesByPod := pp.Cache.LookupPoliciesByPod(pod)
 		if len(policiesByPod) == 0 {
@@ -125,6 +154,7 @@ func (pp *PolicyProcessor) Process(resync bool, pods []podmodel.ID) error {
 						Namespace: policyData.Namespace,
 					},
 					Type:    policyType,
+					Audit:   pp.isPolicyAudited(policyData),
 					Matches: matches,
 				}
 				processedPolicies[policy] = contivPolicy
@@ -170,6 +200,9 @@ func (pp *PolicyProcessor) AddPod(podID podmodel.ID, pod *podmodel.Pod) error {
 	// Update newly added pod as well.
 	pods = append(pods, podID)
 
+	// The pod may be selected by cluster policies or be their peer.
+	pods = append(pods, pp.getPodsAffectedByClusterPolicyPod(podID, pod)...)
+
 	return pp.Process(false, pods)
 }
 
@@ -190,6 +223,9 @@ func (pp *PolicyProcessor) DelPod(podID podmodel.ID, pod *podmodel.Pod) error {
 	// Update deleted pod as well.
 	pods = append(pods, podID)
 
+	// The pod may have been selected by cluster policies or been their peer.
+	pods = append(pods, pp.getPodsAffectedByClusterPolicyPod(podID, pod)...)
+
 	err := pp.Process(false, pods)
 
 	// Remove remembered pod IP address.
@@ -237,11 +273,14 @@ func (pp *PolicyProcessor) UpdatePod(podID podmodel.ID, oldPod, newPod *podmodel
 		}
 	}
 
-	// Process this pod also in case the IP address has changed.
-	if newPod.IpAddress != oldPod.IpAddress {
+	// Process this pod also in case the IP address(es) have changed.
+	if !reflect.DeepEqual(utils.GetPodIPAddresses(newPod), utils.GetPodIPAddresses(oldPod)) {
 		pods = append(pods, podID)
 	}
 
+	// Labels or IP address of the pod may be referenced by cluster policies.
+	pods = append(pods, pp.getPodsAffectedByClusterPolicyPod(podID, oldPod, newPod)...)
+
 	return pp.Process(false, pods)
 }
 
@@ -346,9 +385,46 @@ func (pp *PolicyProcessor) UpdateNamespace(oldNs, newNs *nsmodel.Namespace) erro
 		pods = append(pods, pp.getPodsAssignedToPolicy(policy)...)
 	}
 
+	// Namespace labels may be referenced by cluster policies.
+	pods = append(pods, pp.getPodsAffectedByClusterPolicyNamespace(oldNs, newNs)...)
+
+	// Audit mode of all policies in the namespace may have changed.
+	if hasAuditLabel(oldNs.Label) != hasAuditLabel(newNs.Label) {
+		for _, policy := range pp.Cache.ListAllPolicies() {
+			if policy.Namespace != newNs.Name {
+				continue
+			}
+			if found, policyData := pp.Cache.LookupPolicy(policy); found {
+				pods = append(pods, pp.getPodsAssignedToPolicy(policyData)...)
+			}
+		}
+	}
+
 	return pp.Process(false, pods)
 }
 
+// isPolicyAudited returns true if the policy or its namespace has the audit
+// label set.
+func (pp *PolicyProcessor) isPolicyAudited(policy *policymodel.Policy) bool {
+	for _, label := range policy.Label {
+		if label.Key == AuditLabel {
+			return label.Value == "true"
+		}
+	}
+	found, nsData := pp.Cache.LookupNamespace(nsmodel.ID(policy.Namespace))
+	return found && hasAuditLabel(nsData.Label)
+}
+
+// hasAuditLabel returns true if the namespace labels include the audit label.
+func hasAuditLabel(labels []*nsmodel.Namespace_Label) bool {
+	for _, label := range labels {
+		if label.Key == AuditLabel && label.Value == "true" {
+			return true
+		}
+	}
+	return false
+}
+
 // Close deallocates all resources held by the processor.
 func (pp *PolicyProcessor) Close() error {
 	return nil
@@ -394,10 +470,10 @@ func (pp *PolicyProcessor) getPodsAssignedToPolicy(policy *policymodel.Policy) (
 func (pp *PolicyProcessor) getPoliciesReferencingPod(pod *podmodel.Pod) (policies map[policymodel.ID]*policymodel.Policy) {
 	policies = make(map[policymodel.ID]*policymodel.Policy)
 
-	// Fetch data of all policies from the cache.
-	allPolicies := pp.Cache.ListAllPolicies()
+	// Fetch data of policies that may reference the pod (reverse index of the cache).
+	candidatePolicies := pp.Cache.LookupPoliciesReferencingPod(pod)
 	dataPolicies := []*policymodel.Policy{}
-	for _, policy := range allPolicies {
+	for _, policy := range candidatePolicies {
 		found, policyData := pp.Cache.LookupPolicy(policy)
 
 		if !found {
@@ -480,10 +556,10 @@ func (pp *PolicyProcessor) getPoliciesReferencingPod(pod *podmodel.Pod) (policie
 func (pp *PolicyProcessor) getPoliciesReferencingNamespace(ns *nsmodel.Namespace) (policies map[policymodel.ID]*policymodel.Policy) {
 	policies = make(map[policymodel.ID]*policymodel.Policy)
 
-	// Fetch data of all policies from the cache.
-	allPolicies := pp.Cache.ListAllPolicies()
+	// Fetch data of policies that may reference the namespace (reverse index of the cache).
+	candidatePolicies := pp.Cache.LookupPoliciesReferencingNamespace(ns)
 	dataPolicies := []*policymodel.Policy{}
-	for _, policy := range allPolicies {
+	for _, policy := range candidatePolicies {
 		found, policyData := pp.Cache.LookupPolicy(policy)
 
 		if !found {
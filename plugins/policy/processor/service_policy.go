@@ -0,0 +1,193 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package processor
+
+import (
+	"reflect"
+	"sort"
+
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+	config "github.com/contiv/vpp/plugins/policy/configurator"
+	svcrenderer "github.com/contiv/vpp/plugins/service/renderer"
+)
+
+// UpdateServices processes the event of changed frontends or backends
+// of the given services. Access restrictions of load-balancers are
+// re-configured if they have changed. All pods are re-processed if at least
+// one of the services is referenced by a cluster-wide policy.
+func (pp *PolicyProcessor) UpdateServices(services []svcmodel.ID) error {
+	if loadBalancers := pp.processLoadBalancers(); !reflect.DeepEqual(loadBalancers, pp.loadBalancers) {
+		pp.Log.WithField("loadBalancers", loadBalancers).Info("Access restrictions of load-balancers were updated")
+		pp.loadBalancers = loadBalancers
+		txn := pp.Configurator.NewTxn(false)
+		txn.ConfigureLoadBalancers(loadBalancers)
+		if err := txn.Commit(); err != nil {
+			return err
+		}
+	}
+
+	changed := make(map[svcmodel.ID]struct{})
+	for _, service := range services {
+		changed[service] = struct{}{}
+	}
+	for _, name := range pp.Cache.ListAllClusterPolicies() {
+		found, policyData := pp.Cache.LookupClusterPolicy(name)
+		if !found {
+			continue
+		}
+		for _, rule := range policyData.EgressRule {
+			for _, peer := range rule.Peers {
+				if peer.Service == nil {
+					continue
+				}
+				if _, isChanged := changed[serviceRefToID(peer.Service)]; isChanged {
+					pp.Log.WithField("services", services).Info("Services referenced by cluster policies were updated")
+					return pp.Process(false, pp.Cache.ListAllPods())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// convertServicePeer expands service referenced by a cluster policy rule into
+// the list of frontends (service IPs with service ports) and backends
+// (endpoints with target ports). If the rule selects ports, only the matching
+// service ports and their backends are included.
+// Unknown service (or service without endpoints) is expanded into an empty set
+// of addresses, matching no traffic.
+func (pp *PolicyProcessor) convertServicePeer(ref *clusterpolicymodel.ClusterPolicy_Peer_ServiceRef,
+	ports []config.Port) config.ServicePeer {
+
+	peer := config.ServicePeer{
+		ID:        serviceRefToID(ref),
+		Frontends: []config.ServiceAddr{},
+		Backends:  []config.ServiceAddr{},
+	}
+	if pp.ServiceCache == nil {
+		pp.Log.WithField("service", peer.ID).Warn("Service peer is not supported without the service cache")
+		return peer
+	}
+	service := pp.ServiceCache.LookupService(peer.ID)
+	if service == nil {
+		return peer
+	}
+
+	// Iterate service ports in a stable order to get the same rules every time.
+	var portNames []string
+	for portName := range service.Ports {
+		portNames = append(portNames, portName)
+	}
+	sort.Strings(portNames)
+
+	for _, portName := range portNames {
+		svcPort := service.Ports[portName]
+		protocol := serviceProtocol(svcPort.Protocol)
+		frontendPort := config.Port{Protocol: protocol, Number: svcPort.Port}
+		if len(ports) > 0 && !isPortSelected(ports, frontendPort) {
+			continue
+		}
+		for _, ip := range service.ExternalIPs.List() {
+			peer.Frontends = append(peer.Frontends, config.ServiceAddr{IP: ip, Port: frontendPort})
+		}
+		for _, backend := range service.Backends[portName] {
+			peer.Backends = append(peer.Backends, config.ServiceAddr{
+				IP:   backend.IP,
+				Port: config.Port{Protocol: protocol, Number: backend.Port},
+			})
+		}
+	}
+	return peer
+}
+
+// processLoadBalancers returns services of type LoadBalancer with the access
+// restricted by source ranges, expanded into the list of frontends
+// (load-balancer IPs with service ports).
+func (pp *PolicyProcessor) processLoadBalancers() []*config.LoadBalancer {
+	loadBalancers := []*config.LoadBalancer{}
+	if pp.ServiceCache == nil {
+		return loadBalancers
+	}
+	for _, id := range pp.ServiceCache.ListServices() {
+		service := pp.ServiceCache.LookupService(id)
+		if service == nil || service.LoadBalancerIPs == nil ||
+			len(service.LoadBalancerIPs.List()) == 0 || len(service.LoadBalancerSourceRanges) == 0 {
+			continue
+		}
+		lb := &config.LoadBalancer{
+			Service:      id,
+			Frontends:    []config.ServiceAddr{},
+			SourceRanges: service.LoadBalancerSourceRanges,
+		}
+
+		// Iterate service ports in a stable order to get the same rules every time.
+		var portNames []string
+		for portName := range service.Ports {
+			portNames = append(portNames, portName)
+		}
+		sort.Strings(portNames)
+
+		for _, portName := range portNames {
+			svcPort := service.Ports[portName]
+			frontendPort := config.Port{Protocol: serviceProtocol(svcPort.Protocol), Number: svcPort.Port}
+			for _, ip := range service.LoadBalancerIPs.List() {
+				lb.Frontends = append(lb.Frontends, config.ServiceAddr{IP: ip, Port: frontendPort})
+			}
+		}
+		loadBalancers = append(loadBalancers, lb)
+	}
+	return loadBalancers
+}
+
+// serviceRefToID converts reference to a service into the service ID.
+func serviceRefToID(ref *clusterpolicymodel.ClusterPolicy_Peer_ServiceRef) svcmodel.ID {
+	return svcmodel.ID{Name: ref.Name, Namespace: ref.Namespace}
+}
+
+// serviceProtocol converts protocol of a service port into the protocol
+// used by the configurator.
+func serviceProtocol(protocol svcrenderer.ProtocolType) config.ProtocolType {
+	switch protocol {
+	case svcrenderer.UDP:
+		return config.UDP
+	case svcrenderer.SCTP:
+		return config.SCTP
+	}
+	return config.TCP
+}
+
+// isPortSelected returns true if the given port is included in the list
+// of ports (or port ranges).
+func isPortSelected(ports []config.Port, port config.Port) bool {
+	for _, selected := range ports {
+		if selected.Protocol != port.Protocol {
+			continue
+		}
+		if selected.Number == 0 {
+			return true
+		}
+		last := selected.Number
+		if selected.EndNumber > selected.Number {
+			last = selected.EndNumber
+		}
+		if port.Number >= selected.Number && port.Number <= last {
+			return true
+		}
+	}
+	return false
+}
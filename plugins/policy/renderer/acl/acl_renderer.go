@@ -17,14 +17,22 @@
 package acl
 
 import (
+	"context"
+	"fmt"
+	"hash/fnv"
 	"net"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 
+	govppapi "git.fd.io/govpp.git/api"
 	"github.com/golang/protobuf/proto"
 
 	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/cn-infra/datasync/syncbase"
 	"github.com/ligato/cn-infra/logging"
+	prometheusplugin "github.com/ligato/cn-infra/rpc/prometheus"
 	"github.com/ligato/vpp-agent/clientv1/linux"
 	"github.com/ligato/vpp-agent/plugins/vpp"
 	vpp_acl "github.com/ligato/vpp-agent/plugins/vpp/model/acl"
@@ -33,6 +41,7 @@ import (
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	"github.com/contiv/vpp/plugins/policy/renderer"
 	"github.com/contiv/vpp/plugins/policy/renderer/cache"
+	"github.com/contiv/vpp/plugins/policy/utils"
 )
 
 const (
@@ -44,8 +53,39 @@ const (
 	// regardless of installed policies on the way back.
 	ReflectiveACLName = "REFLECTION"
 
+	// HostInterconnectACLName is the name of the ACL filtering the traffic entering
+	// the host network stack (full name prefixed with ACLNamePrefix).
+	// The ACL is assigned to the egress of the host interconnect and installed
+	// only if the host is isolated by host-endpoint policies.
+	HostInterconnectACLName = "HOST-INTERCONNECT"
+
+	// HostACLName is the name of the ACL filtering the traffic destined to the node
+	// IP (full name prefixed with ACLNamePrefix), most importantly to the node ports,
+	// and to the load-balancer IPs.
+	// The ACL is assigned to the ingress of the physical interfaces and installed
+	// only if the host is isolated by host-endpoint policies or if the access
+	// to a load-balancer is restricted by source ranges.
+	HostACLName = "HOST"
+
+	// PreNATACLNamePrefix is the prefix of names of ACLs filtering the traffic
+	// sent by pods before the service address translation (full name prefixed
+	// with ACLNamePrefix). Pre-NAT ACLs are assigned to the ingress of pod
+	// interfaces (pods with the same rules share the ACL) and replace
+	// the reflective ACL for those interfaces.
+	PreNATACLNamePrefix = "PRE-NAT-"
+
+	// DefaultNodePortRange is the default range of ports reserved for the services
+	// of the NodePort type.
+	DefaultNodePortRange = "30000-32767"
+
 	ipv4AddrAny = "0.0.0.0/0"
 	ipv6AddrAny = "::/0"
+
+	// maxICMPValue is the maximum value of ICMP type and code.
+	maxICMPValue = 255
+
+	// sctpProtocol is the IP protocol number of SCTP.
+	sctpProtocol = 132
 )
 
 // Renderer renders Contiv Rules into VPP ACLs.
@@ -54,18 +94,53 @@ const (
 type Renderer struct {
 	Deps
 
+	sync.Mutex    // protects the cache between transactions and the hit counters
 	cache         *cache.RendererCache
 	podInterfaces PodInterfaces
+
+	// dualStack is true if the installed ACLs render rules with unspecified
+	// networks also for IPv6.
+	dualStack bool
+
+	// reflectiveACL is the installed reflective ACL (nil if not installed).
+	reflectiveACL *vpp_acl.AccessLists_Acl
+
+	// hostRules are the installed rules for the traffic entering the host
+	// (empty if the host is not isolated).
+	hostRules []*renderer.ContivRule
+
+	// lbRules are the installed rules for the traffic destined to the load-balancer
+	// IPs (empty if no load-balancer is restricted).
+	lbRules []*renderer.ContivRule
+
+	// hostACLs are the installed ACLs protecting the host, indexed by ACL names.
+	hostACLs map[string]*vpp_acl.AccessLists_Acl
+
+	// preNATRules are the installed rules for the traffic sent by pods,
+	// evaluated before NAT (pods without such rules are not included).
+	preNATRules map[podmodel.ID][]*renderer.ContivRule
+
+	// preNATACLs are the installed pre-NAT ACLs, indexed by ACL names.
+	preNATACLs map[string]*vpp_acl.AccessLists_Acl
+
+	hitCounters  *hitCounters
+	lastRuleHits map[ruleHitsKey]uint64 // hit counts from the previous reading of the counters
+	hitsChan     chan<- []*RuleHits
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
 }
 
 // Deps lists dependencies of Renderer.
 type Deps struct {
 	Log           logging.Logger
 	LogFactory    logging.LoggerFactory /* optional */
-	Contiv        contiv.API            /* for GetIfName() */
+	Contiv        contiv.API            /* for GetIfName(), GetNodeIP() */
 	VPP           vpp.API               /* for DumpACLs() */
 	ACLTxnFactory func() (dsl linuxclient.DataChangeDSL)
 	LatestRevs    *syncbase.PrevRevisions
+	GoVPPChan     govppapi.Channel     /* to read ACL counters, optional */
+	Prometheus    prometheusplugin.API /* for the hit counters, optional */
 }
 
 // RendererTxn represents a single transaction of Renderer.
@@ -75,12 +150,30 @@ type RendererTxn struct {
 	vpp      vpp.API
 	renderer *Renderer
 	resync   bool
+
+	// dualStack is true if at least one pod has IPv6 address assigned.
+	dualStack bool
+
+	// hostRules are the rules for the traffic entering the host, valid only
+	// if hostRendered is true (until the commit).
+	hostRules    []*renderer.ContivRule
+	hostRendered bool
+
+	// lbRules are the rules for the traffic destined to the load-balancer IPs,
+	// valid only if lbRendered is true (until the commit).
+	lbRules    []*renderer.ContivRule
+	lbRendered bool
+
+	// preNATRules are the pre-NAT rules of pods changed by the transaction
+	// (until the commit), after the commit all the pre-NAT rules.
+	preNATRules map[podmodel.ID][]*renderer.ContivRule
 }
 
 // PodInterfaces is a map used to remember interface of each (configured) pod.
 type PodInterfaces map[podmodel.ID]string
 
-// Init initializes the ACL Renderer.
+// Init initializes the ACL Renderer, registers the metrics of hit counters
+// and starts reading the ACL counters from VPP.
 func (r *Renderer) Init() error {
 	r.cache = &cache.RendererCache{}
 	if r.LogFactory != nil {
@@ -90,21 +183,51 @@ func (r *Renderer) Init() error {
 	}
 	r.cache.Init(cache.EgressOrientation)
 	r.podInterfaces = make(PodInterfaces)
+	r.hostACLs = make(map[string]*vpp_acl.AccessLists_Acl)
+	r.preNATRules = make(map[podmodel.ID][]*renderer.ContivRule)
+	r.preNATACLs = make(map[string]*vpp_acl.AccessLists_Acl)
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+
+	r.hitCounters = newHitCounters()
+	if r.Prometheus != nil {
+		for _, metric := range r.hitCounters.collectors() {
+			if err := r.Prometheus.Register(prometheusplugin.DefaultRegistry, metric); err != nil {
+				return err
+			}
+		}
+	}
+	if r.GoVPPChan != nil {
+		r.wg.Add(1)
+		go r.collectHitCounters()
+	}
+	return nil
+}
+
+// CacheView returns read-only view of the renderer cache.
+func (r *Renderer) CacheView() cache.View {
+	return r.cache
+}
+
+// Close stops reading the ACL counters.
+func (r *Renderer) Close() error {
+	r.cancel()
+	r.wg.Wait()
 	return nil
 }
 
 // NewTxn starts a new transaction. The rendering executes only after Commit()
-// is called. Rollback is not yet supported however.
+// is called. If the commit fails, the already applied changes are rolled back.
 // If <resync> is enabled, the supplied configuration will completely
 // replace the existing one. Otherwise, the change is performed incrementally,
 // i.e. interfaces not mentioned in the transaction are left unaffected.
 func (r *Renderer) NewTxn(resync bool) renderer.Txn {
 	txn := &RendererTxn{
-		Log:      r.Log,
-		cacheTxn: r.cache.NewTxn(),
-		vpp:      r.VPP,
-		renderer: r,
-		resync:   resync,
+		Log:         r.Log,
+		cacheTxn:    r.cache.NewTxn(),
+		vpp:         r.VPP,
+		renderer:    r,
+		resync:      resync,
+		preNATRules: make(map[podmodel.ID][]*renderer.ContivRule),
 	}
 	return txn
 }
@@ -112,7 +235,7 @@ func (r *Renderer) NewTxn(resync bool) renderer.Txn {
 // Render applies the set of ingress & egress rules for a given pod.
 // The existing rules are replaced.
 // Te actual change is performed only after the commit.
-func (art *RendererTxn) Render(pod podmodel.ID, podIP *net.IPNet, ingress []*renderer.ContivRule, egress []*renderer.ContivRule, removed bool) renderer.Txn {
+func (art *RendererTxn) Render(pod podmodel.ID, podIPs []*net.IPNet, ingress []*renderer.ContivRule, egress []*renderer.ContivRule, removed bool) renderer.Txn {
 	art.renderer.Log.WithFields(logging.Fields{
 		"pod":     pod,
 		"ingress": ingress,
@@ -120,7 +243,52 @@ func (art *RendererTxn) Render(pod podmodel.ID, podIP *net.IPNet, ingress []*ren
 		"removed": removed,
 	}).Debug("ACL RendererTxn Render()")
 
-	art.cacheTxn.Update(pod, &cache.PodConfig{PodIP: podIP, Ingress: ingress, Egress: egress, Removed: removed})
+	art.cacheTxn.Update(pod, &cache.PodConfig{PodIPs: podIPs, Ingress: ingress, Egress: egress, Removed: removed})
+	return art
+}
+
+// RenderHost applies the set of rules for the traffic entering the host network
+// stack. The rules are rendered into two ACLs - HOST-INTERCONNECT assigned
+// to the egress of the host interconnect and HOST assigned to the ingress
+// of the physical interfaces, protecting the node ports.
+// The existing host rules are replaced, empty set of rules removes both ACLs.
+func (art *RendererTxn) RenderHost(rules []*renderer.ContivRule) renderer.Txn {
+	art.renderer.Log.WithFields(logging.Fields{
+		"rules": rules,
+	}).Debug("ACL RendererTxn RenderHost()")
+
+	art.hostRules = rules
+	art.hostRendered = true
+	return art
+}
+
+// RenderLoadBalancers applies the set of rules for the traffic destined to the
+// load-balancer IPs. The rules are rendered into the HOST ACL, assigned to the
+// ingress of the physical interfaces.
+// The existing load-balancer rules are replaced.
+func (art *RendererTxn) RenderLoadBalancers(rules []*renderer.ContivRule) renderer.Txn {
+	art.renderer.Log.WithFields(logging.Fields{
+		"rules": rules,
+	}).Debug("ACL RendererTxn RenderLoadBalancers()")
+
+	art.lbRules = rules
+	art.lbRendered = true
+	return art
+}
+
+// RenderPreNAT applies the set of rules for the traffic sent by the given pod,
+// evaluated before the service address translation. The rules are rendered
+// into a pre-NAT ACL assigned to the ingress of the pod interface, shared
+// by all pods with the same rules.
+// The existing pre-NAT rules of the pod are replaced, empty set of rules
+// removes the pod from the pre-NAT ACL.
+func (art *RendererTxn) RenderPreNAT(pod podmodel.ID, rules []*renderer.ContivRule) renderer.Txn {
+	art.renderer.Log.WithFields(logging.Fields{
+		"pod":   pod,
+		"rules": rules,
+	}).Debug("ACL RendererTxn RenderPreNAT()")
+
+	art.preNATRules[pod] = rules
 	return art
 }
 
@@ -135,6 +303,8 @@ func (art *RendererTxn) Commit() error {
 		hasReflectiveACL bool
 		err              error
 	)
+	art.renderer.Lock()
+	defer art.renderer.Unlock()
 
 	if art.resync {
 		// Re-synchronize with VPP first.
@@ -160,6 +330,22 @@ func (art *RendererTxn) Commit() error {
 		for key := range keys {
 			art.renderer.LatestRevs.Del(key)
 		}
+		// -> remember the installed reflective, host and pre-NAT ACLs
+		art.renderer.reflectiveACL = nil
+		art.renderer.hostACLs = make(map[string]*vpp_acl.AccessLists_Acl)
+		art.renderer.preNATACLs = make(map[string]*vpp_acl.AccessLists_Acl)
+		for _, acl := range aclRawDump {
+			switch {
+			case acl.AclName == ACLNamePrefix+ReflectiveACLName:
+				art.renderer.reflectiveACL = acl
+			case acl.AclName == ACLNamePrefix+HostInterconnectACLName, acl.AclName == ACLNamePrefix+HostACLName:
+				art.renderer.hostACLs[acl.AclName] = acl
+			case strings.HasPrefix(acl.AclName, ACLNamePrefix+PreNATACLNamePrefix):
+				art.renderer.preNATACLs[acl.AclName] = acl
+			}
+		}
+		// -> learn if the installed ACLs are rendered for dual-stack
+		art.renderer.dualStack = hasIPv6Rules(aclRawDump)
 		// -> resync cache with VPP
 		err = art.renderer.cache.Resync(aclDump)
 		if err != nil {
@@ -177,35 +363,95 @@ func (art *RendererTxn) Commit() error {
 		}
 	} else {
 		if art.renderer.cache.GetGlobalTable().NumOfRules != 0 ||
-			len(art.renderer.cache.GetIsolatedPods()) > 0 {
+			len(art.renderer.cache.GetIsolatedPods()) > 0 || len(art.renderer.hostRules) > 0 {
 			hasReflectiveACL = true
 		}
 	}
 
+	// Host rules not changed by the transaction remain installed.
+	if !art.hostRendered {
+		art.hostRules = art.renderer.hostRules
+	}
+	hostIsolationChanged := (len(art.hostRules) > 0) != (len(art.renderer.hostRules) > 0)
+
+	// The same applies to the load-balancer rules.
+	if !art.lbRendered {
+		art.lbRules = art.renderer.lbRules
+	}
+	hostACLChanged := art.hasHostACL(art.hostRules, art.lbRules) !=
+		art.hasHostACL(art.renderer.hostRules, art.renderer.lbRules)
+
+	// Merge pre-NAT rules changed by the transaction with the installed ones.
+	preNATChanged := art.resync && len(art.renderer.preNATACLs) > 0
+	preNATRules := make(map[podmodel.ID][]*renderer.ContivRule)
+	if !art.resync {
+		for pod, rules := range art.renderer.preNATRules {
+			preNATRules[pod] = rules
+		}
+	}
+	for pod, rules := range art.preNATRules {
+		if !reflect.DeepEqual(rules, preNATRules[pod]) &&
+			(len(rules) > 0 || len(preNATRules[pod]) > 0) {
+			preNATChanged = true
+		}
+		if len(rules) == 0 {
+			delete(preNATRules, pod)
+		} else {
+			preNATRules[pod] = rules
+		}
+	}
+	art.preNATRules = preNATRules
+
+	// Rules with unspecified networks need to be rendered also for IPv6 once
+	// there is a pod with IPv6 address. All ACLs are re-rendered when this changes.
+	for pod := range art.cacheTxn.GetAllPods() {
+		if podCfg := art.cacheTxn.GetPodConfig(pod); podCfg != nil && hasIPv6Address(podCfg.PodIPs) {
+			art.dualStack = true
+			break
+		}
+	}
+	rerenderAll := art.dualStack != art.renderer.dualStack
+
 	// Get the minimalistic diff to be rendered.
 	changes := art.cacheTxn.GetChanges()
-	if !art.resync && len(changes) == 0 {
+	if !art.resync && !rerenderAll && !art.hostRendered && !art.lbRendered && !preNATChanged &&
+		len(changes) == 0 {
 		art.renderer.Log.Debug("No changes to be rendered in the transaction")
 		// Still need to commit the configuration updates from the transaction.
 		return art.cacheTxn.Commit()
 	}
 
+	// Remember the installed ACLs to be able to rollback the changes.
+	prevACLs := art.installedACLs()
+	reflectiveACL := art.renderer.reflectiveACL
+
 	// Render ACLs and propagate changes via localclient.
 	dsl := art.renderer.ACLTxnFactory()
 	putDsl := dsl.Put()
 	deleteDsl := dsl.Delete()
+	changedACLs := []string{}
+	putACL := func(acl *vpp_acl.AccessLists_Acl) {
+		putDsl.ACL(acl)
+		changedACLs = append(changedACLs, acl.AclName)
+	}
+	deleteACL := func(aclName string) {
+		deleteDsl.ACL(aclName)
+		changedACLs = append(changedACLs, aclName)
+	}
 
 	// First render local tables.
+	changedTables := make(map[string]struct{})
 	for _, change := range changes {
+		changedTables[change.Table.ID] = struct{}{}
 		if change.Table.Type == cache.Global {
 			// Reconfigure global table after the local ones.
 			globalTable = change.Table
 			continue
 		}
-		if len(change.PreviousPods) == 0 {
+		if len(change.PreviousPods) == 0 || (rerenderAll && len(change.Table.Pods) != 0) {
 			// New ACL
 			acl := art.renderACL(change.Table)
-			putDsl.ACL(acl)
+			putACL(acl)
 			art.renderer.Log.WithFields(logging.Fields{
 				"table": change.Table,
 				"acl":   acl,
@@ -215,7 +461,7 @@ func (art *RendererTxn) Commit() error {
 			aclPrivCopy := proto.Clone(change.Table.Private.(*vpp_acl.AccessLists_Acl))
 			acl := aclPrivCopy.(*vpp_acl.AccessLists_Acl)
 			acl.Interfaces = art.renderInterfaces(change.Table.Pods, false)
-			putDsl.ACL(acl)
+			putACL(acl)
 			art.renderer.Log.WithFields(logging.Fields{
 				"table":    change.Table,
 				"prevPods": change.PreviousPods,
@@ -224,7 +470,7 @@ func (art *RendererTxn) Commit() error {
 		} else {
 			// Removed ACL
 			acl := change.Table.Private.(*vpp_acl.AccessLists_Acl)
-			deleteDsl.ACL(acl.AclName)
+			deleteACL(acl.AclName)
 			art.renderer.Log.WithFields(logging.Fields{
 				"table": change.Table,
 				"acl":   acl,
@@ -232,7 +478,29 @@ func (art *RendererTxn) Commit() error {
 		}
 	}
 
-	if art.resync && globalTable == nil && art.renderer.cache.GetGlobalTable().NumOfRules != 0 {
+	if rerenderAll {
+		// Re-render local tables not changed by the transaction.
+		for pod := range art.cacheTxn.GetIsolatedPods() {
+			table := art.cacheTxn.GetLocalTableByPod(pod)
+			if table == nil {
+				continue
+			}
+			if _, changed := changedTables[table.ID]; changed {
+				continue
+			}
+			changedTables[table.ID] = struct{}{}
+			acl := art.renderACL(table)
+			putACL(acl)
+			art.renderer.Log.WithFields(logging.Fields{
+				"table":     table,
+				"acl":       acl,
+				"dualStack": art.dualStack,
+			}).Debug("Put re-rendered ACL")
+		}
+	}
+
+	if (art.resync || rerenderAll || hostIsolationChanged) && globalTable == nil &&
+		art.renderer.cache.GetGlobalTable().NumOfRules != 0 {
 		// Even if the content of the global table has not changed, resync the interfaces.
 		globalTable = art.renderer.cache.GetGlobalTable()
 	}
@@ -243,7 +511,7 @@ func (art *RendererTxn) Commit() error {
 		globalACL := art.renderACL(globalTable)
 		if globalTable.NumOfRules == 0 {
 			// Remove empty global table.
-			deleteDsl.ACL(globalACL.AclName)
+			deleteACL(globalACL.AclName)
 			gtAddedOrDeleted = true
 			art.renderer.Log.WithFields(logging.Fields{
 				"table": globalTable,
@@ -251,8 +519,8 @@ func (art *RendererTxn) Commit() error {
 			}).Debug("Removed Global ACL")
 		} else {
 			// Update content of the global table.
-			globalACL.Interfaces.Egress = art.getNodeOutputInterfaces()
-			putDsl.ACL(globalACL)
+			globalACL.Interfaces.Egress = art.getGlobalACLInterfaces()
+			putACL(globalACL)
 			if art.renderer.cache.GetGlobalTable().NumOfRules == 0 {
 				gtAddedOrDeleted = true
 			}
@@ -263,17 +531,57 @@ func (art *RendererTxn) Commit() error {
 		}
 	}
 
+	// Render the host ACLs (they include rules of the global table).
+	hostACLs := art.renderer.hostACLs
+	if art.resync || rerenderAll || art.hostRendered || art.lbRendered || globalTable != nil {
+		hostACLs = art.renderHostACLs()
+		for _, acl := range hostACLs {
+			putACL(acl)
+			art.renderer.Log.WithFields(logging.Fields{
+				"acl": acl,
+			}).Debug("Put Host ACL")
+		}
+		for aclName := range art.renderer.hostACLs {
+			if _, installed := hostACLs[aclName]; !installed {
+				deleteACL(aclName)
+				art.renderer.Log.WithField("acl", aclName).Debug("Removed Host ACL")
+			}
+		}
+	}
+
+	// Render the pre-NAT ACLs.
+	preNATACLs := art.renderer.preNATACLs
+	if art.resync || rerenderAll || preNATChanged {
+		preNATACLs = art.renderPreNATACLs()
+		for aclName, acl := range preNATACLs {
+			if prevACL, installed := art.renderer.preNATACLs[aclName]; installed && proto.Equal(acl, prevACL) {
+				continue
+			}
+			putACL(acl)
+			art.renderer.Log.WithFields(logging.Fields{
+				"acl": acl,
+			}).Debug("Put Pre-NAT ACL")
+		}
+		for aclName := range art.renderer.preNATACLs {
+			if _, installed := preNATACLs[aclName]; !installed {
+				deleteACL(aclName)
+				art.renderer.Log.WithField("acl", aclName).Debug("Removed Pre-NAT ACL")
+			}
+		}
+	}
+
 	// Render the reflective ACL
-	if art.resync || gtAddedOrDeleted ||
+	if art.resync || rerenderAll || gtAddedOrDeleted || hostIsolationChanged || hostACLChanged || preNATChanged ||
 		!art.cacheTxn.GetIsolatedPods().Equals(art.renderer.cache.GetIsolatedPods()) {
-		reflectiveACL := art.reflectiveACL()
+		reflectiveACL = art.reflectiveACL()
 		if len(reflectiveACL.Interfaces.Ingress) == 0 {
 			if hasReflectiveACL {
-				deleteDsl.ACL(reflectiveACL.AclName)
+				deleteACL(reflectiveACL.AclName)
 				art.renderer.Log.Debug("Removed Reflective ACL")
 			}
+			reflectiveACL = nil
 		} else {
-			putDsl.ACL(reflectiveACL)
+			putACL(reflectiveACL)
 			art.renderer.Log.WithFields(logging.Fields{
 				"acl": reflectiveACL,
 			}).Debug("Put Reflective ACL")
@@ -282,13 +590,94 @@ func (art *RendererTxn) Commit() error {
 
 	err = dsl.Send().ReceiveReply()
 	if err != nil {
-		return err
+		// Revert the changes, the cache remains in the state before the transaction.
+		rollbackErr := art.rollback(changedACLs, prevACLs)
+		return &renderer.CommitError{Renderer: "ACL", Err: err, RollbackErr: rollbackErr}
 	}
+	art.renderer.dualStack = art.dualStack
+	art.renderer.reflectiveACL = reflectiveACL
+	art.renderer.hostRules = art.hostRules
+	art.renderer.lbRules = art.lbRules
+	art.renderer.hostACLs = hostACLs
+	art.renderer.preNATRules = art.preNATRules
+	art.renderer.preNATACLs = preNATACLs
 
 	// Save changes into the cache.
 	return art.cacheTxn.Commit()
 }
 
+// installedACLs returns the currently installed ACLs (as recorded in the cache),
+// indexed by ACL names.
+func (art *RendererTxn) installedACLs() map[string]*vpp_acl.AccessLists_Acl {
+	acls := make(map[string]*vpp_acl.AccessLists_Acl)
+	for _, table := range art.renderer.installedTables() {
+		acl, isACL := table.Private.(*vpp_acl.AccessLists_Acl)
+		if !isACL {
+			continue
+		}
+		if table.Type == cache.Local {
+			// Interfaces of the private copy are not updated when only the set
+			// of pods changes.
+			acl = proto.Clone(acl).(*vpp_acl.AccessLists_Acl)
+			acl.Interfaces = art.renderInterfaces(table.Pods, false)
+		}
+		acls[acl.AclName] = acl
+	}
+	if art.renderer.reflectiveACL != nil {
+		acls[art.renderer.reflectiveACL.AclName] = art.renderer.reflectiveACL
+	}
+	for aclName, acl := range art.renderer.hostACLs {
+		acls[aclName] = acl
+	}
+	for aclName, acl := range art.renderer.preNATACLs {
+		acls[aclName] = acl
+	}
+	return acls
+}
+
+// installedTables returns tables from the cache that are installed as ACLs.
+func (r *Renderer) installedTables() []*cache.ContivRuleTable {
+	var tables []*cache.ContivRuleTable
+	if globalTable := r.cache.GetGlobalTable(); globalTable.NumOfRules > 0 {
+		tables = append(tables, globalTable)
+	}
+	for pod := range r.cache.GetIsolatedPods() {
+		if table := r.cache.GetLocalTableByPod(pod); table != nil {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// rollback reverts ACLs changed by a failed transaction into the state recorded
+// in the cache before the transaction.
+func (art *RendererTxn) rollback(changedACLs []string, prevACLs map[string]*vpp_acl.AccessLists_Acl) error {
+	art.renderer.Log.WithField("acls", changedACLs).Warn("Rolling back changes of ACLs")
+	art.restoreTables(prevACLs)
+
+	dsl := art.renderer.ACLTxnFactory()
+	putDsl := dsl.Put()
+	deleteDsl := dsl.Delete()
+	for _, aclName := range changedACLs {
+		if acl, existed := prevACLs[aclName]; existed {
+			putDsl.ACL(acl)
+		} else {
+			deleteDsl.ACL(aclName)
+		}
+	}
+	return dsl.Send().ReceiveReply()
+}
+
+// restoreTables reverts the ACLs of the tables shared with the cache, which
+// may have been re-rendered by the transaction.
+func (art *RendererTxn) restoreTables(prevACLs map[string]*vpp_acl.AccessLists_Acl) {
+	for _, table := range art.renderer.installedTables() {
+		if acl, hasACL := prevACLs[ACLNamePrefix+table.ID]; hasACL {
+			table.Private = acl
+		}
+	}
+}
+
 // reflectiveACL returns the configuration of the reflective ACL.
 func (art *RendererTxn) reflectiveACL() *vpp_acl.AccessLists_Acl {
 	// Prepare table to render the ACL from.
@@ -303,15 +692,73 @@ func (art *RendererTxn) reflectiveACL() *vpp_acl.AccessLists_Acl {
 	table := cache.NewContivRuleTable(ReflectiveACLName)
 	table.Rules = []*renderer.ContivRule{ruleAny}
 	table.NumOfRules = 1
-	table.Pods = art.cacheTxn.GetIsolatedPods()
+	// Pods with pre-NAT rules are excluded, the pre-NAT ACL already reflects
+	// the allowed traffic.
+	table.Pods = cache.NewPodSet()
+	for pod := range art.cacheTxn.GetIsolatedPods() {
+		if _, hasPreNAT := art.preNATRules[pod]; !hasPreNAT {
+			table.Pods.Add(pod)
+		}
+	}
 	// Render the ACL.
 	acl := art.renderACL(table)
-	if art.cacheTxn.GetGlobalTable().NumOfRules > 0 {
-		acl.Interfaces.Ingress = append(acl.Interfaces.Ingress, art.getNodeOutputInterfaces()...)
+	if art.cacheTxn.GetGlobalTable().NumOfRules > 0 || len(art.hostRules) > 0 {
+		// Physical interfaces with the HOST ACL assigned are excluded,
+		// the HOST ACL already reflects the allowed traffic.
+		hostACLIfs := make(map[string]struct{})
+		if art.hasHostACL(art.hostRules, art.lbRules) {
+			for _, ifName := range art.getPhysicalInterfaces() {
+				hostACLIfs[ifName] = struct{}{}
+			}
+		}
+		for _, ifName := range art.getNodeOutputInterfaces() {
+			if _, hasHostACL := hostACLIfs[ifName]; !hasHostACL {
+				acl.Interfaces.Ingress = append(acl.Interfaces.Ingress, ifName)
+			}
+		}
 	}
 	return acl
 }
 
+// renderPreNATACLs renders the pre-NAT rules into ACLs assigned to the ingress
+// of pod interfaces. Pods with the same rules share the ACL, named after
+// the hash of the rules. Responses to the allowed traffic are reflected.
+func (art *RendererTxn) renderPreNATACLs() map[string]*vpp_acl.AccessLists_Acl {
+	acls := make(map[string]*vpp_acl.AccessLists_Acl)
+	tables := make(map[string]*cache.ContivRuleTable)
+	for pod, rules := range art.preNATRules {
+		tableID := preNATTableID(rules)
+		table, hasTable := tables[tableID]
+		if !hasTable {
+			table = cache.NewContivRuleTable(tableID)
+			table.Rules = rules
+			table.NumOfRules = len(rules)
+			tables[tableID] = table
+		}
+		table.Pods.Add(pod)
+	}
+	for _, table := range tables {
+		acl := art.renderACL(table)
+		for _, aclRule := range acl.Rules {
+			if aclRule.AclAction == vpp_acl.AclAction_PERMIT {
+				aclRule.AclAction = vpp_acl.AclAction_REFLECT
+			}
+		}
+		acl.Interfaces = art.renderInterfaces(table.Pods, true)
+		acls[acl.AclName] = acl
+	}
+	return acls
+}
+
+// preNATTableID returns ID of the table with the given pre-NAT rules.
+func preNATTableID(rules []*renderer.ContivRule) string {
+	hash := fnv.New32a()
+	for _, rule := range rules {
+		hash.Write([]byte(rule.String()))
+	}
+	return fmt.Sprintf("%s%08X", PreNATACLNamePrefix, hash.Sum32())
+}
+
 // getNodeOutputInterfaces returns the list of interfaces that connect this K8s node
 // with the outside world.
 func (art *RendererTxn) getNodeOutputInterfaces() []string {
@@ -326,73 +773,349 @@ func (art *RendererTxn) getNodeOutputInterfaces() []string {
 	return interfaces
 }
 
+// getGlobalACLInterfaces returns the list of interfaces to assign the ACL
+// of the global table to. If the host is isolated, the global table is
+// included in the HOST-INTERCONNECT ACL instead of assigning the global ACL
+// to the host interconnect.
+func (art *RendererTxn) getGlobalACLInterfaces() []string {
+	interfaces := art.getNodeOutputInterfaces()
+	if len(art.hostRules) == 0 {
+		return interfaces
+	}
+	hostInterconnect := art.renderer.Contiv.GetHostInterconnectIfName()
+	filtered := []string{}
+	for _, ifName := range interfaces {
+		if ifName != hostInterconnect {
+			filtered = append(filtered, ifName)
+		}
+	}
+	return filtered
+}
+
+// getPhysicalInterfaces returns the list of physical interfaces of this node.
+func (art *RendererTxn) getPhysicalInterfaces() []string {
+	interfaces := []string{}
+	if mainIf := art.renderer.Contiv.GetMainPhysicalIfName(); mainIf != "" {
+		interfaces = append(interfaces, mainIf)
+	}
+	return append(interfaces, art.renderer.Contiv.GetOtherPhysicalIfNames()...)
+}
+
+// getNodeIP returns the IP address of this node as a host network
+// (nil if not known).
+func (art *RendererTxn) getNodeIP() *net.IPNet {
+	nodeIP, _ := art.renderer.Contiv.GetNodeIP()
+	if len(nodeIP) == 0 {
+		return nil
+	}
+	if nodeIP.To4() != nil {
+		return &net.IPNet{IP: nodeIP.To4(), Mask: net.CIDRMask(net.IPv4len*8, net.IPv4len*8)}
+	}
+	return &net.IPNet{IP: nodeIP, Mask: net.CIDRMask(net.IPv6len*8, net.IPv6len*8)}
+}
+
+// hasHostACL returns true if the HOST ACL is installed for the given host
+// and load-balancer rules.
+func (art *RendererTxn) hasHostACL(hostRules, lbRules []*renderer.ContivRule) bool {
+	if len(art.getPhysicalInterfaces()) == 0 {
+		return false
+	}
+	return len(lbRules) > 0 || (len(hostRules) > 0 && art.getNodeIP() != nil)
+}
+
+// renderHostACLs renders the host rules into HOST-INTERCONNECT and HOST ACLs
+// and the load-balancer rules into the HOST ACL.
+// Returns empty map if the host is not isolated and no load-balancer
+// is restricted.
+func (art *RendererTxn) renderHostACLs() map[string]*vpp_acl.AccessLists_Acl {
+	acls := make(map[string]*vpp_acl.AccessLists_Acl)
+	if len(art.hostRules) > 0 {
+		acl := art.renderHostInterconnectACL()
+		acls[acl.AclName] = acl
+	}
+	if art.hasHostACL(art.hostRules, art.lbRules) {
+		acl := art.renderHostACL()
+		acls[acl.AclName] = acl
+	}
+	return acls
+}
+
+// renderHostInterconnectACL renders the host rules into the HOST-INTERCONNECT
+// ACL.
+func (art *RendererTxn) renderHostInterconnectACL() *vpp_acl.AccessLists_Acl {
+	// Traffic from the local pods is subject to the global
+	// table only (egress of pods is already filtered by the local tables),
+	// traffic from other sources is subject to the host rules.
+	table := cache.NewContivRuleTable(HostInterconnectACLName)
+	globalTable := art.cacheTxn.GetGlobalTable()
+	for i := 0; i < globalTable.NumOfRules; i++ {
+		if rule := globalTable.Rules[i]; len(rule.SrcNetwork.IP) > 0 {
+			table.Rules = append(table.Rules, rule)
+		}
+	}
+	if podNetwork := art.renderer.Contiv.GetPodNetwork(); podNetwork != nil {
+		table.Rules = append(table.Rules, &renderer.ContivRule{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  podNetwork,
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.ANY,
+		})
+	}
+	table.Rules = append(table.Rules, art.hostRules...)
+	table.NumOfRules = len(table.Rules)
+	acl := art.renderACL(table)
+	acl.Interfaces = &vpp_acl.AccessLists_Acl_Interfaces{
+		Egress: []string{art.renderer.Contiv.GetHostInterconnectIfName()},
+	}
+	return acl
+}
+
+// renderHostACL renders the load-balancer rules and the host rules into
+// the HOST ACL.
+func (art *RendererTxn) renderHostACL() *vpp_acl.AccessLists_Acl {
+	// Traffic destined to the load-balancer IPs is permitted only from
+	// the source ranges of the restricted load-balancers.
+	table := cache.NewContivRuleTable(HostACLName)
+	table.Rules = append(table.Rules, art.lbRules...)
+
+	// Traffic destined to the node IP is subject to the host rules as well,
+	// with the traffic denied for all protocols narrowed down to the node ports.
+	// The rest of the traffic to the host is filtered on the host interconnect.
+	if nodeIP := art.getNodeIP(); nodeIP != nil {
+		table.Rules = append(table.Rules, art.nodeIPHostRules(nodeIP)...)
+	}
+
+	table.Rules = append(table.Rules, &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: &net.IPNet{},
+		Protocol:    renderer.ANY,
+	})
+	table.NumOfRules = len(table.Rules)
+	acl := art.renderACL(table)
+	for _, aclRule := range acl.Rules {
+		// Responses to the allowed traffic must pass the physical interfaces.
+		if aclRule.AclAction == vpp_acl.AclAction_PERMIT {
+			aclRule.AclAction = vpp_acl.AclAction_REFLECT
+		}
+	}
+	acl.Interfaces = &vpp_acl.AccessLists_Acl_Interfaces{
+		Ingress: art.getPhysicalInterfaces(),
+	}
+	return acl
+}
+
+// nodeIPHostRules returns the host rules applied to the traffic destined
+// to the given node IP.
+func (art *RendererTxn) nodeIPHostRules(nodeIP *net.IPNet) (rules []*renderer.ContivRule) {
+	firstPort, lastPort, err := parseNodePortRange(art.getNodePortRange())
+	if err != nil {
+		art.Log.WithField("err", err).Warn("Invalid node port range, using the default")
+		firstPort, lastPort, _ = parseNodePortRange(DefaultNodePortRange)
+	}
+	for _, hostRule := range art.hostRules {
+		if len(hostRule.SrcNetwork.IP) > 0 && utils.IsIPv6Net(hostRule.SrcNetwork) != utils.IsIPv6Net(nodeIP) {
+			continue
+		}
+		if hostRule.Action == renderer.ActionDeny && hostRule.Protocol == renderer.ANY {
+			for _, protocol := range []renderer.ProtocolType{renderer.TCP, renderer.UDP} {
+				rule := hostRule.Copy()
+				rule.DestNetwork = nodeIP
+				rule.Protocol = protocol
+				rule.DestPort = firstPort
+				rule.DestPortEnd = lastPort
+				rules = append(rules, rule)
+			}
+			continue
+		}
+		rule := hostRule.Copy()
+		rule.DestNetwork = nodeIP
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// getNodePortRange returns the configured range of node ports.
+func (art *RendererTxn) getNodePortRange() string {
+	if config := art.renderer.Contiv.GetHostEndpointPolicyConfig(); config != nil && config.NodePortRange != "" {
+		return config.NodePortRange
+	}
+	return DefaultNodePortRange
+}
+
+// parseNodePortRange parses port range in the format "<first>-<last>".
+func parseNodePortRange(portRange string) (first, last uint16, err error) {
+	bounds := strings.Split(strings.TrimSpace(portRange), "-")
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("invalid port range %s", portRange)
+	}
+	firstPort, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 16)
+	if err != nil || firstPort == 0 {
+		return 0, 0, fmt.Errorf("invalid first port in port range %s", portRange)
+	}
+	lastPort, err := strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 16)
+	if err != nil || lastPort < firstPort {
+		return 0, 0, fmt.Errorf("invalid last port in port range %s", portRange)
+	}
+	return uint16(firstPort), uint16(lastPort), nil
+}
+
 // renderACL renders ContivRuleTable into the equivalent ACL configuration.
 func (art *RendererTxn) renderACL(table *cache.ContivRuleTable) *vpp_acl.AccessLists_Acl {
-	const maxPortNum = ^uint16(0)
 	acl := &vpp_acl.AccessLists_Acl{}
 	acl.AclName = ACLNamePrefix + table.ID
 	acl.Interfaces = art.renderInterfaces(table.Pods, table.ID == ReflectiveACLName)
 
 	for i := 0; i < table.NumOfRules; i++ {
 		rule := table.Rules[i]
-		aclRule := &vpp_acl.AccessLists_Acl_Rule{}
-		if rule.Action == renderer.ActionDeny {
-			aclRule.AclAction = vpp_acl.AclAction_DENY
-		} else if table.ID == ReflectiveACLName {
-			aclRule.AclAction = vpp_acl.AclAction_REFLECT
-		} else {
-			aclRule.AclAction = vpp_acl.AclAction_PERMIT
-		}
-		aclRule.Match = &vpp_acl.AccessLists_Acl_Rule_Match{}
-		aclRule.Match.IpRule = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule{}
-		aclRule.Match.IpRule.Ip = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Ip{}
-		if len(rule.SrcNetwork.IP) > 0 {
-			aclRule.Match.IpRule.Ip.SourceNetwork = rule.SrcNetwork.String()
-		}
-		if len(rule.DestNetwork.IP) > 0 {
-			aclRule.Match.IpRule.Ip.DestinationNetwork = rule.DestNetwork.String()
-		}
-		if rule.Protocol == renderer.TCP {
-			aclRule.Match.IpRule.Tcp = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Tcp{}
-			aclRule.Match.IpRule.Tcp.SourcePortRange = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_PortRange{}
-			aclRule.Match.IpRule.Tcp.SourcePortRange.LowerPort = uint32(rule.SrcPort)
-			if rule.SrcPort == 0 {
-				aclRule.Match.IpRule.Tcp.SourcePortRange.UpperPort = uint32(maxPortNum)
-			} else {
-				aclRule.Match.IpRule.Tcp.SourcePortRange.UpperPort = uint32(rule.SrcPort)
-			}
-			aclRule.Match.IpRule.Tcp.DestinationPortRange = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_PortRange{}
-			aclRule.Match.IpRule.Tcp.DestinationPortRange.LowerPort = uint32(rule.DestPort)
-			if rule.DestPort == 0 {
-				aclRule.Match.IpRule.Tcp.DestinationPortRange.UpperPort = uint32(maxPortNum)
-			} else {
-				aclRule.Match.IpRule.Tcp.DestinationPortRange.UpperPort = uint32(rule.DestPort)
-			}
+		if rule.Protocol == renderer.SCTP && rule.DestPort != 0 && rule.Action == renderer.ActionDeny {
+			// SCTP is matched only by the protocol number (see renderACLRule),
+			// denying all SCTP traffic for selected ports would be too restrictive.
+			continue
 		}
-		if rule.Protocol == renderer.UDP {
-			aclRule.Match.IpRule.Udp = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Udp{}
-			aclRule.Match.IpRule.Udp.SourcePortRange = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_PortRange{}
-			aclRule.Match.IpRule.Udp.SourcePortRange.LowerPort = uint32(rule.SrcPort)
-			if rule.SrcPort == 0 {
-				aclRule.Match.IpRule.Udp.SourcePortRange.UpperPort = uint32(maxPortNum)
-			} else {
-				aclRule.Match.IpRule.Udp.SourcePortRange.UpperPort = uint32(rule.SrcPort)
-			}
-			aclRule.Match.IpRule.Udp.DestinationPortRange = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_PortRange{}
-			aclRule.Match.IpRule.Udp.DestinationPortRange.LowerPort = uint32(rule.DestPort)
-			if rule.DestPort == 0 {
-				aclRule.Match.IpRule.Udp.DestinationPortRange.UpperPort = uint32(maxPortNum)
-			} else {
-				aclRule.Match.IpRule.Udp.DestinationPortRange.UpperPort = uint32(rule.DestPort)
+		aclRule := renderACLRule(rule, table.ID == ReflectiveACLName)
+		acl.Rules = append(acl.Rules, aclRule)
+
+		// ACL rule with both networks unspecified matches only IPv4 traffic.
+		// In dual-stack the rule is therefore duplicated for IPv6.
+		// ICMP types and codes differ between IPv4 and IPv6, only the rules
+		// matching any ICMP message are duplicated.
+		if art.dualStack && len(rule.SrcNetwork.IP) == 0 && len(rule.DestNetwork.IP) == 0 &&
+			(rule.Protocol != renderer.ICMP || (rule.ICMPType == renderer.AnyICMP && rule.ICMPCode == renderer.AnyICMP)) {
+			aclRuleIPv6 := renderACLRule(rule, table.ID == ReflectiveACLName)
+			aclRuleIPv6.Match.IpRule.Ip.SourceNetwork = ipv6AddrAny
+			if aclRuleIPv6.Match.IpRule.Icmp != nil {
+				aclRuleIPv6.Match.IpRule.Icmp.Icmpv6 = true
 			}
+			acl.Rules = append(acl.Rules, aclRuleIPv6)
 		}
-		acl.Rules = append(acl.Rules, aclRule)
 	}
 
 	table.Private = acl
 	return acl
 }
 
+// renderACLRule renders Contiv rule into the equivalent ACL rule.
+func renderACLRule(rule *renderer.ContivRule, reflective bool) *vpp_acl.AccessLists_Acl_Rule {
+	const maxPortNum = ^uint16(0)
+	aclRule := &vpp_acl.AccessLists_Acl_Rule{}
+	if rule.Action == renderer.ActionDeny {
+		aclRule.AclAction = vpp_acl.AclAction_DENY
+	} else if reflective {
+		aclRule.AclAction = vpp_acl.AclAction_REFLECT
+	} else {
+		aclRule.AclAction = vpp_acl.AclAction_PERMIT
+	}
+	aclRule.Match = &vpp_acl.AccessLists_Acl_Rule_Match{}
+	aclRule.Match.IpRule = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule{}
+	aclRule.Match.IpRule.Ip = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Ip{}
+	if len(rule.SrcNetwork.IP) > 0 {
+		aclRule.Match.IpRule.Ip.SourceNetwork = rule.SrcNetwork.String()
+	}
+	if len(rule.DestNetwork.IP) > 0 {
+		aclRule.Match.IpRule.Ip.DestinationNetwork = rule.DestNetwork.String()
+	}
+	if rule.Protocol == renderer.TCP {
+		aclRule.Match.IpRule.Tcp = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Tcp{}
+		aclRule.Match.IpRule.Tcp.SourcePortRange = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_PortRange{}
+		aclRule.Match.IpRule.Tcp.SourcePortRange.LowerPort = uint32(rule.SrcPort)
+		if rule.SrcPort == 0 {
+			aclRule.Match.IpRule.Tcp.SourcePortRange.UpperPort = uint32(maxPortNum)
+		} else {
+			aclRule.Match.IpRule.Tcp.SourcePortRange.UpperPort = uint32(rule.SrcPort)
+		}
+		aclRule.Match.IpRule.Tcp.DestinationPortRange = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_PortRange{}
+		aclRule.Match.IpRule.Tcp.DestinationPortRange.LowerPort = uint32(rule.DestPort)
+		if rule.DestPort == 0 {
+			aclRule.Match.IpRule.Tcp.DestinationPortRange.UpperPort = uint32(maxPortNum)
+		} else if rule.DestPortEnd > rule.DestPort {
+			aclRule.Match.IpRule.Tcp.DestinationPortRange.UpperPort = uint32(rule.DestPortEnd)
+		} else {
+			aclRule.Match.IpRule.Tcp.DestinationPortRange.UpperPort = uint32(rule.DestPort)
+		}
+	}
+	if rule.Protocol == renderer.UDP {
+		aclRule.Match.IpRule.Udp = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Udp{}
+		aclRule.Match.IpRule.Udp.SourcePortRange = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_PortRange{}
+		aclRule.Match.IpRule.Udp.SourcePortRange.LowerPort = uint32(rule.SrcPort)
+		if rule.SrcPort == 0 {
+			aclRule.Match.IpRule.Udp.SourcePortRange.UpperPort = uint32(maxPortNum)
+		} else {
+			aclRule.Match.IpRule.Udp.SourcePortRange.UpperPort = uint32(rule.SrcPort)
+		}
+		aclRule.Match.IpRule.Udp.DestinationPortRange = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_PortRange{}
+		aclRule.Match.IpRule.Udp.DestinationPortRange.LowerPort = uint32(rule.DestPort)
+		if rule.DestPort == 0 {
+			aclRule.Match.IpRule.Udp.DestinationPortRange.UpperPort = uint32(maxPortNum)
+		} else if rule.DestPortEnd > rule.DestPort {
+			aclRule.Match.IpRule.Udp.DestinationPortRange.UpperPort = uint32(rule.DestPortEnd)
+		} else {
+			aclRule.Match.IpRule.Udp.DestinationPortRange.UpperPort = uint32(rule.DestPort)
+		}
+	}
+	if rule.Protocol == renderer.SCTP {
+		// VPP ACLs match ports only for TCP and UDP, SCTP rules therefore
+		// match all the SCTP traffic of the networks.
+		aclRule.Match.IpRule.Ip.Protocol = sctpProtocol
+	}
+	if rule.Protocol == renderer.ICMP {
+		aclRule.Match.IpRule.Icmp = &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Icmp{}
+		aclRule.Match.IpRule.Icmp.IcmpTypeRange = renderICMPRange(rule.ICMPType)
+		aclRule.Match.IpRule.Icmp.IcmpCodeRange = renderICMPRange(rule.ICMPCode)
+		aclRule.Match.IpRule.Icmp.Icmpv6 = utils.IsIPv6Net(rule.SrcNetwork) || utils.IsIPv6Net(rule.DestNetwork)
+	}
+	return aclRule
+}
+
+// renderICMPRange renders ICMP type or code into the equivalent ACL range.
+func renderICMPRange(value int16) *vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Icmp_Range {
+	if value == renderer.AnyICMP {
+		return &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Icmp_Range{First: 0, Last: maxICMPValue}
+	}
+	return &vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Icmp_Range{First: uint32(value), Last: uint32(value)}
+}
+
+// importICMPRange converts ACL range of ICMP types or codes into the ContivRule
+// representation. Returns false if the range cannot be represented.
+func importICMPRange(icmpRange *vpp_acl.AccessLists_Acl_Rule_Match_IpRule_Icmp_Range) (int16, bool) {
+	if icmpRange == nil || (icmpRange.First == 0 && icmpRange.Last >= maxICMPValue) {
+		return renderer.AnyICMP, true
+	}
+	if icmpRange.First != icmpRange.Last || icmpRange.First > maxICMPValue {
+		return 0, false
+	}
+	return int16(icmpRange.First), true
+}
+
+// hasIPv6Address returns true if at least one of the given addresses is IPv6.
+func hasIPv6Address(addrs []*net.IPNet) bool {
+	for _, addr := range addrs {
+		if utils.IsIPv6Net(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasIPv6Rules returns true if the given ACLs were rendered for dual-stack,
+// i.e. if they contain IPv6 duplicates of rules with unspecified networks.
+func hasIPv6Rules(acls []*vpp_acl.AccessLists_Acl) bool {
+	for _, acl := range acls {
+		if !strings.HasPrefix(acl.AclName, ACLNamePrefix) {
+			continue
+		}
+		for _, aclRule := range acl.Rules {
+			if aclRule.Match == nil || aclRule.Match.IpRule == nil || aclRule.Match.IpRule.Ip == nil {
+				continue
+			}
+			if aclRule.Match.IpRule.Ip.SourceNetwork == ipv6AddrAny {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // renderInterfaces renders a set of Interface names into the corresponding
 // instance of AccessLists_Acl_Interfaces.
 func (art *RendererTxn) renderInterfaces(pods cache.PodSet, ingress bool) *vpp_acl.AccessLists_Acl_Interfaces {
@@ -420,7 +1143,6 @@ func (art *RendererTxn) renderInterfaces(pods cache.PodSet, ingress bool) *vpp_a
 // dumpVppACLConfig dumps current ACL config in the format suitable for the resync
 // of the cache.
 func (art *RendererTxn) dumpVppACLConfig() (acls []*vpp_acl.AccessLists_Acl, tables []*cache.ContivRuleTable, hasReflectiveACL bool, err error) {
-	const maxPortNum = uint32(^uint16(0))
 	tables = []*cache.ContivRuleTable{}
 
 	aclDump, err := art.vpp.DumpIPACL()
@@ -441,6 +1163,16 @@ func (art *RendererTxn) dumpVppACLConfig() (acls []*vpp_acl.AccessLists_Acl, tab
 			continue
 		}
 
+		// Skip the Host ACLs (re-rendered from the host rules).
+		if aclName == HostInterconnectACLName || aclName == HostACLName {
+			continue
+		}
+
+		// Skip the Pre-NAT ACLs (re-rendered from the pre-NAT rules).
+		if strings.HasPrefix(aclName, PreNATACLNamePrefix) {
+			continue
+		}
+
 		// Local / Global table
 		table := cache.NewContivRuleTable(aclName)
 
@@ -473,108 +1205,9 @@ func (art *RendererTxn) dumpVppACLConfig() (acls []*vpp_acl.AccessLists_Acl, tab
 
 		// Rules
 		for _, aclRule := range acl.Rules {
-			rule := &renderer.ContivRule{}
-			// Rule Action
-			switch aclRule.AclAction {
-			case vpp_acl.AclAction_PERMIT:
-				rule.Action = renderer.ActionPermit
-			case vpp_acl.AclAction_DENY:
-				rule.Action = renderer.ActionDeny
-			default:
-				art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with unhandled action 'REFLECT'")
-				continue
-			}
-			// Rule IPs
-			if aclRule.Match == nil {
-				// invalid, skip
-				art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule without 'Matches'")
-				continue
-			}
-			if aclRule.Match.IpRule == nil {
-				// unhandled, skip
-				art.Log.WithField("rule", aclRule).Warn("Skipping ACL MAC-IP rule")
-				continue
-			}
-			rule.SrcNetwork = &net.IPNet{}
-			rule.DestNetwork = &net.IPNet{}
-			if aclRule.Match.IpRule.Ip != nil {
-				if aclRule.Match.IpRule.Ip.SourceNetwork != "" &&
-					aclRule.Match.IpRule.Ip.SourceNetwork != ipv4AddrAny &&
-					aclRule.Match.IpRule.Ip.SourceNetwork != ipv6AddrAny {
-					_, rule.SrcNetwork, err = net.ParseCIDR(aclRule.Match.IpRule.Ip.SourceNetwork)
-					if err != nil {
-						art.Log.WithField("err", err).Warn("Failed to parse source IP address")
-						continue
-					}
-				}
-				if aclRule.Match.IpRule.Ip.DestinationNetwork != "" &&
-					aclRule.Match.IpRule.Ip.DestinationNetwork != ipv4AddrAny &&
-					aclRule.Match.IpRule.Ip.DestinationNetwork != ipv6AddrAny {
-					_, rule.DestNetwork, err = net.ParseCIDR(aclRule.Match.IpRule.Ip.DestinationNetwork)
-					if err != nil {
-						art.Log.WithField("err", err).Warn("Failed to parse destination IP address")
-						continue
-					}
-				}
-			}
-			// L4
-			rule.Protocol = renderer.ANY
-			if aclRule.Match.IpRule.Icmp != nil {
-				// skip ICMP rule
-				continue
+			if rule, ok := art.renderer.importACLRule(aclRule); ok {
+				table.InsertRule(rule)
 			}
-			if aclRule.Match.IpRule.Tcp != nil {
-				rule.Protocol = renderer.TCP
-				if aclRule.Match.IpRule.Tcp.SourcePortRange != nil {
-					if aclRule.Match.IpRule.Tcp.SourcePortRange.LowerPort != aclRule.Match.IpRule.Tcp.SourcePortRange.UpperPort {
-						if aclRule.Match.IpRule.Tcp.SourcePortRange.LowerPort != 0 ||
-							aclRule.Match.IpRule.Tcp.SourcePortRange.UpperPort != maxPortNum {
-							// unhandled, skip
-							art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with TCP port range")
-							continue
-						}
-					}
-					rule.SrcPort = uint16(aclRule.Match.IpRule.Tcp.SourcePortRange.LowerPort)
-				}
-				if aclRule.Match.IpRule.Tcp.DestinationPortRange != nil {
-					if aclRule.Match.IpRule.Tcp.DestinationPortRange.LowerPort != aclRule.Match.IpRule.Tcp.DestinationPortRange.UpperPort {
-						if aclRule.Match.IpRule.Tcp.DestinationPortRange.LowerPort != 0 ||
-							aclRule.Match.IpRule.Tcp.DestinationPortRange.UpperPort != maxPortNum {
-							// unhandled, skip
-							art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with TCP port range")
-							continue
-						}
-					}
-					rule.DestPort = uint16(aclRule.Match.IpRule.Tcp.DestinationPortRange.LowerPort)
-				}
-			}
-			if aclRule.Match.IpRule.Udp != nil {
-				rule.Protocol = renderer.UDP
-				if aclRule.Match.IpRule.Udp.SourcePortRange != nil {
-					if aclRule.Match.IpRule.Udp.SourcePortRange.LowerPort != aclRule.Match.IpRule.Udp.SourcePortRange.UpperPort {
-						if aclRule.Match.IpRule.Udp.SourcePortRange.LowerPort != 0 ||
-							aclRule.Match.IpRule.Udp.SourcePortRange.UpperPort != maxPortNum {
-							// unhandled, skip
-							art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with UDP port range")
-							continue
-						}
-					}
-					rule.SrcPort = uint16(aclRule.Match.IpRule.Udp.SourcePortRange.LowerPort)
-				}
-				if aclRule.Match.IpRule.Udp.DestinationPortRange != nil {
-					if aclRule.Match.IpRule.Udp.DestinationPortRange.LowerPort != aclRule.Match.IpRule.Udp.DestinationPortRange.UpperPort {
-						if aclRule.Match.IpRule.Udp.DestinationPortRange.LowerPort != 0 ||
-							aclRule.Match.IpRule.Udp.DestinationPortRange.UpperPort != maxPortNum {
-							// unhandled, skip
-							art.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with UDP port range")
-							continue
-						}
-					}
-					rule.DestPort = uint16(aclRule.Match.IpRule.Udp.DestinationPortRange.LowerPort)
-				}
-			}
-			// Add rule to the list.
-			table.InsertRule(rule)
 		}
 
 		// Private
@@ -586,3 +1219,119 @@ func (art *RendererTxn) dumpVppACLConfig() (acls []*vpp_acl.AccessLists_Acl, tab
 
 	return aclDump, tables, hasReflectiveACL, nil
 }
+
+// importACLRule converts ACL rule into the equivalent Contiv rule.
+// Returns false if the rule cannot be represented as Contiv rule.
+func (r *Renderer) importACLRule(aclRule *vpp_acl.AccessLists_Acl_Rule) (rule *renderer.ContivRule, ok bool) {
+	const maxPortNum = uint32(^uint16(0))
+	var err error
+
+	rule = &renderer.ContivRule{}
+	// Rule Action
+	switch aclRule.AclAction {
+	case vpp_acl.AclAction_PERMIT:
+		rule.Action = renderer.ActionPermit
+	case vpp_acl.AclAction_DENY:
+		rule.Action = renderer.ActionDeny
+	default:
+		r.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with unhandled action 'REFLECT'")
+		return nil, false
+	}
+	// Rule IPs
+	if aclRule.Match == nil {
+		// invalid, skip
+		r.Log.WithField("rule", aclRule).Warn("Skipping ACL rule without 'Matches'")
+		return nil, false
+	}
+	if aclRule.Match.IpRule == nil {
+		// unhandled, skip
+		r.Log.WithField("rule", aclRule).Warn("Skipping ACL MAC-IP rule")
+		return nil, false
+	}
+	rule.SrcNetwork = &net.IPNet{}
+	rule.DestNetwork = &net.IPNet{}
+	if aclRule.Match.IpRule.Ip != nil {
+		if aclRule.Match.IpRule.Ip.SourceNetwork != "" &&
+			aclRule.Match.IpRule.Ip.SourceNetwork != ipv4AddrAny &&
+			aclRule.Match.IpRule.Ip.SourceNetwork != ipv6AddrAny {
+			_, rule.SrcNetwork, err = net.ParseCIDR(aclRule.Match.IpRule.Ip.SourceNetwork)
+			if err != nil {
+				r.Log.WithField("err", err).Warn("Failed to parse source IP address")
+				return nil, false
+			}
+		}
+		if aclRule.Match.IpRule.Ip.DestinationNetwork != "" &&
+			aclRule.Match.IpRule.Ip.DestinationNetwork != ipv4AddrAny &&
+			aclRule.Match.IpRule.Ip.DestinationNetwork != ipv6AddrAny {
+			_, rule.DestNetwork, err = net.ParseCIDR(aclRule.Match.IpRule.Ip.DestinationNetwork)
+			if err != nil {
+				r.Log.WithField("err", err).Warn("Failed to parse destination IP address")
+				return nil, false
+			}
+		}
+	}
+	// L4
+	rule.Protocol = renderer.ANY
+	if protocol := aclRule.Match.IpRule.Ip.GetProtocol(); protocol != 0 {
+		if protocol != sctpProtocol {
+			// unhandled, skip
+			r.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with unhandled IP protocol")
+			return nil, false
+		}
+		rule.Protocol = renderer.SCTP
+	}
+	if aclRule.Match.IpRule.Icmp != nil {
+		var typeOk, codeOk bool
+		rule.Protocol = renderer.ICMP
+		rule.ICMPType, typeOk = importICMPRange(aclRule.Match.IpRule.Icmp.IcmpTypeRange)
+		rule.ICMPCode, codeOk = importICMPRange(aclRule.Match.IpRule.Icmp.IcmpCodeRange)
+		if !typeOk || !codeOk {
+			// unhandled, skip
+			r.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with unhandled ICMP match")
+			return nil, false
+		}
+	}
+	if aclRule.Match.IpRule.Tcp != nil {
+		rule.Protocol = renderer.TCP
+		if aclRule.Match.IpRule.Tcp.SourcePortRange != nil {
+			if aclRule.Match.IpRule.Tcp.SourcePortRange.LowerPort != aclRule.Match.IpRule.Tcp.SourcePortRange.UpperPort {
+				if aclRule.Match.IpRule.Tcp.SourcePortRange.LowerPort != 0 ||
+					aclRule.Match.IpRule.Tcp.SourcePortRange.UpperPort != maxPortNum {
+					// unhandled, skip
+					r.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with TCP port range")
+					return nil, false
+				}
+			}
+			rule.SrcPort = uint16(aclRule.Match.IpRule.Tcp.SourcePortRange.LowerPort)
+		}
+		if aclRule.Match.IpRule.Tcp.DestinationPortRange != nil {
+			rule.DestPort = uint16(aclRule.Match.IpRule.Tcp.DestinationPortRange.LowerPort)
+			if rule.DestPort != 0 &&
+				aclRule.Match.IpRule.Tcp.DestinationPortRange.UpperPort > aclRule.Match.IpRule.Tcp.DestinationPortRange.LowerPort {
+				rule.DestPortEnd = uint16(aclRule.Match.IpRule.Tcp.DestinationPortRange.UpperPort)
+			}
+		}
+	}
+	if aclRule.Match.IpRule.Udp != nil {
+		rule.Protocol = renderer.UDP
+		if aclRule.Match.IpRule.Udp.SourcePortRange != nil {
+			if aclRule.Match.IpRule.Udp.SourcePortRange.LowerPort != aclRule.Match.IpRule.Udp.SourcePortRange.UpperPort {
+				if aclRule.Match.IpRule.Udp.SourcePortRange.LowerPort != 0 ||
+					aclRule.Match.IpRule.Udp.SourcePortRange.UpperPort != maxPortNum {
+					// unhandled, skip
+					r.Log.WithField("rule", aclRule).Warn("Skipping ACL rule with UDP port range")
+					return nil, false
+				}
+			}
+			rule.SrcPort = uint16(aclRule.Match.IpRule.Udp.SourcePortRange.LowerPort)
+		}
+		if aclRule.Match.IpRule.Udp.DestinationPortRange != nil {
+			rule.DestPort = uint16(aclRule.Match.IpRule.Udp.DestinationPortRange.LowerPort)
+			if rule.DestPort != 0 &&
+				aclRule.Match.IpRule.Udp.DestinationPortRange.UpperPort > aclRule.Match.IpRule.Udp.DestinationPortRange.LowerPort {
+				rule.DestPortEnd = uint16(aclRule.Match.IpRule.Udp.DestinationPortRange.UpperPort)
+			}
+		}
+	}
+	return rule, true
+}
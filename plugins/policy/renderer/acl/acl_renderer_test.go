@@ -17,11 +17,20 @@
 package acl
 
 import (
+	"fmt"
 	"github.com/onsi/gomega"
+	"net"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"git.fd.io/govpp.git/adapter/mock"
+	"git.fd.io/govpp.git/codec"
+	govpp "git.fd.io/govpp.git/core"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/logging/logrus"
+	"github.com/ligato/vpp-agent/plugins/vpp/binapi/vpe"
 	vpp_acl "github.com/ligato/vpp-agent/plugins/vpp/model/acl"
 
 	. "github.com/contiv/vpp/mock/aclengine"
@@ -29,6 +38,7 @@ import (
 	"github.com/contiv/vpp/mock/localclient"
 	. "github.com/contiv/vpp/mock/pluginvpp"
 	"github.com/contiv/vpp/plugins/contiv"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	"github.com/contiv/vpp/plugins/policy/renderer"
 	"github.com/contiv/vpp/plugins/policy/renderer/cache"
 	. "github.com/contiv/vpp/plugins/policy/renderer/testdata"
@@ -148,7 +158,7 @@ func TestEgressRulesOnePod(t *testing.T) {
 	aclRenderer.Init()
 
 	// Execute Renderer transaction.
-	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress, false).Commit()
+	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
 
@@ -173,7 +183,7 @@ func TestEgressRulesOnePod(t *testing.T) {
 	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.OTHER, 0, 0)).To(gomega.Equal(ConnActionDenySyn))
 
 	// Try to execute the same change again.
-	err = aclRenderer.NewTxn(false).Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress, false).Commit()
+	err = aclRenderer.NewTxn(false).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 
 	// Verify that the change had no further effect.
@@ -223,7 +233,7 @@ func TestIngressRulesOnePod(t *testing.T) {
 	aclRenderer.Init()
 
 	// Execute Renderer transaction.
-	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress, false).Commit()
+	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
 
@@ -252,7 +262,7 @@ func TestIngressRulesOnePod(t *testing.T) {
 	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS, Pod1, renderer.OTHER, 0, 0)).To(gomega.Equal(ConnActionAllow))
 
 	// Try to execute the same change again.
-	err = aclRenderer.NewTxn(false).Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress, false).Commit()
+	err = aclRenderer.NewTxn(false).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 
 	// Verify that the change had no further effect.
@@ -305,8 +315,8 @@ func TestEgressRulesTwoPods(t *testing.T) {
 
 	// Execute Renderer transaction.
 	txn := aclRenderer.NewTxn(true)
-	txn.Render(Pod1, GetOneHostSubnet(Pod1IP), ingress, egress, false)
-	txn.Render(Pod2, GetOneHostSubnet(Pod2IP), ingress, egress, false)
+	txn.Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false)
+	txn.Render(Pod2, GetOneHostSubnets(Pod2IP), ingress, egress, false)
 	err := txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
@@ -349,7 +359,7 @@ func TestEgressRulesTwoPods(t *testing.T) {
 	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod2, renderer.UDP, somePort, 53)).To(gomega.Equal(ConnActionDenySyn))
 
 	// Remove pod2 - pod1 should still have the same local table.
-	err = aclRenderer.NewTxn(false).Render(Pod2, GetOneHostSubnet(Pod2IP), []*renderer.ContivRule{}, []*renderer.ContivRule{}, true).Commit()
+	err = aclRenderer.NewTxn(false).Render(Pod2, GetOneHostSubnets(Pod2IP), []*renderer.ContivRule{}, []*renderer.ContivRule{}, true).Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(2))
 
@@ -369,17 +379,17 @@ func TestCombinedRules(t *testing.T) {
 
 	// Prepare test data
 	pod1Txn1Cfg := &cache.PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress[1:],
 		Egress:  Ts7.Pod1Egress[:2],
 	}
 	pod1Txn2Cfg := &cache.PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress,
 		Egress:  Ts7.Pod1Egress,
 	}
 	pod3Cfg := &cache.PodConfig{
-		PodIP:   GetOneHostSubnet(Pod3IP),
+		PodIPs:  GetOneHostSubnets(Pod3IP),
 		Ingress: Ts7.Pod3Ingress,
 		Egress:  Ts7.Pod3Egress,
 	}
@@ -419,8 +429,8 @@ func TestCombinedRules(t *testing.T) {
 
 	// Execute first Renderer transaction.
 	txn := aclRenderer.NewTxn(true)
-	txn.Render(Pod1, pod1Txn1Cfg.PodIP, pod1Txn1Cfg.Ingress, pod1Txn1Cfg.Egress, false)
-	txn.Render(Pod3, pod3Cfg.PodIP, pod3Cfg.Ingress, pod3Cfg.Egress, false)
+	txn.Render(Pod1, pod1Txn1Cfg.PodIPs, pod1Txn1Cfg.Ingress, pod1Txn1Cfg.Egress, false)
+	txn.Render(Pod3, pod3Cfg.PodIPs, pod3Cfg.Ingress, pod3Cfg.Egress, false)
 	err := txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
@@ -470,7 +480,7 @@ func TestCombinedRules(t *testing.T) {
 
 	// Execute second Renderer transaction (change pod1 config).
 	txn = aclRenderer.NewTxn(false)
-	txn.Render(Pod1, pod1Txn2Cfg.PodIP, pod1Txn2Cfg.Ingress, pod1Txn2Cfg.Egress, false)
+	txn.Render(Pod1, pod1Txn2Cfg.PodIPs, pod1Txn2Cfg.Ingress, pod1Txn2Cfg.Egress, false)
 	err = txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(2))
@@ -527,17 +537,17 @@ func TestCombinedRulesWithResync(t *testing.T) {
 
 	// Prepare test data
 	pod1Txn1Cfg := &cache.PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress[1:],
 		Egress:  Ts7.Pod1Egress[:2],
 	}
 	pod1Txn2Cfg := &cache.PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress,
 		Egress:  Ts7.Pod1Egress,
 	}
 	pod3Cfg := &cache.PodConfig{
-		PodIP:   GetOneHostSubnet(Pod3IP),
+		PodIPs:  GetOneHostSubnets(Pod3IP),
 		Ingress: Ts7.Pod3Ingress,
 		Egress:  Ts7.Pod3Egress,
 	}
@@ -577,8 +587,8 @@ func TestCombinedRulesWithResync(t *testing.T) {
 
 	// Execute first Renderer transaction.
 	txn := aclRenderer.NewTxn(true)
-	txn.Render(Pod1, pod1Txn1Cfg.PodIP, pod1Txn1Cfg.Ingress, pod1Txn1Cfg.Egress, false)
-	txn.Render(Pod3, pod3Cfg.PodIP, pod3Cfg.Ingress, pod3Cfg.Egress, false)
+	txn.Render(Pod1, pod1Txn1Cfg.PodIPs, pod1Txn1Cfg.Ingress, pod1Txn1Cfg.Egress, false)
+	txn.Render(Pod3, pod3Cfg.PodIPs, pod3Cfg.Ingress, pod3Cfg.Egress, false)
 	err := txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
@@ -602,8 +612,8 @@ func TestCombinedRulesWithResync(t *testing.T) {
 
 	// Execute second Renderer transaction (from non-empty state; change pod1 config).
 	txn = aclRenderer.NewTxn(true)
-	txn.Render(Pod1, pod1Txn2Cfg.PodIP, pod1Txn2Cfg.Ingress, pod1Txn2Cfg.Egress, false)
-	txn.Render(Pod3, pod3Cfg.PodIP, pod3Cfg.Ingress, pod3Cfg.Egress, false)
+	txn.Render(Pod1, pod1Txn2Cfg.PodIPs, pod1Txn2Cfg.Ingress, pod1Txn2Cfg.Egress, false)
+	txn.Render(Pod3, pod3Cfg.PodIPs, pod3Cfg.Ingress, pod3Cfg.Egress, false)
 	err = txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.PendingTxns).To(gomega.HaveLen(0))
@@ -661,8 +671,8 @@ func TestCombinedRulesWithResync(t *testing.T) {
 
 	// Re-sync back to the state after the first transaction.
 	txn = aclRenderer.NewTxn(true)
-	txn.Render(Pod1, pod1Txn1Cfg.PodIP, pod1Txn1Cfg.Ingress, pod1Txn1Cfg.Egress, false)
-	txn.Render(Pod3, pod3Cfg.PodIP, pod3Cfg.Ingress, pod3Cfg.Egress, false)
+	txn.Render(Pod1, pod1Txn1Cfg.PodIPs, pod1Txn1Cfg.Ingress, pod1Txn1Cfg.Egress, false)
+	txn.Render(Pod3, pod3Cfg.PodIPs, pod3Cfg.Ingress, pod3Cfg.Egress, false)
 	err = txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(2))
@@ -719,12 +729,12 @@ func TestCombinedRulesWithResyncAndRemovedPod(t *testing.T) {
 
 	// Prepare test data
 	pod1Cfg := &cache.PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress[1:],
 		Egress:  Ts7.Pod1Egress[:2],
 	}
 	pod3Cfg := &cache.PodConfig{
-		PodIP:   GetOneHostSubnet(Pod3IP),
+		PodIPs:  GetOneHostSubnets(Pod3IP),
 		Ingress: Ts7.Pod3Ingress,
 		Egress:  Ts7.Pod3Egress,
 	}
@@ -764,8 +774,8 @@ func TestCombinedRulesWithResyncAndRemovedPod(t *testing.T) {
 
 	// Execute first Renderer transaction.
 	txn := aclRenderer.NewTxn(true)
-	txn.Render(Pod1, pod1Cfg.PodIP, pod1Cfg.Ingress, pod1Cfg.Egress, false)
-	txn.Render(Pod3, pod3Cfg.PodIP, pod3Cfg.Ingress, pod3Cfg.Egress, false)
+	txn.Render(Pod1, pod1Cfg.PodIPs, pod1Cfg.Ingress, pod1Cfg.Egress, false)
+	txn.Render(Pod3, pod3Cfg.PodIPs, pod3Cfg.Ingress, pod3Cfg.Egress, false)
 	err := txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
@@ -789,7 +799,7 @@ func TestCombinedRulesWithResyncAndRemovedPod(t *testing.T) {
 
 	// Execute second Renderer transaction (from non-empty state; keep pod1 config & ***remove pod3***).
 	txn = aclRenderer.NewTxn(true)
-	txn.Render(Pod1, pod1Cfg.PodIP, pod1Cfg.Ingress, pod1Cfg.Egress, false)
+	txn.Render(Pod1, pod1Cfg.PodIPs, pod1Cfg.Ingress, pod1Cfg.Egress, false)
 	err = txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.PendingTxns).To(gomega.HaveLen(0))
@@ -849,8 +859,8 @@ func TestCombinedRulesWithResyncAndRemovedPod(t *testing.T) {
 
 	// Re-sync back to the state after the first transaction.
 	txn = aclRenderer.NewTxn(true)
-	txn.Render(Pod1, pod1Cfg.PodIP, pod1Cfg.Ingress, pod1Cfg.Egress, false)
-	txn.Render(Pod3, pod3Cfg.PodIP, pod3Cfg.Ingress, pod3Cfg.Egress, false)
+	txn.Render(Pod1, pod1Cfg.PodIPs, pod1Cfg.Ingress, pod1Cfg.Egress, false)
+	txn.Render(Pod3, pod3Cfg.PodIPs, pod3Cfg.Ingress, pod3Cfg.Egress, false)
 	err = txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(2))
@@ -907,12 +917,12 @@ func TestCombinedRulesWithRemovedPods(t *testing.T) {
 
 	// Prepare test data
 	pod1Cfg := &cache.PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress[1:],
 		Egress:  Ts7.Pod1Egress[:2],
 	}
 	pod3Cfg := &cache.PodConfig{
-		PodIP:   GetOneHostSubnet(Pod3IP),
+		PodIPs:  GetOneHostSubnets(Pod3IP),
 		Ingress: Ts7.Pod3Ingress,
 		Egress:  Ts7.Pod3Egress,
 	}
@@ -952,16 +962,16 @@ func TestCombinedRulesWithRemovedPods(t *testing.T) {
 
 	// Execute first Renderer transaction.
 	txn := aclRenderer.NewTxn(true)
-	txn.Render(Pod1, pod1Cfg.PodIP, pod1Cfg.Ingress, pod1Cfg.Egress, false)
-	txn.Render(Pod3, pod3Cfg.PodIP, pod3Cfg.Ingress, pod3Cfg.Egress, false)
+	txn.Render(Pod1, pod1Cfg.PodIPs, pod1Cfg.Ingress, pod1Cfg.Egress, false)
+	txn.Render(Pod3, pod3Cfg.PodIPs, pod3Cfg.Ingress, pod3Cfg.Egress, false)
 	err := txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
 
 	// Execute second Renderer transaction (keep pod1 config & ***remove pod3***).
 	txn = aclRenderer.NewTxn(false)
-	txn.Render(Pod1, pod1Cfg.PodIP, pod1Cfg.Ingress, pod1Cfg.Egress, false)
-	txn.Render(Pod3, pod3Cfg.PodIP, []*renderer.ContivRule{}, []*renderer.ContivRule{}, true)
+	txn.Render(Pod1, pod1Cfg.PodIPs, pod1Cfg.Ingress, pod1Cfg.Egress, false)
+	txn.Render(Pod3, pod3Cfg.PodIPs, []*renderer.ContivRule{}, []*renderer.ContivRule{}, true)
 	err = txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.PendingTxns).To(gomega.HaveLen(0))
@@ -1014,7 +1024,7 @@ func TestCombinedRulesWithRemovedPods(t *testing.T) {
 
 	// Execute third Renderer transaction (***remove pod1 as well***).
 	txn = aclRenderer.NewTxn(false)
-	txn.Render(Pod1, pod1Cfg.PodIP, []*renderer.ContivRule{}, []*renderer.ContivRule{}, true)
+	txn.Render(Pod1, pod1Cfg.PodIPs, []*renderer.ContivRule{}, []*renderer.ContivRule{}, true)
 	err = txn.Commit()
 	gomega.Expect(err).To(gomega.BeNil())
 	gomega.Expect(txnTracker.PendingTxns).To(gomega.HaveLen(0))
@@ -1025,3 +1035,987 @@ func TestCombinedRulesWithRemovedPods(t *testing.T) {
 	verifyReflectiveACL(aclEngine, contiv, "", false, false)
 	verifyGlobalTable(aclEngine, contiv, false)
 }
+
+func TestICMPRulesOnePod(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestICMPRulesOnePod")
+
+	// Prepare input data
+	ingress := []*renderer.ContivRule{}
+	egress := []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  IpNetwork("10.10.0.0/16"),
+			DestNetwork: IpNetwork(""),
+			Protocol:    renderer.ICMP,
+			ICMPType:    8, /* echo request */
+			ICMPCode:    renderer.AnyICMP,
+		},
+		{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  IpNetwork("10.10.0.0/16"),
+			DestNetwork: IpNetwork(""),
+			Protocol:    renderer.ICMP,
+			ICMPType:    renderer.AnyICMP,
+			ICMPCode:    renderer.AnyICMP,
+		},
+		DenyAll(),
+	}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction.
+	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+
+	// Test ACLs.
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(2))
+	verifyReflectiveACL(aclEngine, contiv, Pod1IfName, false, true)
+	acl := aclEngine.GetOutboundACL(Pod1IfName)
+	gomega.Expect(acl).ToNot(gomega.BeNil())
+	gomega.Expect(acl.Rules).To(gomega.HaveLen(3))
+	icmp := acl.Rules[0].Match.IpRule.Icmp
+	gomega.Expect(icmp).ToNot(gomega.BeNil())
+	gomega.Expect(icmp.Icmpv6).To(gomega.BeFalse())
+	gomega.Expect(icmp.IcmpTypeRange.First).To(gomega.BeEquivalentTo(8))
+	gomega.Expect(icmp.IcmpTypeRange.Last).To(gomega.BeEquivalentTo(8))
+	gomega.Expect(icmp.IcmpCodeRange.First).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(icmp.IcmpCodeRange.Last).To(gomega.BeEquivalentTo(255))
+
+	// Test connections (ICMP type and code are passed as ports).
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.ICMP, 8, 0)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.ICMP, 0, 0)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.ICMP, 3, 1)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod(googleDNS, Pod1, renderer.ICMP, 0, 0)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.TCP, somePort, 80)).To(gomega.Equal(ConnActionDenySyn))
+
+	// Dump ACLs and put them to mock vpp.
+	acls := aclEngine.DumpACLs()
+	vppPlugins.AddIPACL(acls...)
+
+	// Simulate restart of ACL Renderer.
+	txnTracker = localclient.NewTxnTracker(aclEngine.ApplyTxn)
+	aclRenderer = &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+	aclRenderer.Init()
+
+	// Resync with the same configuration - ICMP rules should be imported back.
+	err = aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(2))
+	gomega.Expect(aclEngine.GetOutboundACL(Pod1IfName).Rules).To(gomega.HaveLen(3))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.ICMP, 8, 0)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.ICMP, 0, 0)).To(gomega.Equal(ConnActionAllow))
+}
+
+func TestSCTPRuleOnePod(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestSCTPRuleOnePod")
+
+	// Prepare input data
+	ingress := []*renderer.ContivRule{}
+	egress := []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  IpNetwork("10.10.0.0/16"),
+			DestNetwork: IpNetwork(""),
+			Protocol:    renderer.SCTP,
+		},
+		AllowAll(),
+	}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+	aclRenderer.Init()
+
+	// SCTP is matched by the IP protocol number.
+	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+	acl := aclEngine.GetOutboundACL(Pod1IfName)
+	gomega.Expect(acl).ToNot(gomega.BeNil())
+	gomega.Expect(acl.Rules[0].Match.IpRule.Ip.Protocol).To(gomega.BeEquivalentTo(132))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.SCTP, somePort, 3868)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.20.50.1", Pod1, renderer.SCTP, somePort, 3868)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.TCP, somePort, 3868)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.UDP, somePort, 3868)).To(gomega.Equal(ConnActionAllow))
+
+	// SCTP deny for selected ports cannot be matched, the rule is left out.
+	egress = []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  IpNetwork("10.10.0.0/16"),
+			DestNetwork: IpNetwork(""),
+			Protocol:    renderer.SCTP,
+			DestPort:    3868,
+		},
+		AllowAll(),
+	}
+	err = aclRenderer.NewTxn(false).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(2))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.SCTP, somePort, 3868)).To(gomega.Equal(ConnActionAllow))
+	gomega.Expect(aclEngine.ConnectionInternetToPod("10.10.50.1", Pod1, renderer.TCP, somePort, 3868)).To(gomega.Equal(ConnActionAllow))
+}
+
+func TestHitCounters(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestHitCounters")
+
+	// Prepare input data (policy names are from the pod point of view)
+	ingressPolicy := policymodel.ID{Namespace: "default", Name: "egress-policy"}
+	egressPolicy := policymodel.ID{Namespace: "default", Name: "ingress-policy"}
+	withPolicy := func(rule *renderer.ContivRule, policy policymodel.ID) *renderer.ContivRule {
+		ruleCopy := rule.Copy()
+		ruleCopy.Policies = []policymodel.ID{policy}
+		return ruleCopy
+	}
+	ingress := []*renderer.ContivRule{withPolicy(Ts6.Rule1, ingressPolicy), withPolicy(Ts6.Rule2, ingressPolicy)}
+	egress := []*renderer.ContivRule{withPolicy(Ts5.Rule1, egressPolicy), withPolicy(Ts5.Rule2, egressPolicy)}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction.
+	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Prepare output of the VPP CLI (format of the ACL plugin of VPP 18.07),
+	// the local ACL is applied on one interface, the global ACL on two,
+	// every rule matched 10 packets in total.
+	localTable := aclRenderer.cache.GetLocalTableByPod(Pod1)
+	globalTable := aclRenderer.cache.GetGlobalTable()
+	gomega.Expect(localTable).ToNot(gomega.BeNil())
+	gomega.Expect(globalTable.NumOfRules).ToNot(gomega.BeZero())
+	aclsOutput := ""
+	appliedOutput := "Applied lookup entries for lookup contexts\n"
+	lcIndex := 0
+	for aclIdx, table := range []*cache.ContivRuleTable{localTable, globalTable} {
+		acl := table.Private.(*vpp_acl.AccessLists_Acl)
+		aclsOutput += fmt.Sprintf("acl-index %d count %d tag {%s}\n", aclIdx, len(acl.Rules), acl.AclName)
+		for ruleIdx := range acl.Rules {
+			aclsOutput += fmt.Sprintf("  %9d: ipv4 permit src 0.0.0.0/0 dst 0.0.0.0/0 proto 0 sport 0-65535 dport 0-65535\n", ruleIdx)
+		}
+		numOfIfs := aclIdx + 1
+		aclsOutput += "  applied inbound on sw_if_index: \n"
+		aclsOutput += "  applied outbound on sw_if_index:"
+		for i := 0; i < numOfIfs; i++ {
+			aclsOutput += fmt.Sprintf(" %d", 3+lcIndex+i)
+		}
+		aclsOutput += "\n  used in lookup context index:"
+		for i := 0; i < numOfIfs; i++ {
+			aclsOutput += fmt.Sprintf(" %d", lcIndex+i)
+		}
+		aclsOutput += "\n"
+		for i := 0; i < numOfIfs; i++ {
+			appliedOutput += fmt.Sprintf("lc_index %d:\n  applied acls: %d\n  lookup applied entries:\n", lcIndex, aclIdx)
+			for ruleIdx := range acl.Rules {
+				appliedOutput += fmt.Sprintf(" %4d: acl %d rule %d action 1 bitmask-ready rule %d colliding_rules: 0 "+
+					"next -1 prev -1 tail -1 hitcount %d acl_pos: 0\n", ruleIdx, aclIdx, ruleIdx, ruleIdx, 10/numOfIfs)
+			}
+			lcIndex++
+		}
+	}
+
+	// -> VPP mock replying to the CLI commands
+	vppMock := mock.NewVppAdapter()
+	vppMock.MockReplyHandler(func(request mock.MessageDTO) (reply []byte, msgID uint16, prepared bool) {
+		if request.MsgName != "cli_inband" {
+			return nil, 0, false
+		}
+		cli := &vpe.CliInband{}
+		if err := (&codec.MsgCodec{}).DecodeMsg(request.Data, cli); err != nil {
+			return nil, 0, false
+		}
+		replyMsg := &vpe.CliInbandReply{}
+		switch string(cli.Cmd) {
+		case showACLsCmd:
+			replyMsg.Reply = []byte(aclsOutput)
+		case showAppliedRulesCmd:
+			replyMsg.Reply = []byte(appliedOutput)
+		}
+		replyMsg.Length = uint32(len(replyMsg.Reply))
+		_, msgID, _ = vppMock.ReplyFor(request.MsgName)
+		reply, err := vppMock.ReplyBytes(request, replyMsg)
+		return reply, msgID, err == nil
+	})
+	conn, err := govpp.Connect(vppMock)
+	gomega.Expect(err).To(gomega.BeNil())
+	defer conn.Disconnect()
+	aclRenderer.GoVPPChan, err = conn.NewAPIChannel()
+	gomega.Expect(err).To(gomega.BeNil())
+	defer aclRenderer.GoVPPChan.Close()
+
+	// Read counters.
+	counters, err := aclRenderer.readACLCounters()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(counters).To(gomega.HaveLen(2))
+	gomega.Expect(counters[ACLNamePrefix+localTable.ID]).To(gomega.HaveLen(localTable.NumOfRules))
+	gomega.Expect(counters[ACLNamePrefix+localTable.ID][0]).To(gomega.BeEquivalentTo(10))
+	gomega.Expect(counters[ACLNamePrefix+globalTable.ID][0]).To(gomega.BeEquivalentTo(10))
+
+	// Update metrics (the first reading returns no rule hits).
+	gomega.Expect(aclRenderer.updateHitCounters(counters)).To(gomega.BeEmpty())
+	gaugeValue := func(gauge *prometheus.GaugeVec, labels ...string) float64 {
+		metric := &dto.Metric{}
+		gomega.Expect(gauge.WithLabelValues(labels...).Write(metric)).To(gomega.Succeed())
+		return metric.GetGauge().GetValue()
+	}
+	hitCounters := aclRenderer.hitCounters
+
+	// -> local table: every rule is attributed to the pod ingress policy
+	gomega.Expect(gaugeValue(hitCounters.policyHits, "default/ingress-policy")).To(
+		gomega.BeEquivalentTo(10 * localTable.NumOfRules))
+	gomega.Expect(gaugeValue(hitCounters.ruleHits, localTable.ID, "0", "default/ingress-policy")).To(
+		gomega.BeEquivalentTo(10))
+
+	// -> global table: rules with the pod IP are attributed to the pod egress policy
+	gomega.Expect(gaugeValue(hitCounters.policyHits, "default/egress-policy")).To(gomega.BeEquivalentTo(20))
+
+	// -> pod: all the rules of the local table + rules of the global table with the pod IP
+	gomega.Expect(gaugeValue(hitCounters.podHits, Pod1.Namespace, Pod1.Name)).To(
+		gomega.BeEquivalentTo(10*localTable.NumOfRules + 20))
+
+	// Next reading: the first rule of the local table and all the rules
+	// of the global table matched 5 more packets.
+	counters[ACLNamePrefix+localTable.ID][0] += 5
+	for ruleIdx := range counters[ACLNamePrefix+globalTable.ID] {
+		counters[ACLNamePrefix+globalTable.ID][ruleIdx] += 5
+	}
+	ruleHits := aclRenderer.updateHitCounters(counters)
+	var ingressHits, egressHits []*RuleHits
+	for _, hits := range ruleHits {
+		gomega.Expect(hits.Pod).To(gomega.Equal(Pod1))
+		gomega.Expect(hits.Packets).To(gomega.BeEquivalentTo(5))
+		if hits.Ingress {
+			ingressHits = append(ingressHits, hits)
+		} else {
+			egressHits = append(egressHits, hits)
+		}
+	}
+
+	// -> local table: the hits of the first rule
+	gomega.Expect(ingressHits).To(gomega.HaveLen(1))
+	gomega.Expect(ingressHits[0].Policies).To(gomega.Equal([]policymodel.ID{egressPolicy}))
+
+	// -> global table: the hits of the rules with the pod IP, converted
+	//    to the pod point of view
+	gomega.Expect(egressHits).To(gomega.HaveLen(2))
+	for _, hits := range egressHits {
+		gomega.Expect(hits.Rule.SrcNetwork.IP).To(gomega.BeEmpty())
+		gomega.Expect(hits.Policies).To(gomega.Equal([]policymodel.ID{ingressPolicy}))
+	}
+
+	// Unchanged counters.
+	gomega.Expect(aclRenderer.updateHitCounters(counters)).To(gomega.BeEmpty())
+}
+
+func TestDualStackRules(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestDualStackRules")
+
+	// Prepare input data
+	ingress := []*renderer.ContivRule{}
+	egress := []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  IpNetwork(""),
+			DestNetwork: IpNetwork(""),
+			Protocol:    renderer.TCP,
+			DestPort:    22,
+		},
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  IpNetwork(""),
+			DestNetwork: IpNetwork(""),
+			Protocol:    renderer.ICMP,
+			ICMPType:    8, /* echo request */
+			ICMPCode:    renderer.AnyICMP,
+		},
+		{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  IpNetwork("fd00::/64"),
+			DestNetwork: IpNetwork(""),
+			Protocol:    renderer.ICMP,
+			ICMPType:    renderer.AnyICMP,
+			ICMPCode:    renderer.AnyICMP,
+		},
+		{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  IpNetwork(""),
+			DestNetwork: IpNetwork(""),
+			Protocol:    renderer.ANY,
+		},
+	}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction with IPv4-only pod.
+	err := aclRenderer.NewTxn(true).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(1))
+
+	// Test ACLs - rules with unspecified networks are installed only for IPv4.
+	acl := aclEngine.GetOutboundACL(Pod1IfName)
+	gomega.Expect(acl).ToNot(gomega.BeNil())
+	gomega.Expect(acl.Rules).To(gomega.HaveLen(4))
+	gomega.Expect(acl.Rules[0].Match.IpRule.Icmp.Icmpv6).To(gomega.BeTrue())
+	for _, aclRule := range acl.Rules {
+		gomega.Expect(aclRule.Match.IpRule.Ip.SourceNetwork).ToNot(gomega.Equal(ipv6AddrAny))
+	}
+
+	// Pod gets also IPv6 address assigned - ACLs are re-rendered for dual-stack.
+	podIPs := GetOneHostSubnets(Pod1IP, "fd00::10")
+	err = aclRenderer.NewTxn(false).Render(Pod1, podIPs, ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(txnTracker.CommittedTxns).To(gomega.HaveLen(2))
+
+	// Test ACLs - ICMP rule with specific type is not duplicated.
+	acl = aclEngine.GetOutboundACL(Pod1IfName)
+	gomega.Expect(acl).ToNot(gomega.BeNil())
+	gomega.Expect(acl.Rules).To(gomega.HaveLen(6))
+	gomega.Expect(acl.Rules[0].Match.IpRule.Icmp.Icmpv6).To(gomega.BeTrue())
+	gomega.Expect(acl.Rules[1].Match.IpRule.Ip.SourceNetwork).To(gomega.BeEmpty())
+	gomega.Expect(acl.Rules[2].Match.IpRule.Ip.SourceNetwork).To(gomega.Equal(ipv6AddrAny))
+	gomega.Expect(acl.Rules[2].Match.IpRule.Tcp.DestinationPortRange.LowerPort).To(gomega.BeEquivalentTo(22))
+	gomega.Expect(acl.Rules[3].Match.IpRule.Icmp.Icmpv6).To(gomega.BeFalse())
+	gomega.Expect(acl.Rules[5].Match.IpRule.Ip.SourceNetwork).To(gomega.Equal(ipv6AddrAny))
+	reflectiveACL := aclEngine.GetACLByName(ACLNamePrefix + ReflectiveACLName)
+	gomega.Expect(reflectiveACL).ToNot(gomega.BeNil())
+	gomega.Expect(reflectiveACL.Rules).To(gomega.HaveLen(2))
+
+	// Dump ACLs and put them to mock vpp.
+	acls := aclEngine.DumpACLs()
+	vppPlugins.AddIPACL(acls...)
+
+	// Simulate restart of ACL Renderer.
+	txnTracker = localclient.NewTxnTracker(aclEngine.ApplyTxn)
+	aclRenderer = &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+	aclRenderer.Init()
+
+	// Resync with the same configuration - IPv6 duplicates should be imported back.
+	err = aclRenderer.NewTxn(true).Render(Pod1, podIPs, ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclRenderer.dualStack).To(gomega.BeTrue())
+	gomega.Expect(aclRenderer.cache.GetLocalTableByPod(Pod1).NumOfRules).To(gomega.Equal(4))
+	gomega.Expect(aclEngine.GetOutboundACL(Pod1IfName).Rules).To(gomega.HaveLen(6))
+
+	// IPv6 address is removed - IPv6 duplicates are removed as well.
+	err = aclRenderer.NewTxn(false).Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetOutboundACL(Pod1IfName).Rules).To(gomega.HaveLen(4))
+}
+
+func TestRollback(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestRollback")
+
+	// Prepare input data
+	ingress := []*renderer.ContivRule{}
+	egress := []*renderer.ContivRule{Ts5.Rule1 /* UDP, OTHER not allowed */, Ts5.Rule2}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+	contiv.SetPodIfName(Pod2, Pod2IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+	aclEngine.RegisterPod(Pod2, Pod2IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction.
+	txn := aclRenderer.NewTxn(true)
+	txn.Render(Pod1, GetOneHostSubnets(Pod1IP), ingress, egress, false)
+	txn.Render(Pod2, GetOneHostSubnets(Pod2IP), ingress, egress, false)
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(2)) /* pod1 and pod2 share the same local table */
+	localTable := aclRenderer.cache.GetLocalTableByPod(Pod1)
+	gomega.Expect(localTable).ToNot(gomega.BeNil())
+
+	// Try to change the configuration of pod2 and add global rules for pod1,
+	// the transaction fails after the first operation.
+	aclEngine.InjectFailure(1)
+	txn = aclRenderer.NewTxn(false)
+	txn.Render(Pod1, GetOneHostSubnets(Pod1IP), []*renderer.ContivRule{Ts6.Rule1, Ts6.Rule2}, egress, false)
+	txn.Render(Pod2, GetOneHostSubnets(Pod2IP), ingress, []*renderer.ContivRule{Ts5.Rule2}, false)
+	err = txn.Commit()
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	commitErr, isCommitErr := err.(*renderer.CommitError)
+	gomega.Expect(isCommitErr).To(gomega.BeTrue())
+	gomega.Expect(commitErr.Renderer).To(gomega.Equal("ACL"))
+	gomega.Expect(commitErr.RollbackErr).To(gomega.BeNil())
+
+	// The original configuration should be restored.
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(2))
+	verifyReflectiveACL(aclEngine, contiv, Pod1IfName, false, true)
+	verifyReflectiveACL(aclEngine, contiv, Pod2IfName, false, true)
+	verifyGlobalTable(aclEngine, contiv, false)
+	gomega.Expect(aclEngine.GetOutboundACL(Pod1IfName).AclName).To(gomega.Equal(ACLNamePrefix + localTable.ID))
+	gomega.Expect(aclEngine.GetOutboundACL(Pod2IfName).AclName).To(gomega.Equal(ACLNamePrefix + localTable.ID))
+	gomega.Expect(aclEngine.ConnectionPodToPod(Pod1, Pod2, renderer.UDP, somePort, 53)).To(gomega.Equal(ConnActionDenySyn))
+	gomega.Expect(aclEngine.ConnectionPodToPod(Pod2, Pod1, renderer.TCP, somePort, 80)).To(gomega.Equal(ConnActionAllow))
+
+	// The cache should remain unchanged.
+	gomega.Expect(aclRenderer.cache.GetLocalTableByPod(Pod2)).To(gomega.Equal(localTable))
+	gomega.Expect(aclRenderer.cache.GetGlobalTable().NumOfRules).To(gomega.BeZero())
+
+	// Retry the same change without failure.
+	txn = aclRenderer.NewTxn(false)
+	txn.Render(Pod1, GetOneHostSubnets(Pod1IP), []*renderer.ContivRule{Ts6.Rule1, Ts6.Rule2}, egress, false)
+	txn.Render(Pod2, GetOneHostSubnets(Pod2IP), ingress, []*renderer.ContivRule{Ts5.Rule2}, false)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(4))
+	verifyGlobalTable(aclEngine, contiv, true)
+	gomega.Expect(aclEngine.GetOutboundACL(Pod2IfName).AclName).ToNot(gomega.Equal(ACLNamePrefix + localTable.ID))
+}
+
+func TestHostRules(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestHostRules")
+
+	// Prepare input data
+	_, sshClients, _ := net.ParseCIDR("10.10.0.0/16")
+	hostRules := []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  sshClients,
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.TCP,
+			DestPort:    22,
+		},
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.ANY,
+		},
+	}
+	hostConfig := contiv.HostEndpointPolicyConfig{NodePortRange: "30000-30100"}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodNetwork("10.1.1.0/24")
+	contiv.SetNodeIP("192.168.16.1/24")
+	contiv.SetHostEndpointPolicyConfig(hostConfig)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction.
+	txn := aclRenderer.NewTxn(true)
+	txn.Render(Pod1, GetOneHostSubnets(Pod1IP), []*renderer.ContivRule{}, []*renderer.ContivRule{}, false)
+	txn.(renderer.HostTxn).RenderHost(hostRules)
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test ACLs: HOST-INTERCONNECT, HOST and the reflective ACL.
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(3))
+	verifyGlobalTable(aclEngine, contiv, false)
+
+	hostInterACL := aclEngine.GetOutboundACL(hostInterIfName)
+	gomega.Expect(hostInterACL).ToNot(gomega.BeNil())
+	gomega.Expect(hostInterACL.AclName).To(gomega.Equal(ACLNamePrefix + HostInterconnectACLName))
+	gomega.Expect(hostInterACL.Rules).To(gomega.HaveLen(3))
+	gomega.Expect(hostInterACL.Rules[0].AclAction).To(gomega.Equal(vpp_acl.AclAction_PERMIT))
+	gomega.Expect(hostInterACL.Rules[0].Match.IpRule.Ip.SourceNetwork).To(gomega.Equal("10.1.1.0/24"))
+	gomega.Expect(hostInterACL.Rules[1].AclAction).To(gomega.Equal(vpp_acl.AclAction_PERMIT))
+	gomega.Expect(hostInterACL.Rules[1].Match.IpRule.Ip.SourceNetwork).To(gomega.Equal("10.10.0.0/16"))
+	gomega.Expect(hostInterACL.Rules[1].Match.IpRule.Tcp.DestinationPortRange.LowerPort).To(gomega.BeEquivalentTo(22))
+	gomega.Expect(hostInterACL.Rules[2].AclAction).To(gomega.Equal(vpp_acl.AclAction_DENY))
+
+	hostACL := aclEngine.GetInboundACL(mainIfName)
+	gomega.Expect(hostACL).ToNot(gomega.BeNil())
+	gomega.Expect(hostACL.AclName).To(gomega.Equal(ACLNamePrefix + HostACLName))
+	gomega.Expect(hostACL.Rules).To(gomega.HaveLen(4))
+	gomega.Expect(hostACL.Rules[0].AclAction).To(gomega.Equal(vpp_acl.AclAction_REFLECT))
+	gomega.Expect(hostACL.Rules[0].Match.IpRule.Ip.DestinationNetwork).To(gomega.Equal("192.168.16.1/32"))
+	for _, aclRule := range hostACL.Rules[1:3] {
+		gomega.Expect(aclRule.AclAction).To(gomega.Equal(vpp_acl.AclAction_DENY))
+		gomega.Expect(aclRule.Match.IpRule.Ip.DestinationNetwork).To(gomega.Equal("192.168.16.1/32"))
+	}
+	gomega.Expect(hostACL.Rules[1].Match.IpRule.Tcp.DestinationPortRange.LowerPort).To(gomega.BeEquivalentTo(30000))
+	gomega.Expect(hostACL.Rules[1].Match.IpRule.Tcp.DestinationPortRange.UpperPort).To(gomega.BeEquivalentTo(30100))
+	gomega.Expect(hostACL.Rules[2].Match.IpRule.Udp.DestinationPortRange.LowerPort).To(gomega.BeEquivalentTo(30000))
+	gomega.Expect(hostACL.Rules[2].Match.IpRule.Udp.DestinationPortRange.UpperPort).To(gomega.BeEquivalentTo(30100))
+	gomega.Expect(hostACL.Rules[3].AclAction).To(gomega.Equal(vpp_acl.AclAction_REFLECT))
+	gomega.Expect(hostACL.Rules[3].Match.IpRule.Ip.DestinationNetwork).To(gomega.BeEmpty())
+
+	reflectiveACL := aclEngine.GetInboundACL(hostInterIfName)
+	gomega.Expect(reflectiveACL).ToNot(gomega.BeNil())
+	gomega.Expect(reflectiveACL.AclName).To(gomega.Equal(ACLNamePrefix + ReflectiveACLName))
+	gomega.Expect(reflectiveACL.Interfaces.Ingress).ToNot(gomega.ContainElement(mainIfName))
+
+	// Pod1 remains non-isolated.
+	gomega.Expect(aclEngine.GetOutboundACL(Pod1IfName)).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetInboundACL(Pod1IfName)).To(gomega.BeNil())
+
+	// Isolate Pod1 - the global table is included in the HOST-INTERCONNECT ACL.
+	txn = aclRenderer.NewTxn(false)
+	txn.Render(Pod1, GetOneHostSubnets(Pod1IP), []*renderer.ContivRule{Ts6.Rule1, Ts6.Rule2}, []*renderer.ContivRule{}, false)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	globalACL := aclEngine.GetACLByName(ACLNamePrefix + cache.GlobalTableID)
+	gomega.Expect(globalACL).ToNot(gomega.BeNil())
+	gomega.Expect(globalACL.Interfaces.Egress).To(gomega.ContainElement(mainIfName))
+	gomega.Expect(globalACL.Interfaces.Egress).ToNot(gomega.ContainElement(hostInterIfName))
+	hostInterACL = aclEngine.GetOutboundACL(hostInterIfName)
+	gomega.Expect(hostInterACL.AclName).To(gomega.Equal(ACLNamePrefix + HostInterconnectACLName))
+	gomega.Expect(len(hostInterACL.Rules)).To(gomega.BeNumerically(">", 3))
+	gomega.Expect(aclEngine.GetInboundACL(mainIfName).AclName).To(gomega.Equal(ACLNamePrefix + HostACLName))
+
+	// Remove the host rules.
+	txn = aclRenderer.NewTxn(false)
+	txn.(renderer.HostTxn).RenderHost([]*renderer.ContivRule{})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetACLByName(ACLNamePrefix + HostInterconnectACLName)).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetACLByName(ACLNamePrefix + HostACLName)).To(gomega.BeNil())
+	verifyGlobalTable(aclEngine, contiv, true)
+	verifyReflectiveACL(aclEngine, contiv, Pod1IfName, true, true)
+}
+
+func TestLoadBalancerRules(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestLoadBalancerRules")
+
+	// Prepare input data
+	_, lbClients, _ := net.ParseCIDR("192.168.0.0/16")
+	_, lbIP, _ := net.ParseCIDR("20.0.0.1/32")
+	lbRules := []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  lbClients,
+			DestNetwork: lbIP,
+			Protocol:    renderer.TCP,
+			DestPort:    80,
+		},
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: lbIP,
+			Protocol:    renderer.TCP,
+			DestPort:    80,
+		},
+	}
+	_, sshClients, _ := net.ParseCIDR("10.10.0.0/16")
+	hostRules := []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  sshClients,
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.TCP,
+			DestPort:    22,
+		},
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.ANY,
+		},
+	}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodNetwork("10.1.1.0/24")
+	contiv.SetNodeIP("192.168.16.1/24")
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction.
+	txn := aclRenderer.NewTxn(true)
+	txn.Render(Pod1, GetOneHostSubnets(Pod1IP), []*renderer.ContivRule{}, []*renderer.ContivRule{}, false)
+	txn.(renderer.LoadBalancerTxn).RenderLoadBalancers(lbRules)
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test ACLs: only HOST (the host is not isolated).
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(1))
+	gomega.Expect(aclEngine.GetOutboundACL(hostInterIfName)).To(gomega.BeNil())
+	hostACL := aclEngine.GetInboundACL(mainIfName)
+	gomega.Expect(hostACL).ToNot(gomega.BeNil())
+	gomega.Expect(hostACL.AclName).To(gomega.Equal(ACLNamePrefix + HostACLName))
+	gomega.Expect(hostACL.Rules).To(gomega.HaveLen(3))
+	gomega.Expect(hostACL.Rules[0].AclAction).To(gomega.Equal(vpp_acl.AclAction_REFLECT))
+	gomega.Expect(hostACL.Rules[0].Match.IpRule.Ip.SourceNetwork).To(gomega.Equal("192.168.0.0/16"))
+	gomega.Expect(hostACL.Rules[0].Match.IpRule.Ip.DestinationNetwork).To(gomega.Equal("20.0.0.1/32"))
+	gomega.Expect(hostACL.Rules[1].AclAction).To(gomega.Equal(vpp_acl.AclAction_DENY))
+	gomega.Expect(hostACL.Rules[1].Match.IpRule.Ip.DestinationNetwork).To(gomega.Equal("20.0.0.1/32"))
+	gomega.Expect(hostACL.Rules[1].Match.IpRule.Tcp.DestinationPortRange.LowerPort).To(gomega.BeEquivalentTo(80))
+	gomega.Expect(hostACL.Rules[2].AclAction).To(gomega.Equal(vpp_acl.AclAction_REFLECT))
+	gomega.Expect(hostACL.Rules[2].Match.IpRule.Ip.DestinationNetwork).To(gomega.BeEmpty())
+
+	// Isolate the host - the load-balancer rules are evaluated first.
+	txn = aclRenderer.NewTxn(false)
+	txn.(renderer.HostTxn).RenderHost(hostRules)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetOutboundACL(hostInterIfName).AclName).To(gomega.Equal(ACLNamePrefix + HostInterconnectACLName))
+	hostACL = aclEngine.GetInboundACL(mainIfName)
+	gomega.Expect(hostACL.AclName).To(gomega.Equal(ACLNamePrefix + HostACLName))
+	gomega.Expect(hostACL.Rules).To(gomega.HaveLen(6))
+	gomega.Expect(hostACL.Rules[0].Match.IpRule.Ip.DestinationNetwork).To(gomega.Equal("20.0.0.1/32"))
+	gomega.Expect(hostACL.Rules[1].Match.IpRule.Ip.DestinationNetwork).To(gomega.Equal("20.0.0.1/32"))
+	gomega.Expect(hostACL.Rules[2].Match.IpRule.Ip.DestinationNetwork).To(gomega.Equal("192.168.16.1/32"))
+	reflectiveACL := aclEngine.GetInboundACL(hostInterIfName)
+	gomega.Expect(reflectiveACL).ToNot(gomega.BeNil())
+	gomega.Expect(reflectiveACL.Interfaces.Ingress).ToNot(gomega.ContainElement(mainIfName))
+
+	// Remove the load-balancer rules - the host remains isolated.
+	txn = aclRenderer.NewTxn(false)
+	txn.(renderer.LoadBalancerTxn).RenderLoadBalancers([]*renderer.ContivRule{})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	hostACL = aclEngine.GetInboundACL(mainIfName)
+	gomega.Expect(hostACL.Rules).To(gomega.HaveLen(4))
+	gomega.Expect(hostACL.Rules[0].Match.IpRule.Ip.DestinationNetwork).To(gomega.Equal("192.168.16.1/32"))
+
+	// Remove the host rules.
+	txn = aclRenderer.NewTxn(false)
+	txn.(renderer.HostTxn).RenderHost([]*renderer.ContivRule{})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetNumOfACLs()).To(gomega.Equal(0))
+}
+
+func TestPreNATRules(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestPreNATRules")
+
+	// Prepare input data
+	_, serviceIP, _ := net.ParseCIDR("10.96.0.20/32")
+	preNATRules := []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: serviceIP,
+			Protocol:    renderer.TCP,
+			DestPort:    5432,
+		},
+		{
+			Action:      renderer.ActionPermit,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.ANY,
+		},
+	}
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodIfName(Pod1, Pod1IfName)
+	contiv.SetPodIfName(Pod3, Pod3IfName)
+
+	// -> ACL engine
+	aclEngine := NewMockACLEngine(logger, contiv)
+	aclEngine.RegisterPod(Pod1, Pod1IP, false)
+	aclEngine.RegisterPod(Pod3, Pod3IP, false)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(aclEngine.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+
+	// Prepare ACL Renderer.
+	aclRenderer := &Renderer{
+		Deps: Deps{
+			Log:           logger,
+			Contiv:        contiv,
+			VPP:           vppPlugins,
+			ACLTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+	aclRenderer.Init()
+
+	// Execute Renderer transaction - both pods isolated, only Pod1 with pre-NAT rules.
+	txn := aclRenderer.NewTxn(false)
+	txn.Render(Pod1, GetOneHostSubnets(Pod1IP), []*renderer.ContivRule{Ts6.Rule1, Ts6.Rule2}, []*renderer.ContivRule{}, false)
+	txn.Render(Pod3, GetOneHostSubnets(Pod3IP), []*renderer.ContivRule{Ts6.Rule1, Ts6.Rule2}, []*renderer.ContivRule{}, false)
+	txn.(renderer.PreNATTxn).RenderPreNAT(Pod1, preNATRules)
+	txn.(renderer.PreNATTxn).RenderPreNAT(Pod3, []*renderer.ContivRule{})
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Test the pre-NAT ACL - it replaces the reflective ACL for Pod1.
+	preNATACL := aclEngine.GetInboundACL(Pod1IfName)
+	gomega.Expect(preNATACL).ToNot(gomega.BeNil())
+	gomega.Expect(preNATACL.AclName).To(gomega.HavePrefix(ACLNamePrefix + PreNATACLNamePrefix))
+	gomega.Expect(preNATACL.Interfaces.Ingress).To(gomega.Equal([]string{Pod1IfName}))
+	gomega.Expect(preNATACL.Rules).To(gomega.HaveLen(2))
+	gomega.Expect(preNATACL.Rules[0].AclAction).To(gomega.Equal(vpp_acl.AclAction_DENY))
+	gomega.Expect(preNATACL.Rules[0].Match.IpRule.Ip.DestinationNetwork).To(gomega.Equal("10.96.0.20/32"))
+	gomega.Expect(preNATACL.Rules[0].Match.IpRule.Tcp.DestinationPortRange.LowerPort).To(gomega.BeEquivalentTo(5432))
+	gomega.Expect(preNATACL.Rules[1].AclAction).To(gomega.Equal(vpp_acl.AclAction_REFLECT))
+	verifyReflectiveACL(aclEngine, contiv, Pod3IfName, true, true)
+	gomega.Expect(aclEngine.GetInboundACL(mainIfName).Interfaces.Ingress).ToNot(gomega.ContainElement(Pod1IfName))
+
+	// Resync with the same configuration - the pre-NAT ACL is preserved.
+	acls := aclEngine.DumpACLs()
+	vppPlugins.AddIPACL(acls...)
+
+	txn = aclRenderer.NewTxn(true)
+	txn.Render(Pod1, GetOneHostSubnets(Pod1IP), []*renderer.ContivRule{Ts6.Rule1, Ts6.Rule2}, []*renderer.ContivRule{}, false)
+	txn.Render(Pod3, GetOneHostSubnets(Pod3IP), []*renderer.ContivRule{Ts6.Rule1, Ts6.Rule2}, []*renderer.ContivRule{}, false)
+	txn.(renderer.PreNATTxn).RenderPreNAT(Pod1, preNATRules)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetInboundACL(Pod1IfName)).To(gomega.Equal(preNATACL))
+	verifyReflectiveACL(aclEngine, contiv, Pod3IfName, true, true)
+
+	// Pod3 gets the same pre-NAT rules - the ACL is shared.
+	txn = aclRenderer.NewTxn(false)
+	txn.(renderer.PreNATTxn).RenderPreNAT(Pod3, preNATRules)
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	preNATACL = aclEngine.GetInboundACL(Pod3IfName)
+	gomega.Expect(preNATACL.Interfaces.Ingress).To(gomega.ConsistOf(Pod1IfName, Pod3IfName))
+	gomega.Expect(aclEngine.GetACLByName(ACLNamePrefix + ReflectiveACLName).Interfaces.Ingress).ToNot(gomega.ContainElement(Pod3IfName))
+
+	// Remove the pre-NAT rules.
+	txn = aclRenderer.NewTxn(false)
+	txn.(renderer.PreNATTxn).RenderPreNAT(Pod1, []*renderer.ContivRule{})
+	txn.(renderer.PreNATTxn).RenderPreNAT(Pod3, []*renderer.ContivRule{})
+	err = txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(aclEngine.GetACLByName(preNATACL.AclName)).To(gomega.BeNil())
+	verifyReflectiveACL(aclEngine, contiv, Pod1IfName, true, true)
+	verifyReflectiveACL(aclEngine, contiv, Pod3IfName, true, true)
+}
+
+func TestParseNodePortRange(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	first, last, err := parseNodePortRange(DefaultNodePortRange)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(first).To(gomega.BeEquivalentTo(30000))
+	gomega.Expect(last).To(gomega.BeEquivalentTo(32767))
+
+	first, last, err = parseNodePortRange(" 8000 - 8000 ")
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(first).To(gomega.BeEquivalentTo(8000))
+	gomega.Expect(last).To(gomega.BeEquivalentTo(8000))
+
+	for _, invalid := range []string{"", "8000", "0-100", "200-100", "1-70000", "a-b"} {
+		_, _, err = parseNodePortRange(invalid)
+		gomega.Expect(err).ToNot(gomega.BeNil())
+	}
+}
@@ -0,0 +1,458 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package acl
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/vpp-agent/plugins/vpp/binapi/vpe"
+	vpp_acl "github.com/ligato/vpp-agent/plugins/vpp/model/acl"
+	"github.com/prometheus/client_golang/prometheus"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+	"github.com/contiv/vpp/plugins/policy/renderer/cache"
+)
+
+const (
+	// hitCountersPeriod is the period at which the ACL counters are read from VPP.
+	hitCountersPeriod = 30 * time.Second
+
+	// enableHitCountersCmd is the VPP CLI command enabling the hash-based ACL
+	// matching, which counts hits of the applied ACL rules.
+	enableHitCountersCmd = "set acl-plugin use-hash-acl-matching 1"
+
+	// showACLsCmd is the VPP CLI command printing ACLs with their indexes and tags.
+	showACLsCmd = "show acl-plugin acl"
+
+	// showAppliedRulesCmd is the VPP CLI command printing the ACL rules applied
+	// in the lookup contexts (interfaces) together with the hit counts.
+	showAppliedRulesCmd = "show acl-plugin tables applied"
+
+	// names and labels of the Prometheus metrics
+	ruleHitsMetric     = "policyRuleHits"
+	policyHitsMetric   = "policyHits"
+	podHitsMetric      = "podPolicyHits"
+	tableLabel         = "table"
+	ruleIndexLabel     = "ruleIndex"
+	policiesLabel      = "policies"
+	policyLabel        = "policy"
+	podNamespaceLabel  = "podNamespace"
+	podNameLabel       = "podName"
+	policySeparator    = ","
+	unknownPolicyLabel = ""
+)
+
+var (
+	// aclHeaderRegexp matches the first line of an ACL printed by showACLsCmd,
+	// e.g. "acl-index 0 count 2 tag {contiv/vpp-policy-GLOBAL}".
+	aclHeaderRegexp = regexp.MustCompile(`^\s*acl-index\s+(\d+)\s+count\s+\d+\s+tag\s+\{(.*)\}`)
+
+	// appliedRuleRegexp matches an applied ACL rule printed by showAppliedRulesCmd, e.g.
+	// "   0: acl 1 rule 0 action 1 bitmask-ready rule 0 colliding_rules: 0 next -1 prev -1 tail -1 hitcount 12 acl_pos: 0".
+	appliedRuleRegexp = regexp.MustCompile(`^\s*\d+:\s+acl\s+(\d+)\s+rule\s+(\d+)\s.*\bhitcount\s+(\d+)`)
+)
+
+// hitCounters are the metrics with the number of packets matched by the rendered
+// rules. Bytes are not exported: the ACL plugin of VPP 18.07 keeps only the hit
+// count (packets) of the applied rules, there are no per-rule byte counters.
+type hitCounters struct {
+	ruleHits   *prometheus.GaugeVec
+	policyHits *prometheus.GaugeVec
+	podHits    *prometheus.GaugeVec
+}
+
+// aclCounters maps ACL name and the rule index to the number of hits.
+type aclCounters map[string]map[int]uint64
+
+// RuleHits is the number of packets of a local pod matched by an installed
+// ACL rule since the previous reading of the ACL counters.
+type RuleHits struct {
+	Pod podmodel.ID
+
+	// Ingress is true for the traffic entering the pod, false for the traffic
+	// sent by the pod.
+	Ingress bool
+
+	// Rule is the matched rule from the pod point of view, i.e. with the peer
+	// network as the source network for ingress and as the destination network
+	// for egress (and the other network unspecified).
+	Rule *renderer.ContivRule
+
+	// Policies the rule is attributed to (see updateHitCounters).
+	Policies []policymodel.ID
+
+	// Packets is the number of matched packets.
+	Packets uint64
+}
+
+// ruleHitsKey identifies an applied ACL rule across the readings of the counters.
+// The rule is included, because the rules of a modified ACL may change their indexes.
+type ruleHitsKey struct {
+	aclName string
+	ruleIdx int
+	rule    string
+}
+
+// newHitCounters creates the metrics of hit counters.
+func newHitCounters() *hitCounters {
+	newGaugeVec := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels)
+	}
+	return &hitCounters{
+		ruleHits:   newGaugeVec(ruleHitsMetric, "Number of packets matched by the policy rule", tableLabel, ruleIndexLabel, policiesLabel),
+		policyHits: newGaugeVec(policyHitsMetric, "Number of packets matched by the rules of the policy", policyLabel),
+		podHits:    newGaugeVec(podHitsMetric, "Number of packets matched by the policy rules of the pod", podNamespaceLabel, podNameLabel),
+	}
+}
+
+// collectors returns all the metrics of hit counters.
+func (hc *hitCounters) collectors() []prometheus.Collector {
+	return []prometheus.Collector{hc.ruleHits, hc.policyHits, hc.podHits}
+}
+
+// reset removes all previously reported values.
+func (hc *hitCounters) reset() {
+	for _, metric := range []*prometheus.GaugeVec{hc.ruleHits, hc.policyHits, hc.podHits} {
+		metric.Reset()
+	}
+}
+
+// WatchRuleHits registers a channel through which the renderer periodically
+// sends the numbers of packets matched by the ACL rules of the local pods since
+// the previous reading of the counters (rules without hits are not included).
+func (r *Renderer) WatchRuleHits(hitsChan chan<- []*RuleHits) {
+	r.Lock()
+	defer r.Unlock()
+	r.hitsChan = hitsChan
+}
+
+// collectHitCounters periodically reads the ACL counters from VPP, updates
+// the metrics and notifies the rule hits watcher.
+func (r *Renderer) collectHitCounters() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(hitCountersPeriod)
+	defer ticker.Stop()
+
+	enabled := false
+	for {
+		select {
+		case <-ticker.C:
+			if !enabled {
+				// Hash-based matching is the default of the ACL plugin, but only
+				// this mode counts the hits, therefore it is enabled explicitly.
+				if _, err := r.executeCLI(enableHitCountersCmd); err != nil {
+					r.Log.WithField("err", err).Warn("Failed to enable ACL hit counters")
+					continue
+				}
+				enabled = true
+			}
+			counters, err := r.readACLCounters()
+			if err != nil {
+				r.Log.WithField("err", err).Debug("Failed to read ACL counters")
+				continue
+			}
+			ruleHits := r.updateHitCounters(counters)
+			r.Lock()
+			hitsChan := r.hitsChan
+			r.Unlock()
+			if hitsChan == nil || len(ruleHits) == 0 {
+				continue
+			}
+			select {
+			case hitsChan <- ruleHits:
+			case <-r.ctx.Done():
+				return
+			}
+
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// readACLCounters reads hit counts of all applied ACL rules from VPP.
+func (r *Renderer) readACLCounters() (aclCounters, error) {
+	acls, err := r.executeCLI(showACLsCmd)
+	if err != nil {
+		return nil, err
+	}
+	appliedRules, err := r.executeCLI(showAppliedRulesCmd)
+	if err != nil {
+		return nil, err
+	}
+	return parseACLCounters(acls, appliedRules), nil
+}
+
+// executeCLI executes VPP CLI command and returns the output.
+func (r *Renderer) executeCLI(cmd string) (string, error) {
+	req := &vpe.CliInband{
+		Cmd: []byte(cmd),
+	}
+	reply := &vpe.CliInbandReply{}
+	if err := r.GoVPPChan.SendRequest(req).ReceiveReply(reply); err != nil {
+		return "", err
+	}
+	if reply.Retval != 0 {
+		return "", fmt.Errorf("VPP CLI '%s' failed with retval %d", cmd, reply.Retval)
+	}
+	// CLI errors are reported only in the textual reply
+	output := string(reply.Reply)
+	if strings.Contains(output, "unknown input") {
+		return "", fmt.Errorf("VPP CLI '%s' failed: %s", cmd, strings.TrimSpace(output))
+	}
+	return output, nil
+}
+
+// parseACLCounters parses the hit counts of ACL rules from the output
+// of showAppliedRulesCmd, with ACL indexes translated to ACL names (tags)
+// using the output of showACLsCmd. A rule is applied separately for every
+// interface and direction the ACL is assigned to, the counts are summed.
+func parseACLCounters(acls, appliedRules string) aclCounters {
+	aclNames := make(map[int]string)
+	for _, line := range strings.Split(acls, "\n") {
+		if match := aclHeaderRegexp.FindStringSubmatch(line); match != nil {
+			aclIdx, _ := strconv.Atoi(match[1])
+			aclNames[aclIdx] = match[2]
+		}
+	}
+
+	counters := make(aclCounters)
+	for _, line := range strings.Split(appliedRules, "\n") {
+		match := appliedRuleRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		aclIdx, _ := strconv.Atoi(match[1])
+		aclName, hasName := aclNames[aclIdx]
+		if !hasName {
+			continue
+		}
+		ruleIdx, _ := strconv.Atoi(match[2])
+		hits, _ := strconv.ParseUint(match[3], 10, 64)
+		if _, hasACL := counters[aclName]; !hasACL {
+			counters[aclName] = make(map[int]uint64)
+		}
+		counters[aclName][ruleIdx] += hits
+	}
+	return counters
+}
+
+// updateHitCounters attributes the counters of ACL rules to the rendered tables,
+// and through the configuration of the pods to the policies and the pods,
+// and updates the metrics. Returns the hits of the ACL rules of the local pods
+// since the previous call (nothing is returned for the first call, which only
+// reads the initial values).
+//
+// The ACL rule is attributed to the policies of the pod rule matching
+// the same traffic, or if there is no such rule (rules of multiple pods
+// or policies may get merged by the cache), to all policies with the rules
+// of the same action overlapping with the ACL rule.
+// Counters of a local table shared by multiple pods are reported for each
+// of the pods.
+func (r *Renderer) updateHitCounters(counters aclCounters) (ruleHits []*RuleHits) {
+	r.Lock()
+	defer r.Unlock()
+
+	// Collect all tables with the installed ACLs.
+	tables := make(map[string]*cache.ContivRuleTable)
+	for _, table := range r.installedTables() {
+		tables[ACLNamePrefix+table.ID] = table
+	}
+
+	// Index pods by IP address.
+	podByIP := make(map[string]podmodel.ID)
+	for pod := range r.cache.GetAllPods() {
+		if config := r.cache.GetPodConfig(pod); config != nil {
+			for _, podIP := range config.PodIPs {
+				podByIP[podIP.IP.String()] = pod
+			}
+		}
+	}
+
+	policyHits := make(map[policymodel.ID]uint64)
+	podHits := make(map[podmodel.ID]uint64)
+	lastRuleHits := r.lastRuleHits
+	r.lastRuleHits = make(map[ruleHitsKey]uint64)
+	r.hitCounters.reset()
+	for aclName, ruleCounters := range counters {
+		table, hasTable := tables[aclName]
+		if !hasTable || table.Private == nil {
+			continue
+		}
+		acl := table.Private.(*vpp_acl.AccessLists_Acl)
+		for ruleIdx, hits := range ruleCounters {
+			if ruleIdx >= len(acl.Rules) {
+				continue
+			}
+			rule, ok := r.importACLRule(acl.Rules[ruleIdx])
+			if !ok {
+				continue
+			}
+
+			// Number of hits since the previous reading (counters of re-applied
+			// rules start from zero).
+			key := ruleHitsKey{aclName: aclName, ruleIdx: ruleIdx, rule: acl.Rules[ruleIdx].String()}
+			r.lastRuleHits[key] = hits
+			newHits := hits
+			if lastHits, known := lastRuleHits[key]; known && lastHits <= hits {
+				newHits = hits - lastHits
+			}
+			if lastRuleHits == nil {
+				newHits = 0
+			}
+
+			// Find the pods and the candidate rules the ACL rule was generated from.
+			var (
+				pods       []podmodel.ID
+				candidates []*renderer.ContivRule
+			)
+			srcPod, srcIsPod := podByIP[hostIP(rule.SrcNetwork)]
+			if srcIsPod {
+				// ingress rule of the source pod (from the pod point of view)
+				if config := r.cache.GetPodConfig(srcPod); config != nil {
+					candidates = append(candidates, config.Ingress...)
+				}
+			}
+			if table.Type == cache.Global {
+				if srcIsPod {
+					pods = append(pods, srcPod)
+				}
+			} else {
+				for pod := range table.Pods {
+					pods = append(pods, pod)
+					if config := r.cache.GetPodConfig(pod); config != nil {
+						candidates = append(candidates, config.Egress...)
+					}
+				}
+			}
+			policies := rulePolicies(rule, candidates)
+
+			labels := prometheus.Labels{
+				tableLabel:     table.ID,
+				ruleIndexLabel: strconv.Itoa(ruleIdx),
+				policiesLabel:  joinPolicies(policies),
+			}
+			r.hitCounters.ruleHits.With(labels).Set(float64(hits))
+			for _, policy := range policies {
+				policyHits[policy] += hits
+			}
+			for _, pod := range pods {
+				podHits[pod] += hits
+			}
+
+			if newHits == 0 {
+				continue
+			}
+			if table.Type == cache.Global {
+				if srcIsPod {
+					// the source network is the pod IP
+					podRule := rule.Copy()
+					podRule.SrcNetwork = &net.IPNet{}
+					ruleHits = append(ruleHits, &RuleHits{
+						Pod: srcPod, Rule: podRule, Policies: policies, Packets: newHits})
+				}
+			} else {
+				for _, pod := range pods {
+					ruleHits = append(ruleHits, &RuleHits{
+						Pod: pod, Ingress: true, Rule: rule, Policies: policies, Packets: newHits})
+				}
+			}
+		}
+	}
+
+	for policy, hits := range policyHits {
+		labels := prometheus.Labels{policyLabel: policyName(policy)}
+		r.hitCounters.policyHits.With(labels).Set(float64(hits))
+	}
+	for pod, hits := range podHits {
+		labels := prometheus.Labels{podNamespaceLabel: pod.Namespace, podNameLabel: pod.Name}
+		r.hitCounters.podHits.With(labels).Set(float64(hits))
+	}
+	r.Log.WithFields(logging.Fields{
+		"policies": len(policyHits),
+		"pods":     len(podHits),
+	}).Debug("Updated policy hit counters")
+	return ruleHits
+}
+
+// rulePolicies returns the policies of the candidate rule matching the same
+// traffic as <rule>, or of all candidate rules with the same action overlapping
+// with the rule.
+func rulePolicies(rule *renderer.ContivRule, candidates []*renderer.ContivRule) []policymodel.ID {
+	for _, candidate := range candidates {
+		if candidate.Compare(rule) == 0 {
+			return candidate.Policies
+		}
+	}
+	var policies []policymodel.ID
+	seen := make(map[policymodel.ID]struct{})
+	for _, candidate := range candidates {
+		if candidate.Action != rule.Action || !candidate.Overlaps(rule) {
+			continue
+		}
+		for _, policy := range candidate.Policies {
+			if _, duplicate := seen[policy]; !duplicate {
+				seen[policy] = struct{}{}
+				policies = append(policies, policy)
+			}
+		}
+	}
+	return policies
+}
+
+// hostIP returns the IP address of a single-host network, or empty string
+// for other networks.
+func hostIP(network *net.IPNet) string {
+	if network == nil || len(network.IP) == 0 {
+		return ""
+	}
+	if ones, bits := network.Mask.Size(); ones != bits {
+		return ""
+	}
+	return network.IP.String()
+}
+
+// joinPolicies returns the sorted list of policy names as a single label value.
+func joinPolicies(policies []policymodel.ID) string {
+	if len(policies) == 0 {
+		return unknownPolicyLabel
+	}
+	var names []string
+	for _, policy := range policies {
+		names = append(names, policyName(policy))
+	}
+	sort.Strings(names)
+	return strings.Join(names, policySeparator)
+}
+
+// policyName returns name of the policy as used in the metrics.
+func policyName(policy policymodel.ID) string {
+	if policy.Namespace == "" {
+		// cluster-wide policy
+		return policy.Name
+	}
+	return policy.Namespace + "/" + policy.Name
+}
@@ -22,6 +22,7 @@ import (
 	"strconv"
 
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
 	"github.com/contiv/vpp/plugins/policy/utils"
 )
 
@@ -32,8 +33,9 @@ import (
 // which is Policy Configurator.
 type PolicyRendererAPI interface {
 	// NewTxn starts a new transaction. The rendering should execute only after
-	// Commit() is called. Ideally, the transaction should support rollback
-	// to recover from an in-progress fail.
+	// Commit() is called. The transaction should be atomic: if the commit
+	// fails in the middle, already applied changes should be rolled back
+	// and *CommitError returned.
 	// If <resync> is enabled, the supplied configuration should completely
 	// replace the existing one. Otherwise, perform the changes incrementally,
 	// i.e. pods not mentioned in the transaction should remain unaffected.
@@ -48,18 +50,81 @@ type Txn interface {
 	// point of view!
 	// For ingress rules the source IP is unset, i.e. 0.0.0.0/ (match all).
 	// For egress rules the destination IP is unset, i.e. 0.0.0.0/ (match all).
-	// The renderer may use the provided pod IP to make the rules fully specific
-	// in case they are installed globally and not assigned to interfaces.
+	// The renderer may use the provided pod IPs (one for each IP family
+	// in dual-stack clusters) to make the rules fully specific in case they
+	// are installed globally and not assigned to interfaces.
 	// Empty set of rules should allow any traffic in that direction.
 	// The flag *removed* is set to true if the pod was just removed - in such
-	// case *podIP* may be nil and both list of rules are empty.
-	Render(pod podmodel.ID, podIP *net.IPNet /* one host subnet */, ingress []*ContivRule, egress []*ContivRule, removed bool) Txn
+	// case *podIPs* may be empty and both list of rules are empty.
+	Render(pod podmodel.ID, podIPs []*net.IPNet /* one host subnets */, ingress []*ContivRule, egress []*ContivRule, removed bool) Txn
 
 	// Commit proceeds with the rendering. The changes are propagated into
 	// the destination network stack.
+	// If the changes could not be applied, *CommitError is returned and
+	// the renderer remains in the state before the transaction.
 	Commit() error
 }
 
+// HostTxn is implemented by transactions of renderers able to protect the host
+// network stack of the node.
+type HostTxn interface {
+	// RenderHost applies the set of rules for the traffic entering the host
+	// network stack (through the host interconnect) and for the traffic
+	// destined to the node ports (received through the physical interfaces).
+	// The rules have the source IP set and the destination IP unset.
+	// The existing host rules are replaced. Empty set of rules should allow
+	// any traffic.
+	RenderHost(rules []*ContivRule) Txn
+}
+
+// PreNATTxn is implemented by transactions of renderers able to evaluate rules
+// for the traffic sent by pods before the service address translation.
+type PreNATTxn interface {
+	// RenderPreNAT applies the set of rules for the traffic sent by the given
+	// pod, evaluated before the destination of the traffic is translated from
+	// a service frontend to one of the backends.
+	// The rules have the destination IP set and the source IP unset.
+	// The existing pre-NAT rules of the pod are replaced. Empty set of rules
+	// should allow any traffic.
+	RenderPreNAT(pod podmodel.ID, rules []*ContivRule) Txn
+}
+
+// LoadBalancerTxn is implemented by transactions of renderers able to restrict
+// access to services of type LoadBalancer by the client source ranges.
+type LoadBalancerTxn interface {
+	// RenderLoadBalancers applies the set of rules for the traffic received
+	// through the physical interfaces and destined to the load-balancer IPs.
+	// The rules have both the source and the destination IP set.
+	// The existing load-balancer rules are replaced. Empty set of rules should
+	// allow any traffic.
+	RenderLoadBalancers(rules []*ContivRule) Txn
+}
+
+// CommitError is returned by Txn.Commit() when the rendered changes could
+// not be applied into the destination network stack. The renderer reverts
+// both its internal state and the network stack into the state before
+// the transaction. The configuration should be re-synchronized afterwards.
+type CommitError struct {
+	// Renderer is the name of the renderer that failed to commit.
+	Renderer string
+
+	// Err is the error returned by the network stack.
+	Err error
+
+	// RollbackErr is non-nil if the rollback failed as well, in which case
+	// the network stack may contain partially applied changes.
+	RollbackErr error
+}
+
+// Error returns a human-readable description of the failure.
+func (e *CommitError) Error() string {
+	if e.RollbackErr != nil {
+		return fmt.Sprintf("%s renderer failed to commit transaction: %v (rollback failed: %v)",
+			e.Renderer, e.Err, e.RollbackErr)
+	}
+	return fmt.Sprintf("%s renderer failed to commit transaction: %v (rolled back)", e.Renderer, e.Err)
+}
+
 // ContivRule is an n-tuple with the most basic policy rule definition that the
 // destination network stack must support.
 type ContivRule struct {
@@ -67,15 +132,28 @@ type ContivRule struct {
 	Action ActionType
 
 	// L3
-	SrcNetwork  *net.IPNet // empty = match all
-	DestNetwork *net.IPNet // empty = match all
+	// Networks of both IP families can be used, but not mixed in one rule.
+	SrcNetwork  *net.IPNet // empty = match all (both IPv4 and IPv6)
+	DestNetwork *net.IPNet // empty = match all (both IPv4 and IPv6)
 
 	// L4
-	Protocol ProtocolType
-	SrcPort  uint16 // 0 = match all
-	DestPort uint16 // 0 = match all
+	Protocol    ProtocolType
+	SrcPort     uint16 // 0 = match all
+	DestPort    uint16 // 0 = match all
+	DestPortEnd uint16 // 0 = match only DestPort, otherwise DestPort..DestPortEnd
+
+	// ICMP (used instead of ports when Protocol=ICMP)
+	ICMPType int16 // AnyICMP = match all
+	ICMPCode int16 // AnyICMP = match all
+
+	// Policies the rule was generated from (provenance used for statistics,
+	// it is not considered by Compare and it is not rendered).
+	Policies []policymodel.ID
 }
 
+// AnyICMP matches all ICMP types or codes.
+const AnyICMP int16 = -1
+
 // String converts Contiv Rule (pointer) into a human-readable string
 // representation.
 func (cr *ContivRule) String() string {
@@ -88,6 +166,18 @@ func (cr *ContivRule) String() string {
 	if len(cr.DestNetwork.IP) > 0 {
 		dstNet = cr.DestNetwork.String()
 	}
+	if cr.Protocol == ICMP {
+		icmpType := any
+		icmpCode := any
+		if cr.ICMPType != AnyICMP {
+			icmpType = strconv.Itoa(int(cr.ICMPType))
+		}
+		if cr.ICMPCode != AnyICMP {
+			icmpCode = strconv.Itoa(int(cr.ICMPCode))
+		}
+		return fmt.Sprintf("Rule <%s %s -> %s ICMP[type:%s code:%s]>",
+			cr.Action, srcNet, dstNet, icmpType, icmpCode)
+	}
 	srcPort := any
 	dstPort := any
 	if cr.SrcPort != 0 {
@@ -95,6 +185,9 @@ func (cr *ContivRule) String() string {
 	}
 	if cr.DestPort != 0 {
 		dstPort = strconv.Itoa(int(cr.DestPort))
+		if cr.DestPortEnd > cr.DestPort {
+			dstPort += "-" + strconv.Itoa(int(cr.DestPortEnd))
+		}
 	}
 	return fmt.Sprintf("Rule <%s %s[%s:%s] -> %s[%s:%s]>",
 		cr.Action, srcNet, cr.Protocol, srcPort, dstNet, cr.Protocol, dstPort)
@@ -107,6 +200,56 @@ func (cr *ContivRule) Copy() *ContivRule {
 	return crCopy
 }
 
+// Overlaps returns true if there is traffic matched by both this rule and
+// <cr2> (regardless of the action).
+func (cr *ContivRule) Overlaps(cr2 *ContivRule) bool {
+	if !netsOverlap(cr.SrcNetwork, cr2.SrcNetwork) || !netsOverlap(cr.DestNetwork, cr2.DestNetwork) {
+		return false
+	}
+	if cr.Protocol == ANY || cr2.Protocol == ANY {
+		return true
+	}
+	if cr.Protocol != cr2.Protocol {
+		return false
+	}
+	if cr.Protocol == ICMP {
+		return icmpOverlaps(cr.ICMPType, cr2.ICMPType) && icmpOverlaps(cr.ICMPCode, cr2.ICMPCode)
+	}
+	if cr.SrcPort != 0 && cr2.SrcPort != 0 && cr.SrcPort != cr2.SrcPort {
+		return false
+	}
+	first, last := cr.destPortRange()
+	first2, last2 := cr2.destPortRange()
+	return first <= last2 && first2 <= last
+}
+
+// destPortRange returns the first and the last destination port matched
+// by the rule.
+func (cr *ContivRule) destPortRange() (first, last uint16) {
+	switch {
+	case cr.DestPort == 0:
+		return 0, ^uint16(0)
+	case cr.DestPortEnd > cr.DestPort:
+		return cr.DestPort, cr.DestPortEnd
+	}
+	return cr.DestPort, cr.DestPort
+}
+
+// netsOverlap returns true if the networks have at least one IP address
+// in common (empty network = all addresses).
+func netsOverlap(net1, net2 *net.IPNet) bool {
+	if net1 == nil || net2 == nil || len(net1.IP) == 0 || len(net2.IP) == 0 {
+		return true
+	}
+	return net1.Contains(net2.IP.Mask(net2.Mask)) || net2.Contains(net1.IP.Mask(net1.Mask))
+}
+
+// icmpOverlaps returns true if the ICMP type/code values match at least one
+// common value.
+func icmpOverlaps(value1, value2 int16) bool {
+	return value1 == AnyICMP || value2 == AnyICMP || value1 == value2
+}
+
 // Compare returns -1, 0, 1 if this<cr2 or this==cr2 or this>cr2, respectively.
 // Contiv rules have a total order defined on them.
 // It holds that if cr matches subset of the traffic matched by cr2, then cr<cr2.
@@ -123,12 +266,21 @@ func (cr *ContivRule) Compare(cr2 *ContivRule) int {
 	if protocolOrder != 0 {
 		return protocolOrder
 	}
-	if cr.Protocol != ANY {
+	if cr.Protocol == ICMP {
+		typeOrder := compareICMP(cr.ICMPType, cr2.ICMPType)
+		if typeOrder != 0 {
+			return typeOrder
+		}
+		codeOrder := compareICMP(cr.ICMPCode, cr2.ICMPCode)
+		if codeOrder != 0 {
+			return codeOrder
+		}
+	} else if cr.Protocol != ANY {
 		srcPortOrder := utils.ComparePorts(cr.SrcPort, cr2.SrcPort)
 		if srcPortOrder != 0 {
 			return srcPortOrder
 		}
-		dstPortOrder := utils.ComparePorts(cr.DestPort, cr2.DestPort)
+		dstPortOrder := utils.ComparePortRanges(cr.DestPort, cr.DestPortEnd, cr2.DestPort, cr2.DestPortEnd)
 		if dstPortOrder != 0 {
 			return dstPortOrder
 		}
@@ -136,6 +288,21 @@ func (cr *ContivRule) Compare(cr2 *ContivRule) int {
 	return utils.CompareInts(int(cr.Action), int(cr2.Action))
 }
 
+// compareICMP is a comparison function for ICMP types or codes.
+// AnyICMP is higher in the order than any specific value.
+func compareICMP(a, b int16) int {
+	if a == b {
+		return 0
+	}
+	if a == AnyICMP {
+		return 1
+	}
+	if b == AnyICMP {
+		return -1
+	}
+	return utils.CompareInts(int(a), int(b))
+}
+
 // ActionType is either DENY or PERMIT.
 type ActionType int
 
@@ -168,6 +335,12 @@ const (
 	// UDP protocol.
 	UDP
 
+	// SCTP protocol.
+	SCTP
+
+	// ICMP protocol (ICMP type and code are matched instead of ports).
+	ICMP
+
 	// OTHER is some NON-UDP, NON-TCP traffic (used ONLY in unit tests).
 	OTHER
 
@@ -182,6 +355,10 @@ func (at ProtocolType) String() string {
 		return "TCP"
 	case UDP:
 		return "UDP"
+	case SCTP:
+		return "SCTP"
+	case ICMP:
+		return "ICMP"
 	case OTHER:
 		return "OTHER"
 	case ANY:
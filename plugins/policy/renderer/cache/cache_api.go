@@ -149,7 +149,7 @@ type Txn interface {
 
 // PodConfig encapsulates pod configuration (passed through RendererCacheTxn.Update()).
 type PodConfig struct {
-	PodIP   *net.IPNet
+	PodIPs  []*net.IPNet /* one IP address for each IP family */
 	Ingress []*renderer.ContivRule
 	Egress  []*renderer.ContivRule
 	Removed bool /* false can only be inside the transaction; removed pods are no longer tracked by the cache */
@@ -25,6 +25,7 @@ import (
 
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	"github.com/contiv/vpp/plugins/policy/renderer"
+	"github.com/contiv/vpp/plugins/policy/utils"
 )
 
 // RendererCache implements RendererCacheAPI.
@@ -534,31 +535,45 @@ func (rct *RendererCacheTxn) buildLocalTable(dstPodID podmodel.ID, dstPodCfg *Po
 // The ingress with egress is combined such that the resulting rules all follow
 // the cache orientation while the original semantic of policies between the source
 // and the destination pod is maintained.
+// In dual-stack clusters, the traffic between the pods is considered separately
+// for every IP family.
 func (rct *RendererCacheTxn) installLocalRules(dstTable *ContivRuleTable, dstPodCfg *PodConfig, srcPodCfg *PodConfig) {
+	for _, srcPodIP := range srcPodCfg.PodIPs {
+		for _, dstPodIP := range dstPodCfg.PodIPs {
+			if utils.SameIPFamily(srcPodIP, dstPodIP) {
+				rct.installLocalRulesForIPs(dstTable, dstPodCfg, dstPodIP, srcPodCfg, srcPodIP)
+				break
+			}
+		}
+	}
+}
+
+// installLocalRulesForIPs installs local rules for the traffic between the given
+// IP addresses (of the same IP family) of the source and the destination pod.
+func (rct *RendererCacheTxn) installLocalRulesForIPs(dstTable *ContivRuleTable,
+	dstPodCfg *PodConfig, dstPodIP *net.IPNet, srcPodCfg *PodConfig, srcPodIP *net.IPNet) {
 	// Determine the set of accessible ports from the source pod point of view.
-	var srcTCP, srcUDP Ports
-	var srcAny bool
+	var srcPorts *AllowedPorts
 	if rct.cache.orientation == EgressOrientation {
-		srcTCP, srcUDP, srcAny = getAllowedIngressPorts(dstPodCfg.PodIP, srcPodCfg.Ingress)
+		srcPorts = getAllowedIngressPorts(dstPodIP, srcPodCfg.Ingress)
 	} else {
-		srcTCP, srcUDP, srcAny = getAllowedEgressPorts(dstPodCfg.PodIP, srcPodCfg.Egress)
+		srcPorts = getAllowedEgressPorts(dstPodIP, srcPodCfg.Egress)
 	}
 
 	// Determine the set of accessible ports from the destination pod point of view.
-	var dstTCP, dstUDP Ports
-	var dstAny bool
+	var dstPorts *AllowedPorts
 	if rct.cache.orientation == EgressOrientation {
-		dstTCP, dstUDP, dstAny = getAllowedEgressPorts(srcPodCfg.PodIP, dstPodCfg.Egress)
+		dstPorts = getAllowedEgressPorts(srcPodIP, dstPodCfg.Egress)
 	} else {
-		dstTCP, dstUDP, dstAny = getAllowedIngressPorts(srcPodCfg.PodIP, dstPodCfg.Ingress)
+		dstPorts = getAllowedIngressPorts(srcPodIP, dstPodCfg.Ingress)
 	}
 
-	if srcAny {
+	if srcPorts.Any {
 		return
 	}
 
 	// Intersect allowed traffic
-	if dstAny || !dstTCP.IsSubsetOf(srcTCP) || !dstUDP.IsSubsetOf(srcUDP) {
+	if dstPorts.Any || !dstPorts.IsSubsetOf(srcPorts) {
 		// cleanup rule subtree with the root node:
 		// 	(egress orientation)  srcIP:ANY:0 -> 0/0:ANY:0
 		// 	(ingress orientation) 0/0:ANY:0   -> srcIP:ANY:0
@@ -573,18 +588,26 @@ func (rct *RendererCacheTxn) installLocalRules(dstTable *ContivRuleTable, dstPod
 				return false
 			}
 			ones, bits := ipAddr.Mask.Size()
-			if ones != bits || !ipAddr.IP.Equal(srcPodCfg.PodIP.IP) {
+			if ones != bits || !ipAddr.IP.Equal(srcPodIP.IP) {
 				return false
 			}
 			return true
 		})
+		allowed := dstPorts.Intersection(srcPorts)
 		// Intersect TCP.
-		allowedTCP := dstTCP.Intersection(srcTCP)
-		rct.installAllowedPorts(dstTable, srcPodCfg.PodIP, allowedTCP, renderer.TCP)
+		rct.installAllowedPorts(dstTable, srcPodIP, allowed.TCP, allowed.DeniedTCP, renderer.TCP)
 		// Intersect UDP.
-		allowedUDP := dstUDP.Intersection(srcUDP)
-		rct.installAllowedPorts(dstTable, srcPodCfg.PodIP, allowedUDP, renderer.UDP)
-		// Add the "deny-the-rest" rule.
+		rct.installAllowedPorts(dstTable, srcPodIP, allowed.UDP, allowed.DeniedUDP, renderer.UDP)
+		// Intersect SCTP (unless decided by the rule for the rest of the traffic).
+		if !isPortDecisionUniform(allowed.SCTP, allowed.DeniedSCTP, allowed.Other) {
+			rct.installAllowedPorts(dstTable, srcPodIP, allowed.SCTP, allowed.DeniedSCTP, renderer.SCTP)
+		}
+		// Intersect ICMP (unless decided by the rule for the rest of the traffic).
+		if !allowed.ICMP.isUniform(allowed.Other) {
+			rct.installAllowedICMP(dstTable, srcPodIP, allowed.ICMP)
+		}
+		// Add the "deny-the-rest" rule (or "allow-the-rest" if traffic
+		// of other protocols than TCP and UDP is allowed).
 		newRule := &renderer.ContivRule{
 			Action:      renderer.ActionDeny,
 			SrcNetwork:  &net.IPNet{},
@@ -593,10 +616,13 @@ func (rct *RendererCacheTxn) installLocalRules(dstTable *ContivRuleTable, dstPod
 			DestPort:    AnyPort,
 			Protocol:    renderer.ANY,
 		}
+		if allowed.Other {
+			newRule.Action = renderer.ActionPermit
+		}
 		if rct.cache.orientation == EgressOrientation {
-			newRule.SrcNetwork = srcPodCfg.PodIP
+			newRule.SrcNetwork = srcPodIP
 		} else {
-			newRule.DestNetwork = srcPodCfg.PodIP
+			newRule.DestNetwork = srcPodIP
 		}
 		dstTable.InsertRule(newRule)
 	}
@@ -605,7 +631,8 @@ func (rct *RendererCacheTxn) installLocalRules(dstTable *ContivRuleTable, dstPod
 // installAllowedPorts modifies the table content such that the source pod will
 // be able to communicate with the table owner only on the selected allowed ports
 // of a given protocol with the rest being blocked.
-func (rct *RendererCacheTxn) installAllowedPorts(dstTable *ContivRuleTable, srcPodIP *net.IPNet, allowedPorts Ports, protocol renderer.ProtocolType) {
+// If all ports are allowed, <deniedPorts> are blocked explicitly.
+func (rct *RendererCacheTxn) installAllowedPorts(dstTable *ContivRuleTable, srcPodIP *net.IPNet, allowedPorts, deniedPorts Ports, protocol renderer.ProtocolType) {
 	ruleTemplate := &renderer.ContivRule{
 		Action:      renderer.ActionPermit,
 		SrcNetwork:  &net.IPNet{},
@@ -621,20 +648,69 @@ func (rct *RendererCacheTxn) installAllowedPorts(dstTable *ContivRuleTable, srcP
 	}
 
 	if allowedPorts.HasExplicit(AnyPort) {
-		// Allow all traffic for the given protocol.
+		// Block the explicitly denied ports.
+		for ports := range deniedPorts {
+			newRule := ruleTemplate.Copy()
+			newRule.Action = renderer.ActionDeny
+			setDestPorts(newRule, ports)
+			dstTable.InsertRule(newRule)
+		}
+		// Allow all other traffic for the given protocol.
 		dstTable.InsertRule(ruleTemplate)
 		return
 	}
 
 	// Add explicit rule for each allowed port from the intersection
 	// of ingress with egress.
-	for port := range allowedPorts {
+	for ports := range allowedPorts {
 		newRule := ruleTemplate.Copy()
-		newRule.DestPort = port
+		setDestPorts(newRule, ports)
 		dstTable.InsertRule(newRule)
 	}
 }
 
+// installAllowedICMP modifies the table content such that the source pod will
+// be able to send to the table owner only the selected ICMP messages.
+func (rct *RendererCacheTxn) installAllowedICMP(dstTable *ContivRuleTable, srcPodIP *net.IPNet, icmp ICMPDecisions) {
+	for match, allowed := range icmp {
+		newRule := &renderer.ContivRule{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.ICMP,
+			ICMPType:    match.Type,
+			ICMPCode:    match.Code,
+		}
+		if allowed {
+			newRule.Action = renderer.ActionPermit
+		}
+		if rct.cache.orientation == EgressOrientation {
+			newRule.SrcNetwork = srcPodIP
+		} else {
+			newRule.DestNetwork = srcPodIP
+		}
+		dstTable.InsertRule(newRule)
+	}
+}
+
+// isPortDecisionUniform returns true if either all or none of the ports
+// are allowed, as selected by <allowed>.
+func isPortDecisionUniform(allowedPorts, deniedPorts Ports, allowed bool) bool {
+	if allowed {
+		return allowedPorts.HasExplicit(AnyPort) && len(deniedPorts) == 0
+	}
+	return len(allowedPorts) == 0
+}
+
+// setDestPorts sets the destination port(s) of the rule to the given range.
+func setDestPorts(rule *renderer.ContivRule, ports PortRange) {
+	rule.DestPort = ports.First
+	rule.DestPortEnd = 0
+	if ports.Last > ports.First {
+		rule.DestPortEnd = ports.Last
+	}
+}
+
 // rebuildGlobalTable rebuilds the content of the global table for the current state
 // of the transaction.
 func (rct *RendererCacheTxn) rebuildGlobalTable() {
@@ -654,7 +730,8 @@ func (rct *RendererCacheTxn) rebuildGlobalTable() {
 }
 
 // installGlobalRules takes the rules of the given pod with the opposite orientation
-// wrt. the cache and installs them into the global table.
+// wrt. the cache and installs them into the global table - once for every IP
+// address of the pod, skipping rules for the other IP family.
 func (rct *RendererCacheTxn) installGlobalRules(podCfg *PodConfig) {
 	var rules []*renderer.ContivRule
 	if rct.cache.orientation == EgressOrientation {
@@ -662,14 +739,22 @@ func (rct *RendererCacheTxn) installGlobalRules(podCfg *PodConfig) {
 	} else {
 		rules = podCfg.Egress
 	}
-	for _, rule := range rules {
-		ruleCopy := rule.Copy() /* do not change the original config */
-		if rct.cache.orientation == EgressOrientation {
-			ruleCopy.SrcNetwork = podCfg.PodIP
-		} else {
-			ruleCopy.DestNetwork = podCfg.PodIP
+	for _, podIP := range podCfg.PodIPs {
+		for _, rule := range rules {
+			ruleCopy := rule.Copy() /* do not change the original config */
+			if rct.cache.orientation == EgressOrientation {
+				if !utils.SameIPFamily(rule.DestNetwork, podIP) {
+					continue
+				}
+				ruleCopy.SrcNetwork = podIP
+			} else {
+				if !utils.SameIPFamily(rule.SrcNetwork, podIP) {
+					continue
+				}
+				ruleCopy.DestNetwork = podIP
+			}
+			rct.globalTable.InsertRule(ruleCopy)
 		}
-		rct.globalTable.InsertRule(ruleCopy)
 	}
 }
 
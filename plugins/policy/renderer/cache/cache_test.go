@@ -193,7 +193,7 @@ func TestSingleEgressRuleOnePodEgressOrientation(t *testing.T) {
 	egress := []*renderer.ContivRule{Ts1.Rule}
 	localRules := []*renderer.ContivRule{Ts1.Rule, AllowAll()}
 	podCfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(PodIPs[0]),
+		PodIPs:  GetOneHostSubnets(PodIPs[0]),
 		Ingress: ingress,
 		Egress:  egress,
 		Removed: false,
@@ -270,7 +270,7 @@ func TestSingleEgressRuleOnePodIngressOrientation(t *testing.T) {
 	ingress := []*renderer.ContivRule{}
 	egress := []*renderer.ContivRule{Ts1.Rule}
 	podCfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(PodIPs[0]),
+		PodIPs:  GetOneHostSubnets(PodIPs[0]),
 		Ingress: ingress,
 		Egress:  egress,
 		Removed: false,
@@ -351,7 +351,7 @@ func TestSingleIngressRuleOnePodEgressOrientation(t *testing.T) {
 	ingress := []*renderer.ContivRule{Ts2.Rule}
 	egress := []*renderer.ContivRule{}
 	podCfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(PodIPs[0]),
+		PodIPs:  GetOneHostSubnets(PodIPs[0]),
 		Ingress: ingress,
 		Egress:  egress,
 		Removed: false,
@@ -433,7 +433,7 @@ func TestSingleIngressRuleOnePodIngressOrientation(t *testing.T) {
 	egress := []*renderer.ContivRule{}
 	localRules := []*renderer.ContivRule{Ts2.Rule, AllowAll()}
 	podCfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(PodIPs[0]),
+		PodIPs:  GetOneHostSubnets(PodIPs[0]),
 		Ingress: ingress,
 		Egress:  egress,
 		Removed: false,
@@ -516,7 +516,7 @@ func TestMultipleEgressRulesMultiplePodsEgressOrientation(t *testing.T) {
 	for i := range PodIDs {
 		podCfg = append(podCfg,
 			&PodConfig{
-				PodIP:   GetOneHostSubnet(PodIPs[i]),
+				PodIPs:  GetOneHostSubnets(PodIPs[i]),
 				Ingress: ingress,
 				Egress:  egress,
 				Removed: false,
@@ -616,7 +616,7 @@ func TestMultipleEgressRulesMultiplePodsIngressOrientation(t *testing.T) {
 	for i := range PodIDs {
 		podCfg = append(podCfg,
 			&PodConfig{
-				PodIP:   GetOneHostSubnet(PodIPs[i]),
+				PodIPs:  GetOneHostSubnets(PodIPs[i]),
 				Ingress: ingress,
 				Egress:  egress,
 				Removed: false,
@@ -737,7 +737,7 @@ func TestMultipleIngressRulesMultiplePodsEgressOrientation(t *testing.T) {
 	for i := range PodIDs {
 		podCfg = append(podCfg,
 			&PodConfig{
-				PodIP:   GetOneHostSubnet(PodIPs[i]),
+				PodIPs:  GetOneHostSubnets(PodIPs[i]),
 				Ingress: ingress,
 				Egress:  egress,
 				Removed: false,
@@ -854,7 +854,7 @@ func TestMultipleIngressRulesMultiplePodsIngressOrientation(t *testing.T) {
 	for i := range PodIDs {
 		podCfg = append(podCfg,
 			&PodConfig{
-				PodIP:   GetOneHostSubnet(PodIPs[i]),
+				PodIPs:  GetOneHostSubnets(PodIPs[i]),
 				Ingress: ingress,
 				Egress:  egress,
 				Removed: false,
@@ -947,19 +947,19 @@ func TestCombinedRulesEgressOrientation(t *testing.T) {
 	// Prepare test data
 	pods := NewPodSet(Pod1, Pod3)
 	pod1Txn1Cfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress[1:],
 		Egress:  Ts7.Pod1Egress[:2],
 		Removed: false,
 	}
 	pod1Txn2Cfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress,
 		Egress:  Ts7.Pod1Egress,
 		Removed: false,
 	}
 	pod3Cfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod3IP),
+		PodIPs:  GetOneHostSubnets(Pod3IP),
 		Ingress: Ts7.Pod3Ingress,
 		Egress:  Ts7.Pod3Egress,
 		Removed: false,
@@ -1112,19 +1112,19 @@ func TestCombinedRulesIngressOrientation(t *testing.T) {
 	// Prepare test data
 	pods := NewPodSet(Pod1, Pod3)
 	pod1Txn1Cfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress[1:],
 		Egress:  Ts7.Pod1Egress[:2],
 		Removed: false,
 	}
 	pod1Txn2Cfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress,
 		Egress:  Ts7.Pod1Egress,
 		Removed: false,
 	}
 	pod3Cfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod3IP),
+		PodIPs:  GetOneHostSubnets(Pod3IP),
 		Ingress: Ts7.Pod3Ingress,
 		Egress:  Ts7.Pod3Egress,
 		Removed: false,
@@ -1295,14 +1295,14 @@ func TestRemovedPodsEgressOrientation(t *testing.T) {
 	for i := range PodIDs {
 		podCfg = append(podCfg,
 			&PodConfig{
-				PodIP:   GetOneHostSubnet(PodIPs[i]),
+				PodIPs:  GetOneHostSubnets(PodIPs[i]),
 				Ingress: ingress,
 				Egress:  egress,
 				Removed: false,
 			})
 	}
 	pod3CfgTxn2 := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod3IP),
+		PodIPs:  GetOneHostSubnets(Pod3IP),
 		Ingress: EmptyRules,
 		Egress:  EmptyRules,
 		Removed: true,
@@ -1406,14 +1406,14 @@ func TestRemovedPodsIngressOrientation(t *testing.T) {
 	for i := range PodIDs {
 		podCfg = append(podCfg,
 			&PodConfig{
-				PodIP:   GetOneHostSubnet(PodIPs[i]),
+				PodIPs:  GetOneHostSubnets(PodIPs[i]),
 				Ingress: ingress,
 				Egress:  egress,
 				Removed: false,
 			})
 	}
 	pod3CfgTxn2 := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod3IP),
+		PodIPs:  GetOneHostSubnets(Pod3IP),
 		Ingress: EmptyRules,
 		Egress:  EmptyRules,
 		Removed: true,
@@ -1523,19 +1523,19 @@ func TestResyncEgressOrientation(t *testing.T) {
 	// Prepare input data
 	pods := NewPodSet(Pod1, Pod3)
 	pod1ResyncCfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress[1:],
 		Egress:  Ts7.Pod1Egress[:2],
 		Removed: false,
 	}
 	pod1TxnCfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress,
 		Egress:  Ts7.Pod1Egress,
 		Removed: false,
 	}
 	pod3Cfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod3IP),
+		PodIPs:  GetOneHostSubnets(Pod3IP),
 		Ingress: Ts7.Pod3Ingress,
 		Egress:  Ts7.Pod3Egress,
 		Removed: false,
@@ -1673,19 +1673,19 @@ func TestResyncIngressOrientation(t *testing.T) {
 	// Prepare input data
 	pods := NewPodSet(Pod1, Pod3)
 	pod1ResyncCfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress[1:],
 		Egress:  Ts7.Pod1Egress[:2],
 		Removed: false,
 	}
 	pod1TxnCfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod1IP),
+		PodIPs:  GetOneHostSubnets(Pod1IP),
 		Ingress: Ts7.Pod1Ingress,
 		Egress:  Ts7.Pod1Egress,
 		Removed: false,
 	}
 	pod3Cfg := &PodConfig{
-		PodIP:   GetOneHostSubnet(Pod3IP),
+		PodIPs:  GetOneHostSubnets(Pod3IP),
 		Ingress: Ts7.Pod3Ingress,
 		Egress:  Ts7.Pod3Egress,
 		Removed: false,
@@ -1822,3 +1822,325 @@ func TestResyncIngressOrientation(t *testing.T) {
 	verifyCachedPods(ruleCache, pods, pods)
 	verifyGlobalTable(ruleCache.GetGlobalTable(), globalTableTxn2, globalTable, globalRulesTxn2)
 }
+
+func TestDeniedPortEgressOrientation(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestDeniedPortEgressOrientation")
+
+	// Prepare input data.
+	// Pod1 is not allowed to access Pod2 on TCP port 22, the rest is allowed.
+	denySSH := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  &net.IPNet{},
+		DestNetwork: GetOneHostSubnet(Pod2IP),
+		SrcPort:     AnyPort,
+		DestPort:    22,
+		Protocol:    renderer.TCP,
+	}
+	pod1Cfg := &PodConfig{
+		PodIPs:  GetOneHostSubnets(Pod1IP),
+		Ingress: []*renderer.ContivRule{denySSH, AllowAll()},
+		Egress:  []*renderer.ContivRule{},
+		Removed: false,
+	}
+	pod2Cfg := &PodConfig{
+		PodIPs:  GetOneHostSubnets(Pod2IP),
+		Ingress: []*renderer.ContivRule{},
+		Egress:  []*renderer.ContivRule{},
+		Removed: false,
+	}
+
+	denyPod1SSH := allowPodEgress(Pod1IP, 22, renderer.TCP)
+	denyPod1SSH.Action = renderer.ActionDeny
+	allowPod1Other := blockPodEgress(Pod1IP)
+	allowPod1Other.Action = renderer.ActionPermit
+	pod2LocalRules := []*renderer.ContivRule{
+		denyPod1SSH,
+		allowPodEgress(Pod1IP, AnyPort, renderer.TCP),
+		allowPodEgress(Pod1IP, AnyPort, renderer.UDP),
+		allowPod1Other,
+		AllowAll(),
+	}
+	globalRules := modifySrc(Pod1IP, denySSH, AllowAll())
+	globalRules = append(globalRules, AllowAll())
+
+	// Create an instance of RendererCache
+	ruleCache := &RendererCache{
+		Deps: Deps{
+			Log: logger,
+		},
+	}
+	ruleCache.Init(EgressOrientation)
+
+	// Run single transaction.
+	txn := ruleCache.NewTxn()
+	txn.Update(Pod1, pod1Cfg)
+	txn.Update(Pod2, pod2Cfg)
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Verify cache content.
+	verifyPodLocalTable(ruleCache, Pod2, nil, pod2LocalRules, NewPodSet(Pod2))
+	verifyGlobalTable(ruleCache.GetGlobalTable(), nil, nil, globalRules)
+}
+
+func TestPortRangesEgressOrientation(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestPortRangesEgressOrientation")
+
+	// Prepare input data.
+	// Pod1 is allowed to access Pod2 only on TCP ports 8000-8100,
+	// Pod2 accepts connections from Pod1 only on TCP ports 8050-8200.
+	pod1AllowRange := allowPodIngress(Pod2IP, 8000, renderer.TCP)
+	pod1AllowRange.DestPortEnd = 8100
+	pod1Cfg := &PodConfig{
+		PodIPs:  GetOneHostSubnets(Pod1IP),
+		Ingress: []*renderer.ContivRule{pod1AllowRange, blockPodIngress(Pod2IP), AllowAll()},
+		Egress:  []*renderer.ContivRule{},
+		Removed: false,
+	}
+	pod2AllowRange := allowPodEgress(Pod1IP, 8050, renderer.TCP)
+	pod2AllowRange.DestPortEnd = 8200
+	pod2Cfg := &PodConfig{
+		PodIPs:  GetOneHostSubnets(Pod2IP),
+		Ingress: []*renderer.ContivRule{},
+		Egress:  []*renderer.ContivRule{pod2AllowRange, blockPodEgress(Pod1IP)},
+		Removed: false,
+	}
+
+	// Only the intersection of the ranges is allowed.
+	allowPod1Range := allowPodEgress(Pod1IP, 8050, renderer.TCP)
+	allowPod1Range.DestPortEnd = 8100
+	pod2LocalRules := []*renderer.ContivRule{
+		allowPod1Range,
+		blockPodEgress(Pod1IP),
+		AllowAll(),
+	}
+	globalRules := modifySrc(Pod1IP, pod1AllowRange, blockPodIngress(Pod2IP), AllowAll())
+	globalRules = append(globalRules, AllowAll())
+
+	// Create an instance of RendererCache
+	ruleCache := &RendererCache{
+		Deps: Deps{
+			Log: logger,
+		},
+	}
+	ruleCache.Init(EgressOrientation)
+
+	// Run single transaction.
+	txn := ruleCache.NewTxn()
+	txn.Update(Pod1, pod1Cfg)
+	txn.Update(Pod2, pod2Cfg)
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Verify cache content.
+	verifyPodLocalTable(ruleCache, Pod2, nil, pod2LocalRules, NewPodSet(Pod2))
+	verifyGlobalTable(ruleCache.GetGlobalTable(), nil, nil, globalRules)
+}
+
+func TestPortsWithRanges(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	ports := NewPorts(80)
+	ports.AddRange(8000, 8100)
+	gomega.Expect(ports.Has(80)).To(gomega.BeTrue())
+	gomega.Expect(ports.Has(8050)).To(gomega.BeTrue())
+	gomega.Expect(ports.Has(8101)).To(gomega.BeFalse())
+	gomega.Expect(ports.HasExplicit(8050)).To(gomega.BeFalse())
+
+	ports2 := NewPorts()
+	ports2.AddRange(1, 1000)
+	ports2.AddRange(1001, 9000)
+	gomega.Expect(ports.IsSubsetOf(ports2)).To(gomega.BeTrue())
+	gomega.Expect(ports2.IsSubsetOf(ports)).To(gomega.BeFalse())
+
+	ports3 := NewPorts(8080)
+	ports3.AddRange(8090, 8200)
+	intersection := ports.Intersection(ports3)
+	gomega.Expect(intersection).To(gomega.HaveLen(2))
+	gomega.Expect(intersection).To(gomega.HaveKey(PortRange{First: 8080, Last: 8080}))
+	gomega.Expect(intersection).To(gomega.HaveKey(PortRange{First: 8090, Last: 8100}))
+
+	// Denied range splits the allowed range.
+	denied := NewPorts(8050)
+	allowed, _ := intersectPorts(NewPorts(AnyPort), denied, ports, NewPorts())
+	gomega.Expect(allowed).To(gomega.HaveLen(3))
+	gomega.Expect(allowed).To(gomega.HaveKey(PortRange{First: 80, Last: 80}))
+	gomega.Expect(allowed).To(gomega.HaveKey(PortRange{First: 8000, Last: 8049}))
+	gomega.Expect(allowed).To(gomega.HaveKey(PortRange{First: 8051, Last: 8100}))
+}
+
+func TestICMPDecisions(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	icmpRule := func(action renderer.ActionType, icmpType, icmpCode int16) *renderer.ContivRule {
+		return &renderer.ContivRule{
+			Action:      action,
+			SrcNetwork:  &net.IPNet{},
+			DestNetwork: &net.IPNet{},
+			Protocol:    renderer.ICMP,
+			ICMPType:    icmpType,
+			ICMPCode:    icmpCode,
+		}
+	}
+
+	// All except for echo requests are allowed.
+	noPing := evalICMP([]*renderer.ContivRule{
+		icmpRule(renderer.ActionDeny, 8, renderer.AnyICMP),
+		AllowAll(),
+	})
+	gomega.Expect(noPing.isAllowed(ICMPMatch{Type: 8, Code: 0})).To(gomega.BeFalse())
+	gomega.Expect(noPing.isAllowed(ICMPMatch{Type: 0, Code: 0})).To(gomega.BeTrue())
+	gomega.Expect(noPing.AllowsAll()).To(gomega.BeFalse())
+
+	// Only echo request with code 0 and all echo replies are allowed.
+	pingOnly := evalICMP([]*renderer.ContivRule{
+		icmpRule(renderer.ActionPermit, 0, renderer.AnyICMP),
+		icmpRule(renderer.ActionPermit, 8, 0),
+		DenyAll(),
+	})
+	gomega.Expect(pingOnly.isAllowed(ICMPMatch{Type: 8, Code: 0})).To(gomega.BeTrue())
+	gomega.Expect(pingOnly.isAllowed(ICMPMatch{Type: 8, Code: 1})).To(gomega.BeFalse())
+	gomega.Expect(pingOnly.isAllowed(ICMPMatch{Type: 3, Code: 1})).To(gomega.BeFalse())
+
+	gomega.Expect(pingOnly.IsSubsetOf(noPing)).To(gomega.BeFalse())
+	gomega.Expect(noPing.IsSubsetOf(pingOnly)).To(gomega.BeFalse())
+	gomega.Expect(noPing.IsSubsetOf(allowAllICMP())).To(gomega.BeTrue())
+
+	intersection := noPing.Intersection(pingOnly)
+	gomega.Expect(intersection.isAllowed(ICMPMatch{Type: 0, Code: 0})).To(gomega.BeTrue())
+	gomega.Expect(intersection.isAllowed(ICMPMatch{Type: 0, Code: 5})).To(gomega.BeTrue())
+	gomega.Expect(intersection.isAllowed(ICMPMatch{Type: 8, Code: 0})).To(gomega.BeFalse())
+	gomega.Expect(intersection.isAllowed(ICMPMatch{Type: 3, Code: 1})).To(gomega.BeFalse())
+	gomega.Expect(intersection.IsSubsetOf(noPing)).To(gomega.BeTrue())
+	gomega.Expect(intersection.IsSubsetOf(pingOnly)).To(gomega.BeTrue())
+}
+
+func TestICMPEgressOrientation(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestICMPEgressOrientation")
+
+	// Prepare input data.
+	// Pod1 is allowed to send anything to Pod2 except for echo requests,
+	// Pod2 accepts only ICMP from Pod1.
+	pod1DenyPing := blockPodIngress(Pod2IP)
+	pod1DenyPing.Protocol = renderer.ICMP
+	pod1DenyPing.ICMPType = 8
+	pod1DenyPing.ICMPCode = renderer.AnyICMP
+	pod1Cfg := &PodConfig{
+		PodIPs:  GetOneHostSubnets(Pod1IP),
+		Ingress: []*renderer.ContivRule{pod1DenyPing, AllowAll()},
+		Egress:  []*renderer.ContivRule{},
+		Removed: false,
+	}
+	pod2AllowICMP := allowPodEgress(Pod1IP, AnyPort, renderer.ICMP)
+	pod2AllowICMP.ICMPType = renderer.AnyICMP
+	pod2AllowICMP.ICMPCode = renderer.AnyICMP
+	pod2Cfg := &PodConfig{
+		PodIPs:  GetOneHostSubnets(Pod2IP),
+		Ingress: []*renderer.ContivRule{},
+		Egress:  []*renderer.ContivRule{pod2AllowICMP, blockPodEgress(Pod1IP)},
+		Removed: false,
+	}
+
+	// Only ICMP except for echo requests is allowed.
+	denyPod1Ping := blockPodEgress(Pod1IP)
+	denyPod1Ping.Protocol = renderer.ICMP
+	denyPod1Ping.ICMPType = 8
+	denyPod1Ping.ICMPCode = renderer.AnyICMP
+	pod2LocalRules := []*renderer.ContivRule{
+		denyPod1Ping,
+		pod2AllowICMP,
+		blockPodEgress(Pod1IP),
+		AllowAll(),
+	}
+	globalRules := modifySrc(Pod1IP, pod1DenyPing, AllowAll())
+	globalRules = append(globalRules, AllowAll())
+
+	// Create an instance of RendererCache
+	ruleCache := &RendererCache{
+		Deps: Deps{
+			Log: logger,
+		},
+	}
+	ruleCache.Init(EgressOrientation)
+
+	// Run single transaction.
+	txn := ruleCache.NewTxn()
+	txn.Update(Pod1, pod1Cfg)
+	txn.Update(Pod2, pod2Cfg)
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Verify cache content.
+	verifyPodLocalTable(ruleCache, Pod2, nil, pod2LocalRules, NewPodSet(Pod2))
+	verifyGlobalTable(ruleCache.GetGlobalTable(), nil, nil, globalRules)
+}
+
+func TestDualStackEgressOrientation(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestDualStackEgressOrientation")
+
+	// Prepare input data.
+	// Pod1 and Pod2 have both IPv4 and IPv6 address assigned.
+	// Pod1 is not allowed to access Pod2 over IPv6,
+	// Pod2 accepts only TCP:80 from Pod1 (both IP families).
+	const pod1IPv6 = "fd00::1"
+	const pod2IPv6 = "fd00::2"
+	pod1Cfg := &PodConfig{
+		PodIPs:  GetOneHostSubnets(Pod1IP, pod1IPv6),
+		Ingress: []*renderer.ContivRule{blockPodIngress(pod2IPv6), AllowAll()},
+		Egress:  []*renderer.ContivRule{},
+		Removed: false,
+	}
+	pod2Cfg := &PodConfig{
+		PodIPs:  GetOneHostSubnets(Pod2IP, pod2IPv6),
+		Ingress: []*renderer.ContivRule{},
+		Egress: []*renderer.ContivRule{
+			allowPodEgress(Pod1IP, 80, renderer.TCP),
+			allowPodEgress(pod1IPv6, 80, renderer.TCP),
+			blockPodEgress(Pod1IP),
+			blockPodEgress(pod1IPv6),
+		},
+		Removed: false,
+	}
+
+	// Rules of Pod1 are combined only with the addresses of the same IP family.
+	pod2LocalRules := []*renderer.ContivRule{
+		allowPodEgress(Pod1IP, 80, renderer.TCP),
+		blockPodEgress(Pod1IP),
+		blockPodEgress(pod1IPv6),
+		AllowAll(),
+	}
+	globalRules := modifySrc(Pod1IP, AllowAll())
+	globalRules = append(globalRules, modifySrc(pod1IPv6, blockPodIngress(pod2IPv6), AllowAll())...)
+	globalRules = append(globalRules, AllowAll())
+
+	// Create an instance of RendererCache
+	ruleCache := &RendererCache{
+		Deps: Deps{
+			Log: logger,
+		},
+	}
+	ruleCache.Init(EgressOrientation)
+
+	// Run single transaction.
+	txn := ruleCache.NewTxn()
+	txn.Update(Pod1, pod1Cfg)
+	txn.Update(Pod2, pod2Cfg)
+	err := txn.Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Verify cache content.
+	verifyPodLocalTable(ruleCache, Pod2, nil, pod2LocalRules, NewPodSet(Pod2))
+	verifyGlobalTable(ruleCache.GetGlobalTable(), nil, nil, globalRules)
+}
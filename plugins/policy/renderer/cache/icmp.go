@@ -0,0 +1,233 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/contiv/vpp/plugins/policy/renderer"
+)
+
+// ICMPMatch selects ICMP messages by type and code (renderer.AnyICMP matches
+// all types/codes).
+type ICMPMatch struct {
+	Type int16
+	Code int16
+}
+
+// AnyICMPMatch matches all ICMP messages.
+var AnyICMPMatch = ICMPMatch{Type: renderer.AnyICMP, Code: renderer.AnyICMP}
+
+// String converts ICMPMatch into a human-readable string representation.
+func (m ICMPMatch) String() string {
+	icmpType := "ANY"
+	icmpCode := "ANY"
+	if m.Type != renderer.AnyICMP {
+		icmpType = fmt.Sprintf("%d", m.Type)
+	}
+	if m.Code != renderer.AnyICMP {
+		icmpCode = fmt.Sprintf("%d", m.Code)
+	}
+	return icmpType + "/" + icmpCode
+}
+
+// contains returns true if all ICMP messages matched by <m2> are also matched
+// by this instance.
+func (m ICMPMatch) contains(m2 ICMPMatch) bool {
+	return (m.Type == renderer.AnyICMP || m.Type == m2.Type) &&
+		(m.Code == renderer.AnyICMP || m.Code == m2.Code)
+}
+
+// intersection returns the match selecting ICMP messages matched by both this
+// instance and <m2>. Returns false if there are no such messages.
+func (m ICMPMatch) intersection(m2 ICMPMatch) (ICMPMatch, bool) {
+	intersection := m
+	if m.Type == renderer.AnyICMP {
+		intersection.Type = m2.Type
+	} else if m2.Type != renderer.AnyICMP && m2.Type != m.Type {
+		return ICMPMatch{}, false
+	}
+	if m.Code == renderer.AnyICMP {
+		intersection.Code = m2.Code
+	} else if m2.Code != renderer.AnyICMP && m2.Code != m.Code {
+		return ICMPMatch{}, false
+	}
+	return intersection, true
+}
+
+// specificity returns the number of specific (not "any") values in the match.
+func (m ICMPMatch) specificity() int {
+	specificity := 0
+	if m.Type != renderer.AnyICMP {
+		specificity += 2
+	}
+	if m.Code != renderer.AnyICMP {
+		specificity++
+	}
+	return specificity
+}
+
+// ruleICMPMatch returns the ICMP match of the rule. Rules for all protocols
+// match all ICMP messages.
+func ruleICMPMatch(rule *renderer.ContivRule) (ICMPMatch, bool) {
+	switch rule.Protocol {
+	case renderer.ANY:
+		return AnyICMPMatch, true
+	case renderer.ICMP:
+		return ICMPMatch{Type: rule.ICMPType, Code: rule.ICMPCode}, true
+	}
+	return ICMPMatch{}, false
+}
+
+// ICMPDecisions describes which ICMP messages are allowed. The set of matches
+// always includes AnyICMPMatch and it is closed under intersection, therefore
+// every ICMP message is decided by the most specific match that contains it.
+type ICMPDecisions map[ICMPMatch]bool
+
+// allowAllICMP returns decisions allowing all ICMP messages.
+func allowAllICMP() ICMPDecisions {
+	return ICMPDecisions{AnyICMPMatch: true}
+}
+
+// evalICMP evaluates a sorted list of rules matching the peer IP address
+// with the first-match semantic to determine which ICMP messages are allowed.
+// Messages not matched by any rule are denied.
+func evalICMP(sorted []*renderer.ContivRule) ICMPDecisions {
+	matches := []ICMPMatch{AnyICMPMatch}
+	for _, rule := range sorted {
+		if rule.Protocol == renderer.ICMP {
+			matches = append(matches, ICMPMatch{Type: rule.ICMPType, Code: rule.ICMPCode})
+		}
+	}
+	decisions := make(ICMPDecisions)
+	for _, m := range closeICMPMatches(matches) {
+		decisions[m] = false
+		for _, rule := range sorted {
+			if ruleMatch, isICMP := ruleICMPMatch(rule); isICMP && ruleMatch.contains(m) {
+				decisions[m] = rule.Action == renderer.ActionPermit
+				break
+			}
+		}
+	}
+	return decisions
+}
+
+// closeICMPMatches returns the given matches extended with all their
+// intersections.
+func closeICMPMatches(matches []ICMPMatch) []ICMPMatch {
+	closure := make(map[ICMPMatch]struct{})
+	for _, m := range matches {
+		closure[m] = struct{}{}
+	}
+	for changed := true; changed; {
+		changed = false
+		for m := range closure {
+			for m2 := range closure {
+				if intersection, ok := m.intersection(m2); ok {
+					if _, has := closure[intersection]; !has {
+						closure[intersection] = struct{}{}
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	closed := []ICMPMatch{}
+	for m := range closure {
+		closed = append(closed, m)
+	}
+	return closed
+}
+
+// isAllowed returns the decision for ICMP messages selected by <m>, i.e.
+// the decision of the most specific match containing <m>.
+func (d ICMPDecisions) isAllowed(m ICMPMatch) bool {
+	var decisive ICMPMatch
+	var allowed, found bool
+	for m2, decision := range d {
+		if !m2.contains(m) {
+			continue
+		}
+		if !found || m2.specificity() > decisive.specificity() {
+			decisive, allowed, found = m2, decision, true
+		}
+	}
+	return allowed
+}
+
+// combinedMatches returns the closure of matches from both instances.
+func (d ICMPDecisions) combinedMatches(d2 ICMPDecisions) []ICMPMatch {
+	matches := []ICMPMatch{}
+	for m := range d {
+		matches = append(matches, m)
+	}
+	for m := range d2 {
+		matches = append(matches, m)
+	}
+	return closeICMPMatches(matches)
+}
+
+// IsSubsetOf returns true if the ICMP messages allowed by this instance are
+// also allowed by <d2>.
+func (d ICMPDecisions) IsSubsetOf(d2 ICMPDecisions) bool {
+	for _, m := range d.combinedMatches(d2) {
+		if d.isAllowed(m) && !d2.isAllowed(m) {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersection returns decisions allowing ICMP messages which are allowed
+// by both this instance and <d2>.
+func (d ICMPDecisions) Intersection(d2 ICMPDecisions) ICMPDecisions {
+	intersection := make(ICMPDecisions)
+	for _, m := range d.combinedMatches(d2) {
+		intersection[m] = d.isAllowed(m) && d2.isAllowed(m)
+	}
+	return intersection
+}
+
+// AllowsAll returns true if all ICMP messages are allowed.
+func (d ICMPDecisions) AllowsAll() bool {
+	return d.isUniform(true)
+}
+
+// isUniform returns true if all ICMP messages are decided with the given
+// decision.
+func (d ICMPDecisions) isUniform(allowed bool) bool {
+	for _, decision := range d {
+		if decision != allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// String converts ICMPDecisions into a human-readable string
+// representation.
+func (d ICMPDecisions) String() string {
+	matches := []string{}
+	for m, allowed := range d {
+		action := "DENY"
+		if allowed {
+			action = "PERMIT"
+		}
+		matches = append(matches, m.String()+":"+action)
+	}
+	sort.Strings(matches)
+	return fmt.Sprintf("%v", matches)
+}
@@ -15,14 +15,30 @@
 package cache
 
 import (
+	"fmt"
 	"net"
+	"sort"
 
-	"fmt"
 	"github.com/contiv/vpp/plugins/policy/renderer"
 )
 
-// Ports is a set of port numbers.
-type Ports map[uint16]struct{}
+// PortRange is an inclusive range of port numbers.
+// A single port is represented by a range with First equal to Last.
+type PortRange struct {
+	First uint16
+	Last  uint16
+}
+
+// String converts PortRange into a human-readable string representation.
+func (r PortRange) String() string {
+	if r.First == r.Last {
+		return fmt.Sprintf("%d", r.First)
+	}
+	return fmt.Sprintf("%d-%d", r.First, r.Last)
+}
+
+// Ports is a set of port numbers, stored as (possibly overlapping) port ranges.
+type Ports map[PortRange]struct{}
 
 // AnyPort is a constant that represents any port.
 const AnyPort uint16 = 0
@@ -38,21 +54,118 @@ func NewPorts(portNums ...uint16) Ports {
 
 // Add port number into the set
 func (p Ports) Add(port uint16) {
-	p[port] = struct{}{}
+	p[PortRange{First: port, Last: port}] = struct{}{}
+}
+
+// AddRange adds the range of ports <first>..<last> into the set.
+func (p Ports) AddRange(first, last uint16) {
+	if last < first {
+		last = first
+	}
+	p[PortRange{First: first, Last: last}] = struct{}{}
 }
 
 // Has returns true if the given port is in the set.
 func (p Ports) Has(port uint16) bool {
-	return p.HasExplicit(0) || p.HasExplicit(port)
+	if p.HasExplicit(AnyPort) {
+		return true
+	}
+	for r := range p {
+		if r.First <= port && port <= r.Last {
+			return true
+		}
+	}
+	return false
 }
 
 // HasExplicit returns true if the given port is in the set regardless of AnyPort
-// presence.
+// presence and of port ranges.
 func (p Ports) HasExplicit(port uint16) bool {
-	_, has := p[port]
+	_, has := p[PortRange{First: port, Last: port}]
 	return has
 }
 
+// covers returns true if all ports of the range are in the set (AnyPort
+// is not considered).
+func (p Ports) covers(r PortRange) bool {
+	next := int(r.First)
+	for _, mr := range p.merged() {
+		if int(mr.First) > next {
+			break
+		}
+		if int(mr.Last) >= next {
+			next = int(mr.Last) + 1
+		}
+		if next > int(r.Last) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps returns true if at least one port of the range is in the set
+// (AnyPort is not considered).
+func (p Ports) overlaps(r PortRange) bool {
+	for r2 := range p {
+		if r2 == (PortRange{}) {
+			continue
+		}
+		if r2.First <= r.Last && r.First <= r2.Last {
+			return true
+		}
+	}
+	return false
+}
+
+// subtract returns the ranges of ports from <r> which are not in the set
+// (AnyPort is not considered).
+func (p Ports) subtract(r PortRange) []PortRange {
+	remaining := []PortRange{}
+	next := int(r.First)
+	for _, mr := range p.merged() {
+		if int(mr.Last) < next {
+			continue
+		}
+		if int(mr.First) > int(r.Last) {
+			break
+		}
+		if int(mr.First) > next {
+			remaining = append(remaining, PortRange{First: uint16(next), Last: mr.First - 1})
+		}
+		next = int(mr.Last) + 1
+	}
+	if next <= int(r.Last) {
+		remaining = append(remaining, PortRange{First: uint16(next), Last: r.Last})
+	}
+	return remaining
+}
+
+// merged returns the ports of the set as a sorted list of disjoint ranges
+// (without AnyPort).
+func (p Ports) merged() []PortRange {
+	ranges := []PortRange{}
+	for r := range p {
+		if r != (PortRange{}) {
+			ranges = append(ranges, r)
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].First < ranges[j].First
+	})
+	merged := []PortRange{}
+	for _, r := range ranges {
+		last := len(merged) - 1
+		if last >= 0 && int(r.First) <= int(merged[last].Last)+1 {
+			if r.Last > merged[last].Last {
+				merged[last].Last = r.Last
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
 // IsSubsetOf returns true if this set is a subset of <p2>.
 func (p Ports) IsSubsetOf(p2 Ports) bool {
 	if p2.Has(AnyPort) {
@@ -61,8 +174,8 @@ func (p Ports) IsSubsetOf(p2 Ports) bool {
 	if p.Has(AnyPort) {
 		return false
 	}
-	for port := range p {
-		if !p2.Has(port) {
+	for r := range p {
+		if !p2.covers(r) {
 			return false
 		}
 	}
@@ -78,21 +191,36 @@ func (p Ports) Intersection(p2 Ports) Ports {
 		return p
 	}
 	intersection := NewPorts()
-	for port := range p {
-		if p2.Has(port) {
-			intersection.Add(port)
+	for r := range p {
+		for r2 := range p2 {
+			intersection.addIntersection(r, r2)
 		}
 	}
 	return intersection
 }
 
+// addIntersection adds the intersection of two port ranges into the set
+// (if not empty).
+func (p Ports) addIntersection(r, r2 PortRange) {
+	first, last := r.First, r.Last
+	if r2.First > first {
+		first = r2.First
+	}
+	if r2.Last < last {
+		last = r2.Last
+	}
+	if first <= last {
+		p.AddRange(first, last)
+	}
+}
+
 // String converts Ports into a human-readable string
 // representation.
 func (p Ports) String() string {
 	ports := "{"
 	count := 0
-	for port := range p {
-		ports += fmt.Sprintf("%d", port)
+	for r := range p {
+		ports += r.String()
 		count++
 		if count < len(p) {
 			ports += ","
@@ -102,70 +230,258 @@ func (p Ports) String() string {
 	return ports
 }
 
+// AllowedPorts describes the subset of TCP, UDP, SCTP and ICMP traffic allowed
+// between a pair of pods in one direction.
+// If allowed ports of a protocol include AnyPort, then all the ports of that
+// protocol except for the explicitly denied ones are allowed.
+type AllowedPorts struct {
+	TCP        Ports
+	UDP        Ports
+	SCTP       Ports
+	DeniedTCP  Ports
+	DeniedUDP  Ports
+	DeniedSCTP Ports
+	ICMP       ICMPDecisions
+	// Other is true if traffic of protocols other than TCP, UDP and SCTP is allowed.
+	// Unless ICMP is decided by more specific rules, Other applies to ICMP as well.
+	Other bool
+	// Any is true if all the traffic (including non-TCP/UDP) is allowed.
+	Any bool
+}
+
+// newAllowedPorts returns AllowedPorts with nothing allowed and nothing denied.
+func newAllowedPorts() *AllowedPorts {
+	return &AllowedPorts{
+		TCP:        NewPorts(),
+		UDP:        NewPorts(),
+		SCTP:       NewPorts(),
+		DeniedTCP:  NewPorts(),
+		DeniedUDP:  NewPorts(),
+		DeniedSCTP: NewPorts(),
+		ICMP:       ICMPDecisions{AnyICMPMatch: false},
+	}
+}
+
+// allowAllPorts returns AllowedPorts with everything allowed.
+func allowAllPorts() *AllowedPorts {
+	allowed := newAllowedPorts()
+	allowed.TCP.Add(AnyPort)
+	allowed.UDP.Add(AnyPort)
+	allowed.SCTP.Add(AnyPort)
+	allowed.ICMP = allowAllICMP()
+	allowed.Other = true
+	allowed.Any = true
+	return allowed
+}
+
+// isPortSubset returns true if the set of ports allowed by <ports> and <denied>
+// is a subset of ports allowed by <ports2> and <denied2>.
+func isPortSubset(ports, denied, ports2, denied2 Ports) bool {
+	if ports2.HasExplicit(AnyPort) {
+		for r := range denied2 {
+			if ports.HasExplicit(AnyPort) {
+				if !denied.covers(r) {
+					return false
+				}
+			} else if ports.overlaps(r) {
+				return false
+			}
+		}
+		return true
+	}
+	if ports.HasExplicit(AnyPort) {
+		return false
+	}
+	for r := range ports {
+		if !ports2.covers(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// intersectPorts returns the intersection of ports allowed by <ports> and <denied>
+// with ports allowed by <ports2> and <denied2>.
+func intersectPorts(ports, denied, ports2, denied2 Ports) (allowed, allowedDenied Ports) {
+	allowed = NewPorts()
+	allowedDenied = NewPorts()
+	anyPort := ports.HasExplicit(AnyPort)
+	anyPort2 := ports2.HasExplicit(AnyPort)
+	switch {
+	case anyPort && anyPort2:
+		allowed.Add(AnyPort)
+		for r := range denied {
+			allowedDenied[r] = struct{}{}
+		}
+		for r := range denied2 {
+			allowedDenied[r] = struct{}{}
+		}
+	case anyPort:
+		for r := range ports2 {
+			for _, remaining := range denied.subtract(r) {
+				allowed[remaining] = struct{}{}
+			}
+		}
+	case anyPort2:
+		for r := range ports {
+			for _, remaining := range denied2.subtract(r) {
+				allowed[remaining] = struct{}{}
+			}
+		}
+	default:
+		for r := range ports {
+			for r2 := range ports2 {
+				allowed.addIntersection(r, r2)
+			}
+		}
+	}
+	return allowed, allowedDenied
+}
+
+// IsSubsetOf returns true if the traffic allowed by this instance is a subset
+// of the traffic allowed by <ap2>.
+func (ap *AllowedPorts) IsSubsetOf(ap2 *AllowedPorts) bool {
+	if ap2.Any {
+		return true
+	}
+	if ap.Any || (ap.Other && !ap2.Other) {
+		return false
+	}
+	return isPortSubset(ap.TCP, ap.DeniedTCP, ap2.TCP, ap2.DeniedTCP) &&
+		isPortSubset(ap.UDP, ap.DeniedUDP, ap2.UDP, ap2.DeniedUDP) &&
+		isPortSubset(ap.SCTP, ap.DeniedSCTP, ap2.SCTP, ap2.DeniedSCTP) &&
+		ap.ICMP.IsSubsetOf(ap2.ICMP)
+}
+
+// Intersection returns the traffic allowed by both this instance and <ap2>.
+func (ap *AllowedPorts) Intersection(ap2 *AllowedPorts) *AllowedPorts {
+	intersection := &AllowedPorts{Other: ap.Other && ap2.Other, Any: ap.Any && ap2.Any}
+	intersection.TCP, intersection.DeniedTCP = intersectPorts(ap.TCP, ap.DeniedTCP, ap2.TCP, ap2.DeniedTCP)
+	intersection.UDP, intersection.DeniedUDP = intersectPorts(ap.UDP, ap.DeniedUDP, ap2.UDP, ap2.DeniedUDP)
+	intersection.SCTP, intersection.DeniedSCTP = intersectPorts(ap.SCTP, ap.DeniedSCTP, ap2.SCTP, ap2.DeniedSCTP)
+	intersection.ICMP = ap.ICMP.Intersection(ap2.ICMP)
+	return intersection
+}
+
+// String converts AllowedPorts into a human-readable string
+// representation.
+func (ap *AllowedPorts) String() string {
+	return fmt.Sprintf("AllowedPorts <TCP:%s, DeniedTCP:%s, UDP:%s, DeniedUDP:%s, "+
+		"SCTP:%s, DeniedSCTP:%s, ICMP:%s, Other:%t, Any:%t>",
+		ap.TCP, ap.DeniedTCP, ap.UDP, ap.DeniedUDP, ap.SCTP, ap.DeniedSCTP, ap.ICMP, ap.Other, ap.Any)
+}
+
 // getAllowedEgressPorts returns allowed destination UDP and TCP ports for a given
 // source pod IP wrt. egress rules.
-func getAllowedEgressPorts(srcIP *net.IPNet, egress []*renderer.ContivRule) (tcp, udp Ports, any bool) {
-	tcp = NewPorts()
-	udp = NewPorts()
+func getAllowedEgressPorts(srcIP *net.IPNet, egress []*renderer.ContivRule) *AllowedPorts {
+	matching := []*renderer.ContivRule{}
 	hasDeny := false
 	for _, rule := range egress {
 		if rule.Action == renderer.ActionDeny {
-			// This implementation assumes there is only the single default deny-all rule (for ANY protocol),
-			// or no deny rule at all.
 			hasDeny = true
-			continue
 		}
 		if len(rule.SrcNetwork.IP) > 0 && !rule.SrcNetwork.Contains(srcIP.IP) {
 			continue
 		}
-		/* matching ALLOW rule */
-		switch rule.Protocol {
-		case renderer.TCP:
-			tcp.Add(rule.DestPort)
-		case renderer.UDP:
-			udp.Add(rule.DestPort)
-		case renderer.ANY:
-			tcp.Add(AnyPort)
-			udp.Add(AnyPort)
-			any = true
-		}
+		matching = append(matching, rule)
 	}
 	if !hasDeny {
-		return NewPorts(AnyPort), NewPorts(AnyPort), true
+		return allowAllPorts()
 	}
-	return tcp, udp, any
+	return evalAllowedPorts(matching)
 }
 
 // getAllowedIngressPorts returns allowed destination UDP and TCP ports for a given
 // destination pod IP wrt. ingress rules.
-func getAllowedIngressPorts(dstIP *net.IPNet, ingress []*renderer.ContivRule) (tcp, udp Ports, any bool) {
-	tcp = NewPorts()
-	udp = NewPorts()
+func getAllowedIngressPorts(dstIP *net.IPNet, ingress []*renderer.ContivRule) *AllowedPorts {
+	matching := []*renderer.ContivRule{}
 	hasDeny := false
 	for _, rule := range ingress {
 		if rule.Action == renderer.ActionDeny {
-			// This implementation assumes there is only the single default deny-all rule (for ANY protocol),
-			// or no deny rule at all.
 			hasDeny = true
-			continue
 		}
 		if len(rule.DestNetwork.IP) > 0 && !rule.DestNetwork.Contains(dstIP.IP) {
 			continue
 		}
-		/* matching ALLOW rule */
-		switch rule.Protocol {
-		case renderer.TCP:
-			tcp.Add(rule.DestPort)
-		case renderer.UDP:
-			udp.Add(rule.DestPort)
-		case renderer.ANY:
-			tcp.Add(AnyPort)
-			udp.Add(AnyPort)
-			any = true
-		}
+		matching = append(matching, rule)
 	}
 	if !hasDeny {
-		return NewPorts(AnyPort), NewPorts(AnyPort), true
+		return allowAllPorts()
+	}
+	return evalAllowedPorts(matching)
+}
+
+// evalAllowedPorts evaluates rules matching the peer IP address with the first-match
+// semantic (in the order of ContivRule.Compare) to determine the set of allowed ports.
+// Traffic not matched by any rule is denied (the function is used only for lists
+// with at least one deny rule).
+func evalAllowedPorts(rules []*renderer.ContivRule) *AllowedPorts {
+	sorted := make([]*renderer.ContivRule, len(rules))
+	copy(sorted, rules)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Compare(sorted[j]) < 0
+	})
+
+	allowed := newAllowedPorts()
+	allowed.TCP, allowed.DeniedTCP = evalProtocolPorts(sorted, renderer.TCP)
+	allowed.UDP, allowed.DeniedUDP = evalProtocolPorts(sorted, renderer.UDP)
+	allowed.SCTP, allowed.DeniedSCTP = evalProtocolPorts(sorted, renderer.SCTP)
+	allowed.ICMP = evalICMP(sorted)
+
+	// Determine the action for traffic that is neither TCP, UDP nor SCTP.
+	for _, rule := range sorted {
+		if rule.Protocol == renderer.ANY {
+			allowed.Other = rule.Action == renderer.ActionPermit
+			break
+		}
+	}
+	allowed.Any = allowed.Other &&
+		allowed.TCP.HasExplicit(AnyPort) && len(allowed.DeniedTCP) == 0 &&
+		allowed.UDP.HasExplicit(AnyPort) && len(allowed.DeniedUDP) == 0 &&
+		allowed.SCTP.HasExplicit(AnyPort) && len(allowed.DeniedSCTP) == 0 &&
+		allowed.ICMP.AllowsAll()
+	return allowed
+}
+
+// evalProtocolPorts returns allowed and denied ports of the given protocol
+// for a sorted list of rules.
+func evalProtocolPorts(sorted []*renderer.ContivRule, protocol renderer.ProtocolType) (allowed, denied Ports) {
+	allowed = NewPorts()
+	denied = NewPorts()
+	for _, rule := range sorted {
+		if rule.Protocol != protocol && rule.Protocol != renderer.ANY {
+			continue
+		}
+		if rule.Protocol == renderer.ANY || rule.DestPort == AnyPort {
+			// Rule matches all the remaining ports.
+			if rule.Action == renderer.ActionPermit {
+				allowed.Add(AnyPort)
+			} else {
+				// Explicitly denied ports are covered by the deny of the rest.
+				denied = NewPorts()
+			}
+			return allowed, denied
+		}
+		ruleRange := PortRange{First: rule.DestPort, Last: rule.DestPort}
+		if rule.DestPortEnd > rule.DestPort {
+			ruleRange.Last = rule.DestPortEnd
+		}
+		// Ports already decided by a preceding rule are skipped.
+		decided := NewPorts()
+		for r := range allowed {
+			decided[r] = struct{}{}
+		}
+		for r := range denied {
+			decided[r] = struct{}{}
+		}
+		for _, r := range decided.subtract(ruleRange) {
+			if rule.Action == renderer.ActionPermit {
+				allowed[r] = struct{}{}
+			} else {
+				denied[r] = struct{}{}
+			}
+		}
 	}
-	return tcp, udp, any
+	// Traffic not matched by any rule is denied.
+	return allowed, NewPorts()
 }
@@ -38,6 +38,11 @@ const (
 	// filtering for ANY protocol.
 	AnyProtocolSessionRuleTag = "-ANY"
 
+	// MaxPortRangeSessionRules is the maximum number of ports of a port range
+	// expanded into session rules (one rule per port). Rules with larger port
+	// ranges are left to be enforced by the ACL renderer (see ExportSessionRules).
+	MaxPortRangeSessionRules = 256
+
 	// SplitSessionRuleTag is used to mark deny-all rules split into two
 	// (two halves of the IP address space) in order to avoid collision with
 	// the VPP proxy rules.
@@ -202,7 +207,12 @@ func (sr *SessionRule) Compare(sr2 *SessionRule, compareTag bool) int {
 
 // ExportSessionRules converts Contiv rules into the corresponding set of session rules.
 // Set *podID* to nil if the rules are from the global table.
-func ExportSessionRules(rules []*renderer.ContivRule, podID *podmodel.ID, podIP net.IP, contiv contiv.API, log logging.Logger) []*SessionRule {
+// *podIPs* (one for each IP family) are used to determine which IP families
+// should local rules without remote network be installed for.
+// Rules with a port range larger than MaxPortRangeSessionRules are not expanded:
+// deny rules are skipped and permit rules are installed for all the ports,
+// leaving the exact range to the ACL renderer.
+func ExportSessionRules(rules []*renderer.ContivRule, podID *podmodel.ID, podIPs []*net.IPNet, contiv contiv.API, log logging.Logger) []*SessionRule {
 	global := podID == nil
 	// Construct Session rules.
 	sessionRules := []*SessionRule{}
@@ -219,6 +229,23 @@ func ExportSessionRules(rules []*renderer.ContivRule, podID *podmodel.ID, podIP
 	}
 
 	for _, rule := range rules {
+		if rule.Protocol == renderer.SCTP || rule.Protocol == renderer.ICMP {
+			/* VPPTCP stack supports only TCP and UDP sessions */
+			continue
+		}
+		if int(rule.DestPortEnd)-int(rule.DestPort)+1 > MaxPortRangeSessionRules {
+			/* too many session rules, the VPPTCP renderer may only be less restrictive than ACLs */
+			log.WithFields(logging.Fields{
+				"rule":  rule,
+				"limit": MaxPortRangeSessionRules,
+			}).Warn("Port range is too large for session rules, leaving it to ACLs")
+			if rule.Action == renderer.ActionDeny {
+				continue
+			}
+			rule = rule.Copy()
+			rule.DestPort = 0
+			rule.DestPortEnd = 0
+		}
 		if rule.DestPort == 0 && rule.Action == renderer.ActionPermit &&
 			((global && len(rule.SrcNetwork.IP) == 0) || (!global && len(rule.DestNetwork.IP) == 0)) {
 			/* do not install allow-all destination rules - it is the default behaviour in the stack */
@@ -227,7 +254,7 @@ func ExportSessionRules(rules []*renderer.ContivRule, podID *podmodel.ID, podIP
 
 		if !global && len(rule.DestNetwork.IP) > 0 {
 			ones, bits := rule.DestNetwork.Mask.Size()
-			if ones == bits && rule.DestNetwork.IP.Equal(podIP) {
+			if ones == bits && isPodIP(rule.DestNetwork.IP, podIPs) {
 				/* do not install rules that have the same source as destination */
 				continue
 			}
@@ -241,18 +268,73 @@ func ExportSessionRules(rules []*renderer.ContivRule, podID *podmodel.ID, podIP
 			ruleUDP := rule.Copy()
 			ruleUDP.Protocol = renderer.UDP
 			sessionRules = append(sessionRules,
-				convertContivRule(ruleTCP, global, nsIndex, SessionRuleTagPrefix+AnyProtocolSessionRuleTag)...)
+				convertForIPFamilies(ruleTCP, global, nsIndex, podIPs, SessionRuleTagPrefix+AnyProtocolSessionRuleTag)...)
 			sessionRules = append(sessionRules,
-				convertContivRule(ruleUDP, global, nsIndex, SessionRuleTagPrefix+AnyProtocolSessionRuleTag)...)
+				convertForIPFamilies(ruleUDP, global, nsIndex, podIPs, SessionRuleTagPrefix+AnyProtocolSessionRuleTag)...)
+		} else if rule.DestPortEnd > rule.DestPort {
+			// Session rules match only exact port numbers.
+			// Port range is thus implemented as one rule for every port of the range,
+			// which is feasible only for small ranges.
+			for port := uint32(rule.DestPort); port <= uint32(rule.DestPortEnd); port++ {
+				portRule := rule.Copy()
+				portRule.DestPort = uint16(port)
+				portRule.DestPortEnd = 0
+				sessionRules = append(sessionRules,
+					convertForIPFamilies(portRule, global, nsIndex, podIPs, SessionRuleTagPrefix)...)
+			}
 		} else {
-			sessionRules = append(sessionRules, convertContivRule(rule, global, nsIndex, SessionRuleTagPrefix)...)
+			sessionRules = append(sessionRules, convertForIPFamilies(rule, global, nsIndex, podIPs, SessionRuleTagPrefix)...)
 		}
 	}
 	return sessionRules
 }
 
+// isPodIP returns true if *ip* is one of the pod IP addresses.
+func isPodIP(ip net.IP, podIPs []*net.IPNet) bool {
+	for _, podIP := range podIPs {
+		if podIP.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// convertForIPFamilies converts Contiv rule for TCP or UDP into the corresponding
+// set of session rules, installed for every IP family the rule applies to.
+// Session rule matches only one IP family, the family of a rule with networks
+// unspecified is thus determined by the pod IP addresses. Rules of the global
+// table with both networks unspecified are installed only for IPv4.
+func convertForIPFamilies(rule *renderer.ContivRule, global bool, nsIndex uint32, podIPs []*net.IPNet, tagPrefix string) []*SessionRule {
+	if len(rule.SrcNetwork.IP) > 0 {
+		return convertContivRule(rule, global, nsIndex, rule.SrcNetwork.IP.To4() != nil, tagPrefix)
+	}
+	if len(rule.DestNetwork.IP) > 0 {
+		return convertContivRule(rule, global, nsIndex, rule.DestNetwork.IP.To4() != nil, tagPrefix)
+	}
+	if global {
+		return convertContivRule(rule, global, nsIndex, true, tagPrefix)
+	}
+
+	var hasIPv4, hasIPv6 bool
+	for _, podIP := range podIPs {
+		if podIP.IP.To4() != nil {
+			hasIPv4 = true
+		} else {
+			hasIPv6 = true
+		}
+	}
+	sessionRules := []*SessionRule{}
+	if hasIPv4 || !hasIPv6 {
+		sessionRules = append(sessionRules, convertContivRule(rule, global, nsIndex, true, tagPrefix)...)
+	}
+	if hasIPv6 {
+		sessionRules = append(sessionRules, convertContivRule(rule, global, nsIndex, false, tagPrefix)...)
+	}
+	return sessionRules
+}
+
 // convertContivRule converts Contiv rule for TCP or UDP into the corresponding set of session rules.
-func convertContivRule(rule *renderer.ContivRule, global bool, nsIndex uint32, tagPrefix string) []*SessionRule {
+func convertContivRule(rule *renderer.ContivRule, global bool, nsIndex uint32, isIPv4 bool, tagPrefix string) []*SessionRule {
 	// Construct Session rules.
 	sessionRules := []*SessionRule{}
 	sessionRule := &SessionRule{}
@@ -265,12 +347,7 @@ func convertContivRule(rule *renderer.ContivRule, global bool, nsIndex uint32, t
 	}
 
 	// Is IPv4?
-	if global &&
-		(len(rule.SrcNetwork.IP) == 0 || rule.SrcNetwork.IP.To4() != nil) {
-		sessionRule.IsIP4 = 1
-	}
-	if !global &&
-		(len(rule.DestNetwork.IP) == 0 || rule.DestNetwork.IP.To4() != nil) {
+	if isIPv4 {
 		sessionRule.IsIP4 = 1
 	}
 
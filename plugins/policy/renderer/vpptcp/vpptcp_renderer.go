@@ -70,8 +70,13 @@ func (r *Renderer) Init() error {
 	return nil
 }
 
+// CacheView returns read-only view of the renderer cache.
+func (r *Renderer) CacheView() cache.View {
+	return r.cache
+}
+
 // NewTxn starts a new transaction. The rendering executes only after Commit()
-// is called. Rollback is not yet supported however.
+// is called. If the commit fails, the already applied changes are rolled back.
 // If <resync> is enabled, the supplied configuration will completely
 // replace the existing one. Otherwise, the change is performed incrementally,
 // i.e. interfaces not mentioned in the transaction are left unaffected.
@@ -87,7 +92,7 @@ func (r *Renderer) NewTxn(resync bool) renderer.Txn {
 // Render applies the set of ingress & egress rules for a given pod.
 // The existing rules are replaced.
 // Te actual change is performed only after the commit.
-func (art *RendererTxn) Render(pod podmodel.ID, podIP *net.IPNet, ingress []*renderer.ContivRule, egress []*renderer.ContivRule, removed bool) renderer.Txn {
+func (art *RendererTxn) Render(pod podmodel.ID, podIPs []*net.IPNet, ingress []*renderer.ContivRule, egress []*renderer.ContivRule, removed bool) renderer.Txn {
 	art.renderer.Log.WithFields(logging.Fields{
 		"pod":     pod,
 		"ingress": ingress,
@@ -95,7 +100,7 @@ func (art *RendererTxn) Render(pod podmodel.ID, podIP *net.IPNet, ingress []*ren
 	}).Debug("VPPTCP RendererTxn Render()")
 
 	// Add the rules into the transaction.
-	art.cacheTxn.Update(pod, &cache.PodConfig{PodIP: podIP, Ingress: ingress, Egress: egress, Removed: removed})
+	art.cacheTxn.Update(pod, &cache.PodConfig{PodIPs: podIPs, Ingress: ingress, Egress: egress, Removed: removed})
 	return art
 }
 
@@ -159,12 +164,12 @@ func (art *RendererTxn) Commit() error {
 
 		// -> export new session rules
 		newSessionRules := vpptcprule.ExportSessionRules(
-			newContivRules, &pod, podCfg.PodIP.IP, art.renderer.Contiv, art.Log)
+			newContivRules, &pod, podCfg.PodIPs, art.renderer.Contiv, art.Log)
 		added = append(added, newSessionRules...)
 
 		// -> export removed session rules.
 		removedSessionRules := vpptcprule.ExportSessionRules(
-			removedContivRules, &pod, podCfg.PodIP.IP, art.renderer.Contiv, art.Log)
+			removedContivRules, &pod, podCfg.PodIPs, art.renderer.Contiv, art.Log)
 		removed = append(removed, removedSessionRules...)
 	}
 
@@ -180,9 +185,12 @@ func (art *RendererTxn) Commit() error {
 	if len(added) == 0 && len(removed) == 0 {
 		art.renderer.Log.Debug("No changes to be rendered in the transaction")
 	} else {
-		err := art.renderer.updateRules(added, removed)
+		applied, err := art.renderer.updateRules(added, removed)
 		if err != nil {
-			return err
+			// Revert the changes, the cache remains in the state before the transaction.
+			art.renderer.Log.WithField("count", len(applied)).Warn("Rolling back changes of session rules")
+			rollbackErr := art.renderer.revertRules(applied)
+			return &renderer.CommitError{Renderer: "VPPTCP", Err: err, RollbackErr: rollbackErr}
 		}
 	}
 
@@ -262,18 +270,42 @@ func (r *Renderer) makeSessionRuleAddDelReq(rule *vpptcprule.SessionRule, add bo
 	return msg
 }
 
-// updateRules adds/removes selected rules to/from VPP Session rule tables.
-func (r *Renderer) updateRules(add, remove []*vpptcprule.SessionRule) error {
-	const errMsg = "failed to update VPPTCP session rule"
+// sessionRuleChange represents addition or removal of a single session rule.
+type sessionRuleChange struct {
+	rule *vpptcprule.SessionRule
+	add  bool
+}
 
-	// Prepare VPP requests.
-	var requests []govpp.Message
+// updateRules adds/removes selected rules to/from VPP Session rule tables.
+// Returns the list of changes that were successfully applied before a failure
+// (if any).
+func (r *Renderer) updateRules(add, remove []*vpptcprule.SessionRule) (applied []sessionRuleChange, err error) {
+	var changes []sessionRuleChange
 	for _, delRule := range remove {
-		requests = append(requests, r.makeSessionRuleAddDelReq(delRule, false))
+		changes = append(changes, sessionRuleChange{rule: delRule, add: false})
 	}
 	for _, addRule := range add {
-		requests = append(requests, r.makeSessionRuleAddDelReq(addRule, true))
+		changes = append(changes, sessionRuleChange{rule: addRule, add: true})
+	}
+	return r.applyChanges(changes)
+}
+
+// revertRules reverts already applied changes of session rules
+// (in the reverse order).
+func (r *Renderer) revertRules(applied []sessionRuleChange) error {
+	var changes []sessionRuleChange
+	for i := len(applied) - 1; i >= 0; i-- {
+		changes = append(changes, sessionRuleChange{rule: applied[i].rule, add: !applied[i].add})
 	}
+	_, err := r.applyChanges(changes)
+	return err
+}
+
+// applyChanges applies the given changes of session rules via binary API.
+// Requests are sent in bunches, the first bunch with a failed request is still
+// fully processed, but the following bunches are not sent.
+func (r *Renderer) applyChanges(changes []sessionRuleChange) (applied []sessionRuleChange, err error) {
+	const errMsg = "failed to update VPPTCP session rule"
 
 	chanBufSize := 100
 	if r.GoVPPChanBufSize != 0 {
@@ -281,38 +313,41 @@ func (r *Renderer) updateRules(add, remove []*vpptcprule.SessionRule) error {
 	}
 
 	var wasError error
-	for i := 0; i < len(requests); {
+	for i := 0; i < len(changes); {
 		// Send multiple VPP requests at once, but no more than what govpp request
 		// reply channels can buffer.
 		j := 0
 		var reqCtxs []govpp.RequestCtx
-		for ; i+j < len(requests) && j < chanBufSize; j++ {
-			reqCtxs = append(reqCtxs, r.GoVPPChan.SendRequest(requests[i+j]))
+		for ; i+j < len(changes) && j < chanBufSize; j++ {
+			req := r.makeSessionRuleAddDelReq(changes[i+j].rule, changes[i+j].add)
+			reqCtxs = append(reqCtxs, r.GoVPPChan.SendRequest(req))
 		}
-		i += j
 
 		// Wait for VPP responses.
+		// All replies have to be received, otherwise they would be left
+		// in the channel.
 		r.Log.WithField("count", j).Debug("Waiting for a bunch of BIN API responses")
-		for ; j > 0; j-- {
-
+		for k, reqCtx := range reqCtxs {
 			msg := &session.SessionRuleAddDelReply{}
-			err := reqCtxs[len(reqCtxs)-j].ReceiveReply(msg)
+			err := reqCtx.ReceiveReply(msg)
 			if err != nil {
 				r.Log.WithField("err", err).Error(errMsg)
 				wasError = err
-				break
+				continue
 			}
 			if msg.Retval != 0 {
 				r.Log.WithField("retval", msg.Retval).Error(errMsg)
 				wasError = errors.New(errMsg)
-				break
+				continue
 			}
+			applied = append(applied, changes[i+k])
 		}
+		i += j
 		if wasError != nil {
 			break
 		}
 	}
 
-	r.Log.WithField("count", len(requests)).Debug("All BIN API responses were received")
-	return wasError
+	r.Log.WithField("count", len(applied)).Debug("All BIN API responses were received")
+	return applied, wasError
 }
@@ -95,7 +95,7 @@ func TestSingleEgressRuleSinglePod(t *testing.T) {
 	vppTCPRenderer.Init()
 
 	// Execute Renderer transaction.
-	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnet(pod1IP), ingress, egress, false).Commit()
+	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP), ingress, egress, false).Commit()
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
@@ -150,7 +150,7 @@ func TestSingleIngressRuleSinglePod(t *testing.T) {
 	vppTCPRenderer.Init()
 
 	// Execute Renderer transaction.
-	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnet(pod1IP), ingress, egress, false).Commit()
+	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP), ingress, egress, false).Commit()
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
@@ -230,7 +230,7 @@ func TestMultipleRulesSinglePodWithDataChange(t *testing.T) {
 	vppTCPRenderer.Init()
 
 	// Execute first Renderer transaction.
-	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnet(pod1IP), ingress, egress, false).Commit()
+	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP), ingress, egress, false).Commit()
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
@@ -257,7 +257,7 @@ func TestMultipleRulesSinglePodWithDataChange(t *testing.T) {
 	egress2 := []*renderer.ContivRule{egRule2}
 
 	// Execute second first Renderer transaction.
-	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnet(pod1IP), ingress2, egress2, false).Commit()
+	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP), ingress2, egress2, false).Commit()
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
@@ -350,8 +350,8 @@ func TestMultipleRulesMultiplePodsWithDataChange(t *testing.T) {
 
 	// Execute first Renderer transaction for two pods.
 	txn := vppTCPRenderer.NewTxn(false)
-	txn.Render(pod1, GetOneHostSubnet(pod1IP), ingressPod1, egressPod1, false)
-	txn.Render(pod2, GetOneHostSubnet(pod2IP), ingressPod2, egressPod2, false)
+	txn.Render(pod1, GetOneHostSubnets(pod1IP), ingressPod1, egressPod1, false)
+	txn.Render(pod2, GetOneHostSubnets(pod2IP), ingressPod2, egressPod2, false)
 	txn.Commit()
 
 	// Verify output
@@ -387,8 +387,8 @@ func TestMultipleRulesMultiplePodsWithDataChange(t *testing.T) {
 
 	// Execute second Renderer transaction for both pods.
 	txn = vppTCPRenderer.NewTxn(false)
-	txn.Render(pod1, GetOneHostSubnet(pod1IP), ingressPod1, egressPod1, false)
-	txn.Render(pod2, GetOneHostSubnet(pod2IP), ingressPod2, egressPod2, false)
+	txn.Render(pod1, GetOneHostSubnets(pod1IP), ingressPod1, egressPod1, false)
+	txn.Render(pod2, GetOneHostSubnets(pod2IP), ingressPod2, egressPod2, false)
 	txn.Commit()
 
 	// Verify output
@@ -484,8 +484,8 @@ func TestMultipleRulesMultiplePodsWithResync(t *testing.T) {
 
 	// Execute first Renderer transaction for two pods.
 	txn := vppTCPRenderer.NewTxn(false)
-	txn.Render(pod1, GetOneHostSubnet(pod1IP), ingressPod1, egressPod1, false)
-	txn.Render(pod2, GetOneHostSubnet(pod2IP), ingressPod2, egressPod2, false)
+	txn.Render(pod1, GetOneHostSubnets(pod1IP), ingressPod1, egressPod1, false)
+	txn.Render(pod2, GetOneHostSubnets(pod2IP), ingressPod2, egressPod2, false)
 	txn.Commit()
 
 	// Verify output
@@ -531,8 +531,8 @@ func TestMultipleRulesMultiplePodsWithResync(t *testing.T) {
 
 	// Execute RESYNC Renderer transaction for both pods.
 	txn = vppTCPRenderer.NewTxn(true)
-	txn.Render(pod1, GetOneHostSubnet(pod1IP), ingressPod1, egressPod1, false)
-	txn.Render(pod2, GetOneHostSubnet(pod2IP), ingressPod2, egressPod2, false)
+	txn.Render(pod1, GetOneHostSubnets(pod1IP), ingressPod1, egressPod1, false)
+	txn.Render(pod2, GetOneHostSubnets(pod2IP), ingressPod2, egressPod2, false)
 	txn.Commit()
 
 	// Verify output
@@ -610,7 +610,7 @@ func TestSinglePodWithResync(t *testing.T) {
 	vppTCPRenderer.Init()
 
 	// Execute Renderer transaction.
-	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnet(pod1IP), ingress, egress, false).Commit()
+	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP), ingress, egress, false).Commit()
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
@@ -632,7 +632,7 @@ func TestSinglePodWithResync(t *testing.T) {
 	vppTCPRenderer.Init()
 
 	// Execute Renderer RESYNC transaction.
-	vppTCPRenderer.NewTxn(true).Render(pod1, GetOneHostSubnet(pod1IP), ingress, egress, false).Commit()
+	vppTCPRenderer.NewTxn(true).Render(pod1, GetOneHostSubnets(pod1IP), ingress, egress, false).Commit()
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
@@ -667,7 +667,7 @@ func TestSinglePodWithResync(t *testing.T) {
 	egress2 := []*renderer.ContivRule{egRule1, egRule2}
 
 	// Execute Renderer transaction.
-	vppTCPRenderer.NewTxn(true).Render(pod1, GetOneHostSubnet(pod1IP), ingress2, egress2, false).Commit()
+	vppTCPRenderer.NewTxn(true).Render(pod1, GetOneHostSubnets(pod1IP), ingress2, egress2, false).Commit()
 
 	// Verify output
 	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
@@ -678,3 +678,279 @@ func TestSinglePodWithResync(t *testing.T) {
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 80, "192.168.2.0/24", 0, "TCP", "DENY")).To(gomega.BeTrue())
 	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "192.168.3.0/24", 0, "UDP", "ALLOW")).To(gomega.BeTrue())
 }
+
+func TestDualStackSinglePod(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestDualStackSinglePod")
+
+	// Prepare input data.
+	const (
+		namespace      = "default"
+		pod1Name       = "pod1"
+		pod1IP         = "192.168.1.1"
+		pod1IPv6       = "fd00::1"
+		pod1VPPNsIndex = 10
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+
+	ingress := []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  ipNetwork(""),
+			DestNetwork: ipNetwork("fd00:10::/64"),
+			Protocol:    renderer.TCP,
+			SrcPort:     0,
+			DestPort:    22,
+		},
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  ipNetwork(""),
+			DestNetwork: ipNetwork(""),
+			Protocol:    renderer.UDP,
+			SrcPort:     0,
+			DestPort:    53,
+		},
+	}
+	egress := []*renderer.ContivRule{
+		{
+			Action:      renderer.ActionDeny,
+			SrcNetwork:  ipNetwork("fd00:20::/64"),
+			DestNetwork: ipNetwork(""),
+			Protocol:    renderer.TCP,
+			SrcPort:     0,
+			DestPort:    80,
+		},
+	}
+
+	// Prepare mocks.
+	contiv := NewMockContiv()
+	contiv.SetPodAppNsIndex(pod1, pod1VPPNsIndex)
+	mockSessionRules.Clear()
+	vppChan := mockSessionRules.NewVPPChan()
+	gomega.Expect(vppChan).ToNot(gomega.BeNil())
+
+	// Prepare VPPTCP Renderer.
+	vppTCPRenderer := &Renderer{
+		Deps: Deps{
+			Log:              logger,
+			Contiv:           contiv,
+			GoVPPChan:        vppChan,
+			GoVPPChanBufSize: 20,
+		},
+	}
+	vppTCPRenderer.Init()
+
+	// Execute Renderer transaction.
+	vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP, pod1IPv6), ingress, egress, false).Commit()
+
+	// Verify output - rule without remote network is installed (split) for both IP families,
+	// global rule is installed only for the pod address of the same family.
+	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(5))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "fd00:10::/64", 22, "TCP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "0.0.0.0/1", 53, "UDP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "128.0.0.0/1", 53, "UDP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "::/1", 53, "UDP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "8000::/1", 53, "UDP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(1))
+	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IPv6, 80, "fd00:20::/64", 0, "TCP", "DENY")).To(gomega.BeTrue())
+}
+
+func TestRollback(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestRollback")
+
+	// Prepare input data.
+	const (
+		namespace      = "default"
+		pod1Name       = "pod1"
+		pod1IP         = "192.168.1.1"
+		pod1VPPNsIndex = 10
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+
+	inRule1 := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork(""),
+		DestNetwork: ipNetwork("10.0.0.0/8"),
+		Protocol:    renderer.TCP,
+		SrcPort:     0,
+		DestPort:    22,
+	}
+	inRule2 := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork(""),
+		DestNetwork: ipNetwork("10.1.0.0/16"),
+		Protocol:    renderer.TCP,
+		SrcPort:     0,
+		DestPort:    80,
+	}
+	egRule1 := &renderer.ContivRule{
+		Action:      renderer.ActionPermit,
+		SrcNetwork:  ipNetwork("192.168.2.0/24"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+		SrcPort:     0,
+		DestPort:    23,
+	}
+	egRule2 := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork(""),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.UDP,
+		SrcPort:     0,
+		DestPort:    0,
+	}
+
+	ingress := []*renderer.ContivRule{inRule1}
+	egress := []*renderer.ContivRule{egRule1}
+
+	// Prepare mocks.
+	contiv := NewMockContiv()
+	contiv.SetPodAppNsIndex(pod1, pod1VPPNsIndex)
+	mockSessionRules.Clear()
+	vppChan := mockSessionRules.NewVPPChan()
+	gomega.Expect(vppChan).ToNot(gomega.BeNil())
+
+	// Prepare VPPTCP Renderer.
+	vppTCPRenderer := &Renderer{
+		Deps: Deps{
+			Log:              logger,
+			Contiv:           contiv,
+			GoVPPChan:        vppChan,
+			GoVPPChanBufSize: 2,
+		},
+	}
+	vppTCPRenderer.Init()
+
+	// Execute first Renderer transaction.
+	err := vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP), ingress, egress, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Verify output
+	verifyFirstTxn := func() {
+		gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
+		gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(1))
+		gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "10.0.0.0/8", 22, "TCP", "DENY")).To(gomega.BeTrue())
+		gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(1))
+		gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 23, "192.168.2.0/24", 0, "TCP", "ALLOW")).To(gomega.BeTrue())
+	}
+	verifyFirstTxn()
+
+	// Execute second Renderer transaction, which fails in the middle.
+	ingress2 := []*renderer.ContivRule{inRule2}
+	egress2 := []*renderer.ContivRule{egRule2}
+	mockSessionRules.InjectFailure(3)
+	err = vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP), ingress2, egress2, false).Commit()
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	commitErr, isCommitErr := err.(*renderer.CommitError)
+	gomega.Expect(isCommitErr).To(gomega.BeTrue())
+	gomega.Expect(commitErr.Renderer).To(gomega.Equal("VPPTCP"))
+	gomega.Expect(commitErr.RollbackErr).To(gomega.BeNil())
+
+	// The configuration of the first transaction should be restored.
+	verifyFirstTxn()
+
+	// Retry the second transaction without failure.
+	err = vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP), ingress2, egress2, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// Verify output
+	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).NumOfRules()).To(gomega.BeEquivalentTo(1))
+	gomega.Expect(mockSessionRules.LocalTable(pod1VPPNsIndex).HasRule("", 0, "10.1.0.0/16", 80, "TCP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(2))
+	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "0.0.0.0/1", 0, "UDP", "DENY")).To(gomega.BeTrue())
+	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "128.0.0.0/1", 0, "UDP", "DENY")).To(gomega.BeTrue())
+}
+
+func TestPortRange(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestPortRange")
+
+	// Prepare input data.
+	const (
+		namespace      = "default"
+		pod1Name       = "pod1"
+		pod1IP         = "192.168.1.1"
+		pod1VPPNsIndex = 10
+	)
+	pod1 := podmodel.ID{Name: pod1Name, Namespace: namespace}
+
+	smallRange := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork("192.168.2.0/24"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.TCP,
+		SrcPort:     0,
+		DestPort:    8000,
+		DestPortEnd: 8003,
+	}
+	largeRange := &renderer.ContivRule{
+		Action:      renderer.ActionDeny,
+		SrcNetwork:  ipNetwork("192.168.2.0/24"),
+		DestNetwork: ipNetwork(""),
+		Protocol:    renderer.UDP,
+		SrcPort:     0,
+		DestPort:    10000,
+		DestPortEnd: 10000 + vpptcprule.MaxPortRangeSessionRules,
+	}
+
+	// Prepare mocks.
+	contiv := NewMockContiv()
+	contiv.SetPodAppNsIndex(pod1, pod1VPPNsIndex)
+	mockSessionRules.Clear()
+	vppChan := mockSessionRules.NewVPPChan()
+	gomega.Expect(vppChan).ToNot(gomega.BeNil())
+
+	// Prepare VPPTCP Renderer.
+	vppTCPRenderer := &Renderer{
+		Deps: Deps{
+			Log:              logger,
+			Contiv:           contiv,
+			GoVPPChan:        vppChan,
+			GoVPPChanBufSize: 20,
+		},
+	}
+	vppTCPRenderer.Init()
+
+	// Small port range is expanded into one session rule per port.
+	err := vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP),
+		[]*renderer.ContivRule{}, []*renderer.ContivRule{smallRange}, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(4))
+	for port := uint16(8000); port <= 8003; port++ {
+		gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, port, "192.168.2.0/24", 0, "TCP", "DENY")).To(gomega.BeTrue())
+	}
+
+	// Deny rule with a port range over the limit is left to ACLs.
+	err = vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP),
+		[]*renderer.ContivRule{}, []*renderer.ContivRule{smallRange, largeRange}, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(4))
+
+	// Permit rule with a port range over the limit is installed for all the ports.
+	largePermitRange := largeRange.Copy()
+	largePermitRange.Action = renderer.ActionPermit
+	err = vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP),
+		[]*renderer.ContivRule{}, []*renderer.ContivRule{smallRange, largePermitRange}, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(5))
+	gomega.Expect(mockSessionRules.GlobalTable().HasRule(pod1IP, 0, "192.168.2.0/24", 0, "UDP", "ALLOW")).To(gomega.BeTrue())
+
+	// Removal of the rules with port ranges.
+	err = vppTCPRenderer.NewTxn(false).Render(pod1, GetOneHostSubnets(pod1IP),
+		[]*renderer.ContivRule{}, []*renderer.ContivRule{smallRange}, false).Commit()
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(mockSessionRules.GetErrCount()).To(gomega.BeEquivalentTo(0))
+	gomega.Expect(mockSessionRules.GlobalTable().NumOfRules()).To(gomega.BeEquivalentTo(4))
+}
@@ -0,0 +1,35 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+// Package simulator implements offline simulation of network policies:
+// "can pod A reach pod B on port X?".
+//
+// Simulator loads a snapshot of the K8s state (namespaces, pods, K8s and
+// cluster-wide policies) - read from etcd, a JSON file or the policy cache
+// of a running agent - into a private PolicyCache, runs PolicyProcessor and
+// PolicyConfigurator over it as if all the pods were deployed on a single node,
+// and renders the resulting ContivRule tables in-memory only. A simulated
+// flow is then evaluated against the egress table of the source pod and
+// the ingress table of the destination pod; each evaluation returns
+// the verdict, the responsible policies and the matching rule.
+//
+// The simulation is available via the REST API of the agent (the current
+// state of the agent is used unless the request carries a snapshot):
+//
+//	POST /contiv/v1/policy/simulate  {"snapshot": {...}, "query": {...}}
+//
+// and from netctl (see cmdimpl.SimulatePolicyCmd).
+package simulator
@@ -0,0 +1,82 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"net"
+
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+)
+
+// podTables are the rule tables rendered for a single pod.
+// Direction of the tables is from the vswitch point of view.
+type podTables struct {
+	ingress []*renderer.ContivRule
+	egress  []*renderer.ContivRule
+}
+
+// tableRenderer is an in-memory renderer which only stores the rendered
+// rule tables for the evaluation of simulated flows.
+type tableRenderer struct {
+	tables map[podmodel.ID]*podTables
+}
+
+// tableRendererTxn is a transaction of the in-memory renderer.
+type tableRendererTxn struct {
+	renderer *tableRenderer
+	resync   bool
+	tables   map[podmodel.ID]*podTables
+}
+
+// newTableRenderer is a constructor for tableRenderer.
+func newTableRenderer() *tableRenderer {
+	return &tableRenderer{tables: make(map[podmodel.ID]*podTables)}
+}
+
+// NewTxn starts a new transaction.
+func (tr *tableRenderer) NewTxn(resync bool) renderer.Txn {
+	return &tableRendererTxn{
+		renderer: tr,
+		resync:   resync,
+		tables:   make(map[podmodel.ID]*podTables),
+	}
+}
+
+// Render stores the rule tables of the pod (or nil if the pod was removed).
+func (trt *tableRendererTxn) Render(pod podmodel.ID, podIPs []*net.IPNet, ingress []*renderer.ContivRule,
+	egress []*renderer.ContivRule, removed bool) renderer.Txn {
+	if removed {
+		trt.tables[pod] = nil
+	} else {
+		trt.tables[pod] = &podTables{ingress: ingress, egress: egress}
+	}
+	return trt
+}
+
+// Commit applies the rendered tables.
+func (trt *tableRendererTxn) Commit() error {
+	if trt.resync {
+		trt.renderer.tables = make(map[podmodel.ID]*podTables)
+	}
+	for pod, tables := range trt.tables {
+		if tables == nil {
+			delete(trt.renderer.tables, pod)
+			continue
+		}
+		trt.renderer.tables[pod] = tables
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+)
+
+const (
+	// Prefix is versioned prefix for REST urls
+	Prefix = "/contiv/v1/"
+	// SimulateURL is versioned URL (using prefix) for the REST endpoint running
+	// policy simulations
+	SimulateURL = Prefix + "policy/simulate"
+)
+
+// StateSource provides the K8s state to simulate against if the request does
+// not carry a snapshot.
+type StateSource interface {
+	// Snapshot returns the current K8s state.
+	Snapshot() *Snapshot
+}
+
+// Request is the body of a simulation request.
+type Request struct {
+	// Snapshot to simulate against, the current state is used if nil.
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+
+	Query Query `json:"query"`
+}
+
+// RESTHandler exposes policy simulations over the REST API.
+type RESTHandler struct {
+	Deps
+}
+
+// Deps lists dependencies of RESTHandler.
+type Deps struct {
+	Log          logging.Logger
+	HTTPHandlers rest.HTTPHandlers
+	State        StateSource
+}
+
+// Init registers the REST handler.
+func (h *RESTHandler) Init() error {
+	if h.HTTPHandlers == nil {
+		h.Log.Warnf("No http handler provided, skipping registration of policy simulation REST handler")
+		return nil
+	}
+	h.HTTPHandlers.RegisterHTTPHandler(SimulateURL, h.simulateHandler, "POST")
+	h.Log.Infof("Policy simulation REST handler registered: POST %v", SimulateURL)
+	return nil
+}
+
+// simulateHandler runs the simulation described by the request body.
+func (h *RESTHandler) simulateHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		request := &Request{}
+		if err := json.NewDecoder(req.Body).Decode(request); err != nil {
+			formatter.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		snapshot := request.Snapshot
+		if snapshot == nil {
+			snapshot = h.State.Snapshot()
+		}
+		simulator, err := NewSimulator(h.Log, snapshot)
+		if err != nil {
+			formatter.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		result, err := simulator.Simulate(request.Query)
+		if err != nil {
+			formatter.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		formatter.JSON(w, http.StatusOK, result)
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/ligato/cn-infra/logging"
+
+	"github.com/contiv/vpp/plugins/contiv"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/cache"
+	"github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/processor"
+	"github.com/contiv/vpp/plugins/policy/renderer"
+	"github.com/contiv/vpp/plugins/policy/utils"
+)
+
+// Verdict of a simulated flow.
+type Verdict string
+
+const (
+	// VerdictAllowed is returned for flows permitted by the policies.
+	VerdictAllowed Verdict = "ALLOWED"
+
+	// VerdictDenied is returned for flows blocked by the policies.
+	VerdictDenied Verdict = "DENIED"
+
+	// VerdictAudited is returned for flows which are permitted only because
+	// the policies denying them are in the audit mode.
+	VerdictAudited Verdict = "AUDITED"
+)
+
+// Endpoint is one side of a simulated flow: a pod given by
+// "<namespace>/<name>" (or just "<name>" for the default namespace),
+// or an IP address. If both are given, the IP address overrides the IP
+// address of the pod.
+type Endpoint struct {
+	Pod string `json:"pod,omitempty"`
+	IP  string `json:"ip,omitempty"`
+}
+
+// Query describes the flow to simulate.
+type Query struct {
+	Src Endpoint `json:"src"`
+	Dst Endpoint `json:"dst"`
+
+	// Protocol is one of: TCP (default), UDP, SCTP, ICMP or OTHER.
+	Protocol string `json:"protocol,omitempty"`
+
+	// SrcPort is not matched by policies, it only completes the 5-tuple.
+	SrcPort uint16 `json:"srcPort,omitempty"`
+	DstPort uint16 `json:"dstPort,omitempty"`
+
+	// ICMP type and code (Protocol=ICMP only).
+	ICMPType uint8 `json:"icmpType,omitempty"`
+	ICMPCode uint8 `json:"icmpCode,omitempty"`
+}
+
+// Evaluation is the outcome of the evaluation of the flow against the policies
+// of one of the pods.
+type Evaluation struct {
+	// Pod is the evaluated pod: "<namespace>/<name>".
+	Pod string `json:"pod"`
+
+	// Direction of the flow from the pod point of view.
+	Direction string `json:"direction"`
+
+	Verdict Verdict `json:"verdict"`
+
+	// Policies responsible for the verdict.
+	Policies []string `json:"policies,omitempty"`
+
+	// Rule is the first rule of the rendered table matching the flow
+	// (empty if the pod is not isolated in the direction of the flow).
+	Rule string `json:"rule,omitempty"`
+}
+
+// Result of a simulation. The flow is evaluated against the egress policies
+// of the source pod and the ingress policies of the destination pod,
+// evaluation is skipped for endpoints which are not pods.
+type Result struct {
+	Verdict Verdict     `json:"verdict"`
+	Egress  *Evaluation `json:"egress,omitempty"`
+	Ingress *Evaluation `json:"ingress,omitempty"`
+}
+
+// Simulator evaluates flows against the policies of a K8s state snapshot,
+// processed by the same layers as in the agent, but rendered only in-memory.
+type Simulator struct {
+	Log logging.Logger
+
+	cache        *cache.PolicyCache
+	configurator *configurator.PolicyConfigurator
+	renderer     *tableRenderer
+}
+
+// NewSimulator creates a simulator for the given snapshot.
+func NewSimulator(log logging.Logger, snapshot *Snapshot) (*Simulator, error) {
+	s := &Simulator{
+		Log: log,
+		cache: &cache.PolicyCache{
+			Deps: cache.Deps{Log: log},
+		},
+		renderer: newTableRenderer(),
+	}
+	s.configurator = &configurator.PolicyConfigurator{
+		Deps: configurator.Deps{
+			Log:    log,
+			Cache:  s.cache,
+			Contiv: &simContiv{},
+		},
+	}
+	policyProcessor := &processor.PolicyProcessor{
+		Deps: processor.Deps{
+			Log:          log,
+			Cache:        s.cache,
+			Contiv:       &simContiv{},
+			Configurator: s.configurator,
+		},
+	}
+	s.cache.Init()
+	policyProcessor.Init()
+	s.configurator.Init(false)
+	s.configurator.RegisterRenderer(s.renderer)
+
+	resyncEv, err := snapshot.resyncEvent()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Resync(resyncEv); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Simulate evaluates the given flow.
+func (s *Simulator) Simulate(query Query) (*Result, error) {
+	protocol, err := parseProtocol(query.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	srcPod, srcIP, err := s.resolveEndpoint(query.Src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source: %v", err)
+	}
+	dstPod, dstIP, err := s.resolveEndpoint(query.Dst)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination: %v", err)
+	}
+
+	flow := configurator.Flow{
+		Protocol: protocol,
+		DestPort: query.DstPort,
+		ICMPType: query.ICMPType,
+		ICMPCode: query.ICMPCode,
+	}
+	result := &Result{Verdict: VerdictAllowed}
+	if srcPod != nil {
+		flow.Direction = configurator.MatchEgress
+		flow.PeerIP = dstIP
+		result.Egress = s.evaluate(*srcPod, flow)
+		result.Verdict = combineVerdicts(result.Verdict, result.Egress.Verdict)
+	}
+	if dstPod != nil {
+		flow.Direction = configurator.MatchIngress
+		flow.PeerIP = srcIP
+		result.Ingress = s.evaluate(*dstPod, flow)
+		result.Verdict = combineVerdicts(result.Verdict, result.Ingress.Verdict)
+	}
+	s.Log.WithFields(logging.Fields{
+		"query":   query,
+		"verdict": result.Verdict,
+	}).Debug("Simulated flow")
+	return result, nil
+}
+
+// evaluate evaluates the flow against the rendered tables and the policies
+// of the pod.
+func (s *Simulator) evaluate(pod podmodel.ID, flow configurator.Flow) *Evaluation {
+	evaluation := &Evaluation{
+		Pod:       pod.String(),
+		Direction: flow.Direction.String(),
+		Verdict:   VerdictAllowed,
+	}
+
+	// Direction of the rendered tables is from the vswitch point of view.
+	var rule *renderer.ContivRule
+	if tables, rendered := s.renderer.tables[pod]; rendered {
+		table := tables.egress
+		if flow.Direction == configurator.MatchEgress {
+			table = tables.ingress
+		}
+		rule = configurator.MatchingRule(table, flow)
+	}
+	if rule != nil {
+		evaluation.Rule = rule.String()
+		if rule.Action == renderer.ActionDeny {
+			evaluation.Verdict = VerdictDenied
+		}
+	}
+
+	verdict := s.configurator.EvaluateFlow(pod, flow)
+	if verdict.Denied && verdict.Audited && evaluation.Verdict == VerdictAllowed {
+		evaluation.Verdict = VerdictAudited
+	}
+	for _, policy := range verdict.Policies {
+		evaluation.Policies = append(evaluation.Policies, policyName(policy))
+	}
+	return evaluation
+}
+
+// resolveEndpoint returns the pod and the IP address of the endpoint.
+// The returned pod is nil if the endpoint is not a known pod.
+func (s *Simulator) resolveEndpoint(endpoint Endpoint) (pod *podmodel.ID, ip net.IP, err error) {
+	if endpoint.IP != "" {
+		if ip = net.ParseIP(endpoint.IP); ip == nil {
+			return nil, nil, fmt.Errorf("invalid IP address: %s", endpoint.IP)
+		}
+	}
+	if endpoint.Pod == "" {
+		if ip == nil {
+			return nil, nil, fmt.Errorf("either pod or IP address has to be specified")
+		}
+		// Check if the IP address belongs to a pod.
+		for _, podID := range s.cache.ListAllPods() {
+			found, podData := s.cache.LookupPod(podID)
+			if !found {
+				continue
+			}
+			for _, podIP := range utils.GetPodIPAddresses(podData) {
+				if ip.Equal(net.ParseIP(podIP)) {
+					return &podID, ip, nil
+				}
+			}
+		}
+		return nil, ip, nil
+	}
+
+	podID := parsePodID(endpoint.Pod)
+	found, podData := s.cache.LookupPod(podID)
+	if !found {
+		return nil, nil, fmt.Errorf("pod %s not found", podID)
+	}
+	if ip == nil {
+		if ip = net.ParseIP(podData.IpAddress); ip == nil {
+			return nil, nil, fmt.Errorf("pod %s has no IP address assigned", podID)
+		}
+	}
+	return &podID, ip, nil
+}
+
+// parsePodID parses pod ID from "<namespace>/<name>" or "<name>" (default
+// namespace).
+func parsePodID(pod string) podmodel.ID {
+	if parts := strings.SplitN(pod, "/", 2); len(parts) == 2 {
+		return podmodel.ID{Namespace: parts[0], Name: parts[1]}
+	}
+	return podmodel.ID{Namespace: "default", Name: pod}
+}
+
+// parseProtocol converts protocol name into the protocol type used by policy
+// rules.
+func parseProtocol(protocol string) (renderer.ProtocolType, error) {
+	switch strings.ToUpper(protocol) {
+	case "", "TCP":
+		return renderer.TCP, nil
+	case "UDP":
+		return renderer.UDP, nil
+	case "SCTP":
+		return renderer.SCTP, nil
+	case "ICMP":
+		return renderer.ICMP, nil
+	case "OTHER":
+		return renderer.OTHER, nil
+	}
+	return renderer.ANY, fmt.Errorf("unsupported protocol: %s", protocol)
+}
+
+// combineVerdicts returns the verdict of a flow evaluated on both ends.
+func combineVerdicts(verdict1, verdict2 Verdict) Verdict {
+	if verdict1 == VerdictDenied || verdict2 == VerdictDenied {
+		return VerdictDenied
+	}
+	if verdict1 == VerdictAudited || verdict2 == VerdictAudited {
+		return VerdictAudited
+	}
+	return VerdictAllowed
+}
+
+// policyName returns name of the policy as used in the outputs.
+func policyName(policy policymodel.ID) string {
+	if policy.Namespace == "" {
+		// cluster-wide policy
+		return policy.Name
+	}
+	return policy.Namespace + "/" + policy.Name
+}
+
+// simContiv substitutes Contiv plugin for the policy layers. All pods
+// of the snapshot are simulated as if they were deployed on the same node
+// and the NAT-loopback IP is unspecified, i.e. not matching any simulated
+// traffic.
+type simContiv struct {
+	contiv.API
+}
+
+// GetPodNetwork returns network including all IPv4 addresses.
+func (sc *simContiv) GetPodNetwork() *net.IPNet {
+	return &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+}
+
+// GetNatLoopbackIP returns the unspecified IPv4 address.
+func (sc *simContiv) GetNatLoopbackIP() net.IP {
+	return net.IPv4zero
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
+	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+)
+
+func testSnapshot() *Snapshot {
+	pod := func(name, app, ip string) *podmodel.Pod {
+		return &podmodel.Pod{
+			Name:      name,
+			Namespace: "default",
+			Label:     []*podmodel.Pod_Label{{Key: "app", Value: app}},
+			IpAddress: ip,
+		}
+	}
+	return &Snapshot{
+		Namespaces: []*nsmodel.Namespace{{Name: "default"}},
+		Pods: []*podmodel.Pod{
+			pod("web", "web", "10.1.1.1"),
+			pod("client", "client", "10.1.1.2"),
+			pod("other", "other", "10.1.1.3"),
+		},
+		Policies: []*policymodel.Policy{
+			{
+				Name:      "web-ingress",
+				Namespace: "default",
+				Pods: &policymodel.Policy_LabelSelector{
+					MatchLabel: []*policymodel.Policy_Label{{Key: "app", Value: "web"}},
+				},
+				PolicyType: policymodel.Policy_INGRESS,
+				IngressRule: []*policymodel.Policy_IngressRule{
+					{
+						Port: []*policymodel.Policy_Port{
+							{
+								Protocol: policymodel.Policy_Port_TCP,
+								Port: &policymodel.Policy_Port_PortNameOrNumber{
+									Type:   policymodel.Policy_Port_PortNameOrNumber_NUMBER,
+									Number: 80,
+								},
+							},
+						},
+						From: []*policymodel.Policy_Peer{
+							{
+								Pods: &policymodel.Policy_LabelSelector{
+									MatchLabel: []*policymodel.Policy_Label{{Key: "app", Value: "client"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		ClusterPolicies: []*clusterpolicymodel.ClusterPolicy{
+			{
+				Name: "deny-external-dns",
+				Pods: &clusterpolicymodel.ClusterPolicy_LabelSelector{},
+				EgressRule: []*clusterpolicymodel.ClusterPolicy_Rule{
+					{
+						Action: clusterpolicymodel.ClusterPolicy_DENY,
+						Peers: []*clusterpolicymodel.ClusterPolicy_Peer{
+							{IpBlock: &clusterpolicymodel.ClusterPolicy_Peer_IPBlock{Cidr: "8.8.8.8/32"}},
+						},
+						Ports: []*clusterpolicymodel.ClusterPolicy_Port{
+							{Protocol: clusterpolicymodel.ClusterPolicy_Port_UDP, Port: 53},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSimulatePodToPod(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestSimulatePodToPod")
+
+	sim, err := NewSimulator(logger, testSnapshot())
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// allowed by the K8s policy
+	result, err := sim.Simulate(Query{
+		Src:     Endpoint{Pod: "default/client"},
+		Dst:     Endpoint{Pod: "web"},
+		DstPort: 80,
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(result.Verdict).To(gomega.Equal(VerdictAllowed))
+	gomega.Expect(result.Egress).ToNot(gomega.BeNil())
+	gomega.Expect(result.Egress.Pod).To(gomega.Equal("default/client"))
+	gomega.Expect(result.Egress.Verdict).To(gomega.Equal(VerdictAllowed))
+	gomega.Expect(result.Ingress).ToNot(gomega.BeNil())
+	gomega.Expect(result.Ingress.Pod).To(gomega.Equal("default/web"))
+	gomega.Expect(result.Ingress.Direction).To(gomega.Equal("INGRESS"))
+	gomega.Expect(result.Ingress.Verdict).To(gomega.Equal(VerdictAllowed))
+	gomega.Expect(result.Ingress.Policies).To(gomega.Equal([]string{"default/web-ingress"}))
+	gomega.Expect(result.Ingress.Rule).ToNot(gomega.BeEmpty())
+
+	// wrong port
+	result, err = sim.Simulate(Query{
+		Src:     Endpoint{Pod: "default/client"},
+		Dst:     Endpoint{Pod: "default/web"},
+		DstPort: 8080,
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(result.Verdict).To(gomega.Equal(VerdictDenied))
+	gomega.Expect(result.Ingress.Verdict).To(gomega.Equal(VerdictDenied))
+	gomega.Expect(result.Ingress.Policies).To(gomega.Equal([]string{"default/web-ingress"}))
+	gomega.Expect(result.Ingress.Rule).ToNot(gomega.BeEmpty())
+
+	// wrong peer, given by the IP address
+	result, err = sim.Simulate(Query{
+		Src:      Endpoint{IP: "10.1.1.3"},
+		Dst:      Endpoint{Pod: "default/web"},
+		Protocol: "tcp",
+		DstPort:  80,
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(result.Verdict).To(gomega.Equal(VerdictDenied))
+	gomega.Expect(result.Egress).ToNot(gomega.BeNil())
+	gomega.Expect(result.Egress.Pod).To(gomega.Equal("default/other"))
+	gomega.Expect(result.Ingress.Verdict).To(gomega.Equal(VerdictDenied))
+
+	// wrong protocol
+	result, err = sim.Simulate(Query{
+		Src:      Endpoint{Pod: "default/client"},
+		Dst:      Endpoint{Pod: "default/web"},
+		Protocol: "UDP",
+		DstPort:  80,
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(result.Verdict).To(gomega.Equal(VerdictDenied))
+}
+
+func TestSimulateExternal(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestSimulateExternal")
+
+	sim, err := NewSimulator(logger, testSnapshot())
+	gomega.Expect(err).To(gomega.BeNil())
+
+	// denied by the cluster-wide policy
+	result, err := sim.Simulate(Query{
+		Src:      Endpoint{Pod: "default/client"},
+		Dst:      Endpoint{IP: "8.8.8.8"},
+		Protocol: "UDP",
+		DstPort:  53,
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(result.Verdict).To(gomega.Equal(VerdictDenied))
+	gomega.Expect(result.Ingress).To(gomega.BeNil())
+	gomega.Expect(result.Egress.Verdict).To(gomega.Equal(VerdictDenied))
+	gomega.Expect(result.Egress.Policies).To(gomega.Equal([]string{"deny-external-dns"}))
+	gomega.Expect(result.Egress.Rule).ToNot(gomega.BeEmpty())
+
+	// other external traffic is allowed
+	result, err = sim.Simulate(Query{
+		Src:     Endpoint{Pod: "default/client"},
+		Dst:     Endpoint{IP: "8.8.8.8"},
+		DstPort: 443,
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(result.Verdict).To(gomega.Equal(VerdictAllowed))
+
+	// external client is subject to ingress policies
+	result, err = sim.Simulate(Query{
+		Src:     Endpoint{IP: "192.168.1.1"},
+		Dst:     Endpoint{Pod: "default/web"},
+		DstPort: 80,
+	})
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(result.Verdict).To(gomega.Equal(VerdictDenied))
+	gomega.Expect(result.Egress).To(gomega.BeNil())
+}
+
+func TestSimulateInvalidQuery(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestSimulateInvalidQuery")
+
+	sim, err := NewSimulator(logger, testSnapshot())
+	gomega.Expect(err).To(gomega.BeNil())
+
+	_, err = sim.Simulate(Query{Src: Endpoint{Pod: "default/unknown"}, Dst: Endpoint{Pod: "default/web"}})
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	_, err = sim.Simulate(Query{Src: Endpoint{IP: "not-an-ip"}, Dst: Endpoint{Pod: "default/web"}})
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	_, err = sim.Simulate(Query{Src: Endpoint{}, Dst: Endpoint{Pod: "default/web"}})
+	gomega.Expect(err).ToNot(gomega.BeNil())
+	_, err = sim.Simulate(Query{Src: Endpoint{Pod: "client"}, Dst: Endpoint{Pod: "web"}, Protocol: "GRE"})
+	gomega.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestLoadSnapshotFile(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestLoadSnapshotFile")
+
+	dir, err := ioutil.TempDir("", "policy-simulator")
+	gomega.Expect(err).To(gomega.BeNil())
+	defer os.RemoveAll(dir)
+
+	data, err := json.Marshal(testSnapshot())
+	gomega.Expect(err).To(gomega.BeNil())
+	path := filepath.Join(dir, "snapshot.json")
+	gomega.Expect(ioutil.WriteFile(path, data, 0644)).To(gomega.Succeed())
+
+	snapshot, err := LoadSnapshotFile(path)
+	gomega.Expect(err).To(gomega.BeNil())
+	gomega.Expect(snapshot).To(gomega.Equal(testSnapshot()))
+
+	_, err = LoadSnapshotFile(filepath.Join(dir, "missing.json"))
+	gomega.Expect(err).ToNot(gomega.BeNil())
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/syncbase"
+	"github.com/ligato/cn-infra/db/keyval"
+
+	clusterpolicymodel "github.com/contiv/vpp/plugins/crd/handler/clusterpolicy/model"
+	nsmodel "github.com/contiv/vpp/plugins/ksr/model/namespace"
+	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/cache"
+)
+
+// Snapshot is the K8s state relevant to network policies, as published
+// by KSR and the CRD handlers.
+type Snapshot struct {
+	Namespaces      []*nsmodel.Namespace                `json:"namespaces,omitempty"`
+	Pods            []*podmodel.Pod                     `json:"pods,omitempty"`
+	Policies        []*policymodel.Policy               `json:"policies,omitempty"`
+	ClusterPolicies []*clusterpolicymodel.ClusterPolicy `json:"clusterPolicies,omitempty"`
+}
+
+// LoadSnapshotFile loads snapshot from a JSON file.
+func LoadSnapshotFile(path string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot file %s: %v", path, err)
+	}
+	return snapshot, nil
+}
+
+// LoadSnapshotFromDB loads snapshot from the database where KSR stores the K8s
+// state under the given key prefix.
+func LoadSnapshotFromDB(db keyval.BytesBroker, ksrPrefix string) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	err := listValues(db, ksrPrefix+nsmodel.KeyPrefix(), func(data []byte) error {
+		namespace := &nsmodel.Namespace{}
+		snapshot.Namespaces = append(snapshot.Namespaces, namespace)
+		return json.Unmarshal(data, namespace)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = listValues(db, ksrPrefix+podmodel.KeyPrefix(), func(data []byte) error {
+		pod := &podmodel.Pod{}
+		snapshot.Pods = append(snapshot.Pods, pod)
+		return json.Unmarshal(data, pod)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = listValues(db, ksrPrefix+policymodel.KeyPrefix(), func(data []byte) error {
+		policy := &policymodel.Policy{}
+		snapshot.Policies = append(snapshot.Policies, policy)
+		return json.Unmarshal(data, policy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = listValues(db, ksrPrefix+clusterpolicymodel.KeyPrefix(), func(data []byte) error {
+		policy := &clusterpolicymodel.ClusterPolicy{}
+		snapshot.ClusterPolicies = append(snapshot.ClusterPolicies, policy)
+		return json.Unmarshal(data, policy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// listValues calls <decode> for the value of every key under the given prefix.
+func listValues(db keyval.BytesBroker, prefix string, decode func(data []byte) error) error {
+	it, err := db.ListValues(prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list values under %s: %v", prefix, err)
+	}
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			return nil
+		}
+		if err := decode(kv.GetValue()); err != nil {
+			return fmt.Errorf("failed to decode value of %s: %v", kv.GetKey(), err)
+		}
+	}
+}
+
+// SnapshotFromCache builds snapshot of the K8s state stored in the policy
+// cache.
+func SnapshotFromCache(policyCache cache.PolicyCacheAPI) *Snapshot {
+	snapshot := &Snapshot{}
+	for _, id := range policyCache.ListAllNamespaces() {
+		if found, namespace := policyCache.LookupNamespace(id); found {
+			snapshot.Namespaces = append(snapshot.Namespaces, namespace)
+		}
+	}
+	for _, id := range policyCache.ListAllPods() {
+		if found, pod := policyCache.LookupPod(id); found {
+			snapshot.Pods = append(snapshot.Pods, pod)
+		}
+	}
+	for _, id := range policyCache.ListAllPolicies() {
+		if found, policy := policyCache.LookupPolicy(id); found {
+			snapshot.Policies = append(snapshot.Policies, policy)
+		}
+	}
+	for _, name := range policyCache.ListAllClusterPolicies() {
+		if found, policy := policyCache.LookupClusterPolicy(name); found {
+			snapshot.ClusterPolicies = append(snapshot.ClusterPolicies, policy)
+		}
+	}
+	return snapshot
+}
+
+// resyncEvent converts the snapshot into a resync event that can be processed
+// by the policy cache. The values are serialized, therefore the cache does not
+// share data with the snapshot.
+func (s *Snapshot) resyncEvent() (datasync.ResyncEvent, error) {
+	kvs := make(map[string][]datasync.KeyVal)
+	add := func(prefix, key string, value interface{}) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to serialize %s: %v", key, err)
+		}
+		kvs[prefix] = append(kvs[prefix], syncbase.NewKeyValBytes(key, data, 0))
+		return nil
+	}
+	for _, namespace := range s.Namespaces {
+		if err := add(nsmodel.KeyPrefix(), nsmodel.Key(namespace.Name), namespace); err != nil {
+			return nil, err
+		}
+	}
+	for _, pod := range s.Pods {
+		if err := add(podmodel.KeyPrefix(), podmodel.Key(pod.Name, pod.Namespace), pod); err != nil {
+			return nil, err
+		}
+	}
+	for _, policy := range s.Policies {
+		if err := add(policymodel.KeyPrefix(), policymodel.Key(policy.Name, policy.Namespace), policy); err != nil {
+			return nil, err
+		}
+	}
+	for _, policy := range s.ClusterPolicies {
+		if err := add(clusterpolicymodel.KeyPrefix(), clusterpolicymodel.Key(policy.Name), policy); err != nil {
+			return nil, err
+		}
+	}
+	return syncbase.NewResyncEvent(kvs), nil
+}
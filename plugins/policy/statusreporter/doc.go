@@ -0,0 +1,28 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+// Package statusreporter publishes the rendering status of policies applied
+// to the pods of this node into ETCD for contiv-ksr.
+//
+// The status (NodePolicyStatus from the model/status package) lists for every
+// policy the number of selected local pods, the number of generated rules and
+// the error of the last rendering (if any). It is published under
+// the key "policy-status/<node-name>" (with the KSR prefix) shortly after every
+// change of the rendered configuration and periodically refreshed with a TTL,
+// so that the status of a node with a stopped agent eventually expires.
+// contiv-ksr aggregates the status across all nodes and reports it as K8s
+// Events on the policies (see ksr.PolicyStatusUpdater).
+package statusreporter
@@ -0,0 +1,154 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package statusreporter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/logging"
+
+	"github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/model/status"
+)
+
+var (
+	// publishDelay is the delay between a change of the rendered configuration
+	// and the publishing of the status, used to batch subsequent changes.
+	publishDelay = time.Second
+
+	// refreshPeriod is the period in which the published status is refreshed.
+	refreshPeriod = 30 * time.Second
+)
+
+// StatusReporter publishes the status of policies rendered on this node
+// into ETCD for contiv-ksr.
+type StatusReporter struct {
+	Deps
+
+	notifyChan chan struct{}
+	lastStatus *status.NodePolicyStatus // last published status
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Deps lists dependencies of StatusReporter.
+type Deps struct {
+	Log      logging.Logger
+	NodeName string
+	Broker   keyval.ProtoBroker /* expected to be prefixed with the KSR prefix */
+	State    PolicyState
+}
+
+// PolicyState gives access to the status of policies rendered on this node.
+type PolicyState interface {
+	// GetPolicyStatus returns the rendering status of all policies applied
+	// to at least one local pod.
+	GetPolicyStatus() []*configurator.PolicyStatus
+}
+
+// Init starts the go routine publishing the policy status.
+func (sr *StatusReporter) Init() {
+	sr.notifyChan = make(chan struct{}, 1)
+	sr.ctx, sr.cancel = context.WithCancel(context.Background())
+	sr.wg.Add(1)
+	go sr.publishLoop()
+}
+
+// NotifyChan returns the channel through which the reporter should be notified
+// about changes of the policy status (see PolicyConfigurator.WatchPolicyStatus).
+func (sr *StatusReporter) NotifyChan() chan<- struct{} {
+	return sr.notifyChan
+}
+
+// Close stops publishing of the policy status.
+func (sr *StatusReporter) Close() error {
+	sr.cancel()
+	sr.wg.Wait()
+	return nil
+}
+
+// publishLoop publishes the status with a short delay after every notification
+// and periodically refreshes it with a TTL.
+func (sr *StatusReporter) publishLoop() {
+	defer sr.wg.Done()
+	refresh := time.NewTicker(refreshPeriod)
+	defer refresh.Stop()
+	var delayed <-chan time.Time
+
+	for {
+		select {
+		case <-sr.ctx.Done():
+			return
+		case <-sr.notifyChan:
+			if delayed == nil {
+				delayed = time.After(publishDelay)
+			}
+		case <-delayed:
+			delayed = nil
+			sr.publishStatus(false)
+		case <-refresh.C:
+			sr.publishStatus(true)
+		}
+	}
+}
+
+// publishStatus writes the current policy status into ETCD. Unless <refresh>
+// is enabled, the status is written only if it has changed.
+func (sr *StatusReporter) publishStatus(refresh bool) {
+	nodeStatus := sr.getStatus()
+	changed := !proto.Equal(nodeStatus, sr.lastStatus)
+	if !changed && !refresh {
+		return
+	}
+	if changed {
+		sr.Log.WithField("status", nodeStatus).Debug("Publishing changed policy status")
+	}
+
+	err := sr.Broker.Put(status.Key(sr.NodeName), nodeStatus, datasync.WithTTL(3*refreshPeriod))
+	if err != nil {
+		sr.Log.Warnf("Failed to publish policy status: %v", err)
+		return
+	}
+	sr.lastStatus = nodeStatus
+}
+
+// getStatus converts the status of the rendered policies into the data model.
+func (sr *StatusReporter) getStatus() *status.NodePolicyStatus {
+	nodeStatus := &status.NodePolicyStatus{NodeName: sr.NodeName}
+	for _, policyStatus := range sr.State.GetPolicyStatus() {
+		kind := status.PolicyStatus_NETWORK_POLICY
+		if policyStatus.ClusterWide {
+			kind = status.PolicyStatus_CLUSTER_POLICY
+		}
+		nodeStatus.Policies = append(nodeStatus.Policies, &status.PolicyStatus{
+			Kind:         kind,
+			Name:         policyStatus.Policy.Name,
+			Namespace:    policyStatus.Policy.Namespace,
+			Error:        policyStatus.Error,
+			SelectedPods: uint32(policyStatus.SelectedPods),
+			Rules:        uint32(policyStatus.Rules),
+		})
+	}
+	return nodeStatus
+}
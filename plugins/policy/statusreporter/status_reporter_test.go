@@ -0,0 +1,145 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package statusreporter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	policymodel "github.com/contiv/vpp/plugins/ksr/model/policy"
+	"github.com/contiv/vpp/plugins/policy/configurator"
+	"github.com/contiv/vpp/plugins/policy/model/status"
+)
+
+const testNodeName = "k8s-worker1"
+
+// mockBroker records values written by Put.
+type mockBroker struct {
+	keyval.ProtoBroker // other methods are not used
+
+	sync.Mutex
+	puts map[string][]proto.Message
+}
+
+func (mb *mockBroker) Put(key string, data proto.Message, opts ...datasync.PutOption) error {
+	mb.Lock()
+	defer mb.Unlock()
+	mb.puts[key] = append(mb.puts[key], data)
+	return nil
+}
+
+func (mb *mockBroker) getPuts(key string) []proto.Message {
+	mb.Lock()
+	defer mb.Unlock()
+	return append([]proto.Message{}, mb.puts[key]...)
+}
+
+// mockState returns preset policy status.
+type mockState struct {
+	sync.Mutex
+	policyStatus []*configurator.PolicyStatus
+}
+
+func (ms *mockState) GetPolicyStatus() []*configurator.PolicyStatus {
+	ms.Lock()
+	defer ms.Unlock()
+	return ms.policyStatus
+}
+
+func (ms *mockState) setPolicyStatus(policyStatus ...*configurator.PolicyStatus) {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.policyStatus = policyStatus
+}
+
+func TestStatusReporter(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	publishDelay = 10 * time.Millisecond
+
+	broker := &mockBroker{puts: make(map[string][]proto.Message)}
+	state := &mockState{}
+	state.setPolicyStatus(
+		&configurator.PolicyStatus{
+			Policy:       policymodel.ID{Name: "policy1", Namespace: "default"},
+			SelectedPods: 2,
+			Rules:        4,
+		},
+		&configurator.PolicyStatus{
+			Policy:       policymodel.ID{Name: "policy2"},
+			ClusterWide:  true,
+			SelectedPods: 1,
+			Rules:        1,
+			Error:        "failed to commit",
+		})
+
+	reporter := &StatusReporter{
+		Deps: Deps{
+			Log:      logrus.DefaultLogger(),
+			NodeName: testNodeName,
+			Broker:   broker,
+			State:    state,
+		},
+	}
+	reporter.Init()
+	defer reporter.Close()
+	key := status.Key(testNodeName)
+
+	// Status is published shortly after the notification.
+	reporter.NotifyChan() <- struct{}{}
+	gomega.Eventually(func() []proto.Message { return broker.getPuts(key) }).Should(gomega.HaveLen(1))
+	published := broker.getPuts(key)[0].(*status.NodePolicyStatus)
+	gomega.Expect(published.NodeName).To(gomega.Equal(testNodeName))
+	gomega.Expect(published.Policies).To(gomega.HaveLen(2))
+	gomega.Expect(published.Policies[0]).To(gomega.Equal(&status.PolicyStatus{
+		Kind:         status.PolicyStatus_NETWORK_POLICY,
+		Name:         "policy1",
+		Namespace:    "default",
+		SelectedPods: 2,
+		Rules:        4,
+	}))
+	gomega.Expect(published.Policies[1]).To(gomega.Equal(&status.PolicyStatus{
+		Kind:         status.PolicyStatus_CLUSTER_POLICY,
+		Name:         "policy2",
+		Error:        "failed to commit",
+		SelectedPods: 1,
+		Rules:        1,
+	}))
+
+	// Unchanged status is not re-published.
+	reporter.NotifyChan() <- struct{}{}
+	gomega.Consistently(func() []proto.Message { return broker.getPuts(key) }, 5*publishDelay).Should(gomega.HaveLen(1))
+
+	// Changed status is published.
+	state.setPolicyStatus(&configurator.PolicyStatus{
+		Policy:       policymodel.ID{Name: "policy1", Namespace: "default"},
+		SelectedPods: 1,
+		Rules:        2,
+	})
+	reporter.NotifyChan() <- struct{}{}
+	gomega.Eventually(func() []proto.Message { return broker.getPuts(key) }).Should(gomega.HaveLen(2))
+	published = broker.getPuts(key)[1].(*status.NodePolicyStatus)
+	gomega.Expect(published.Policies).To(gomega.HaveLen(1))
+	gomega.Expect(published.Policies[0].SelectedPods).To(gomega.BeEquivalentTo(1))
+}
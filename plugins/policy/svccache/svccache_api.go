@@ -0,0 +1,43 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package svccache
+
+import (
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+	svcrenderer "github.com/contiv/vpp/plugins/service/renderer"
+)
+
+// ServiceCacheAPI defines API of Service Cache - a cache of K8s services
+// referenced by service-aware policy rules.
+// The cache is registered as a renderer into the service plugin, learning
+// the frontends (cluster and external IPs with service ports) and backends
+// (endpoints with target ports) of every service from the state of the service
+// processor.
+type ServiceCacheAPI interface {
+	// LookupService returns the current frontends and backends of the given
+	// service (nil if the service is not known).
+	// The returned instance must not be modified.
+	LookupService(service svcmodel.ID) *svcrenderer.ContivService
+
+	// ListServices returns IDs of all known services, ordered by the ID.
+	ListServices() []svcmodel.ID
+
+	// Watch subscribes for notifications about changes in services.
+	// Each notification carries the list of services whose frontends
+	// or backends have changed.
+	Watch(subscriber chan<- []svcmodel.ID)
+}
@@ -0,0 +1,178 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package svccache
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/ligato/cn-infra/logging"
+
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+	svcrenderer "github.com/contiv/vpp/plugins/service/renderer"
+)
+
+// ServiceCache implements ServiceCacheAPI and ServiceRendererAPI
+// (of the service plugin).
+type ServiceCache struct {
+	Deps
+
+	sync.Mutex
+	services    map[svcmodel.ID]*svcrenderer.ContivService
+	subscribers []chan<- []svcmodel.ID
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Deps lists dependencies of ServiceCache.
+type Deps struct {
+	Log logging.Logger
+}
+
+// Init initializes the service cache.
+func (sc *ServiceCache) Init() error {
+	sc.services = make(map[svcmodel.ID]*svcrenderer.ContivService)
+	sc.ctx, sc.cancel = context.WithCancel(context.Background())
+	return nil
+}
+
+// Close stops sending of notifications.
+func (sc *ServiceCache) Close() error {
+	sc.cancel()
+	return nil
+}
+
+// LookupService returns the current frontends and backends of the given service.
+func (sc *ServiceCache) LookupService(service svcmodel.ID) *svcrenderer.ContivService {
+	sc.Lock()
+	defer sc.Unlock()
+	return sc.services[service]
+}
+
+// ListServices returns IDs of all known services, ordered by the ID.
+func (sc *ServiceCache) ListServices() []svcmodel.ID {
+	sc.Lock()
+	defer sc.Unlock()
+	services := make([]svcmodel.ID, 0, len(sc.services))
+	for id := range sc.services {
+		services = append(services, id)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].String() < services[j].String()
+	})
+	return services
+}
+
+// Watch subscribes for notifications about changes in services.
+func (sc *ServiceCache) Watch(subscriber chan<- []svcmodel.ID) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.subscribers = append(sc.subscribers, subscriber)
+}
+
+// AddService learns a newly added service.
+func (sc *ServiceCache) AddService(service *svcrenderer.ContivService) error {
+	sc.update(map[svcmodel.ID]*svcrenderer.ContivService{service.ID: service}, false)
+	return nil
+}
+
+// UpdateService learns the updated frontends and backends of a service.
+func (sc *ServiceCache) UpdateService(oldService, newService *svcrenderer.ContivService) error {
+	sc.update(map[svcmodel.ID]*svcrenderer.ContivService{newService.ID: newService}, false)
+	return nil
+}
+
+// DeleteService forgets a removed service.
+func (sc *ServiceCache) DeleteService(service *svcrenderer.ContivService) error {
+	sc.update(map[svcmodel.ID]*svcrenderer.ContivService{service.ID: nil}, false)
+	return nil
+}
+
+// UpdateNodePortServices is not used by the cache (node ports are not
+// referenced by policies).
+func (sc *ServiceCache) UpdateNodePortServices(nodeIPs *svcrenderer.IPAddresses, npServices []*svcrenderer.ContivService) error {
+	return nil
+}
+
+// UpdateLocalFrontendIfs is not used by the cache.
+func (sc *ServiceCache) UpdateLocalFrontendIfs(oldIfNames, newIfNames svcrenderer.Interfaces) error {
+	return nil
+}
+
+// UpdateLocalBackendIfs is not used by the cache.
+func (sc *ServiceCache) UpdateLocalBackendIfs(oldIfNames, newIfNames svcrenderer.Interfaces) error {
+	return nil
+}
+
+// Resync replaces the content of the cache with the given snapshot of services.
+func (sc *ServiceCache) Resync(resyncEv *svcrenderer.ResyncEventData) error {
+	services := make(map[svcmodel.ID]*svcrenderer.ContivService)
+	for _, service := range resyncEv.Services {
+		services[service.ID] = service
+	}
+	sc.update(services, true)
+	return nil
+}
+
+// update applies the given changes into the cache (nil service = removed)
+// and notifies subscribers about services that have actually changed.
+// With resync, services not mentioned in the changes are removed.
+func (sc *ServiceCache) update(services map[svcmodel.ID]*svcrenderer.ContivService, resync bool) {
+	sc.Lock()
+	if resync {
+		for id := range sc.services {
+			if _, hasService := services[id]; !hasService {
+				services[id] = nil
+			}
+		}
+	}
+	changed := []svcmodel.ID{}
+	for id, service := range services {
+		prevService, hadService := sc.services[id]
+		if service == nil {
+			if hadService {
+				delete(sc.services, id)
+				changed = append(changed, id)
+			}
+			continue
+		}
+		if !hadService || !reflect.DeepEqual(prevService, service) {
+			sc.services[id] = service
+			changed = append(changed, id)
+		}
+	}
+	subscribers := append([]chan<- []svcmodel.ID{}, sc.subscribers...)
+	sc.Unlock()
+
+	if len(changed) == 0 {
+		return
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].String() < changed[j].String()
+	})
+	sc.Log.WithField("services", changed).Debug("Services have changed")
+	for _, subscriber := range subscribers {
+		select {
+		case subscriber <- changed:
+		case <-sc.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package svccache
+
+import (
+	"net"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+	svcrenderer "github.com/contiv/vpp/plugins/service/renderer"
+)
+
+// newService returns service with one port and the given backends.
+func newService(name string, clusterIP string, backendIPs ...string) *svcrenderer.ContivService {
+	service := svcrenderer.NewContivService()
+	service.ID = svcmodel.ID{Name: name, Namespace: "default"}
+	service.ExternalIPs.Add(net.ParseIP(clusterIP))
+	service.Ports["http"] = &svcrenderer.ServicePort{Protocol: svcrenderer.TCP, Port: 80}
+	for _, backendIP := range backendIPs {
+		service.Backends["http"] = append(service.Backends["http"],
+			&svcrenderer.ServiceBackend{IP: net.ParseIP(backendIP), Port: 8080})
+	}
+	return service
+}
+
+func TestServiceCache(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	svcCache := &ServiceCache{Deps: Deps{Log: logger}}
+	gomega.Expect(svcCache.Init()).To(gomega.BeNil())
+	defer svcCache.Close()
+	changes := make(chan []svcmodel.ID, 10)
+	svcCache.Watch(changes)
+
+	web := newService("web", "10.96.0.10", "10.1.1.1")
+	db := newService("db", "10.96.0.20", "10.1.1.2")
+	webID := web.ID
+	dbID := db.ID
+
+	// Resync.
+	gomega.Expect(svcCache.Resync(&svcrenderer.ResyncEventData{
+		Services: []*svcrenderer.ContivService{web},
+	})).To(gomega.BeNil())
+	gomega.Expect(<-changes).To(gomega.Equal([]svcmodel.ID{webID}))
+	gomega.Expect(svcCache.LookupService(webID)).To(gomega.Equal(web))
+	gomega.Expect(svcCache.LookupService(dbID)).To(gomega.BeNil())
+
+	// Add service.
+	gomega.Expect(svcCache.AddService(db)).To(gomega.BeNil())
+	gomega.Expect(<-changes).To(gomega.Equal([]svcmodel.ID{dbID}))
+	gomega.Expect(svcCache.ListServices()).To(gomega.Equal([]svcmodel.ID{dbID, webID}))
+
+	// Update without change of frontends or backends.
+	gomega.Expect(svcCache.UpdateService(web, newService("web", "10.96.0.10", "10.1.1.1"))).To(gomega.BeNil())
+	gomega.Expect(changes).To(gomega.BeEmpty())
+
+	// Update with a new backend.
+	newWeb := newService("web", "10.96.0.10", "10.1.1.1", "10.1.1.3")
+	gomega.Expect(svcCache.UpdateService(web, newWeb)).To(gomega.BeNil())
+	gomega.Expect(<-changes).To(gomega.Equal([]svcmodel.ID{webID}))
+	gomega.Expect(svcCache.LookupService(webID).Backends["http"]).To(gomega.HaveLen(2))
+
+	// Delete service.
+	gomega.Expect(svcCache.DeleteService(db)).To(gomega.BeNil())
+	gomega.Expect(<-changes).To(gomega.Equal([]svcmodel.ID{dbID}))
+	gomega.Expect(svcCache.LookupService(dbID)).To(gomega.BeNil())
+
+	// Resync removes services not included.
+	gomega.Expect(svcCache.Resync(&svcrenderer.ResyncEventData{})).To(gomega.BeNil())
+	gomega.Expect(<-changes).To(gomega.Equal([]svcmodel.ID{webID}))
+	gomega.Expect(svcCache.LookupService(webID)).To(gomega.BeNil())
+}
@@ -256,6 +256,28 @@ func ComparePorts(a, b uint16) int {
 	return 1
 }
 
+// ComparePortRanges is a comparison function for two port ranges, each given
+// by the first port and the last port (0 = single-port range).
+// Port=0 means "all-ports". A range is higher in the order than all the ranges
+// it contains, i.e. single ports come first, then ranges sorted by their size
+// and finally "all-ports".
+func ComparePortRanges(a, aEnd, b, bEnd uint16) int {
+	if aEnd <= a || a == 0 {
+		aEnd = a
+	}
+	if bEnd <= b || b == 0 {
+		bEnd = b
+	}
+	if a == 0 || b == 0 {
+		return ComparePorts(a, b)
+	}
+	sizeOrder := CompareInts(int(aEnd-a), int(bEnd-b))
+	if sizeOrder != 0 {
+		return sizeOrder
+	}
+	return ComparePorts(a, b)
+}
+
 // CompareIPNetsBytes returns an integer comparing two IP network addresses
 // represented as raw bytes lexicographically.
 func CompareIPNetsBytes(aPrefixLen uint8, aIP [16]byte, bPrefixLen uint8, bIP [16]byte) int {
@@ -289,3 +311,45 @@ func GetOneHostSubnetFromIP(hostIP net.IP) *net.IPNet {
 	}
 	return ipNet
 }
+
+// GetOneHostSubnets returns one-host subnets of all the given host addresses.
+// Invalid addresses are skipped.
+func GetOneHostSubnets(hostAddrs ...string) []*net.IPNet {
+	var subnets []*net.IPNet
+	for _, hostAddr := range hostAddrs {
+		if subnet := GetOneHostSubnet(hostAddr); subnet != nil {
+			subnets = append(subnets, subnet)
+		}
+	}
+	return subnets
+}
+
+// GetPodIPAddresses returns all IP addresses allocated to the pod (one for
+// each IP family in dual-stack clusters), starting with the primary one.
+func GetPodIPAddresses(pod *podmodel.Pod) []string {
+	var addrs []string
+	if pod.IpAddress != "" {
+		addrs = append(addrs, pod.IpAddress)
+	}
+	for _, addr := range pod.IpAddresses {
+		if addr != "" && addr != pod.IpAddress {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// IsIPv6Net returns true if the network is from the IPv6 family.
+// Empty network (matching all addresses) is not considered as IPv6.
+func IsIPv6Net(ipNet *net.IPNet) bool {
+	return ipNet != nil && len(ipNet.IP) > 0 && ipNet.IP.To4() == nil
+}
+
+// SameIPFamily returns true if the given networks are from the same IP family.
+// Empty network (matching all addresses) is considered to be from both families.
+func SameIPFamily(net1, net2 *net.IPNet) bool {
+	if net1 == nil || net2 == nil || len(net1.IP) == 0 || len(net2.IP) == 0 {
+		return true
+	}
+	return (net1.IP.To4() == nil) == (net2.IP.To4() == nil)
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package healthcheck implements HTTP responders for health-check node ports
+// of services with externalTrafficPolicy=Local.
+//
+// External load-balancers probe the health-check node port of such service
+// on every node to learn whether the node has at least one local endpoint
+// to forward the traffic to. The responder returns 200 (OK) if there is one
+// or more local ready endpoint and 503 (Service Unavailable) otherwise,
+// with a JSON body compatible with kube-proxy:
+//
+//	{"service":{"namespace":"default","name":"web"},"localEndpoints":1}
+//
+// The responders listen in the host network namespace of the agent. The node IP
+// is owned by VPP, therefore the NAT44 renderer forwards the health-check node
+// port on the node IP to the host end of the VPP-host interconnect.
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/ligato/cn-infra/logging"
+
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+)
+
+// API defines the interface used by the service processor to keep
+// the health-check responders in sync with the services.
+type API interface {
+	// Sync updates the set of health-check responders to match the given map
+	// of services that require one. Responders of services not present
+	// in the map are stopped.
+	Sync(checks map[svcmodel.ID]*Check)
+
+	// Close stops all the responders.
+	Close() error
+}
+
+// Check describes the health-check of a single service.
+type Check struct {
+	// Port is the health-check node port of the service.
+	Port uint16
+
+	// LocalEndpoints is the number of node-local ready endpoints of the service.
+	LocalEndpoints int
+}
+
+// String converts Check into a human-readable string.
+func (c *Check) String() string {
+	return fmt.Sprintf("HealthCheck <port:%d localEndpoints:%d>", c.Port, c.LocalEndpoints)
+}
+
+// Server runs one HTTP responder per health-check node port.
+type Server struct {
+	Log logging.Logger
+
+	sync.Mutex
+	responders map[svcmodel.ID]*responder
+
+	// listen opens the listener for the given port (overridden in tests).
+	listen func(port uint16) (net.Listener, error)
+}
+
+// responder serves health-checks of a single service.
+type responder struct {
+	sync.Mutex
+	service        svcmodel.ID
+	port           uint16
+	localEndpoints int
+	server         *http.Server
+}
+
+// response is the JSON body returned by the responders.
+type response struct {
+	Service struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"service"`
+	LocalEndpoints int `json:"localEndpoints"`
+}
+
+// NewServer creates a new instance of the health-check server.
+func NewServer(log logging.Logger) *Server {
+	return &Server{
+		Log:        log,
+		responders: make(map[svcmodel.ID]*responder),
+		listen:     listenOnPort,
+	}
+}
+
+// Sync updates the set of health-check responders to match the given map
+// of services that require one.
+// Failure to open a port is logged and retried with the next Sync.
+func (s *Server) Sync(checks map[svcmodel.ID]*Check) {
+	s.Lock()
+	defer s.Unlock()
+
+	// stop responders of removed services and of services with changed port
+	for id, resp := range s.responders {
+		check, hasCheck := checks[id]
+		if !hasCheck || check.Port != resp.port {
+			s.Log.WithFields(logging.Fields{
+				"service": id,
+				"port":    resp.port,
+			}).Info("Stopping health-check responder")
+			resp.server.Close()
+			delete(s.responders, id)
+		}
+	}
+
+	// update existing and start new responders
+	for id, check := range checks {
+		if resp, hasResponder := s.responders[id]; hasResponder {
+			resp.setLocalEndpoints(check.LocalEndpoints)
+			continue
+		}
+		listener, err := s.listen(check.Port)
+		if err != nil {
+			s.Log.WithFields(logging.Fields{
+				"service": id,
+				"port":    check.Port,
+			}).Warnf("Failed to open health-check node port: %v", err)
+			continue
+		}
+		resp := &responder{
+			service:        id,
+			port:           check.Port,
+			localEndpoints: check.LocalEndpoints,
+		}
+		resp.server = &http.Server{Handler: resp}
+		go resp.server.Serve(listener)
+		s.responders[id] = resp
+		s.Log.WithFields(logging.Fields{
+			"service": id,
+			"port":    check.Port,
+		}).Info("Started health-check responder")
+	}
+}
+
+// Close stops all the responders.
+func (s *Server) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	for id, resp := range s.responders {
+		resp.server.Close()
+		delete(s.responders, id)
+	}
+	return nil
+}
+
+// setLocalEndpoints updates the number of local endpoints reported by the responder.
+func (r *responder) setLocalEndpoints(count int) {
+	r.Lock()
+	defer r.Unlock()
+	r.localEndpoints = count
+}
+
+// ServeHTTP responds to a health-check.
+func (r *responder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	count := r.localEndpoints
+	r.Unlock()
+
+	body := response{LocalEndpoints: count}
+	body.Service.Namespace = r.service.Namespace
+	body.Service.Name = r.service.Name
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if count == 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(&body)
+}
+
+// listenOnPort opens TCP listener for the given port on all host addresses
+// (including the host end of the VPP-host interconnect).
+func listenOnPort(port uint16) (net.Listener, error) {
+	return net.Listen("tcp", fmt.Sprintf(":%d", port))
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+)
+
+func TestHealthCheckServer(t *testing.T) {
+	gomega.RegisterTestingT(t)
+
+	server := NewServer(logrus.DefaultLogger())
+	defer server.Close()
+
+	// listen on random loopback ports, remember the address for each node port
+	addrs := make(map[uint16]string)
+	server.listen = func(port uint16) (net.Listener, error) {
+		if port == 1 {
+			return nil, fmt.Errorf("port in use")
+		}
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err == nil {
+			addrs[port] = listener.Addr().String()
+		}
+		return listener, err
+	}
+	probe := func(port uint16) (int, *response) {
+		resp, err := http.Get("http://" + addrs[port] + "/healthz")
+		if err != nil {
+			return 0, nil
+		}
+		defer resp.Body.Close()
+		gomega.Expect(resp.Header.Get("Content-Type")).To(gomega.Equal("application/json"))
+		body := &response{}
+		gomega.Expect(json.NewDecoder(resp.Body).Decode(body)).To(gomega.BeNil())
+		return resp.StatusCode, body
+	}
+
+	web := svcmodel.ID{Namespace: "default", Name: "web"}
+	dns := svcmodel.ID{Namespace: "kube-system", Name: "dns"}
+	db := svcmodel.ID{Namespace: "default", Name: "db"}
+
+	// service with and without local endpoints, port of "db" cannot be opened
+	server.Sync(map[svcmodel.ID]*Check{
+		web: {Port: 30001, LocalEndpoints: 2},
+		dns: {Port: 30002, LocalEndpoints: 0},
+		db:  {Port: 1, LocalEndpoints: 1},
+	})
+	gomega.Expect(server.responders).To(gomega.HaveLen(2))
+	status, body := probe(30001)
+	gomega.Expect(status).To(gomega.Equal(http.StatusOK))
+	gomega.Expect(body.Service.Namespace).To(gomega.Equal("default"))
+	gomega.Expect(body.Service.Name).To(gomega.Equal("web"))
+	gomega.Expect(body.LocalEndpoints).To(gomega.Equal(2))
+	status, body = probe(30002)
+	gomega.Expect(status).To(gomega.Equal(http.StatusServiceUnavailable))
+	gomega.Expect(body.Service.Name).To(gomega.Equal("dns"))
+	gomega.Expect(body.LocalEndpoints).To(gomega.Equal(0))
+
+	// local endpoints changed, "dns" moved to another port
+	server.Sync(map[svcmodel.ID]*Check{
+		web: {Port: 30001, LocalEndpoints: 0},
+		dns: {Port: 30003, LocalEndpoints: 1},
+	})
+	gomega.Expect(server.responders).To(gomega.HaveLen(2))
+	status, _ = probe(30001)
+	gomega.Expect(status).To(gomega.Equal(http.StatusServiceUnavailable))
+	status, _ = probe(30002)
+	gomega.Expect(status).To(gomega.Equal(0))
+	status, body = probe(30003)
+	gomega.Expect(status).To(gomega.Equal(http.StatusOK))
+	gomega.Expect(body.LocalEndpoints).To(gomega.Equal(1))
+
+	// "web" no longer requires the health-check
+	server.Sync(map[svcmodel.ID]*Check{
+		dns: {Port: 30003, LocalEndpoints: 1},
+	})
+	gomega.Expect(server.responders).To(gomega.HaveLen(1))
+	status, _ = probe(30001)
+	gomega.Expect(status).To(gomega.Equal(0))
+
+	// all responders stopped
+	gomega.Expect(server.Close()).To(gomega.BeNil())
+	gomega.Expect(server.responders).To(gomega.BeEmpty())
+	status, _ = probe(30003)
+	gomega.Expect(status).To(gomega.Equal(0))
+}
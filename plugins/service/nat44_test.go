@@ -19,6 +19,7 @@ import (
 	"net"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/logging/logrus"
@@ -26,6 +27,8 @@ import (
 
 	. "github.com/contiv/vpp/mock/contiv"
 	. "github.com/contiv/vpp/mock/datasync"
+	. "github.com/contiv/vpp/mock/healthcheck"
+	. "github.com/contiv/vpp/mock/lbplugin"
 	. "github.com/contiv/vpp/mock/natplugin"
 	. "github.com/contiv/vpp/mock/pluginvpp"
 	. "github.com/contiv/vpp/mock/servicelabel"
@@ -33,6 +36,8 @@ import (
 	"github.com/contiv/vpp/mock/localclient"
 	svc_processor "github.com/contiv/vpp/plugins/service/processor"
 	svc_renderer "github.com/contiv/vpp/plugins/service/renderer"
+	"github.com/contiv/vpp/plugins/service/renderer/ipv6"
+	"github.com/contiv/vpp/plugins/service/renderer/maglev"
 	"github.com/contiv/vpp/plugins/service/renderer/nat44"
 
 	nodemodel "github.com/contiv/vpp/plugins/contiv/model/node"
@@ -51,6 +56,7 @@ const (
 	OtherIfName2    = "GbE3"
 	vxlanIfName     = "VXLAN-BVI"
 	hostInterIfName = "VPP-Host"
+	hostInterIP     = "172.30.10.2"
 	nodeIP          = "192.168.16.10"
 	mgmtIP          = "172.30.1.1"
 	otherIfIP       = "192.168.17.10"
@@ -103,8 +109,20 @@ var (
 
 var (
 	keyPrefixes = []string{epmodel.KeyPrefix(), podmodel.KeyPrefix(), svcmodel.KeyPrefix(), nodemodel.AllocatedIDsKeyPrefix}
+
+	// mockLB is shared by all tests - GoVPP supports only one connection per process.
+	mockLB *MockLBPlugin
 )
 
+// getMockLBPlugin returns the shared LB plugin mock, cleared of any configuration.
+func getMockLBPlugin(logger logging.Logger) *MockLBPlugin {
+	if mockLB == nil {
+		mockLB = NewMockLBPlugin(logger)
+	}
+	mockLB.Clear()
+	return mockLB
+}
+
 func TestResyncAndSingleService(t *testing.T) {
 	RegisterTestingT(t)
 	logger := logrus.DefaultLogger()
@@ -1781,6 +1799,1328 @@ func TestServiceUpdates(t *testing.T) {
 	Expect(natPlugin.HasIdentityMapping(mainIfID)).To(BeTrue())
 }
 
+func TestSessionAffinity(t *testing.T) {
+	RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestSessionAffinity")
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetNatExternalTraffic(true)
+	const localEndpointWeight uint8 = 1
+	contiv.SetServiceLocalEndpointWeight(localEndpointWeight)
+	contiv.SetSTNMode(false)
+	contiv.SetNodeIP(nodeIP + nodePrefix)
+	contiv.SetDefaultInterface(mainIfName, net.ParseIP(nodeIP))
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodNetwork(podNetwork)
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+	contiv.SetPodIfName(pod1, pod1If)
+	contiv.SetPodIfName(pod2, pod2If)
+	contiv.SetMainVrfID(mainVrfID)
+	contiv.SetPodVrfID(podVrfID)
+	contiv.SetHostIPs([]net.IP{net.ParseIP(nodeIP), net.ParseIP(mgmtIP)})
+
+	// -> NAT plugin
+	natPlugin := NewMockNatPlugin(logger)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(natPlugin.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.SetNat44Global(&nat.Nat44Global{})
+	vppPlugins.SetNat44Dnat(&nat.Nat44DNat{})
+
+	// -> service label
+	serviceLabel := NewMockServiceLabel()
+	serviceLabel.SetAgentLabel(masterLabel)
+
+	// -> datasync
+	datasync := NewMockDataSync()
+
+	// Prepare processor.
+	processor := &svc_processor.ServiceProcessor{
+		Deps: svc_processor.Deps{
+			Log:          logger,
+			ServiceLabel: serviceLabel,
+			Contiv:       contiv,
+		},
+	}
+
+	// Prepare NAT44 Renderer.
+	renderer := &nat44.Renderer{
+		Deps: nat44.Deps{
+			Log:           logger,
+			VPP:           vppPlugins,
+			Contiv:        contiv,
+			NATTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+
+	// Initialize and resync.
+	Expect(processor.Init()).To(BeNil())
+	Expect(renderer.Init(false)).To(BeNil())
+	Expect(processor.RegisterRenderer(renderer)).To(BeNil())
+	resyncEv := datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+
+	// Add pods.
+	dataChange1 := datasync.Put(podmodel.Key(pod1.Name, pod1.Namespace), pod1Model)
+	Expect(processor.Update(dataChange1)).To(BeNil())
+	dataChange2 := datasync.Put(podmodel.Key(pod2.Name, pod2.Namespace), pod2Model)
+	Expect(processor.Update(dataChange2)).To(BeNil())
+	dataChange3 := datasync.Put(podmodel.Key(pod3.Name, pod3.Namespace), pod3Model)
+	Expect(processor.Update(dataChange3)).To(BeNil())
+
+	// Service1: http + https with ClientIP affinity.
+	service1 := &svcmodel.Service{
+		Name:                  "service1",
+		Namespace:             namespace1,
+		ServiceType:           "ClusterIP",
+		ExternalTrafficPolicy: "Cluster",
+		SessionAffinity:       "ClientIP",
+		ClusterIp:             "10.96.0.1",
+		ExternalIps:           []string{"20.20.20.20"},
+		Port: []*svcmodel.Service_ServicePort{
+			{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     80,
+				NodePort: 0,
+			},
+			{
+				Name:     "https",
+				Protocol: "TCP",
+				Port:     443,
+				NodePort: 0,
+			},
+		},
+	}
+
+	dataChange4 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange4)).To(BeNil())
+
+	// Add endpoints.
+	backends := []struct {
+		id   podmodel.ID
+		ip   string
+		node string
+	}{
+		{id: pod1, ip: pod1IP, node: masterLabel},
+		{id: pod2, ip: pod2IP, node: masterLabel},
+		{id: pod3, ip: pod3IP, node: workerLabel},
+	}
+	endpoints := func(excluded string) *epmodel.Endpoints {
+		subset := &epmodel.EndpointSubset{
+			Ports: []*epmodel.EndpointSubset_EndpointPort{
+				{
+					Name:     "http",
+					Port:     8080,
+					Protocol: "TCP",
+				},
+				{
+					Name:     "https",
+					Port:     8443,
+					Protocol: "TCP",
+				},
+			},
+		}
+		for _, backend := range backends {
+			if backend.ip == excluded {
+				continue
+			}
+			subset.Addresses = append(subset.Addresses, &epmodel.EndpointSubset_EndpointAddress{
+				Ip:       backend.ip,
+				NodeName: backend.node,
+				TargetRef: &epmodel.ObjectReference{
+					Kind:      "Pod",
+					Namespace: backend.id.Namespace,
+					Name:      backend.id.Name,
+				},
+			})
+		}
+		return &epmodel.Endpoints{
+			Name:            "service1",
+			Namespace:       namespace1,
+			EndpointSubsets: []*epmodel.EndpointSubset{subset},
+		}
+	}
+
+	eps1 := endpoints("")
+	dataChange5 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange5)).To(BeNil())
+
+	// pinnedBackend returns IP of the single backend all the static mappings
+	// of the service lead to (empty if there is no such backend).
+	pinnedBackend := func() string {
+		for _, backend := range backends {
+			pinned := true
+			for _, externalIP := range []string{"10.96.0.1", "20.20.20.20"} {
+				for port, targetPort := range map[uint16]uint16{80: 8080, 443: 8443} {
+					staticMapping := &StaticMapping{
+						ExternalIP:   net.ParseIP(externalIP),
+						ExternalPort: port,
+						Protocol:     svc_renderer.TCP,
+						Locals: []*Local{
+							{
+								VrfID:       podVrfID,
+								IP:          net.ParseIP(backend.ip),
+								Port:        targetPort,
+								Probability: 0,
+							},
+						},
+					}
+					if !natPlugin.HasStaticMapping(staticMapping) {
+						pinned = false
+					}
+				}
+			}
+			if pinned {
+				return backend.ip
+			}
+		}
+		return ""
+	}
+
+	// loadBalanced returns true if all the static mappings of the service
+	// load-balance between the given backends.
+	loadBalanced := func(backendIPs ...string) bool {
+		for _, externalIP := range []string{"10.96.0.1", "20.20.20.20"} {
+			for port, targetPort := range map[uint16]uint16{80: 8080, 443: 8443} {
+				staticMapping := &StaticMapping{
+					ExternalIP:   net.ParseIP(externalIP),
+					ExternalPort: port,
+					Protocol:     svc_renderer.TCP,
+				}
+				for _, backend := range backends {
+					for _, backendIP := range backendIPs {
+						if backend.ip != backendIP {
+							continue
+						}
+						probability := uint8(1)
+						if backend.node == masterLabel {
+							probability = localEndpointWeight
+						}
+						staticMapping.Locals = append(staticMapping.Locals, &Local{
+							VrfID:       podVrfID,
+							IP:          net.ParseIP(backend.ip),
+							Port:        targetPort,
+							Probability: probability,
+						})
+					}
+				}
+				if !natPlugin.HasStaticMapping(staticMapping) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	// Check NAT configuration - all the mappings lead to the same backend.
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(4))
+	selected := pinnedBackend()
+	Expect(selected).ToNot(BeEmpty())
+
+	// Remove one of the other backends - the selection should not change.
+	remaining := []string{}
+	for _, backend := range backends {
+		if backend.ip != selected && len(remaining) == 0 {
+			eps1 = endpoints(backend.ip)
+			continue
+		}
+		remaining = append(remaining, backend.ip)
+	}
+	dataChange6 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange6)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(4))
+	Expect(pinnedBackend()).To(Equal(selected))
+
+	// Disable session affinity - mappings should load-balance again.
+	service1.SessionAffinity = "None"
+	dataChange7 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange7)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(4))
+	Expect(pinnedBackend()).To(BeEmpty())
+	Expect(loadBalanced(remaining...)).To(BeTrue())
+
+	// Re-enable session affinity - the same backend should be selected.
+	service1.SessionAffinity = "ClientIP"
+	dataChange8 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange8)).To(BeNil())
+	Expect(pinnedBackend()).To(Equal(selected))
+
+	// Remove the selected backend - another one should be selected.
+	eps1 = endpoints(selected)
+	dataChange9 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange9)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(4))
+	reselected := pinnedBackend()
+	Expect(reselected).ToNot(BeEmpty())
+	Expect(reselected).ToNot(Equal(selected))
+
+	// Cleanup
+	Expect(processor.Close()).To(BeNil())
+	Expect(renderer.Close()).To(BeNil())
+}
+
+func TestLoadBalancerService(t *testing.T) {
+	RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestLoadBalancerService")
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetNatExternalTraffic(true)
+	const localEndpointWeight uint8 = 1
+	contiv.SetServiceLocalEndpointWeight(localEndpointWeight)
+	contiv.SetSTNMode(false)
+	contiv.SetNodeIP(nodeIP + nodePrefix)
+	contiv.SetDefaultInterface(mainIfName, net.ParseIP(nodeIP))
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodNetwork(podNetwork)
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+	contiv.SetPodIfName(pod1, pod1If)
+	contiv.SetPodIfName(pod2, pod2If)
+	contiv.SetMainVrfID(mainVrfID)
+	contiv.SetPodVrfID(podVrfID)
+	contiv.SetHostIPs([]net.IP{net.ParseIP(nodeIP), net.ParseIP(mgmtIP)})
+
+	// -> NAT plugin
+	natPlugin := NewMockNatPlugin(logger)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(natPlugin.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.SetNat44Global(&nat.Nat44Global{})
+	vppPlugins.SetNat44Dnat(&nat.Nat44DNat{})
+
+	// -> service label
+	serviceLabel := NewMockServiceLabel()
+	serviceLabel.SetAgentLabel(masterLabel)
+
+	// -> datasync
+	datasync := NewMockDataSync()
+
+	// Prepare processor.
+	processor := &svc_processor.ServiceProcessor{
+		Deps: svc_processor.Deps{
+			Log:          logger,
+			ServiceLabel: serviceLabel,
+			Contiv:       contiv,
+		},
+	}
+
+	// Prepare NAT44 Renderer.
+	renderer := &nat44.Renderer{
+		Deps: nat44.Deps{
+			Log:           logger,
+			VPP:           vppPlugins,
+			Contiv:        contiv,
+			NATTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+
+	// Initialize and resync.
+	Expect(processor.Init()).To(BeNil())
+	Expect(renderer.Init(false)).To(BeNil())
+	Expect(processor.RegisterRenderer(renderer)).To(BeNil())
+	resyncEv := datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+
+	// Add pods.
+	dataChange1 := datasync.Put(podmodel.Key(pod1.Name, pod1.Namespace), pod1Model)
+	Expect(processor.Update(dataChange1)).To(BeNil())
+	dataChange2 := datasync.Put(podmodel.Key(pod2.Name, pod2.Namespace), pod2Model)
+	Expect(processor.Update(dataChange2)).To(BeNil())
+	dataChange3 := datasync.Put(podmodel.Key(pod3.Name, pod3.Namespace), pod3Model)
+	Expect(processor.Update(dataChange3)).To(BeNil())
+
+	// Service1: LoadBalancer with a requested IP and an ingress IP from the status.
+	service1 := &svcmodel.Service{
+		Name:                     "service1",
+		Namespace:                namespace1,
+		ServiceType:              "LoadBalancer",
+		ExternalTrafficPolicy:    "Cluster",
+		ClusterIp:                "10.96.0.1",
+		LoadbalancerIp:           "30.30.30.30",
+		LoadbalancerIngressIps:   []string{"30.30.30.31", "invalid-ip"},
+		LoadbalancerSourceRanges: []string{"192.168.0.0/16"},
+		Port: []*svcmodel.Service_ServicePort{
+			{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     80,
+				NodePort: 0,
+			},
+		},
+	}
+
+	dataChange4 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange4)).To(BeNil())
+
+	// Add endpoints.
+	eps1 := &epmodel.Endpoints{
+		Name:      "service1",
+		Namespace: namespace1,
+		EndpointSubsets: []*epmodel.EndpointSubset{
+			{
+				Addresses: []*epmodel.EndpointSubset_EndpointAddress{
+					{
+						Ip:       pod1IP,
+						NodeName: masterLabel,
+						TargetRef: &epmodel.ObjectReference{
+							Kind:      "Pod",
+							Namespace: pod1.Namespace,
+							Name:      pod1.Name,
+						},
+					},
+				},
+				Ports: []*epmodel.EndpointSubset_EndpointPort{
+					{
+						Name:     "http",
+						Port:     8080,
+						Protocol: "TCP",
+					},
+				},
+			},
+		},
+	}
+
+	dataChange5 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange5)).To(BeNil())
+
+	// hasMapping returns true if the service is mapped from the given IP.
+	hasMapping := func(externalIP string, port uint16) bool {
+		return natPlugin.HasStaticMapping(&StaticMapping{
+			ExternalIP:   net.ParseIP(externalIP),
+			ExternalPort: port,
+			Protocol:     svc_renderer.TCP,
+			Locals: []*Local{
+				{
+					VrfID:       podVrfID,
+					IP:          net.ParseIP(pod1IP),
+					Port:        8080,
+					Probability: 0,
+				},
+			},
+		})
+	}
+
+	// Check NAT configuration - cluster IP and both LB IPs.
+	Expect(hasMapping("10.96.0.1", 80)).To(BeTrue())
+	Expect(hasMapping("30.30.30.30", 80)).To(BeTrue())
+	Expect(hasMapping("30.30.30.31", 80)).To(BeTrue())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(3))
+
+	// Ingress IP removed from the status.
+	service1.LoadbalancerIngressIps = []string{}
+	dataChange6 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange6)).To(BeNil())
+	Expect(hasMapping("30.30.30.30", 80)).To(BeTrue())
+	Expect(hasMapping("30.30.30.31", 80)).To(BeFalse())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(2))
+
+	// Service changed to ClusterIP - load-balancer IPs are no longer used.
+	service1.ServiceType = "ClusterIP"
+	dataChange7 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange7)).To(BeNil())
+	Expect(hasMapping("10.96.0.1", 80)).To(BeTrue())
+	Expect(hasMapping("30.30.30.30", 80)).To(BeFalse())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(1))
+
+	// Cleanup
+	Expect(processor.Close()).To(BeNil())
+	Expect(renderer.Close()).To(BeNil())
+}
+
+func TestHealthCheckNodePort(t *testing.T) {
+	RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestHealthCheckNodePort")
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetNatExternalTraffic(true)
+	const localEndpointWeight uint8 = 1
+	contiv.SetServiceLocalEndpointWeight(localEndpointWeight)
+	contiv.SetSTNMode(false)
+	contiv.SetNodeIP(nodeIP + nodePrefix)
+	contiv.SetDefaultInterface(mainIfName, net.ParseIP(nodeIP))
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetHostInterconnectIP(net.ParseIP(hostInterIP))
+	contiv.SetPodNetwork(podNetwork)
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+	contiv.SetPodIfName(pod1, pod1If)
+	contiv.SetPodIfName(pod2, pod2If)
+	contiv.SetMainVrfID(mainVrfID)
+	contiv.SetPodVrfID(podVrfID)
+	contiv.SetHostIPs([]net.IP{net.ParseIP(nodeIP), net.ParseIP(mgmtIP)})
+
+	// -> NAT plugin
+	natPlugin := NewMockNatPlugin(logger)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(natPlugin.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.SetNat44Global(&nat.Nat44Global{})
+	vppPlugins.SetNat44Dnat(&nat.Nat44DNat{})
+
+	// -> service label
+	serviceLabel := NewMockServiceLabel()
+	serviceLabel.SetAgentLabel(masterLabel)
+
+	// -> datasync
+	datasync := NewMockDataSync()
+
+	// -> health-check server
+	healthCheck := NewMockHealthCheck()
+
+	// Prepare processor.
+	processor := &svc_processor.ServiceProcessor{
+		Deps: svc_processor.Deps{
+			Log:          logger,
+			ServiceLabel: serviceLabel,
+			Contiv:       contiv,
+			HealthCheck:  healthCheck,
+		},
+	}
+
+	// Prepare NAT44 Renderer.
+	renderer := &nat44.Renderer{
+		Deps: nat44.Deps{
+			Log:           logger,
+			VPP:           vppPlugins,
+			Contiv:        contiv,
+			NATTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+
+	// Initialize and resync.
+	Expect(processor.Init()).To(BeNil())
+	Expect(renderer.Init(false)).To(BeNil())
+	Expect(processor.RegisterRenderer(renderer)).To(BeNil())
+	resyncEv := datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+
+	// Add pods.
+	dataChange1 := datasync.Put(podmodel.Key(pod1.Name, pod1.Namespace), pod1Model)
+	Expect(processor.Update(dataChange1)).To(BeNil())
+	dataChange2 := datasync.Put(podmodel.Key(pod2.Name, pod2.Namespace), pod2Model)
+	Expect(processor.Update(dataChange2)).To(BeNil())
+
+	// Service1: node-local traffic policy with a health-check node port.
+	service1 := &svcmodel.Service{
+		Name:                  "service1",
+		Namespace:             namespace1,
+		ServiceType:           "LoadBalancer",
+		ExternalTrafficPolicy: "Local",
+		HealthCheckNodePort:   31000,
+		ClusterIp:             "10.96.0.1",
+		Port: []*svcmodel.Service_ServicePort{
+			{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     80,
+				NodePort: 0,
+			},
+		},
+	}
+	service1ID := svcmodel.GetID(service1)
+
+	dataChange3 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange3)).To(BeNil())
+
+	// Service without endpoints is reported as unhealthy.
+	Expect(healthCheck.NumOfChecks()).To(Equal(1))
+	Expect(healthCheck.GetCheck(service1ID).Port).To(BeEquivalentTo(31000))
+	Expect(healthCheck.GetCheck(service1ID).LocalEndpoints).To(Equal(0))
+
+	// Health-check node port on the node IP is forwarded to the host
+	// (the service is rendered once it has endpoints).
+	healthCheckMapping := &StaticMapping{
+		ExternalIP:   net.ParseIP(nodeIP),
+		ExternalPort: 31000,
+		Protocol:     svc_renderer.TCP,
+		Locals: []*Local{
+			{
+				VrfID:       mainVrfID,
+				IP:          net.ParseIP(hostInterIP),
+				Port:        31000,
+				Probability: 0,
+			},
+		},
+	}
+	Expect(natPlugin.HasStaticMapping(healthCheckMapping)).To(BeFalse())
+
+	// Add endpoints - two local, one remote.
+	endpointAddress := func(ip, nodeName string, pod podmodel.ID) *epmodel.EndpointSubset_EndpointAddress {
+		return &epmodel.EndpointSubset_EndpointAddress{
+			Ip:       ip,
+			NodeName: nodeName,
+			TargetRef: &epmodel.ObjectReference{
+				Kind:      "Pod",
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+			},
+		}
+	}
+	eps1 := &epmodel.Endpoints{
+		Name:      "service1",
+		Namespace: namespace1,
+		EndpointSubsets: []*epmodel.EndpointSubset{
+			{
+				Addresses: []*epmodel.EndpointSubset_EndpointAddress{
+					endpointAddress(pod1IP, masterLabel, pod1),
+					endpointAddress(pod2IP, masterLabel, pod2),
+					endpointAddress("10.3.1.1", workerLabel, podmodel.ID{Name: "pod4", Namespace: namespace1}),
+				},
+				Ports: []*epmodel.EndpointSubset_EndpointPort{
+					{
+						Name:     "http",
+						Port:     8080,
+						Protocol: "TCP",
+					},
+				},
+			},
+		},
+	}
+
+	dataChange4 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange4)).To(BeNil())
+	Expect(healthCheck.GetCheck(service1ID).LocalEndpoints).To(Equal(2))
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(2))
+	Expect(natPlugin.HasStaticMapping(healthCheckMapping)).To(BeTrue())
+
+	// Local endpoint removed.
+	eps1.EndpointSubsets[0].Addresses = eps1.EndpointSubsets[0].Addresses[1:]
+	dataChange5 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange5)).To(BeNil())
+	Expect(healthCheck.GetCheck(service1ID).LocalEndpoints).To(Equal(1))
+
+	// Traffic policy changed to cluster-wide - health-check is no longer served.
+	service1.ExternalTrafficPolicy = "Cluster"
+	dataChange6 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange6)).To(BeNil())
+	Expect(healthCheck.NumOfChecks()).To(Equal(0))
+	Expect(natPlugin.HasStaticMapping(healthCheckMapping)).To(BeFalse())
+
+	// Back to node-local, then the service is removed.
+	service1.ExternalTrafficPolicy = "Local"
+	dataChange7 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange7)).To(BeNil())
+	Expect(healthCheck.NumOfChecks()).To(Equal(1))
+	Expect(natPlugin.HasStaticMapping(healthCheckMapping)).To(BeTrue())
+	dataChange8 := datasync.Delete(svcmodel.Key(service1.Name, service1.Namespace))
+	Expect(processor.Update(dataChange8)).To(BeNil())
+	Expect(healthCheck.NumOfChecks()).To(Equal(0))
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+
+	// Cleanup
+	Expect(processor.Close()).To(BeNil())
+	Expect(renderer.Close()).To(BeNil())
+}
+
+func TestBackendDraining(t *testing.T) {
+	RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestBackendDraining")
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetNatExternalTraffic(true)
+	const localEndpointWeight uint8 = 1
+	contiv.SetServiceLocalEndpointWeight(localEndpointWeight)
+	const drainPeriod = 200 * time.Millisecond
+	contiv.SetServiceBackendDrainPeriod(drainPeriod)
+	contiv.SetSTNMode(false)
+	contiv.SetNodeIP(nodeIP + nodePrefix)
+	contiv.SetDefaultInterface(mainIfName, net.ParseIP(nodeIP))
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodNetwork(podNetwork)
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+	contiv.SetPodIfName(pod1, pod1If)
+	contiv.SetPodIfName(pod2, pod2If)
+	contiv.SetMainVrfID(mainVrfID)
+	contiv.SetPodVrfID(podVrfID)
+	contiv.SetHostIPs([]net.IP{net.ParseIP(nodeIP), net.ParseIP(mgmtIP)})
+
+	// -> NAT plugin
+	natPlugin := NewMockNatPlugin(logger)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(natPlugin.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.SetNat44Global(&nat.Nat44Global{})
+	vppPlugins.SetNat44Dnat(&nat.Nat44DNat{})
+
+	// -> service label
+	serviceLabel := NewMockServiceLabel()
+	serviceLabel.SetAgentLabel(masterLabel)
+
+	// -> datasync
+	datasync := NewMockDataSync()
+
+	// -> health-check server
+	healthCheck := NewMockHealthCheck()
+
+	// Prepare processor.
+	processor := &svc_processor.ServiceProcessor{
+		Deps: svc_processor.Deps{
+			Log:          logger,
+			ServiceLabel: serviceLabel,
+			Contiv:       contiv,
+			HealthCheck:  healthCheck,
+		},
+	}
+
+	// Prepare NAT44 Renderer.
+	renderer := &nat44.Renderer{
+		Deps: nat44.Deps{
+			Log:           logger,
+			VPP:           vppPlugins,
+			Contiv:        contiv,
+			NATTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+
+	// Initialize and resync.
+	Expect(processor.Init()).To(BeNil())
+	Expect(renderer.Init(false)).To(BeNil())
+	Expect(processor.RegisterRenderer(renderer)).To(BeNil())
+	resyncEv := datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+
+	// Add pods.
+	dataChange1 := datasync.Put(podmodel.Key(pod1.Name, pod1.Namespace), pod1Model)
+	Expect(processor.Update(dataChange1)).To(BeNil())
+	dataChange2 := datasync.Put(podmodel.Key(pod2.Name, pod2.Namespace), pod2Model)
+	Expect(processor.Update(dataChange2)).To(BeNil())
+
+	// Service1: node-local traffic policy with a health-check node port.
+	service1 := &svcmodel.Service{
+		Name:                  "service1",
+		Namespace:             namespace1,
+		ExternalTrafficPolicy: "Local",
+		HealthCheckNodePort:   31000,
+		ClusterIp:             "10.96.0.1",
+		Port: []*svcmodel.Service_ServicePort{
+			{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     80,
+				NodePort: 0,
+			},
+		},
+	}
+	service1ID := svcmodel.GetID(service1)
+
+	dataChange3 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange3)).To(BeNil())
+
+	// Add endpoints - two local, one remote.
+	endpointAddress := func(ip, nodeName string, pod podmodel.ID) *epmodel.EndpointSubset_EndpointAddress {
+		return &epmodel.EndpointSubset_EndpointAddress{
+			Ip:       ip,
+			NodeName: nodeName,
+			TargetRef: &epmodel.ObjectReference{
+				Kind:      "Pod",
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+			},
+		}
+	}
+	pod1Addr := endpointAddress(pod1IP, masterLabel, pod1)
+	pod2Addr := endpointAddress(pod2IP, masterLabel, pod2)
+	pod3Addr := endpointAddress(pod3IP, workerLabel, pod3)
+	eps1 := &epmodel.Endpoints{
+		Name:      "service1",
+		Namespace: namespace1,
+		EndpointSubsets: []*epmodel.EndpointSubset{
+			{
+				Addresses: []*epmodel.EndpointSubset_EndpointAddress{pod1Addr, pod2Addr, pod3Addr},
+				Ports: []*epmodel.EndpointSubset_EndpointPort{
+					{
+						Name:     "http",
+						Port:     8080,
+						Protocol: "TCP",
+					},
+				},
+			},
+		},
+	}
+
+	dataChange4 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange4)).To(BeNil())
+
+	// Check NAT configuration - both local backends are load-balanced.
+	staticMappingWith := func(backendIPs ...string) *StaticMapping {
+		staticMapping := &StaticMapping{
+			ExternalIP:   net.ParseIP("10.96.0.1"),
+			ExternalPort: 80,
+			Protocol:     svc_renderer.TCP,
+		}
+		for _, backendIP := range backendIPs {
+			local := &Local{
+				VrfID:       podVrfID,
+				IP:          net.ParseIP(backendIP),
+				Port:        8080,
+				Probability: uint8(localEndpointWeight),
+			}
+			if len(backendIPs) == 1 {
+				local.Probability = 0
+			}
+			staticMapping.Locals = append(staticMapping.Locals, local)
+		}
+		return staticMapping
+	}
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(1))
+	Expect(natPlugin.HasStaticMapping(staticMappingWith(pod1IP, pod2IP))).To(BeTrue())
+	Expect(natPlugin.GetInterfaceFeatures(pod2If)).To(Equal(NewNatFeatures(IN, OUT)))
+	Expect(healthCheck.GetCheck(service1ID).LocalEndpoints).To(Equal(2))
+
+	// Pod2 is no longer ready - draining: no new connections, but the pod
+	// interface remains configured for the existing sessions.
+	eps1.EndpointSubsets[0].Addresses = []*epmodel.EndpointSubset_EndpointAddress{pod1Addr, pod3Addr}
+	eps1.EndpointSubsets[0].NotReadyAddresses = []*epmodel.EndpointSubset_EndpointAddress{pod2Addr}
+	dataChange5 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange5)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(1))
+	Expect(natPlugin.HasStaticMapping(staticMappingWith(pod1IP))).To(BeTrue())
+	Expect(natPlugin.GetInterfaceFeatures(pod2If)).To(Equal(NewNatFeatures(IN, OUT)))
+	Expect(healthCheck.GetCheck(service1ID).LocalEndpoints).To(Equal(1))
+
+	// Resync (e.g. after a restart of the agent) - pod2 is among the not-ready
+	// addresses and therefore keeps draining.
+	vppPlugins.SetNat44Global(natPlugin.DumpNat44Global())
+	vppPlugins.SetNat44Dnat(natPlugin.DumpNat44DNat())
+	resyncEv = datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(1))
+	Expect(natPlugin.HasStaticMapping(staticMappingWith(pod1IP))).To(BeTrue())
+	Expect(natPlugin.GetInterfaceFeatures(pod2If)).To(Equal(NewNatFeatures(IN, OUT)))
+	Expect(healthCheck.GetCheck(service1ID).LocalEndpoints).To(Equal(1))
+
+	// Pod2 is ready again before the drain period expires.
+	eps1.EndpointSubsets[0].Addresses = []*epmodel.EndpointSubset_EndpointAddress{pod1Addr, pod2Addr, pod3Addr}
+	eps1.EndpointSubsets[0].NotReadyAddresses = nil
+	dataChange6 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange6)).To(BeNil())
+	Expect(natPlugin.HasStaticMapping(staticMappingWith(pod1IP, pod2IP))).To(BeTrue())
+	Expect(healthCheck.GetCheck(service1ID).LocalEndpoints).To(Equal(2))
+	time.Sleep(2 * drainPeriod)
+	Expect(natPlugin.HasStaticMapping(staticMappingWith(pod1IP, pod2IP))).To(BeTrue())
+
+	// Pod2 is terminating - removed from endpoints, draining until the drain period expires.
+	eps1.EndpointSubsets[0].Addresses = []*epmodel.EndpointSubset_EndpointAddress{pod1Addr, pod3Addr}
+	dataChange7 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange7)).To(BeNil())
+	Expect(natPlugin.HasStaticMapping(staticMappingWith(pod1IP))).To(BeTrue())
+	Expect(natPlugin.GetInterfaceFeatures(pod2If)).To(Equal(NewNatFeatures(IN, OUT)))
+	Eventually(func() NatFeatures {
+		processor.Lock()
+		defer processor.Unlock()
+		return natPlugin.GetInterfaceFeatures(pod2If)
+	}, 10*drainPeriod, drainPeriod/10).Should(Equal(NewNatFeatures(OUT)))
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(1))
+	Expect(natPlugin.HasStaticMapping(staticMappingWith(pod1IP))).To(BeTrue())
+	Expect(healthCheck.GetCheck(service1ID).LocalEndpoints).To(Equal(1))
+
+	// Service is removed while pod1 is draining - nothing is left behind.
+	eps1.EndpointSubsets[0].Addresses = []*epmodel.EndpointSubset_EndpointAddress{pod3Addr}
+	dataChange8 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange8)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+	Expect(natPlugin.GetInterfaceFeatures(pod1If)).To(Equal(NewNatFeatures(IN, OUT)))
+	dataChange9 := datasync.Delete(svcmodel.Key(service1.Name, service1.Namespace))
+	Expect(processor.Update(dataChange9)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+	Expect(natPlugin.GetInterfaceFeatures(pod1If)).To(Equal(NewNatFeatures(OUT)))
+	Expect(healthCheck.NumOfChecks()).To(Equal(0))
+
+	// Cleanup
+	Expect(processor.Close()).To(BeNil())
+	Expect(renderer.Close()).To(BeNil())
+}
+
+func TestMaglevRendererSelection(t *testing.T) {
+	RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestMaglevRendererSelection")
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetNatExternalTraffic(true)
+	const localEndpointWeight uint8 = 1
+	contiv.SetServiceLocalEndpointWeight(localEndpointWeight)
+	contiv.SetSTNMode(false)
+	contiv.SetNodeIP(nodeIP + nodePrefix)
+	contiv.SetDefaultInterface(mainIfName, net.ParseIP(nodeIP))
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodNetwork(podNetwork)
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+	contiv.SetPodIfName(pod1, pod1If)
+	contiv.SetPodIfName(pod2, pod2If)
+	contiv.SetMainVrfID(mainVrfID)
+	contiv.SetPodVrfID(podVrfID)
+	contiv.SetHostIPs([]net.IP{net.ParseIP(nodeIP), net.ParseIP(mgmtIP)})
+
+	// -> NAT plugin
+	natPlugin := NewMockNatPlugin(logger)
+
+	// -> LB plugin
+	lbPlugin := getMockLBPlugin(logger)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(natPlugin.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.SetNat44Global(&nat.Nat44Global{})
+	vppPlugins.SetNat44Dnat(&nat.Nat44DNat{})
+	vppPlugins.AddInterface(pod1If, 1, pod1IP)
+	vppPlugins.AddInterface(pod2If, 2, pod2IP)
+
+	// -> service label
+	serviceLabel := NewMockServiceLabel()
+	serviceLabel.SetAgentLabel(masterLabel)
+
+	// -> datasync
+	datasync := NewMockDataSync()
+
+	// Prepare processor.
+	processor := &svc_processor.ServiceProcessor{
+		Deps: svc_processor.Deps{
+			Log:          logger,
+			ServiceLabel: serviceLabel,
+			Contiv:       contiv,
+			HealthCheck:  NewMockHealthCheck(),
+		},
+	}
+
+	// Prepare NAT44 Renderer.
+	natRenderer := &nat44.Renderer{
+		Deps: nat44.Deps{
+			Log:           logger,
+			VPP:           vppPlugins,
+			Contiv:        contiv,
+			NATTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+
+	// Prepare Maglev Renderer.
+	maglevRenderer := &maglev.Renderer{
+		Deps: maglev.Deps{
+			Log:       logger,
+			VPP:       vppPlugins,
+			GoVPPChan: lbPlugin.NewVPPChan(),
+		},
+	}
+
+	// Initialize and resync.
+	Expect(processor.Init()).To(BeNil())
+	Expect(natRenderer.Init(false)).To(BeNil())
+	Expect(maglevRenderer.Init()).To(BeNil())
+	Expect(processor.RegisterRenderer(natRenderer)).To(BeNil())
+	Expect(processor.RegisterRenderer(maglevRenderer)).To(BeNil())
+	resyncEv := datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+
+	// Add pods.
+	dataChange1 := datasync.Put(podmodel.Key(pod1.Name, pod1.Namespace), pod1Model)
+	Expect(processor.Update(dataChange1)).To(BeNil())
+	dataChange2 := datasync.Put(podmodel.Key(pod2.Name, pod2.Namespace), pod2Model)
+	Expect(processor.Update(dataChange2)).To(BeNil())
+
+	// Service1 selects the Maglev renderer with the annotation.
+	service1 := &svcmodel.Service{
+		Name:        "service1",
+		Namespace:   namespace1,
+		ServiceType: "ClusterIP",
+		ClusterIp:   "10.96.0.1",
+		Annotations: map[string]string{
+			svc_processor.ServiceRendererAnnotation: svc_renderer.MaglevRenderer,
+		},
+		Port: []*svcmodel.Service_ServicePort{
+			{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     80,
+			},
+		},
+	}
+	eps1 := &epmodel.Endpoints{
+		Name:      "service1",
+		Namespace: namespace1,
+		EndpointSubsets: []*epmodel.EndpointSubset{
+			{
+				Addresses: []*epmodel.EndpointSubset_EndpointAddress{
+					{
+						Ip:       pod1IP,
+						NodeName: masterLabel,
+						TargetRef: &epmodel.ObjectReference{
+							Kind:      "Pod",
+							Namespace: pod1.Namespace,
+							Name:      pod1.Name,
+						},
+					},
+					{
+						Ip:       pod2IP,
+						NodeName: masterLabel,
+						TargetRef: &epmodel.ObjectReference{
+							Kind:      "Pod",
+							Namespace: pod2.Namespace,
+							Name:      pod2.Name,
+						},
+					},
+				},
+				Ports: []*epmodel.EndpointSubset_EndpointPort{
+					{
+						Name:     "http",
+						Port:     8080,
+						Protocol: "TCP",
+					},
+				},
+			},
+		},
+	}
+
+	dataChange3 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange3)).To(BeNil())
+	dataChange4 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange4)).To(BeNil())
+
+	// Service1 is rendered by Maglev only.
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(1))
+	vip := lbPlugin.GetVIP("10.96.0.1", uint8(svc_renderer.TCP), 80)
+	Expect(vip).ToNot(BeNil())
+	Expect(vip.TargetPort).To(BeEquivalentTo(8080))
+	Expect(vip.ASs).To(Equal([]string{pod1IP, pod2IP}))
+	Expect(lbPlugin.NumOfNAT4Interfaces()).To(Equal(2))
+
+	// Annotation removed - service1 moves to NAT44.
+	service1.Annotations = nil
+	dataChange5 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange5)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(1))
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(0))
+	Expect(lbPlugin.NumOfNAT4Interfaces()).To(Equal(0))
+
+	// Invalid annotation - the configured default renderer is used.
+	contiv.SetServiceRenderer(svc_renderer.MaglevRenderer)
+	service1.Annotations = map[string]string{
+		svc_processor.ServiceRendererAnnotation: "invalid",
+	}
+	dataChange6 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange6)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(1))
+
+	// Weighted local endpoints, all backends are local - Maglev is still used.
+	contiv.SetServiceLocalEndpointWeight(2)
+	dataChange7 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange7)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(1))
+
+	// Weighted local endpoints mixed with a remote endpoint - NAT44 is used instead of Maglev.
+	eps1.EndpointSubsets[0].Addresses = append(eps1.EndpointSubsets[0].Addresses,
+		&epmodel.EndpointSubset_EndpointAddress{
+			Ip:       pod3IP,
+			NodeName: workerLabel,
+			TargetRef: &epmodel.ObjectReference{
+				Kind:      "Pod",
+				Namespace: pod3.Namespace,
+				Name:      pod3.Name,
+			},
+		})
+	dataChange8 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange8)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(1))
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(0))
+
+	// Equal weights - Maglev again.
+	contiv.SetServiceLocalEndpointWeight(localEndpointWeight)
+	dataChange9 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange9)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(1))
+	vip = lbPlugin.GetVIP("10.96.0.1", uint8(svc_renderer.TCP), 80)
+	Expect(vip).ToNot(BeNil())
+	Expect(vip.ASs).To(Equal([]string{pod1IP, pod2IP, pod3IP}))
+
+	// Maglev renderer disabled - NAT44 is used instead.
+	processor.DisableRenderer(svc_renderer.MaglevRenderer)
+	dataChange10 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange10)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(1))
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(0))
+
+	// Service1 removed.
+	dataChange11 := datasync.Delete(svcmodel.Key(service1.Name, service1.Namespace))
+	Expect(processor.Update(dataChange11)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(0))
+	Expect(lbPlugin.GetErrCount()).To(Equal(0))
+
+	// Cleanup
+	Expect(processor.Close()).To(BeNil())
+	Expect(natRenderer.Close()).To(BeNil())
+	Expect(maglevRenderer.Close()).To(BeNil())
+}
+
+func TestIPv6Renderer(t *testing.T) {
+	RegisterTestingT(t)
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	logger.Debug("TestIPv6Renderer")
+
+	// Prepare mocks.
+	//  -> Contiv plugin
+	contiv := NewMockContiv()
+	contiv.SetNatExternalTraffic(true)
+	const localEndpointWeight uint8 = 1
+	contiv.SetServiceLocalEndpointWeight(localEndpointWeight)
+	contiv.SetSTNMode(false)
+	contiv.SetNodeIP(nodeIP + nodePrefix)
+	contiv.SetDefaultInterface(mainIfName, net.ParseIP(nodeIP))
+	contiv.SetMainPhysicalIfName(mainIfName)
+	contiv.SetVxlanBVIIfName(vxlanIfName)
+	contiv.SetHostInterconnectIfName(hostInterIfName)
+	contiv.SetPodNetwork(podNetwork)
+	contiv.SetNatLoopbackIP(natLoopbackIP)
+	contiv.SetPodIfName(pod1, pod1If)
+	contiv.SetPodIfName(pod2, pod2If)
+	contiv.SetMainVrfID(mainVrfID)
+	contiv.SetPodVrfID(podVrfID)
+	contiv.SetHostIPs([]net.IP{net.ParseIP(nodeIP), net.ParseIP(mgmtIP)})
+
+	// -> NAT plugin
+	natPlugin := NewMockNatPlugin(logger)
+
+	// -> LB plugin
+	lbPlugin := getMockLBPlugin(logger)
+
+	// -> localclient
+	txnTracker := localclient.NewTxnTracker(natPlugin.ApplyTxn)
+
+	// -> default VPP plugins
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.SetNat44Global(&nat.Nat44Global{})
+	vppPlugins.SetNat44Dnat(&nat.Nat44DNat{})
+	vppPlugins.AddInterface(pod1If, 1, pod1IP)
+	vppPlugins.AddInterface(pod2If, 2, pod2IP)
+
+	// -> service label
+	serviceLabel := NewMockServiceLabel()
+	serviceLabel.SetAgentLabel(masterLabel)
+
+	// -> datasync
+	datasync := NewMockDataSync()
+
+	// Prepare processor.
+	processor := &svc_processor.ServiceProcessor{
+		Deps: svc_processor.Deps{
+			Log:          logger,
+			ServiceLabel: serviceLabel,
+			Contiv:       contiv,
+			HealthCheck:  NewMockHealthCheck(),
+		},
+	}
+
+	// Prepare NAT44 Renderer.
+	natRenderer := &nat44.Renderer{
+		Deps: nat44.Deps{
+			Log:           logger,
+			VPP:           vppPlugins,
+			Contiv:        contiv,
+			NATTxnFactory: txnTracker.NewLinuxDataChangeTxn,
+			LatestRevs:    txnTracker.LatestRevisions,
+		},
+	}
+
+	// Prepare IPv6 Renderer.
+	ipv6Renderer := &ipv6.Renderer{
+		Deps: ipv6.Deps{
+			Log:       logger,
+			VPP:       vppPlugins,
+			GoVPPChan: lbPlugin.NewVPPChan(),
+		},
+	}
+
+	// Initialize and resync.
+	Expect(processor.Init()).To(BeNil())
+	Expect(natRenderer.Init(false)).To(BeNil())
+	Expect(ipv6Renderer.Init()).To(BeNil())
+	Expect(processor.RegisterRenderer(natRenderer)).To(BeNil())
+	Expect(processor.RegisterRenderer(ipv6Renderer)).To(BeNil())
+	resyncEv := datasync.Resync(keyPrefixes...)
+	Expect(processor.Resync(resyncEv)).To(BeNil())
+
+	// Add pods.
+	dataChange1 := datasync.Put(podmodel.Key(pod1.Name, pod1.Namespace), pod1Model)
+	Expect(processor.Update(dataChange1)).To(BeNil())
+	dataChange2 := datasync.Put(podmodel.Key(pod2.Name, pod2.Namespace), pod2Model)
+	Expect(processor.Update(dataChange2)).To(BeNil())
+
+	// Master node with IPv6 node IP and IPv4 management IP.
+	masterNode := &nodemodel.NodeInfo{
+		Id:                  1,
+		Name:                masterLabel,
+		IpAddress:           "fd00:16::10/64",
+		ManagementIpAddress: mgmtIP,
+	}
+	dataChange3 := datasync.Put(nodemodel.AllocatedIDsKeyPrefix+strconv.FormatUint(uint64(masterNode.Id), 10), masterNode)
+	Expect(processor.Update(dataChange3)).To(BeNil())
+
+	// Service1: IPv6 cluster IP, IPv4 external IP, node port.
+	service1 := &svcmodel.Service{
+		Name:        "service1",
+		Namespace:   namespace1,
+		ServiceType: "NodePort",
+		ClusterIp:   "fd00:96::1",
+		ExternalIps: []string{"20.20.20.20"},
+		Port: []*svcmodel.Service_ServicePort{
+			{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     80,
+				NodePort: 30080,
+			},
+		},
+	}
+	// Endpoints: the same pod with IPv4 and IPv6 address.
+	eps1 := &epmodel.Endpoints{
+		Name:      "service1",
+		Namespace: namespace1,
+		EndpointSubsets: []*epmodel.EndpointSubset{
+			{
+				Addresses: []*epmodel.EndpointSubset_EndpointAddress{
+					{
+						Ip:       pod1IP,
+						NodeName: masterLabel,
+						TargetRef: &epmodel.ObjectReference{
+							Kind:      "Pod",
+							Namespace: pod1.Namespace,
+							Name:      pod1.Name,
+						},
+					},
+					{
+						Ip:       "fd00:1::3",
+						NodeName: masterLabel,
+						TargetRef: &epmodel.ObjectReference{
+							Kind:      "Pod",
+							Namespace: pod1.Namespace,
+							Name:      pod1.Name,
+						},
+					},
+				},
+				Ports: []*epmodel.EndpointSubset_EndpointPort{
+					{
+						Name:     "http",
+						Port:     8080,
+						Protocol: "TCP",
+					},
+				},
+			},
+		},
+	}
+
+	dataChange4 := datasync.Put(epmodel.Key(eps1.Name, eps1.Namespace), eps1)
+	Expect(processor.Update(dataChange4)).To(BeNil())
+	dataChange5 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange5)).To(BeNil())
+
+	// NAT44 renders only the IPv4 part (external IP + node port on the IPv4 node IP).
+	ipv4Locals := []*Local{
+		{
+			VrfID: podVrfID,
+			IP:    net.ParseIP(pod1IP),
+			Port:  8080,
+		},
+	}
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(2))
+	Expect(natPlugin.HasStaticMapping(&StaticMapping{
+		ExternalIP:   net.ParseIP("20.20.20.20"),
+		ExternalPort: 80,
+		Protocol:     svc_renderer.TCP,
+		Locals:       ipv4Locals,
+	})).To(BeTrue())
+	Expect(natPlugin.HasStaticMapping(&StaticMapping{
+		ExternalIP:   net.ParseIP(mgmtIP),
+		ExternalPort: 30080,
+		Protocol:     svc_renderer.TCP,
+		Locals:       ipv4Locals,
+	})).To(BeTrue())
+
+	// IPv6 renderer renders only the IPv6 part.
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(1))
+	vip := lbPlugin.GetVIP("fd00:96::1", uint8(svc_renderer.TCP), 80)
+	Expect(vip).ToNot(BeNil())
+	Expect(vip.NodePort).To(BeEquivalentTo(30080))
+	Expect(vip.TargetPort).To(BeEquivalentTo(8080))
+	Expect(vip.ASs).To(Equal([]string{"fd00:1::3"}))
+	Expect(lbPlugin.NumOfNAT6Interfaces()).To(Equal(1))
+	Expect(lbPlugin.HasNAT6Interface(1)).To(BeTrue())
+	Expect(lbPlugin.NumOfNAT4Interfaces()).To(Equal(0))
+
+	// External IP removed - nothing left for NAT44.
+	service1.ExternalIps = nil
+	service1.ServiceType = "ClusterIP"
+	service1.Port[0].NodePort = 0
+	dataChange6 := datasync.Put(svcmodel.Key(service1.Name, service1.Namespace), service1)
+	Expect(processor.Update(dataChange6)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(1))
+	Expect(lbPlugin.GetVIP("fd00:96::1", uint8(svc_renderer.TCP), 80).NodePort).To(BeEquivalentTo(0))
+
+	// Service1 removed.
+	dataChange7 := datasync.Delete(svcmodel.Key(service1.Name, service1.Namespace))
+	Expect(processor.Update(dataChange7)).To(BeNil())
+	Expect(natPlugin.NumOfStaticMappings()).To(Equal(0))
+	Expect(lbPlugin.NumOfVIPs()).To(Equal(0))
+	Expect(lbPlugin.NumOfNAT6Interfaces()).To(Equal(0))
+	Expect(lbPlugin.GetErrCount()).To(Equal(0))
+
+	// Cleanup
+	Expect(processor.Close()).To(BeNil())
+	Expect(natRenderer.Close()).To(BeNil())
+	Expect(ipv6Renderer.Close()).To(BeNil())
+}
+
 func TestWithSNATOnly(t *testing.T) {
 	RegisterTestingT(t)
 	logger := logrus.DefaultLogger()
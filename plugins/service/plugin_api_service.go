@@ -0,0 +1,28 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"github.com/contiv/vpp/plugins/service/renderer"
+)
+
+// API defines methods provided by the service plugin for use by other plugins.
+type API interface {
+	// RegisterRenderer registers a renderer from outside of the service plugin,
+	// receiving the same data as the renderers of the plugin (e.g. to learn
+	// frontends and backends of services). The renderer should be registered
+	// before the first resync, i.e. at latest in the AfterInit phase.
+	RegisterRenderer(renderer renderer.ServiceRendererAPI) error
+}
@@ -29,7 +29,11 @@ import (
 	"github.com/ligato/vpp-agent/plugins/vpp"
 
 	"github.com/contiv/vpp/plugins/contiv"
+	"github.com/contiv/vpp/plugins/service/healthcheck"
 	"github.com/contiv/vpp/plugins/service/processor"
+	"github.com/contiv/vpp/plugins/service/renderer"
+	"github.com/contiv/vpp/plugins/service/renderer/ipv6"
+	"github.com/contiv/vpp/plugins/service/renderer/maglev"
 	"github.com/contiv/vpp/plugins/service/renderer/nat44"
 
 	"github.com/contiv/vpp/plugins/contiv/model/node"
@@ -63,8 +67,11 @@ type Plugin struct {
 	pendingResync  datasync.ResyncEvent
 	pendingChanges []datasync.ChangeEvent
 
-	processor     *processor.ServiceProcessor
-	nat44Renderer *nat44.Renderer
+	processor      *processor.ServiceProcessor
+	nat44Renderer  *nat44.Renderer
+	maglevRenderer *maglev.Renderer
+	ipv6Renderer   *ipv6.Renderer
+	healthCheck    *healthcheck.Server
 }
 
 // Deps defines dependencies of the service plugin.
@@ -93,11 +100,14 @@ func (p *Plugin) Init() error {
 		return err
 	}
 
+	p.healthCheck = healthcheck.NewServer(p.Log.NewLogger("-healthCheck"))
+
 	p.processor = &processor.ServiceProcessor{
 		Deps: processor.Deps{
 			Log:          p.Log.NewLogger("-serviceProcessor"),
 			ServiceLabel: p.ServiceLabel,
 			Contiv:       p.Contiv,
+			HealthCheck:  p.healthCheck,
 		},
 	}
 	p.processor.Log.SetLevel(logging.DebugLevel)
@@ -117,12 +127,58 @@ func (p *Plugin) Init() error {
 	}
 	p.nat44Renderer.Log.SetLevel(logging.DebugLevel)
 
-	p.processor.Init()
-	p.nat44Renderer.Init(false)
+	lbVppCh, err := p.GoVPP.NewAPIChannel()
+	if err != nil {
+		return err
+	}
+	p.maglevRenderer = &maglev.Renderer{
+		Deps: maglev.Deps{
+			Log:       p.Log.NewLogger("-maglevRenderer"),
+			VPP:       p.VPP,
+			GoVPPChan: lbVppCh,
+		},
+	}
+	p.maglevRenderer.Log.SetLevel(logging.DebugLevel)
 
-	// Register renderers.
+	lb6VppCh, err := p.GoVPP.NewAPIChannel()
+	if err != nil {
+		return err
+	}
+	p.ipv6Renderer = &ipv6.Renderer{
+		Deps: ipv6.Deps{
+			Log:       p.Log.NewLogger("-ipv6Renderer"),
+			VPP:       p.VPP,
+			GoVPPChan: lb6VppCh,
+		},
+	}
+	p.ipv6Renderer.Log.SetLevel(logging.DebugLevel)
+
+	if err = p.processor.Init(); err != nil {
+		return err
+	}
+	if err = p.nat44Renderer.Init(false); err != nil {
+		return err
+	}
 	p.processor.RegisterRenderer(p.nat44Renderer)
 
+	// Renderers built on the LB plugin are optional - VPP may be running
+	// without the plugin or with an incompatible version of its API.
+	if err = p.maglevRenderer.Init(); err != nil {
+		p.Log.Warnf("Maglev service renderer is disabled, services are rendered by NAT44: %v", err)
+		p.processor.DisableRenderer(renderer.MaglevRenderer)
+		lbVppCh.Close()
+		p.maglevRenderer = nil
+	} else {
+		p.processor.RegisterRenderer(p.maglevRenderer)
+	}
+	if err = p.ipv6Renderer.Init(); err != nil {
+		p.Log.Warnf("IPv6 service renderer is disabled, IPv6 services are not rendered: %v", err)
+		lb6VppCh.Close()
+		p.ipv6Renderer = nil
+	} else {
+		p.processor.RegisterRenderer(p.ipv6Renderer)
+	}
+
 	p.ctx, p.cancel = context.WithCancel(context.Background())
 
 	go p.watchEvents()
@@ -234,12 +290,17 @@ func (p *Plugin) handleResync(resyncChan chan resync.StatusEvent) {
 	}
 }
 
+// RegisterRenderer registers a renderer from outside of the service plugin.
+func (p *Plugin) RegisterRenderer(renderer renderer.ServiceRendererAPI) error {
+	return p.processor.RegisterRenderer(renderer)
+}
+
 // Close stops watching of KSR reflected data.
 func (p *Plugin) Close() error {
 	if p.cancel != nil {
 		p.cancel()
 	}
 	p.wg.Wait()
-	safeclose.CloseAll(p.watchConfigReg, p.resyncChan, p.changeChan)
+	safeclose.CloseAll(p.watchConfigReg, p.resyncChan, p.changeChan, p.healthCheck)
 	return nil
 }
@@ -19,6 +19,7 @@ package processor
 import (
 	"net"
 	"strings"
+	"time"
 
 	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/cn-infra/logging"
@@ -29,6 +30,7 @@ import (
 	epmodel "github.com/contiv/vpp/plugins/ksr/model/endpoints"
 	podmodel "github.com/contiv/vpp/plugins/ksr/model/pod"
 	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+	"github.com/contiv/vpp/plugins/service/healthcheck"
 	"github.com/contiv/vpp/plugins/service/renderer"
 	"sync"
 )
@@ -39,6 +41,9 @@ type ServiceProcessor struct {
 
 	renderers []renderer.ServiceRendererAPI
 
+	/* renderers which cannot be used (e.g. not supported by VPP), services are rendered by NAT44 instead */
+	disabledRenderers map[string]struct{}
+
 	/* nodes */
 	nodes map[int]*nodemodel.NodeInfo
 
@@ -46,10 +51,17 @@ type ServiceProcessor struct {
 	services map[svcmodel.ID]*Service
 	localEps map[podmodel.ID]*LocalEndpoint
 
+	/* endpoints imported from remote clusters of the cluster mesh (cluster ID -> endpoints);
+	   not cleared by resync - these are not part of the local K8s state data */
+	remoteEps map[svcmodel.ID]map[string]*epmodel.Endpoints
+
 	/* local frontend and backend interfaces */
 	frontendIfs renderer.Interfaces
 	backendIfs  renderer.Interfaces
 
+	/* timer re-rendering services when the drain period of their backends expires */
+	drainTimer *time.Timer
+
 	sync.Mutex
 }
 
@@ -57,7 +69,8 @@ type ServiceProcessor struct {
 type Deps struct {
 	Log          logging.Logger
 	ServiceLabel servicelabel.ReaderAPI
-	Contiv       contiv.API /* to get all interface names and pod IP network */
+	Contiv       contiv.API      /* to get all interface names and pod IP network */
+	HealthCheck  healthcheck.API /* optional, serves health-check node ports */
 }
 
 // LocalEndpoint represents a node-local endpoint.
@@ -69,8 +82,11 @@ type LocalEndpoint struct {
 // Init initializes service processor.
 func (sp *ServiceProcessor) Init() error {
 	sp.reset()
+	sp.disabledRenderers = make(map[string]struct{})
+	sp.remoteEps = make(map[svcmodel.ID]map[string]*epmodel.Endpoints)
 	sp.Contiv.RegisterPodPreRemovalHook(sp.processDeletingPod)
 	sp.Contiv.RegisterPodPostAddHook(sp.processNewPod)
+	sp.Contiv.RegisterRemoteEndpointsHook(sp.processRemoteEndpoints)
 	return nil
 }
 
@@ -124,6 +140,16 @@ func (sp *ServiceProcessor) RegisterRenderer(renderer renderer.ServiceRendererAP
 	return nil
 }
 
+// DisableRenderer marks the renderer with the given name (e.g. renderer.MaglevRenderer)
+// as unavailable. Services selecting the renderer are rendered by NAT44 instead.
+// Should be called before the first resync.
+func (sp *ServiceProcessor) DisableRenderer(name string) {
+	sp.Lock()
+	defer sp.Unlock()
+
+	sp.disabledRenderers[name] = struct{}{}
+}
+
 func (sp *ServiceProcessor) processNewPod(podNamespace string, podName string) error {
 	sp.Lock()
 	defer sp.Unlock()
@@ -287,6 +313,38 @@ func (sp *ServiceProcessor) processDeletedEndpoints(epsID epmodel.ID) error {
 	return sp.renderService(svc, oldContivSvc, oldBackends)
 }
 
+func (sp *ServiceProcessor) processRemoteEndpoints(clusterID string, epsID epmodel.ID, eps *epmodel.Endpoints) error {
+	sp.Lock()
+	defer sp.Unlock()
+
+	sp.Log.WithFields(logging.Fields{
+		"cluster": clusterID,
+		"epsID":   epsID,
+	}).Debug("ServiceProcessor - processRemoteEndpoints()")
+
+	svcID := svcmodel.ID{Namespace: epsID.Namespace, Name: epsID.Name}
+	if eps != nil {
+		if _, hasEntry := sp.remoteEps[svcID]; !hasEntry {
+			sp.remoteEps[svcID] = make(map[string]*epmodel.Endpoints)
+		}
+		sp.remoteEps[svcID][clusterID] = eps
+	} else {
+		if _, hasEntry := sp.remoteEps[svcID][clusterID]; !hasEntry {
+			return nil
+		}
+		delete(sp.remoteEps[svcID], clusterID)
+		if len(sp.remoteEps[svcID]) == 0 {
+			delete(sp.remoteEps, svcID)
+		}
+	}
+
+	svc := sp.getService(svcID)
+	oldContivSvc := svc.GetContivService()
+	oldBackends := svc.GetLocalBackends()
+	svc.SetRemoteEndpoints(sp.remoteEps[svcID])
+	return sp.renderService(svc, oldContivSvc, oldBackends)
+}
+
 func (sp *ServiceProcessor) processNewService(service *svcmodel.Service) error {
 	sp.Log.WithFields(logging.Fields{
 		"service": *service,
@@ -362,28 +420,27 @@ func (sp *ServiceProcessor) renderService(svc *Service, oldContivSvc *renderer.C
 	newContivSvc := svc.GetContivService()
 	newBackends := svc.GetLocalBackends()
 
+	// Update health-check responders with the new set of local backends.
+	sp.syncHealthChecks()
+
+	// Schedule removal of draining backends.
+	sp.scheduleDrainTimer()
+
 	// Render service.
-	if newContivSvc != nil {
-		if oldContivSvc == nil {
-			for _, renderer := range sp.renderers {
-				if err = renderer.AddService(newContivSvc); err != nil {
-					return err
-				}
-			}
-		} else {
-			for _, renderer := range sp.renderers {
-				if err = renderer.UpdateService(oldContivSvc, newContivSvc); err != nil {
-					return err
-				}
+	for _, renderer := range sp.renderers {
+		oldRendered := filterService(renderer, oldContivSvc)
+		newRendered := filterService(renderer, newContivSvc)
+		if newRendered != nil {
+			if oldRendered == nil {
+				err = renderer.AddService(newRendered)
+			} else {
+				err = renderer.UpdateService(oldRendered, newRendered)
 			}
+		} else if oldRendered != nil {
+			err = renderer.DeleteService(oldRendered)
 		}
-	} else {
-		if oldContivSvc != nil {
-			for _, renderer := range sp.renderers {
-				if err = renderer.DeleteService(oldContivSvc); err != nil {
-					return err
-				}
-			}
+		if err != nil {
+			return err
 		}
 	}
 
@@ -455,7 +512,7 @@ func (sp *ServiceProcessor) renderNodePorts() error {
 		}
 	}
 	for _, renderer := range sp.renderers {
-		err := renderer.UpdateNodePortServices(sp.getNodeIPs(), npServices)
+		err := renderer.UpdateNodePortServices(sp.getNodeIPs(), filterServices(renderer, npServices))
 		if err != nil {
 			return err
 		}
@@ -464,40 +521,39 @@ func (sp *ServiceProcessor) renderNodePorts() error {
 }
 
 // getNodeIPs returns a slice of IP addresses of all nodes in the cluster
-// without duplicities.
+// without duplicities. Both IPv4 and IPv6 node addresses are included,
+// IPv4 addresses in the 4-byte representation.
 func (sp *ServiceProcessor) getNodeIPs() *renderer.IPAddresses {
 	nodeIPs := renderer.NewIPAddresses()
-	addedNodeIPs := map[string]struct{}{}
 
 	for _, node := range sp.nodes {
 		// Node IP (VPP)
-		ipAddr := sp.trimIPAddrPrefix(node.IpAddress)
-		sp.Log.WithField("IPAddr", ipAddr).Debug("Node IP")
-		if _, duplicate := addedNodeIPs[ipAddr]; !duplicate {
-			nodeIP := net.ParseIP(ipAddr)
-			if nodeIP != nil {
-				nodeIPs.Add(nodeIP)
-			}
+		nodeIP := parseIPAddr(node.IpAddress)
+		sp.Log.WithField("IPAddr", nodeIP).Debug("Node IP")
+		if nodeIP != nil {
+			nodeIPs.Add(nodeIP)
 		}
-		addedNodeIPs[ipAddr] = struct{}{}
 		// Node management IP (K8s, host)
-		ipAddr = sp.trimIPAddrPrefix(node.ManagementIpAddress)
-		sp.Log.WithField("IPAddr", ipAddr).Debug("Node mgmt IP")
-		if _, duplicate := addedNodeIPs[ipAddr]; !duplicate {
-			nodeMgmtIP := net.ParseIP(ipAddr)
-			if nodeMgmtIP != nil {
-				nodeIPs.Add(nodeMgmtIP)
-			}
+		nodeMgmtIP := parseIPAddr(node.ManagementIpAddress)
+		sp.Log.WithField("IPAddr", nodeMgmtIP).Debug("Node mgmt IP")
+		if nodeMgmtIP != nil {
+			nodeIPs.Add(nodeMgmtIP)
 		}
-		addedNodeIPs[ipAddr] = struct{}{}
 	}
 
 	return nodeIPs
 }
 
-func (sp *ServiceProcessor) trimIPAddrPrefix(ip string) string {
-	if strings.Contains(ip, "/") {
-		return ip[:strings.Index(ip, "/")]
+// parseIPAddr parses IPv4 or IPv6 address with an optional prefix length.
+// IPv4 address is returned in the 4-byte representation, nil if the address
+// is empty or invalid.
+func parseIPAddr(addr string) net.IP {
+	if strings.Contains(addr, "/") {
+		addr = addr[:strings.Index(addr, "/")]
+	}
+	ip := net.ParseIP(addr)
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
 	}
 	return ip
 }
@@ -595,28 +651,132 @@ func (sp *ServiceProcessor) processResyncEvent(resyncEv *ResyncEventData) error
 		}
 	}
 
+	sp.syncHealthChecks()
+	sp.scheduleDrainTimer()
+
 	// Build resync data for service renderers.
 	confResyncEv.FrontendIfs = sp.frontendIfs
 	confResyncEv.BackendIfs = sp.backendIfs
 	for _, renderer := range sp.renderers {
-		if err := renderer.Resync(confResyncEv); err != nil {
+		rendererResyncEv := *confResyncEv
+		rendererResyncEv.Services = filterServices(renderer, confResyncEv.Services)
+		if err := renderer.Resync(&rendererResyncEv); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// processDrainedBackends re-renders services with backends whose drain period
+// has expired.
+func (sp *ServiceProcessor) processDrainedBackends() {
+	sp.Lock()
+	defer sp.Unlock()
+
+	sp.Log.Debug("ServiceProcessor - processDrainedBackends()")
+
+	now := time.Now()
+	for _, svc := range sp.services {
+		deadline, draining := svc.GetDrainDeadline()
+		if !draining || deadline.After(now) {
+			continue
+		}
+		oldContivSvc := svc.GetContivService()
+		oldBackends := svc.GetLocalBackends()
+		svc.RemoveDrainedBackends()
+		if err := sp.renderService(svc, oldContivSvc, oldBackends); err != nil {
+			sp.Log.WithFields(logging.Fields{
+				"service": oldContivSvc.ID,
+				"err":     err,
+			}).Error("Failed to remove drained service backends")
+		}
+	}
+	sp.scheduleDrainTimer()
+}
+
+// scheduleDrainTimer (re)schedules the timer to fire when the drain period of
+// the first of the draining backends expires.
+func (sp *ServiceProcessor) scheduleDrainTimer() {
+	var next time.Time
+	for _, svc := range sp.services {
+		deadline, draining := svc.GetDrainDeadline()
+		if draining && (next.IsZero() || deadline.Before(next)) {
+			next = deadline
+		}
+	}
+	if sp.drainTimer != nil {
+		sp.drainTimer.Stop()
+		sp.drainTimer = nil
+	}
+	if !next.IsZero() {
+		sp.drainTimer = time.AfterFunc(time.Until(next), sp.processDrainedBackends)
+	}
+}
+
 // Close deallocates resource held by the processor.
 func (sp *ServiceProcessor) Close() error {
+	sp.Lock()
+	defer sp.Unlock()
+
+	if sp.drainTimer != nil {
+		sp.drainTimer.Stop()
+	}
 	return nil
 }
 
 /**** Helper methods ****/
 
+// filterService returns the part of the service to be rendered by the given
+// renderer (nil if the renderer should ignore the service).
+func filterService(rndr renderer.ServiceRendererAPI, service *renderer.ContivService) *renderer.ContivService {
+	if service == nil {
+		return nil
+	}
+	if filter, isFilter := rndr.(renderer.ServiceFilter); isFilter {
+		return filter.FilterService(service)
+	}
+	return service
+}
+
+// filterServices returns the subset of services to be rendered by the given renderer.
+func filterServices(rndr renderer.ServiceRendererAPI, services []*renderer.ContivService) []*renderer.ContivService {
+	if _, isFilter := rndr.(renderer.ServiceFilter); !isFilter {
+		return services
+	}
+	filtered := []*renderer.ContivService{}
+	for _, service := range services {
+		if rendered := filterService(rndr, service); rendered != nil {
+			filtered = append(filtered, rendered)
+		}
+	}
+	return filtered
+}
+
+// syncHealthChecks updates health-check responders to match the node-local
+// services with health-check node port and their number of local backends.
+func (sp *ServiceProcessor) syncHealthChecks() {
+	if sp.HealthCheck == nil {
+		return
+	}
+	checks := make(map[svcmodel.ID]*healthcheck.Check)
+	for svcID, svc := range sp.services {
+		port := svc.GetHealthCheckNodePort()
+		if port == 0 {
+			continue
+		}
+		checks[svcID] = &healthcheck.Check{
+			Port:           port,
+			LocalEndpoints: svc.GetNumOfReadyLocalBackends(),
+		}
+	}
+	sp.HealthCheck.Sync(checks)
+}
+
 func (sp *ServiceProcessor) getService(svcID svcmodel.ID) *Service {
 	_, hasEntry := sp.services[svcID]
 	if !hasEntry {
 		sp.services[svcID] = NewService(sp)
+		sp.services[svcID].SetRemoteEndpoints(sp.remoteEps[svcID])
 	}
 	return sp.services[svcID]
 }
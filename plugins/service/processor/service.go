@@ -18,6 +18,8 @@ package processor
 
 import (
 	"net"
+	"sort"
+	"time"
 
 	"github.com/ligato/cn-infra/logging"
 
@@ -27,14 +29,48 @@ import (
 	"github.com/contiv/vpp/plugins/service/renderer"
 )
 
+// GlobalServiceAnnotation is the annotation which, if set to "true", makes the service
+// global across the cluster mesh - endpoints of the same service from the remote
+// clusters are added to the set of backends.
+const GlobalServiceAnnotation = "contiv.vpp/global-service"
+
+// ServiceRendererAnnotation is the annotation selecting the renderer for the service
+// ("nat44" or "maglev"), overriding the renderer selected in the Contiv configuration.
+const ServiceRendererAnnotation = "contiv.vpp/service-renderer"
+
+// DefaultSessionAffinityTimeout is the session sticky time (in seconds) used for
+// services with the ClientIP affinity and without explicit timeout (3 hours,
+// as defaulted by Kubernetes).
+const DefaultSessionAffinityTimeout = 10800
+
 // Service is used to combine data from the service model with the endpoints.
 type Service struct {
-	sp            *ServiceProcessor
-	meta          *svcmodel.Service
-	endpoints     *epmodel.Endpoints
-	contivSvc     *renderer.ContivService
-	localBackends []podmodel.ID
-	refreshed     bool
+	sp                 *ServiceProcessor
+	meta               *svcmodel.Service
+	endpoints          *epmodel.Endpoints
+	remoteEps          map[string]*epmodel.Endpoints /* cluster ID -> endpoints */
+	contivSvc          *renderer.ContivService
+	localBackends      []podmodel.ID
+	readyLocalBackends int                             /* number of local backends which are not draining */
+	backendPods        map[string]podmodel.ID          /* IP of a ready local backend -> pod */
+	draining           map[backendKey]*drainingBackend /* backends which are no longer ready */
+	refreshed          bool
+}
+
+// backendKey identifies backend of a service port.
+type backendKey struct {
+	port       string /* service port name */
+	ip         string
+	targetPort uint16
+}
+
+// drainingBackend is a backend which was removed from the endpoints or moved
+// into the not-ready addresses, kept with the Draining flag until the drain period
+// expires so that the existing connections are not broken.
+type drainingBackend struct {
+	backend  renderer.ServiceBackend
+	pod      *podmodel.ID /* nil if not a local pod */
+	deadline time.Time
 }
 
 // NewService is a constructor for Service.
@@ -42,6 +78,8 @@ func NewService(sp *ServiceProcessor) *Service {
 	return &Service{
 		sp:            sp,
 		localBackends: []podmodel.ID{},
+		backendPods:   make(map[string]podmodel.ID),
+		draining:      make(map[backendKey]*drainingBackend),
 	}
 }
 
@@ -57,6 +95,18 @@ func (s *Service) SetEndpoints(endpoints *epmodel.Endpoints) {
 	s.refreshed = false
 }
 
+// SetRemoteEndpoints initializes or changes endpoints of the service imported from
+// the remote clusters of the cluster mesh.
+func (s *Service) SetRemoteEndpoints(remoteEps map[string]*epmodel.Endpoints) {
+	s.remoteEps = remoteEps
+	s.refreshed = false
+}
+
+// IsGlobal returns true if the service is marked as global across the cluster mesh.
+func (s *Service) IsGlobal() bool {
+	return s.meta != nil && s.meta.GetAnnotations()[GlobalServiceAnnotation] == "true"
+}
+
 // GetContivService returns the service data represented as ContivService.
 // Returns nil if there are not enough available data.
 func (s *Service) GetContivService() *renderer.ContivService {
@@ -75,25 +125,177 @@ func (s *Service) GetLocalBackends() []podmodel.ID {
 	return s.localBackends
 }
 
+// GetNumOfReadyLocalBackends returns the number of local backends of this service
+// which are ready, i.e. not draining.
+func (s *Service) GetNumOfReadyLocalBackends() int {
+	if !s.refreshed {
+		s.Refresh()
+	}
+	return s.readyLocalBackends
+}
+
+// GetDrainDeadline returns the time when the drain period of the first
+// of the draining backends expires. Returns false if there are no draining
+// backends.
+func (s *Service) GetDrainDeadline() (deadline time.Time, draining bool) {
+	if !s.refreshed {
+		s.Refresh()
+	}
+	for _, backend := range s.draining {
+		if !draining || backend.deadline.Before(deadline) {
+			deadline = backend.deadline
+			draining = true
+		}
+	}
+	return deadline, draining
+}
+
+// RemoveDrainedBackends triggers refresh of the service, which removes backends
+// with the expired drain period.
+func (s *Service) RemoveDrainedBackends() {
+	s.refreshed = false
+}
+
+// GetHealthCheckNodePort returns the health-check node port of the service.
+// Returns zero if the service is not node-local (externalTrafficPolicy=Local)
+// or if the port is not allocated.
+func (s *Service) GetHealthCheckNodePort() uint16 {
+	if s.meta == nil || s.meta.ExternalTrafficPolicy != "Local" {
+		return 0
+	}
+	return uint16(s.meta.HealthCheckNodePort)
+}
+
+// selectRenderer returns the name of the renderer selected for the service
+// - either by the annotation or by the Contiv configuration.
+// Services selecting a disabled renderer are rendered by NAT44 instead.
+func (s *Service) selectRenderer() string {
+	selected := renderer.NAT44Renderer
+	if configured := s.sp.Contiv.GetServiceRenderer(); configured != "" {
+		if isValidRenderer(configured) {
+			selected = configured
+		} else {
+			s.sp.Log.WithField("renderer", configured).Warn("Invalid service renderer in the configuration")
+		}
+	}
+	if annotated, hasAnnotation := s.meta.GetAnnotations()[ServiceRendererAnnotation]; hasAnnotation {
+		if isValidRenderer(annotated) {
+			selected = annotated
+		} else {
+			s.sp.Log.WithFields(logging.Fields{
+				"service":  svcmodel.GetID(s.meta),
+				"renderer": annotated,
+			}).Warn("Invalid service renderer annotation")
+		}
+	}
+	if _, disabled := s.sp.disabledRenderers[selected]; disabled {
+		s.sp.Log.WithFields(logging.Fields{
+			"service":  svcmodel.GetID(s.meta),
+			"renderer": selected,
+		}).Warn("Service renderer is disabled, NAT44 is used instead")
+		selected = renderer.NAT44Renderer
+	}
+	return selected
+}
+
+// hasWeightedBackends returns true if the service combines local backends,
+// weighted by ServiceLocalEndpointWeight, with remote backends (weight 1).
+// The LB plugin used by the Maglev renderer splits the traffic evenly between
+// the backends and a backend cannot be added more than once, such services are
+// therefore rendered by NAT44.
+func (s *Service) hasWeightedBackends() bool {
+	if s.sp.Contiv.GetServiceLocalEndpointWeight() == 1 {
+		return false
+	}
+	var local, remote bool
+	for _, backends := range s.contivSvc.Backends {
+		for _, backend := range backends {
+			if backend.Draining {
+				continue
+			}
+			if backend.Local {
+				local = true
+			} else {
+				remote = true
+			}
+		}
+	}
+	return local && remote
+}
+
+// parseEndpointAddress returns IP address of the endpoint and whether it is
+// local and in the host network. Returns nil IP if the address is not valid.
+func (s *Service) parseEndpointAddress(epAddr *epmodel.EndpointSubset_EndpointAddress) (
+	epIP net.IP, local, hostNetwork bool) {
+
+	epIP = net.ParseIP(epAddr.GetIp())
+	if epIP == nil {
+		s.sp.Log.WithFields(logging.Fields{
+			"service":    s.contivSvc.ID,
+			"endpointIP": epAddr.GetIp(),
+		}).Warn("Failed to parse endpoint IP")
+		return nil, false, false
+	}
+	if epAddr.GetNodeName() == "" || epAddr.GetNodeName() == s.sp.ServiceLabel.GetAgentLabel() {
+		local = true
+	}
+	if !s.sp.Contiv.GetPodSubnet().Contains(epIP) {
+		hostNetwork = true
+	}
+	return epIP, local, hostNetwork
+}
+
+// endpointPod returns ID of the pod referenced by the endpoint address,
+// nil if the endpoint is not a pod.
+func endpointPod(epAddr *epmodel.EndpointSubset_EndpointAddress) *podmodel.ID {
+	targetRef := epAddr.GetTargetRef()
+	if targetRef.GetKind() != "Pod" {
+		return nil
+	}
+	return &podmodel.ID{Name: targetRef.GetName(), Namespace: targetRef.GetNamespace()}
+}
+
+// isValidRenderer returns true if the given name refers to a service renderer.
+func isValidRenderer(name string) bool {
+	return name == renderer.NAT44Renderer || name == renderer.MaglevRenderer
+}
+
 // Refresh combines metadata with endpoints to get ContivService representation
 // and the list of local backends.
 func (s *Service) Refresh() {
-	if s.meta == nil || s.endpoints == nil {
+	hasRemoteEps := s.IsGlobal() && len(s.remoteEps) > 0
+	if s.meta == nil || (s.endpoints == nil && !hasRemoteEps) {
 		s.contivSvc = nil
 		s.localBackends = []podmodel.ID{}
+		s.readyLocalBackends = 0
+		s.backendPods = make(map[string]podmodel.ID)
+		s.draining = make(map[backendKey]*drainingBackend)
 		s.refreshed = true
 		return
 	}
 
+	prevContivSvc := s.contivSvc
+	prevBackendPods := s.backendPods
 	s.contivSvc = renderer.NewContivService()
 	s.localBackends = []podmodel.ID{}
+	s.backendPods = make(map[string]podmodel.ID)
 
 	s.contivSvc.ID = svcmodel.GetID(s.meta)
+	s.contivSvc.Renderer = s.selectRenderer()
 	if s.meta.ExternalTrafficPolicy == "Local" {
 		s.contivSvc.TrafficPolicy = renderer.NodeLocal
 	} else {
 		s.contivSvc.TrafficPolicy = renderer.ClusterWide
 	}
+	if s.meta.SessionAffinity == "ClientIP" {
+		s.contivSvc.SessionAffinity = renderer.ClientIPAffinity
+		s.contivSvc.SessionAffinityTimeout = uint32(s.meta.SessionAffinityTimeout)
+		if s.contivSvc.SessionAffinityTimeout == 0 {
+			s.contivSvc.SessionAffinityTimeout = DefaultSessionAffinityTimeout
+		}
+	}
+
+	s.contivSvc.HealthCheckNodePort = s.GetHealthCheckNodePort()
 
 	// Collect all IP addresses on which the service should be exposed.
 	if s.meta.ClusterIp != "" && s.meta.ClusterIp != "None" {
@@ -120,15 +322,49 @@ func (s *Service) Refresh() {
 		}
 	}
 
+	// Expose LoadBalancer services also on the IP addresses of the load-balancer.
+	if s.meta.ServiceType == "LoadBalancer" {
+		lbIPs := s.meta.LoadbalancerIngressIps
+		if s.meta.LoadbalancerIp != "" {
+			lbIPs = append([]string{s.meta.LoadbalancerIp}, lbIPs...)
+		}
+		for _, lbIPStr := range lbIPs {
+			lbIP := net.ParseIP(lbIPStr)
+			if lbIP != nil {
+				s.contivSvc.ExternalIPs.Add(lbIP)
+				s.contivSvc.LoadBalancerIPs.Add(lbIP)
+			} else {
+				s.sp.Log.WithFields(logging.Fields{
+					"service":        s.contivSvc.ID,
+					"loadBalancerIP": lbIPStr,
+				}).Warn("Failed to parse load-balancer IP")
+			}
+		}
+		for _, sourceRange := range s.meta.LoadbalancerSourceRanges {
+			_, subnet, err := net.ParseCIDR(sourceRange)
+			if err != nil {
+				s.sp.Log.WithFields(logging.Fields{
+					"service":     s.contivSvc.ID,
+					"sourceRange": sourceRange,
+				}).Warn("Failed to parse load-balancer source range")
+				continue
+			}
+			s.contivSvc.LoadBalancerSourceRanges = append(s.contivSvc.LoadBalancerSourceRanges, subnet)
+		}
+	}
+
 	// Fill up the map of service ports.
 	for _, port := range s.meta.Port {
 		sp := &renderer.ServicePort{
 			Port:     uint16(port.GetPort()),
 			NodePort: uint16(port.GetNodePort()),
 		}
-		if port.GetProtocol() == "TCP" {
+		switch port.GetProtocol() {
+		case "TCP":
 			sp.Protocol = renderer.TCP
-		} else {
+		case "SCTP":
+			sp.Protocol = renderer.SCTP
+		default:
 			sp.Protocol = renderer.UDP
 		}
 		s.contivSvc.Ports[port.Name] = sp
@@ -140,25 +376,11 @@ func (s *Service) Refresh() {
 	}
 	for _, epSubSet := range s.endpoints.GetEndpointSubsets() {
 		epPorts := epSubSet.GetPorts()
-		epAddrs := epSubSet.GetAddresses()
-		for _, epAddr := range epAddrs {
-			var local bool
-			var hostNetwork bool
-			epIP := net.ParseIP(epAddr.GetIp())
+		for _, epAddr := range epSubSet.GetAddresses() {
+			epIP, local, hostNetwork := s.parseEndpointAddress(epAddr)
 			if epIP == nil {
-				s.sp.Log.WithFields(logging.Fields{
-					"service":    s.contivSvc.ID,
-					"endpointIP": epAddr.GetIp(),
-				}).Warn("Failed to parse endpoint IP")
 				continue
 			}
-			if epAddr.GetNodeName() == "" || epAddr.GetNodeName() == s.sp.ServiceLabel.GetAgentLabel() {
-				local = true
-			}
-			if !s.sp.Contiv.GetPodSubnet().Contains(epIP) {
-				hostNetwork = true
-			}
-
 			for _, epPort := range epPorts {
 				port := epPort.GetName()
 				if _, exposedPort := s.contivSvc.Ports[port]; exposedPort {
@@ -172,14 +394,211 @@ func (s *Service) Refresh() {
 			}
 			if local {
 				// Get target pod and add it to the set of local backends.
-				targetPod := epAddr.GetTargetRef()
-				if targetPod.GetKind() == "Pod" {
-					s.localBackends = append(s.localBackends,
-						podmodel.ID{Name: targetPod.GetName(), Namespace: targetPod.GetNamespace()})
+				if podID := endpointPod(epAddr); podID != nil {
+					s.localBackends = append(s.localBackends, *podID)
+					s.backendPods[epIP.String()] = *podID
 				}
 			}
 		}
 	}
 
+	// Collect backends which are not ready (e.g. failing the readiness probe)
+	// - candidates for draining.
+	notReady := make(map[backendKey]*drainingBackend)
+	for _, epSubSet := range s.endpoints.GetEndpointSubsets() {
+		epPorts := epSubSet.GetPorts()
+		for _, epAddr := range epSubSet.GetNotReadyAddresses() {
+			epIP, local, hostNetwork := s.parseEndpointAddress(epAddr)
+			if epIP == nil {
+				continue
+			}
+			for _, epPort := range epPorts {
+				port := epPort.GetName()
+				if _, exposedPort := s.contivSvc.Ports[port]; exposedPort {
+					db := &drainingBackend{}
+					db.backend.IP = epIP
+					db.backend.Port = uint16(epPort.GetPort())
+					db.backend.Local = local
+					db.backend.HostNetwork = hostNetwork
+					db.backend.Draining = true
+					if local {
+						db.pod = endpointPod(epAddr)
+					}
+					notReady[newBackendKey(port, &db.backend)] = db
+				}
+			}
+		}
+	}
+
+	// Add backends from the remote clusters if the service is global.
+	if hasRemoteEps {
+		for clusterID, remoteEps := range s.remoteEps {
+			for _, epSubSet := range remoteEps.GetEndpointSubsets() {
+				epPorts := epSubSet.GetPorts()
+				for _, epAddr := range epSubSet.GetAddresses() {
+					epIP := net.ParseIP(epAddr.GetIp())
+					if epIP == nil {
+						s.sp.Log.WithFields(logging.Fields{
+							"service":    s.contivSvc.ID,
+							"cluster":    clusterID,
+							"endpointIP": epAddr.GetIp(),
+						}).Warn("Failed to parse remote endpoint IP")
+						continue
+					}
+					for _, epPort := range epPorts {
+						port := epPort.GetName()
+						if _, exposedPort := s.contivSvc.Ports[port]; exposedPort {
+							sb := &renderer.ServiceBackend{}
+							sb.IP = epIP
+							sb.Port = uint16(epPort.GetPort())
+							s.contivSvc.Backends[port] = append(s.contivSvc.Backends[port], sb)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Keep backends which are no longer ready draining.
+	s.readyLocalBackends = len(s.localBackends)
+	s.updateDrainingBackends(prevContivSvc, prevBackendPods, notReady)
+
+	if s.contivSvc.Renderer == renderer.MaglevRenderer && s.hasWeightedBackends() {
+		s.sp.Log.WithFields(logging.Fields{
+			"service": s.contivSvc.ID,
+			"weight":  s.sp.Contiv.GetServiceLocalEndpointWeight(),
+		}).Warn("Maglev renderer cannot weight local and remote backends, NAT44 is used instead")
+		s.contivSvc.Renderer = renderer.NAT44Renderer
+	}
+
 	s.refreshed = true
 }
+
+// updateDrainingBackends updates the set of draining backends and adds them
+// into the ContivService with the Draining flag.
+// Backends of <prevContivSvc> which are neither among the ready backends
+// of the refreshed service (i.e. were removed from the endpoints or moved into
+// the not-ready addresses) start draining for the period configured in Contiv.
+// Backends listed in <notReady> (not-ready addresses of the endpoints) start
+// draining also when the service is refreshed for the first time (e.g. after
+// a restart of the agent), since they might have been ready before.
+// Draining backends with expired period and those which are ready again
+// are removed.
+func (s *Service) updateDrainingBackends(prevContivSvc *renderer.ContivService,
+	prevBackendPods map[string]podmodel.ID, notReady map[backendKey]*drainingBackend) {
+
+	now := time.Now()
+	ready := make(map[backendKey]struct{})
+	for port, backends := range s.contivSvc.Backends {
+		for _, backend := range backends {
+			ready[newBackendKey(port, backend)] = struct{}{}
+		}
+	}
+
+	// -> backends already draining
+	draining := make(map[backendKey]*drainingBackend)
+	for key, backend := range s.draining {
+		if _, isReady := ready[key]; isReady {
+			continue
+		}
+		if _, exposedPort := s.contivSvc.Ports[key.port]; !exposedPort {
+			continue
+		}
+		if !now.Before(backend.deadline) {
+			continue
+		}
+		draining[key] = backend
+	}
+
+	// -> backends which are no longer ready
+	drainPeriod := s.sp.Contiv.GetServiceBackendDrainPeriod()
+	if prevContivSvc != nil && drainPeriod > 0 {
+		for port, backends := range prevContivSvc.Backends {
+			if _, exposedPort := s.contivSvc.Ports[port]; !exposedPort {
+				continue
+			}
+			for _, backend := range backends {
+				key := newBackendKey(port, backend)
+				if _, isReady := ready[key]; isReady || backend.Draining {
+					continue
+				}
+				db := &drainingBackend{
+					backend:  *backend,
+					deadline: now.Add(drainPeriod),
+				}
+				db.backend.Draining = true
+				if nrb, isNotReady := notReady[key]; isNotReady {
+					db.pod = nrb.pod
+				}
+				if podID, isPod := prevBackendPods[backend.IP.String()]; isPod && backend.Local && db.pod == nil {
+					db.pod = &podID
+				}
+				draining[key] = db
+				s.sp.Log.WithFields(logging.Fields{
+					"service": s.contivSvc.ID,
+					"port":    port,
+					"backend": backend,
+				}).Debug("Service backend is draining")
+			}
+		}
+	}
+
+	// -> not-ready backends of a service refreshed for the first time
+	if prevContivSvc == nil && drainPeriod > 0 {
+		for key, nrb := range notReady {
+			if _, isReady := ready[key]; isReady {
+				continue
+			}
+			if _, isDraining := draining[key]; isDraining {
+				continue
+			}
+			nrb.deadline = now.Add(drainPeriod)
+			draining[key] = nrb
+			s.sp.Log.WithFields(logging.Fields{
+				"service": s.contivSvc.ID,
+				"port":    key.port,
+				"backend": nrb.backend,
+			}).Debug("Not-ready service backend is draining")
+		}
+	}
+	s.draining = draining
+
+	// -> add draining backends into the service
+	keys := make([]backendKey, 0, len(draining))
+	for key := range draining {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].port != keys[j].port {
+			return keys[i].port < keys[j].port
+		}
+		if keys[i].ip != keys[j].ip {
+			return keys[i].ip < keys[j].ip
+		}
+		return keys[i].targetPort < keys[j].targetPort
+	})
+	for _, key := range keys {
+		db := draining[key]
+		backend := db.backend
+		s.contivSvc.Backends[key.port] = append(s.contivSvc.Backends[key.port], &backend)
+		if db.pod != nil && !s.hasLocalBackend(*db.pod) {
+			// interface of the pod still has to be configured for the existing sessions
+			s.localBackends = append(s.localBackends, *db.pod)
+		}
+	}
+}
+
+// hasLocalBackend returns true if the given pod is among the local backends.
+func (s *Service) hasLocalBackend(podID podmodel.ID) bool {
+	for _, localBackend := range s.localBackends {
+		if localBackend == podID {
+			return true
+		}
+	}
+	return false
+}
+
+// newBackendKey returns key identifying backend of the given service port.
+func newBackendKey(port string, backend *renderer.ServiceBackend) backendKey {
+	return backendKey{port: port, ip: backend.IP.String(), targetPort: backend.Port}
+}
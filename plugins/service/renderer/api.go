@@ -36,7 +36,8 @@ import (
 // stack - one being the default, others possibly experimental or tailor-made
 // for specific applications. The set of renderers to be activated can be
 // configurable or determined from the environment. Every active renderer is
-// given the same set of data from the processor.
+// given the same set of data from the processor, unless it implements
+// the ServiceFilter interface to select only a subset of it.
 //
 // ServiceRendererAPI is the interface that connects processor's southbound
 // with the renderer's northbound. For a single Kubernetes service, all relevant
@@ -104,6 +105,26 @@ type ServiceRendererAPI interface {
 	Resync(resyncEv *ResyncEventData) error
 }
 
+// ServiceFilter can be optionally implemented by a service renderer to receive
+// only a subset of services, or a subset of the service data, from the processor.
+// For example, renderers can be selected per service or split by the IP version.
+type ServiceFilter interface {
+	// FilterService returns the part of the service that should be rendered
+	// by this renderer, nil if the service should be ignored altogether.
+	// The given service must not be modified (return a copy instead).
+	FilterService(service *ContivService) *ContivService
+}
+
+const (
+	// NAT44Renderer is the name of the default renderer, implementing services
+	// with VPP-NAT44 static mappings.
+	NAT44Renderer = "nat44"
+
+	// MaglevRenderer is the name of the renderer implementing services with
+	// the VPP load-balancer plugin (Maglev consistent hashing).
+	MaglevRenderer = "maglev"
+)
+
 // ContivService is a less-abstract, free of indirect references representation
 // of K8s Service.
 // It has:
@@ -114,19 +135,46 @@ type ContivService struct {
 	// ID uniquely identifies service across all namespaces.
 	ID svcmodel.ID
 
+	// Renderer is the name of the renderer selected to load-balance the IPv4 part
+	// of the service (NAT44Renderer or MaglevRenderer). The IPv6 part is always
+	// handled by the IPv6 renderer.
+	Renderer string
+
 	// TrafficPolicy decides if traffic is routed cluster-wide or node-local only.
 	TrafficPolicy TrafficPolicyType
 
+	// SessionAffinity decides if connections from the same client should be
+	// passed to the same backend.
+	SessionAffinity SessionAffinityType
+
+	// SessionAffinityTimeout is the maximum session sticky time in seconds
+	// (used only with the ClientIP affinity).
+	SessionAffinityTimeout uint32
+
+	// HealthCheckNodePort is the node port on which the agent answers health-checks
+	// of the service (only with the node-local traffic policy, 0 if none).
+	HealthCheckNodePort uint16
+
 	// ExternalIPs is a set of all IP addresses on which the service
 	// should be exposed on this node (aside from node IPs for NodePorts, which
 	// are provided separately via the ServiceRendererAPI.UpdateNodePortServices()
 	// method).
 	ExternalIPs *IPAddresses
 
+	// LoadBalancerIPs is a subset of ExternalIPs with the IP addresses of the
+	// load-balancer (used only with Service Type: LoadBalancer).
+	LoadBalancerIPs *IPAddresses
+
+	// LoadBalancerSourceRanges, if non-empty, restrict access through
+	// LoadBalancerIPs to the given client subnets.
+	LoadBalancerSourceRanges []*net.IPNet
+
 	// Ports is a map of all ports exposed for this service.
 	Ports map[string] /* service port name */ *ServicePort
 
 	// Backends map external service ports with corresponding backends (= endpoints).
+	// Terminating and no longer ready backends are included with the Draining
+	// flag for the drain period configured in Contiv (ServiceBackendDrainPeriod).
 	Backends map[string] /*service port name */ []*ServiceBackend
 }
 
@@ -141,12 +189,25 @@ const (
 	NodeLocal TrafficPolicyType = 1
 )
 
+// SessionAffinityType is either None or ClientIP.
+type SessionAffinityType int
+
+const (
+	// NoAffinity allows to load-balance every connection independently.
+	NoAffinity SessionAffinityType = 0
+
+	// ClientIPAffinity passes connections from the same client IP to the same
+	// backend (until the affinity timeout).
+	ClientIPAffinity SessionAffinityType = 1
+)
+
 // NewContivService is a constructor for ContivService.
 func NewContivService() *ContivService {
 	return &ContivService{
-		ExternalIPs: NewIPAddresses(),
-		Ports:       make(map[string]*ServicePort),
-		Backends:    make(map[string][]*ServiceBackend),
+		ExternalIPs:     NewIPAddresses(),
+		LoadBalancerIPs: NewIPAddresses(),
+		Ports:           make(map[string]*ServicePort),
+		Backends:        make(map[string][]*ServiceBackend),
 	}
 }
 
@@ -175,8 +236,12 @@ func (cs ContivService) String() string {
 		}
 		idx++
 	}
-	return fmt.Sprintf("ContivService %s <Traffic-Policy:%s ExternalIPs:[%s] Backends:{%s}>",
-		cs.ID.String(), cs.TrafficPolicy.String(), externalIPs, allBackends)
+	affinity := cs.SessionAffinity.String()
+	if cs.SessionAffinity == ClientIPAffinity {
+		affinity += fmt.Sprintf("/%ds", cs.SessionAffinityTimeout)
+	}
+	return fmt.Sprintf("ContivService %s <Renderer:%s Traffic-Policy:%s Session-Affinity:%s ExternalIPs:[%s] Backends:{%s}>",
+		cs.ID.String(), cs.Renderer, cs.TrafficPolicy.String(), affinity, externalIPs, allBackends)
 }
 
 // String converts TrafficPolicyType into a human-readable string.
@@ -190,6 +255,17 @@ func (tpt TrafficPolicyType) String() string {
 	return "INVALID"
 }
 
+// String converts SessionAffinityType into a human-readable string.
+func (sat SessionAffinityType) String() string {
+	switch sat {
+	case NoAffinity:
+		return "none"
+	case ClientIPAffinity:
+		return "client-IP"
+	}
+	return "INVALID"
+}
+
 // HasNodePort returns true if service is also exposed on the Node IP.
 func (cs ContivService) HasNodePort() bool {
 	for _, port := range cs.Ports {
@@ -200,6 +276,40 @@ func (cs ContivService) HasNodePort() bool {
 	return false
 }
 
+// FilterIPVersion returns a copy of the service restricted to the IP addresses
+// (external IPs, load-balancer IPs, source ranges) and backends of the given
+// IP version. Returns nil if the service has no external IP of that version.
+// Ports are shared with the original service.
+func (cs ContivService) FilterIPVersion(ipv6 bool) *ContivService {
+	externalIPs := cs.ExternalIPs.FilterIPVersion(ipv6)
+	if len(externalIPs.List()) == 0 {
+		return nil
+	}
+	filtered := cs
+	filtered.ExternalIPs = externalIPs
+	filtered.LoadBalancerIPs = cs.LoadBalancerIPs.FilterIPVersion(ipv6)
+	filtered.LoadBalancerSourceRanges = nil
+	for _, sourceRange := range cs.LoadBalancerSourceRanges {
+		if IsIPv6(sourceRange.IP) == ipv6 {
+			filtered.LoadBalancerSourceRanges = append(filtered.LoadBalancerSourceRanges, sourceRange)
+		}
+	}
+	filtered.Ports = make(map[string]*ServicePort)
+	for portName, port := range cs.Ports {
+		filtered.Ports[portName] = port
+	}
+	filtered.Backends = make(map[string][]*ServiceBackend)
+	for portName, backends := range cs.Backends {
+		filtered.Backends[portName] = []*ServiceBackend{}
+		for _, backend := range backends {
+			if IsIPv6(backend.IP) == ipv6 {
+				filtered.Backends[portName] = append(filtered.Backends[portName], backend)
+			}
+		}
+	}
+	return &filtered
+}
+
 // ServicePort contains information on service's port.
 type ServicePort struct {
 	Protocol ProtocolType /* protocol type */
@@ -215,7 +325,7 @@ func (sp ServicePort) String() string {
 	return fmt.Sprintf("%d:%d/%s", sp.Port, sp.NodePort, sp.Protocol.String())
 }
 
-// ProtocolType is either TCP or UDP.
+// ProtocolType is either TCP, UDP or SCTP.
 type ProtocolType int
 
 const (
@@ -224,6 +334,9 @@ const (
 
 	// UDP protocol.
 	UDP ProtocolType = 17
+
+	// SCTP protocol.
+	SCTP ProtocolType = 132
 )
 
 // String converts ProtocolType into a human-readable string.
@@ -233,6 +346,8 @@ func (pt ProtocolType) String() string {
 		return "TCP"
 	case UDP:
 		return "UDP"
+	case SCTP:
+		return "SCTP"
 	}
 	return "INVALID"
 }
@@ -243,11 +358,12 @@ type ServiceBackend struct {
 	Port        uint16 /* backend-local port on which the service listens */
 	Local       bool   /* true if the backend is deployed on this node (can be leveraged for smart load-balancing) */
 	HostNetwork bool   /* true if the backend uses host networking */
+	Draining    bool   /* true if the backend is terminating or no longer ready - should not receive new connections */
 }
 
 // String converts Backend into a human-readable string.
 func (sb ServiceBackend) String() string {
-	return fmt.Sprintf("<IP:%s Port:%d, Local:%t>", sb.IP, sb.Port, sb.Local)
+	return fmt.Sprintf("<IP:%s Port:%d, Local:%t, Draining:%t>", sb.IP, sb.Port, sb.Local, sb.Draining)
 }
 
 // IPAddresses is a set of IP addresses.
@@ -308,6 +424,22 @@ func (addrs *IPAddresses) Has(addr net.IP) bool {
 	return false
 }
 
+// FilterIPVersion returns the subset of IP addresses of the given IP version.
+func (addrs *IPAddresses) FilterIPVersion(ipv6 bool) *IPAddresses {
+	filtered := NewIPAddresses()
+	for _, addr := range addrs.list {
+		if IsIPv6(addr) == ipv6 {
+			filtered.list = append(filtered.list, addr)
+		}
+	}
+	return filtered
+}
+
+// IsIPv6 returns true if the given IP address is IPv6 (not IPv4 or IPv4-mapped).
+func IsIPv6(addr net.IP) bool {
+	return addr.To4() == nil
+}
+
 // String converts a set of IP addresses into a human-readable string.
 func (addrs IPAddresses) String() string {
 	str := "{"
@@ -0,0 +1,148 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package ipv6
+
+import (
+	govpp "git.fd.io/govpp.git/api"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/vpp-agent/plugins/vpp"
+
+	"github.com/contiv/vpp/plugins/service/renderer"
+	"github.com/contiv/vpp/plugins/service/renderer/lbvips"
+)
+
+// Renderer implements rendering of services for IPv6 in VPP using the NAT6 mode
+// of the load-balancer (LB) plugin.
+//
+// The renderer is registered alongside the IPv4 renderers (NAT44, Maglev) and
+// receives only the IPv6 part of every service - IPv6 cluster IP, external
+// and load-balancer IPs together with IPv6 backends. Services without any
+// IPv6 address are ignored. VPP-NAT66 supports only 1:1 address translation
+// without ports and load-balancing, therefore the LB plugin is used instead,
+// with the destination translated to the backend selected by the Maglev
+// consistent hashing.
+//
+// Services are mapped to IPv6 VIPs in the NAT6 mode by the lbvips Configurator.
+// Node ports are attached to the VIP of the cluster IP - the LB plugin then
+// accepts the node port on every local IPv6 address, therefore
+// UpdateNodePortServices() requires no action. Backend interfaces are enabled
+// with the NAT6 in2out feature.
+//
+// Limitations of the Maglev renderer (see the maglev package) apply also here:
+// no load-balancing weights, no ClientIP session affinity, default VRF only
+// and LoadBalancerSourceRanges are not enforced.
+type Renderer struct {
+	Deps
+
+	vips *lbvips.Configurator
+}
+
+// Deps lists dependencies of the Renderer.
+type Deps struct {
+	Log             logging.Logger
+	VPP             vpp.API       /* interface indexes */
+	GoVPPChan       govpp.Channel /* used for LB binary API calls */
+	FlowTableLength uint32        /* size of the new-flows table of VIPs, lbvips.DefaultFlowTableLength if zero */
+}
+
+// Init initializes the renderer.
+// Returns an error if VPP does not support the binary API of the LB plugin.
+func (rndr *Renderer) Init() error {
+	rndr.vips = &lbvips.Configurator{
+		Deps: lbvips.Deps{
+			Log:             rndr.Log,
+			VPP:             rndr.VPP,
+			GoVPPChan:       rndr.GoVPPChan,
+			IPv6:            true,
+			FlowTableLength: rndr.FlowTableLength,
+		},
+	}
+	return rndr.vips.Init()
+}
+
+// FilterService selects the IPv6 part of every service (regardless of the renderer
+// selected for IPv4).
+func (rndr *Renderer) FilterService(service *renderer.ContivService) *renderer.ContivService {
+	return service.FilterIPVersion(true)
+}
+
+// AddService configures IPv6 VIPs of a newly added service.
+func (rndr *Renderer) AddService(service *renderer.ContivService) error {
+	rndr.Log.WithFields(logging.Fields{
+		"service": service,
+	}).Debug("IPv6Renderer - AddService()")
+
+	return rndr.vips.AddService(service)
+}
+
+// UpdateService updates IPv6 VIPs of a changed service.
+func (rndr *Renderer) UpdateService(oldService, newService *renderer.ContivService) error {
+	rndr.Log.WithFields(logging.Fields{
+		"oldService": oldService,
+		"newService": newService,
+	}).Debug("IPv6Renderer - UpdateService()")
+
+	return rndr.vips.UpdateService(oldService, newService)
+}
+
+// DeleteService removes IPv6 VIPs of an un-deployed service.
+func (rndr *Renderer) DeleteService(service *renderer.ContivService) error {
+	rndr.Log.WithFields(logging.Fields{
+		"service": service,
+	}).Debug("IPv6Renderer - DeleteService()")
+
+	return rndr.vips.DeleteService(service)
+}
+
+// UpdateNodePortServices does nothing - the LB plugin accepts node ports
+// on all local IPv6 addresses.
+func (rndr *Renderer) UpdateNodePortServices(nodeIPs *renderer.IPAddresses,
+	npServices []*renderer.ContivService) error {
+	return nil
+}
+
+// UpdateLocalFrontendIfs does nothing - VIPs are matched in the FIB regardless
+// of the ingress interface.
+func (rndr *Renderer) UpdateLocalFrontendIfs(oldIfNames, newIfNames renderer.Interfaces) error {
+	return nil
+}
+
+// UpdateLocalBackendIfs enables the NAT6 in2out feature of the LB plugin
+// for interfaces connecting service backends with VPP.
+func (rndr *Renderer) UpdateLocalBackendIfs(oldIfNames, newIfNames renderer.Interfaces) error {
+	rndr.Log.WithFields(logging.Fields{
+		"oldIfNames": oldIfNames,
+		"newIfNames": newIfNames,
+	}).Debug("IPv6Renderer - UpdateLocalBackendIfs()")
+
+	return rndr.vips.UpdateBackendIfs(newIfNames)
+}
+
+// Resync reconciles the configuration of the LB plugin with the provided
+// full state of K8s services.
+func (rndr *Renderer) Resync(resyncEv *renderer.ResyncEventData) error {
+	rndr.Log.WithFields(logging.Fields{
+		"resyncEv": resyncEv,
+	}).Debug("IPv6Renderer - Resync()")
+
+	return rndr.vips.Resync(resyncEv.Services, resyncEv.BackendIfs)
+}
+
+// Close deallocates resources held by the renderer.
+func (rndr *Renderer) Close() error {
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipv6
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	. "github.com/contiv/vpp/mock/lbplugin"
+	. "github.com/contiv/vpp/mock/pluginvpp"
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+	"github.com/contiv/vpp/plugins/service/renderer"
+)
+
+const (
+	tcp = uint8(renderer.TCP)
+
+	pod1If = "master-tap1"
+	pod2If = "master-tap2"
+
+	pod1IfIndex = 11
+	pod2IfIndex = 12
+
+	// LB plugin API values
+	encapNAT6        = 4
+	vipTypeClusterIP = 0
+	vipTypeNodePort  = 1
+)
+
+var mockLB *MockLBPlugin
+
+func TestMain(m *testing.M) {
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	mockLB = NewMockLBPlugin(logger)
+	os.Exit(m.Run())
+}
+
+// newRenderer creates a new instance of the renderer connected with the LB plugin mock.
+func newRenderer() *Renderer {
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.AddInterface(pod1If, pod1IfIndex, "fd00:1::3")
+	vppPlugins.AddInterface(pod2If, pod2IfIndex, "fd00:1::4")
+
+	rndr := &Renderer{
+		Deps: Deps{
+			Log:       logrus.DefaultLogger(),
+			VPP:       vppPlugins,
+			GoVPPChan: mockLB.NewVPPChan(),
+		},
+	}
+	Expect(rndr.Init()).To(BeNil())
+	return rndr
+}
+
+// newService returns dual-stack service exposed on an IPv6 cluster IP, IPv6
+// external IP and IPv4 external IP, with TCP port also exposed as node port.
+func newService(name, clusterIP string) *renderer.ContivService {
+	service := renderer.NewContivService()
+	service.ID = svcmodel.ID{Name: name, Namespace: "default"}
+	service.Renderer = renderer.NAT44Renderer
+	service.ExternalIPs.Add(net.ParseIP(clusterIP))
+	service.ExternalIPs.Add(net.ParseIP("2001:db8::20"))
+	service.ExternalIPs.Add(net.ParseIP("20.20.20.20"))
+	service.Ports["http"] = &renderer.ServicePort{Protocol: renderer.TCP, Port: 80, NodePort: 30080}
+	service.Backends["http"] = []*renderer.ServiceBackend{
+		{IP: net.ParseIP("fd00:1::3"), Port: 8080, Local: true},
+		{IP: net.ParseIP("fd00:2::1"), Port: 8080, Local: false},
+		{IP: net.ParseIP("10.1.1.3"), Port: 8080, Local: true},
+	}
+	return service
+}
+
+func TestFilterService(t *testing.T) {
+	RegisterTestingT(t)
+
+	rndr := newRenderer()
+	service := newService("service1", "fd00:96::1")
+	filtered := rndr.FilterService(service)
+	Expect(filtered).ToNot(BeNil())
+	Expect(filtered.ExternalIPs.List()).To(HaveLen(2))
+	Expect(filtered.ExternalIPs.Has(net.ParseIP("20.20.20.20"))).To(BeFalse())
+	Expect(filtered.Backends["http"]).To(HaveLen(2))
+	Expect(service.Backends["http"]).To(HaveLen(3))
+
+	// IPv4-only service is ignored
+	service.ExternalIPs = renderer.NewIPAddresses(net.ParseIP("10.96.0.1"))
+	Expect(rndr.FilterService(service)).To(BeNil())
+}
+
+func TestServiceLifecycle(t *testing.T) {
+	RegisterTestingT(t)
+	mockLB.Clear()
+	rndr := newRenderer()
+
+	backendIfs := renderer.NewInterfaces(pod1If, pod2If)
+	Expect(rndr.UpdateLocalBackendIfs(renderer.NewInterfaces(), backendIfs)).To(BeNil())
+	Expect(mockLB.NumOfNAT6Interfaces()).To(Equal(0))
+
+	// add service (IPv6 part only)
+	service := rndr.FilterService(newService("service1", "fd00:96::1"))
+	Expect(rndr.AddService(service)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(2))
+	allBackends := []string{"fd00:1::3", "fd00:2::1"}
+
+	vip := mockLB.GetVIP("fd00:96::1", tcp, 80)
+	Expect(vip).ToNot(BeNil())
+	Expect(vip.Encap).To(BeEquivalentTo(encapNAT6))
+	Expect(vip.Type).To(BeEquivalentTo(vipTypeNodePort))
+	Expect(vip.NodePort).To(BeEquivalentTo(30080))
+	Expect(vip.TargetPort).To(BeEquivalentTo(8080))
+	Expect(vip.ASs).To(Equal(allBackends))
+
+	vip = mockLB.GetVIP("2001:db8::20", tcp, 80)
+	Expect(vip).ToNot(BeNil())
+	Expect(vip.Type).To(BeEquivalentTo(vipTypeClusterIP))
+	Expect(vip.ASs).To(Equal(allBackends))
+	Expect(mockLB.GetVIP("20.20.20.20", tcp, 80)).To(BeNil())
+
+	Expect(mockLB.NumOfNAT6Interfaces()).To(Equal(2))
+	Expect(mockLB.HasNAT6Interface(pod1IfIndex)).To(BeTrue())
+	Expect(mockLB.NumOfNAT4Interfaces()).To(Equal(0))
+
+	// switch to node-local traffic policy
+	newService := rndr.FilterService(newService("service1", "fd00:96::1"))
+	newService.TrafficPolicy = renderer.NodeLocal
+	Expect(rndr.UpdateService(service, newService)).To(BeNil())
+	Expect(mockLB.GetVIP("fd00:96::1", tcp, 80).ASs).To(Equal(allBackends))
+	Expect(mockLB.GetVIP("2001:db8::20", tcp, 80).ASs).To(Equal([]string{"fd00:1::3"}))
+
+	// delete service
+	Expect(rndr.DeleteService(newService)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(0))
+	Expect(mockLB.NumOfNAT6Interfaces()).To(Equal(0))
+	Expect(mockLB.GetErrCount()).To(Equal(0))
+}
+
+func TestResync(t *testing.T) {
+	RegisterTestingT(t)
+	mockLB.Clear()
+	rndr := newRenderer()
+
+	service1 := rndr.FilterService(newService("service1", "fd00:96::1"))
+	service2 := rndr.FilterService(newService("service2", "fd00:96::2"))
+	service2.ExternalIPs = renderer.NewIPAddresses(net.ParseIP("fd00:96::2"))
+
+	// initial resync
+	resyncEv := renderer.NewResyncEventData()
+	resyncEv.Services = append(resyncEv.Services, service1)
+	resyncEv.BackendIfs.Add(pod1If)
+	Expect(rndr.Resync(resyncEv)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(2))
+	Expect(mockLB.NumOfNAT6Interfaces()).To(Equal(1))
+
+	// resync after restart of the agent - configuration already in VPP
+	rndr = newRenderer()
+	resyncEv.Services = append(resyncEv.Services, service2)
+	Expect(rndr.Resync(resyncEv)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(3))
+	Expect(mockLB.NumOfNAT6Interfaces()).To(Equal(1))
+
+	// resync without services
+	Expect(rndr.Resync(renderer.NewResyncEventData())).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(0))
+	Expect(mockLB.NumOfNAT6Interfaces()).To(Equal(0))
+	Expect(mockLB.GetErrCount()).To(Equal(0))
+}
@@ -0,0 +1,148 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package lbvips
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ligato/vpp-agent/plugins/vpp/binapi/vpe"
+
+	"github.com/contiv/vpp/plugins/service/renderer"
+)
+
+const (
+	// showVIPsCmd is the VPP CLI command listing VIPs of the LB plugin with their ASs
+	// (the binary API of the LB plugin in VPP 18.07 has no dump requests).
+	showVIPsCmd = "show lb vips verbose"
+
+	// VIP types (as printed by the CLI) of the NAT4/NAT6 mode.
+	vipTypeNameNAT4 = "ip4-nat4"
+	vipTypeNameNAT6 = "ip6-nat6"
+)
+
+var (
+	// vipHeaderRegexp matches the first line of a VIP in the output of showVIPsCmd, e.g.:
+	// " ip4-nat4 [1] 10.96.0.1/32".
+	vipHeaderRegexp = regexp.MustCompile(`^\s*(\S+)\s+\[\d+\]\s+(\S+)/(\d+)(\s+removed)?\s*$`)
+
+	// vipPortRegexp matches the protocol and the port of a VIP, e.g.:
+	// "   protocol:6 port:80".
+	vipPortRegexp = regexp.MustCompile(`\bprotocol:(\d+)\s+port:(\d+)`)
+
+	// vipTargetPortRegexp and vipNodePortRegexp match the NAT parameters of a VIP.
+	vipTargetPortRegexp = regexp.MustCompile(`\btarget_port:(\d+)`)
+	vipNodePortRegexp   = regexp.MustCompile(`\bnode_port:(\d+)`)
+
+	// asRegexp matches AS of a VIP, e.g.:
+	// "     10.1.1.3 512 buckets   0 flows  dpo:14 used".
+	asRegexp = regexp.MustCompile(`^\s+(\S+)\s+\d+\s+buckets\s+\d+\s+flows\s+dpo:\d+\s+(\S+)`)
+)
+
+// dumpVIPs reads VIPs of the IP version handled by the configurator, configured
+// in the NAT mode, together with their ASs from the LB plugin.
+// VIPs and ASs already removed, which the LB plugin keeps only until their
+// flows time out, are not returned.
+func (c *Configurator) dumpVIPs() (map[vipKey]*vip, error) {
+	output, err := c.executeCLI(showVIPsCmd)
+	if err != nil {
+		return nil, err
+	}
+	vipTypeName := vipTypeNameNAT4
+	if c.IPv6 {
+		vipTypeName = vipTypeNameNAT6
+	}
+	return parseVIPs(output, vipTypeName), nil
+}
+
+// parseVIPs parses VIPs of the given type from the output of showVIPsCmd.
+func parseVIPs(output string, vipTypeName string) map[vipKey]*vip {
+	vips := make(map[vipKey]*vip)
+	var current *vip
+	addCurrent := func() {
+		if current != nil && current.port != 0 {
+			vips[current.key()] = current
+		}
+		current = nil
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if match := vipHeaderRegexp.FindStringSubmatch(line); match != nil {
+			addCurrent()
+			ip := net.ParseIP(match[2])
+			if match[1] != vipTypeName || match[4] != "" || ip == nil {
+				// another type, removed VIP or unexpected format
+				continue
+			}
+			current = &vip{ip: normalizeIP(ip), backends: make(map[string]net.IP)}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if match := vipPortRegexp.FindStringSubmatch(line); match != nil {
+			protocol, _ := strconv.Atoi(match[1])
+			current.protocol = renderer.ProtocolType(protocol)
+			current.port = parsePort(match[2])
+		}
+		if match := vipTargetPortRegexp.FindStringSubmatch(line); match != nil {
+			current.targetPort = parsePort(match[1])
+		}
+		if match := vipNodePortRegexp.FindStringSubmatch(line); match != nil {
+			current.nodePort = parsePort(match[1])
+		}
+		if match := asRegexp.FindStringSubmatch(line); match != nil {
+			as := net.ParseIP(match[1])
+			if as != nil && match[2] == "used" {
+				current.backends[as.String()] = normalizeIP(as)
+			}
+		}
+	}
+	addCurrent()
+	return vips
+}
+
+// parsePort parses port number, returns zero if the number is not valid.
+func parsePort(port string) uint16 {
+	number, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return 0
+	}
+	return uint16(number)
+}
+
+// executeCLI executes the given VPP CLI command and returns its output.
+func (c *Configurator) executeCLI(cmd string) (string, error) {
+	req := &vpe.CliInband{
+		Cmd: []byte(cmd),
+	}
+	reply := &vpe.CliInbandReply{}
+	if err := c.GoVPPChan.SendRequest(req).ReceiveReply(reply); err != nil {
+		return "", err
+	}
+	if reply.Retval != 0 {
+		return "", fmt.Errorf("VPP CLI '%s' failed with retval %d", cmd, reply.Retval)
+	}
+	// CLI errors are reported only in the textual reply
+	output := string(reply.Reply)
+	if strings.Contains(output, "unknown input") {
+		return "", fmt.Errorf("VPP CLI '%s' failed: %s", cmd, strings.TrimSpace(output))
+	}
+	return output, nil
+}
@@ -0,0 +1,562 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+// Package lbvips maintains the configuration of the VPP load-balancer (LB) plugin
+// for service renderers built on top of the plugin - the Maglev renderer (IPv4)
+// and the IPv6 renderer.
+package lbvips
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	govpp "git.fd.io/govpp.git/api"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/vpp-agent/plugins/vpp"
+
+	"github.com/contiv/vpp/plugins/service/renderer"
+	lb_api "github.com/contiv/vpp/plugins/service/renderer/binapi/lb"
+)
+
+const (
+	// DefaultFlowTableLength is the default size of the new-flows table
+	// (Maglev lookup table) of every VIP. Must be a power of 2.
+	DefaultFlowTableLength = 1024
+
+	// encapNAT4/encapNAT6 select the NAT mode of the LB plugin for IPv4/IPv6 VIPs
+	// (destination address and port are translated to the AS and target port).
+	encapNAT4 = 3
+	encapNAT6 = 4
+
+	// VIP types applicable to the NAT mode.
+	vipTypeClusterIP = 0
+	vipTypeNodePort  = 1
+
+	// VPP API errors tolerated by the configurator, the configuration is already
+	// in the requested state (e.g. after restart of the agent).
+	vnetAPIErrorNoSuchEntry = -6
+	vnetAPIErrorValueExist  = -103
+)
+
+// lbRequests lists binary API requests of the LB plugin sent by the configurator.
+var lbRequests = []govpp.Message{
+	&lb_api.LbAddDelVip{},
+	&lb_api.LbAddDelAs{},
+	&lb_api.LbAddDelIntfNat4{},
+	&lb_api.LbAddDelIntfNat6{},
+}
+
+// Configurator maps services into VIPs of the LB plugin.
+//
+// Every combination of service IP, protocol and port is configured as one VIP
+// in the NAT mode, translating the destination to the selected backend and
+// its target port. The backends are added as the VIP's application servers (ASs).
+// Node ports are attached to the VIP of the cluster IP (the first IP
+// of the service) - the LB plugin then accepts the node port on every local IP
+// address of the same IP version. Backend interfaces are enabled with the NAT
+// in2out feature of the LB plugin, translating the responses back to the VIP
+// (only while at least one VIP is configured).
+// Draining backends are not added as ASs - the LB plugin keeps forwarding
+// the established flows of a removed AS until they time out in the sticky table.
+//
+// A single instance of Configurator handles either IPv4 (NAT4 mode), or IPv6
+// (NAT6 mode) addresses, addresses of the other IP version are ignored.
+//
+// The binary API of the LB plugin does not allow to dump the configuration,
+// therefore Resync() reads VIPs and their ASs from the output of the VPP CLI
+// and removes those not expected anymore (e.g. left behind by a previous run
+// of the agent). If the CLI output cannot be obtained, VPP is reconciled with
+// the configuration cached by the configurator instead (VIPs and ASs that
+// already exist in VPP are tolerated).
+type Configurator struct {
+	Deps
+
+	vips       map[vipKey]*vip     /* configured VIPs */
+	backendIfs renderer.Interfaces /* interfaces connecting backends */
+	natIfs     map[string]uint32   /* interfaces with the NAT in2out feature (name -> sw_if_index) */
+}
+
+// Deps lists dependencies of the Configurator.
+type Deps struct {
+	Log             logging.Logger
+	VPP             vpp.API       /* interface indexes */
+	GoVPPChan       govpp.Channel /* used for LB binary API calls */
+	IPv6            bool          /* configure IPv6 VIPs in the NAT6 mode instead of IPv4 VIPs in the NAT4 mode */
+	FlowTableLength uint32        /* size of the new-flows table of VIPs, DefaultFlowTableLength if zero */
+}
+
+// vipKey uniquely identifies VIP in the LB plugin.
+type vipKey struct {
+	ip       string
+	protocol renderer.ProtocolType
+	port     uint16
+}
+
+// vip represents VIP configuration.
+type vip struct {
+	ip         net.IP
+	protocol   renderer.ProtocolType
+	port       uint16
+	targetPort uint16
+	nodePort   uint16
+	backends   map[string]net.IP /* AS addresses */
+}
+
+// String converts vipKey into a human-readable string.
+func (vk vipKey) String() string {
+	return fmt.Sprintf("%s:%d/%s", vk.ip, vk.port, vk.protocol.String())
+}
+
+// key returns the key identifying the VIP.
+func (v *vip) key() vipKey {
+	return vipKey{ip: v.ip.String(), protocol: v.protocol, port: v.port}
+}
+
+// Init initializes the configurator.
+// Returns an error if the binary API of the LB plugin is not compatible with VPP.
+func (c *Configurator) Init() error {
+	if err := CheckMessageCompatibility(c.GoVPPChan, lbRequests...); err != nil {
+		return err
+	}
+	c.vips = make(map[vipKey]*vip)
+	c.backendIfs = renderer.NewInterfaces()
+	c.natIfs = make(map[string]uint32)
+	if c.FlowTableLength == 0 {
+		c.FlowTableLength = DefaultFlowTableLength
+	}
+	return nil
+}
+
+// CheckMessageCompatibility verifies that the connected VPP knows all the given
+// binary API request messages (names with CRCs).
+// The message IDs are resolved through notification subscriptions (released
+// immediately), nothing is sent to VPP. Replies cannot be checked this way
+// - GoVPP would keep delivering them as notifications even after the subscription
+// is released.
+func CheckMessageCompatibility(ch govpp.Channel, msgs ...govpp.Message) error {
+	for _, msg := range msgs {
+		if msg.GetMessageType() != govpp.RequestMessage {
+			return fmt.Errorf("VPP binary API message %s is not a request", msg.GetMessageName())
+		}
+		sub, err := ch.SubscribeNotification(make(chan govpp.Message), msg)
+		if err != nil {
+			return fmt.Errorf("VPP binary API message %s (CRC %s) is not compatible with VPP: %v",
+				msg.GetMessageName(), msg.GetCrcString(), err)
+		}
+		if err := sub.Unsubscribe(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddService configures VIPs of a newly added service.
+func (c *Configurator) AddService(service *renderer.ContivService) error {
+	return c.updateVIPs(nil, c.serviceVIPs(service))
+}
+
+// UpdateService updates VIPs of a changed service.
+func (c *Configurator) UpdateService(oldService, newService *renderer.ContivService) error {
+	return c.updateVIPs(c.serviceVIPs(oldService), c.serviceVIPs(newService))
+}
+
+// DeleteService removes VIPs of an un-deployed service.
+func (c *Configurator) DeleteService(service *renderer.ContivService) error {
+	return c.updateVIPs(c.serviceVIPs(service), nil)
+}
+
+// UpdateBackendIfs enables the NAT in2out feature of the LB plugin
+// for the given interfaces connecting service backends with VPP.
+func (c *Configurator) UpdateBackendIfs(backendIfs renderer.Interfaces) error {
+	c.backendIfs = backendIfs.Copy()
+	return c.updateNATInterfaces()
+}
+
+// Resync reconciles the configuration of the LB plugin with the given
+// full set of services and backend interfaces.
+func (c *Configurator) Resync(services []*renderer.ContivService, backendIfs renderer.Interfaces) error {
+	newVIPs := make(map[vipKey]*vip)
+	for _, service := range services {
+		for key, vip := range c.serviceVIPs(service) {
+			newVIPs[key] = vip
+		}
+	}
+	dumpedVIPs, err := c.dumpVIPs()
+	if err != nil {
+		c.Log.Warnf("Failed to dump VIPs, resync is based on the cached configuration: %v", err)
+	} else {
+		c.vips = dumpedVIPs
+	}
+	oldVIPs := make(map[vipKey]*vip)
+	for key, vip := range c.vips {
+		oldVIPs[key] = vip
+	}
+	c.backendIfs = backendIfs.Copy()
+	return c.updateVIPs(oldVIPs, newVIPs)
+}
+
+// isSupportedIP returns true if the given IP address is of the IP version
+// handled by the configurator.
+func (c *Configurator) isSupportedIP(ip net.IP) bool {
+	return renderer.IsIPv6(ip) == c.IPv6
+}
+
+// serviceVIPs returns the set of VIPs corresponding to a given service.
+func (c *Configurator) serviceVIPs(service *renderer.ContivService) map[vipKey]*vip {
+	vips := make(map[vipKey]*vip)
+	if service.SessionAffinity == renderer.ClientIPAffinity {
+		c.Log.WithField("service", service.ID).Warn("ClientIP session affinity is not supported by the LB plugin")
+	}
+
+	for portName, port := range service.Ports {
+		if port.Protocol != renderer.TCP && port.Protocol != renderer.UDP {
+			c.Log.WithFields(logging.Fields{
+				"service": service.ID,
+				"port":    port,
+			}).Warn("Protocol not supported by the LB plugin")
+			continue
+		}
+		var backends []*renderer.ServiceBackend
+		for _, backend := range service.Backends[portName] {
+			if !backend.Draining {
+				backends = append(backends, backend)
+			}
+		}
+		if len(backends) == 0 {
+			continue
+		}
+		// all backends of a VIP have to listen on the same port
+		targetPort := backends[0].Port
+
+		isClusterIP := true
+		for _, ip := range service.ExternalIPs.List() {
+			if !c.isSupportedIP(ip) {
+				c.Log.WithFields(logging.Fields{
+					"service": service.ID,
+					"ip":      ip,
+				}).Debug("Skipping address of another IP version")
+				continue
+			}
+			v := &vip{
+				ip:         normalizeIP(ip),
+				protocol:   port.Protocol,
+				port:       port.Port,
+				targetPort: targetPort,
+				backends:   make(map[string]net.IP),
+			}
+			nodeLocal := service.TrafficPolicy == renderer.NodeLocal && !isClusterIP
+			if isClusterIP {
+				v.nodePort = port.NodePort
+				isClusterIP = false
+			}
+			for _, backend := range backends {
+				if nodeLocal && !backend.Local {
+					continue
+				}
+				if backend.Port != targetPort {
+					c.Log.WithFields(logging.Fields{
+						"service": service.ID,
+						"backend": backend,
+					}).Warn("Backend port differs from the target port of the VIP, skipping")
+					continue
+				}
+				if !c.isSupportedIP(backend.IP) {
+					continue
+				}
+				v.backends[backend.IP.String()] = normalizeIP(backend.IP)
+			}
+			vips[v.key()] = v
+		}
+	}
+	return vips
+}
+
+// updateVIPs removes VIPs present only in <oldVIPs> and configures <newVIPs>.
+// The configured state is compared with the state cached in the configurator,
+// which is updated with every successful binary API call.
+func (c *Configurator) updateVIPs(oldVIPs, newVIPs map[vipKey]*vip) error {
+	// remove obsolete VIPs
+	for _, key := range sortedKeys(oldVIPs) {
+		if _, keep := newVIPs[key]; keep {
+			continue
+		}
+		if err := c.delVIP(key); err != nil {
+			return err
+		}
+	}
+
+	// add new and update existing VIPs
+	for _, key := range sortedKeys(newVIPs) {
+		newVIP := newVIPs[key]
+		if oldVIP, exists := c.vips[key]; exists {
+			if oldVIP.targetPort != newVIP.targetPort || oldVIP.nodePort != newVIP.nodePort {
+				// VIP parameters cannot be modified, re-create the VIP
+				if err := c.delVIP(key); err != nil {
+					return err
+				}
+			}
+		}
+		if err := c.addVIP(newVIP); err != nil {
+			return err
+		}
+	}
+	return c.updateNATInterfaces()
+}
+
+// addVIP configures VIP (if not yet configured) and synchronizes the set of its ASs.
+func (c *Configurator) addVIP(newVIP *vip) error {
+	key := newVIP.key()
+	current, exists := c.vips[key]
+	if !exists {
+		vipType := uint8(vipTypeClusterIP)
+		if newVIP.nodePort != 0 {
+			vipType = vipTypeNodePort
+		}
+		encap := uint8(encapNAT4)
+		if c.IPv6 {
+			encap = encapNAT6
+		}
+		req := &lb_api.LbAddDelVip{
+			IPPrefix:            ipToLBAddress(newVIP.ip),
+			PrefixLength:        128,
+			Protocol:            uint8(newVIP.protocol),
+			Port:                newVIP.port,
+			Encap:               encap,
+			Type:                vipType,
+			TargetPort:          newVIP.targetPort,
+			NodePort:            newVIP.nodePort,
+			NewFlowsTableLength: c.FlowTableLength,
+		}
+		reply := &lb_api.LbAddDelVipReply{}
+		err := c.GoVPPChan.SendRequest(req).ReceiveReply(reply)
+		err = ignoreRetval(err, vnetAPIErrorValueExist)
+		if err != nil {
+			c.Log.WithField("vip", key).Errorf("Failed to add VIP: %v", err)
+			return err
+		}
+		current = &vip{
+			ip:         newVIP.ip,
+			protocol:   newVIP.protocol,
+			port:       newVIP.port,
+			targetPort: newVIP.targetPort,
+			nodePort:   newVIP.nodePort,
+			backends:   make(map[string]net.IP),
+		}
+		c.vips[key] = current
+	}
+
+	// remove obsolete ASs
+	for _, asKey := range sortedIPs(current.backends) {
+		if _, keep := newVIP.backends[asKey]; keep {
+			continue
+		}
+		if err := c.addDelAS(current, current.backends[asKey], true); err != nil {
+			return err
+		}
+	}
+	// add new ASs
+	for _, asKey := range sortedIPs(newVIP.backends) {
+		if _, exists := current.backends[asKey]; exists {
+			continue
+		}
+		if err := c.addDelAS(current, newVIP.backends[asKey], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// delVIP removes all ASs of the VIP and then the VIP itself.
+func (c *Configurator) delVIP(key vipKey) error {
+	current, exists := c.vips[key]
+	if !exists {
+		return nil
+	}
+	for _, asKey := range sortedIPs(current.backends) {
+		if err := c.addDelAS(current, current.backends[asKey], true); err != nil {
+			return err
+		}
+	}
+	req := &lb_api.LbAddDelVip{
+		IPPrefix:     ipToLBAddress(current.ip),
+		PrefixLength: 128,
+		Protocol:     uint8(current.protocol),
+		Port:         current.port,
+		IsDel:        1,
+	}
+	reply := &lb_api.LbAddDelVipReply{}
+	err := c.GoVPPChan.SendRequest(req).ReceiveReply(reply)
+	err = ignoreRetval(err, vnetAPIErrorNoSuchEntry)
+	if err != nil {
+		c.Log.WithField("vip", key).Errorf("Failed to delete VIP: %v", err)
+		return err
+	}
+	delete(c.vips, key)
+	return nil
+}
+
+// addDelAS adds or removes AS of the given VIP.
+func (c *Configurator) addDelAS(v *vip, as net.IP, isDel bool) error {
+	req := &lb_api.LbAddDelAs{
+		VipIPPrefix:     ipToLBAddress(v.ip),
+		VipPrefixLength: 128,
+		Protocol:        uint8(v.protocol),
+		Port:            v.port,
+		AsAddress:       ipToLBAddress(as),
+	}
+	tolerated := int32(vnetAPIErrorValueExist)
+	if isDel {
+		req.IsDel = 1
+		tolerated = vnetAPIErrorNoSuchEntry
+	}
+	reply := &lb_api.LbAddDelAsReply{}
+	err := c.GoVPPChan.SendRequest(req).ReceiveReply(reply)
+	err = ignoreRetval(err, tolerated)
+	if err != nil {
+		c.Log.WithFields(logging.Fields{
+			"vip":   v.key(),
+			"as":    as,
+			"isDel": isDel,
+		}).Errorf("Failed to update AS: %v", err)
+		return err
+	}
+	if isDel {
+		delete(v.backends, as.String())
+	} else {
+		v.backends[as.String()] = as
+	}
+	return nil
+}
+
+// updateNATInterfaces enables the NAT in2out feature for all backend interfaces
+// (while at least one VIP is configured) and disables it for the other interfaces.
+// Interfaces not yet known to VPP are skipped and retried with the next update.
+func (c *Configurator) updateNATInterfaces() error {
+	enabled := renderer.NewInterfaces()
+	if len(c.vips) > 0 {
+		enabled = c.backendIfs
+	}
+
+	// disable feature for non-backends
+	for ifName, swIfIndex := range c.natIfs {
+		if enabled.Has(ifName) {
+			continue
+		}
+		if err := c.addDelNATInterface(ifName, swIfIndex, false); err != nil {
+			return err
+		}
+		delete(c.natIfs, ifName)
+	}
+
+	// enable feature for backends
+	for ifName := range enabled {
+		if _, isEnabled := c.natIfs[ifName]; isEnabled {
+			continue
+		}
+		swIfIndex, _, exists := c.VPP.GetSwIfIndexes().LookupIdx(ifName)
+		if !exists {
+			c.Log.WithField("interface", ifName).Debug("Interface not yet configured in VPP")
+			continue
+		}
+		if err := c.addDelNATInterface(ifName, swIfIndex, true); err != nil {
+			return err
+		}
+		c.natIfs[ifName] = swIfIndex
+	}
+	return nil
+}
+
+// addDelNATInterface enables or disables the NAT4/NAT6 in2out feature
+// for the given interface.
+func (c *Configurator) addDelNATInterface(ifName string, swIfIndex uint32, isAdd bool) error {
+	var (
+		isAddFlag uint8
+		err       error
+	)
+	tolerated := int32(vnetAPIErrorNoSuchEntry)
+	if isAdd {
+		isAddFlag = 1
+		tolerated = vnetAPIErrorValueExist
+	}
+	if c.IPv6 {
+		req := &lb_api.LbAddDelIntfNat6{IsAdd: isAddFlag, SwIfIndex: swIfIndex}
+		err = c.GoVPPChan.SendRequest(req).ReceiveReply(&lb_api.LbAddDelIntfNat6Reply{})
+	} else {
+		req := &lb_api.LbAddDelIntfNat4{IsAdd: isAddFlag, SwIfIndex: swIfIndex}
+		err = c.GoVPPChan.SendRequest(req).ReceiveReply(&lb_api.LbAddDelIntfNat4Reply{})
+	}
+	err = ignoreRetval(err, tolerated)
+	if err != nil {
+		c.Log.WithFields(logging.Fields{
+			"interface": ifName,
+			"isAdd":     isAdd,
+			"ipv6":      c.IPv6,
+		}).Errorf("Failed to update NAT interface feature: %v", err)
+	}
+	return err
+}
+
+// ignoreRetval returns nil if the error is the given (tolerated) VPP API error.
+// govpp reports non-zero retval of a reply as VPPApiError.
+func ignoreRetval(err error, tolerated int32) error {
+	if apiErr, isAPIErr := err.(govpp.VPPApiError); isAPIErr && int32(apiErr) == tolerated {
+		return nil
+	}
+	return err
+}
+
+// normalizeIP returns IPv4 address in the 4-byte representation, IPv6 unchanged.
+func normalizeIP(ip net.IP) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip
+}
+
+// ipToLBAddress converts IP address into the format of the LB plugin binary API
+// (IPv4 in lower order 32 bits).
+func ipToLBAddress(ip net.IP) []byte {
+	addr := make([]byte, net.IPv6len)
+	if ip4 := ip.To4(); ip4 != nil {
+		copy(addr[net.IPv6len-net.IPv4len:], ip4)
+	} else {
+		copy(addr, ip.To16())
+	}
+	return addr
+}
+
+// sortedKeys returns keys of the given VIP map in a deterministic order.
+func sortedKeys(vips map[vipKey]*vip) []vipKey {
+	keys := make([]vipKey, 0, len(vips))
+	for key := range vips {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+// sortedIPs returns keys of the given map of IP addresses in a deterministic order.
+func sortedIPs(ips map[string]net.IP) []string {
+	keys := make([]string, 0, len(ips))
+	for key := range ips {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
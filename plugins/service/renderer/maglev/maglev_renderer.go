@@ -0,0 +1,163 @@
+/*
+ * // Copyright (c) 2018 Cisco and/or its affiliates.
+ * //
+ * // Licensed under the Apache License, Version 2.0 (the "License");
+ * // you may not use this file except in compliance with the License.
+ * // You may obtain a copy of the License at:
+ * //
+ * //     http://www.apache.org/licenses/LICENSE-2.0
+ * //
+ * // Unless required by applicable law or agreed to in writing, software
+ * // distributed under the License is distributed on an "AS IS" BASIS,
+ * // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * // See the License for the specific language governing permissions and
+ * // limitations under the License.
+ */
+
+package maglev
+
+import (
+	govpp "git.fd.io/govpp.git/api"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/vpp-agent/plugins/vpp"
+
+	"github.com/contiv/vpp/plugins/service/renderer"
+	"github.com/contiv/vpp/plugins/service/renderer/lbvips"
+)
+
+// Renderer implements rendering of services for IPv4 in VPP using the load-balancer
+// (LB) plugin.
+//
+// Unlike the NAT44 renderer, which uses static mappings with probabilities
+// and stateful NAT sessions, the LB plugin selects the backend (application server,
+// AS) for every new flow using the Maglev consistent hashing. The addition
+// or the removal of a backend therefore re-assigns only the minimum number
+// of flows and there are no per-connection NAT sessions that would need to be
+// periodically cleaned up - established flows time out on their own in the LB
+// plugin's sticky table.
+//
+// Services are mapped to VIPs of the LB plugin in the NAT4 mode by the lbvips
+// Configurator. Node ports are attached to the VIP of the cluster IP - the LB
+// plugin then accepts the node port on every local IP address, therefore
+// UpdateNodePortServices() requires no action.
+//
+// Limitations (as of the LB plugin API):
+//   - all backends of a VIP receive an equal share of the Maglev lookup table
+//     (the API has no AS weights and rejects duplicate ASs), the service
+//     processor therefore assigns services mixing local and remote backends
+//     to the NAT44 renderer while the local endpoints are weighted
+//     (ServiceLocalEndpointWeight != 1),
+//   - VIPs are installed into the default VRF (the main VRF of Contiv has to be 0),
+//   - the ClientIP session affinity is not supported,
+//   - node-local traffic policy is applied to the external IPs only (node port
+//     shares the VIP with the cluster IP, load-balancing across all backends),
+//   - only IPv4 is supported (IPv6 is handled by the IPv6 renderer),
+//   - LoadBalancerSourceRanges are not enforced,
+//   - the LB plugin API does not allow to dump the configuration, therefore
+//     Resync() reads the VIPs from the output of the VPP CLI.
+//
+// Services are assigned to the renderer either with the annotation
+// `contiv.vpp/service-renderer: maglev`, or globally with the Contiv
+// configuration option `ServiceRenderer: maglev`.
+type Renderer struct {
+	Deps
+
+	vips *lbvips.Configurator
+}
+
+// Deps lists dependencies of the Renderer.
+type Deps struct {
+	Log             logging.Logger
+	VPP             vpp.API       /* interface indexes */
+	GoVPPChan       govpp.Channel /* used for LB binary API calls */
+	FlowTableLength uint32        /* size of the new-flows table of VIPs, lbvips.DefaultFlowTableLength if zero */
+}
+
+// Init initializes the renderer.
+// Returns an error if VPP does not support the binary API of the LB plugin.
+func (rndr *Renderer) Init() error {
+	rndr.vips = &lbvips.Configurator{
+		Deps: lbvips.Deps{
+			Log:             rndr.Log,
+			VPP:             rndr.VPP,
+			GoVPPChan:       rndr.GoVPPChan,
+			FlowTableLength: rndr.FlowTableLength,
+		},
+	}
+	return rndr.vips.Init()
+}
+
+// FilterService selects the IPv4 part of services assigned to the Maglev renderer.
+func (rndr *Renderer) FilterService(service *renderer.ContivService) *renderer.ContivService {
+	if service.Renderer != renderer.MaglevRenderer {
+		return nil
+	}
+	return service.FilterIPVersion(false)
+}
+
+// AddService configures VIPs of a newly added service.
+func (rndr *Renderer) AddService(service *renderer.ContivService) error {
+	rndr.Log.WithFields(logging.Fields{
+		"service": service,
+	}).Debug("MaglevRenderer - AddService()")
+
+	return rndr.vips.AddService(service)
+}
+
+// UpdateService updates VIPs of a changed service.
+func (rndr *Renderer) UpdateService(oldService, newService *renderer.ContivService) error {
+	rndr.Log.WithFields(logging.Fields{
+		"oldService": oldService,
+		"newService": newService,
+	}).Debug("MaglevRenderer - UpdateService()")
+
+	return rndr.vips.UpdateService(oldService, newService)
+}
+
+// DeleteService removes VIPs of an un-deployed service.
+func (rndr *Renderer) DeleteService(service *renderer.ContivService) error {
+	rndr.Log.WithFields(logging.Fields{
+		"service": service,
+	}).Debug("MaglevRenderer - DeleteService()")
+
+	return rndr.vips.DeleteService(service)
+}
+
+// UpdateNodePortServices does nothing - the LB plugin accepts node ports
+// on all local IP addresses.
+func (rndr *Renderer) UpdateNodePortServices(nodeIPs *renderer.IPAddresses,
+	npServices []*renderer.ContivService) error {
+	return nil
+}
+
+// UpdateLocalFrontendIfs does nothing - VIPs are matched in the FIB regardless
+// of the ingress interface.
+func (rndr *Renderer) UpdateLocalFrontendIfs(oldIfNames, newIfNames renderer.Interfaces) error {
+	return nil
+}
+
+// UpdateLocalBackendIfs enables the NAT4 in2out feature of the LB plugin
+// for interfaces connecting service backends with VPP.
+func (rndr *Renderer) UpdateLocalBackendIfs(oldIfNames, newIfNames renderer.Interfaces) error {
+	rndr.Log.WithFields(logging.Fields{
+		"oldIfNames": oldIfNames,
+		"newIfNames": newIfNames,
+	}).Debug("MaglevRenderer - UpdateLocalBackendIfs()")
+
+	return rndr.vips.UpdateBackendIfs(newIfNames)
+}
+
+// Resync reconciles the configuration of the LB plugin with the provided
+// full state of K8s services.
+func (rndr *Renderer) Resync(resyncEv *renderer.ResyncEventData) error {
+	rndr.Log.WithFields(logging.Fields{
+		"resyncEv": resyncEv,
+	}).Debug("MaglevRenderer - Resync()")
+
+	return rndr.vips.Resync(resyncEv.Services, resyncEv.BackendIfs)
+}
+
+// Close deallocates resources held by the renderer.
+func (rndr *Renderer) Close() error {
+	return nil
+}
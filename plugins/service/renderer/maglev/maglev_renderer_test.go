@@ -0,0 +1,258 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maglev
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+
+	. "github.com/contiv/vpp/mock/lbplugin"
+	. "github.com/contiv/vpp/mock/pluginvpp"
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
+	"github.com/contiv/vpp/plugins/service/renderer"
+)
+
+const (
+	tcp = uint8(renderer.TCP)
+	udp = uint8(renderer.UDP)
+
+	pod1If = "master-tap1"
+	pod2If = "master-tap2"
+
+	pod1IfIndex = 11
+	pod2IfIndex = 12
+
+	// LB plugin API values
+	encapNAT4        = 3
+	vipTypeClusterIP = 0
+	vipTypeNodePort  = 1
+)
+
+var mockLB *MockLBPlugin
+
+func TestMain(m *testing.M) {
+	logger := logrus.DefaultLogger()
+	logger.SetLevel(logging.DebugLevel)
+	mockLB = NewMockLBPlugin(logger)
+	os.Exit(m.Run())
+}
+
+// newRenderer creates a new instance of the renderer connected with the LB plugin mock.
+func newRenderer() *Renderer {
+	vppPlugins := NewMockVppPlugin()
+	vppPlugins.AddInterface(pod1If, pod1IfIndex, "10.1.1.3")
+	vppPlugins.AddInterface(pod2If, pod2IfIndex, "10.1.1.4")
+
+	rndr := &Renderer{
+		Deps: Deps{
+			Log:       logrus.DefaultLogger(),
+			VPP:       vppPlugins,
+			GoVPPChan: mockLB.NewVPPChan(),
+		},
+	}
+	Expect(rndr.Init()).To(BeNil())
+	return rndr
+}
+
+// newService returns service exposed on a cluster IP and an external IP,
+// with TCP port (also exposed as node port) and UDP port, and with two
+// local and one remote backend.
+func newService() *renderer.ContivService {
+	service := renderer.NewContivService()
+	service.ID = svcmodel.ID{Name: "service1", Namespace: "default"}
+	service.Renderer = renderer.MaglevRenderer
+	service.ExternalIPs.Add(net.ParseIP("10.96.0.1"))
+	service.ExternalIPs.Add(net.ParseIP("20.20.20.20"))
+	service.ExternalIPs.Add(net.ParseIP("2001:db8::1"))
+	service.Ports["http"] = &renderer.ServicePort{Protocol: renderer.TCP, Port: 80, NodePort: 30080}
+	service.Ports["dns"] = &renderer.ServicePort{Protocol: renderer.UDP, Port: 53}
+	for _, port := range []string{"http", "dns"} {
+		targetPort := uint16(8080)
+		if port == "dns" {
+			targetPort = 5353
+		}
+		service.Backends[port] = []*renderer.ServiceBackend{
+			{IP: net.ParseIP("10.1.1.3"), Port: targetPort, Local: true},
+			{IP: net.ParseIP("10.1.1.4"), Port: targetPort, Local: true},
+			{IP: net.ParseIP("10.2.1.1"), Port: targetPort, Local: false},
+		}
+	}
+	return service
+}
+
+func TestFilterService(t *testing.T) {
+	RegisterTestingT(t)
+
+	rndr := newRenderer()
+	service := newService()
+	filtered := rndr.FilterService(service)
+	Expect(filtered).ToNot(BeNil())
+	Expect(filtered.ExternalIPs.List()).To(HaveLen(2))
+	Expect(filtered.ExternalIPs.Has(net.ParseIP("2001:db8::1"))).To(BeFalse())
+	Expect(service.ExternalIPs.List()).To(HaveLen(3))
+	service.Renderer = renderer.NAT44Renderer
+	Expect(rndr.FilterService(service)).To(BeNil())
+	service.Renderer = ""
+	Expect(rndr.FilterService(service)).To(BeNil())
+}
+
+func TestServiceLifecycle(t *testing.T) {
+	RegisterTestingT(t)
+	mockLB.Clear()
+	rndr := newRenderer()
+
+	// backend interfaces are not enabled with NAT4 until there is a VIP
+	backendIfs := renderer.NewInterfaces(pod1If, pod2If, "not-yet-created")
+	Expect(rndr.UpdateLocalBackendIfs(renderer.NewInterfaces(), backendIfs)).To(BeNil())
+	Expect(mockLB.NumOfNAT4Interfaces()).To(Equal(0))
+
+	// add service
+	service := newService()
+	Expect(rndr.AddService(service)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(4))
+	allBackends := []string{"10.1.1.3", "10.1.1.4", "10.2.1.1"}
+
+	vip := mockLB.GetVIP("10.96.0.1", tcp, 80)
+	Expect(vip).ToNot(BeNil())
+	Expect(vip.Encap).To(BeEquivalentTo(encapNAT4))
+	Expect(vip.Type).To(BeEquivalentTo(vipTypeNodePort))
+	Expect(vip.NodePort).To(BeEquivalentTo(30080))
+	Expect(vip.TargetPort).To(BeEquivalentTo(8080))
+	Expect(vip.ASs).To(Equal(allBackends))
+
+	vip = mockLB.GetVIP("20.20.20.20", tcp, 80)
+	Expect(vip).ToNot(BeNil())
+	Expect(vip.Type).To(BeEquivalentTo(vipTypeClusterIP))
+	Expect(vip.NodePort).To(BeEquivalentTo(0))
+	Expect(vip.ASs).To(Equal(allBackends))
+
+	vip = mockLB.GetVIP("10.96.0.1", udp, 53)
+	Expect(vip).ToNot(BeNil())
+	Expect(vip.Type).To(BeEquivalentTo(vipTypeClusterIP))
+	Expect(vip.TargetPort).To(BeEquivalentTo(5353))
+	Expect(vip.ASs).To(Equal(allBackends))
+	Expect(mockLB.GetVIP("20.20.20.20", udp, 53)).ToNot(BeNil())
+
+	Expect(mockLB.NumOfNAT4Interfaces()).To(Equal(2))
+	Expect(mockLB.HasNAT4Interface(pod1IfIndex)).To(BeTrue())
+	Expect(mockLB.HasNAT4Interface(pod2IfIndex)).To(BeTrue())
+
+	// remove one backend, switch to node-local traffic policy
+	service2 := newService()
+	service2.TrafficPolicy = renderer.NodeLocal
+	for port, backends := range service2.Backends {
+		service2.Backends[port] = backends[1:]
+	}
+	Expect(rndr.UpdateService(service, service2)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(4))
+	Expect(mockLB.GetVIP("10.96.0.1", tcp, 80).ASs).To(Equal([]string{"10.1.1.4", "10.2.1.1"}))
+	Expect(mockLB.GetVIP("20.20.20.20", tcp, 80).ASs).To(Equal([]string{"10.1.1.4"}))
+	Expect(mockLB.GetVIP("20.20.20.20", udp, 53).ASs).To(Equal([]string{"10.1.1.4"}))
+
+	// change target port, remove external IP and UDP port
+	service3 := newService()
+	service3.TrafficPolicy = renderer.NodeLocal
+	service3.ExternalIPs = renderer.NewIPAddresses(net.ParseIP("10.96.0.1"))
+	delete(service3.Ports, "dns")
+	delete(service3.Backends, "dns")
+	service3.Backends["http"] = service3.Backends["http"][1:]
+	for _, backend := range service3.Backends["http"] {
+		backend.Port = 9090
+	}
+	Expect(rndr.UpdateService(service2, service3)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(1))
+	vip = mockLB.GetVIP("10.96.0.1", tcp, 80)
+	Expect(vip.TargetPort).To(BeEquivalentTo(9090))
+	Expect(vip.ASs).To(Equal([]string{"10.1.1.4", "10.2.1.1"}))
+
+	// backend interface removed
+	Expect(rndr.UpdateLocalBackendIfs(backendIfs, renderer.NewInterfaces(pod2If))).To(BeNil())
+	Expect(mockLB.NumOfNAT4Interfaces()).To(Equal(1))
+	Expect(mockLB.HasNAT4Interface(pod2IfIndex)).To(BeTrue())
+
+	// delete service
+	Expect(rndr.DeleteService(service3)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(0))
+	Expect(mockLB.NumOfNAT4Interfaces()).To(Equal(0))
+	Expect(mockLB.GetErrCount()).To(Equal(0))
+}
+
+func TestResync(t *testing.T) {
+	RegisterTestingT(t)
+	mockLB.Clear()
+	rndr := newRenderer()
+
+	service1 := newService()
+	service2 := renderer.NewContivService()
+	service2.ID = svcmodel.ID{Name: "service2", Namespace: "default"}
+	service2.Renderer = renderer.MaglevRenderer
+	service2.ExternalIPs.Add(net.ParseIP("10.96.0.2"))
+	service2.Ports["http"] = &renderer.ServicePort{Protocol: renderer.TCP, Port: 80}
+	service2.Backends["http"] = []*renderer.ServiceBackend{
+		{IP: net.ParseIP("10.1.1.3"), Port: 80, Local: true},
+	}
+
+	// initial resync
+	resyncEv := renderer.NewResyncEventData()
+	resyncEv.Services = append(resyncEv.Services, service1)
+	resyncEv.BackendIfs.Add(pod1If)
+	Expect(rndr.Resync(resyncEv)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(4))
+	Expect(mockLB.NumOfNAT4Interfaces()).To(Equal(1))
+
+	// resync after restart of the agent - configuration already in VPP
+	rndr = newRenderer()
+	resyncEv.Services = append(resyncEv.Services, service2)
+	resyncEv.BackendIfs.Add(pod2If)
+	Expect(rndr.Resync(resyncEv)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(5))
+	Expect(mockLB.GetVIP("10.96.0.2", tcp, 80).ASs).To(Equal([]string{"10.1.1.3"}))
+	Expect(mockLB.NumOfNAT4Interfaces()).To(Equal(2))
+
+	// resync after restart of the agent - stale VIPs and ASs are removed from VPP
+	rndr = newRenderer()
+	service2.Backends["http"] = []*renderer.ServiceBackend{
+		{IP: net.ParseIP("10.1.1.4"), Port: 80, Local: true},
+	}
+	resyncEv = renderer.NewResyncEventData()
+	resyncEv.Services = append(resyncEv.Services, service2)
+	resyncEv.BackendIfs.Add(pod1If)
+	resyncEv.BackendIfs.Add(pod2If)
+	Expect(rndr.Resync(resyncEv)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(1))
+	Expect(mockLB.GetVIP("10.96.0.2", tcp, 80).ASs).To(Equal([]string{"10.1.1.4"}))
+
+	// resync with service1 removed
+	resyncEv = renderer.NewResyncEventData()
+	resyncEv.Services = append(resyncEv.Services, service2)
+	resyncEv.BackendIfs.Add(pod1If)
+	Expect(rndr.Resync(resyncEv)).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(1))
+	Expect(mockLB.GetVIP("10.96.0.2", tcp, 80)).ToNot(BeNil())
+	Expect(mockLB.NumOfNAT4Interfaces()).To(Equal(1))
+	Expect(mockLB.HasNAT4Interface(pod1IfIndex)).To(BeTrue())
+
+	// resync without services
+	Expect(rndr.Resync(renderer.NewResyncEventData())).To(BeNil())
+	Expect(mockLB.NumOfVIPs()).To(Equal(0))
+	Expect(mockLB.NumOfNAT4Interfaces()).To(Equal(0))
+	Expect(mockLB.GetErrCount()).To(Equal(0))
+}
@@ -17,8 +17,10 @@
 package nat44
 
 import (
+	"hash/fnv"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -32,6 +34,7 @@ import (
 	"github.com/ligato/vpp-agent/plugins/vpp/model/nat"
 
 	"github.com/contiv/vpp/plugins/contiv"
+	svcmodel "github.com/contiv/vpp/plugins/ksr/model/service"
 	"github.com/contiv/vpp/plugins/service/renderer"
 
 	govpp "git.fd.io/govpp.git/api"
@@ -78,9 +81,21 @@ var (
 // the virtual NAT loopback IP address, which is then inserted into the `TwiceNAT`
 // address pool. `self-twice-nat` feature is enabled for every static mapping.
 //
+// VPP 18.07 does not support NAT session affinity. For services with the ClientIP
+// affinity, the renderer therefore pins all clients accessing the service via
+// this node to a single backend (per-node stickiness). The affinity timeout is
+// not applicable to this mechanism - clients stay with the selected backend
+// for as long as it remains available. This limitation is logged once
+// per service.
+//
 // Until VPP supports timing-out of NAT sessions, the renderer also performs
 // periodic cleanup of inactive NAT sessions.
 //
+// Draining backends (terminating or no longer ready) are left out of the static
+// mappings, therefore they receive no new connections, while the NAT sessions
+// established before are preserved. Once the backend is removed from the service
+// (after the drain period), its remaining NAT sessions are purged.
+//
 // An extra feature of the renderer, outside the scope of services, is a management
 // of the dynamic source-NAT for node-outbound traffic, configured to enable
 // Internet access even for pods with private IPv4 addresses.
@@ -98,9 +113,33 @@ type Renderer struct {
 	natGlobalCfg *nat.Nat44Global
 	nodeIPs      *renderer.IPAddresses
 
+	/* services with ClientIP affinity for which the limitation was logged */
+	affinityLogged map[svcmodel.ID]struct{}
+
 	/* dynamic SNAT */
 	defaultIfName string
 	defaultIfIP   net.IP
+
+	/* purge of NAT sessions of removed backends */
+	purgeLock    sync.Mutex
+	purgeQueue   []*natSessionPurge
+	purgeSignal  chan struct{}
+	sessionsLock sync.Mutex /* serializes NAT session dumps & deletes */
+}
+
+// natSessionPurge selects NAT sessions to be purged: sessions established
+// via any of the given frontends with any of the given backends.
+type natSessionPurge struct {
+	service   string
+	frontends map[natEndpoint]struct{} /* outside endpoints */
+	backends  map[natEndpoint]struct{} /* inside endpoints */
+}
+
+// natEndpoint is an IP address, port and protocol of a NAT session endpoint.
+type natEndpoint struct {
+	ip       string
+	port     uint16
+	protocol uint16
 }
 
 // Deps lists dependencies of the Renderer.
@@ -122,16 +161,28 @@ func (rndr *Renderer) Init(snatOnly bool) error {
 	rndr.natGlobalCfg = &nat.Nat44Global{
 		Forwarding: true,
 	}
+	rndr.purgeSignal = make(chan struct{}, 1)
+	rndr.affinityLogged = make(map[svcmodel.ID]struct{})
 	return nil
 }
 
 // AfterInit starts asynchronous NAT session cleanup.
 func (rndr *Renderer) AfterInit() error {
-	// run async NAT session cleanup routine
+	// run async NAT session cleanup routines
 	go rndr.idleNATSessionCleanup()
+	go rndr.removedBackendsNATSessionPurge()
 	return nil
 }
 
+// FilterService selects the IPv4 part of services load-balanced by the NAT44
+// renderer, i.e. services not assigned to another renderer.
+func (rndr *Renderer) FilterService(service *renderer.ContivService) *renderer.ContivService {
+	if service.Renderer != "" && service.Renderer != renderer.NAT44Renderer {
+		return nil
+	}
+	return service.FilterIPVersion(false)
+}
+
 // AddService installs destination-NAT rules for a newly added service.
 func (rndr *Renderer) AddService(service *renderer.ContivService) error {
 	if rndr.snatOnly {
@@ -168,7 +219,11 @@ func (rndr *Renderer) UpdateService(oldService, newService *renderer.ContivServi
 	putDsl := dsl.Put()
 	putDsl.NAT44DNat(newDNAT)
 
-	return dsl.Send().ReceiveReply()
+	if err := dsl.Send().ReceiveReply(); err != nil {
+		return err
+	}
+	rndr.purgeRemovedBackends(oldService, newService)
+	return nil
 }
 
 // DeleteService removes destination-NAT configuration associated with a freshly
@@ -186,7 +241,12 @@ func (rndr *Renderer) DeleteService(service *renderer.ContivService) error {
 	deleteDsl := dsl.Delete()
 	deleteDsl.NAT44DNat(service.ID.String())
 
-	return dsl.Send().ReceiveReply()
+	if err := dsl.Send().ReceiveReply(); err != nil {
+		return err
+	}
+	delete(rndr.affinityLogged, service.ID)
+	rndr.purgeRemovedBackends(service, nil)
+	return nil
 }
 
 // UpdateNodePortServices updates configuration of nodeport services to reflect
@@ -487,13 +547,27 @@ func (rndr *Renderer) contivServiceToDNat(service *renderer.ContivService) *nat.
 // exportDNATMappings exports the corresponding list of D-NAT mappings from a Contiv service.
 func (rndr *Renderer) exportDNATMappings(service *renderer.ContivService) []*nat.Nat44DNat_DNatConfig_StaticMapping {
 	mappings := []*nat.Nat44DNat_DNatConfig_StaticMapping{}
+	if service.SessionAffinity == renderer.ClientIPAffinity {
+		if _, logged := rndr.affinityLogged[service.ID]; !logged {
+			rndr.Log.WithFields(logging.Fields{
+				"service": service.ID,
+				"timeout": service.SessionAffinityTimeout,
+			}).Warn("NAT44 cannot track ClientIP affinity per client, all clients accessing " +
+				"the service via this node are pinned to one backend and the timeout is not applied")
+			rndr.affinityLogged[service.ID] = struct{}{}
+		}
+	} else {
+		delete(rndr.affinityLogged, service.ID)
+	}
 
 	// Export NAT mappings for NodePort services.
 	if service.HasNodePort() {
 		for _, nodeIP := range rndr.nodeIPs.List() {
-			if nodeIP.To4() != nil {
-				nodeIP = nodeIP.To4()
+			if nodeIP.To4() == nil {
+				// node ports on IPv6 node IPs are rendered by the IPv6 renderer
+				continue
 			}
+			nodeIP = nodeIP.To4()
 			// Add one mapping for each port.
 			for portName, port := range service.Ports {
 				if port.NodePort == 0 {
@@ -508,8 +582,20 @@ func (rndr *Renderer) exportDNATMappings(service *renderer.ContivService) []*nat
 					mapping.Protocol = nat.Protocol_TCP
 				case renderer.UDP:
 					mapping.Protocol = nat.Protocol_UDP
+				case renderer.SCTP:
+					mapping.Protocol = nat.Protocol_SCTP
+				default:
+					rndr.Log.WithFields(logging.Fields{
+						"service": service.ID,
+						"port":    port,
+					}).Warn("Skipping service port with protocol not supported by NAT44")
+					continue
 				}
 				for _, backend := range service.Backends[portName] {
+					if backend.Draining {
+						// Draining backends receive no new connections.
+						continue
+					}
 					if service.TrafficPolicy != renderer.ClusterWide && !backend.Local {
 						// Do not NAT+LB remote backends.
 						continue
@@ -533,6 +619,9 @@ func (rndr *Renderer) exportDNATMappings(service *renderer.ContivService) []*nat
 				if len(mapping.LocalIps) == 0 {
 					continue
 				}
+				if service.SessionAffinity == renderer.ClientIPAffinity {
+					mapping.LocalIps = rndr.pinAffinityBackend(service, mapping.LocalIps)
+				}
 				if len(mapping.LocalIps) == 1 {
 					// For single backend we use "0" to represent the probability
 					// (not really configured).
@@ -543,6 +632,13 @@ func (rndr *Renderer) exportDNATMappings(service *renderer.ContivService) []*nat
 		}
 	}
 
+	// Export NAT mapping for the health-check node port.
+	if service.HealthCheckNodePort != 0 {
+		if mapping := rndr.exportHealthCheckMapping(service); mapping != nil {
+			mappings = append(mappings, mapping)
+		}
+	}
+
 	// Export NAT mappings for external IPs.
 	for _, externalIP := range service.ExternalIPs.List() {
 		// Add one mapping for each port.
@@ -559,8 +655,20 @@ func (rndr *Renderer) exportDNATMappings(service *renderer.ContivService) []*nat
 				mapping.Protocol = nat.Protocol_TCP
 			case renderer.UDP:
 				mapping.Protocol = nat.Protocol_UDP
+			case renderer.SCTP:
+				mapping.Protocol = nat.Protocol_SCTP
+			default:
+				rndr.Log.WithFields(logging.Fields{
+					"service": service.ID,
+					"port":    port,
+				}).Warn("Skipping service port with protocol not supported by NAT44")
+				continue
 			}
 			for _, backend := range service.Backends[portName] {
+				if backend.Draining {
+					// Draining backends receive no new connections.
+					continue
+				}
 				if service.TrafficPolicy != renderer.ClusterWide && !backend.Local {
 					// Do not NAT+LB remote backends.
 					continue
@@ -584,6 +692,9 @@ func (rndr *Renderer) exportDNATMappings(service *renderer.ContivService) []*nat
 			if len(mapping.LocalIps) == 0 {
 				continue
 			}
+			if service.SessionAffinity == renderer.ClientIPAffinity {
+				mapping.LocalIps = rndr.pinAffinityBackend(service, mapping.LocalIps)
+			}
 			if len(mapping.LocalIps) == 1 {
 				// For single backend we use "0" to represent the probability
 				// (not really configured).
@@ -596,6 +707,65 @@ func (rndr *Renderer) exportDNATMappings(service *renderer.ContivService) []*nat
 	return mappings
 }
 
+// exportHealthCheckMapping returns the static mapping forwarding the health-check
+// node port of the service on the IP of this node to the host end of the VPP-host
+// interconnect, where the agent answers the health-checks (the same way as
+// the traffic to host-network backends is forwarded).
+// Returns nil if the IPv4 node IP is not known yet.
+func (rndr *Renderer) exportHealthCheckMapping(service *renderer.ContivService) *nat.Nat44DNat_DNatConfig_StaticMapping {
+	nodeIP, _ := rndr.Contiv.GetNodeIP()
+	hostIP := rndr.Contiv.GetHostInterconnectIP()
+	if nodeIP.To4() == nil || hostIP == nil {
+		return nil
+	}
+	return &nat.Nat44DNat_DNatConfig_StaticMapping{
+		TwiceNat:     nat.TwiceNatMode_SELF,
+		ExternalIp:   nodeIP.To4().String(),
+		ExternalPort: uint32(service.HealthCheckNodePort),
+		Protocol:     nat.Protocol_TCP,
+		LocalIps: []*nat.Nat44DNat_DNatConfig_StaticMapping_LocalIP{
+			{
+				LocalIp:   hostIP.String(),
+				LocalPort: uint32(service.HealthCheckNodePort),
+				VrfId:     rndr.Contiv.GetMainVrfID(),
+			},
+		},
+	}
+}
+
+// pinAffinityBackend selects a single backend out of <locals> for a service with
+// the ClientIP session affinity.
+// NAT44 in VPP 18.07 cannot remember the backend selected for a client, therefore
+// all the clients accessing the service via this node are pinned to the same
+// backend instead. The backend is selected using rendezvous hashing of the node IP,
+// service ID and backend IP, so that different nodes spread the load across
+// different backends, all ports of the service on this node lead to the same
+// backend and the selection does not change unless the selected backend is removed.
+func (rndr *Renderer) pinAffinityBackend(service *renderer.ContivService,
+	locals []*nat.Nat44DNat_DNatConfig_StaticMapping_LocalIP) []*nat.Nat44DNat_DNatConfig_StaticMapping_LocalIP {
+	if len(locals) <= 1 {
+		return locals
+	}
+	nodeIP, _ := rndr.Contiv.GetNodeIP()
+	var (
+		selected  *nat.Nat44DNat_DNatConfig_StaticMapping_LocalIP
+		maxWeight uint64
+	)
+	for _, local := range locals {
+		hash := fnv.New64a()
+		hash.Write([]byte(nodeIP.String()))
+		hash.Write([]byte(service.ID.String()))
+		hash.Write([]byte(local.LocalIp))
+		weight := hash.Sum64()
+		if selected == nil || weight > maxWeight ||
+			(weight == maxWeight && local.LocalIp < selected.LocalIp) {
+			selected = local
+			maxWeight = weight
+		}
+	}
+	return []*nat.Nat44DNat_DNatConfig_StaticMapping_LocalIP{selected}
+}
+
 // isNodeLocalIP returns true if the given IP is local to the current node, false otherwise.
 func (rndr *Renderer) isNodeLocalIP(ip net.IP) bool {
 	nodeIP, _ := rndr.Contiv.GetNodeIP()
@@ -679,102 +849,220 @@ func (rndr *Renderer) idleNATSessionCleanup() {
 
 		rndr.Log.Debugf("NAT session cleanup started.")
 
-		natUsers := make([][]byte, 0)
 		delRules := make([]*nat_api.Nat44DelSession, 0)
 		var tcpCount uint64
 		var otherCount uint64
 
-		// dump NAT users
-		req1 := &nat_api.Nat44UserDump{}
-		reqCtx1 := rndr.GoVPPChan.SendMultiRequest(req1)
-		for {
-			msg := &nat_api.Nat44UserDetails{}
-			stop, err := reqCtx1.ReceiveReply(msg)
-			if stop {
-				break // break out of the loop
+		rndr.sessionsLock.Lock()
+		for _, msg := range rndr.dumpNATSessions() {
+			if msg.Protocol == 6 {
+				tcpCount++
+			} else {
+				otherCount++
 			}
-			if err != nil {
-				rndr.Log.Errorf("Error by dumping NAT users: %v", err)
+
+			lastHeard := zeroTime.Add(time.Duration(msg.LastHeard) * time.Second)
+			if lastHeard.Before(time.Now()) {
+				if (msg.Protocol == 6 && time.Since(lastHeard) > tcpTimeout) ||
+					(msg.Protocol != 6 && time.Since(lastHeard) > otherTimeout) {
+					// inactive session
+					delRules = append(delRules, natSessionDelRule(msg))
+				}
 			}
-			natUsers = append(natUsers, msg.IPAddress)
 		}
 
-		// dump NAT sessions per user
-		for _, natUser := range natUsers {
-			req2 := &nat_api.Nat44UserSessionDump{
-				IPAddress: natUser,
+		rndr.Log.Debugf("There are %d TCP / %d other NAT sessions, %d will be deleted", tcpCount, otherCount, len(delRules))
+		atomic.StoreUint64(&tcpNatSessionCount, tcpCount)
+		atomic.StoreUint64(&otherNatSessionCount, otherCount)
+
+		// delete the old sessions
+		rndr.deleteNATSessions(delRules)
+		rndr.sessionsLock.Unlock()
+	}
+}
+
+// purgeRemovedBackends schedules purge of NAT sessions of backends which
+// are part of <oldService>, but not of <newService> (nil if the service was removed).
+// Draining backends are still part of the service - their sessions are
+// preserved until the drain period expires.
+func (rndr *Renderer) purgeRemovedBackends(oldService, newService *renderer.ContivService) {
+	purge := &natSessionPurge{
+		service:   oldService.ID.String(),
+		frontends: make(map[natEndpoint]struct{}),
+		backends:  make(map[natEndpoint]struct{}),
+	}
+	newBackends := make(map[natEndpoint]struct{})
+	if newService != nil {
+		for portName, port := range newService.Ports {
+			for _, backend := range newService.Backends[portName] {
+				newBackends[backendEndpoint(backend, port)] = struct{}{}
+			}
+		}
+	}
+	for portName, port := range oldService.Ports {
+		for _, backend := range oldService.Backends[portName] {
+			endpoint := backendEndpoint(backend, port)
+			if _, kept := newBackends[endpoint]; !kept {
+				purge.backends[endpoint] = struct{}{}
 			}
-			reqCtx2 := rndr.GoVPPChan.SendMultiRequest(req2)
+		}
+		if port.Port != 0 {
+			for _, externalIP := range oldService.ExternalIPs.List() {
+				purge.frontends[natEndpoint{
+					ip: externalIP.String(), port: port.Port, protocol: uint16(port.Protocol)}] = struct{}{}
+			}
+		}
+		if port.NodePort != 0 {
+			for _, nodeIP := range rndr.nodeIPs.List() {
+				purge.frontends[natEndpoint{
+					ip: nodeIP.String(), port: port.NodePort, protocol: uint16(port.Protocol)}] = struct{}{}
+			}
+		}
+	}
+	if len(purge.backends) == 0 {
+		return
+	}
 
-			for {
-				msg := &nat_api.Nat44UserSessionDetails{}
-				stop, err := reqCtx2.ReceiveReply(msg)
-				if stop {
-					break // break out of the loop
-				}
-				if err != nil {
-					rndr.Log.Errorf("Error by dumping NAT sessions: %v", err)
-				}
-				if msg.Protocol == 6 {
-					tcpCount++
-				} else {
-					otherCount++
-				}
+	rndr.Log.WithFields(logging.Fields{
+		"service":  purge.service,
+		"backends": purge.backends,
+	}).Debug("Scheduling purge of NAT sessions of removed backends")
+	rndr.purgeLock.Lock()
+	rndr.purgeQueue = append(rndr.purgeQueue, purge)
+	rndr.purgeLock.Unlock()
+	select {
+	case rndr.purgeSignal <- struct{}{}:
+	default:
+		// purge already signaled
+	}
+}
 
-				lastHeard := zeroTime.Add(time.Duration(msg.LastHeard) * time.Second)
-				if lastHeard.Before(time.Now()) {
-					if (msg.Protocol == 6 && time.Since(lastHeard) > tcpTimeout) ||
-						(msg.Protocol != 6 && time.Since(lastHeard) > otherTimeout) {
-						// inactive session
-						delRule := &nat_api.Nat44DelSession{
-							IsIn:     1,
-							Address:  msg.InsideIPAddress,
-							Port:     msg.InsidePort,
-							Protocol: uint8(msg.Protocol),
-						}
-						if msg.ExtHostValid > 0 {
-							delRule.ExtHostValid = 1
-
-							if msg.IsTwicenat > 0 {
-								delRule.ExtHostAddress = msg.ExtHostNatAddress
-								delRule.ExtHostPort = msg.ExtHostNatPort
-							} else {
-								delRule.ExtHostAddress = msg.ExtHostAddress
-								delRule.ExtHostPort = msg.ExtHostPort
-							}
-						}
-
-						delRules = append(delRules, delRule)
-					}
+// removedBackendsNATSessionPurge deletes NAT sessions of backends removed
+// from services, as scheduled by purgeRemovedBackends().
+func (rndr *Renderer) removedBackendsNATSessionPurge() {
+	for range rndr.purgeSignal {
+		rndr.purgeLock.Lock()
+		purges := rndr.purgeQueue
+		rndr.purgeQueue = nil
+		rndr.purgeLock.Unlock()
+
+		rndr.sessionsLock.Lock()
+		delRules := make([]*nat_api.Nat44DelSession, 0)
+		for _, msg := range rndr.dumpNATSessions() {
+			backend := natEndpoint{
+				ip:       net.IP(msg.InsideIPAddress).String(),
+				port:     msg.InsidePort,
+				protocol: msg.Protocol,
+			}
+			frontend := natEndpoint{
+				ip:       net.IP(msg.OutsideIPAddress).String(),
+				port:     msg.OutsidePort,
+				protocol: msg.Protocol,
+			}
+			for _, purge := range purges {
+				_, removedBackend := purge.backends[backend]
+				_, serviceFrontend := purge.frontends[frontend]
+				if removedBackend && serviceFrontend {
+					delRules = append(delRules, natSessionDelRule(msg))
+					break
 				}
 			}
+		}
+		rndr.Log.Debugf("Purging %d NAT sessions of removed service backends", len(delRules))
+		rndr.deleteNATSessions(delRules)
+		rndr.sessionsLock.Unlock()
+	}
+}
+
+// dumpNATSessions dumps NAT sessions of all NAT users.
+func (rndr *Renderer) dumpNATSessions() []*nat_api.Nat44UserSessionDetails {
+	natUsers := make([][]byte, 0)
+	sessions := make([]*nat_api.Nat44UserSessionDetails, 0)
 
+	// dump NAT users
+	req1 := &nat_api.Nat44UserDump{}
+	reqCtx1 := rndr.GoVPPChan.SendMultiRequest(req1)
+	for {
+		msg := &nat_api.Nat44UserDetails{}
+		stop, err := reqCtx1.ReceiveReply(msg)
+		if stop {
+			break // break out of the loop
+		}
+		if err != nil {
+			rndr.Log.Errorf("Error by dumping NAT users: %v", err)
 		}
+		natUsers = append(natUsers, msg.IPAddress)
+	}
 
-		rndr.Log.Debugf("There are %d TCP / %d other NAT sessions, %d will be deleted", tcpCount, otherCount, len(delRules))
-		atomic.StoreUint64(&tcpNatSessionCount, tcpCount)
-		atomic.StoreUint64(&otherNatSessionCount, otherCount)
+	// dump NAT sessions per user
+	for _, natUser := range natUsers {
+		req2 := &nat_api.Nat44UserSessionDump{
+			IPAddress: natUser,
+		}
+		reqCtx2 := rndr.GoVPPChan.SendMultiRequest(req2)
 
-		// delete the old sessions
-		for _, r := range delRules {
-			msg := &nat_api.Nat44DelSessionReply{}
-			err := rndr.GoVPPChan.SendRequest(r).ReceiveReply(msg)
-			if err != nil || msg.Retval != 0 {
-				rndr.Log.Warnf("Error by deleting NAT session: %v, retval=%d, req: %v", err, msg.Retval, r)
-				atomic.AddUint64(&natSessionDeleteErrorCount, 1)
+		for {
+			msg := &nat_api.Nat44UserSessionDetails{}
+			stop, err := reqCtx2.ReceiveReply(msg)
+			if stop {
+				break // break out of the loop
+			}
+			if err != nil {
+				rndr.Log.Errorf("Error by dumping NAT sessions: %v", err)
+			}
+			sessions = append(sessions, msg)
+		}
+	}
+	return sessions
+}
+
+// deleteNATSessions deletes the given NAT sessions and updates the statistics.
+func (rndr *Renderer) deleteNATSessions(delRules []*nat_api.Nat44DelSession) {
+	for _, r := range delRules {
+		msg := &nat_api.Nat44DelSessionReply{}
+		err := rndr.GoVPPChan.SendRequest(r).ReceiveReply(msg)
+		if err != nil || msg.Retval != 0 {
+			rndr.Log.Warnf("Error by deleting NAT session: %v, retval=%d, req: %v", err, msg.Retval, r)
+			atomic.AddUint64(&natSessionDeleteErrorCount, 1)
+		} else {
+			if r.Protocol == 6 {
+				atomic.AddUint64(&deletedTCPNatSessionCount, 1)
+				atomic.StoreUint64(&tcpNatSessionCount, atomic.LoadUint64(&tcpNatSessionCount)-1)
 			} else {
-				if r.Protocol == 6 {
-					atomic.AddUint64(&deletedTCPNatSessionCount, 1)
-					atomic.StoreUint64(&tcpNatSessionCount, atomic.LoadUint64(&tcpNatSessionCount)-1)
-				} else {
-					atomic.AddUint64(&deletedOtherNatSessionCount, 1)
-					atomic.StoreUint64(&otherNatSessionCount, atomic.LoadUint64(&otherNatSessionCount)-1)
-				}
+				atomic.AddUint64(&deletedOtherNatSessionCount, 1)
+				atomic.StoreUint64(&otherNatSessionCount, atomic.LoadUint64(&otherNatSessionCount)-1)
 			}
 		}
 	}
 }
 
+// natSessionDelRule returns request deleting the given NAT session.
+func natSessionDelRule(msg *nat_api.Nat44UserSessionDetails) *nat_api.Nat44DelSession {
+	delRule := &nat_api.Nat44DelSession{
+		IsIn:     1,
+		Address:  msg.InsideIPAddress,
+		Port:     msg.InsidePort,
+		Protocol: uint8(msg.Protocol),
+	}
+	if msg.ExtHostValid > 0 {
+		delRule.ExtHostValid = 1
+
+		if msg.IsTwicenat > 0 {
+			delRule.ExtHostAddress = msg.ExtHostNatAddress
+			delRule.ExtHostPort = msg.ExtHostNatPort
+		} else {
+			delRule.ExtHostAddress = msg.ExtHostAddress
+			delRule.ExtHostPort = msg.ExtHostPort
+		}
+	}
+	return delRule
+}
+
+// backendEndpoint returns NAT session endpoint of a service backend.
+func backendEndpoint(backend *renderer.ServiceBackend, port *renderer.ServicePort) natEndpoint {
+	return natEndpoint{ip: backend.IP.String(), port: backend.Port, protocol: uint16(port.Protocol)}
+}
+
 func tcpNatSessionsGauge() float64 {
 	return float64(atomic.LoadUint64(&tcpNatSessionCount))
 }